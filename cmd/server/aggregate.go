@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+)
+
+// runAggregate implements `hallmonitor aggregate`: maintenance of the
+// aggregates kept in storage
+func runAggregate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: hallmonitor aggregate recompute -from TIME [-to TIME] [-monitor NAME]")
+	}
+	switch args[0] {
+	case "recompute":
+		return runAggregateRecompute(args[1:])
+	default:
+		return fmt.Errorf("unknown aggregate command %q; use recompute", args[0])
+	}
+}
+
+// runAggregateRecompute regenerates aggregates over a range directly in the
+// store. The server should be stopped first, since Badger allows one process;
+// while it runs, use POST /api/v1/admin/aggregate/recompute instead.
+func runAggregateRecompute(args []string) error {
+	flags := flag.NewFlagSet("aggregate recompute", flag.ExitOnError)
+	configPath := flags.String("config", "config.yml", "Path to configuration file")
+	monitor := flags.String("monitor", "", "Monitor to recompute (default every monitor with stored results)")
+	fromStr := flags.String("from", "", "Start of the range, RFC3339")
+	toStr := flags.String("to", "", "End of the range, RFC3339 (default now)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	from, err := time.Parse(time.RFC3339, *fromStr)
+	if err != nil {
+		return fmt.Errorf("-from is required (use RFC3339, like 2025-01-01T00:00:00Z)")
+	}
+	to := time.Now()
+	if *toStr != "" {
+		if to, err = time.Parse(time.RFC3339, *toStr); err != nil {
+			return fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logger, err := logging.InitLogger(logging.Config{Level: "warn", Format: "text", Output: "stderr"})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	store, err := storage.NewStore(&cfg.Storage, logger)
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+	storage.ApplyTenants(store, cfg)
+
+	badgerStore, ok := store.(*storage.BadgerStore)
+	if !ok {
+		return fmt.Errorf("the %s backend keeps no aggregates to recompute", cfg.Storage.Backend)
+	}
+
+	summary, err := storage.NewAggregator(badgerStore, logger).Recompute(*monitor, from, to)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Recomputed %d hourly, %d daily, %d weekly and %d monthly aggregates for %d monitor(s)\n", summary.Hourly, summary.Daily, summary.Weekly, summary.Monthly, summary.Monitors)
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/importer"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+)
+
+// importOptions are the command line settings of an import
+type importOptions struct {
+	uptimeKuma string // Uptime Kuma database
+	blackbox   string // blackbox_exporter config
+	targets    string // Target list for the blackbox_exporter modules
+	group      string // Group for imported monitors
+	history    bool   // Backfill history into storage
+	dryRun     bool   // Print the converted monitors instead of saving them
+}
+
+// requested reports whether an import was asked for
+func (o importOptions) requested() bool {
+	return o.uptimeKuma != "" || o.blackbox != ""
+}
+
+// runImport adds monitors from another tool to the config file and
+// backfills their history. The server should be stopped first, since the
+// config is rewritten and Badger allows one process.
+func runImport(configPath string, opts importOptions) error {
+	var imp *importer.Import
+	switch {
+	case opts.uptimeKuma != "" && opts.blackbox != "":
+		return fmt.Errorf("import from one source at a time")
+
+	case opts.uptimeKuma != "":
+		file, err := os.Open(opts.uptimeKuma)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil {
+			return fmt.Errorf("failed to stat database: %w", err)
+		}
+		if imp, err = importer.ReadUptimeKuma(file, info.Size(), opts.group); err != nil {
+			return err
+		}
+
+	default:
+		if opts.targets == "" {
+			return fmt.Errorf("-import-blackbox requires -import-targets")
+		}
+		modules, err := os.ReadFile(opts.blackbox)
+		if err != nil {
+			return fmt.Errorf("failed to read blackbox_exporter config: %w", err)
+		}
+		targets, err := os.ReadFile(opts.targets)
+		if err != nil {
+			return fmt.Errorf("failed to read target list: %w", err)
+		}
+		if imp, err = importer.ReadBlackbox(modules, targets, opts.group); err != nil {
+			return err
+		}
+	}
+
+	if opts.dryRun {
+		printImportWarnings(imp)
+		data, err := yaml.Marshal(map[string]interface{}{"groups": imp.Groups})
+		if err != nil {
+			return fmt.Errorf("failed to marshal monitors: %w", err)
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	// Profiles are left out so they are not written into the file
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	added := imp.Merge(cfg)
+	printImportWarnings(imp)
+	if len(added) == 0 {
+		return fmt.Errorf("no monitors could be imported")
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("imported configuration is invalid: %w", err)
+	}
+	if err := cfg.WriteConfig(configPath); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d monitors into %s\n", len(added), configPath)
+
+	if !opts.history || !imp.HasHistory() {
+		return nil
+	}
+
+	logger, err := logging.InitLogger(logging.Config{Level: "warn", Format: "text", Output: "stderr"})
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	store, err := storage.NewStore(&cfg.Storage, logger)
+	if err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
+	}
+	defer store.Close()
+	storage.ApplyTenants(store, cfg)
+
+	results, err := imp.Backfill(store, added)
+	if err != nil {
+		return fmt.Errorf("history backfill failed after %d results: %w", results, err)
+	}
+	fmt.Printf("Backfilled %d results\n", results)
+	return nil
+}
+
+// printImportWarnings lists what could not be carried over
+func printImportWarnings(imp *importer.Import) {
+	for _, warning := range imp.Warnings {
+		fmt.Fprintln(os.Stderr, "warning:", warning)
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/wizard"
+)
+
+// runInit implements `hallmonitor init`: it asks for the basics of a setup
+// and writes them as a commented starter config
+func runInit(args []string) error {
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	configPath := flags.String("config", "config.yml", "Path of the configuration file to write")
+	force := flags.Bool("force", false, "Overwrite the configuration file if it exists")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(*configPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists; use -force to overwrite it", *configPath)
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to check %s: %w", *configPath, err)
+	}
+
+	fmt.Println("Hall Monitor setup. Press Enter to accept the [default] answers.")
+	answers, err := wizard.Run(os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+	data, err := wizard.Render(answers)
+	if err != nil {
+		return err
+	}
+
+	// The file may hold storage credentials
+	if err := os.WriteFile(*configPath, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	cfg, err := config.LoadConfig(*configPath)
+	if err != nil {
+		return fmt.Errorf("wrote %s but it does not load: %w", *configPath, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("wrote %s but it is invalid: %w", *configPath, err)
+	}
+
+	fmt.Printf("\nWrote %s with %d monitors. Start Hall Monitor with:\n\n  hallmonitor -config %s\n", *configPath, len(answers.Monitors), *configPath)
+	return nil
+}
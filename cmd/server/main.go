@@ -5,6 +5,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -12,23 +13,123 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/1broseidon/hallmonitor/internal/accounts"
 	"github.com/1broseidon/hallmonitor/internal/api"
 	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/geoip"
+	"github.com/1broseidon/hallmonitor/internal/graphite"
 	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/internal/mqtt"
+	"github.com/1broseidon/hallmonitor/internal/nagios"
+	"github.com/1broseidon/hallmonitor/internal/pipeline"
+	"github.com/1broseidon/hallmonitor/internal/reports"
+	"github.com/1broseidon/hallmonitor/internal/service"
+	"github.com/1broseidon/hallmonitor/internal/snmp"
 	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/internal/webhooks"
+	"github.com/1broseidon/hallmonitor/internal/zabbix"
 )
 
 func main() {
+	// Subcommands come before any flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "init":
+			if err := runInit(os.Args[2:]); err != nil {
+				log.Fatalf("Init failed: %v", err)
+			}
+			return
+		case "top":
+			if err := runTop(os.Args[2:]); err != nil {
+				log.Fatalf("Top failed: %v", err)
+			}
+			return
+		case "secret":
+			if err := runSecret(os.Args[2:]); err != nil {
+				log.Fatalf("Secret failed: %v", err)
+			}
+			return
+		case "aggregate":
+			if err := runAggregate(os.Args[2:]); err != nil {
+				log.Fatalf("Aggregate failed: %v", err)
+			}
+			return
+		}
+	}
+
 	// Parse command line flags
 	configPath := flag.String("config", "config.yml", "Path to configuration file")
+	profile := flag.String("profile", os.Getenv(config.ProfileEnvVar), "Configuration profile to apply (defaults to $"+config.ProfileEnvVar+")")
+	printMIB := flag.Bool("print-mib", false, "Print the SNMP trap MIB for the configured enterprise OID and exit")
+	var importOpts importOptions
+	flag.StringVar(&importOpts.uptimeKuma, "import-uptime-kuma", "", "Import monitors and history from an Uptime Kuma database (kuma.db) into the config and exit")
+	flag.StringVar(&importOpts.blackbox, "import-blackbox", "", "Import monitors from a blackbox_exporter config, probing -import-targets, into the config and exit")
+	flag.StringVar(&importOpts.targets, "import-targets", "", "Prometheus file_sd target list for -import-blackbox")
+	flag.StringVar(&importOpts.group, "import-group", "", "Group for imported monitors (default uptime-kuma or blackbox; Uptime Kuma groups are kept)")
+	flag.BoolVar(&importOpts.history, "import-history", true, "Backfill imported history into storage")
+	flag.BoolVar(&importOpts.dryRun, "import-dry-run", false, "Print imported monitors as YAML instead of saving them")
+	var scanOpts scanOptions
+	flag.StringVar(&scanOpts.path, "scan-proxy", "", "Propose HTTP monitors for the virtual hosts of a Caddyfile, Traefik dynamic config, or nginx config, print them as YAML, and exit")
+	flag.StringVar(&scanOpts.format, "scan-format", "", "Format of the -scan-proxy config: caddy, traefik, or nginx (detected by default)")
+	flag.StringVar(&scanOpts.group, "scan-group", "", "Group for the proposed monitors (default the format name)")
+	flag.BoolVar(&scanOpts.apply, "apply", false, "With -scan-proxy, create the proposed monitors on the server at -api-url instead of printing them")
+	flag.StringVar(&scanOpts.apiURL, "api-url", "http://localhost:7878", "Base URL of the server for -apply")
+	flag.StringVar(&scanOpts.apiToken, "api-token", os.Getenv("HALLMONITOR_ADMIN_TOKEN"), "Admin API token for -apply (defaults to $HALLMONITOR_ADMIN_TOKEN)")
+	var serviceOpts serviceOptions
+	flag.BoolVar(&serviceOpts.install, "install-service", false, "Install and start a background service (systemd, launchd, or Windows) running with this config, and exit")
+	flag.BoolVar(&serviceOpts.uninstall, "uninstall-service", false, "Stop and remove the background service, and exit")
+	flag.StringVar(&serviceOpts.name, "service-name", service.DefaultName, "Name of the background service")
+	flag.StringVar(&serviceOpts.user, "service-user", "", "Account the background service runs as (default the service manager's)")
 	flag.Parse()
 
+	if serviceOpts.requested() {
+		if err := runService(serviceOpts, *configPath, *profile); err != nil {
+			log.Fatalf("Service setup failed: %v", err)
+		}
+		return
+	}
+
+	if scanOpts.path != "" {
+		if err := runScan(scanOpts); err != nil {
+			log.Fatalf("Scan failed: %v", err)
+		}
+		return
+	}
+
+	if importOpts.requested() {
+		if err := runImport(*configPath, importOpts); err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		return
+	}
+
+	// Under the Windows service manager, stop requests arrive through it
+	// rather than as signals
+	serviceStop, serviceStopped, err := service.Control(serviceOpts.name)
+	if err != nil {
+		log.Fatalf("Failed to connect to the service manager: %v", err)
+	}
+
 	// Load configuration
-	cfg, err := config.LoadConfig(*configPath)
+	cfg, err := config.LoadConfigWithProfile(*configPath, *profile)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	if *printMIB {
+		enterpriseOID := cfg.SNMP.EnterpriseOID
+		if enterpriseOID == "" {
+			enterpriseOID = snmp.DefaultEnterpriseOID
+		}
+		enterprise, err := snmp.ParseOID(enterpriseOID)
+		if err != nil {
+			log.Fatalf("Invalid snmp.enterpriseOid: %v", err)
+		}
+		fmt.Print(snmp.GenerateMIB(enterprise))
+		return
+	}
+
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
 		log.Fatalf("Invalid configuration: %v", err)
@@ -36,15 +137,30 @@ func main() {
 
 	// Initialize logger
 	logger, err := logging.InitLogger(logging.Config{
-		Level:  cfg.Logging.Level,
-		Format: cfg.Logging.Format,
-		Output: cfg.Logging.Output,
-		Fields: cfg.Logging.Fields,
+		Level:   cfg.Logging.Level,
+		Format:  cfg.Logging.Format,
+		Output:  cfg.Logging.Output,
+		Outputs: cfg.Logging.Outputs,
+		Rotation: logging.RotationConfig{
+			MaxSizeMB:  cfg.Logging.Rotation.MaxSizeMB,
+			MaxAge:     cfg.Logging.Rotation.MaxAge,
+			MaxBackups: cfg.Logging.Rotation.MaxBackups,
+			Interval:   cfg.Logging.Rotation.Interval,
+			Compress:   cfg.Logging.Rotation.Compress,
+		},
+		ComponentLevels: cfg.Logging.ComponentLevels,
+		Fields:          cfg.Logging.Fields,
 	})
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
+	if cfg.Profile != "" {
+		logger.WithFields(map[string]interface{}{
+			"profile": cfg.Profile,
+		}).Info("Configuration profile applied")
+	}
+
 	// Create Prometheus registry
 	registry := prometheus.NewRegistry()
 
@@ -58,7 +174,9 @@ func main() {
 	// Check storage capabilities
 	caps := store.Capabilities()
 
-	// Create aggregator if backend supports aggregation and it's enabled
+	// Create aggregator if backend supports aggregation and it's enabled. In
+	// read-only mode it only serves the aggregates its writer maintains, since
+	// the scheduler never starts it.
 	var aggregator *storage.Aggregator
 	enableAggregation := cfg.Storage.EnableAggregation || cfg.Storage.Badger.EnableAggregation
 	if caps.SupportsAggregation && enableAggregation {
@@ -75,6 +193,7 @@ func main() {
 			"backend":             cfg.Storage.Backend,
 			"supportsRawResults":  caps.SupportsRawResults,
 			"supportsAggregation": caps.SupportsAggregation,
+			"readOnly":            caps.ReadOnly,
 		}).Info("Persistent storage enabled")
 	} else {
 		// Create server without persistent storage (NoOp backend)
@@ -82,6 +201,26 @@ func main() {
 		logger.Info("Running in metrics-only mode (no persistent storage)")
 	}
 
+	// Sign in with local accounts kept in the storage backend
+	if cfg.Server.Accounts.Enabled {
+		userStore, ok := store.(storage.UserStore)
+		if !ok || caps.ReadOnly {
+			logger.Fatal("Local accounts need a writable storage backend that stores users (badger)")
+		}
+		manager := accounts.NewManager(userStore, cfg.Server.Accounts.SessionTTL.ToDuration())
+		created, err := manager.EnsureInitialAdmin(cfg.Server.Accounts.InitialAdmin)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to set up local accounts")
+		}
+		if created {
+			logger.WithFields(map[string]interface{}{
+				"username": cfg.Server.Accounts.InitialAdmin.Username,
+			}).Info("Created initial admin account")
+		}
+		server.SetAccountManager(manager)
+		logger.Info("Local accounts enabled")
+	}
+
 	// Load monitors from configuration
 	if err := server.GetMonitorManager().LoadMonitors(cfg.Monitoring.Groups); err != nil {
 		logger.WithError(err).Fatal("Failed to load monitors")
@@ -89,10 +228,134 @@ func main() {
 
 	// Start the monitoring scheduler
 	scheduler := server.GetScheduler()
+
+	// Locate and rewrite results with the configured hooks before they are stored
+	if len(cfg.ResultHooks) > 0 || cfg.GeoIP.Enabled() {
+		resultPipeline := pipeline.New(cfg.ResultHooks, cfg.Monitoring.PluginDir, logger)
+		if cfg.GeoIP.Enabled() {
+			geo, err := geoip.Open(cfg.GeoIP)
+			if err != nil {
+				logger.WithError(err).Fatal("Failed to load GeoIP databases")
+			}
+			resultPipeline.SetGeoIP(geo)
+		}
+		scheduler.SetResultProcessor(resultPipeline)
+	}
+
+	// Register result webhooks
+	var resultWebhooks []*webhooks.ResultWebhook
+	for _, webhookCfg := range cfg.ResultWebhooks {
+		webhook := webhooks.NewResultWebhook(webhookCfg, logger)
+		if err := webhook.Start(context.Background()); err != nil {
+			logger.WithError(err).Fatal("Failed to start result webhook")
+		}
+		scheduler.AddResultHandler(webhook)
+		resultWebhooks = append(resultWebhooks, webhook)
+	}
+
+	// Register notification webhooks
+	var notifiers []*webhooks.Notifier
+	for _, webhookCfg := range cfg.Webhooks {
+		if webhookCfg.Locale == "" {
+			webhookCfg.Locale = cfg.Server.Locale
+		}
+		notifier := webhooks.NewNotifier(webhookCfg, cfg.Monitoring.PluginDir, logger)
+		notifier.SetMonitorSource(server.GetMonitorManager())
+		scheduler.AddResultHandler(notifier)
+		notifiers = append(notifiers, notifier)
+	}
+
+	// Publish monitor states to MQTT
+	var mqttPublisher *mqtt.Publisher
+	if cfg.MQTT.Enabled {
+		mqttPublisher = mqtt.NewPublisher(cfg.MQTT, logger)
+		if err := mqttPublisher.Start(context.Background()); err != nil {
+			logger.WithError(err).Fatal("Failed to start MQTT publisher")
+		}
+		scheduler.AddResultHandler(mqttPublisher)
+	}
+
+	// Send SNMP traps on state changes
+	if cfg.SNMP.Enabled {
+		trapSender, err := snmp.NewTrapSender(cfg.SNMP, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to create SNMP trap sender")
+		}
+		// Count restarts so SNMPv3 receivers accept traps after one
+		if bootStore, ok := store.(snmp.BootStore); ok && !caps.ReadOnly {
+			if err := trapSender.RecordBoot(bootStore); err != nil {
+				logger.WithError(err).Warn("Failed to record SNMP engine boot")
+			}
+		}
+		scheduler.AddResultHandler(trapSender)
+		logger.WithFields(map[string]interface{}{
+			"targets":      len(cfg.SNMP.Targets),
+			"engine_id":    trapSender.EngineID(),
+			"engine_boots": trapSender.EngineBoots(),
+		}).Info("SNMP traps enabled")
+	}
+
+	// Export check durations and states to Graphite
+	var graphiteEmitter *graphite.Emitter
+	if cfg.Graphite.Enabled {
+		graphiteEmitter = graphite.NewEmitter(cfg.Graphite, logger)
+		if err := graphiteEmitter.Start(context.Background()); err != nil {
+			logger.WithError(err).Fatal("Failed to start Graphite emitter")
+		}
+		scheduler.AddResultHandler(graphiteEmitter)
+	}
+
+	// Send monitor statuses to Zabbix as trapper items
+	var zabbixSender *zabbix.Sender
+	if cfg.Zabbix.Enabled {
+		zabbixSender = zabbix.NewSender(cfg.Zabbix, logger)
+		if err := zabbixSender.Start(context.Background()); err != nil {
+			logger.WithError(err).Fatal("Failed to start Zabbix sender")
+		}
+		scheduler.AddResultHandler(zabbixSender)
+	}
+
+	// Submit results to Nagios or Icinga as passive checks
+	var nagiosExporter *nagios.Exporter
+	if cfg.Nagios.Enabled {
+		nagiosExporter = nagios.NewExporter(cfg.Nagios, logger)
+		if err := nagiosExporter.Start(context.Background()); err != nil {
+			logger.WithError(err).Fatal("Failed to start Nagios exporter")
+		}
+		scheduler.AddResultHandler(nagiosExporter)
+	}
+
 	if err := scheduler.Start(context.Background()); err != nil {
 		logger.WithError(err).Fatal("Failed to start scheduler")
 	}
 
+	// Schedule uptime reports. Report changes take effect on restart, not reload.
+	var reportRunner *reports.Runner
+	if len(cfg.Reports.Schedules) > 0 {
+		generator := reports.NewGenerator(scheduler, server.GetMonitorManager())
+		runner, err := reports.NewRunner(cfg.Reports, generator, logger)
+		if err != nil {
+			logger.WithError(err).Fatal("Failed to create report runner")
+		}
+		if err := runner.Start(context.Background()); err != nil {
+			logger.WithError(err).Fatal("Failed to start report runner")
+		}
+		server.SetReportRunner(runner)
+		reportRunner = runner
+		logger.WithFields(map[string]interface{}{
+			"reports": len(cfg.Reports.Schedules),
+		}).Info("Scheduled reports enabled")
+	}
+
+	// Push metrics for probes that cannot be scraped
+	var metricsPusher *metrics.Pusher
+	if cfg.Metrics.Push.Enabled {
+		metricsPusher = metrics.NewPusher(cfg.Metrics.Push, registry, logger)
+		if err := metricsPusher.Start(context.Background()); err != nil {
+			logger.WithError(err).Fatal("Failed to start metrics push")
+		}
+	}
+
 	// Start server in a goroutine
 	go func() {
 		if err := server.Start(); err != nil {
@@ -105,7 +368,10 @@ func main() {
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	select {
+	case <-quit:
+	case <-serviceStop:
+	}
 
 	logger.Info("Shutting down Hall Monitor...")
 
@@ -114,10 +380,61 @@ func main() {
 		logger.WithError(err).Error("Failed to stop scheduler gracefully")
 	}
 
+	if reportRunner != nil {
+		if err := reportRunner.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop report runner")
+		}
+	}
+
+	if metricsPusher != nil {
+		if err := metricsPusher.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop metrics push")
+		}
+	}
+
+	// Flush pending result webhook batches
+	for _, webhook := range resultWebhooks {
+		if err := webhook.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop result webhook")
+		}
+	}
+
+	// Send pending notification digests
+	for _, notifier := range notifiers {
+		if err := notifier.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop notification webhook")
+		}
+	}
+
+	if mqttPublisher != nil {
+		if err := mqttPublisher.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop MQTT publisher")
+		}
+	}
+
+	if graphiteEmitter != nil {
+		if err := graphiteEmitter.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop Graphite emitter")
+		}
+	}
+
+	if zabbixSender != nil {
+		if err := zabbixSender.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop Zabbix sender")
+		}
+	}
+
+	if nagiosExporter != nil {
+		if err := nagiosExporter.Stop(); err != nil {
+			logger.WithError(err).Error("Failed to stop Nagios exporter")
+		}
+	}
+
 	// Gracefully shutdown the server
 	if err := server.Stop(); err != nil {
 		logger.WithError(err).Error("Failed to shutdown server gracefully")
 	}
 
 	logger.Info("Hall Monitor stopped")
+	serviceStopped()
 }
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/importer"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// scanOptions are the command line settings of a proxy config scan
+type scanOptions struct {
+	path     string // Reverse proxy config to scan
+	format   string // caddy, traefik, or nginx; detected when empty
+	group    string // Group for the proposed monitors
+	apply    bool   // Create the monitors through the API instead of printing them
+	apiURL   string // Base URL of the running server
+	apiToken string // Admin token for the API
+}
+
+// runScan proposes monitors for the virtual hosts of a reverse proxy config.
+// They are printed as a YAML snippet, or with apply created on a running
+// server through the API.
+func runScan(opts scanOptions) error {
+	data, err := os.ReadFile(opts.path)
+	if err != nil {
+		return fmt.Errorf("failed to read proxy config: %w", err)
+	}
+	format := opts.format
+	if format == "" {
+		if format = importer.DetectProxyFormat(opts.path, data); format == "" {
+			return fmt.Errorf("cannot tell the format of %s; set -scan-format", opts.path)
+		}
+	}
+	imp, err := importer.ScanProxyConfig(data, format, opts.group)
+	if err != nil {
+		return err
+	}
+	if imp.MonitorCount() == 0 {
+		printImportWarnings(imp)
+		return fmt.Errorf("no virtual hosts found in %s", opts.path)
+	}
+
+	if !opts.apply {
+		printImportWarnings(imp)
+		data, err := yaml.Marshal(map[string]interface{}{"groups": imp.Groups})
+		if err != nil {
+			return fmt.Errorf("failed to marshal monitors: %w", err)
+		}
+		fmt.Print(string(data))
+		return nil
+	}
+
+	client := &apiClient{
+		baseURL: strings.TrimSuffix(opts.apiURL, "/"),
+		token:   opts.apiToken,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	added, err := client.createMonitors(imp)
+	printImportWarnings(imp)
+	if err != nil {
+		if added > 0 {
+			return fmt.Errorf("created %d monitors before failing: %w", added, err)
+		}
+		return err
+	}
+	if added == 0 {
+		return fmt.Errorf("all proposed monitors already exist")
+	}
+	fmt.Printf("Created %d monitors on %s\n", added, client.baseURL)
+	return nil
+}
+
+// apiClient calls the config endpoints of a running server
+type apiClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// revisionResponse is the config revision returned by config writes
+type revisionResponse struct {
+	Revision string `json:"revision"`
+}
+
+// createMonitors adds the imported monitors to the server's config, creating
+// their group when it does not exist. Monitors whose names are taken are
+// skipped with a warning. It returns the number of monitors created.
+func (c *apiClient) createMonitors(imp *importer.Import) (int, error) {
+	var current struct {
+		Revision   string `json:"revision"`
+		Monitoring struct {
+			Groups []models.MonitorGroup `json:"groups"`
+		} `json:"monitoring"`
+	}
+	if err := c.do(http.MethodGet, "/api/v1/config", nil, &current); err != nil {
+		return 0, err
+	}
+
+	// Merging into a copy of the server's groups tells which monitors and
+	// groups are new
+	remote := &config.Config{}
+	remote.Monitoring.Groups = current.Monitoring.Groups
+	existingGroups := make(map[string]bool, len(remote.Monitoring.Groups))
+	for _, group := range remote.Monitoring.Groups {
+		existingGroups[group.Name] = true
+	}
+	added := make(map[string]bool)
+	for _, name := range imp.Merge(remote) {
+		added[name] = true
+	}
+
+	revision := current.Revision
+	created := 0
+	for _, group := range imp.Groups {
+		var monitors []models.Monitor
+		for _, monitor := range group.Monitors {
+			if added[monitor.Name] {
+				monitors = append(monitors, monitor)
+			}
+		}
+		if len(monitors) == 0 {
+			continue
+		}
+
+		if !existingGroups[group.Name] {
+			group.Monitors = monitors
+			var resp revisionResponse
+			if err := c.do(http.MethodPost, "/api/v1/groups", map[string]interface{}{
+				"group":    group,
+				"revision": revision,
+			}, &resp); err != nil {
+				return created, err
+			}
+			revision = resp.Revision
+			created += len(monitors)
+			continue
+		}
+
+		for _, monitor := range monitors {
+			var resp revisionResponse
+			if err := c.do(http.MethodPost, "/api/v1/monitors", map[string]interface{}{
+				"group_name": group.Name,
+				"monitor":    monitor,
+				"revision":   revision,
+			}, &resp); err != nil {
+				return created, err
+			}
+			revision = resp.Revision
+			created++
+		}
+	}
+	return created, nil
+}
+
+// do sends body as JSON and decodes the response into out
+func (c *apiClient) do(method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		var apiErr struct {
+			Message string `json:"message"`
+			Error   string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil {
+			switch {
+			case apiErr.Message != "" && apiErr.Error != "":
+				return fmt.Errorf("%s %s: %s: %s", method, path, apiErr.Message, apiErr.Error)
+			case apiErr.Message != "":
+				return fmt.Errorf("%s %s: %s", method, path, apiErr.Message)
+			case apiErr.Error != "":
+				return fmt.Errorf("%s %s: %s", method, path, apiErr.Error)
+			}
+		}
+		return fmt.Errorf("%s %s: unexpected status %s", method, path, resp.Status)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response of %s %s: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+)
+
+// runSecret implements `hallmonitor secret`: generating the secrets key and
+// encrypting values for the config's secrets block
+func runSecret(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: hallmonitor secret keygen | encrypt -name NAME")
+	}
+	switch args[0] {
+	case "keygen":
+		key, err := config.GenerateSecretKey()
+		if err != nil {
+			return err
+		}
+		fmt.Println(key)
+		return nil
+	case "encrypt":
+		return runSecretEncrypt(args[1:])
+	default:
+		return fmt.Errorf("unknown secret command %q; use keygen or encrypt", args[0])
+	}
+}
+
+// runSecretEncrypt reads a value from stdin and prints it encrypted, as a
+// line for the secrets block
+func runSecretEncrypt(args []string) error {
+	flags := flag.NewFlagSet("secret encrypt", flag.ExitOnError)
+	name := flags.String("name", "", "Name the config refers to the secret by, as ${secret:NAME}")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	key, err := config.LoadSecretKey()
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read value: %w", err)
+	}
+	// Drop the newline left by echo or a heredoc
+	value := strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r")
+	if value == "" {
+		return fmt.Errorf("no value on stdin")
+	}
+
+	encrypted, err := config.EncryptSecret(key, *name, value)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %q\n", strings.ToLower(*name), encrypted)
+	return nil
+}
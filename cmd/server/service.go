@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/service"
+)
+
+// serviceOptions are the command line settings for installing the service
+type serviceOptions struct {
+	install   bool   // Install and start the service
+	uninstall bool   // Stop and remove the service
+	name      string // Service name
+	user      string // Account the service runs as
+}
+
+// requested reports whether a service command was given
+func (o serviceOptions) requested() bool {
+	return o.install || o.uninstall
+}
+
+// runService installs or uninstalls Hall Monitor as a background service
+// that runs this binary with the given config and profile
+func runService(opts serviceOptions, configPath, profile string) error {
+	if opts.install && opts.uninstall {
+		return fmt.Errorf("-install-service and -uninstall-service cannot be combined")
+	}
+	if opts.uninstall {
+		location, err := service.Uninstall(opts.name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Uninstalled service %s (%s)\n", opts.name, location)
+		return nil
+	}
+
+	// The service starts in another directory, so paths must be absolute
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the server binary: %w", err)
+	}
+	if executable, err = filepath.EvalSymlinks(executable); err != nil {
+		return fmt.Errorf("failed to resolve the server binary: %w", err)
+	}
+	configPath, err = filepath.Abs(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config path: %w", err)
+	}
+	// Catch a broken config now rather than in a crash-looping service
+	if _, err := config.LoadConfigWithProfile(configPath, profile); err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	args := []string{"-config", configPath, "-service-name", opts.name}
+	if profile != "" {
+		args = append(args, "-profile", profile)
+	}
+	location, err := service.Install(service.Definition{
+		Name:        opts.name,
+		DisplayName: "Hall Monitor",
+		Description: "Network and service health monitoring",
+		Executable:  executable,
+		Args:        args,
+		WorkingDir:  filepath.Dir(configPath),
+		User:        opts.user,
+	})
+	if err != nil {
+		if location != "" {
+			return fmt.Errorf("installed %s but failed to start it: %w", location, err)
+		}
+		return err
+	}
+	fmt.Printf("Installed and started service %s (%s)\n", opts.name, location)
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/1broseidon/hallmonitor/internal/top"
+)
+
+// runTop implements `hallmonitor top`: a live table of monitor statuses
+// streamed from a running server, for terminals without a browser
+func runTop(args []string) error {
+	flags := flag.NewFlagSet("top", flag.ExitOnError)
+	apiURL := flags.String("api-url", "http://localhost:7878", "Base URL of the server to follow")
+	apiToken := flags.String("api-token", os.Getenv("HALLMONITOR_ADMIN_TOKEN"), "API token, when the server requires one (defaults to $HALLMONITOR_ADMIN_TOKEN)")
+	sortOrder := flags.String("sort", top.SortStatus, "Initial sort order: status, name, group, or latency")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if !top.ValidSort(*sortOrder) {
+		return fmt.Errorf("unknown sort order %q", *sortOrder)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return top.Run(ctx, top.Options{
+		BaseURL: *apiURL,
+		Token:   *apiToken,
+		Sort:    *sortOrder,
+	})
+}
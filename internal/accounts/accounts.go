@@ -0,0 +1,479 @@
+// Package accounts manages local user accounts and their sign-in sessions,
+// persisted in a storage backend that implements storage.UserStore.
+package accounts
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	// defaultSessionTTL is used when no session lifetime is configured
+	defaultSessionTTL = 24 * time.Hour
+	// challengeTTL is how long a password-checked sign-in waits for its
+	// second factor
+	challengeTTL = 5 * time.Minute
+)
+
+var (
+	// ErrInvalidCredentials is returned for an unknown user or wrong password
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	// ErrUserExists is returned when creating a user whose name is taken
+	ErrUserExists = errors.New("user already exists")
+	// ErrUserNotFound is returned when changing a user that does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidUsername is returned for usernames outside usernamePattern
+	ErrInvalidUsername = errors.New("username must be 1-64 letters, digits, '.', '_', '@' or '-'")
+	// ErrWeakPassword is returned for passwords shorter than the minimum
+	ErrWeakPassword = fmt.Errorf("password must be at least %d characters", config.MinPasswordLength)
+	// ErrInvalidCode is returned for a wrong two-factor or recovery code
+	ErrInvalidCode = errors.New("invalid two-factor code")
+	// ErrChallengeExpired is returned when a pending sign-in ended or expired
+	ErrChallengeExpired = errors.New("sign-in expired, sign in again")
+	// ErrTooManyAttempts is returned while a user or client IP is locked out
+	// after too many wrong two-factor codes
+	ErrTooManyAttempts = errors.New("too many failed two-factor attempts, try again later")
+	// ErrTOTPEnabled is returned when enrolling a user who already has TOTP
+	ErrTOTPEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTOTPNotEnabled is returned for TOTP changes of a user without TOTP
+	ErrTOTPNotEnabled = errors.New("two-factor authentication is not enabled")
+	// ErrTOTPNotPending is returned when confirming without a started enrollment
+	ErrTOTPNotPending = errors.New("two-factor enrollment has not been started")
+)
+
+// usernamePattern restricts usernames, which appear in storage keys and URLs
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9._@-]{1,64}$`)
+
+// dummyHash is compared against when a user does not exist, so sign-in takes
+// as long for unknown users as for wrong passwords
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("hallmonitor-dummy-password"), bcrypt.DefaultCost)
+
+// Manager creates and authenticates local users and their sessions
+type Manager struct {
+	store      storage.UserStore
+	sessionTTL time.Duration
+	failures   *failureLog      // Wrong two-factor codes per user and client IP
+	now        func() time.Time // Replaced in tests
+}
+
+// NewManager creates an account manager; a zero sessionTTL uses the default
+func NewManager(store storage.UserStore, sessionTTL time.Duration) *Manager {
+	if sessionTTL <= 0 {
+		sessionTTL = defaultSessionTTL
+	}
+	return &Manager{
+		store:      store,
+		sessionTTL: sessionTTL,
+		failures:   newFailureLog(),
+		now:        time.Now,
+	}
+}
+
+// SessionTTL returns how long new sessions last
+func (m *Manager) SessionTTL() time.Duration {
+	return m.sessionTTL
+}
+
+// EnsureInitialAdmin creates the configured first account when no accounts
+// exist, reporting whether it did
+func (m *Manager) EnsureInitialAdmin(admin config.InitialAdminConfig) (bool, error) {
+	if admin.Username == "" {
+		return false, nil
+	}
+
+	users, err := m.store.ListUsers()
+	if err != nil {
+		return false, err
+	}
+	if len(users) > 0 {
+		return false, nil
+	}
+
+	if _, err := m.CreateUser(admin.Username, admin.Password, ""); err != nil {
+		return false, fmt.Errorf("failed to create initial admin: %w", err)
+	}
+	return true, nil
+}
+
+// CreateUser creates a user; a tenant limits them to that tenant's groups
+func (m *Manager) CreateUser(username, password, tenant string) (*models.User, error) {
+	if !usernamePattern.MatchString(username) {
+		return nil, ErrInvalidUsername
+	}
+
+	existing, err := m.store.GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, ErrUserExists
+	}
+
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.now()
+	user := &models.User{
+		Username:          username,
+		PasswordHash:      hash,
+		Tenant:            tenant,
+		CreatedAt:         now,
+		PasswordChangedAt: now,
+	}
+	if err := m.store.SaveUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUser returns a user, or nil if there is none with that name
+func (m *Manager) GetUser(username string) (*models.User, error) {
+	return m.store.GetUser(username)
+}
+
+// ListUsers returns every user sorted by name
+func (m *Manager) ListUsers() ([]*models.User, error) {
+	return m.store.ListUsers()
+}
+
+// UpdateUser changes a user's tenant and, if password is set, their password
+func (m *Manager) UpdateUser(username, password, tenant string) (*models.User, error) {
+	user, err := m.requireUser(username)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Tenant = tenant
+	if password != "" {
+		if user.PasswordHash, err = hashPassword(password); err != nil {
+			return nil, err
+		}
+		user.PasswordChangedAt = m.now()
+	}
+	if err := m.store.SaveUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ChangePassword sets a new password after checking the current one. Existing
+// sessions of the user end.
+func (m *Manager) ChangePassword(username, current, password string) error {
+	user, err := m.Authenticate(username, current)
+	if err != nil {
+		return err
+	}
+
+	if user.PasswordHash, err = hashPassword(password); err != nil {
+		return err
+	}
+	user.PasswordChangedAt = m.now()
+	return m.store.SaveUser(user)
+}
+
+// DeleteUser removes a user, ending their sessions
+func (m *Manager) DeleteUser(username string) error {
+	if _, err := m.requireUser(username); err != nil {
+		return err
+	}
+	return m.store.DeleteUser(username)
+}
+
+// Authenticate returns the user if the password matches
+func (m *Manager) Authenticate(username, password string) (*models.User, error) {
+	user, err := m.store.GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return user, nil
+}
+
+// Login authenticates a user and starts a session, returning its token. For
+// users with two-factor authentication the session is Pending until
+// VerifyChallenge accepts a code for it. Sign-ins of a user or from a client
+// IP locked out after too many wrong codes are refused.
+func (m *Manager) Login(username, password, clientIP string) (string, *models.Session, error) {
+	if m.failures.locked(m.now(), failureKeys(username, clientIP)...) {
+		return "", nil, ErrTooManyAttempts
+	}
+
+	user, err := m.Authenticate(username, password)
+	if err != nil {
+		return "", nil, err
+	}
+	if user.TOTPEnabled() {
+		return m.newSession(user.Username, challengeTTL, true)
+	}
+	return m.StartSession(user.Username)
+}
+
+// StartSession starts a session for a user, returning its token
+func (m *Manager) StartSession(username string) (string, *models.Session, error) {
+	return m.newSession(username, m.sessionTTL, false)
+}
+
+// VerifyChallenge completes a pending sign-in with a TOTP or recovery code,
+// replacing it with a full session. Wrong codes count against the user and
+// clientIP across sign-ins; too many lock both out for a while and end the
+// sign-in.
+func (m *Manager) VerifyChallenge(token, code, clientIP string) (string, *models.Session, error) {
+	challenge, err := m.store.GetSession(hashToken(token))
+	if err != nil {
+		return "", nil, err
+	}
+	if challenge == nil || !challenge.Pending || !m.now().Before(challenge.ExpiresAt) {
+		return "", nil, ErrChallengeExpired
+	}
+
+	user, err := m.store.GetUser(challenge.Username)
+	if err != nil {
+		return "", nil, err
+	}
+	if user == nil || !user.TOTPEnabled() {
+		return "", nil, ErrChallengeExpired
+	}
+
+	keys := failureKeys(user.Username, clientIP)
+	if m.failures.locked(m.now(), keys...) {
+		if err := m.store.DeleteSession(challenge.TokenHash); err != nil {
+			return "", nil, err
+		}
+		return "", nil, ErrTooManyAttempts
+	}
+
+	if step, ok := verifyTOTP(user.TOTPSecret, code, m.now(), user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+	} else if remaining, ok := useRecoveryCode(user.RecoveryCodes, code); ok {
+		user.RecoveryCodes = remaining
+	} else {
+		if m.failures.fail(m.now(), keys...) {
+			if err := m.store.DeleteSession(challenge.TokenHash); err != nil {
+				return "", nil, err
+			}
+			return "", nil, ErrTooManyAttempts
+		}
+		return "", nil, ErrInvalidCode
+	}
+	m.failures.reset(userKey(user.Username))
+
+	if err := m.store.SaveUser(user); err != nil {
+		return "", nil, err
+	}
+	if err := m.store.DeleteSession(challenge.TokenHash); err != nil {
+		return "", nil, err
+	}
+	return m.StartSession(user.Username)
+}
+
+// BeginTOTP starts enrolling a user in two-factor authentication, returning
+// the new secret and its otpauth:// URI. It takes effect once ConfirmTOTP
+// accepts a code from it.
+func (m *Manager) BeginTOTP(username string) (string, string, error) {
+	user, err := m.requireUser(username)
+	if err != nil {
+		return "", "", err
+	}
+	if user.TOTPEnabled() {
+		return "", "", ErrTOTPEnabled
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	user.TOTPPendingSecret = secret
+	if err := m.store.SaveUser(user); err != nil {
+		return "", "", err
+	}
+	return secret, totpURI(user.Username, secret), nil
+}
+
+// ConfirmTOTP enables two-factor authentication once a code from the secret
+// being enrolled checks out, returning the user's recovery codes
+func (m *Manager) ConfirmTOTP(username, code string) ([]string, error) {
+	user, err := m.requireUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPPendingSecret == "" {
+		return nil, ErrTOTPNotPending
+	}
+
+	step, ok := verifyTOTP(user.TOTPPendingSecret, code, m.now(), 0)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	user.TOTPSecret = user.TOTPPendingSecret
+	user.TOTPPendingSecret = ""
+	user.TOTPLastStep = step
+	user.RecoveryCodes = hashes
+	if err := m.store.SaveUser(user); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor authentication after checking the password
+func (m *Manager) DisableTOTP(username, password string) error {
+	user, err := m.Authenticate(username, password)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled() {
+		return ErrTOTPNotEnabled
+	}
+	clearTOTP(user)
+	return m.store.SaveUser(user)
+}
+
+// ResetTOTP turns off two-factor authentication for a user who lost their
+// authenticator and recovery codes
+func (m *Manager) ResetTOTP(username string) error {
+	user, err := m.requireUser(username)
+	if err != nil {
+		return err
+	}
+	clearTOTP(user)
+	return m.store.SaveUser(user)
+}
+
+// RegenerateRecoveryCodes replaces a user's recovery codes after checking the
+// password
+func (m *Manager) RegenerateRecoveryCodes(username, password string) ([]string, error) {
+	user, err := m.Authenticate(username, password)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled() {
+		return nil, ErrTOTPNotEnabled
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	user.RecoveryCodes = hashes
+	if err := m.store.SaveUser(user); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// newSession stores a session lasting ttl, returning its token
+func (m *Manager) newSession(username string, ttl time.Duration, pending bool) (string, *models.Session, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	now := m.now()
+	session := &models.Session{
+		TokenHash: hashToken(token),
+		Username:  username,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		Pending:   pending,
+	}
+	if err := m.store.SaveSession(session); err != nil {
+		return "", nil, err
+	}
+	return token, session, nil
+}
+
+// requireUser returns a user, or ErrUserNotFound
+func (m *Manager) requireUser(username string) (*models.User, error) {
+	user, err := m.store.GetUser(username)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// Session returns the user signed in with a session token, or nil if the
+// session expired, was ended, or predates the user's last password change
+func (m *Manager) Session(token string) (*models.User, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	session, err := m.store.GetSession(hashToken(token))
+	if err != nil || session == nil {
+		return nil, err
+	}
+	if session.Pending || !m.now().Before(session.ExpiresAt) {
+		return nil, nil
+	}
+
+	user, err := m.store.GetUser(session.Username)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if session.CreatedAt.Before(user.PasswordChangedAt) {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// Logout ends a session
+func (m *Manager) Logout(token string) error {
+	if token == "" {
+		return nil
+	}
+	return m.store.DeleteSession(hashToken(token))
+}
+
+// hashPassword checks a password's length and hashes it with bcrypt
+func hashPassword(password string) (string, error) {
+	if len(password) < config.MinPasswordLength {
+		return "", ErrWeakPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// clearTOTP removes a user's two-factor authentication settings
+func clearTOTP(user *models.User) {
+	user.TOTPSecret = ""
+	user.TOTPPendingSecret = ""
+	user.TOTPLastStep = 0
+	user.RecoveryCodes = nil
+}
+
+// hashToken returns the stored form of a session token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,182 @@
+package accounts
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// memoryStore is an in-memory storage.UserStore
+type memoryStore struct {
+	users    map[string]models.User
+	sessions map[string]models.Session
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{users: make(map[string]models.User), sessions: make(map[string]models.Session)}
+}
+
+func (s *memoryStore) SaveUser(user *models.User) error {
+	s.users[user.Username] = *user
+	return nil
+}
+
+func (s *memoryStore) GetUser(username string) (*models.User, error) {
+	user, ok := s.users[username]
+	if !ok {
+		return nil, nil
+	}
+	return &user, nil
+}
+
+func (s *memoryStore) ListUsers() ([]*models.User, error) {
+	users := make([]*models.User, 0, len(s.users))
+	for _, user := range s.users {
+		user := user
+		users = append(users, &user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users, nil
+}
+
+func (s *memoryStore) DeleteUser(username string) error {
+	delete(s.users, username)
+	return nil
+}
+
+func (s *memoryStore) SaveSession(session *models.Session) error {
+	s.sessions[session.TokenHash] = *session
+	return nil
+}
+
+func (s *memoryStore) GetSession(tokenHash string) (*models.Session, error) {
+	session, ok := s.sessions[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (s *memoryStore) DeleteSession(tokenHash string) error {
+	delete(s.sessions, tokenHash)
+	return nil
+}
+
+func TestManagerCreateUser(t *testing.T) {
+	manager := NewManager(newMemoryStore(), 0)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		wantErr  error
+	}{
+		{name: "valid", username: "alice@example.com", password: "correct horse"},
+		{name: "duplicate", username: "alice@example.com", password: "correct horse", wantErr: ErrUserExists},
+		{name: "invalid username", username: "alice smith", password: "correct horse", wantErr: ErrInvalidUsername},
+		{name: "short password", username: "bob", password: "short", wantErr: ErrWeakPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := manager.CreateUser(tt.username, tt.password, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateUser() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (user.PasswordHash == "" || user.PasswordHash == tt.password) {
+				t.Errorf("expected a bcrypt hash, got %q", user.PasswordHash)
+			}
+		})
+	}
+}
+
+func TestManagerLoginAndSessions(t *testing.T) {
+	manager := NewManager(newMemoryStore(), time.Hour)
+	if _, err := manager.CreateUser("alice", "correct horse", "acme"); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	if _, _, err := manager.Login("alice", "wrong password", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected invalid credentials for a wrong password, got %v", err)
+	}
+	if _, _, err := manager.Login("mallory", "correct horse", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected invalid credentials for an unknown user, got %v", err)
+	}
+
+	token, session, err := manager.Login("alice", "correct horse", "")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if session.TokenHash == token {
+		t.Errorf("expected only a hash of the token to be stored")
+	}
+
+	user, err := manager.Session(token)
+	if err != nil || user == nil || user.Username != "alice" || user.Tenant != "acme" {
+		t.Fatalf("expected alice's session, got %+v, %v", user, err)
+	}
+
+	// Sessions end when they expire
+	manager.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if user, _ := manager.Session(token); user != nil {
+		t.Errorf("expected expired session to be rejected")
+	}
+	manager.now = time.Now
+
+	if err := manager.Logout(token); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	if user, _ := manager.Session(token); user != nil {
+		t.Errorf("expected session to end on logout")
+	}
+}
+
+func TestManagerChangePassword(t *testing.T) {
+	manager := NewManager(newMemoryStore(), time.Hour)
+	if _, err := manager.CreateUser("alice", "correct horse", ""); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	token, _, err := manager.Login("alice", "correct horse", "")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	if err := manager.ChangePassword("alice", "wrong password", "battery staple"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected the current password to be checked, got %v", err)
+	}
+	if err := manager.ChangePassword("alice", "correct horse", "short"); !errors.Is(err, ErrWeakPassword) {
+		t.Errorf("expected a weak password to be rejected, got %v", err)
+	}
+	if err := manager.ChangePassword("alice", "correct horse", "battery staple"); err != nil {
+		t.Fatalf("ChangePassword failed: %v", err)
+	}
+
+	if user, _ := manager.Session(token); user != nil {
+		t.Errorf("expected sessions to end when the password changes")
+	}
+	if _, _, err := manager.Login("alice", "battery staple", ""); err != nil {
+		t.Errorf("expected the new password to work, got %v", err)
+	}
+}
+
+func TestManagerEnsureInitialAdmin(t *testing.T) {
+	manager := NewManager(newMemoryStore(), 0)
+	admin := config.InitialAdminConfig{Username: "admin", Password: "correct horse"}
+
+	created, err := manager.EnsureInitialAdmin(admin)
+	if err != nil || !created {
+		t.Fatalf("expected the initial admin to be created, got %v, %v", created, err)
+	}
+	created, err = manager.EnsureInitialAdmin(config.InitialAdminConfig{Username: "other", Password: "correct horse"})
+	if err != nil || created {
+		t.Errorf("expected no admin to be created once accounts exist, got %v, %v", created, err)
+	}
+
+	if _, err := manager.Authenticate("admin", "correct horse"); err != nil {
+		t.Errorf("expected the initial admin to sign in, got %v", err)
+	}
+}
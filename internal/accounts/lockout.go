@@ -0,0 +1,105 @@
+package accounts
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// maxTOTPFailures is how many wrong second-factor codes, across sign-ins,
+	// lock out a user or client IP
+	maxTOTPFailures = 5
+	// totpLockout is how long a lockout lasts, and how long failures are
+	// remembered before they stop counting towards one
+	totpLockout = 15 * time.Minute
+)
+
+// failureCount is the recent wrong codes of one user or client IP
+type failureCount struct {
+	count       int
+	last        time.Time
+	lockedUntil time.Time
+}
+
+// failureLog counts wrong second-factor codes per user and per client IP, so
+// guessing cannot continue by starting new sign-ins or switching accounts. It
+// is kept in memory; a restart forgets it.
+type failureLog struct {
+	mu      sync.Mutex
+	entries map[string]*failureCount
+}
+
+func newFailureLog() *failureLog {
+	return &failureLog{entries: make(map[string]*failureCount)}
+}
+
+// userKey and ipKey keep usernames and addresses apart in the log
+func userKey(username string) string { return "user:" + username }
+func ipKey(ip string) string         { return "ip:" + ip }
+
+// failureKeys returns the log entries a sign-in counts against. An empty
+// client IP, as when it cannot be determined, counts against the user only.
+func failureKeys(username, clientIP string) []string {
+	keys := []string{userKey(username)}
+	if clientIP != "" {
+		keys = append(keys, ipKey(clientIP))
+	}
+	return keys
+}
+
+// locked reports whether any of keys is locked out at now
+func (l *failureLog) locked(now time.Time, keys ...string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		if entry, ok := l.entries[key]; ok && now.Before(entry.lockedUntil) {
+			return true
+		}
+	}
+	return false
+}
+
+// fail records a wrong code against keys, reporting whether any of them is
+// now locked out
+func (l *failureLog) fail(now time.Time, keys ...string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pruneLocked(now)
+	locked := false
+	for _, key := range keys {
+		entry, ok := l.entries[key]
+		if !ok {
+			entry = &failureCount{}
+			l.entries[key] = entry
+		}
+		entry.count++
+		entry.last = now
+		if entry.count >= maxTOTPFailures {
+			entry.count = 0
+			entry.lockedUntil = now.Add(totpLockout)
+		}
+		if now.Before(entry.lockedUntil) {
+			locked = true
+		}
+	}
+	return locked
+}
+
+// reset forgets the failures of key, after a code it sent was accepted
+func (l *failureLog) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.entries, key)
+}
+
+// pruneLocked drops entries whose failures and lockout have both run out;
+// callers must hold mu
+func (l *failureLog) pruneLocked(now time.Time) {
+	for key, entry := range l.entries {
+		if now.Sub(entry.last) >= totpLockout && !now.Before(entry.lockedUntil) {
+			delete(l.entries, key)
+		}
+	}
+}
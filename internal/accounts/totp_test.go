@@ -0,0 +1,286 @@
+package accounts
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 SHA-1 test key "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := totpCode(rfcSecret, totpStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("totpCode failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("totpCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	code, _ := totpCode(rfcSecret, totpStep(now))
+	previous, _ := totpCode(rfcSecret, totpStep(now)-1)
+	stale, _ := totpCode(rfcSecret, totpStep(now)-3)
+
+	tests := []struct {
+		name     string
+		code     string
+		lastStep int64
+		want     bool
+	}{
+		{name: "current", code: code, want: true},
+		{name: "with spaces", code: code[:3] + " " + code[3:], want: true},
+		{name: "previous step", code: previous, want: true},
+		{name: "stale", code: stale, want: false},
+		{name: "replayed", code: code, lastStep: totpStep(now), want: false},
+		{name: "wrong length", code: "12345", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := verifyTOTP(rfcSecret, tt.code, now, tt.lastStep); ok != tt.want {
+				t.Errorf("verifyTOTP() = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := totpURI("alice@example.com", rfcSecret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Hall%20Monitor:alice@example.com?") || !strings.Contains(uri, "secret="+rfcSecret) {
+		t.Errorf("unexpected URI %s", uri)
+	}
+}
+
+// enrollTOTP enables TOTP for a user, returning the secret and recovery codes
+func enrollTOTP(t *testing.T, manager *Manager, username string) (string, []string) {
+	t.Helper()
+
+	secret, _, err := manager.BeginTOTP(username)
+	if err != nil {
+		t.Fatalf("BeginTOTP failed: %v", err)
+	}
+	code, _ := totpCode(secret, totpStep(manager.now()))
+	codes, err := manager.ConfirmTOTP(username, code)
+	if err != nil {
+		t.Fatalf("ConfirmTOTP failed: %v", err)
+	}
+	return secret, codes
+}
+
+func TestManagerTOTPEnrollment(t *testing.T) {
+	manager := NewManager(newMemoryStore(), time.Hour)
+	if _, err := manager.CreateUser("alice", "correct horse", ""); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+
+	if _, err := manager.ConfirmTOTP("alice", "123456"); !errors.Is(err, ErrTOTPNotPending) {
+		t.Errorf("expected confirming without enrolling to fail, got %v", err)
+	}
+	if _, _, err := manager.BeginTOTP("alice"); err != nil {
+		t.Fatalf("BeginTOTP failed: %v", err)
+	}
+	if _, err := manager.ConfirmTOTP("alice", "000000"); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("expected a wrong code to be rejected, got %v", err)
+	}
+
+	// Pending enrollments do not change sign-in
+	if _, session, err := manager.Login("alice", "correct horse", ""); err != nil || session.Pending {
+		t.Fatalf("expected a full session before enrollment is confirmed, got %+v, %v", session, err)
+	}
+
+	_, codes := enrollTOTP(t, manager, "alice")
+	if len(codes) != recoveryCodeCount {
+		t.Errorf("expected %d recovery codes, got %d", recoveryCodeCount, len(codes))
+	}
+	if _, _, err := manager.BeginTOTP("alice"); !errors.Is(err, ErrTOTPEnabled) {
+		t.Errorf("expected enrolling twice to fail, got %v", err)
+	}
+
+	if err := manager.DisableTOTP("alice", "wrong password"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected disabling to check the password, got %v", err)
+	}
+	if err := manager.DisableTOTP("alice", "correct horse"); err != nil {
+		t.Fatalf("DisableTOTP failed: %v", err)
+	}
+	if user, _ := manager.GetUser("alice"); user.TOTPEnabled() || len(user.RecoveryCodes) != 0 {
+		t.Errorf("expected TOTP settings to be cleared, got %+v", user)
+	}
+}
+
+func TestManagerVerifyChallenge(t *testing.T) {
+	manager := NewManager(newMemoryStore(), time.Hour)
+	if _, err := manager.CreateUser("alice", "correct horse", ""); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	secret, recovery := enrollTOTP(t, manager, "alice")
+
+	// Move to the next time step so the enrollment code is not a replay
+	manager.now = func() time.Time { return time.Now().Add(totpPeriod) }
+
+	challenge, session, err := manager.Login("alice", "correct horse", "")
+	if err != nil || !session.Pending {
+		t.Fatalf("expected a pending session, got %+v, %v", session, err)
+	}
+	if user, _ := manager.Session(challenge); user != nil {
+		t.Errorf("expected a pending session to grant no access")
+	}
+
+	if _, _, err := manager.VerifyChallenge(challenge, "000000", ""); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("expected a wrong code to be rejected, got %v", err)
+	}
+	code, _ := totpCode(secret, totpStep(manager.now()))
+	token, _, err := manager.VerifyChallenge(challenge, code, "")
+	if err != nil {
+		t.Fatalf("VerifyChallenge failed: %v", err)
+	}
+	if user, _ := manager.Session(token); user == nil {
+		t.Errorf("expected the verified session to grant access")
+	}
+	if _, _, err := manager.VerifyChallenge(challenge, code, ""); !errors.Is(err, ErrChallengeExpired) {
+		t.Errorf("expected the challenge to be used up, got %v", err)
+	}
+
+	// The same code cannot complete a second sign-in
+	challenge, _, _ = manager.Login("alice", "correct horse", "")
+	if _, _, err := manager.VerifyChallenge(challenge, code, ""); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("expected a replayed code to be rejected, got %v", err)
+	}
+
+	// Recovery codes work once each
+	if _, _, err := manager.VerifyChallenge(challenge, strings.ToUpper(recovery[0]), ""); err != nil {
+		t.Errorf("expected a recovery code to be accepted, got %v", err)
+	}
+	challenge, _, _ = manager.Login("alice", "correct horse", "")
+	if _, _, err := manager.VerifyChallenge(challenge, recovery[0], ""); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("expected a used recovery code to be rejected, got %v", err)
+	}
+
+	// Too many wrong codes end the sign-in
+	for i := 1; i < maxTOTPFailures-1; i++ {
+		manager.VerifyChallenge(challenge, "000000", "")
+	}
+	if _, _, err := manager.VerifyChallenge(challenge, "000000", ""); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("expected a lockout after %d wrong codes, got %v", maxTOTPFailures, err)
+	}
+	if _, _, err := manager.VerifyChallenge(challenge, recovery[1], ""); !errors.Is(err, ErrChallengeExpired) {
+		t.Errorf("expected the challenge to end with the lockout, got %v", err)
+	}
+}
+
+func TestManagerTOTPLockout(t *testing.T) {
+	manager := NewManager(newMemoryStore(), time.Hour)
+	for _, username := range []string{"alice", "bob"} {
+		if _, err := manager.CreateUser(username, "correct horse", ""); err != nil {
+			t.Fatalf("CreateUser failed: %v", err)
+		}
+	}
+	secret, _ := enrollTOTP(t, manager, "alice")
+	enrollTOTP(t, manager, "bob")
+	now := time.Now().Add(totpPeriod)
+	manager.now = func() time.Time { return now }
+
+	// Each wrong code is on a new sign-in, so only counting across sign-ins
+	// stops the guessing
+	for i := 0; i < maxTOTPFailures; i++ {
+		challenge, _, err := manager.Login("alice", "correct horse", "192.0.2.1")
+		if err != nil {
+			t.Fatalf("Login %d failed: %v", i+1, err)
+		}
+		manager.VerifyChallenge(challenge, "000000", "192.0.2.1")
+	}
+
+	// A correct password and code no longer sign in, from any address
+	if _, _, err := manager.Login("alice", "correct horse", "192.0.2.1"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("expected signing in again after %d failures to be rejected, got %v", maxTOTPFailures, err)
+	}
+	if _, _, err := manager.Login("alice", "correct horse", "198.51.100.1"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("expected the user to be locked out from other addresses, got %v", err)
+	}
+	// The address is locked out for other users too
+	if _, _, err := manager.Login("bob", "correct horse", "192.0.2.1"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("expected the address to be locked out for other users, got %v", err)
+	}
+	if _, session, err := manager.Login("bob", "correct horse", "198.51.100.1"); err != nil || !session.Pending {
+		t.Errorf("expected other users to sign in from other addresses, got %+v, %v", session, err)
+	}
+
+	// The lockout ends
+	now = now.Add(totpLockout)
+	challenge, _, err := manager.Login("alice", "correct horse", "192.0.2.1")
+	if err != nil {
+		t.Fatalf("expected the lockout to end, got %v", err)
+	}
+	code, _ := totpCode(secret, totpStep(now))
+	if _, _, err := manager.VerifyChallenge(challenge, code, "192.0.2.1"); err != nil {
+		t.Errorf("expected a correct code after the lockout, got %v", err)
+	}
+}
+
+func TestManagerTOTPLockoutPerAddress(t *testing.T) {
+	manager := NewManager(newMemoryStore(), time.Hour)
+	users := []string{"alice", "bob", "carol"}
+	for _, username := range users {
+		if _, err := manager.CreateUser(username, "correct horse", ""); err != nil {
+			t.Fatalf("CreateUser failed: %v", err)
+		}
+		enrollTOTP(t, manager, username)
+	}
+
+	// Spreading guesses over accounts still locks out the address
+	for i := 0; i < maxTOTPFailures; i++ {
+		username := users[i%2]
+		challenge, _, err := manager.Login(username, "correct horse", "192.0.2.1")
+		if err != nil {
+			t.Fatalf("Login %d failed: %v", i+1, err)
+		}
+		manager.VerifyChallenge(challenge, "000000", "192.0.2.1")
+	}
+	if _, _, err := manager.Login("carol", "correct horse", "192.0.2.1"); !errors.Is(err, ErrTooManyAttempts) {
+		t.Errorf("expected the address to be locked out, got %v", err)
+	}
+	if _, _, err := manager.Login("alice", "correct horse", "198.51.100.1"); err != nil {
+		t.Errorf("expected alice to sign in from another address, got %v", err)
+	}
+}
+
+func TestManagerRegenerateRecoveryCodes(t *testing.T) {
+	manager := NewManager(newMemoryStore(), time.Hour)
+	if _, err := manager.CreateUser("alice", "correct horse", ""); err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if _, err := manager.RegenerateRecoveryCodes("alice", "correct horse"); !errors.Is(err, ErrTOTPNotEnabled) {
+		t.Errorf("expected regenerating without TOTP to fail, got %v", err)
+	}
+
+	_, old := enrollTOTP(t, manager, "alice")
+	codes, err := manager.RegenerateRecoveryCodes("alice", "correct horse")
+	if err != nil {
+		t.Fatalf("RegenerateRecoveryCodes failed: %v", err)
+	}
+
+	user, _ := manager.GetUser("alice")
+	if _, ok := useRecoveryCode(user.RecoveryCodes, old[0]); ok {
+		t.Errorf("expected old recovery codes to stop working")
+	}
+	if _, ok := useRecoveryCode(user.RecoveryCodes, codes[0]); !ok {
+		t.Errorf("expected new recovery codes to work")
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+)
+
+// adminNetworkAllowed reports whether the client is in server.adminAllowlist,
+// or whether there is no allowlist
+func (s *Server) adminNetworkAllowed(c *fiber.Ctx) bool {
+	cfg := s.currentConfig()
+	if len(cfg.Server.AdminAllowlist) == 0 {
+		return true
+	}
+
+	// The allowlist is validated when the config loads
+	networks, err := monitors.ParseNetworks(cfg.Server.AdminAllowlist)
+	if err != nil {
+		return false
+	}
+
+	ip := s.clientIP(c)
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// requireAdminNetwork rejects admin requests from outside the admin allowlist,
+// whatever token they carry
+func (s *Server) requireAdminNetwork(c *fiber.Ctx) error {
+	if s.adminNetworkAllowed(c) {
+		return c.Next()
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"ip":     s.clientIP(c).String(),
+			"method": c.Method(),
+			"path":   c.Path(),
+		}).
+		Warn("Admin request from outside the allowlist")
+
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error":   true,
+		"message": "This endpoint is not reachable from your network",
+	})
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestAdminAllowlist(t *testing.T) {
+	// Test requests come from 0.0.0.0
+	tests := []struct {
+		name       string
+		allowlist  []string
+		path       string
+		wantStatus int
+	}{
+		{name: "no allowlist", path: "/api/v1/config", wantStatus: fiber.StatusOK},
+		{name: "allowed network", allowlist: []string{"10.0.0.0/8", "0.0.0.0/32"}, path: "/api/v1/config", wantStatus: fiber.StatusOK},
+		{name: "other network", allowlist: []string{"10.0.0.0/8"}, path: "/api/v1/config", wantStatus: fiber.StatusForbidden},
+		{name: "other network, admin page", allowlist: []string{"10.0.0.0/8"}, path: "/api/v1/templates", wantStatus: fiber.StatusForbidden},
+		{name: "other network, read endpoint", allowlist: []string{"10.0.0.0/8"}, path: "/api/v1/monitors", wantStatus: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(t)
+			defer server.app.Shutdown()
+			server.currentConfig().Server.AdminAllowlist = tt.allowlist
+
+			status, _ := doJSON(t, server, "GET", tt.path, nil, nil)
+			if status != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, status)
+			}
+		})
+	}
+}
+
+func TestAdminAllowlistWithTokens(t *testing.T) {
+	server := createYAMLTestServer(t, tenantTestConfig, nil)
+	defer server.app.Shutdown()
+	server.currentConfig().Server.AdminAllowlist = []string{"192.0.2.0/24"}
+
+	// A valid admin token does not get past the allowlist
+	if status, _ := doJSON(t, server, "GET", "/api/v1/config", nil, bearer("admin-token")); status != fiber.StatusForbidden {
+		t.Errorf("expected 403 outside the allowlist, got %d", status)
+	}
+	if status, _ := doJSON(t, server, "GET", "/api/v1/groups", nil, bearer("admin-token")); status != fiber.StatusOK {
+		t.Errorf("expected other endpoints to stay reachable, got %d", status)
+	}
+}
@@ -0,0 +1,374 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// annotationMemoryLimit bounds the annotations kept in memory
+const annotationMemoryLimit = 1000
+
+// AnnotationRequest represents a request to record an annotation
+type AnnotationRequest struct {
+	Text    string    `json:"text"`
+	Monitor string    `json:"monitor,omitempty"`
+	Group   string    `json:"group,omitempty"`
+	Tags    []string  `json:"tags,omitempty"`
+	Time    time.Time `json:"time,omitempty"`     // Defaults to now
+	TimeEnd time.Time `json:"time_end,omitempty"` // Optional end for ranged events
+}
+
+// GrafanaAnnotation is an annotation in the Grafana JSON datasource format
+type GrafanaAnnotation struct {
+	Time    int64    `json:"time"`
+	TimeEnd int64    `json:"timeEnd,omitempty"`
+	Title   string   `json:"title"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags"`
+}
+
+// annotationLog keeps recent annotations in memory and persists them when the
+// storage backend supports it
+type annotationLog struct {
+	mu         sync.RWMutex
+	recent     []*models.Annotation
+	persistent storage.AnnotationStore
+}
+
+// newAnnotationLog creates an annotation log, persisting to store if it can
+func newAnnotationLog(store storage.ResultStore) *annotationLog {
+	log := &annotationLog{}
+	if persistent, ok := store.(storage.AnnotationStore); ok {
+		log.persistent = persistent
+	}
+	return log
+}
+
+// Add records an annotation
+func (l *annotationLog) Add(annotation *models.Annotation) error {
+	if l.persistent != nil {
+		if err := l.persistent.StoreAnnotation(annotation); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	l.recent = append(l.recent, annotation)
+	sort.SliceStable(l.recent, func(i, j int) bool {
+		return l.recent[i].Time.Before(l.recent[j].Time)
+	})
+	if len(l.recent) > annotationMemoryLimit {
+		l.recent = l.recent[len(l.recent)-annotationMemoryLimit:]
+	}
+	l.mu.Unlock()
+
+	return nil
+}
+
+// Query returns annotations in [start, end] scoped to monitor and group; empty
+// monitor and group match every annotation
+func (l *annotationLog) Query(start, end time.Time, monitor, group string) ([]*models.Annotation, error) {
+	var candidates []*models.Annotation
+	if l.persistent != nil {
+		stored, err := l.persistent.GetAnnotations(start, end)
+		if err != nil {
+			return nil, err
+		}
+		candidates = stored
+	} else {
+		l.mu.RLock()
+		for _, annotation := range l.recent {
+			if !annotation.Time.Before(start) && !annotation.Time.After(end) {
+				candidates = append(candidates, annotation)
+			}
+		}
+		l.mu.RUnlock()
+	}
+
+	annotations := make([]*models.Annotation, 0, len(candidates))
+	for _, annotation := range candidates {
+		if monitor == "" && group == "" || annotation.Applies(monitor, group) {
+			annotations = append(annotations, annotation)
+		}
+	}
+	return annotations, nil
+}
+
+// annotateStall marks a scheduler stall on every monitor's timeline, so the
+// gap it left in their history is explained
+func (s *Server) annotateStall(stall scheduler.Stall) {
+	var text string
+	switch {
+	case stall.Kind == scheduler.StallLoop:
+		text = fmt.Sprintf("Checks stalled for %s", stall.Gap.Round(time.Second))
+	case stall.ClockJump < 0:
+		text = fmt.Sprintf("System clock went back %s", (-stall.ClockJump).Round(time.Second))
+	default:
+		text = fmt.Sprintf("System clock jumped %s; host suspended?", stall.ClockJump.Round(time.Second))
+	}
+	if stall.Missed > 0 {
+		text += fmt.Sprintf(" (%d checks missed)", stall.Missed)
+	}
+
+	annotation := &models.Annotation{
+		ID:      newAnnotationID(),
+		Time:    stall.Start,
+		TimeEnd: stall.End,
+		Text:    text,
+		Tags:    []string{"scheduler", stall.Kind},
+	}
+	if err := s.annotations.Add(annotation); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Warn("Failed to record scheduler stall annotation")
+	}
+}
+
+// newAnnotationID returns a random annotation identifier
+func newAnnotationID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+// createAnnotationHandler records a deploy marker or other event
+func (s *Server) createAnnotationHandler(c *fiber.Ctx) error {
+	var req AnnotationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	req.Text = strings.TrimSpace(req.Text)
+	if req.Text == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "text is required",
+		})
+	}
+	if req.Monitor != "" && req.Group != "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "monitor and group are mutually exclusive",
+		})
+	}
+	if requestTenant(c) != nil && req.Monitor == "" && req.Group == "" {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"message": "Tenant annotations require a monitor or group",
+		})
+	}
+	if req.Monitor != "" {
+		if _, _, found := s.currentConfig().FindMonitor(req.Monitor); !found || !s.canAccessMonitor(c, req.Monitor) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": fmt.Sprintf("Monitor %s not found", req.Monitor),
+			})
+		}
+	}
+	if req.Group != "" {
+		if _, found := s.currentConfig().FindGroup(req.Group); !found || !s.canAccessGroup(c, req.Group) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"success": false,
+				"message": fmt.Sprintf("Group %s not found", req.Group),
+			})
+		}
+	}
+
+	annotation := &models.Annotation{
+		ID:      newAnnotationID(),
+		Time:    req.Time,
+		TimeEnd: req.TimeEnd,
+		Monitor: req.Monitor,
+		Group:   req.Group,
+		Text:    req.Text,
+		Tags:    req.Tags,
+	}
+	if annotation.Time.IsZero() {
+		annotation.Time = time.Now()
+	}
+	if !annotation.TimeEnd.IsZero() && annotation.TimeEnd.Before(annotation.Time) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "time_end must be after time",
+		})
+	}
+
+	if err := s.annotations.Add(annotation); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to store annotation")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to store annotation",
+			"error":   err.Error(),
+		})
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"annotation": annotation.ID,
+			"monitor":    annotation.Monitor,
+			"group":      annotation.Group,
+			"tags":       annotation.Tags,
+		}).
+		Info("Annotation recorded")
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success":    true,
+		"annotation": annotation,
+	})
+}
+
+// grafanaAnnotationsHandler returns annotations in the Grafana JSON datasource
+// format. The range comes from from/to (RFC3339 or epoch milliseconds) and
+// defaults to the last 24 hours; monitor, group, and tags narrow the results.
+func (s *Server) grafanaAnnotationsHandler(c *fiber.Ctx) error {
+	end := time.Now()
+	start := end.Add(-24 * time.Hour)
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if start, err = parseAnnotationTime(from); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid from (use RFC3339 or epoch milliseconds)",
+			})
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if end, err = parseAnnotationTime(to); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid to (use RFC3339 or epoch milliseconds)",
+			})
+		}
+	}
+
+	monitor := c.Query("monitor")
+	group := c.Query("group")
+	if monitor != "" && !s.canAccessMonitor(c, monitor) || group != "" && !s.canAccessGroup(c, group) {
+		return c.JSON([]GrafanaAnnotation{})
+	}
+	if monitor != "" && group == "" {
+		if m := s.monitorManager.GetMonitorByName(monitor); m != nil {
+			group = m.GetGroup()
+		}
+	}
+
+	annotations, err := s.annotations.Query(start, end, monitor, group)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to get annotations")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to retrieve annotations",
+		})
+	}
+
+	var wantTags []string
+	if tags := c.Query("tags"); tags != "" {
+		wantTags = strings.Split(tags, ",")
+	}
+
+	response := make([]GrafanaAnnotation, 0, len(annotations))
+	for _, annotation := range annotations {
+		if !hasAllTags(annotation.Tags, wantTags) || !s.canAccessAnnotation(c, annotation) {
+			continue
+		}
+		response = append(response, toGrafanaAnnotation(annotation))
+	}
+
+	return c.JSON(response)
+}
+
+// canAccessAnnotation reports whether the request may see an annotation;
+// annotations without a monitor or group are visible to every tenant
+func (s *Server) canAccessAnnotation(c *fiber.Ctx, annotation *models.Annotation) bool {
+	switch {
+	case annotation.Monitor != "":
+		if requestTenant(c) == nil {
+			return true
+		}
+		monitor := s.monitorManager.GetMonitorByName(annotation.Monitor)
+		return monitor != nil && s.canAccessGroup(c, monitor.GetGroup())
+	case annotation.Group != "":
+		return s.canAccessGroup(c, annotation.Group)
+	}
+	return true
+}
+
+// toGrafanaAnnotation converts an annotation to the Grafana format
+func toGrafanaAnnotation(annotation *models.Annotation) GrafanaAnnotation {
+	title := "Annotation"
+	switch {
+	case annotation.Monitor != "":
+		title = annotation.Monitor
+	case annotation.Group != "":
+		title = annotation.Group
+	}
+
+	tags := annotation.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	grafana := GrafanaAnnotation{
+		Time:  annotation.Time.UnixMilli(),
+		Title: title,
+		Text:  annotation.Text,
+		Tags:  tags,
+	}
+	if !annotation.TimeEnd.IsZero() {
+		grafana.TimeEnd = annotation.TimeEnd.UnixMilli()
+	}
+	return grafana
+}
+
+// hasAllTags reports whether tags contains every entry of want
+func hasAllTags(tags, want []string) bool {
+	for _, w := range want {
+		w = strings.TrimSpace(w)
+		if w == "" {
+			continue
+		}
+		found := false
+		for _, tag := range tags {
+			if tag == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAnnotationTime parses an RFC3339 timestamp or epoch milliseconds
+func parseAnnotationTime(value string) (time.Time, error) {
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
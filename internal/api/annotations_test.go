@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// annotationTestConfig has two groups of monitors
+const annotationTestConfig = `
+server:
+  port: "7878"
+  host: "0.0.0.0"
+
+monitoring:
+  groups:
+    - name: "core"
+      monitors:
+        - type: "http"
+          name: "api"
+          url: "https://api.example.com"
+    - name: "edge"
+      monitors:
+        - type: "http"
+          name: "cdn"
+          url: "https://cdn.example.com"
+`
+
+func TestCreateAnnotationHandlerErrors(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{
+			name:       "missing text",
+			body:       map[string]interface{}{"monitor": "api"},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "monitor and group",
+			body:       map[string]interface{}{"text": "deploy", "monitor": "api", "group": "core"},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "unknown monitor",
+			body:       map[string]interface{}{"text": "deploy", "monitor": "missing"},
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name:       "unknown group",
+			body:       map[string]interface{}{"text": "deploy", "group": "missing"},
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name: "end before start",
+			body: map[string]interface{}{
+				"text":     "maintenance",
+				"time":     "2025-01-01T12:00:00Z",
+				"time_end": "2025-01-01T11:00:00Z",
+			},
+			wantStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "POST", "/api/v1/annotations", tt.body, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+		})
+	}
+}
+
+func TestAnnotationsInHistoryAndGrafana(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	for _, body := range []map[string]interface{}{
+		{"text": "api v2.3.1", "monitor": "api", "tags": []string{"deploy", "api"}},
+		{"text": "core config change", "group": "core", "tags": []string{"config"}},
+		{"text": "cdn purge", "monitor": "cdn", "tags": []string{"deploy"}},
+	} {
+		status, payload := doJSON(t, server, "POST", "/api/v1/annotations", body, nil)
+		if status != fiber.StatusCreated {
+			t.Fatalf("expected 201, got %d: %v", status, payload)
+		}
+		annotation := payload["annotation"].(map[string]interface{})
+		if annotation["id"] == "" || annotation["time"] == "" {
+			t.Fatalf("expected id and time to be set, got %v", annotation)
+		}
+	}
+
+	storeResult(t, server, &models.MonitorResult{
+		Monitor:   "api",
+		Type:      models.MonitorTypeHTTP,
+		Group:     "core",
+		Status:    models.StatusUp,
+		Timestamp: time.Now().Add(-time.Minute),
+	})
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/monitors/api/history", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	annotations, ok := payload["annotations"].([]interface{})
+	if !ok {
+		t.Fatalf("expected annotations array, got %T", payload["annotations"])
+	}
+	if len(annotations) != 2 {
+		t.Fatalf("expected monitor and group annotations for api, got %v", annotations)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantTexts []string
+	}{
+		{name: "all", query: "", wantTexts: []string{"api v2.3.1", "core config change", "cdn purge"}},
+		{name: "by tag", query: "?tags=deploy", wantTexts: []string{"api v2.3.1", "cdn purge"}},
+		{name: "by monitor", query: "?monitor=cdn", wantTexts: []string{"cdn purge"}},
+		{name: "by group", query: "?group=core", wantTexts: []string{"core config change"}},
+		{name: "outside range", query: "?from=0&to=1000", wantTexts: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/annotations"+tt.query, nil)
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("expected status 200, got %d", resp.StatusCode)
+			}
+
+			var grafana []GrafanaAnnotation
+			if err := json.NewDecoder(resp.Body).Decode(&grafana); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(grafana) != len(tt.wantTexts) {
+				t.Fatalf("expected %d annotations, got %+v", len(tt.wantTexts), grafana)
+			}
+			for i, want := range tt.wantTexts {
+				if grafana[i].Text != want {
+					t.Errorf("annotation %d: expected %q, got %q", i, want, grafana[i].Text)
+				}
+				if grafana[i].Time == 0 {
+					t.Errorf("annotation %d: expected time in milliseconds", i)
+				}
+			}
+		})
+	}
+}
+
+func TestAnnotateStall(t *testing.T) {
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+
+	start := time.Date(2026, 3, 1, 2, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		stall scheduler.Stall
+		want  string
+	}{
+		{
+			name:  "loop",
+			stall: scheduler.Stall{Kind: scheduler.StallLoop, Gap: 90 * time.Second, Missed: 3},
+			want:  "Checks stalled for 1m30s (3 checks missed)",
+		},
+		{
+			name:  "suspend",
+			stall: scheduler.Stall{Kind: scheduler.StallClockJump, Gap: time.Hour, ClockJump: time.Hour},
+			want:  "System clock jumped 1h0m0s; host suspended?",
+		},
+		{
+			name:  "clock back",
+			stall: scheduler.Stall{Kind: scheduler.StallClockJump, ClockJump: -time.Minute},
+			want:  "System clock went back 1m0s",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.stall.Start = start.Add(time.Duration(i) * time.Hour * 2)
+			tt.stall.End = tt.stall.Start.Add(tt.stall.Gap)
+			server.annotateStall(tt.stall)
+
+			annotations, err := server.annotations.Query(tt.stall.Start, tt.stall.Start, "api", "core")
+			if err != nil {
+				t.Fatalf("Query() error = %v", err)
+			}
+			if len(annotations) != 1 {
+				t.Fatalf("expected 1 annotation applying to every monitor, got %d", len(annotations))
+			}
+			if got := annotations[0]; got.Text != tt.want || got.Tags[1] != tt.stall.Kind {
+				t.Errorf("annotation %q tags %v, want %q", got.Text, got.Tags, tt.want)
+			}
+		})
+	}
+}
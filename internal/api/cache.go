@@ -0,0 +1,138 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Cached figure kinds, used as the cache label on hit/miss metrics
+const (
+	cacheUptime  = "uptime"
+	cacheGroups  = "groups"
+	cacheGroup   = "group"
+	cacheStats   = "stats"
+	cacheHeatmap = "heatmap"
+)
+
+// cacheEntry is a cached value and the monitors it was computed from
+type cacheEntry struct {
+	value    interface{}
+	monitors []string
+	expires  time.Time
+}
+
+// responseCache holds computed dashboard figures for a short time so repeated
+// requests don't re-run historical queries. Entries are dropped as soon as one
+// of the monitors they depend on reports a new result, and all entries are
+// dropped when the configuration changes.
+type responseCache struct {
+	ttl       time.Duration
+	metrics   *metrics.Metrics
+	entries   map[string]*cacheEntry
+	byMonitor map[string]map[string]struct{}
+	mu        sync.Mutex
+}
+
+// newResponseCache creates a cache keeping entries for ttl; a ttl of zero
+// disables caching
+func newResponseCache(ttl time.Duration, m *metrics.Metrics) *responseCache {
+	return &responseCache{
+		ttl:       ttl,
+		metrics:   m,
+		entries:   make(map[string]*cacheEntry),
+		byMonitor: make(map[string]map[string]struct{}),
+	}
+}
+
+// Get returns a cached value of the given kind
+func (rc *responseCache) Get(kind, key string) (interface{}, bool) {
+	if rc.ttl <= 0 {
+		return nil, false
+	}
+
+	rc.mu.Lock()
+	entry, ok := rc.entries[cacheKey(kind, key)]
+	if ok && time.Now().After(entry.expires) {
+		rc.removeLocked(cacheKey(kind, key))
+		ok = false
+	}
+	rc.mu.Unlock()
+
+	if rc.metrics != nil {
+		rc.metrics.RecordCacheLookup(kind, ok)
+	}
+	if !ok {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set caches a value computed from the given monitors' results
+func (rc *responseCache) Set(kind, key string, value interface{}, monitors ...string) {
+	if rc.ttl <= 0 {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	k := cacheKey(kind, key)
+	rc.removeLocked(k)
+	rc.entries[k] = &cacheEntry{
+		value:    value,
+		monitors: monitors,
+		expires:  time.Now().Add(rc.ttl),
+	}
+	for _, monitor := range monitors {
+		keys, ok := rc.byMonitor[monitor]
+		if !ok {
+			keys = make(map[string]struct{})
+			rc.byMonitor[monitor] = keys
+		}
+		keys[k] = struct{}{}
+	}
+}
+
+// HandleResult drops entries computed from the result's monitor
+func (rc *responseCache) HandleResult(result *models.MonitorResult) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for k := range rc.byMonitor[result.Monitor] {
+		rc.removeLocked(k)
+	}
+}
+
+// Clear drops all entries
+func (rc *responseCache) Clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.entries = make(map[string]*cacheEntry)
+	rc.byMonitor = make(map[string]map[string]struct{})
+}
+
+// removeLocked drops an entry; callers must hold mu
+func (rc *responseCache) removeLocked(k string) {
+	entry, ok := rc.entries[k]
+	if !ok {
+		return
+	}
+	delete(rc.entries, k)
+
+	for _, monitor := range entry.monitors {
+		keys := rc.byMonitor[monitor]
+		delete(keys, k)
+		if len(keys) == 0 {
+			delete(rc.byMonitor, monitor)
+		}
+	}
+}
+
+// cacheKey combines a kind and key into a single map key
+func cacheKey(kind, key string) string {
+	return kind + "\x00" + key
+}
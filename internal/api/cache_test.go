@@ -0,0 +1,79 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestResponseCache(t *testing.T) {
+	m := metrics.NewMetrics(prometheus.NewRegistry())
+	cache := newResponseCache(time.Minute, m)
+
+	cache.Set(cacheUptime, "api/24h", 99.5, "api")
+	cache.Set(cacheGroup, "core", "core summary", "api", "db")
+	cache.Set(cacheGroups, "", "all groups")
+
+	if v, ok := cache.Get(cacheUptime, "api/24h"); !ok || v != 99.5 {
+		t.Fatalf("Get() = %v, %v; want cached value", v, ok)
+	}
+	if _, ok := cache.Get(cacheUptime, "api/7d"); ok {
+		t.Fatal("Expected a miss for an uncached key")
+	}
+
+	// A new result drops everything computed from that monitor
+	cache.HandleResult(&models.MonitorResult{Monitor: "api", Status: models.StatusUp})
+
+	tests := []struct {
+		kind, key string
+		wantHit   bool
+	}{
+		{kind: cacheUptime, key: "api/24h", wantHit: false},
+		{kind: cacheGroup, key: "core", wantHit: false},
+		{kind: cacheGroups, key: "", wantHit: true},
+	}
+	for _, tt := range tests {
+		if _, ok := cache.Get(tt.kind, tt.key); ok != tt.wantHit {
+			t.Errorf("Get(%s, %q) hit = %v, want %v", tt.kind, tt.key, ok, tt.wantHit)
+		}
+	}
+
+	cache.Clear()
+	if _, ok := cache.Get(cacheGroups, ""); ok {
+		t.Error("Expected Clear to drop all entries")
+	}
+
+	if hits := testutil.ToFloat64(m.CacheLookups.WithLabelValues(cacheUptime, "hit")); hits != 1 {
+		t.Errorf("uptime hits = %v, want 1", hits)
+	}
+	if misses := testutil.ToFloat64(m.CacheLookups.WithLabelValues(cacheUptime, "miss")); misses != 2 {
+		t.Errorf("uptime misses = %v, want 2", misses)
+	}
+}
+
+func TestResponseCacheExpiry(t *testing.T) {
+	cache := newResponseCache(20*time.Millisecond, nil)
+	cache.Set(cacheUptime, "api/24h", 99.5, "api")
+
+	time.Sleep(40 * time.Millisecond)
+	if _, ok := cache.Get(cacheUptime, "api/24h"); ok {
+		t.Fatal("Expected the entry to expire")
+	}
+	if len(cache.byMonitor) != 0 {
+		t.Errorf("Expected expired entries to be untracked, got %v", cache.byMonitor)
+	}
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	cache := newResponseCache(0, nil)
+	cache.Set(cacheUptime, "api/24h", 99.5, "api")
+
+	if _, ok := cache.Get(cacheUptime, "api/24h"); ok {
+		t.Error("Expected a disabled cache to never hit")
+	}
+}
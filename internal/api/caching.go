@@ -0,0 +1,45 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/compress"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+)
+
+// Cache-Control policies for API responses. Responses depend on the caller's
+// token or session, so only the browser may keep them.
+const (
+	// cacheRevalidate is for live status, reused only after an ETag check
+	cacheRevalidate = "private, no-cache"
+	// cacheShort is for history and statistics, which move at most once per
+	// check interval
+	cacheShort = "private, max-age=30"
+)
+
+// conditional answers If-None-Match with 304 when the response body is
+// unchanged. The ETag is weak since compression changes the bytes sent.
+var conditional = etag.New(etag.Config{Weak: true})
+
+// cacheControl sets a Cache-Control policy on successful responses
+func cacheControl(policy string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if status := c.Response().StatusCode(); status == fiber.StatusOK || status == fiber.StatusNotModified {
+			c.Set(fiber.HeaderCacheControl, policy)
+		}
+		return nil
+	}
+}
+
+// newCompression compresses responses with brotli or gzip as the client
+// accepts. The event stream is left alone so events are not held back.
+func newCompression() fiber.Handler {
+	return compress.New(compress.Config{
+		Next: func(c *fiber.Ctx) bool {
+			return c.Path() == "/api/v1/stream"
+		},
+		Level: compress.LevelDefault,
+	})
+}
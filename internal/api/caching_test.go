@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// request sends a request without a body and returns the response
+func request(t *testing.T, server *Server, method, path string, headers map[string]string) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestConditionalRequests(t *testing.T) {
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+	loadMonitors(t, server, []models.MonitorGroup{{
+		Name:     "core",
+		Monitors: []models.Monitor{{Type: models.MonitorTypeTCP, Name: "ssh", Target: "localhost:22"}},
+	}})
+
+	tests := []struct {
+		path         string
+		cacheControl string
+	}{
+		{path: "/api/v1/monitors", cacheControl: cacheRevalidate},
+		{path: "/api/v1/groups", cacheControl: cacheRevalidate},
+		{path: "/api/v1/monitors/ssh/history", cacheControl: cacheShort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp := request(t, server, "GET", tt.path, nil)
+			etag := resp.Header.Get(fiber.HeaderETag)
+			if resp.StatusCode != fiber.StatusOK || etag == "" {
+				t.Fatalf("expected 200 with an ETag, got %d %q", resp.StatusCode, etag)
+			}
+			if got := resp.Header.Get(fiber.HeaderCacheControl); got != tt.cacheControl {
+				t.Errorf("expected Cache-Control %q, got %q", tt.cacheControl, got)
+			}
+
+			resp = request(t, server, "GET", tt.path, map[string]string{fiber.HeaderIfNoneMatch: etag})
+			if resp.StatusCode != fiber.StatusNotModified {
+				t.Errorf("expected 304 for a matching ETag, got %d", resp.StatusCode)
+			}
+			if got := resp.Header.Get(fiber.HeaderCacheControl); got != tt.cacheControl {
+				t.Errorf("expected Cache-Control %q on 304, got %q", tt.cacheControl, got)
+			}
+		})
+	}
+
+	// Missing monitors are not cached
+	resp := request(t, server, "GET", "/api/v1/monitors/missing", nil)
+	if resp.Header.Get(fiber.HeaderETag) != "" || resp.Header.Get(fiber.HeaderCacheControl) != "" {
+		t.Errorf("expected no caching headers on 404")
+	}
+}
+
+func TestCompression(t *testing.T) {
+	tests := []struct {
+		name           string
+		enabled        bool
+		acceptEncoding string
+		want           string
+	}{
+		{name: "brotli", enabled: true, acceptEncoding: "gzip, br", want: "br"},
+		{name: "gzip", enabled: true, acceptEncoding: "gzip", want: "gzip"},
+		{name: "not accepted", enabled: true, want: ""},
+		{name: "disabled", acceptEncoding: "gzip, br", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Server: config.ServerConfig{Port: "7878", Compression: tt.enabled}}
+			server := NewServer(cfg, "config.yml", testLogger(t), prometheus.NewRegistry())
+			defer server.app.Shutdown()
+
+			// Small bodies are not worth compressing
+			monitors := make([]models.Monitor, 0, 20)
+			for i := 0; i < 20; i++ {
+				monitors = append(monitors, models.Monitor{Type: models.MonitorTypeTCP, Name: fmt.Sprintf("ssh-%d", i), Target: "localhost:22"})
+			}
+			loadMonitors(t, server, []models.MonitorGroup{{Name: "core", Monitors: monitors}})
+
+			resp := request(t, server, "GET", "/api/v1/monitors", map[string]string{fiber.HeaderAcceptEncoding: tt.acceptEncoding})
+			if got := resp.Header.Get(fiber.HeaderContentEncoding); got != tt.want {
+				t.Errorf("expected Content-Encoding %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+)
+
+// originMatcher decides whether a browser origin may call the API
+type originMatcher struct {
+	any        bool
+	exact      map[string]bool
+	subdomains []string // "scheme://" + "." + parent host, from "scheme://*.parent"
+}
+
+// newOriginMatcher builds a matcher from validated origins and "*." patterns
+func newOriginMatcher(origins []string) *originMatcher {
+	m := &originMatcher{exact: make(map[string]bool)}
+	for _, origin := range origins {
+		origin = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(origin)), "/")
+		switch {
+		case origin == "*":
+			m.any = true
+		case strings.Contains(origin, "://*."):
+			m.subdomains = append(m.subdomains, strings.Replace(origin, "://*.", "://.", 1))
+		default:
+			m.exact[origin] = true
+		}
+	}
+	return m
+}
+
+// allowed reports whether an Origin header value matches
+func (m *originMatcher) allowed(origin string) bool {
+	origin = strings.ToLower(origin)
+	if m.any || m.exact[origin] {
+		return true
+	}
+	for _, pattern := range m.subdomains {
+		scheme, parent, _ := strings.Cut(pattern, "://")
+		rest, ok := strings.CutPrefix(origin, scheme+"://")
+		if ok && strings.HasSuffix(rest, parent) && len(rest) > len(parent) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOrigin reports whether the Origin header names this server, as browsers
+// send it on same-site writes too
+func sameOrigin(c *fiber.Ctx, origin string) bool {
+	parsed, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(parsed.Host, string(c.Request().Host()))
+}
+
+// newCORS returns the CORS middleware for the configured policy. Without any
+// allowed origins every origin is allowed, unless the policy is strict.
+func (s *Server) newCORS(policy config.CORSConfig) []fiber.Handler {
+	matcher := newOriginMatcher(policy.AllowOrigins)
+	if len(policy.AllowOrigins) == 0 && !policy.Strict {
+		matcher.any = true
+	}
+
+	corsConfig := cors.Config{
+		AllowMethods:     strings.Join(policy.AllowMethods, ","),
+		AllowHeaders:     strings.Join(policy.AllowHeaders, ","),
+		ExposeHeaders:    strings.Join(policy.ExposeHeaders, ","),
+		AllowCredentials: policy.AllowCredentials,
+		MaxAge:           int(policy.MaxAge.ToDuration().Seconds()),
+	}
+	if matcher.any {
+		corsConfig.AllowOrigins = "*"
+	} else {
+		corsConfig.AllowOriginsFunc = matcher.allowed
+	}
+
+	var handlers []fiber.Handler
+	if policy.Strict {
+		handlers = append(handlers, func(c *fiber.Ctx) error {
+			origin := c.Get(fiber.HeaderOrigin)
+			if origin == "" || matcher.allowed(origin) || sameOrigin(c, origin) {
+				return c.Next()
+			}
+
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithFields(map[string]interface{}{
+					"origin": origin,
+					"method": c.Method(),
+					"path":   c.Path(),
+				}).
+				Warn("Rejected request from a disallowed origin")
+
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":   true,
+				"message": "Origin not allowed",
+			})
+		})
+	}
+	return append(handlers, cors.New(corsConfig))
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	matcher := newOriginMatcher([]string{"https://app.example.com/", "https://*.example.org", "http://*.local.test:8080"})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{origin: "https://app.example.com", want: true},
+		{origin: "HTTPS://APP.EXAMPLE.COM", want: true},
+		{origin: "http://app.example.com", want: false},
+		{origin: "https://status.example.org", want: true},
+		{origin: "https://a.b.example.org", want: true},
+		{origin: "https://example.org", want: false},
+		{origin: "https://evilexample.org", want: false},
+		{origin: "http://dash.local.test:8080", want: true},
+		{origin: "http://dash.local.test", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := matcher.allowed(tt.origin); got != tt.want {
+				t.Errorf("allowed(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSPolicy(t *testing.T) {
+	tests := []struct {
+		name            string
+		cors            config.CORSConfig
+		method          string
+		origin          string
+		wantStatus      int
+		wantAllowOrigin string
+		wantCredentials string
+		wantMaxAge      string
+	}{
+		{
+			name:            "allowed origin with credentials",
+			cors:            config.CORSConfig{AllowOrigins: []string{"https://*.example.com"}, AllowCredentials: true},
+			method:          "GET",
+			origin:          "https://ops.example.com",
+			wantStatus:      fiber.StatusOK,
+			wantAllowOrigin: "https://ops.example.com",
+			wantCredentials: "true",
+		},
+		{
+			name:       "disallowed origin gets no headers",
+			cors:       config.CORSConfig{AllowOrigins: []string{"https://ops.example.com"}},
+			method:     "GET",
+			origin:     "https://evil.example.net",
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name:       "strict rejects disallowed origin",
+			cors:       config.CORSConfig{AllowOrigins: []string{"https://ops.example.com"}, Strict: true},
+			method:     "GET",
+			origin:     "https://evil.example.net",
+			wantStatus: fiber.StatusForbidden,
+		},
+		{
+			name:       "strict allows same origin",
+			cors:       config.CORSConfig{Strict: true},
+			method:     "GET",
+			origin:     "http://example.com",
+			wantStatus: fiber.StatusOK,
+		},
+		{
+			name:            "preflight",
+			cors:            config.CORSConfig{AllowOrigins: []string{"https://ops.example.com"}, MaxAge: models.Duration(10 * time.Minute)},
+			method:          "OPTIONS",
+			origin:          "https://ops.example.com",
+			wantStatus:      fiber.StatusNoContent,
+			wantAllowOrigin: "https://ops.example.com",
+			wantMaxAge:      "600",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Server: config.ServerConfig{Port: "7878", CORS: tt.cors}}
+			server := NewServer(cfg, "config.yml", testLogger(t), prometheus.NewRegistry())
+			defer server.app.Shutdown()
+
+			headers := map[string]string{fiber.HeaderOrigin: tt.origin}
+			if tt.method == "OPTIONS" {
+				headers[fiber.HeaderAccessControlRequestMethod] = "PUT"
+			}
+			resp := request(t, server, tt.method, "/api/v1/monitors", headers)
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if got := resp.Header.Get(fiber.HeaderAccessControlAllowOrigin); got != tt.wantAllowOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", tt.wantAllowOrigin, got)
+			}
+			if got := resp.Header.Get(fiber.HeaderAccessControlAllowCredentials); got != tt.wantCredentials {
+				t.Errorf("expected Access-Control-Allow-Credentials %q, got %q", tt.wantCredentials, got)
+			}
+			if got := resp.Header.Get(fiber.HeaderAccessControlMaxAge); got != tt.wantMaxAge {
+				t.Errorf("expected Access-Control-Max-Age %q, got %q", tt.wantMaxAge, got)
+			}
+		})
+	}
+}
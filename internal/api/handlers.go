@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,6 +15,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+	"github.com/1broseidon/hallmonitor/internal/storage"
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
@@ -39,27 +42,37 @@ func (s *Server) readyHandler(c *fiber.Ctx) error {
 	})
 }
 
-// metricsHandler handles Prometheus metrics endpoint
+// metricsHandler handles Prometheus metrics endpoint. Scrapers that accept
+// OpenMetrics get it, which carries the exemplars linking latency histograms
+// to stored results.
 func (s *Server) metricsHandler(c *fiber.Ctx) error {
-	// Set content type for Prometheus metrics
-	c.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
-
 	// Create a buffer to capture the metrics
 	var buf bytes.Buffer
 
-	// Create a fake HTTP request and response writer
+	// Create a fake HTTP request and response writer, passing on the
+	// scraper's Accept header so the format is negotiated
 	req, _ := http.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", c.Get(fiber.HeaderAccept))
 	rw := &responseWriter{Buffer: &buf, header: make(http.Header)}
 
 	// Get the Prometheus handler for our custom registry and call it
-	gatherer, ok := s.prometheusReg.(prometheus.Gatherer)
+	registry, ok := s.prometheusReg.(prometheus.Gatherer)
 	if !ok {
 		return c.Status(500).SendString("Error: registry does not implement Gatherer interface")
 	}
-	handler := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+	gatherer := prometheus.Gatherers{registry}
+	if s.pushedMetrics != nil {
+		gatherer = append(gatherer, s.pushedMetrics)
+	}
+	handler := promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})
 	handler.ServeHTTP(rw, req)
 
-	// Return the captured metrics
+	// Return the captured metrics in the negotiated format
+	contentType := "text/plain; version=0.0.4; charset=utf-8"
+	if negotiated := rw.header.Get("Content-Type"); strings.HasPrefix(negotiated, "application/openmetrics-text") {
+		contentType = negotiated
+	}
+	c.Set("Content-Type", contentType)
 	return c.SendString(buf.String())
 }
 
@@ -85,10 +98,7 @@ func (rw *responseWriter) Write(data []byte) (int, error) {
 func (s *Server) dashboardHandler(c *fiber.Ctx) error {
 	c.Set("Content-Type", "text/html; charset=utf-8")
 
-	data := DashboardData{
-		IsAmbient:   false,
-		CurrentView: "dashboard",
-	}
+	data := s.dashboardData(c, false, "dashboard")
 
 	var buf bytes.Buffer
 	if err := dashboardTpl.Execute(&buf, data); err != nil {
@@ -102,10 +112,7 @@ func (s *Server) dashboardHandler(c *fiber.Ctx) error {
 func (s *Server) dashboardAmbientHandler(c *fiber.Ctx) error {
 	c.Set("Content-Type", "text/html; charset=utf-8")
 
-	data := DashboardData{
-		IsAmbient:   true,
-		CurrentView: "ambient",
-	}
+	data := s.dashboardData(c, true, "ambient")
 
 	var buf bytes.Buffer
 	if err := ambientTpl.Execute(&buf, data); err != nil {
@@ -119,10 +126,7 @@ func (s *Server) dashboardAmbientHandler(c *fiber.Ctx) error {
 func (s *Server) configPageHandler(c *fiber.Ctx) error {
 	c.Set("Content-Type", "text/html; charset=utf-8")
 
-	data := DashboardData{
-		IsAmbient:   false,
-		CurrentView: "config",
-	}
+	data := s.dashboardData(c, false, "config")
 
 	var buf bytes.Buffer
 	if err := configTpl.Execute(&buf, data); err != nil {
@@ -191,12 +195,32 @@ func extractHostnameAndIP(target, urlStr string) (hostname, ipAddr *string) {
 	return hostname, ipAddr
 }
 
-// getMonitorsHandler returns all monitor statuses
+// statusDisabled is reported for monitors that are configured but not
+// scheduled, instead of their last result's status
+const statusDisabled = "disabled"
+
+// getMonitorsHandler returns all monitor statuses. ?enabled=true or false
+// lists only enabled or disabled monitors.
 func (s *Server) getMonitorsHandler(c *fiber.Ctx) error {
-	monitors := s.monitorManager.GetMonitors()
+	monitors := s.accessibleMonitors(c)
+
+	var enabledFilter *bool
+	if raw := c.Query("enabled"); raw != "" {
+		enabled, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "enabled must be true or false",
+			})
+		}
+		enabledFilter = &enabled
+	}
 
 	var results []MonitorStatus
 	for _, monitor := range monitors {
+		if enabledFilter != nil && monitor.IsEnabled() != *enabledFilter {
+			continue
+		}
 		config := monitor.GetConfig()
 		status := MonitorStatus{
 			Name:    monitor.GetName(),
@@ -233,6 +257,9 @@ func (s *Server) getMonitorsHandler(c *fiber.Ctx) error {
 		if config.Count > 0 {
 			status.Count = &config.Count
 		}
+		if config.SampleRate > 1 {
+			status.SampleRate = &config.SampleRate
+		}
 		if config.ExpectedStatus > 0 {
 			status.ExpectedStatus = &config.ExpectedStatus
 		}
@@ -245,6 +272,10 @@ func (s *Server) getMonitorsHandler(c *fiber.Ctx) error {
 		if len(config.Labels) > 0 {
 			status.Labels = config.Labels
 		}
+		status.Owner = config.Owner
+		status.Team = config.Team
+		status.RunbookURL = config.RunbookURL
+		status.Description = config.Description
 
 		// Extract hostname and IP address
 		hostname, ipAddr := extractHostnameAndIP(config.Target, config.URL)
@@ -277,6 +308,9 @@ func (s *Server) getMonitorsHandler(c *fiber.Ctx) error {
 				status.DNSResult = latestResult.DNSResult
 			}
 		}
+		if !status.Enabled {
+			status.Status = statusDisabled
+		}
 
 		results = append(results, status)
 	}
@@ -335,6 +369,9 @@ func (s *Server) getMonitorHandler(c *fiber.Ctx) error {
 	if config.Count > 0 {
 		status.Count = &config.Count
 	}
+	if config.SampleRate > 1 {
+		status.SampleRate = &config.SampleRate
+	}
 	if config.ExpectedStatus > 0 {
 		status.ExpectedStatus = &config.ExpectedStatus
 	}
@@ -347,6 +384,10 @@ func (s *Server) getMonitorHandler(c *fiber.Ctx) error {
 	if len(config.Labels) > 0 {
 		status.Labels = config.Labels
 	}
+	status.Owner = config.Owner
+	status.Team = config.Team
+	status.RunbookURL = config.RunbookURL
+	status.Description = config.Description
 
 	// Extract hostname and IP address
 	hostname, ipAddr := extractHostnameAndIP(config.Target, config.URL)
@@ -379,13 +420,25 @@ func (s *Server) getMonitorHandler(c *fiber.Ctx) error {
 			status.DNSResult = latestResult.DNSResult
 		}
 	}
+	if !status.Enabled {
+		status.Status = statusDisabled
+	}
+
+	if pressure, ok := s.scheduler.Timeouts().Pressure(monitor.GetName()); ok {
+		status.TimeoutPressure = &pressure
+	}
 
 	return c.JSON(status)
 }
 
 // getGroupsHandler returns all group statuses
 func (s *Server) getGroupsHandler(c *fiber.Ctx) error {
-	groups := s.monitorManager.GetGroups()
+	scope := requestCacheScope(c)
+	if cached, ok := s.cache.Get(cacheGroups, scope); ok {
+		return c.JSON(cached)
+	}
+
+	groups := s.accessibleGroups(c)
 
 	var results []GroupStatus
 	for _, groupName := range groups {
@@ -393,21 +446,30 @@ func (s *Server) getGroupsHandler(c *fiber.Ctx) error {
 
 		status := GroupStatus{
 			Name:     groupName,
+			Enabled:  s.groupEnabled(groupName),
 			Monitors: len(monitors),
 			Status:   "unknown", // TODO: Calculate group status
 		}
 		results = append(results, status)
 	}
 
-	return c.JSON(fiber.Map{
+	// Group summaries only change with the configuration
+	response := fiber.Map{
 		"groups": results,
 		"total":  len(results),
-	})
+	}
+	s.cache.Set(cacheGroups, scope, response)
+
+	return c.JSON(response)
 }
 
 // getGroupHandler returns specific group status
 func (s *Server) getGroupHandler(c *fiber.Ctx) error {
 	groupName := c.Params("name")
+	if cached, ok := s.cache.Get(cacheGroup, groupName); ok {
+		return c.JSON(cached)
+	}
+
 	monitors := s.monitorManager.GetMonitorsByGroup(groupName)
 
 	if len(monitors) == 0 {
@@ -418,7 +480,9 @@ func (s *Server) getGroupHandler(c *fiber.Ctx) error {
 	}
 
 	var monitorStatuses []MonitorStatus
+	monitorNames := make([]string, 0, len(monitors))
 	for _, monitor := range monitors {
+		monitorNames = append(monitorNames, monitor.GetName())
 		status := MonitorStatus{
 			Name:    monitor.GetName(),
 			Type:    string(monitor.GetType()),
@@ -439,20 +503,27 @@ func (s *Server) getGroupHandler(c *fiber.Ctx) error {
 			}
 			status.Metadata = latestResult.Metadata
 		}
+		if !status.Enabled {
+			status.Status = statusDisabled
+		}
 
 		monitorStatuses = append(monitorStatuses, status)
 	}
 
 	groupStatus := GroupStatus{
 		Name:     groupName,
+		Enabled:  s.groupEnabled(groupName),
 		Monitors: len(monitors),
 		Status:   "unknown", // TODO: Calculate group status
 	}
 
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"group":    groupStatus,
 		"monitors": monitorStatuses,
-	})
+	}
+	s.cache.Set(cacheGroup, groupName, response, monitorNames...)
+
+	return c.JSON(response)
 }
 
 // reloadConfigHandler handles configuration reload requests
@@ -460,7 +531,8 @@ func (s *Server) reloadConfigHandler(c *fiber.Ctx) error {
 	s.logger.WithComponent("api").Info("Config reload requested")
 
 	// Reload configuration
-	if err := s.ReloadConfig(c.Context()); err != nil {
+	diff, err := s.ReloadConfig(c.Context())
+	if err != nil {
 		s.logger.WithComponent("api").WithError(err).Error("Failed to reload configuration")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"success": false,
@@ -474,41 +546,49 @@ func (s *Server) reloadConfigHandler(c *fiber.Ctx) error {
 		"message":        "Configuration reloaded successfully",
 		"total_monitors": len(s.monitorManager.GetMonitors()),
 		"total_groups":   len(s.monitorManager.GetGroups()),
+		"revision":       s.ConfigRevision(),
+		"changes":        diff,
 	})
 }
 
 // getConfigHandler returns current configuration (sanitized)
 func (s *Server) getConfigHandler(c *fiber.Ctx) error {
+	cfg := s.currentConfig()
+	revision := s.ConfigRevision()
+	c.Set(fiber.HeaderETag, strconv.Quote(revision))
+
 	// Return sanitized configuration without sensitive data
 	return c.JSON(fiber.Map{
+		"revision": revision,
+		"profile":  cfg.Profile,
 		"server": fiber.Map{
-			"port":            s.config.Server.Port,
-			"host":            s.config.Server.Host,
-			"enableDashboard": s.config.Server.EnableDashboard,
+			"port":            cfg.Server.Port,
+			"host":            cfg.Server.Host,
+			"enableDashboard": cfg.Server.EnableDashboard,
 		},
-		"metrics": s.config.Metrics,
+		"metrics": cfg.Metrics,
 		"logging": fiber.Map{
-			"level":  s.config.Logging.Level,
-			"format": s.config.Logging.Format,
+			"level":  cfg.Logging.Level,
+			"format": cfg.Logging.Format,
 		},
 		"monitoring": fiber.Map{
-			"defaultInterval": s.config.Monitoring.DefaultInterval,
-			"defaultTimeout":  s.config.Monitoring.DefaultTimeout,
-			"groups":          s.config.Monitoring.Groups,
+			"defaultInterval": cfg.Monitoring.DefaultInterval,
+			"defaultTimeout":  cfg.Monitoring.DefaultTimeout,
+			"groups":          cfg.Monitoring.Groups,
 		},
 		"storage": fiber.Map{
-			"backend": s.config.Storage.Backend,
+			"backend": cfg.Storage.Backend,
 			"badger": fiber.Map{
-				"enabled":           s.config.Storage.Badger.Enabled,
-				"path":              s.config.Storage.Badger.Path,
-				"retentionDays":     s.config.Storage.Badger.RetentionDays,
-				"enableAggregation": s.config.Storage.Badger.EnableAggregation,
+				"enabled":           cfg.Storage.Badger.Enabled,
+				"path":              cfg.Storage.Badger.Path,
+				"retentionDays":     cfg.Storage.Badger.RetentionDays,
+				"enableAggregation": cfg.Storage.Badger.EnableAggregation,
 			},
 			// Legacy fields for backward compatibility
-			"enabled":           s.config.Storage.Enabled,
-			"path":              s.config.Storage.Path,
-			"retentionDays":     s.config.Storage.RetentionDays,
-			"enableAggregation": s.config.Storage.EnableAggregation,
+			"enabled":           cfg.Storage.Enabled,
+			"path":              cfg.Storage.Path,
+			"retentionDays":     cfg.Storage.RetentionDays,
+			"enableAggregation": cfg.Storage.EnableAggregation,
 		},
 	})
 }
@@ -521,7 +601,8 @@ func (s *Server) getConfigHandler(c *fiber.Ctx) error {
 // To fully implement:
 // 1. grafanaQueryHandler: Query time-series data from result store
 // 2. grafanaTagsHandler: Return available tag keys/values for filtering
-// 3. grafanaAnnotationsHandler: Return events/annotations for timeline
+//
+// grafanaAnnotationsHandler (annotations.go) returns recorded annotations.
 //
 // For now, these return minimal responses to avoid errors in Grafana.
 // See: https://grafana.com/grafana/plugins/simpod-json-datasource/
@@ -538,12 +619,6 @@ func (s *Server) grafanaTagsHandler(c *fiber.Ctx) error {
 	return c.JSON([]interface{}{})
 }
 
-func (s *Server) grafanaAnnotationsHandler(c *fiber.Ctx) error {
-	// Placeholder: Return empty annotations
-	// Future: Return monitor state changes as timeline annotations
-	return c.JSON([]interface{}{})
-}
-
 // API response models
 
 // MonitorStatus represents the status of a single monitor
@@ -571,6 +646,13 @@ type MonitorStatus struct {
 	ExpectedResponse *string           `json:"expected_response,omitempty"`
 	Headers          map[string]string `json:"headers,omitempty"`
 	Labels           map[string]string `json:"labels,omitempty"`
+	SampleRate       *int              `json:"sample_rate,omitempty"` // One in this many successful results is persisted
+
+	// Ownership and contact details
+	Owner       string `json:"owner,omitempty"`
+	Team        string `json:"team,omitempty"`
+	RunbookURL  string `json:"runbook_url,omitempty"`
+	Description string `json:"description,omitempty"`
 
 	// Extracted/derived fields for easier frontend consumption
 	Hostname  *string `json:"hostname,omitempty"`
@@ -581,17 +663,33 @@ type MonitorStatus struct {
 	PingResult interface{} `json:"ping_result,omitempty"`
 	TCPResult  interface{} `json:"tcp_result,omitempty"`
 	DNSResult  interface{} `json:"dns_result,omitempty"`
+
+	// How much of its timeout the monitor's recent checks used
+	TimeoutPressure *scheduler.TimeoutPressure `json:"timeout_pressure,omitempty"`
 }
 
 // GroupStatus represents the status of a monitor group
 type GroupStatus struct {
 	Name     string  `json:"name"`
+	Enabled  bool    `json:"enabled"`
 	Monitors int     `json:"monitors"`
 	Status   string  `json:"status"`
 	Uptime   *string `json:"uptime,omitempty"`
 }
 
-// getMonitorHistoryHandler returns historical results for a monitor
+// groupEnabled reports whether a group's monitors may be scheduled
+func (s *Server) groupEnabled(name string) bool {
+	for _, group := range s.currentConfig().Monitoring.Groups {
+		if group.Name == name {
+			return group.IsEnabled()
+		}
+	}
+	return true
+}
+
+// getMonitorHistoryHandler returns historical results for a monitor.
+// ?status= and ?errorContains= keep only matching results, filtered by the
+// storage backend so failure views need not download the full history.
 func (s *Server) getMonitorHistoryHandler(c *fiber.Ctx) error {
 	// Check if storage backend supports historical queries
 	if s.storage != nil {
@@ -658,8 +756,29 @@ func (s *Server) getMonitorHistoryHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	status, ok := statusQuery(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid status (use up, down or unknown)",
+		})
+	}
+	errorContains := strings.TrimSpace(c.Query("errorContains"))
+
 	// Get historical results
-	results, err := s.scheduler.GetHistoricalResults(monitorName, start, end, limit)
+	var results []*models.MonitorResult
+	if status == "" && errorContains == "" {
+		results, err = s.scheduler.GetHistoricalResults(monitorName, start, end, limit)
+	} else {
+		results, err = s.scheduler.SearchResults(storage.ResultQuery{
+			Status:        status,
+			ErrorContains: errorContains,
+			Monitors:      []string{monitorName},
+			Start:         start,
+			End:           end,
+			Limit:         limit,
+		})
+	}
 	if err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
@@ -669,13 +788,80 @@ func (s *Server) getMonitorHistoryHandler(c *fiber.Ctx) error {
 			"message": "Failed to retrieve historical data",
 		})
 	}
+	if results == nil {
+		results = []*models.MonitorResult{}
+	}
+
+	// Deploy markers and other events for the monitor or its group
+	var group string
+	if m := s.monitorManager.GetMonitorByName(monitorName); m != nil {
+		group = m.GetGroup()
+	}
+	annotations, err := s.annotations.Query(start, end, monitorName, group)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Warn("Failed to get annotations for history")
+		annotations = []*models.Annotation{}
+	}
 
 	return c.JSON(fiber.Map{
-		"monitor": monitorName,
-		"start":   start.Format(time.RFC3339),
-		"end":     end.Format(time.RFC3339),
-		"results": results,
-		"total":   len(results),
+		"monitor":     monitorName,
+		"start":       start.Format(time.RFC3339),
+		"end":         end.Format(time.RFC3339),
+		"results":     results,
+		"total":       len(results),
+		"annotations": annotations,
+	})
+}
+
+// getResultHandler returns a single stored result by the ID attached to
+// latency exemplars. Under /monitors/:name it looks in that monitor's results;
+// under /results it searches every monitor the caller can see.
+func (s *Server) getResultHandler(c *fiber.Ctx) error {
+	nanos, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid result ID",
+		})
+	}
+	timestamp := time.Unix(0, nanos)
+
+	names := []string{c.Params("name")}
+	if names[0] == "" {
+		names = names[:0]
+		for _, m := range s.monitorManager.GetMonitors() {
+			if s.canAccessMonitor(c, m.GetName()) {
+				names = append(names, m.GetName())
+			}
+		}
+	}
+
+	for _, name := range names {
+		result, err := s.scheduler.FindResult(name, timestamp)
+		if err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				WithFields(map[string]interface{}{"monitor": name}).
+				Error("Failed to look up result")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Failed to retrieve result",
+			})
+		}
+		if result != nil {
+			return c.JSON(fiber.Map{
+				"id":      result.ID(),
+				"monitor": name,
+				"result":  result,
+			})
+		}
+	}
+
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error":   true,
+		"message": "Result not found",
 	})
 }
 
@@ -705,43 +891,37 @@ func (s *Server) getMonitorUptimeHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	cacheKey := monitorName + "\x00" + periodStr
+	if cached, ok := s.cache.Get(cacheUptime, cacheKey); ok {
+		return c.JSON(cached)
+	}
+
 	// Get results for the period
 	start := time.Now().Add(-period)
 	end := time.Now()
 
-	results, err := s.scheduler.GetHistoricalResults(monitorName, start, end, 100000)
+	counts, err := s.scheduler.CountHistoricalResults(monitorName, start, end)
 	if err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
-			Error("Failed to get historical results for uptime")
+			Error("Failed to count historical results for uptime")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error":   true,
 			"message": "Failed to calculate uptime",
 		})
 	}
 
-	// Calculate uptime
-	totalChecks := len(results)
-	upChecks := 0
-	for _, result := range results {
-		if result.Status == models.StatusUp {
-			upChecks++
-		}
-	}
-
-	uptimePercent := 0.0
-	if totalChecks > 0 {
-		uptimePercent = float64(upChecks) / float64(totalChecks) * 100.0
-	}
-
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"monitor":        monitorName,
 		"period":         periodStr,
 		"start":          start.Format(time.RFC3339),
 		"end":            end.Format(time.RFC3339),
-		"total_checks":   totalChecks,
-		"up_checks":      upChecks,
-		"down_checks":    totalChecks - upChecks,
-		"uptime_percent": uptimePercent,
-	})
+		"total_checks":   counts.Total,
+		"up_checks":      counts.Up,
+		"down_checks":    counts.Down,
+		"uptime_percent": counts.UptimePercent(),
+	}
+	s.cache.Set(cacheUptime, cacheKey, response, monitorName)
+
+	return c.JSON(response)
 }
@@ -0,0 +1,446 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/accounts"
+	"github.com/1broseidon/hallmonitor/internal/i18n"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	// sessionCookie carries a signed-in user's session token
+	sessionCookie = "hallmonitor_session"
+	// challengeCookie carries a sign-in waiting for its two-factor code
+	challengeCookie = "hallmonitor_challenge"
+)
+
+// LoginRequest is a sign-in submitted as a form or JSON
+type LoginRequest struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"`
+	Next     string `json:"next,omitempty" form:"next"`
+}
+
+// PasswordChangeRequest changes the signed-in user's password
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// UserRequest creates or updates a user. On update an empty password keeps
+// the current one.
+type UserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Tenant   string `json:"tenant,omitempty"`
+}
+
+// UserInfo is a user as returned by the API, without the password hash
+type UserInfo struct {
+	Username          string    `json:"username"`
+	Tenant            string    `json:"tenant,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	TOTPEnabled       bool      `json:"totp_enabled"`
+}
+
+// loginPageData holds data passed to the sign-in page template. Challenge
+// asks for a two-factor code instead of the password.
+type loginPageData struct {
+	*i18n.Catalog
+	Username  string
+	Next      string
+	ErrorKey  string // Catalog key of the error shown, if any
+	Challenge bool
+}
+
+// newUserInfo returns the API view of a user
+func newUserInfo(user *models.User) UserInfo {
+	return UserInfo{
+		Username:          user.Username,
+		Tenant:            user.Tenant,
+		CreatedAt:         user.CreatedAt,
+		PasswordChangedAt: user.PasswordChangedAt,
+		TOTPEnabled:       user.TOTPEnabled(),
+	}
+}
+
+// accountsDisabled answers requests for account features when local accounts
+// are not enabled
+func accountsDisabled(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		"error":   true,
+		"message": "Local accounts are not enabled",
+	})
+}
+
+// safeRedirect returns next if it is a path on this server, or the dashboard
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/dashboard"
+	}
+	return next
+}
+
+// setSessionCookie stores a session token in the browser until it expires
+func setSessionCookie(c *fiber.Ctx, token string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+}
+
+// requireUser rejects requests that are not signed in as a local user
+func (s *Server) requireUser(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+	if requestUser(c) == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "This requires signing in as a user",
+		})
+	}
+	return c.Next()
+}
+
+// renderLoginPage serves the sign-in page with an optional error
+func (s *Server) renderLoginPage(c *fiber.Ctx, status int, data loginPageData) error {
+	data.Catalog = s.requestCatalog(c)
+
+	var buf bytes.Buffer
+	if err := loginTpl.Execute(&buf, data); err != nil {
+		return err
+	}
+	c.Type("html", "utf-8")
+	return c.Status(status).Send(buf.Bytes())
+}
+
+// loginPageHandler serves the sign-in page
+func (s *Server) loginPageHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+	return s.renderLoginPage(c, fiber.StatusOK, loginPageData{Next: safeRedirect(c.Query("next"))})
+}
+
+// loginHandler signs a user in. Forms are redirected to their next page or
+// shown the sign-in page again; JSON requests get the user as JSON.
+func (s *Server) loginHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+
+	isJSON := strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON)
+
+	var req LoginRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	req.Next = safeRedirect(req.Next)
+
+	token, session, err := s.accounts.Login(req.Username, req.Password, s.clientIP(c).String())
+	if err != nil {
+		status, key := fiber.StatusUnauthorized, "login.error.credentials"
+		switch {
+		case errors.Is(err, accounts.ErrTooManyAttempts):
+			status, key = fiber.StatusTooManyRequests, "login.error.locked"
+		case !errors.Is(err, accounts.ErrInvalidCredentials):
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Error("Failed to sign in")
+			status, key = fiber.StatusInternalServerError, "login.error.failed"
+		default:
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithFields(map[string]interface{}{
+					"username": req.Username,
+					"ip":       s.clientIP(c).String(),
+				}).
+				Warn("Failed sign-in attempt")
+		}
+
+		if isJSON {
+			return c.Status(status).JSON(fiber.Map{
+				"success": false,
+				"message": i18n.Get(i18n.DefaultLanguage).T(key),
+			})
+		}
+		return s.renderLoginPage(c, status, loginPageData{Username: req.Username, Next: req.Next, ErrorKey: key})
+	}
+
+	if session.Pending {
+		c.Cookie(&fiber.Cookie{
+			Name:     challengeCookie,
+			Value:    token,
+			Path:     "/login",
+			Expires:  session.ExpiresAt,
+			HTTPOnly: true,
+			Secure:   c.Protocol() == "https",
+			SameSite: fiber.CookieSameSiteStrictMode,
+		})
+		if isJSON {
+			return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+				"success":       true,
+				"totp_required": true,
+				"message":       "Enter a two-factor code at /login/verify",
+			})
+		}
+		return s.renderLoginPage(c, fiber.StatusOK, loginPageData{Next: req.Next, Challenge: true})
+	}
+
+	return s.completeLogin(c, token, session, req.Next, isJSON)
+}
+
+// completeLogin sets the session cookie of a finished sign-in and answers the
+// sign-in request
+func (s *Server) completeLogin(c *fiber.Ctx, token string, session *models.Session, next string, isJSON bool) error {
+	setSessionCookie(c, token, session.ExpiresAt)
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{"username": session.Username}).
+		Info("User signed in")
+
+	if isJSON {
+		user, err := s.accounts.GetUser(session.Username)
+		if err != nil || user == nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to sign in",
+			})
+		}
+		return c.JSON(fiber.Map{
+			"success":    true,
+			"user":       newUserInfo(user),
+			"expires_at": session.ExpiresAt,
+		})
+	}
+	return c.Redirect(next, fiber.StatusSeeOther)
+}
+
+// logoutHandler ends the current session
+func (s *Server) logoutHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+
+	if err := s.accounts.Logout(c.Cookies(sessionCookie)); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Warn("Failed to end session")
+	}
+	c.ClearCookie(sessionCookie)
+
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		return c.JSON(fiber.Map{"success": true})
+	}
+	return c.Redirect("/login", fiber.StatusSeeOther)
+}
+
+// getSessionHandler describes who the request is authenticated as
+func (s *Server) getSessionHandler(c *fiber.Ctx) error {
+	response := fiber.Map{"admin": requestTenant(c) == nil}
+	if user := requestUser(c); user != nil {
+		response["user"] = newUserInfo(user)
+	}
+	if tenant := requestTenant(c); tenant != nil {
+		response["tenant"] = tenant.Name
+	}
+	return c.JSON(response)
+}
+
+// changePasswordHandler changes the signed-in user's password. Other sessions
+// of the user end; the current one is replaced.
+func (s *Server) changePasswordHandler(c *fiber.Ctx) error {
+	user := requestUser(c)
+
+	var req PasswordChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := s.accounts.ChangePassword(user.Username, req.CurrentPassword, req.NewPassword); err != nil {
+		return s.accountError(c, err, "Failed to change password")
+	}
+
+	token, session, err := s.accounts.StartSession(user.Username)
+	if err != nil {
+		return s.accountError(c, err, "Failed to start a new session")
+	}
+	setSessionCookie(c, token, session.ExpiresAt)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Password changed",
+	})
+}
+
+// getUsersHandler lists local users
+func (s *Server) getUsersHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+
+	users, err := s.accounts.ListUsers()
+	if err != nil {
+		return s.accountError(c, err, "Failed to list users")
+	}
+
+	infos := make([]UserInfo, 0, len(users))
+	for _, user := range users {
+		infos = append(infos, newUserInfo(user))
+	}
+	return c.JSON(fiber.Map{
+		"users": infos,
+		"total": len(infos),
+	})
+}
+
+// createUserHandler creates a local user
+func (s *Server) createUserHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+
+	var req UserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if req.Tenant != "" && s.currentConfig().GetTenant(req.Tenant) == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Unknown tenant " + req.Tenant,
+		})
+	}
+
+	user, err := s.accounts.CreateUser(req.Username, req.Password, req.Tenant)
+	if err != nil {
+		return s.accountError(c, err, "Failed to create user")
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"username": user.Username,
+			"tenant":   user.Tenant,
+		}).
+		Info("User created")
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"user":    newUserInfo(user),
+	})
+}
+
+// updateUserHandler changes a user's tenant or resets their password
+func (s *Server) updateUserHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+
+	var req UserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	if req.Tenant != "" && s.currentConfig().GetTenant(req.Tenant) == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Unknown tenant " + req.Tenant,
+		})
+	}
+
+	user, err := s.accounts.UpdateUser(c.Params("username"), req.Password, req.Tenant)
+	if err != nil {
+		return s.accountError(c, err, "Failed to update user")
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"user":    newUserInfo(user),
+	})
+}
+
+// deleteUserHandler deletes a user, ending their sessions
+func (s *Server) deleteUserHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+
+	username := c.Params("username")
+	if user := requestUser(c); user != nil && user.Username == username {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "You cannot delete your own account",
+		})
+	}
+
+	if err := s.accounts.DeleteUser(username); err != nil {
+		return s.accountError(c, err, "Failed to delete user")
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{"username": username}).
+		Info("User deleted")
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "User deleted",
+	})
+}
+
+// accountError maps account errors to responses, logging unexpected ones
+func (s *Server) accountError(c *fiber.Ctx, err error, message string) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, accounts.ErrUserNotFound):
+		status = fiber.StatusNotFound
+	case errors.Is(err, accounts.ErrUserExists):
+		status = fiber.StatusConflict
+	case errors.Is(err, accounts.ErrInvalidCredentials), errors.Is(err, accounts.ErrInvalidCode),
+		errors.Is(err, accounts.ErrChallengeExpired):
+		status = fiber.StatusUnauthorized
+	case errors.Is(err, accounts.ErrTOTPEnabled), errors.Is(err, accounts.ErrTOTPNotEnabled),
+		errors.Is(err, accounts.ErrTOTPNotPending):
+		status = fiber.StatusConflict
+	case errors.Is(err, accounts.ErrInvalidUsername), errors.Is(err, accounts.ErrWeakPassword):
+		status = fiber.StatusBadRequest
+	default:
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error(message)
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": message,
+		})
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"success": false,
+		"message": err.Error(),
+	})
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/accounts"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+)
+
+// createAccountsTestServer returns a tenant test server with local accounts:
+// admin has full access and alice belongs to tenant acme
+func createAccountsTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	server := createYAMLTestServer(t, tenantTestConfig, nil)
+	store, err := storage.NewBadgerStore(t.TempDir(), 7, server.logger)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	manager := accounts.NewManager(store, 0)
+	for _, user := range []struct{ name, tenant string }{{"admin", ""}, {"alice", "acme"}} {
+		if _, err := manager.CreateUser(user.name, "correct horse", user.tenant); err != nil {
+			t.Fatalf("failed to create user: %v", err)
+		}
+	}
+	server.SetAccountManager(manager)
+	return server
+}
+
+// login signs in with JSON and returns the session cookie
+func login(t *testing.T, server *Server, username, password string) *http.Cookie {
+	t.Helper()
+
+	body, _ := json.Marshal(LoginRequest{Username: username, Password: password})
+	req := httptest.NewRequest("POST", "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("expected sign-in to succeed, got %d", resp.StatusCode)
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == sessionCookie {
+			return cookie
+		}
+	}
+	t.Fatalf("expected a session cookie")
+	return nil
+}
+
+// withSession sends a request with a session cookie
+func withSession(t *testing.T, server *Server, method, path string, body interface{}, cookie *http.Cookie) (int, map[string]interface{}) {
+	t.Helper()
+	return doJSON(t, server, method, path, body, map[string]string{"Cookie": cookie.Name + "=" + cookie.Value})
+}
+
+func TestLoginHandler(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	status, _ := doJSON(t, server, "POST", "/login", LoginRequest{Username: "admin", Password: "wrong password"}, nil)
+	if status != fiber.StatusUnauthorized {
+		t.Errorf("expected 401 for a wrong password, got %d", status)
+	}
+
+	cookie := login(t, server, "admin", "correct horse")
+	if !cookie.HttpOnly {
+		t.Errorf("expected the session cookie to be HttpOnly")
+	}
+
+	status, payload := withSession(t, server, "GET", "/api/v1/session", nil, cookie)
+	if status != fiber.StatusOK || payload["admin"] != true {
+		t.Fatalf("expected an admin session, got %d %v", status, payload)
+	}
+	if user := payload["user"].(map[string]interface{}); user["username"] != "admin" || user["password_hash"] != nil {
+		t.Errorf("expected admin's user info without the hash, got %v", user)
+	}
+}
+
+func TestLoginFormRedirects(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	// Dashboard pages send anonymous visitors to sign in
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/dashboard/ambient", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if location := resp.Header.Get("Location"); resp.StatusCode != fiber.StatusFound || location != "/login?next=%2Fdashboard%2Fambient" {
+		t.Errorf("expected a redirect to sign in, got %d %q", resp.StatusCode, location)
+	}
+
+	tests := []struct {
+		name         string
+		password     string
+		next         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "valid", password: "correct horse", next: "/dashboard/ambient", wantStatus: fiber.StatusSeeOther, wantLocation: "/dashboard/ambient"},
+		{name: "offsite next", password: "correct horse", next: "//evil.example.com", wantStatus: fiber.StatusSeeOther, wantLocation: "/dashboard"},
+		{name: "wrong password", password: "wrong password", next: "/dashboard", wantStatus: fiber.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"username": {"alice"}, "password": {tt.password}, "next": {tt.next}}
+			req := httptest.NewRequest("POST", "/login", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if location := resp.Header.Get("Location"); location != tt.wantLocation {
+				t.Errorf("expected location %q, got %q", tt.wantLocation, location)
+			}
+		})
+	}
+}
+
+func TestSessionScope(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	if status, _ := doJSON(t, server, "GET", "/api/v1/monitors", nil, nil); status != fiber.StatusUnauthorized {
+		t.Errorf("expected 401 without a session, got %d", status)
+	}
+
+	cookie := login(t, server, "alice", "correct horse")
+	status, payload := withSession(t, server, "GET", "/api/v1/monitors", nil, cookie)
+	if status != fiber.StatusOK || payload["total"].(float64) != 1 {
+		t.Errorf("expected alice to see acme's one monitor, got %d %v", status, payload)
+	}
+	if status, _ := withSession(t, server, "GET", "/api/v1/users", nil, cookie); status != fiber.StatusForbidden {
+		t.Errorf("expected a tenant user to be refused user management, got %d", status)
+	}
+
+	// Tokens keep working alongside accounts
+	if status, _ := doJSON(t, server, "GET", "/api/v1/users", nil, bearer("admin-token")); status != fiber.StatusOK {
+		t.Errorf("expected admin tokens to manage users, got %d", status)
+	}
+}
+
+func TestChangePasswordHandler(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	cookie := login(t, server, "alice", "correct horse")
+
+	status, _ := withSession(t, server, "PUT", "/api/v1/session/password", PasswordChangeRequest{CurrentPassword: "wrong password", NewPassword: "battery staple"}, cookie)
+	if status != fiber.StatusUnauthorized {
+		t.Errorf("expected 401 for a wrong current password, got %d", status)
+	}
+	status, _ = withSession(t, server, "PUT", "/api/v1/session/password", PasswordChangeRequest{CurrentPassword: "correct horse", NewPassword: "short"}, cookie)
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for a weak password, got %d", status)
+	}
+	status, _ = withSession(t, server, "PUT", "/api/v1/session/password", PasswordChangeRequest{CurrentPassword: "correct horse", NewPassword: "battery staple"}, cookie)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected the password to change, got %d", status)
+	}
+
+	if status, _ := withSession(t, server, "GET", "/api/v1/session", nil, cookie); status != fiber.StatusUnauthorized {
+		t.Errorf("expected the old session to end, got %d", status)
+	}
+	login(t, server, "alice", "battery staple")
+
+	status, _ = doJSON(t, server, "PUT", "/api/v1/session/password", PasswordChangeRequest{}, bearer("admin-token"))
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected tokens to be refused a password change, got %d", status)
+	}
+}
+
+func TestUserManagementHandlers(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	cookie := login(t, server, "admin", "correct horse")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       interface{}
+		wantStatus int
+	}{
+		{name: "create", method: "POST", path: "/api/v1/users", body: UserRequest{Username: "bob", Password: "correct horse", Tenant: "globex"}, wantStatus: fiber.StatusCreated},
+		{name: "create duplicate", method: "POST", path: "/api/v1/users", body: UserRequest{Username: "bob", Password: "correct horse"}, wantStatus: fiber.StatusConflict},
+		{name: "create unknown tenant", method: "POST", path: "/api/v1/users", body: UserRequest{Username: "carol", Password: "correct horse", Tenant: "initech"}, wantStatus: fiber.StatusBadRequest},
+		{name: "create weak password", method: "POST", path: "/api/v1/users", body: UserRequest{Username: "carol", Password: "short"}, wantStatus: fiber.StatusBadRequest},
+		{name: "update", method: "PUT", path: "/api/v1/users/bob", body: UserRequest{Tenant: "acme"}, wantStatus: fiber.StatusOK},
+		{name: "update missing", method: "PUT", path: "/api/v1/users/carol", body: UserRequest{}, wantStatus: fiber.StatusNotFound},
+		{name: "delete self", method: "DELETE", path: "/api/v1/users/admin", wantStatus: fiber.StatusBadRequest},
+		{name: "delete", method: "DELETE", path: "/api/v1/users/bob", wantStatus: fiber.StatusOK},
+		{name: "delete missing", method: "DELETE", path: "/api/v1/users/bob", wantStatus: fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := withSession(t, server, tt.method, tt.path, tt.body, cookie)
+			if status != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+		})
+	}
+
+	_, payload := withSession(t, server, "GET", "/api/v1/users", nil, cookie)
+	if total := payload["total"].(float64); total != 2 {
+		t.Errorf("expected admin and alice to remain, got %v", payload["users"])
+	}
+}
+
+func TestLogoutHandler(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	cookie := login(t, server, "alice", "correct horse")
+	if status, _ := withSession(t, server, "POST", "/logout", nil, cookie); status != fiber.StatusOK {
+		t.Fatalf("expected sign-out to succeed, got %d", status)
+	}
+	if status, _ := withSession(t, server, "GET", "/api/v1/session", nil, cookie); status != fiber.StatusUnauthorized {
+		t.Errorf("expected the session to end, got %d", status)
+	}
+}
@@ -0,0 +1,196 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// aggregateDefaultRanges is how far back each period type looks without ?start=
+var aggregateDefaultRanges = map[string]time.Duration{
+	"hour":  24 * time.Hour,
+	"day":   30 * 24 * time.Hour,
+	"week":  26 * 7 * 24 * time.Hour,
+	"month": 365 * 24 * time.Hour,
+}
+
+// liveAggregator is implemented by aggregators that can compute the period
+// in progress on demand
+type liveAggregator interface {
+	LiveAggregate(monitor, periodType string, now time.Time) (*models.AggregateResult, error)
+}
+
+// getMonitorAggregatesHandler returns a monitor's stored aggregates
+// (?period=hour|day|week|month, default hour) that start within ?start= and
+// ?end=, oldest first, so rollups need not be recomputed from raw results.
+// The period in progress follows as a partial aggregate unless ?partial=false.
+func (s *Server) getMonitorAggregatesHandler(c *fiber.Ctx) error {
+	if s.storage == nil || !s.storage.Capabilities().SupportsAggregation {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error":   true,
+			"message": "Current storage backend does not support aggregates",
+			"hint":    "Enable BadgerDB storage in config.yml (set storage.backend to 'badger')",
+		})
+	}
+
+	monitorName := c.Params("name")
+	period := c.Query("period", "hour")
+	defaultRange, ok := aggregateDefaultRanges[period]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid period (use hour, day, week or month)",
+		})
+	}
+
+	end := time.Now()
+	if endStr := c.Query("end"); endStr != "" {
+		var err error
+		end, err = time.Parse(time.RFC3339, endStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid end timestamp format (use RFC3339)",
+			})
+		}
+	}
+	start := end.Add(-defaultRange)
+	if startStr := c.Query("start"); startStr != "" {
+		var err error
+		start, err = time.Parse(time.RFC3339, startStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid start timestamp format (use RFC3339)",
+			})
+		}
+	}
+	if end.Before(start) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "End time must be after start time",
+		})
+	}
+
+	aggregates, err := s.storage.GetAggregates(monitorName, period, start, end)
+	if errors.Is(err, storage.ErrNotSupported) {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error":   true,
+			"message": "Current storage backend does not support aggregates",
+		})
+	}
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			WithFields(map[string]interface{}{"monitor": monitorName, "period": period}).
+			Error("Failed to get aggregates")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to retrieve aggregates",
+		})
+	}
+	if aggregates == nil {
+		aggregates = []*models.AggregateResult{}
+	}
+	// Backends differ in order; always return the oldest period first
+	sort.Slice(aggregates, func(i, j int) bool {
+		return aggregates[i].PeriodStart.Before(aggregates[j].PeriodStart)
+	})
+
+	if live, ok := s.aggregator.(liveAggregator); ok && c.Query("partial") != "false" {
+		current, err := live.LiveAggregate(monitorName, period, time.Now())
+		if err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				WithFields(map[string]interface{}{"monitor": monitorName, "period": period}).
+				Warn("Failed to compute partial aggregate")
+		} else if current != nil && !current.PeriodStart.Before(start) && !current.PeriodStart.After(end) {
+			aggregates = append(aggregates, current)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"monitor":    monitorName,
+		"period":     period,
+		"start":      start.Format(time.RFC3339),
+		"end":        end.Format(time.RFC3339),
+		"aggregates": aggregates,
+		"total":      len(aggregates),
+	})
+}
+
+// aggregateRecomputer is implemented by aggregators that can regenerate the
+// aggregates of a time range
+type aggregateRecomputer interface {
+	Recompute(monitor string, from, to time.Time) (storage.RecomputeResult, error)
+}
+
+// recomputeAggregatesHandler regenerates the aggregates of ?monitor= (every
+// monitor when unset) for the periods overlapping ?from= to ?to= (default
+// now), after importing data or an aggregator outage
+func (s *Server) recomputeAggregatesHandler(c *fiber.Ctx) error {
+	recomputer, ok := s.aggregator.(aggregateRecomputer)
+	if !ok {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"success": false,
+			"message": "Aggregation is not enabled",
+			"hint":    "Use BadgerDB storage with storage.enableAggregation set",
+		})
+	}
+
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "from is required (use RFC3339)",
+		})
+	}
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid to timestamp format (use RFC3339)",
+			})
+		}
+	}
+	if !to.After(from) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "to must be after from",
+		})
+	}
+
+	monitor := c.Query("monitor")
+	summary, err := recomputer.Recompute(monitor, from, to)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			WithFields(map[string]interface{}{"monitor": monitor}).
+			Error("Failed to recompute aggregates")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to recompute aggregates",
+			"error":   err.Error(),
+			"result":  summary,
+		})
+	}
+
+	// Cached uptime figures may have been computed from the old aggregates
+	s.cache.Clear()
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("Recomputed %d hourly, %d daily, %d weekly and %d monthly aggregates for %d monitor(s)", summary.Hourly, summary.Daily, summary.Weekly, summary.Monthly, summary.Monitors),
+		"from":    from.Format(time.RFC3339),
+		"to":      to.Format(time.RFC3339),
+		"result":  summary,
+	})
+}
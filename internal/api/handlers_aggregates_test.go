@@ -0,0 +1,194 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// createAggregateTestServer serves a writable Badger store with its aggregator
+func createAggregateTestServer(t *testing.T) (*Server, *storage.BadgerStore) {
+	t.Helper()
+
+	store, err := storage.NewBadgerStore(t.TempDir(), 7, testLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	server := createYAMLTestServer(t, `
+server:
+  port: "7878"
+  host: "0.0.0.0"
+`, store)
+	return server, store
+}
+
+func TestGetMonitorAggregatesHandler(t *testing.T) {
+	server, store := createAggregateTestServer(t)
+	defer server.app.Shutdown()
+
+	hour := time.Now().UTC().Truncate(time.Hour)
+	for _, agg := range []*models.AggregateResult{
+		{Monitor: "api", PeriodType: "hour", PeriodStart: hour.Add(-time.Hour), PeriodEnd: hour, TotalChecks: 60, UpChecks: 60, UptimePercent: 100},
+		{Monitor: "api", PeriodType: "hour", PeriodStart: hour.Add(-3 * time.Hour), PeriodEnd: hour.Add(-2 * time.Hour), TotalChecks: 60, UpChecks: 30, DownChecks: 30, UptimePercent: 50},
+		{Monitor: "api", PeriodType: "hour", PeriodStart: hour.Add(-48 * time.Hour), PeriodEnd: hour.Add(-47 * time.Hour), TotalChecks: 60, UpChecks: 60, UptimePercent: 100},
+		{Monitor: "api", PeriodType: "day", PeriodStart: hour.Truncate(24 * time.Hour).Add(-24 * time.Hour), PeriodEnd: hour.Truncate(24 * time.Hour), TotalChecks: 1440, UpChecks: 1440, UptimePercent: 100},
+		{Monitor: "web", PeriodType: "hour", PeriodStart: hour.Add(-time.Hour), PeriodEnd: hour, TotalChecks: 60, UpChecks: 60, UptimePercent: 100},
+	} {
+		if err := store.StoreAggregate(agg); err != nil {
+			t.Fatalf("failed to store aggregate: %v", err)
+		}
+	}
+
+	// Defaults to the last day of hourly aggregates, oldest first
+	status, payload := doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	aggregates := payload["aggregates"].([]interface{})
+	if len(aggregates) != 2 || payload["total"] != float64(2) {
+		t.Fatalf("expected 2 hourly aggregates, got %v", payload)
+	}
+	if first := aggregates[0].(map[string]interface{}); first["uptime_percent"] != float64(50) || first["period_type"] != "hour" {
+		t.Errorf("expected the older aggregate first, got %v", first)
+	}
+
+	start := hour.Add(-72 * time.Hour).Format(time.RFC3339)
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates?period=hour&start="+start, nil, nil)
+	if status != fiber.StatusOK || payload["total"] != float64(3) {
+		t.Errorf("expected 3 aggregates since %s, got %d: %v", start, status, payload)
+	}
+
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates?period=day", nil, nil)
+	if status != fiber.StatusOK || payload["total"] != float64(1) {
+		t.Errorf("expected 1 daily aggregate, got %d: %v", status, payload)
+	}
+
+	week := hour.Truncate(7 * 24 * time.Hour).Add(-7 * 24 * time.Hour)
+	if err := store.StoreAggregate(&models.AggregateResult{Monitor: "api", PeriodType: "week", PeriodStart: week, PeriodEnd: week.Add(7 * 24 * time.Hour), TotalChecks: 10080, UpChecks: 10080, UptimePercent: 100}); err != nil {
+		t.Fatalf("failed to store aggregate: %v", err)
+	}
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates?period=week&partial=false", nil, nil)
+	if status != fiber.StatusOK || payload["total"] != float64(1) {
+		t.Errorf("expected 1 weekly aggregate, got %d: %v", status, payload)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "?period=year", want: fiber.StatusBadRequest},
+		{query: "?start=yesterday", want: fiber.StatusBadRequest},
+		{query: "?end=today", want: fiber.StatusBadRequest},
+		{query: "?start=2025-01-02T00:00:00Z&end=2025-01-01T00:00:00Z", want: fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if status, _ := doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates"+tt.query, nil, nil); status != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.query, tt.want, status)
+		}
+	}
+}
+
+func TestGetMonitorAggregatesHandlerWithoutStorage(t *testing.T) {
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+
+	if status, _ := doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates", nil, nil); status != fiber.StatusNotImplemented {
+		t.Errorf("expected 501 without storage, got %d", status)
+	}
+}
+
+func TestRecomputeAggregatesHandler(t *testing.T) {
+	server, store := createAggregateTestServer(t)
+	defer server.app.Shutdown()
+
+	hour := time.Now().UTC().Truncate(time.Hour).Add(-2 * time.Hour)
+	for i, status := range []models.MonitorStatus{models.StatusUp, models.StatusDown} {
+		if err := store.StoreResult(&models.MonitorResult{Monitor: "api", Status: status, Timestamp: hour.Add(time.Duration(i+1) * time.Minute)}); err != nil {
+			t.Fatalf("failed to store result: %v", err)
+		}
+	}
+
+	from := hour.Format(time.RFC3339)
+	status, payload := doJSON(t, server, "POST", "/api/v1/admin/aggregate/recompute?monitor=api&from="+from, nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if result := payload["result"].(map[string]interface{}); result["monitors"] != float64(1) || result["hourly"] != float64(1) {
+		t.Errorf("expected 1 hourly aggregate for 1 monitor, got %v", result)
+	}
+
+	aggregates, err := store.GetAggregates("api", "hour", hour, hour)
+	if err != nil || len(aggregates) != 1 || aggregates[0].UptimePercent != 50 {
+		t.Fatalf("expected a 50%% hourly aggregate, got %v (%v)", aggregates, err)
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "", want: fiber.StatusBadRequest},
+		{query: "?from=yesterday", want: fiber.StatusBadRequest},
+		{query: "?from=" + from + "&to=later", want: fiber.StatusBadRequest},
+		{query: "?from=" + from + "&to=" + from, want: fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if status, _ := doJSON(t, server, "POST", "/api/v1/admin/aggregate/recompute"+tt.query, nil, nil); status != tt.want {
+			t.Errorf("%q: expected %d, got %d", tt.query, tt.want, status)
+		}
+	}
+
+	// Without an aggregator there is nothing to recompute with
+	plain := createTestServer(t)
+	defer plain.app.Shutdown()
+	if status, _ := doJSON(t, plain, "POST", "/api/v1/admin/aggregate/recompute?from="+from, nil, nil); status != fiber.StatusNotImplemented {
+		t.Errorf("expected 501 without aggregation, got %d", status)
+	}
+}
+
+func TestGetMonitorAggregatesHandlerPartial(t *testing.T) {
+	server, store := createAggregateTestServer(t)
+	defer server.app.Shutdown()
+
+	hour := time.Now().UTC().Truncate(time.Hour)
+	if err := store.StoreAggregate(&models.AggregateResult{Monitor: "api", PeriodType: "hour", PeriodStart: hour.Add(-time.Hour), PeriodEnd: hour, TotalChecks: 60, UpChecks: 60, UptimePercent: 100}); err != nil {
+		t.Fatalf("failed to store aggregate: %v", err)
+	}
+	if err := store.StoreResult(&models.MonitorResult{Monitor: "api", Status: models.StatusDown, Timestamp: hour}); err != nil {
+		t.Fatalf("failed to store result: %v", err)
+	}
+
+	// The current hour so far follows the completed ones
+	status, payload := doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	aggregates := payload["aggregates"].([]interface{})
+	if len(aggregates) != 2 {
+		t.Fatalf("expected a stored and a partial aggregate, got %v", aggregates)
+	}
+	if stored := aggregates[0].(map[string]interface{}); stored["partial"] != nil {
+		t.Errorf("expected the stored aggregate not to be partial, got %v", stored)
+	}
+	live := aggregates[1].(map[string]interface{})
+	if live["partial"] != true || live["down_checks"] != float64(1) {
+		t.Errorf("expected a partial aggregate with the current hour's result, got %v", live)
+	}
+
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates?partial=false", nil, nil)
+	if status != fiber.StatusOK || payload["total"] != float64(1) {
+		t.Errorf("expected only the stored aggregate, got %d: %v", status, payload)
+	}
+
+	// Ranges ending before the current hour leave it out
+	end := hour.Add(-time.Minute).Format(time.RFC3339)
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/api/aggregates?end="+end, nil, nil)
+	if status != fiber.StatusOK || payload["total"] != float64(1) {
+		t.Errorf("expected only the stored aggregate before %s, got %d: %v", end, status, payload)
+	}
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+)
+
+// Bulk monitor actions
+const (
+	BulkActionEnable  = "enable"
+	BulkActionDisable = "disable"
+	BulkActionDelete  = "delete"
+	BulkActionMove    = "move"
+	BulkActionRestore = "restore"
+)
+
+// bulkActionVerbs describe completed actions in responses
+var bulkActionVerbs = map[string]string{
+	BulkActionEnable:  "enabled",
+	BulkActionDisable: "disabled",
+	BulkActionDelete:  "deleted",
+	BulkActionMove:    "moved",
+	BulkActionRestore: "restored",
+}
+
+// maxBulkMonitors bounds the monitors one bulk request may change
+const maxBulkMonitors = 1000
+
+// BulkMonitorRequest represents a request to change several monitors in one
+// config write. Restore puts back monitor definitions, replacing monitors of
+// the same name; it is how the undo returned by other actions is applied.
+type BulkMonitorRequest struct {
+	Action      string                 `json:"action"`
+	Monitors    []string               `json:"monitors,omitempty"`
+	GroupName   string                 `json:"group_name,omitempty"`  // Target group for move
+	Definitions []MonitorCreateRequest `json:"definitions,omitempty"` // Monitors to restore
+	Revision    *string                `json:"revision,omitempty"`
+}
+
+// bulkMonitorsHandler applies one action to several monitors. Either every
+// monitor changes or none does. The response holds an undo request that
+// restores the monitors as they were.
+func (s *Server) bulkMonitorsHandler(c *fiber.Ctx) error {
+	var req BulkMonitorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	if err := validateBulkRequest(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid bulk request",
+			"error":   err.Error(),
+		})
+	}
+
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, req.Revision); !ok {
+		return err
+	}
+
+	// Load current config
+	cfg, err := config.LoadConfig(s.configPath)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to load config for bulk monitor change")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	undo, err := applyBulkAction(cfg, &req)
+	if err != nil {
+		status := fiber.StatusNotFound
+		if errors.Is(err, config.ErrGeneratedMonitor) || req.Action == BulkActionRestore {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Failed to %s monitors", req.Action),
+			"error":   err.Error(),
+		})
+	}
+
+	// Validate modified config
+	if err := cfg.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Configuration validation failed",
+			"error":   err.Error(),
+		})
+	}
+
+	// Write config to file
+	if err := cfg.WriteConfig(s.configPath); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to write config after bulk monitor change")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	revision := s.ConfigRevision()
+
+	// Reload configuration
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to reload config after bulk monitor change")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
+		})
+	}
+
+	count := len(req.Monitors) + len(req.Definitions)
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"action":   req.Action,
+			"monitors": count,
+		}).
+		Info("Bulk monitor change applied")
+
+	message := fmt.Sprintf("%d monitor(s) %s", count, bulkActionVerbs[req.Action])
+	if req.Action == BulkActionMove {
+		message += " to " + req.GroupName
+	}
+	response := fiber.Map{
+		"success":  true,
+		"message":  message,
+		"revision": revision,
+	}
+	if undo != nil {
+		response["undo"] = undo
+	}
+	return c.JSON(response)
+}
+
+// validateBulkRequest checks a bulk request before the config is touched
+func validateBulkRequest(req *BulkMonitorRequest) error {
+	switch req.Action {
+	case BulkActionEnable, BulkActionDisable, BulkActionDelete, BulkActionMove:
+		if len(req.Monitors) == 0 {
+			return fmt.Errorf("monitors is required")
+		}
+		if len(req.Definitions) > 0 {
+			return fmt.Errorf("definitions are only used by %s", BulkActionRestore)
+		}
+	case BulkActionRestore:
+		if len(req.Definitions) == 0 {
+			return fmt.Errorf("definitions is required")
+		}
+		if len(req.Monitors) > 0 {
+			return fmt.Errorf("%s takes definitions, not monitors", BulkActionRestore)
+		}
+	default:
+		return fmt.Errorf("unknown action %q (want enable, disable, delete, move, or restore)", req.Action)
+	}
+	if req.Action == BulkActionMove && req.GroupName == "" {
+		return fmt.Errorf("group_name is required to move monitors")
+	}
+	if len(req.Monitors)+len(req.Definitions) > maxBulkMonitors {
+		return fmt.Errorf("at most %d monitors can be changed at once", maxBulkMonitors)
+	}
+
+	seen := make(map[string]bool, len(req.Monitors)+len(req.Definitions))
+	names := append([]string{}, req.Monitors...)
+	for _, definition := range req.Definitions {
+		names = append(names, definition.Monitor.Name)
+	}
+	for _, name := range names {
+		if name == "" {
+			return fmt.Errorf("monitor name is required")
+		}
+		if seen[name] {
+			return fmt.Errorf("monitor %s is listed more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// applyBulkAction changes cfg and returns the request that undoes the change.
+// Restores return no undo.
+func applyBulkAction(cfg *config.Config, req *BulkMonitorRequest) (*BulkMonitorRequest, error) {
+	if req.Action == BulkActionRestore {
+		for _, definition := range req.Definitions {
+			if _, _, found := cfg.FindMonitor(definition.Monitor.Name); found {
+				if err := cfg.DeleteMonitor(definition.Monitor.Name); err != nil {
+					return nil, err
+				}
+			}
+			if err := cfg.AddMonitor(definition.GroupName, definition.Monitor); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	undo := &BulkMonitorRequest{Action: BulkActionRestore}
+	for _, name := range req.Monitors {
+		groupIdx, monitorIdx, found := cfg.FindMonitor(name)
+		if !found {
+			return nil, fmt.Errorf("monitor %s not found", name)
+		}
+		undo.Definitions = append(undo.Definitions, MonitorCreateRequest{
+			GroupName: cfg.Monitoring.Groups[groupIdx].Name,
+			Monitor:   cfg.Monitoring.Groups[groupIdx].Monitors[monitorIdx],
+		})
+
+		var err error
+		switch req.Action {
+		case BulkActionEnable, BulkActionDisable:
+			err = cfg.SetMonitorEnabled(name, req.Action == BulkActionEnable)
+		case BulkActionDelete:
+			err = cfg.DeleteMonitor(name)
+		case BulkActionMove:
+			err = cfg.MoveMonitor(name, req.GroupName)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return undo, nil
+}
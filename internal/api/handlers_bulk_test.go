@@ -0,0 +1,156 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// bulkTestConfig is the config file behind tests of bulk operations
+const bulkTestConfig = `
+server:
+  port: "7878"
+
+monitoring:
+  defaultInterval: "30s"
+  defaultTimeout: "10s"
+  groups:
+    - name: "core"
+      monitors:
+        - type: "http"
+          name: "api"
+          url: "https://example.com"
+        - type: "tcp"
+          name: "db"
+          target: "localhost:5432"
+        - type: "tcp"
+          name: "cache"
+          target: "localhost:6379"
+    - name: "edge"
+      monitors:
+        - type: "http"
+          name: "cdn"
+          url: "https://cdn.example.com"
+`
+
+func TestBulkMonitorsHandlerValidation(t *testing.T) {
+	server := createYAMLTestServer(t, bulkTestConfig, nil)
+	revision := server.ConfigRevision()
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{name: "unknown action", body: map[string]interface{}{"action": "pause", "monitors": []string{"api"}, "revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "no monitors", body: map[string]interface{}{"action": "delete", "revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "duplicate monitors", body: map[string]interface{}{"action": "delete", "monitors": []string{"api", "api"}, "revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "move without group", body: map[string]interface{}{"action": "move", "monitors": []string{"api"}, "revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "restore without definitions", body: map[string]interface{}{"action": "restore", "revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "missing revision", body: map[string]interface{}{"action": "disable", "monitors": []string{"api"}}, wantStatus: fiber.StatusPreconditionRequired},
+		{name: "stale revision", body: map[string]interface{}{"action": "disable", "monitors": []string{"api"}, "revision": "stale"}, wantStatus: fiber.StatusConflict},
+		{name: "unknown monitor", body: map[string]interface{}{"action": "disable", "monitors": []string{"api", "missing"}, "revision": revision}, wantStatus: fiber.StatusNotFound},
+		{name: "unknown group", body: map[string]interface{}{"action": "move", "monitors": []string{"api"}, "group_name": "missing", "revision": revision}, wantStatus: fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "POST", "/api/v1/monitors/bulk", tt.body, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+		})
+	}
+
+	// Failed requests change nothing, not even the monitors before the bad one
+	if !server.monitorManager.GetMonitorByName("api").IsEnabled() {
+		t.Error("expected api to stay enabled after a failed bulk request")
+	}
+	if server.ConfigRevision() != revision {
+		t.Errorf("expected the revision to stay at %s, got %s", revision, server.ConfigRevision())
+	}
+}
+
+func TestBulkMonitorsHandlerUndo(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  map[string]interface{}
+		check func(t *testing.T, server *Server)
+	}{
+		{
+			name: "disable",
+			body: map[string]interface{}{"action": "disable", "monitors": []string{"api", "db"}},
+			check: func(t *testing.T, server *Server) {
+				for _, name := range []string{"api", "db"} {
+					if server.monitorManager.GetMonitorByName(name).IsEnabled() {
+						t.Errorf("expected %s to be disabled", name)
+					}
+				}
+				if !server.monitorManager.GetMonitorByName("cache").IsEnabled() {
+					t.Error("expected cache to stay enabled")
+				}
+			},
+		},
+		{
+			name: "delete",
+			body: map[string]interface{}{"action": "delete", "monitors": []string{"db", "cdn"}},
+			check: func(t *testing.T, server *Server) {
+				for _, name := range []string{"db", "cdn"} {
+					if server.monitorManager.GetMonitorByName(name) != nil {
+						t.Errorf("expected %s to be deleted", name)
+					}
+				}
+			},
+		},
+		{
+			name: "move",
+			body: map[string]interface{}{"action": "move", "monitors": []string{"api", "cache"}, "group_name": "edge"},
+			check: func(t *testing.T, server *Server) {
+				for _, name := range []string{"api", "cache"} {
+					if group := server.monitorManager.GetMonitorByName(name).GetGroup(); group != "edge" {
+						t.Errorf("expected %s in edge, got %s", name, group)
+					}
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createYAMLTestServer(t, bulkTestConfig, nil)
+
+			tt.body["revision"] = server.ConfigRevision()
+			status, payload := doJSON(t, server, "POST", "/api/v1/monitors/bulk", tt.body, nil)
+			if status != fiber.StatusOK {
+				t.Fatalf("expected 200, got %d: %v", status, payload)
+			}
+			tt.check(t, server)
+
+			undo, ok := payload["undo"].(map[string]interface{})
+			if !ok || undo["action"] != BulkActionRestore {
+				t.Fatalf("expected a restore undo, got %v", payload["undo"])
+			}
+			undo["revision"] = payload["revision"]
+			status, payload = doJSON(t, server, "POST", "/api/v1/monitors/bulk", undo, nil)
+			if status != fiber.StatusOK {
+				t.Fatalf("expected 200 undoing, got %d: %v", status, payload)
+			}
+			if _, ok := payload["undo"]; ok {
+				t.Errorf("expected no undo for a restore, got %v", payload["undo"])
+			}
+
+			// Every monitor is back in its group and enabled
+			want := map[string]string{"api": "core", "db": "core", "cache": "core", "cdn": "edge"}
+			for name, group := range want {
+				monitor := server.monitorManager.GetMonitorByName(name)
+				if monitor == nil {
+					t.Errorf("expected %s to be restored", name)
+					continue
+				}
+				if monitor.GetGroup() != group || !monitor.IsEnabled() {
+					t.Errorf("expected %s enabled in %s, got enabled=%v in %s", name, group, monitor.IsEnabled(), monitor.GetGroup())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// CertificateReport is the TLS certificate chain a monitor's latest check saw
+type CertificateReport struct {
+	Monitor       string               `json:"monitor"`
+	CheckedAt     time.Time            `json:"checked_at"`
+	ExpiresAt     time.Time            `json:"expires_at"` // Earliest expiry in the chain
+	DaysRemaining int                  `json:"days_remaining"`
+	Certificates  []models.Certificate `json:"certificates"` // Leaf first
+}
+
+// getMonitorCertsHandler returns the certificate chain from a monitor's
+// latest check
+func (s *Server) getMonitorCertsHandler(c *fiber.Ctx) error {
+	name := c.Params("name")
+	if s.monitorManager.GetMonitorByName(name) == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "Monitor not found",
+		})
+	}
+
+	result := s.scheduler.GetLatestResult(name)
+	if result == nil || result.HTTPResult == nil || len(result.HTTPResult.CertChain) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "No certificate data for this monitor",
+			"hint":    "Certificates are recorded by HTTPS monitors once they have been checked",
+		})
+	}
+
+	chain := result.HTTPResult.CertChain
+	report := CertificateReport{
+		Monitor:      name,
+		CheckedAt:    result.Timestamp,
+		ExpiresAt:    chain[0].NotAfter,
+		Certificates: chain,
+	}
+	for _, cert := range chain[1:] {
+		if cert.NotAfter.Before(report.ExpiresAt) {
+			report.ExpiresAt = cert.NotAfter
+		}
+	}
+	report.DaysRemaining = int(time.Until(report.ExpiresAt).Hours() / 24)
+
+	return c.JSON(report)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestGetMonitorCertsHandler(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	now := time.Now()
+	storeResult(t, server, &models.MonitorResult{
+		Monitor:   "api",
+		Type:      models.MonitorTypeHTTP,
+		Group:     "core",
+		Status:    models.StatusUp,
+		Timestamp: now,
+		HTTPResult: &models.HTTPResult{
+			StatusCode: 200,
+			CertChain: []models.Certificate{
+				{Subject: "CN=api.example.com", Issuer: "CN=Example CA", NotAfter: now.Add(60 * 24 * time.Hour), DNSNames: []string{"api.example.com"}},
+				{Subject: "CN=Example CA", Issuer: "CN=Example Root", NotAfter: now.Add(10*24*time.Hour + time.Hour), IsCA: true},
+			},
+		},
+	})
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/monitors/api/certs", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if certs := payload["certificates"].([]interface{}); len(certs) != 2 {
+		t.Fatalf("expected 2 certificates, got %d", len(certs))
+	}
+	// The intermediate expires first
+	if payload["days_remaining"] != float64(10) {
+		t.Errorf("expected 10 days remaining, got %v", payload["days_remaining"])
+	}
+
+	// The chain is part of the monitor detail too
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/api", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	httpResult := payload["http_result"].(map[string]interface{})
+	if chain, ok := httpResult["cert_chain"].([]interface{}); !ok || len(chain) != 2 {
+		t.Errorf("expected cert_chain in the monitor detail, got %v", httpResult["cert_chain"])
+	}
+
+	if status, _ := doJSON(t, server, "GET", "/api/v1/monitors/cdn/certs", nil, nil); status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for a monitor without certificate data, got %d", status)
+	}
+	if status, _ := doJSON(t, server, "GET", "/api/v1/monitors/missing/certs", nil, nil); status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for a missing monitor, got %d", status)
+	}
+}
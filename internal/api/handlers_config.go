@@ -1,45 +1,58 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
 
 	"github.com/1broseidon/hallmonitor/internal/config"
 	"github.com/1broseidon/hallmonitor/internal/logging"
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
-// MonitorCreateRequest represents a request to create a monitor
+// MonitorCreateRequest represents a request to create a monitor. It is also
+// the shape of an exported monitor definition, and may be sent as YAML.
 type MonitorCreateRequest struct {
-	GroupName string         `json:"group_name"` // Which group to add the monitor to
-	Monitor   models.Monitor `json:"monitor"`
+	GroupName string         `json:"group_name" yaml:"groupName"` // Which group to add the monitor to
+	Monitor   models.Monitor `json:"monitor" yaml:"monitor"`
+	Revision  *string        `json:"revision,omitempty" yaml:"revision,omitempty"` // Config revision the change is based on
 }
 
 // MonitorUpdateRequest represents a request to update a monitor
 type MonitorUpdateRequest struct {
-	Monitor models.Monitor `json:"monitor"`
+	Monitor  models.Monitor `json:"monitor"`
+	Revision *string        `json:"revision,omitempty"`
 }
 
 // GroupCreateRequest represents a request to create a group
 type GroupCreateRequest struct {
-	Group models.MonitorGroup `json:"group"`
+	Group    models.MonitorGroup `json:"group"`
+	Revision *string             `json:"revision,omitempty"`
 }
 
 // GroupUpdateRequest represents a request to update a group
 type GroupUpdateRequest struct {
-	Group models.MonitorGroup `json:"group"`
+	Group    models.MonitorGroup `json:"group"`
+	Revision *string             `json:"revision,omitempty"`
 }
 
 // ConfigUpdateRequest represents a request to update the entire config
 type ConfigUpdateRequest struct {
-	Config config.Config `json:"config"`
+	Config   config.Config `json:"config"`
+	Revision *string       `json:"revision,omitempty"`
 }
 
 // createMonitorHandler creates a new monitor
 func (s *Server) createMonitorHandler(c *fiber.Ctx) error {
 	var req MonitorCreateRequest
-	if err := c.BodyParser(&req); err != nil {
+	parse := func() error { return c.BodyParser(&req) }
+	if isYAML(c.Get(fiber.HeaderContentType)) {
+		parse = func() error { return yaml.Unmarshal(c.Body(), &req) }
+	}
+	if err := parse(); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"success": false,
 			"message": "Invalid request body",
@@ -62,6 +75,14 @@ func (s *Server) createMonitorHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, req.Revision); !ok {
+		return err
+	}
+
 	// Load current config
 	cfg, err := config.LoadConfig(s.configPath)
 	if err != nil {
@@ -105,15 +126,18 @@ func (s *Server) createMonitorHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	revision := s.ConfigRevision()
+
 	// Reload configuration
-	if err := s.ReloadConfig(c.Context()); err != nil {
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
 			Error("Failed to reload config after monitor creation")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Configuration saved but reload failed",
-			"error":   err.Error(),
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
 		})
 	}
 
@@ -125,9 +149,10 @@ func (s *Server) createMonitorHandler(c *fiber.Ctx) error {
 		Info("Monitor created successfully")
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"message": fmt.Sprintf("Monitor %s created successfully", req.Monitor.Name),
-		"monitor": req.Monitor,
+		"success":  true,
+		"message":  fmt.Sprintf("Monitor %s created successfully", req.Monitor.Name),
+		"monitor":  req.Monitor,
+		"revision": revision,
 	})
 }
 
@@ -150,6 +175,14 @@ func (s *Server) updateMonitorHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, req.Revision); !ok {
+		return err
+	}
+
 	// Load current config
 	cfg, err := config.LoadConfig(s.configPath)
 	if err != nil {
@@ -165,7 +198,11 @@ func (s *Server) updateMonitorHandler(c *fiber.Ctx) error {
 
 	// Update monitor in config
 	if err := cfg.UpdateMonitor(monitorName, req.Monitor); err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		status := fiber.StatusNotFound
+		if errors.Is(err, config.ErrGeneratedMonitor) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"success": false,
 			"message": "Failed to update monitor",
 			"error":   err.Error(),
@@ -193,15 +230,18 @@ func (s *Server) updateMonitorHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	revision := s.ConfigRevision()
+
 	// Reload configuration
-	if err := s.ReloadConfig(c.Context()); err != nil {
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
 			Error("Failed to reload config after monitor update")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Configuration saved but reload failed",
-			"error":   err.Error(),
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
 		})
 	}
 
@@ -212,12 +252,112 @@ func (s *Server) updateMonitorHandler(c *fiber.Ctx) error {
 		Info("Monitor updated successfully")
 
 	return c.JSON(fiber.Map{
-		"success": true,
-		"message": fmt.Sprintf("Monitor %s updated successfully", monitorName),
-		"monitor": req.Monitor,
+		"success":  true,
+		"message":  fmt.Sprintf("Monitor %s updated successfully", monitorName),
+		"monitor":  req.Monitor,
+		"revision": revision,
 	})
 }
 
+// setMonitorEnabledHandler returns a handler that enables or disables a
+// monitor in the config file. The config revision is sent in If-Match.
+func (s *Server) setMonitorEnabledHandler(enabled bool) fiber.Handler {
+	return s.setEnabledHandler("monitor", enabled, (*config.Config).SetMonitorEnabled)
+}
+
+// setGroupEnabledHandler returns a handler that enables or disables a whole
+// group in the config file. The config revision is sent in If-Match.
+func (s *Server) setGroupEnabledHandler(enabled bool) fiber.Handler {
+	return s.setEnabledHandler("group", enabled, (*config.Config).SetGroupEnabled)
+}
+
+// setEnabledHandler saves an enabled flag with set and reloads; kind names
+// what is being toggled in responses and logs
+func (s *Server) setEnabledHandler(kind string, enabled bool, set func(*config.Config, string, bool) error) fiber.Handler {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+
+	return func(c *fiber.Ctx) error {
+		name := c.Params("name")
+
+		// Serialize config writes and reject edits based on a stale revision
+		s.configMu.Lock()
+		defer s.configMu.Unlock()
+
+		if ok, err := s.requireConfigRevision(c, nil); !ok {
+			return err
+		}
+
+		// Load current config
+		cfg, err := config.LoadConfig(s.configPath)
+		if err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Errorf("Failed to load config to %s %s", action, kind)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to load configuration",
+				"error":   err.Error(),
+			})
+		}
+
+		if err := set(cfg, name, enabled); err != nil {
+			status := fiber.StatusNotFound
+			if errors.Is(err, config.ErrGeneratedMonitor) {
+				status = fiber.StatusBadRequest
+			}
+			return c.Status(status).JSON(fiber.Map{
+				"success": false,
+				"message": fmt.Sprintf("Failed to %s %s", action, kind),
+				"error":   err.Error(),
+			})
+		}
+
+		// Write config to file
+		if err := cfg.WriteConfig(s.configPath); err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Errorf("Failed to write config after %s %s", kind, action)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to save configuration",
+				"error":   err.Error(),
+			})
+		}
+
+		revision := s.ConfigRevision()
+
+		// Reload configuration
+		if _, err := s.reloadConfigLocked(c.Context()); err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Errorf("Failed to reload config after %s %s", kind, action)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success":  false,
+				"message":  "Configuration saved but reload failed",
+				"error":    err.Error(),
+				"revision": revision,
+			})
+		}
+
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithFields(map[string]interface{}{
+				kind:      name,
+				"enabled": enabled,
+			}).
+			Info("Enabled state changed")
+
+		return c.JSON(fiber.Map{
+			"success":  true,
+			"message":  fmt.Sprintf("%s %s %sd", strings.ToUpper(kind[:1])+kind[1:], name, action),
+			"enabled":  enabled,
+			"revision": revision,
+		})
+	}
+}
+
 // deleteMonitorHandler deletes a monitor
 func (s *Server) deleteMonitorHandler(c *fiber.Ctx) error {
 	monitorName := c.Params("name")
@@ -228,6 +368,14 @@ func (s *Server) deleteMonitorHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, nil); !ok {
+		return err
+	}
+
 	// Load current config
 	cfg, err := config.LoadConfig(s.configPath)
 	if err != nil {
@@ -243,7 +391,11 @@ func (s *Server) deleteMonitorHandler(c *fiber.Ctx) error {
 
 	// Delete monitor from config
 	if err := cfg.DeleteMonitor(monitorName); err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+		status := fiber.StatusNotFound
+		if errors.Is(err, config.ErrGeneratedMonitor) {
+			status = fiber.StatusBadRequest
+		}
+		return c.Status(status).JSON(fiber.Map{
 			"success": false,
 			"message": "Failed to delete monitor",
 			"error":   err.Error(),
@@ -271,15 +423,18 @@ func (s *Server) deleteMonitorHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	revision := s.ConfigRevision()
+
 	// Reload configuration
-	if err := s.ReloadConfig(c.Context()); err != nil {
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
 			Error("Failed to reload config after monitor deletion")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Configuration saved but reload failed",
-			"error":   err.Error(),
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
 		})
 	}
 
@@ -290,8 +445,9 @@ func (s *Server) deleteMonitorHandler(c *fiber.Ctx) error {
 		Info("Monitor deleted successfully")
 
 	return c.JSON(fiber.Map{
-		"success": true,
-		"message": fmt.Sprintf("Monitor %s deleted successfully", monitorName),
+		"success":  true,
+		"message":  fmt.Sprintf("Monitor %s deleted successfully", monitorName),
+		"revision": revision,
 	})
 }
 
@@ -313,6 +469,14 @@ func (s *Server) createGroupHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, req.Revision); !ok {
+		return err
+	}
+
 	// Load current config
 	cfg, err := config.LoadConfig(s.configPath)
 	if err != nil {
@@ -356,15 +520,18 @@ func (s *Server) createGroupHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	revision := s.ConfigRevision()
+
 	// Reload configuration
-	if err := s.ReloadConfig(c.Context()); err != nil {
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
 			Error("Failed to reload config after group creation")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Configuration saved but reload failed",
-			"error":   err.Error(),
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
 		})
 	}
 
@@ -375,9 +542,10 @@ func (s *Server) createGroupHandler(c *fiber.Ctx) error {
 		Info("Group created successfully")
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"success": true,
-		"message": fmt.Sprintf("Group %s created successfully", req.Group.Name),
-		"group":   req.Group,
+		"success":  true,
+		"message":  fmt.Sprintf("Group %s created successfully", req.Group.Name),
+		"group":    req.Group,
+		"revision": revision,
 	})
 }
 
@@ -400,6 +568,14 @@ func (s *Server) updateGroupHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, req.Revision); !ok {
+		return err
+	}
+
 	// Load current config
 	cfg, err := config.LoadConfig(s.configPath)
 	if err != nil {
@@ -443,15 +619,18 @@ func (s *Server) updateGroupHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	revision := s.ConfigRevision()
+
 	// Reload configuration
-	if err := s.ReloadConfig(c.Context()); err != nil {
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
 			Error("Failed to reload config after group update")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Configuration saved but reload failed",
-			"error":   err.Error(),
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
 		})
 	}
 
@@ -462,9 +641,10 @@ func (s *Server) updateGroupHandler(c *fiber.Ctx) error {
 		Info("Group updated successfully")
 
 	return c.JSON(fiber.Map{
-		"success": true,
-		"message": fmt.Sprintf("Group %s updated successfully", groupName),
-		"group":   req.Group,
+		"success":  true,
+		"message":  fmt.Sprintf("Group %s updated successfully", groupName),
+		"group":    req.Group,
+		"revision": revision,
 	})
 }
 
@@ -478,6 +658,14 @@ func (s *Server) deleteGroupHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, nil); !ok {
+		return err
+	}
+
 	// Load current config
 	cfg, err := config.LoadConfig(s.configPath)
 	if err != nil {
@@ -521,15 +709,18 @@ func (s *Server) deleteGroupHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	revision := s.ConfigRevision()
+
 	// Reload configuration
-	if err := s.ReloadConfig(c.Context()); err != nil {
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
 			Error("Failed to reload config after group deletion")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Configuration saved but reload failed",
-			"error":   err.Error(),
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
 		})
 	}
 
@@ -540,8 +731,9 @@ func (s *Server) deleteGroupHandler(c *fiber.Ctx) error {
 		Info("Group deleted successfully")
 
 	return c.JSON(fiber.Map{
-		"success": true,
-		"message": fmt.Sprintf("Group %s deleted successfully", groupName),
+		"success":  true,
+		"message":  fmt.Sprintf("Group %s deleted successfully", groupName),
+		"revision": revision,
 	})
 }
 
@@ -556,6 +748,25 @@ func (s *Server) updateConfigHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, req.Revision); !ok {
+		return err
+	}
+
+	// Access settings are never sent by the API, so keep the current ones
+	current := s.currentConfig()
+	req.Config.Server.AdminTokens = current.Server.AdminTokens
+	req.Config.Server.Accounts = current.Server.Accounts
+	req.Config.Server.AdminAllowlist = current.Server.AdminAllowlist
+	req.Config.Server.TrustedProxies = current.Server.TrustedProxies
+	req.Config.CopySecrets(current)
+
+	// Which executables may run is only set in the file
+	req.Config.Monitoring.PluginDir = current.Monitoring.PluginDir
+
 	// Validate the new config
 	if err := req.Config.Validate(); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -577,15 +788,18 @@ func (s *Server) updateConfigHandler(c *fiber.Ctx) error {
 		})
 	}
 
+	revision := s.ConfigRevision()
+
 	// Reload configuration
-	if err := s.ReloadConfig(c.Context()); err != nil {
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
 		s.logger.WithComponent(logging.ComponentAPI).
 			WithError(err).
 			Error("Failed to reload config after full update")
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"success": false,
-			"message": "Configuration saved but reload failed",
-			"error":   err.Error(),
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
 		})
 	}
 
@@ -597,5 +811,6 @@ func (s *Server) updateConfigHandler(c *fiber.Ctx) error {
 		"message":        "Configuration updated successfully",
 		"total_monitors": len(s.monitorManager.GetMonitors()),
 		"total_groups":   len(s.monitorManager.GetGroups()),
+		"revision":       revision,
 	})
 }
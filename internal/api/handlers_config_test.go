@@ -0,0 +1,379 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// configTestConfig is the config file behind tests of config writes
+const configTestConfig = `
+server:
+  port: "7878"
+  host: "0.0.0.0"
+
+monitoring:
+  defaultInterval: "30s"
+  defaultTimeout: "10s"
+  groups:
+    - name: "core"
+      monitors:
+        - type: "http"
+          name: "existing"
+          url: "https://example.com"
+`
+
+// doJSON sends a JSON request and decodes the response body
+func doJSON(t *testing.T, server *Server, method, path string, body interface{}, headers map[string]string) (int, map[string]interface{}) {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("failed to encode body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var payload map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp.StatusCode, payload
+}
+
+func newMonitorRequest(name string, revision *string) map[string]interface{} {
+	req := map[string]interface{}{
+		"group_name": "core",
+		"monitor": map[string]interface{}{
+			"type": "http",
+			"name": name,
+			"url":  "https://example.org",
+		},
+	}
+	if revision != nil {
+		req["revision"] = *revision
+	}
+	return req
+}
+
+// ifMatch returns headers carrying the current config revision
+func ifMatch(server *Server) map[string]string {
+	return map[string]string{"If-Match": strconv.Quote(server.ConfigRevision())}
+}
+
+func TestGetConfigHandlerReturnsRevision(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+	revision := server.ConfigRevision()
+
+	req := httptest.NewRequest("GET", "/api/v1/config", nil)
+	resp, err := server.app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if etag := resp.Header.Get("ETag"); etag != strconv.Quote(revision) {
+		t.Errorf("expected ETag %q, got %q", revision, etag)
+	}
+
+	var payload map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if payload["revision"] != revision {
+		t.Errorf("expected revision %s, got %v", revision, payload["revision"])
+	}
+}
+
+func TestConfigRevisionFollowsFile(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+	revision := server.ConfigRevision()
+
+	// A new process reading the same file agrees on the revision
+	restarted := NewServer(server.currentConfig(), server.configPath, server.logger, prometheus.NewRegistry())
+	defer restarted.app.Shutdown()
+	if restarted.ConfigRevision() != revision {
+		t.Errorf("expected the revision to survive a restart, got %s and %s", revision, restarted.ConfigRevision())
+	}
+
+	// Edits made outside the API make writes based on the old revision stale
+	data, err := os.ReadFile(server.configPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if err := os.WriteFile(server.configPath, append(data, []byte("# edited by hand\n")...), 0o644); err != nil {
+		t.Fatalf("failed to edit config: %v", err)
+	}
+	status, payload := doJSON(t, server, "POST", "/api/v1/monitors", newMonitorRequest("new", &revision), nil)
+	if status != fiber.StatusConflict {
+		t.Fatalf("expected 409 after an external edit, got %d: %v", status, payload)
+	}
+	if payload["revision"] == revision || payload["revision"] != server.ConfigRevision() {
+		t.Errorf("expected the conflict to report the edited file's revision, got %v", payload["revision"])
+	}
+}
+
+func TestConfigWritesRequireCurrentRevision(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	status, payload := doJSON(t, server, "POST", "/api/v1/monitors", newMonitorRequest("new", nil), nil)
+	if status != fiber.StatusPreconditionRequired {
+		t.Fatalf("expected 428 without revision, got %d: %v", status, payload)
+	}
+
+	stale := "stale"
+	status, payload = doJSON(t, server, "POST", "/api/v1/monitors", newMonitorRequest("new", &stale), nil)
+	if status != fiber.StatusConflict {
+		t.Fatalf("expected 409 for stale revision, got %d: %v", status, payload)
+	}
+	current := server.ConfigRevision()
+	if payload["revision"] != current {
+		t.Errorf("expected conflict to report current revision %s, got %v", current, payload["revision"])
+	}
+
+	status, payload = doJSON(t, server, "POST", "/api/v1/monitors", newMonitorRequest("new", &current), nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", status, payload)
+	}
+	if payload["revision"] == current || payload["revision"] != server.ConfigRevision() {
+		t.Errorf("expected a new revision after write, got %v", payload["revision"])
+	}
+
+	// Deletes carry the revision in If-Match
+	status, payload = doJSON(t, server, "DELETE", "/api/v1/monitors/new", nil, map[string]string{"If-Match": strconv.Quote(current)})
+	if status != fiber.StatusConflict {
+		t.Fatalf("expected 409 for stale If-Match, got %d: %v", status, payload)
+	}
+
+	written := server.ConfigRevision()
+	status, payload = doJSON(t, server, "DELETE", "/api/v1/monitors/new", nil, ifMatch(server))
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if server.ConfigRevision() == written {
+		t.Errorf("expected the delete to change the revision")
+	}
+}
+
+func TestUpdateConfigKeepsExecutables(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	// A config update cannot choose where plugins run from
+	revision := server.ConfigRevision()
+	status, payload := doJSON(t, server, "PUT", "/api/v1/config", map[string]interface{}{
+		"config": map[string]interface{}{
+			"server": map[string]interface{}{"port": "7878"},
+			"monitoring": map[string]interface{}{
+				"pluginDir": "/",
+				"groups": []map[string]interface{}{{
+					"name":     "core",
+					"monitors": []map[string]interface{}{{"type": "plugin", "name": "shell", "plugin": "bin/sh"}},
+				}},
+			},
+		},
+		"revision": revision,
+	}, nil)
+	if status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400, got %d: %v", status, payload)
+	}
+	if server.currentConfig().Monitoring.PluginDir != "" || server.monitorManager.GetMonitorByName("shell") != nil {
+		t.Error("expected the plugin directory to stay unset")
+	}
+
+}
+
+func TestConfigWritesKeepInheritedDefaultsOut(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	// Edit the group the way the dashboard does: from GET /config, which
+	// serves monitors with their defaults filled in
+	status, payload := doJSON(t, server, "GET", "/api/v1/config", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	group := payload["monitoring"].(map[string]interface{})["groups"].([]interface{})[0].(map[string]interface{})
+	group["retries"] = 2
+
+	status, payload = doJSON(t, server, "PUT", "/api/v1/groups/core", map[string]interface{}{"group": group}, ifMatch(server))
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200 updating the group, got %d: %v", status, payload)
+	}
+
+	data, err := os.ReadFile(server.configPath)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	var written struct {
+		Monitoring struct {
+			Groups []models.MonitorGroup `yaml:"groups"`
+		} `yaml:"monitoring"`
+	}
+	if err := yaml.Unmarshal(data, &written); err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	core := written.Monitoring.Groups[0]
+	if core.Retries != 2 || core.Interval != 0 || core.Timeout != 0 {
+		t.Errorf("expected the group written with retries 2 and no global defaults, got %+v", core)
+	}
+	existing := core.Monitors[0]
+	if existing.Retries != 0 || existing.Interval != 0 || existing.Timeout != 0 || existing.Enabled != nil {
+		t.Errorf("expected the monitor written without inherited defaults, got %+v", existing)
+	}
+
+	if retries := server.monitorManager.GetMonitorByName("existing").GetConfig().Retries; retries != 2 {
+		t.Errorf("expected the monitor to inherit retries 2 after reload, got %d", retries)
+	}
+}
+
+func TestConcurrentConfigWritesOnlyOneWins(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	const writers = 5
+	revision := server.ConfigRevision()
+	statuses := make([]int, writers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "concurrent-" + string(rune('a'+i))
+			statuses[i], _ = doJSON(t, server, "POST", "/api/v1/monitors", newMonitorRequest(name, &revision), nil)
+		}(i)
+	}
+	wg.Wait()
+
+	created, conflicts := 0, 0
+	for _, status := range statuses {
+		switch status {
+		case http.StatusCreated:
+			created++
+		case http.StatusConflict:
+			conflicts++
+		}
+	}
+
+	if created != 1 || conflicts != writers-1 {
+		t.Fatalf("expected 1 write and %d conflicts, got statuses %v", writers-1, statuses)
+	}
+
+	cfg, err := config.LoadConfig(server.configPath)
+	if err != nil {
+		t.Fatalf("failed to reload config: %v", err)
+	}
+	if len(cfg.Monitoring.Groups[0].Monitors) != 2 {
+		t.Errorf("expected 2 monitors on disk, got %d", len(cfg.Monitoring.Groups[0].Monitors))
+	}
+}
+
+func TestSetMonitorEnabledHandler(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	status, payload := doJSON(t, server, "POST", "/api/v1/monitors/existing/disable", nil, nil)
+	if status != fiber.StatusPreconditionRequired {
+		t.Fatalf("expected 428 without revision, got %d: %v", status, payload)
+	}
+	status, payload = doJSON(t, server, "POST", "/api/v1/monitors/missing/disable", nil, ifMatch(server))
+	if status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown monitor, got %d: %v", status, payload)
+	}
+
+	status, payload = doJSON(t, server, "POST", "/api/v1/monitors/existing/disable", nil, ifMatch(server))
+	if status != fiber.StatusOK || payload["enabled"] != false {
+		t.Fatalf("expected 200 disabling, got %d: %v", status, payload)
+	}
+
+	// Disabled monitors stay listed, flagged and filterable
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors", nil, nil)
+	if status != fiber.StatusOK || payload["total"] != float64(1) {
+		t.Fatalf("expected the disabled monitor to be listed, got %d: %v", status, payload)
+	}
+	monitor := payload["monitors"].([]interface{})[0].(map[string]interface{})
+	if monitor["enabled"] != false || monitor["status"] != statusDisabled {
+		t.Errorf("expected a disabled monitor, got %v", monitor)
+	}
+	if _, payload = doJSON(t, server, "GET", "/api/v1/monitors?enabled=true", nil, nil); payload["total"] != float64(0) {
+		t.Errorf("expected no enabled monitors, got %v", payload)
+	}
+	if _, payload = doJSON(t, server, "GET", "/api/v1/monitors?enabled=false", nil, nil); payload["total"] != float64(1) {
+		t.Errorf("expected one disabled monitor, got %v", payload)
+	}
+	if status, _ = doJSON(t, server, "GET", "/api/v1/monitors?enabled=maybe", nil, nil); status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid filter, got %d", status)
+	}
+
+	status, payload = doJSON(t, server, "POST", "/api/v1/monitors/existing/enable", nil, ifMatch(server))
+	if status != fiber.StatusOK || payload["enabled"] != true {
+		t.Fatalf("expected 200 enabling, got %d: %v", status, payload)
+	}
+	if !server.monitorManager.GetMonitorByName("existing").IsEnabled() {
+		t.Error("expected the monitor to be enabled after reload")
+	}
+}
+
+func TestSetGroupEnabledHandler(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	status, payload := doJSON(t, server, "POST", "/api/v1/groups/missing/disable", nil, ifMatch(server))
+	if status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown group, got %d: %v", status, payload)
+	}
+
+	status, payload = doJSON(t, server, "POST", "/api/v1/groups/core/disable", nil, ifMatch(server))
+	if status != fiber.StatusOK || payload["enabled"] != false {
+		t.Fatalf("expected 200 disabling, got %d: %v", status, payload)
+	}
+	if server.monitorManager.GetMonitorByName("existing").IsEnabled() {
+		t.Error("expected the group's monitors to be disabled")
+	}
+
+	status, payload = doJSON(t, server, "GET", "/api/v1/groups/core", nil, nil)
+	if status != fiber.StatusOK || payload["group"].(map[string]interface{})["enabled"] != false {
+		t.Fatalf("expected the group to be reported disabled, got %d: %v", status, payload)
+	}
+
+	// Enabling the monitor alone does not override its group
+	status, payload = doJSON(t, server, "POST", "/api/v1/monitors/existing/enable", nil, ifMatch(server))
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200 enabling the monitor, got %d: %v", status, payload)
+	}
+	if server.monitorManager.GetMonitorByName("existing").IsEnabled() {
+		t.Error("expected the monitor to stay disabled with its group")
+	}
+
+	status, payload = doJSON(t, server, "POST", "/api/v1/groups/core/enable", nil, ifMatch(server))
+	if status != fiber.StatusOK || payload["enabled"] != true {
+		t.Fatalf("expected 200 enabling, got %d: %v", status, payload)
+	}
+	if !server.monitorManager.GetMonitorByName("existing").IsEnabled() {
+		t.Error("expected the group's monitors to be enabled again")
+	}
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// CheckRequest is a monitor definition to check once, in the shape of
+// MonitorCreateRequest, so a form can test what it is about to save
+type CheckRequest struct {
+	GroupName string         `json:"group_name" yaml:"groupName"` // Group whose defaults apply
+	Monitor   models.Monitor `json:"monitor" yaml:"monitor"`
+}
+
+// debugCheckHandler runs one check of a monitor with tracing and returns the
+// result and trace. The check runs on a fresh copy of the monitor, so it opens
+// new connections, and its result is neither stored nor counted in metrics.
+func (s *Server) debugCheckHandler(c *fiber.Ctx) error {
+	// Copy the name since fiber reuses the buffer backing route params
+	monitorName := strings.Clone(c.Params("name"))
+	monitor, err := s.monitorManager.NewDetachedMonitor(monitorName)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create monitor for the debug check",
+			"error":   err.Error(),
+		})
+	}
+	if monitor == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Monitor %s not found", monitorName),
+		})
+	}
+
+	result, events, err := runTracedCheck(monitor)
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"monitor": monitorName,
+		}).
+		Info("Ran debug check")
+
+	response := fiber.Map{
+		"success": err == nil,
+		"monitor": monitorName,
+		"result":  result,
+		"trace":   events,
+	}
+	if err != nil {
+		response["error"] = err.Error()
+	}
+	return c.JSON(response)
+}
+
+// checkHandler runs one traced check of a monitor definition from the request
+// body, with the defaults of its group applied, without adding it to the
+// config. Like a debug check, the result is neither stored nor counted.
+func (s *Server) checkHandler(c *fiber.Ctx) error {
+	var req CheckRequest
+	parse := func() error { return c.BodyParser(&req) }
+	if isYAML(c.Get(fiber.HeaderContentType)) {
+		parse = func() error { return yaml.Unmarshal(c.Body(), &req) }
+	}
+	if err := parse(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	spec, err := s.currentConfig().PrepareMonitor(req.GroupName, req.Monitor)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid monitor",
+			"error":   err.Error(),
+		})
+	}
+	monitor, err := s.monitorManager.NewDetachedMonitorFromSpec(&spec, req.GroupName)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to create monitor",
+			"error":   err.Error(),
+		})
+	}
+
+	result, events, err := runTracedCheck(monitor)
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"monitor": spec.Name,
+			"type":    string(spec.Type),
+		}).
+		Info("Ran ad-hoc check")
+
+	response := fiber.Map{
+		"success": err == nil,
+		"monitor": spec.Name,
+		"result":  result,
+		"trace":   events,
+	}
+	if err != nil {
+		response["error"] = err.Error()
+	}
+	return c.JSON(response)
+}
+
+// runTracedCheck runs one check of a detached monitor within its timeout,
+// records the outcome in a trace, and closes the monitor
+func runTracedCheck(monitor monitors.Monitor) (*models.MonitorResult, []monitors.TraceEvent, error) {
+	if closer, ok := monitor.(io.Closer); ok {
+		defer closer.Close()
+	}
+
+	timeout := monitor.GetConfig().Timeout.ToDuration()
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	trace := monitors.NewTrace()
+	trace.Add(monitors.TracePhaseCheck, "Running %s check with timeout %s", monitor.GetType(), timeout)
+	result, err := monitor.Check(monitors.WithTrace(ctx, trace))
+	switch {
+	case err != nil:
+		trace.Add(monitors.TracePhaseCheck, "Check failed: %v", err)
+	case result.Error != "":
+		trace.Add(monitors.TracePhaseCheck, "Check finished %s in %s: %s", result.Status, result.Duration, result.Error)
+	default:
+		trace.Add(monitors.TracePhaseCheck, "Check finished %s in %s", result.Status, result.Duration)
+	}
+	return result, trace.Events(), err
+}
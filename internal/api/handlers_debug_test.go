@@ -0,0 +1,143 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestDebugCheckHandler(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer target.Close()
+
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+
+	enabled := true
+	loadMonitors(t, server, []models.MonitorGroup{
+		{
+			Name: "core",
+			Monitors: []models.Monitor{
+				{Type: models.MonitorTypeHTTP, Name: "api", URL: target.URL, Enabled: &enabled},
+			},
+		},
+	})
+
+	status, payload := doJSON(t, server, "POST", "/api/v1/monitors/missing/debug-check", nil, nil)
+	if status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown monitor, got %d: %v", status, payload)
+	}
+
+	status, payload = doJSON(t, server, "POST", "/api/v1/monitors/api/debug-check", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	result := payload["result"].(map[string]interface{})
+	if result["status"] != string(models.StatusDown) {
+		t.Errorf("expected the 503 to fail the check, got %v", result)
+	}
+	trace := payload["trace"].([]interface{})
+	if len(trace) < 3 {
+		t.Fatalf("expected a trace of the check, got %v", trace)
+	}
+	last := trace[len(trace)-1].(map[string]interface{})
+	if last["phase"] != "check" {
+		t.Errorf("expected the trace to end with the outcome, got %v", last)
+	}
+
+	// Debug checks are not stored
+	if latest := server.scheduler.GetLatestResult("api"); latest != nil {
+		t.Errorf("expected no stored result, got %+v", latest)
+	}
+}
+
+func TestCheckHandler(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Team") != "core" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+	server.currentConfig().Monitoring.Groups = []models.MonitorGroup{
+		{Name: "core", Headers: map[string]string{"X-Team": "core"}},
+	}
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		wantStatus int
+		wantResult models.MonitorStatus
+	}{
+		{
+			name: "group defaults apply",
+			body: map[string]interface{}{
+				"group_name": "core",
+				"monitor":    map[string]interface{}{"type": "http", "name": "draft", "url": target.URL},
+			},
+			wantStatus: fiber.StatusOK,
+			wantResult: models.StatusUp,
+		},
+		{
+			name: "unknown group uses global defaults",
+			body: map[string]interface{}{
+				"group_name": "new",
+				"monitor":    map[string]interface{}{"type": "http", "name": "draft", "url": target.URL},
+			},
+			wantStatus: fiber.StatusOK,
+			wantResult: models.StatusDown,
+		},
+		{
+			name: "invalid monitor",
+			body: map[string]interface{}{
+				"group_name": "core",
+				"monitor":    map[string]interface{}{"type": "http", "name": "draft"},
+			},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name: "missing name",
+			body: map[string]interface{}{
+				"monitor": map[string]interface{}{"type": "http", "url": target.URL},
+			},
+			wantStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "POST", "/api/v1/check", tt.body, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+			if tt.wantResult == "" {
+				return
+			}
+			result := payload["result"].(map[string]interface{})
+			if result["status"] != string(tt.wantResult) {
+				t.Errorf("expected status %s, got %v", tt.wantResult, result)
+			}
+			if _, ok := payload["trace"].([]interface{}); !ok {
+				t.Errorf("expected a trace, got %v", payload)
+			}
+		})
+	}
+
+	// Checked definitions are neither stored nor added to the config
+	if latest := server.scheduler.GetLatestResult("draft"); latest != nil {
+		t.Errorf("expected no stored result, got %+v", latest)
+	}
+	if monitor := server.monitorManager.GetMonitorByName("draft"); monitor != nil {
+		t.Errorf("expected the monitor not to be loaded")
+	}
+}
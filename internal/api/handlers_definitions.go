@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// MonitorCloneRequest represents a request to copy a monitor under a new
+// name. Overrides replace top-level monitor fields, keyed by their JSON names.
+type MonitorCloneRequest struct {
+	Name      string                 `json:"name"`
+	GroupName string                 `json:"group_name,omitempty"` // Defaults to the source monitor's group
+	Overrides map[string]interface{} `json:"overrides,omitempty"`
+	Revision  *string                `json:"revision,omitempty"`
+}
+
+// isYAML reports whether a content type names YAML
+func isYAML(contentType string) bool {
+	contentType, _, _ = strings.Cut(contentType, ";")
+	switch strings.TrimSpace(strings.ToLower(contentType)) {
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return true
+	}
+	return false
+}
+
+// getMonitorDefinitionHandler returns a monitor's spec as saved in the config
+// file, in the shape POST /monitors accepts so it can be imported elsewhere.
+// ?format=yaml returns YAML instead of JSON.
+func (s *Server) getMonitorDefinitionHandler(c *fiber.Ctx) error {
+	monitorName := c.Params("name")
+
+	cfg, err := config.LoadConfig(s.configPath)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to load config for monitor definition")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	groupIdx, monitorIdx, found := cfg.FindMonitor(monitorName)
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Monitor %s not found", monitorName),
+		})
+	}
+
+	definition := MonitorCreateRequest{
+		GroupName: cfg.Monitoring.Groups[groupIdx].Name,
+		Monitor:   cfg.Monitoring.Groups[groupIdx].Monitors[monitorIdx],
+	}
+
+	format := strings.ToLower(c.Query("format", "json"))
+	switch format {
+	case "json":
+		return c.JSON(definition)
+	case "yaml", "yml":
+		data, err := yaml.Marshal(definition)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success": false,
+				"message": "Failed to encode monitor definition",
+				"error":   err.Error(),
+			})
+		}
+		c.Set(fiber.HeaderContentType, "application/yaml")
+		return c.Send(data)
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "format must be json or yaml",
+		})
+	}
+}
+
+// cloneMonitorHandler copies a monitor under a new name, optionally into
+// another group and with some fields changed
+func (s *Server) cloneMonitorHandler(c *fiber.Ctx) error {
+	sourceName := c.Params("name")
+
+	var req MonitorCloneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	if req.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "name is required",
+		})
+	}
+
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, req.Revision); !ok {
+		return err
+	}
+
+	// Load current config
+	cfg, err := config.LoadConfig(s.configPath)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to load config for monitor clone")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	groupIdx, monitorIdx, found := cfg.FindMonitor(sourceName)
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Monitor %s not found", sourceName),
+		})
+	}
+	groupName := req.GroupName
+	if groupName == "" {
+		groupName = cfg.Monitoring.Groups[groupIdx].Name
+	}
+
+	clone, err := cloneMonitor(cfg.Monitoring.Groups[groupIdx].Monitors[monitorIdx], req.Overrides)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid overrides",
+			"error":   err.Error(),
+		})
+	}
+	clone.Name = req.Name
+
+	// Add monitor to config
+	if err := cfg.AddMonitor(groupName, clone); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to add monitor",
+			"error":   err.Error(),
+		})
+	}
+
+	// Validate modified config
+	if err := cfg.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Configuration validation failed",
+			"error":   err.Error(),
+		})
+	}
+
+	// Write config to file
+	if err := cfg.WriteConfig(s.configPath); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to write config after monitor clone")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	revision := s.ConfigRevision()
+
+	// Reload configuration
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to reload config after monitor clone")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
+		})
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"monitor": clone.Name,
+			"source":  sourceName,
+			"group":   groupName,
+		}).
+		Info("Monitor cloned successfully")
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success":  true,
+		"message":  fmt.Sprintf("Monitor %s cloned to %s", sourceName, clone.Name),
+		"monitor":  clone,
+		"revision": revision,
+	})
+}
+
+// cloneMonitor deep-copies a monitor through JSON, replacing the top-level
+// fields named in overrides; a null override clears the field
+func cloneMonitor(source models.Monitor, overrides map[string]interface{}) (models.Monitor, error) {
+	data, err := json.Marshal(source)
+	if err != nil {
+		return models.Monitor{}, fmt.Errorf("failed to encode monitor: %w", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return models.Monitor{}, fmt.Errorf("failed to decode monitor: %w", err)
+	}
+	for key, value := range overrides {
+		if value == nil {
+			delete(fields, key)
+			continue
+		}
+		fields[key] = value
+	}
+
+	if data, err = json.Marshal(fields); err != nil {
+		return models.Monitor{}, fmt.Errorf("failed to encode monitor: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var clone models.Monitor
+	if err := decoder.Decode(&clone); err != nil {
+		return models.Monitor{}, err
+	}
+	return clone, nil
+}
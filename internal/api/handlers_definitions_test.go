@@ -0,0 +1,103 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestCloneMonitorHandler(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	revision := server.ConfigRevision()
+	tests := []struct {
+		name       string
+		source     string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{name: "unknown source", source: "missing", body: map[string]interface{}{"name": "copy", "revision": revision}, wantStatus: fiber.StatusNotFound},
+		{name: "no name", source: "existing", body: map[string]interface{}{"revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "name taken", source: "existing", body: map[string]interface{}{"name": "existing", "revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "unknown field", source: "existing", body: map[string]interface{}{"name": "copy", "overrides": map[string]interface{}{"colour": "red"}, "revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "invalid result", source: "existing", body: map[string]interface{}{"name": "copy", "overrides": map[string]interface{}{"url": nil}, "revision": revision}, wantStatus: fiber.StatusBadRequest},
+		{name: "unknown group", source: "existing", body: map[string]interface{}{"name": "copy", "group_name": "edge", "revision": revision}, wantStatus: fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "POST", "/api/v1/monitors/"+tt.source+"/clone", tt.body, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+		})
+	}
+
+	status, payload := doJSON(t, server, "POST", "/api/v1/monitors/existing/clone", map[string]interface{}{
+		"name":      "copy",
+		"overrides": map[string]interface{}{"url": "https://example.org/health", "interval": "1m"},
+		"revision":  revision,
+	}, nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", status, payload)
+	}
+	if server.monitorManager.GetMonitorByName("copy") == nil {
+		t.Fatal("expected the clone to be loaded")
+	}
+
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/copy/definition", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	monitor := payload["monitor"].(map[string]interface{})
+	if payload["group_name"] != "core" || monitor["url"] != "https://example.org/health" || monitor["interval"] != "1m0s" || monitor["type"] != "http" {
+		t.Errorf("unexpected definition %v", payload)
+	}
+}
+
+func TestMonitorDefinitionRoundTrip(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	req := httptest.NewRequest("GET", "/api/v1/monitors/existing/definition?format=yaml", nil)
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get(fiber.HeaderContentType) != "application/yaml" {
+		t.Fatalf("expected a YAML definition, got %d %q", resp.StatusCode, resp.Header.Get(fiber.HeaderContentType))
+	}
+	if !strings.Contains(string(body), "groupName: core") || !strings.Contains(string(body), "url: https://example.com") {
+		t.Fatalf("unexpected YAML definition:\n%s", body)
+	}
+
+	// The exported YAML imports as a new monitor once renamed
+	definition := strings.Replace(string(body), "name: existing", "name: imported", 1) + "revision: " + strconv.Quote(server.ConfigRevision()) + "\n"
+	req = httptest.NewRequest("POST", "/api/v1/monitors", strings.NewReader(definition))
+	req.Header.Set(fiber.HeaderContentType, "application/yaml")
+	resp, err = server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("expected 201 importing YAML, got %d", resp.StatusCode)
+	}
+	if server.monitorManager.GetMonitorByName("imported") == nil {
+		t.Error("expected the imported monitor to be loaded")
+	}
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/monitors/existing/definition?format=xml", nil, nil)
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for an unknown format, got %d: %v", status, payload)
+	}
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/missing/definition", nil, nil)
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for an unknown monitor, got %d: %v", status, payload)
+	}
+}
@@ -0,0 +1,140 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// maxFaultDuration bounds how long a simulated failure may last
+const maxFaultDuration = 24 * time.Hour
+
+// FaultRequest represents a request to simulate a monitor failure. The length
+// is given either as minutes or as a duration string such as "90s".
+type FaultRequest struct {
+	Minutes  int    `json:"minutes,omitempty"`
+	Duration string `json:"duration,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// getFaultsHandler lists active simulated failures
+func (s *Server) getFaultsHandler(c *fiber.Ctx) error {
+	faults := make([]scheduler.Fault, 0)
+	for _, fault := range s.scheduler.Faults().List() {
+		if s.canAccessMonitor(c, fault.Monitor) {
+			faults = append(faults, fault)
+		}
+	}
+	return c.JSON(fiber.Map{
+		"faults": faults,
+		"count":  len(faults),
+	})
+}
+
+// injectFaultHandler marks a monitor down with a synthetic error for a period
+// so alert routing, escalation, and status pages can be tested end to end
+func (s *Server) injectFaultHandler(c *fiber.Ctx) error {
+	// Copy the name since fiber reuses the buffer backing route params
+	monitorName := strings.Clone(c.Params("name"))
+	if s.monitorManager.GetMonitorByName(monitorName) == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Monitor %s not found", monitorName),
+		})
+	}
+
+	var req FaultRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	duration := time.Duration(req.Minutes) * time.Minute
+	if req.Duration != "" {
+		if req.Minutes != 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "minutes and duration are mutually exclusive",
+			})
+		}
+		parsed, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid duration",
+				"error":   err.Error(),
+			})
+		}
+		duration = parsed
+	}
+	if duration <= 0 || duration > maxFaultDuration {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("duration must be between 0 and %s", maxFaultDuration),
+		})
+	}
+
+	fault := s.scheduler.Faults().Inject(monitorName, duration, req.Reason)
+
+	// Mark the test window on dashboards so it is not mistaken for an outage
+	text := "Synthetic failure injected"
+	if req.Reason != "" {
+		text += ": " + req.Reason
+	}
+	if err := s.annotations.Add(&models.Annotation{
+		ID:      newAnnotationID(),
+		Time:    fault.StartedAt,
+		TimeEnd: fault.ExpiresAt,
+		Monitor: monitorName,
+		Text:    text,
+		Tags:    []string{"chaos"},
+	}); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Warn("Failed to record annotation for injected failure")
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"monitor":    monitorName,
+			"reason":     req.Reason,
+			"expires_at": fault.ExpiresAt,
+		}).
+		Warn("Synthetic failure injection started")
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"fault":   fault,
+	})
+}
+
+// clearFaultHandler ends a simulated failure early
+func (s *Server) clearFaultHandler(c *fiber.Ctx) error {
+	monitorName := c.Params("name")
+	if !s.scheduler.Faults().Clear(monitorName) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("No active fault for monitor %s", monitorName),
+		})
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"monitor": monitorName,
+		}).
+		Info("Synthetic failure injection cleared")
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": fmt.Sprintf("Fault for monitor %s cleared", monitorName),
+	})
+}
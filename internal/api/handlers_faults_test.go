@@ -0,0 +1,59 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestInjectFaultHandler(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	tests := []struct {
+		name       string
+		path       string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{name: "unknown monitor", path: "/api/v1/monitors/missing/fault", body: map[string]interface{}{"minutes": 5}, wantStatus: fiber.StatusNotFound},
+		{name: "no duration", path: "/api/v1/monitors/api/fault", body: map[string]interface{}{}, wantStatus: fiber.StatusBadRequest},
+		{name: "invalid duration", path: "/api/v1/monitors/api/fault", body: map[string]interface{}{"duration": "soon"}, wantStatus: fiber.StatusBadRequest},
+		{name: "both lengths", path: "/api/v1/monitors/api/fault", body: map[string]interface{}{"minutes": 5, "duration": "5m"}, wantStatus: fiber.StatusBadRequest},
+		{name: "too long", path: "/api/v1/monitors/api/fault", body: map[string]interface{}{"duration": "48h"}, wantStatus: fiber.StatusBadRequest},
+		{name: "minutes", path: "/api/v1/monitors/api/fault", body: map[string]interface{}{"minutes": 5, "reason": "drill"}, wantStatus: fiber.StatusCreated},
+		{name: "duration", path: "/api/v1/monitors/cdn/fault", body: map[string]interface{}{"duration": "90s"}, wantStatus: fiber.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "POST", tt.path, tt.body, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+		})
+	}
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/faults", nil, nil)
+	if status != fiber.StatusOK || payload["count"] != float64(2) {
+		t.Fatalf("expected 2 active faults, got %d: %v", status, payload)
+	}
+
+	// The test window is annotated on the monitor's timeline
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/api/history", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if annotations := payload["annotations"].([]interface{}); len(annotations) != 1 {
+		t.Errorf("expected fault annotation in history, got %v", annotations)
+	}
+
+	status, payload = doJSON(t, server, "DELETE", "/api/v1/monitors/api/fault", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200 clearing fault, got %d: %v", status, payload)
+	}
+	status, payload = doJSON(t, server, "DELETE", "/api/v1/monitors/api/fault", nil, nil)
+	if status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 clearing twice, got %d: %v", status, payload)
+	}
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// maxHeatmapBuckets bounds how many buckets one heatmap request may ask for
+const maxHeatmapBuckets = 1000
+
+// Heatmap bucket states
+const (
+	heatmapUp      = "up"      // Every check succeeded
+	heatmapDown    = "down"    // Every check failed
+	heatmapPartial = "partial" // Some checks failed
+	heatmapNoData  = "nodata"  // No checks ran
+)
+
+// HeatmapBucket is one cell of an availability heatmap
+type HeatmapBucket struct {
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	State         string    `json:"state"`
+	TotalChecks   int       `json:"total_checks"`
+	UpChecks      int       `json:"up_checks"`
+	DownChecks    int       `json:"down_checks"`
+	UptimePercent *float64  `json:"uptime_percent,omitempty"` // Unset without checks
+}
+
+// getMonitorHeatmapHandler returns a monitor's availability per bucket over
+// ?period= (default 90d) in ?bucket= steps (default 1d), for uptime bars.
+// Buckets are aligned to the bucket size in UTC; the last one is in progress.
+func (s *Server) getMonitorHeatmapHandler(c *fiber.Ctx) error {
+	if s.storage != nil && !s.storage.Capabilities().SupportsRawResults {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error":   true,
+			"message": "Current storage backend does not support historical queries",
+			"hint":    "Enable BadgerDB storage in config.yml (set storage.backend to 'badger')",
+		})
+	}
+
+	monitorName := c.Params("name")
+	periodStr := c.Query("period", "90d")
+	bucketStr := c.Query("bucket", "1d")
+
+	period, err := models.ParsePeriod(periodStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid period format (use a duration like 24h or a number of days like 90d)",
+		})
+	}
+	bucket, err := models.ParsePeriod(bucketStr)
+	if err != nil || bucket < time.Minute {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid bucket (use a duration of at least 1m, like 1h or 1d)",
+		})
+	}
+	count := int((period + bucket - 1) / bucket)
+	if count > maxHeatmapBuckets {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Too many buckets; use a shorter period or a larger bucket",
+			"max":     maxHeatmapBuckets,
+		})
+	}
+
+	cacheKey := monitorName + "\x00" + periodStr + "\x00" + bucketStr
+	if cached, ok := s.cache.Get(cacheHeatmap, cacheKey); ok {
+		return c.JSON(cached)
+	}
+
+	end := time.Now().UTC()
+	start := end.Truncate(bucket).Add(-time.Duration(count-1) * bucket)
+
+	buckets := make([]HeatmapBucket, 0, count)
+	var overall storage.ResultCounts
+	for bucketStart := start; bucketStart.Before(end); bucketStart = bucketStart.Add(bucket) {
+		bucketEnd := bucketStart.Add(bucket)
+		if bucketEnd.After(end) {
+			bucketEnd = end
+		}
+
+		// Counts include the end instant, so stop just short of the next bucket
+		counts, err := s.scheduler.CountHistoricalResults(monitorName, bucketStart, bucketEnd.Add(-time.Nanosecond))
+		if err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Error("Failed to count historical results for heatmap")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Failed to compute heatmap",
+			})
+		}
+		overall.Total += counts.Total
+		overall.Up += counts.Up
+		overall.Down += counts.Down
+
+		buckets = append(buckets, newHeatmapBucket(bucketStart, bucketEnd, counts))
+	}
+
+	response := fiber.Map{
+		"monitor": monitorName,
+		"period":  periodStr,
+		"bucket":  bucketStr,
+		"start":   start.Format(time.RFC3339),
+		"end":     end.Format(time.RFC3339),
+		"buckets": buckets,
+	}
+	if overall.Total > 0 {
+		response["uptime_percent"] = float64(overall.Up) / float64(overall.Total) * 100
+	}
+	s.cache.Set(cacheHeatmap, cacheKey, response, monitorName)
+
+	return c.JSON(response)
+}
+
+// newHeatmapBucket classifies a bucket's checks
+func newHeatmapBucket(start, end time.Time, counts storage.ResultCounts) HeatmapBucket {
+	bucket := HeatmapBucket{
+		Start:       start,
+		End:         end,
+		TotalChecks: counts.Total,
+		UpChecks:    counts.Up,
+		DownChecks:  counts.Down,
+	}
+
+	switch {
+	case counts.Total == 0:
+		bucket.State = heatmapNoData
+		return bucket
+	case counts.Down == 0:
+		bucket.State = heatmapUp
+	case counts.Up == 0:
+		bucket.State = heatmapDown
+	default:
+		bucket.State = heatmapPartial
+	}
+
+	uptime := float64(counts.Up) / float64(counts.Total) * 100
+	bucket.UptimePercent = &uptime
+	return bucket
+}
@@ -0,0 +1,66 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestGetMonitorHeatmapHandler(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	// Two whole hours back: all up; one hour back: mixed; current hour: all down
+	hour := time.Now().UTC().Truncate(time.Hour)
+	for _, result := range []*models.MonitorResult{
+		{Monitor: "api", Status: models.StatusUp, Timestamp: hour.Add(-2*time.Hour + time.Minute)},
+		{Monitor: "api", Status: models.StatusUp, Timestamp: hour.Add(-time.Hour + time.Minute)},
+		{Monitor: "api", Status: models.StatusDown, Timestamp: hour.Add(-time.Hour + 2*time.Minute)},
+		{Monitor: "api", Status: models.StatusDown, Timestamp: hour},
+	} {
+		storeResult(t, server, result)
+	}
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/monitors/api/heatmap?period=4h&bucket=1h", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+
+	buckets := payload["buckets"].([]interface{})
+	want := []string{heatmapNoData, heatmapUp, heatmapPartial, heatmapDown}
+	if len(buckets) != len(want) {
+		t.Fatalf("expected %d buckets, got %d", len(want), len(buckets))
+	}
+	for i, state := range want {
+		bucket := buckets[i].(map[string]interface{})
+		if bucket["state"] != state {
+			t.Errorf("bucket %d: expected %s, got %v", i, state, bucket["state"])
+		}
+	}
+	if partial := buckets[2].(map[string]interface{}); partial["uptime_percent"] != float64(50) {
+		t.Errorf("expected 50%% uptime in the partial bucket, got %v", partial["uptime_percent"])
+	}
+	if _, ok := buckets[0].(map[string]interface{})["uptime_percent"]; ok {
+		t.Errorf("expected no uptime for a bucket without checks")
+	}
+	if payload["uptime_percent"] != float64(50) {
+		t.Errorf("expected 50%% overall uptime, got %v", payload["uptime_percent"])
+	}
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{query: "?period=soon", want: fiber.StatusBadRequest},
+		{query: "?bucket=1s", want: fiber.StatusBadRequest},
+		{query: "?period=365d&bucket=1m", want: fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if status, _ := doJSON(t, server, "GET", "/api/v1/monitors/api/heatmap"+tt.query, nil, nil); status != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.query, tt.want, status)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/importer"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+)
+
+// BlackboxImportRequest carries the files of a blackbox_exporter import
+type BlackboxImportRequest struct {
+	Config  string `json:"config"`  // blackbox_exporter config with modules
+	Targets string `json:"targets"` // Prometheus file_sd target list
+}
+
+// importUptimeKumaHandler imports monitors from an Uptime Kuma database sent
+// as the request body
+func (s *Server) importUptimeKumaHandler(c *fiber.Ctx) error {
+	body := c.Body()
+	if len(body) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Request body must be an Uptime Kuma database (kuma.db)",
+		})
+	}
+
+	// The body is only valid during the request, and history is read from it
+	// after the config is saved
+	data := bytes.Clone(body)
+	imp, err := importer.ReadUptimeKuma(bytes.NewReader(data), int64(len(data)), c.Query("group"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read Uptime Kuma database",
+			"error":   err.Error(),
+		})
+	}
+	return s.applyImport(c, imp)
+}
+
+// importBlackboxHandler imports monitors from a blackbox_exporter config and
+// the targets probed with its modules
+func (s *Server) importBlackboxHandler(c *fiber.Ctx) error {
+	var req BlackboxImportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	imp, err := importer.ReadBlackbox([]byte(req.Config), []byte(req.Targets), c.Query("group"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to read blackbox_exporter config",
+			"error":   err.Error(),
+		})
+	}
+	return s.applyImport(c, imp)
+}
+
+// applyImport responds with the converted monitors when preview=true.
+// Otherwise it adds them to the config and, unless history=false, stores
+// their history as results.
+func (s *Server) applyImport(c *fiber.Ctx, imp *importer.Import) error {
+	if c.QueryBool("preview") {
+		return c.JSON(fiber.Map{
+			"success":  true,
+			"groups":   imp.Groups,
+			"count":    imp.MonitorCount(),
+			"warnings": imp.Warnings,
+		})
+	}
+
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, nil); !ok {
+		return err
+	}
+
+	// Load current config
+	cfg, err := config.LoadConfig(s.configPath)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to load config for import")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	added := imp.Merge(cfg)
+	if len(added) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":  false,
+			"message":  "No monitors could be imported",
+			"warnings": imp.Warnings,
+		})
+	}
+
+	// Validate modified config
+	if err := cfg.Validate(); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success":  false,
+			"message":  "Configuration validation failed",
+			"error":    err.Error(),
+			"warnings": imp.Warnings,
+		})
+	}
+
+	// Write config to file
+	if err := cfg.WriteConfig(s.configPath); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to write config after import")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	revision := s.ConfigRevision()
+
+	// Reload configuration
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to reload config after import")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
+		})
+	}
+
+	// Backfill history into storage
+	results := 0
+	if s.storage != nil && imp.HasHistory() && c.QueryBool("history", true) {
+		results, err = imp.Backfill(s.storage, added)
+		if err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				WithFields(map[string]interface{}{
+					"results": results,
+				}).
+				Error("Failed to backfill imported history")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"success":  false,
+				"message":  fmt.Sprintf("Imported %d monitors but history backfill failed after %d results", len(added), results),
+				"error":    err.Error(),
+				"monitors": added,
+				"revision": revision,
+			})
+		}
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"source":   imp.Source,
+			"monitors": len(added),
+			"results":  results,
+			"warnings": len(imp.Warnings),
+		}).
+		Info("Monitors imported successfully")
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success":  true,
+		"message":  fmt.Sprintf("Imported %d monitors and %d results from %s", len(added), results, imp.Source),
+		"monitors": added,
+		"results":  results,
+		"warnings": imp.Warnings,
+		"revision": revision,
+	})
+}
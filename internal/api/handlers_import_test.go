@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+)
+
+// postKumaDatabase sends the importer's test database to the import endpoint
+func postKumaDatabase(t *testing.T, server *Server, query string) (int, map[string]interface{}) {
+	t.Helper()
+
+	data, err := os.ReadFile("../importer/testdata/kuma.db")
+	if err != nil {
+		t.Fatalf("failed to read test database: %v", err)
+	}
+	req := httptest.NewRequest("POST", "/api/v1/import/uptime-kuma"+query, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var payload map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp.StatusCode, payload
+}
+
+func TestImportUptimeKumaPreview(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	status, payload := postKumaDatabase(t, server, "?preview=true")
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if payload["count"] != float64(6) {
+		t.Errorf("expected 6 monitors, got %v", payload["count"])
+	}
+
+	// Previews leave the config alone
+	cfg, err := config.LoadConfig(server.configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	if len(cfg.Monitoring.Groups) != 1 {
+		t.Errorf("expected the config to be unchanged, got %d groups", len(cfg.Monitoring.Groups))
+	}
+}
+
+func TestImportUptimeKuma(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+	store, err := storage.NewBadgerStore(t.TempDir(), 7, server.logger)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	server.storage = store
+
+	// Like other config writes, imports need the current revision
+	if status, _ := postKumaDatabase(t, server, "?group=migrated"); status != fiber.StatusPreconditionRequired {
+		t.Fatalf("expected 428 without a revision, got %d", status)
+	}
+
+	status, payload := postKumaDatabase(t, server, fmt.Sprintf("?group=migrated&revision=%s", server.ConfigRevision()))
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", status, payload)
+	}
+	if payload["results"] != float64(397) {
+		t.Errorf("expected 397 results, got %v", payload["results"])
+	}
+
+	cfg, err := config.LoadConfig(server.configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	for _, name := range []string{"API health", "Website", "Website (2)"} {
+		if _, _, found := cfg.FindMonitor(name); !found {
+			t.Errorf("expected monitor %s in the saved config", name)
+		}
+	}
+	if _, found := cfg.FindGroup("migrated"); !found {
+		t.Error("expected ungrouped monitors in group migrated")
+	}
+
+	results, err := store.GetResults("Website", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), 0)
+	if err != nil {
+		t.Fatalf("failed to read results: %v", err)
+	}
+	if len(results) != 396 {
+		t.Errorf("expected 396 backfilled results, got %d", len(results))
+	}
+}
+
+func TestImportBlackbox(t *testing.T) {
+	server := createYAMLTestServer(t, configTestConfig, nil)
+
+	status, payload := doJSON(t, server, "POST", fmt.Sprintf("/api/v1/import/blackbox?revision=%s", server.ConfigRevision()), BlackboxImportRequest{
+		Config:  "modules:\n  http_2xx:\n    prober: http\n  icmp:\n    prober: icmp\n",
+		Targets: `[{"targets": ["https://example.org", "https://example.com"], "labels": {"module": "http_2xx"}}, {"targets": ["10.0.0.1"], "labels": {"module": "icmp"}}]`,
+	}, nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", status, payload)
+	}
+	if monitors := payload["monitors"].([]interface{}); len(monitors) != 3 {
+		t.Errorf("expected 3 monitors, got %v", monitors)
+	}
+
+	cfg, err := config.LoadConfig(server.configPath)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+	groupIdx, found := cfg.FindGroup("blackbox")
+	if !found {
+		t.Fatal("expected monitors in group blackbox")
+	}
+	if got := len(cfg.Monitoring.Groups[groupIdx].Monitors); got != 3 {
+		t.Errorf("expected 3 monitors in group blackbox, got %d", got)
+	}
+}
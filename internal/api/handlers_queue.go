@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+)
+
+// maxDeferDuration bounds how far a monitor's next check may be pushed out
+const maxDeferDuration = 24 * time.Hour
+
+// DeferRequest represents a request to push a monitor's next check out,
+// either by a duration string such as "10m" or to a given time
+type DeferRequest struct {
+	Duration string    `json:"duration,omitempty"`
+	Until    time.Time `json:"until,omitempty"`
+}
+
+// getSchedulerQueueHandler lists each monitor's next scheduled check, backoff,
+// and last run, soonest first
+func (s *Server) getSchedulerQueueHandler(c *fiber.Ctx) error {
+	queue := make([]scheduler.QueueEntry, 0)
+	for _, entry := range s.scheduler.Queue() {
+		if s.canAccessMonitor(c, entry.Monitor) {
+			queue = append(queue, entry)
+		}
+	}
+	return c.JSON(fiber.Map{
+		"running": s.scheduler.IsRunning(),
+		"queue":   queue,
+		"count":   len(queue),
+	})
+}
+
+// deferMonitorHandler pushes a monitor's next check out
+func (s *Server) deferMonitorHandler(c *fiber.Ctx) error {
+	// Copy the name since fiber reuses the buffer backing route params
+	monitorName := strings.Clone(c.Params("name"))
+	if s.monitorManager.GetMonitorByName(monitorName) == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Monitor %s not found", monitorName),
+		})
+	}
+
+	var req DeferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	now := time.Now()
+	until := req.Until
+	if req.Duration != "" {
+		if !req.Until.IsZero() {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "duration and until are mutually exclusive",
+			})
+		}
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"success": false,
+				"message": "Invalid duration",
+				"error":   err.Error(),
+			})
+		}
+		until = now.Add(duration)
+	}
+	if !until.After(now) || until.Sub(now) > maxDeferDuration {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("the next run must be in the future and within %s", maxDeferDuration),
+		})
+	}
+
+	if err := s.scheduler.DeferMonitor(monitorName, until); err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success": false,
+			"message": err.Error(),
+		})
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"monitor":  monitorName,
+			"next_run": until,
+		}).
+		Info("Monitor check deferred")
+
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"monitor":  monitorName,
+		"next_run": until,
+	})
+}
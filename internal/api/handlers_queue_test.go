@@ -0,0 +1,52 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestSchedulerQueueHandlers(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	until := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name       string
+		path       string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{name: "unknown monitor", path: "/api/v1/scheduler/queue/missing/defer", body: map[string]interface{}{"duration": "10m"}, wantStatus: fiber.StatusNotFound},
+		{name: "no time", path: "/api/v1/scheduler/queue/api/defer", body: map[string]interface{}{}, wantStatus: fiber.StatusBadRequest},
+		{name: "invalid duration", path: "/api/v1/scheduler/queue/api/defer", body: map[string]interface{}{"duration": "later"}, wantStatus: fiber.StatusBadRequest},
+		{name: "both", path: "/api/v1/scheduler/queue/api/defer", body: map[string]interface{}{"duration": "10m", "until": until}, wantStatus: fiber.StatusBadRequest},
+		{name: "too long", path: "/api/v1/scheduler/queue/api/defer", body: map[string]interface{}{"duration": "48h"}, wantStatus: fiber.StatusBadRequest},
+		{name: "duration", path: "/api/v1/scheduler/queue/api/defer", body: map[string]interface{}{"duration": "10m"}, wantStatus: fiber.StatusOK},
+		{name: "until", path: "/api/v1/scheduler/queue/cdn/defer", body: map[string]interface{}{"until": until}, wantStatus: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "POST", tt.path, tt.body, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+		})
+	}
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/scheduler/queue", nil, nil)
+	if status != fiber.StatusOK || payload["count"] != float64(2) {
+		t.Fatalf("expected 2 queued monitors, got %d: %v", status, payload)
+	}
+	for _, raw := range payload["queue"].([]interface{}) {
+		entry := raw.(map[string]interface{})
+		if entry["deferred_until"] == nil {
+			t.Errorf("expected %v to be deferred, got %v", entry["monitor"], entry)
+		}
+	}
+	if payload["queue"].([]interface{})[1].(map[string]interface{})["deferred_until"] != until {
+		t.Errorf("expected cdn deferred until %s, got %v", until, payload["queue"])
+	}
+}
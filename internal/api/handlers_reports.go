@@ -0,0 +1,246 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/reports"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// getLatestReportHandler returns the most recently generated scheduled
+// reports. ?name= selects one report, which ?format=html or ?format=markdown
+// renders as it was delivered.
+func (s *Server) getLatestReportHandler(c *fiber.Ctx) error {
+	if s.reports == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "No reports are configured",
+		})
+	}
+
+	name := c.Query("name")
+	format := c.Query("format", "json")
+	if format != "json" && format != "html" && format != "markdown" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid format (use json, html or markdown)",
+		})
+	}
+
+	if name == "" {
+		if format != "json" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Rendering a report requires name",
+			})
+		}
+		latest := s.reports.LatestAll()
+		return c.JSON(fiber.Map{
+			"reports": latest,
+			"total":   len(latest),
+		})
+	}
+
+	report, ok := s.reports.Latest(name)
+	if !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "Report has not been generated yet",
+		})
+	}
+
+	var (
+		body []byte
+		err  error
+	)
+	switch format {
+	case "html":
+		body, err = reports.RenderHTML(report)
+		c.Type("html", "utf-8")
+	case "markdown":
+		body, err = reports.RenderMarkdown(report)
+		c.Set(fiber.HeaderContentType, "text/markdown; charset=utf-8")
+	default:
+		return c.JSON(report)
+	}
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to render report")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to render report",
+		})
+	}
+	return c.Send(body)
+}
+
+// getSLAReportHandler generates an SLA report with uptime and objective
+// compliance per monitor and a list of incidents. ?period= is month (the last
+// full calendar month, the default), week (the last full Monday-to-Monday
+// week) or a lookback such as 30d; ?start= and ?end= (RFC3339) set an explicit
+// range instead. ?group= (comma-separated) limits the groups, ?slo= overrides
+// the monitors' objectives, ?tz= sets the timezone calendar periods use, and
+// ?format= is html (the default), pdf or json.
+func (s *Server) getSLAReportHandler(c *fiber.Ctx) error {
+	if s.storage != nil && !s.storage.Capabilities().SupportsRawResults {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error":   true,
+			"message": "Current storage backend does not support historical queries",
+			"hint":    "Enable BadgerDB storage in config.yml (set storage.backend to 'badger')",
+		})
+	}
+
+	format := c.Query("format", "html")
+	if format != "html" && format != "pdf" && format != "json" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid format (use html, pdf or json)",
+		})
+	}
+
+	location := time.Local
+	if tz := c.Query("tz"); tz != "" {
+		var err error
+		if location, err = time.LoadLocation(tz); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid timezone",
+			})
+		}
+	}
+
+	start, end, err := slaReportRange(c.Query("period", "month"), time.Now().In(location))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+	if startStr, endStr := c.Query("start"), c.Query("end"); startStr != "" || endStr != "" {
+		if start, err = time.Parse(time.RFC3339, startStr); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid start timestamp format (use RFC3339 and provide both start and end)",
+			})
+		}
+		if end, err = time.Parse(time.RFC3339, endStr); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid end timestamp format (use RFC3339 and provide both start and end)",
+			})
+		}
+		if !end.After(start) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "End time must be after start time",
+			})
+		}
+	}
+
+	var groups []string
+	for _, group := range strings.Split(c.Query("group"), ",") {
+		if group = strings.TrimSpace(group); group == "" {
+			continue
+		}
+		if len(s.monitorManager.GetMonitorsByGroup(group)) == 0 || !s.canAccessGroup(c, group) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Group not found",
+			})
+		}
+		groups = append(groups, group)
+	}
+
+	slo := 0.0
+	if sloStr := c.Query("slo"); sloStr != "" {
+		if slo, err = strconv.ParseFloat(sloStr, 64); err != nil || slo <= 0 || slo > 100 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid slo (use a percentage such as 99.9)",
+			})
+		}
+	}
+
+	title := "SLA Report"
+	if len(groups) > 0 {
+		title += ": " + strings.Join(groups, ", ")
+	} else if tenant := requestTenant(c); tenant != nil {
+		// Tenant reports cover the tenant's groups only
+		title += ": " + tenantTitle(tenant)
+		if groups = s.accessibleGroups(c); len(groups) == 0 {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "No groups to report on",
+			})
+		}
+	}
+
+	generator := reports.NewGenerator(s.scheduler, s.monitorManager)
+	report, err := generator.Generate(reports.Options{
+		Name:      "sla",
+		Title:     title,
+		Groups:    groups,
+		Start:     start,
+		End:       end,
+		SLO:       slo,
+		Incidents: true,
+	})
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to generate SLA report")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to generate SLA report",
+		})
+	}
+
+	var body []byte
+	switch format {
+	case "json":
+		return c.JSON(report)
+	case "pdf":
+		body, err = reports.RenderPDF(report)
+		c.Type("pdf")
+		c.Attachment(fmt.Sprintf("sla-report-%s.pdf", start.Format("2006-01-02")))
+	default:
+		body, err = reports.RenderHTML(report)
+		c.Type("html", "utf-8")
+	}
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to render SLA report")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to render SLA report",
+		})
+	}
+	return c.Send(body)
+}
+
+// slaReportRange resolves an SLA report period ending at or before now
+func slaReportRange(period string, now time.Time) (time.Time, time.Time, error) {
+	switch period {
+	case "month":
+		end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return end.AddDate(0, -1, 0), end, nil
+	case "week":
+		daysSinceMonday := (int(now.Weekday()) + 6) % 7
+		end := time.Date(now.Year(), now.Month(), now.Day()-daysSinceMonday, 0, 0, 0, 0, now.Location())
+		return end.AddDate(0, 0, -7), end, nil
+	}
+
+	lookback, err := models.ParsePeriod(period)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid period (use month, week, or a duration such as 30d): %w", err)
+	}
+	return now.Add(-lookback), now, nil
+}
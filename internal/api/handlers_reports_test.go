@@ -0,0 +1,180 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/reports"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestGetLatestReportHandler(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	status, _ := doJSON(t, server, "GET", "/api/v1/reports/latest", nil, nil)
+	if status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 without configured reports, got %d", status)
+	}
+
+	cfg := config.ReportsConfig{Schedules: []config.ReportScheduleConfig{
+		{Name: "weekly", Title: "Weekly SLA", Cron: "@weekly", Groups: []string{"core"}, WebhookURL: "http://127.0.0.1:1"},
+	}}
+	runner, err := reports.NewRunner(cfg, reports.NewGenerator(server.scheduler, server.monitorManager), server.logger)
+	if err != nil {
+		t.Fatalf("failed to create report runner: %v", err)
+	}
+	server.SetReportRunner(runner)
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/reports/latest?name=weekly", nil, nil)
+	if status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 before a report is generated, got %d: %v", status, payload)
+	}
+
+	// Delivery to the unreachable webhook fails but the report is kept
+	if _, err := runner.Run(context.Background(), "weekly"); err == nil {
+		t.Fatal("expected webhook delivery to fail")
+	}
+
+	status, payload = doJSON(t, server, "GET", "/api/v1/reports/latest", nil, nil)
+	if status != fiber.StatusOK || payload["total"] != float64(1) {
+		t.Fatalf("expected one latest report, got %d: %v", status, payload)
+	}
+
+	status, payload = doJSON(t, server, "GET", "/api/v1/reports/latest?name=weekly", nil, nil)
+	if status != fiber.StatusOK || payload["title"] != "Weekly SLA" {
+		t.Fatalf("expected the weekly report, got %d: %v", status, payload)
+	}
+	if groups := payload["groups"].([]interface{}); len(groups) != 1 {
+		t.Errorf("expected only the core group, got %v", groups)
+	}
+
+	for format, contentType := range map[string]string{"html": "text/html", "markdown": "text/markdown"} {
+		resp, err := server.app.Test(httptest.NewRequest("GET", "/api/v1/reports/latest?name=weekly&format="+format, nil))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), contentType) {
+			t.Errorf("format %s: expected 200 %s, got %d %s", format, contentType, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		if !strings.Contains(string(body), "Weekly SLA") {
+			t.Errorf("format %s: expected the rendered report, got %s", format, body)
+		}
+	}
+
+	for _, path := range []string{"/api/v1/reports/latest?format=html", "/api/v1/reports/latest?name=weekly&format=pdf"} {
+		if status, _ := doJSON(t, server, "GET", path, nil, nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, status)
+		}
+	}
+}
+
+func TestGetSLAReportHandler(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	now := time.Now()
+	for _, result := range []*models.MonitorResult{
+		{Monitor: "api", Group: "core", Status: models.StatusUp, Timestamp: now.Add(-40 * time.Minute)},
+		{Monitor: "api", Group: "core", Status: models.StatusDown, Timestamp: now.Add(-30 * time.Minute)},
+		{Monitor: "api", Group: "core", Status: models.StatusUp, Timestamp: now.Add(-20 * time.Minute)},
+		{Monitor: "api", Group: "core", Status: models.StatusUp, Timestamp: now.Add(-10 * time.Minute)},
+	} {
+		storeResult(t, server, result)
+	}
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/reports/sla?period=1d&group=core&slo=99&format=json", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if payload["title"] != "SLA Report: core" {
+		t.Errorf("unexpected title: %v", payload["title"])
+	}
+	groups := payload["groups"].([]interface{})
+	if len(groups) != 1 {
+		t.Fatalf("expected only the core group, got %v", groups)
+	}
+	monitor := groups[0].(map[string]interface{})["monitors"].([]interface{})[0].(map[string]interface{})
+	if monitor["uptime_percent"] != float64(75) || monitor["slo"] != float64(99) || monitor["meets_slo"] != false {
+		t.Errorf("expected api at 75%% missing a 99%% objective, got %v", monitor)
+	}
+	incidents := payload["incidents"].([]interface{})
+	if len(incidents) != 1 || incidents[0].(map[string]interface{})["duration_seconds"] != float64(600) {
+		t.Errorf("expected one 10 minute incident, got %v", incidents)
+	}
+
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/api/v1/reports/sla?period=1d&format=pdf", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get("Content-Type") != "application/pdf" {
+		t.Errorf("expected a PDF, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Disposition"), "attachment") || !strings.HasPrefix(string(body), "%PDF-") {
+		t.Errorf("expected a PDF attachment, got %s", resp.Header.Get("Content-Disposition"))
+	}
+
+	resp, err = server.app.Test(httptest.NewRequest("GET", "/api/v1/reports/sla", nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || !strings.Contains(string(body), "Incidents") {
+		t.Errorf("expected an HTML report by default, got %d", resp.StatusCode)
+	}
+
+	for _, path := range []string{
+		"/api/v1/reports/sla?period=fortnight",
+		"/api/v1/reports/sla?format=docx",
+		"/api/v1/reports/sla?slo=101",
+		"/api/v1/reports/sla?tz=Mars/Olympus",
+		"/api/v1/reports/sla?start=2024-03-01T00:00:00Z",
+		"/api/v1/reports/sla?start=2024-03-02T00:00:00Z&end=2024-03-01T00:00:00Z",
+	} {
+		if status, _ := doJSON(t, server, "GET", path, nil, nil); status != fiber.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", path, status)
+		}
+	}
+	if status, _ := doJSON(t, server, "GET", "/api/v1/reports/sla?group=missing", nil, nil); status != fiber.StatusNotFound {
+		t.Errorf("expected 404 for an unknown group, got %d", status)
+	}
+}
+
+func TestSLAReportRange(t *testing.T) {
+	// Wednesday
+	now := time.Date(2024, 3, 13, 15, 4, 0, 0, time.UTC)
+
+	tests := []struct {
+		period string
+		start  time.Time
+		end    time.Time
+	}{
+		{period: "month", start: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), end: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{period: "week", start: time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), end: time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)},
+		{period: "30d", start: now.AddDate(0, 0, -30), end: now},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			start, end, err := slaReportRange(tt.period, now)
+			if err != nil {
+				t.Fatalf("slaReportRange failed: %v", err)
+			}
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Errorf("slaReportRange(%q) = %v - %v, want %v - %v", tt.period, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
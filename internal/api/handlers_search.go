@@ -0,0 +1,167 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	defaultSearchLimit = 100
+	maxSearchLimit     = 1000
+)
+
+// searchResultsHandler searches recent results across monitors. ?q= matches
+// error messages, metadata values and status codes; ?code=, ?status=,
+// ?monitor= (comma-separated) and ?group= narrow the search, and ?start= and
+// ?end= (RFC3339) set the range, which defaults to the last 24 hours.
+func (s *Server) searchResultsHandler(c *fiber.Ctx) error {
+	if s.storage != nil && !s.storage.Capabilities().SupportsRawResults {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error":   true,
+			"message": "Current storage backend does not support historical queries",
+			"hint":    "Enable BadgerDB storage in config.yml (set storage.backend to 'badger')",
+		})
+	}
+
+	query := storage.ResultQuery{
+		Text:  strings.TrimSpace(c.Query("q")),
+		Limit: c.QueryInt("limit", defaultSearchLimit),
+		End:   time.Now(),
+	}
+	query.Start = query.End.Add(-24 * time.Hour)
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchLimit
+	}
+	if query.Limit > maxSearchLimit {
+		query.Limit = maxSearchLimit
+	}
+
+	if code := c.Query("code"); code != "" {
+		query.StatusCode = c.QueryInt("code")
+		if query.StatusCode < 100 || query.StatusCode > 599 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid status code",
+			})
+		}
+	}
+
+	status, ok := statusQuery(c)
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid status (use up, down or unknown)",
+		})
+	}
+	query.Status = status
+
+	if query.Text == "" && query.StatusCode == 0 && query.Status == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Provide q, code or status to search",
+		})
+	}
+
+	for _, name := range strings.Split(c.Query("monitor"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			if !s.canAccessMonitor(c, name) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error":   true,
+					"message": "Monitor not found",
+				})
+			}
+			query.Monitors = append(query.Monitors, name)
+		}
+	}
+	if group := c.Query("group"); group != "" {
+		monitors := s.monitorManager.GetMonitorsByGroup(group)
+		if len(monitors) == 0 || !s.canAccessGroup(c, group) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error":   true,
+				"message": "Group not found",
+			})
+		}
+		for _, monitor := range monitors {
+			query.Monitors = append(query.Monitors, monitor.GetName())
+		}
+	}
+
+	var err error
+	if startStr := c.Query("start"); startStr != "" {
+		if query.Start, err = time.Parse(time.RFC3339, startStr); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid start timestamp format (use RFC3339)",
+			})
+		}
+	}
+	if endStr := c.Query("end"); endStr != "" {
+		if query.End, err = time.Parse(time.RFC3339, endStr); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid end timestamp format (use RFC3339)",
+			})
+		}
+	}
+	if query.End.Before(query.Start) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "End time must be after start time",
+		})
+	}
+
+	// Tenant searches without a monitor or group cover the tenant's monitors
+	if requestTenant(c) != nil && len(query.Monitors) == 0 {
+		for _, monitor := range s.accessibleMonitors(c) {
+			query.Monitors = append(query.Monitors, monitor.GetName())
+		}
+		if len(query.Monitors) == 0 {
+			return c.JSON(fiber.Map{
+				"query":   query.Text,
+				"start":   query.Start.Format(time.RFC3339),
+				"end":     query.End.Format(time.RFC3339),
+				"results": []*models.MonitorResult{},
+				"total":   0,
+			})
+		}
+	}
+
+	results, err := s.scheduler.SearchResults(query)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to search results")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to search results",
+		})
+	}
+	if results == nil {
+		results = []*models.MonitorResult{}
+	}
+
+	return c.JSON(fiber.Map{
+		"query":   query.Text,
+		"start":   query.Start.Format(time.RFC3339),
+		"end":     query.End.Format(time.RFC3339),
+		"results": results,
+		"total":   len(results),
+	})
+}
+
+// statusQuery reads the ?status= filter, reporting false if it is not a
+// known status
+func statusQuery(c *fiber.Ctx) (models.MonitorStatus, bool) {
+	switch status := models.MonitorStatus(c.Query("status")); status {
+	case "", models.StatusUp, models.StatusDown, models.StatusUnknown:
+		return status, true
+	default:
+		return "", false
+	}
+}
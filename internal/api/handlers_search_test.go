@@ -0,0 +1,68 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestSearchResultsHandler(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	now := time.Now()
+	for _, result := range []*models.MonitorResult{
+		{Monitor: "api", Status: models.StatusDown, Error: "connection refused", Timestamp: now.Add(-3 * time.Minute)},
+		{Monitor: "api", Status: models.StatusUp, HTTPResult: &models.HTTPResult{StatusCode: 200}, Timestamp: now.Add(-2 * time.Minute)},
+		{Monitor: "cdn", Status: models.StatusDown, HTTPResult: &models.HTTPResult{StatusCode: 503}, Metadata: map[string]interface{}{"pop": "fra1"}, Timestamp: now.Add(-time.Minute)},
+		{Monitor: "cdn", Status: models.StatusDown, Error: "connection reset", Timestamp: now.Add(-48 * time.Hour)},
+	} {
+		storeResult(t, server, result)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantResults []string // Monitors of the results, newest first
+	}{
+		{name: "error message", path: "/api/v1/search/results?q=connection", wantStatus: fiber.StatusOK, wantResults: []string{"api"}},
+		{name: "metadata value", path: "/api/v1/search/results?q=FRA", wantStatus: fiber.StatusOK, wantResults: []string{"cdn"}},
+		{name: "status code text", path: "/api/v1/search/results?q=503", wantStatus: fiber.StatusOK, wantResults: []string{"cdn"}},
+		{name: "status", path: "/api/v1/search/results?status=down", wantStatus: fiber.StatusOK, wantResults: []string{"cdn", "api"}},
+		{name: "group", path: "/api/v1/search/results?status=down&group=core", wantStatus: fiber.StatusOK, wantResults: []string{"api"}},
+		{name: "code", path: "/api/v1/search/results?code=200&monitor=api,cdn", wantStatus: fiber.StatusOK, wantResults: []string{"api"}},
+		{name: "wider range", path: "/api/v1/search/results?q=connection&start=" + now.Add(-72*time.Hour).UTC().Format(time.RFC3339), wantStatus: fiber.StatusOK, wantResults: []string{"api", "cdn"}},
+		{name: "limit", path: "/api/v1/search/results?status=down&limit=1", wantStatus: fiber.StatusOK, wantResults: []string{"cdn"}},
+		{name: "no filters", path: "/api/v1/search/results", wantStatus: fiber.StatusBadRequest},
+		{name: "bad code", path: "/api/v1/search/results?code=abc", wantStatus: fiber.StatusBadRequest},
+		{name: "bad status", path: "/api/v1/search/results?status=sideways", wantStatus: fiber.StatusBadRequest},
+		{name: "bad start", path: "/api/v1/search/results?q=x&start=yesterday", wantStatus: fiber.StatusBadRequest},
+		{name: "unknown group", path: "/api/v1/search/results?q=x&group=missing", wantStatus: fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "GET", tt.path, nil, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+			if tt.wantStatus != fiber.StatusOK {
+				return
+			}
+
+			results := payload["results"].([]interface{})
+			if len(results) != len(tt.wantResults) {
+				t.Fatalf("expected %d results, got %d: %v", len(tt.wantResults), len(results), results)
+			}
+			for i, result := range results {
+				if monitor := result.(map[string]interface{})["monitor"]; monitor != tt.wantResults[i] {
+					t.Errorf("result %d is for %v, want %s", i, monitor, tt.wantResults[i])
+				}
+			}
+		})
+	}
+}
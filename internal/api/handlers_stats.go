@@ -0,0 +1,64 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// getMonitorStatsHandler returns outage and latency stats for a monitor over
+// ?period= (default 30d): MTTR, MTBF, outage count and durations, and latency
+// percentiles
+func (s *Server) getMonitorStatsHandler(c *fiber.Ctx) error {
+	if s.storage != nil && !s.storage.Capabilities().SupportsRawResults {
+		return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+			"error":   true,
+			"message": "Current storage backend does not support historical queries",
+			"hint":    "Enable BadgerDB storage in config.yml (set storage.backend to 'badger')",
+		})
+	}
+
+	monitorName := c.Params("name")
+	periodStr := c.Query("period", "30d")
+
+	period, err := models.ParsePeriod(periodStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": "Invalid period format (use a duration like 24h or a number of days like 30d)",
+		})
+	}
+
+	cacheKey := monitorName + "\x00" + periodStr
+	if cached, ok := s.cache.Get(cacheStats, cacheKey); ok {
+		return c.JSON(cached)
+	}
+
+	end := time.Now()
+	start := end.Add(-period)
+
+	stats, err := s.scheduler.GetMonitorStats(monitorName, start, end)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to compute monitor stats")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   true,
+			"message": "Failed to compute monitor stats",
+		})
+	}
+
+	response := fiber.Map{
+		"monitor": monitorName,
+		"period":  periodStr,
+		"start":   start.Format(time.RFC3339),
+		"end":     end.Format(time.RFC3339),
+		"stats":   stats,
+	}
+	s.cache.Set(cacheStats, cacheKey, response, monitorName)
+
+	return c.JSON(response)
+}
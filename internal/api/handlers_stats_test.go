@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestGetMonitorStatsHandler(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	now := time.Now()
+	for _, result := range []*models.MonitorResult{
+		{Monitor: "api", Status: models.StatusUp, Duration: 20 * time.Millisecond, Timestamp: now.Add(-40 * time.Minute)},
+		{Monitor: "api", Status: models.StatusDown, Duration: 40 * time.Millisecond, Timestamp: now.Add(-30 * time.Minute)},
+		{Monitor: "api", Status: models.StatusUp, Duration: 30 * time.Millisecond, Timestamp: now.Add(-20 * time.Minute)},
+	} {
+		storeResult(t, server, result)
+	}
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/monitors/api/stats?period=1d", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	stats := payload["stats"].(map[string]interface{})
+	if stats["outages"] != float64(1) || stats["mttr_seconds"] != float64(600) || stats["ongoing_outage"] != false {
+		t.Errorf("unexpected outage stats: %v", stats)
+	}
+	if latency := stats["latency"].(map[string]interface{}); latency["avg_ms"] != float64(30) || latency["max_ms"] != float64(40) {
+		t.Errorf("unexpected latency stats: %v", latency)
+	}
+
+	status, _ = doJSON(t, server, "GET", "/api/v1/monitors/api/stats?period=soon", nil, nil)
+	if status != fiber.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid period, got %d", status)
+	}
+}
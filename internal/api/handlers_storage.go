@@ -0,0 +1,60 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+)
+
+// aggregationRunReporter is implemented by aggregators that record when they
+// last ran
+type aggregationRunReporter interface {
+	LastRun() (*time.Time, error)
+}
+
+// getStorageHealthHandler describes the storage backend: its type and
+// capabilities, what it holds, and when aggregation and retention cleanup
+// last ran, so operators can check retention and growth at a glance
+func (s *Server) getStorageHealthHandler(c *fiber.Ctx) error {
+	if s.storage == nil {
+		return c.JSON(fiber.Map{
+			"backend":      storage.BackendNone,
+			"capabilities": storage.NewNoOpStore().Capabilities(),
+		})
+	}
+
+	response := fiber.Map{
+		"backend":      storage.ConfiguredBackend(&s.currentConfig().Storage),
+		"capabilities": s.storage.Capabilities(),
+	}
+
+	if reporter, ok := s.storage.(storage.StatsReporter); ok {
+		stats, err := reporter.Stats()
+		if err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Error("Failed to get storage stats")
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":   true,
+				"message": "Failed to get storage stats",
+			})
+		}
+		response["stats"] = stats
+	}
+
+	if reporter, ok := s.aggregator.(aggregationRunReporter); ok {
+		lastRun, err := reporter.LastRun()
+		if err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Warn("Failed to get last aggregation run")
+		} else if lastRun != nil {
+			response["last_aggregation_run"] = lastRun
+		}
+	}
+
+	return c.JSON(response)
+}
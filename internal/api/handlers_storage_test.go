@@ -0,0 +1,57 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestGetStorageHealthHandler(t *testing.T) {
+	server, store := createAggregateTestServer(t)
+	defer server.app.Shutdown()
+	server.currentConfig().Storage.Backend = "badger"
+
+	oldest := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	for i, status := range []models.MonitorStatus{models.StatusUp, models.StatusDown} {
+		if err := store.StoreResult(&models.MonitorResult{Monitor: "api", Status: status, Timestamp: oldest.Add(time.Duration(i) * time.Minute)}); err != nil {
+			t.Fatalf("failed to store result: %v", err)
+		}
+	}
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/admin/storage", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if payload["backend"] != "badger" {
+		t.Errorf("expected the badger backend, got %v", payload["backend"])
+	}
+	if caps := payload["capabilities"].(map[string]interface{}); caps["supports_aggregation"] != true || caps["read_only"] != false {
+		t.Errorf("unexpected capabilities: %v", caps)
+	}
+	stats := payload["stats"].(map[string]interface{})
+	if counts := stats["counts"].(map[string]interface{}); counts["result"] != float64(2) || counts["latest"] != float64(1) {
+		t.Errorf("expected 2 result keys and 1 latest key, got %v", counts)
+	}
+	if stats["oldest_result"] != oldest.Format(time.RFC3339) || stats["retention_days"] != float64(7) {
+		t.Errorf("unexpected stats: %v", stats)
+	}
+	if _, ok := payload["last_aggregation_run"]; ok {
+		t.Errorf("expected no aggregation run yet, got %v", payload["last_aggregation_run"])
+	}
+}
+
+func TestGetStorageHealthHandlerWithoutStorage(t *testing.T) {
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/admin/storage", nil, nil)
+	if status != fiber.StatusOK || payload["backend"] != "none" {
+		t.Fatalf("expected the none backend, got %d: %v", status, payload)
+	}
+	if _, ok := payload["stats"]; ok {
+		t.Errorf("expected no stats without storage, got %v", payload["stats"])
+	}
+}
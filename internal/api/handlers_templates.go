@@ -0,0 +1,186 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// TemplateExpandRequest represents a request to expand a monitor template.
+// Without a group the expansion is only previewed; with a group it is saved
+// as an expand entry on that group.
+type TemplateExpandRequest struct {
+	Values   []string            `json:"values,omitempty"`
+	Vars     []map[string]string `json:"vars,omitempty"`
+	Group    string              `json:"group,omitempty"`
+	Revision *string             `json:"revision,omitempty"`
+}
+
+// getTemplatesHandler lists configured monitor templates
+func (s *Server) getTemplatesHandler(c *fiber.Ctx) error {
+	templates := s.currentConfig().Templates
+	if templates == nil {
+		templates = []config.MonitorTemplate{}
+	}
+
+	return c.JSON(fiber.Map{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// expandTemplateHandler expands a template over a list of values or variable sets
+func (s *Server) expandTemplateHandler(c *fiber.Ctx) error {
+	templateName := c.Params("name")
+
+	var req TemplateExpandRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	if len(req.Values) == 0 && len(req.Vars) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "values or vars is required",
+		})
+	}
+
+	expansion := models.TemplateExpansion{
+		Template: templateName,
+		Values:   req.Values,
+		Vars:     req.Vars,
+	}
+
+	if req.Group == "" {
+		return s.previewTemplateExpansion(c, expansion)
+	}
+
+	// Serialize config writes and reject edits based on a stale revision
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	if ok, err := s.requireConfigRevision(c, req.Revision); !ok {
+		return err
+	}
+
+	// Load current config
+	cfg, err := config.LoadConfig(s.configPath)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to load config for template expansion")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to load configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	if _, ok := cfg.FindTemplate(templateName); !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Template %s not found", templateName),
+		})
+	}
+
+	groupIdx, found := cfg.FindGroup(req.Group)
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Group %s not found", req.Group),
+		})
+	}
+
+	// Record the expansion on the group and regenerate to validate the result
+	group := &cfg.Monitoring.Groups[groupIdx]
+	group.Expand = append(group.Expand, expansion)
+	monitors, err := cfg.ExpandTemplate(expansion)
+	if err == nil {
+		err = cfg.ExpandTemplates()
+	}
+	if err == nil {
+		err = cfg.Validate()
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Template expansion failed",
+			"error":   err.Error(),
+		})
+	}
+
+	// Write config to file
+	if err := cfg.WriteConfig(s.configPath); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to write config after template expansion")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success": false,
+			"message": "Failed to save configuration",
+			"error":   err.Error(),
+		})
+	}
+
+	revision := s.ConfigRevision()
+
+	// Reload configuration
+	if _, err := s.reloadConfigLocked(c.Context()); err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Error("Failed to reload config after template expansion")
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"success":  false,
+			"message":  "Configuration saved but reload failed",
+			"error":    err.Error(),
+			"revision": revision,
+		})
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"template": templateName,
+			"group":    req.Group,
+			"monitors": len(monitors),
+		}).
+		Info("Template expanded successfully")
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success":  true,
+		"message":  fmt.Sprintf("Template %s expanded into %d monitors in group %s", templateName, len(monitors), req.Group),
+		"monitors": monitors,
+		"revision": revision,
+	})
+}
+
+// previewTemplateExpansion returns the monitors an expansion would generate
+func (s *Server) previewTemplateExpansion(c *fiber.Ctx, expansion models.TemplateExpansion) error {
+	if _, ok := s.currentConfig().FindTemplate(expansion.Template); !ok {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"success": false,
+			"message": fmt.Sprintf("Template %s not found", expansion.Template),
+		})
+	}
+
+	monitors, err := s.currentConfig().ExpandTemplate(expansion)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Template expansion failed",
+			"error":   err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"monitors": monitors,
+		"count":    len(monitors),
+	})
+}
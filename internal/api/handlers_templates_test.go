@@ -0,0 +1,156 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+)
+
+// templateTestConfig is the config file behind tests of template expansion
+const templateTestConfig = `
+server:
+  port: "7878"
+
+templates:
+  - name: "https-service"
+    monitor:
+      type: "http"
+      name: "{{value}}"
+      url: "https://{{value}}/health"
+
+monitoring:
+  groups:
+    - name: "fleet"
+      monitors:
+        - type: "http"
+          name: "existing"
+          url: "https://example.com"
+`
+
+func TestGetTemplatesHandler(t *testing.T) {
+	server := createYAMLTestServer(t, templateTestConfig, nil)
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/templates", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if payload["count"] != float64(1) {
+		t.Errorf("expected 1 template, got %v", payload["count"])
+	}
+}
+
+func TestExpandTemplateHandlerPreview(t *testing.T) {
+	server := createYAMLTestServer(t, templateTestConfig, nil)
+	revision := server.ConfigRevision()
+
+	status, payload := doJSON(t, server, "POST", "/api/v1/templates/https-service/expand", map[string]interface{}{
+		"values": []string{"a.example.com", "b.example.com"},
+	}, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if payload["count"] != float64(2) {
+		t.Fatalf("expected 2 monitors, got %v", payload["count"])
+	}
+
+	monitors := payload["monitors"].([]interface{})
+	first := monitors[0].(map[string]interface{})
+	if first["url"] != "https://a.example.com/health" {
+		t.Errorf("unexpected url: %v", first["url"])
+	}
+
+	// Previews never touch the config
+	if server.ConfigRevision() != revision {
+		t.Errorf("expected preview to leave revision at %s, got %s", revision, server.ConfigRevision())
+	}
+}
+
+func TestExpandTemplateHandlerErrors(t *testing.T) {
+	server := createYAMLTestServer(t, templateTestConfig, nil)
+	revision := server.ConfigRevision()
+
+	tests := []struct {
+		name       string
+		path       string
+		body       map[string]interface{}
+		wantStatus int
+	}{
+		{
+			name:       "unknown template",
+			path:       "/api/v1/templates/missing/expand",
+			body:       map[string]interface{}{"values": []string{"a"}},
+			wantStatus: fiber.StatusNotFound,
+		},
+		{
+			name:       "no values",
+			path:       "/api/v1/templates/https-service/expand",
+			body:       map[string]interface{}{},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "undefined variable",
+			path:       "/api/v1/templates/https-service/expand",
+			body:       map[string]interface{}{"vars": []map[string]string{{"host": "a"}}},
+			wantStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:       "save without revision",
+			path:       "/api/v1/templates/https-service/expand",
+			body:       map[string]interface{}{"values": []string{"a"}, "group": "fleet"},
+			wantStatus: fiber.StatusPreconditionRequired,
+		},
+		{
+			name:       "duplicate generated name",
+			path:       "/api/v1/templates/https-service/expand",
+			body:       map[string]interface{}{"values": []string{"existing"}, "group": "fleet", "revision": revision},
+			wantStatus: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "POST", tt.path, tt.body, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+		})
+	}
+}
+
+func TestExpandTemplateHandlerSavesExpansion(t *testing.T) {
+	server := createYAMLTestServer(t, templateTestConfig, nil)
+	revision := server.ConfigRevision()
+
+	status, payload := doJSON(t, server, "POST", "/api/v1/templates/https-service/expand", map[string]interface{}{
+		"values":   []string{"a.example.com", "b.example.com"},
+		"group":    "fleet",
+		"revision": revision,
+	}, nil)
+	if status != fiber.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", status, payload)
+	}
+	if payload["revision"] == revision || payload["revision"] != server.ConfigRevision() {
+		t.Errorf("expected the revision of the changed config, got %v", payload["revision"])
+	}
+
+	if server.monitorManager.GetMonitorByName("b.example.com") == nil {
+		t.Error("expected generated monitor to be loaded after reload")
+	}
+
+	cfg, err := config.LoadConfig(server.configPath)
+	if err != nil {
+		t.Fatalf("failed to load saved config: %v", err)
+	}
+	group := cfg.Monitoring.Groups[0]
+	if len(group.Expand) != 1 || group.Expand[0].Template != "https-service" {
+		t.Fatalf("expected expansion to be saved on group, got %+v", group.Expand)
+	}
+
+	// Generated monitors are managed through the template, not the monitor API
+	status, payload = doJSON(t, server, "DELETE", "/api/v1/monitors/a.example.com", nil, ifMatch(server))
+	if status != fiber.StatusBadRequest {
+		t.Fatalf("expected 400 deleting generated monitor, got %d: %v", status, payload)
+	}
+}
@@ -6,6 +6,9 @@ import (
 	"io"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,22 +17,14 @@ import (
 
 	"github.com/1broseidon/hallmonitor/internal/config"
 	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+	"github.com/1broseidon/hallmonitor/internal/storage"
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
 func createTestServer(t *testing.T) *Server {
 	t.Helper()
 
-	// Create test logger (discard output)
-	logger, err := logging.InitLogger(logging.Config{
-		Level:  "error",
-		Format: "json",
-		Output: "stdout",
-	})
-	if err != nil {
-		t.Fatalf("failed to create test logger: %v", err)
-	}
-
 	// Create test config
 	cfg := &config.Config{
 		Server: config.ServerConfig{
@@ -43,10 +38,56 @@ func createTestServer(t *testing.T) *Server {
 	reg := prometheus.NewRegistry()
 
 	// Create server (NewServer creates its own scheduler, monitor manager, etc.)
-	server := NewServer(cfg, "config.yml", logger, reg)
+	server := NewServer(cfg, "config.yml", testLogger(t), reg)
 	return server
 }
 
+// createYAMLTestServer creates a server from configYAML, written to a temp
+// config file so config writes work, and loads its monitors. With a store the
+// server keeps results in it, aggregating them unless the store is read-only.
+func createYAMLTestServer(t *testing.T, configYAML string, store *storage.BadgerStore) *Server {
+	t.Helper()
+
+	configPath := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(configPath, []byte(configYAML), 0644); err != nil {
+		t.Fatalf("failed to write temp config: %v", err)
+	}
+
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("failed to load temp config: %v", err)
+	}
+
+	logger := testLogger(t)
+	var server *Server
+	if store == nil {
+		server = NewServer(cfg, configPath, logger, prometheus.NewRegistry())
+	} else {
+		var aggregator scheduler.Aggregator
+		if !store.Capabilities().ReadOnly {
+			aggregator = storage.NewAggregator(store, logger)
+		}
+		server = NewServerWithStorage(cfg, configPath, logger, prometheus.NewRegistry(), store, aggregator, store)
+	}
+	loadMonitors(t, server, cfg.Monitoring.Groups)
+	return server
+}
+
+// testLogger creates a logger that only reports errors
+func testLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+
+	logger, err := logging.InitLogger(logging.Config{
+		Level:  "error",
+		Format: "json",
+		Output: "stdout",
+	})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	return logger
+}
+
 func loadMonitors(t *testing.T, server *Server, groups []models.MonitorGroup) {
 	t.Helper()
 
@@ -156,6 +197,76 @@ func TestMetricsHandler(t *testing.T) {
 	}
 }
 
+func TestMetricsHandlerOpenMetricsExemplars(t *testing.T) {
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+
+	result := &models.MonitorResult{Monitor: "homepage", Timestamp: time.Unix(1700000000, 5)}
+	server.metrics.RecordHTTPCheck("homepage", "default", "GET", 200, 120*time.Millisecond, result.ID())
+
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "application/openmetrics-text") {
+		t.Fatalf("expected OpenMetrics, got %s", contentType)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	exemplar := regexp.MustCompile(`hallmonitor_http_response_time_seconds_bucket\{[^}]*\} 1 # \{[^}]*result_id="1700000000000000005"[^}]*\}`)
+	if !exemplar.Match(body) {
+		t.Errorf("expected an exemplar on the response time histogram, got:\n%s", body)
+	}
+}
+
+func TestGetResultHandler(t *testing.T) {
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+
+	enabled := true
+	loadMonitors(t, server, []models.MonitorGroup{
+		{
+			Name: "core",
+			Monitors: []models.Monitor{
+				{Type: models.MonitorTypeHTTP, Name: "homepage", URL: "https://example.com", Enabled: &enabled},
+			},
+		},
+	})
+
+	result := &models.MonitorResult{Monitor: "homepage", Type: models.MonitorTypeHTTP, Group: "core", Status: models.StatusDown, Error: "http 500", Timestamp: time.Unix(1700000000, 123456789)}
+	storeResult(t, server, result)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "by monitor", path: "/api/v1/monitors/homepage/results/" + result.ID(), wantStatus: fiber.StatusOK},
+		{name: "across monitors", path: "/api/v1/results/" + result.ID(), wantStatus: fiber.StatusOK},
+		{name: "unknown ID", path: "/api/v1/results/1700000000000000000", wantStatus: fiber.StatusNotFound},
+		{name: "invalid ID", path: "/api/v1/results/yesterday", wantStatus: fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "GET", tt.path, nil, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+			if status != fiber.StatusOK {
+				return
+			}
+			stored := payload["result"].(map[string]interface{})
+			if payload["monitor"] != "homepage" || payload["id"] != result.ID() || stored["error"] != "http 500" {
+				t.Errorf("unexpected result %v", payload)
+			}
+		})
+	}
+}
+
 func TestGetMonitorsHandler(t *testing.T) {
 	server := createTestServer(t)
 	defer server.app.Shutdown()
@@ -281,10 +392,13 @@ func TestGetMonitorHandlerReturnsDetailedStatus(t *testing.T) {
 			Name: "core",
 			Monitors: []models.Monitor{
 				{
-					Type:    models.MonitorTypeHTTP,
-					Name:    "homepage",
-					URL:     "https://example.com",
-					Enabled: &enabled,
+					Type:       models.MonitorTypeHTTP,
+					Name:       "homepage",
+					URL:        "https://example.com",
+					Enabled:    &enabled,
+					Owner:      "alice",
+					Team:       "web",
+					RunbookURL: "https://wiki.example.com/runbooks/homepage",
 				},
 			},
 		},
@@ -342,6 +456,10 @@ func TestGetMonitorHandlerReturnsDetailedStatus(t *testing.T) {
 	if payload["metadata"] == nil {
 		t.Fatalf("expected metadata to be present")
 	}
+
+	if payload["owner"] != "alice" || payload["team"] != "web" || payload["runbook_url"] != "https://wiki.example.com/runbooks/homepage" {
+		t.Fatalf("expected ownership details, got %v", payload)
+	}
 }
 
 func TestGetGroupsHandler(t *testing.T) {
@@ -554,6 +672,14 @@ monitoring:
 	if payload["message"] != "Configuration reloaded successfully" {
 		t.Fatalf("unexpected message: %v", payload["message"])
 	}
+
+	changes, ok := payload["changes"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected changes in response, got %v", payload["changes"])
+	}
+	if added, _ := changes["added"].([]interface{}); len(added) != 1 || added[0] != "test-monitor" {
+		t.Fatalf("expected test-monitor to be reported as added, got %v", changes["added"])
+	}
 }
 
 func TestGrafanaEndpointsReturnEmptyArrays(t *testing.T) {
@@ -653,6 +779,11 @@ func TestDashboardHandler(t *testing.T) {
 	if !contains(bodyStr, "Hall Monitor") {
 		t.Error("dashboard missing expected title")
 	}
+
+	// Keyboard users can skip the header to the main content
+	if !contains(bodyStr, `href="#main"`) || !contains(bodyStr, `id="main"`) {
+		t.Error("dashboard missing skip link or main landmark")
+	}
 }
 
 func TestDashboardAmbientHandler(t *testing.T) {
@@ -690,6 +821,9 @@ func TestDashboardAmbientHandler(t *testing.T) {
 	if !contains(bodyStr, "Ambient View") || !contains(bodyStr, "Hall Monitor") {
 		t.Error("ambient dashboard missing expected content")
 	}
+	if !contains(bodyStr, `id="main"`) || !contains(bodyStr, `aria-live="polite"`) {
+		t.Error("ambient dashboard missing main landmark or live status")
+	}
 }
 
 func TestGetMonitorHistoryHandler(t *testing.T) {
@@ -804,6 +938,59 @@ func TestGetMonitorHistoryHandlerWithTimeRange(t *testing.T) {
 	}
 }
 
+func TestGetMonitorHistoryHandlerFilters(t *testing.T) {
+	server := createTestServer(t)
+	defer server.app.Shutdown()
+
+	now := time.Now()
+	for _, result := range []*models.MonitorResult{
+		{Monitor: "api", Status: models.StatusDown, Error: "dial tcp: i/o timeout", Timestamp: now.Add(-4 * time.Minute)},
+		{Monitor: "api", Status: models.StatusUp, Timestamp: now.Add(-3 * time.Minute)},
+		{Monitor: "api", Status: models.StatusDown, Error: "connection refused", Timestamp: now.Add(-2 * time.Minute)},
+		{Monitor: "api", Status: models.StatusUp, Metadata: map[string]interface{}{"note": "timeout raised"}, Timestamp: now.Add(-time.Minute)},
+		{Monitor: "web", Status: models.StatusDown, Error: "Timeout awaiting headers", Timestamp: now.Add(-time.Minute)},
+	} {
+		storeResult(t, server, result)
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantErrors []string // Errors of the results, newest first
+	}{
+		{name: "failures only", query: "?status=down", wantStatus: fiber.StatusOK, wantErrors: []string{"connection refused", "dial tcp: i/o timeout"}},
+		{name: "error text", query: "?errorContains=TIMEOUT", wantStatus: fiber.StatusOK, wantErrors: []string{"dial tcp: i/o timeout"}},
+		{name: "both", query: "?status=down&errorContains=refused", wantStatus: fiber.StatusOK, wantErrors: []string{"connection refused"}},
+		{name: "no match", query: "?status=up&errorContains=refused", wantStatus: fiber.StatusOK, wantErrors: []string{}},
+		{name: "limit", query: "?status=down&limit=1", wantStatus: fiber.StatusOK, wantErrors: []string{"connection refused"}},
+		{name: "bad status", query: "?status=sideways", wantStatus: fiber.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, payload := doJSON(t, server, "GET", "/api/v1/monitors/api/history"+tt.query, nil, nil)
+			if status != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %v", tt.wantStatus, status, payload)
+			}
+			if tt.wantStatus != fiber.StatusOK {
+				return
+			}
+
+			results := payload["results"].([]interface{})
+			if len(results) != len(tt.wantErrors) {
+				t.Fatalf("expected %d results, got %d: %v", len(tt.wantErrors), len(results), results)
+			}
+			for i, result := range results {
+				got, _ := result.(map[string]interface{})["error"].(string)
+				if got != tt.wantErrors[i] {
+					t.Errorf("result %d has error %q, want %q", i, got, tt.wantErrors[i])
+				}
+			}
+		})
+	}
+}
+
 func TestGetMonitorHistoryHandlerNotFound(t *testing.T) {
 	server := createTestServer(t)
 	defer server.app.Shutdown()
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+)
+
+// getTimeoutsHandler lists monitors whose checks routinely run close to or
+// over their timeout, with a suggested config fix. ?all=true includes every
+// monitor with recorded checks.
+func (s *Server) getTimeoutsHandler(c *fiber.Ctx) error {
+	all := c.QueryBool("all")
+
+	timeouts := make([]scheduler.TimeoutPressure, 0)
+	for _, pressure := range s.scheduler.Timeouts().List() {
+		if !s.canAccessMonitor(c, pressure.Monitor) {
+			continue
+		}
+		if all || pressure.Level != scheduler.PressureOK {
+			timeouts = append(timeouts, pressure)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"timeouts": timeouts,
+		"count":    len(timeouts),
+	})
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestGetTimeoutsHandler(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	tracker := server.scheduler.Timeouts()
+	for i := 0; i < 5; i++ {
+		tracker.Record("api", time.Second, time.Second, true)
+		tracker.Record("cdn", time.Second, 10*time.Millisecond, false)
+	}
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/timeouts", nil, nil)
+	if status != fiber.StatusOK || payload["count"] != float64(1) {
+		t.Fatalf("expected 1 monitor under pressure, got %d: %v", status, payload)
+	}
+	entry := payload["timeouts"].([]interface{})[0].(map[string]interface{})
+	if entry["monitor"] != "api" || entry["level"] != "high" || entry["suggestion"] == "" {
+		t.Errorf("unexpected timeout pressure entry: %v", entry)
+	}
+
+	status, payload = doJSON(t, server, "GET", "/api/v1/timeouts?all=true", nil, nil)
+	if status != fiber.StatusOK || payload["count"] != float64(2) {
+		t.Fatalf("expected 2 tracked monitors, got %d: %v", status, payload)
+	}
+
+	status, payload = doJSON(t, server, "GET", "/api/v1/monitors/api", nil, nil)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", status, payload)
+	}
+	if pressure, ok := payload["timeout_pressure"].(map[string]interface{}); !ok || pressure["overruns"] != float64(5) {
+		t.Errorf("expected timeout_pressure on monitor detail, got %v", payload["timeout_pressure"])
+	}
+}
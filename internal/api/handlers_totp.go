@@ -0,0 +1,204 @@
+package api
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/accounts"
+	"github.com/1broseidon/hallmonitor/internal/i18n"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+)
+
+// TOTPCodeRequest carries a two-factor or recovery code
+type TOTPCodeRequest struct {
+	Code string `json:"code" form:"code"`
+	Next string `json:"next,omitempty" form:"next"`
+}
+
+// TOTPPasswordRequest confirms a two-factor change with the user's password
+type TOTPPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// verifyLoginHandler completes a sign-in waiting for a two-factor code. A
+// recovery code is accepted in place of the code.
+func (s *Server) verifyLoginHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+
+	isJSON := strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON)
+
+	var req TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+	req.Next = safeRedirect(req.Next)
+
+	ip := s.clientIP(c).String()
+	token, session, err := s.accounts.VerifyChallenge(c.Cookies(challengeCookie), req.Code, ip)
+	if err != nil {
+		status, key := fiber.StatusUnauthorized, "login.error.code"
+		locked := errors.Is(err, accounts.ErrTooManyAttempts)
+		expired := locked || errors.Is(err, accounts.ErrChallengeExpired)
+		switch {
+		case locked:
+			status, key = fiber.StatusTooManyRequests, "login.error.locked"
+			c.ClearCookie(challengeCookie)
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithFields(map[string]interface{}{"ip": ip}).
+				Warn("Two-factor sign-in locked out")
+		case expired:
+			key = "login.error.expired"
+			c.ClearCookie(challengeCookie)
+		case errors.Is(err, accounts.ErrInvalidCode):
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithFields(map[string]interface{}{"ip": ip}).
+				Warn("Failed two-factor attempt")
+		default:
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Error("Failed to verify two-factor code")
+			status, key = fiber.StatusInternalServerError, "login.error.failed"
+		}
+
+		if isJSON {
+			return c.Status(status).JSON(fiber.Map{
+				"success": false,
+				"message": i18n.Get(i18n.DefaultLanguage).T(key),
+			})
+		}
+		return s.renderLoginPage(c, status, loginPageData{Next: req.Next, ErrorKey: key, Challenge: !expired})
+	}
+
+	c.ClearCookie(challengeCookie)
+	return s.completeLogin(c, token, session, req.Next, isJSON)
+}
+
+// getTOTPHandler reports the signed-in user's two-factor status
+func (s *Server) getTOTPHandler(c *fiber.Ctx) error {
+	user := requestUser(c)
+	return c.JSON(fiber.Map{
+		"enabled":                  user.TOTPEnabled(),
+		"pending":                  user.TOTPPendingSecret != "",
+		"recovery_codes_remaining": len(user.RecoveryCodes),
+	})
+}
+
+// beginTOTPHandler starts two-factor enrollment, returning the secret to add
+// to an authenticator app
+func (s *Server) beginTOTPHandler(c *fiber.Ctx) error {
+	secret, uri, err := s.accounts.BeginTOTP(requestUser(c).Username)
+	if err != nil {
+		return s.accountError(c, err, "Failed to start two-factor enrollment")
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"secret":  secret,
+		"uri":     uri,
+	})
+}
+
+// confirmTOTPHandler enables two-factor authentication with a code from the
+// enrolled authenticator, returning recovery codes that are only shown once
+func (s *Server) confirmTOTPHandler(c *fiber.Ctx) error {
+	var req TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	username := requestUser(c).Username
+	codes, err := s.accounts.ConfirmTOTP(username, req.Code)
+	if err != nil {
+		return s.accountError(c, err, "Failed to enable two-factor authentication")
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{"username": username}).
+		Info("Two-factor authentication enabled")
+
+	return c.JSON(fiber.Map{
+		"success":        true,
+		"recovery_codes": codes,
+	})
+}
+
+// disableTOTPHandler turns off two-factor authentication for the signed-in
+// user after checking their password
+func (s *Server) disableTOTPHandler(c *fiber.Ctx) error {
+	var req TOTPPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	username := requestUser(c).Username
+	if err := s.accounts.DisableTOTP(username, req.Password); err != nil {
+		return s.accountError(c, err, "Failed to disable two-factor authentication")
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{"username": username}).
+		Info("Two-factor authentication disabled")
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// regenerateRecoveryCodesHandler replaces the signed-in user's recovery codes
+func (s *Server) regenerateRecoveryCodesHandler(c *fiber.Ctx) error {
+	var req TOTPPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"success": false,
+			"message": "Invalid request body",
+			"error":   err.Error(),
+		})
+	}
+
+	codes, err := s.accounts.RegenerateRecoveryCodes(requestUser(c).Username, req.Password)
+	if err != nil {
+		return s.accountError(c, err, "Failed to regenerate recovery codes")
+	}
+	return c.JSON(fiber.Map{
+		"success":        true,
+		"recovery_codes": codes,
+	})
+}
+
+// resetUserTOTPHandler turns off two-factor authentication for a user who
+// lost their authenticator
+func (s *Server) resetUserTOTPHandler(c *fiber.Ctx) error {
+	if s.accounts == nil {
+		return accountsDisabled(c)
+	}
+
+	username := c.Params("username")
+	if err := s.accounts.ResetTOTP(username); err != nil {
+		return s.accountError(c, err, "Failed to reset two-factor authentication")
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{"username": username}).
+		Warn("Two-factor authentication reset by an admin")
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Two-factor authentication reset",
+	})
+}
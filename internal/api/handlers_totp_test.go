@@ -0,0 +1,212 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// totpAt computes the code an authenticator app shows for secret, offset by
+// whole time steps from now
+func totpAt(t *testing.T, secret string, steps int64) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("invalid secret: %v", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(time.Now().Unix()/30+steps))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+// enrollTOTPViaAPI enables TOTP for the session's user, returning the secret
+// and recovery codes
+func enrollTOTPViaAPI(t *testing.T, server *Server, cookie *http.Cookie) (string, []interface{}) {
+	t.Helper()
+
+	status, payload := withSession(t, server, "POST", "/api/v1/session/totp", nil, cookie)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected enrollment to start, got %d %v", status, payload)
+	}
+	secret := payload["secret"].(string)
+	if !strings.HasPrefix(payload["uri"].(string), "otpauth://totp/") {
+		t.Errorf("expected an otpauth URI, got %v", payload["uri"])
+	}
+
+	status, payload = withSession(t, server, "POST", "/api/v1/session/totp/confirm", TOTPCodeRequest{Code: totpAt(t, secret, 0)}, cookie)
+	if status != fiber.StatusOK {
+		t.Fatalf("expected enrollment to be confirmed, got %d %v", status, payload)
+	}
+	return secret, payload["recovery_codes"].([]interface{})
+}
+
+// postLoginForm submits a sign-in form with optional cookies
+func postLoginForm(t *testing.T, server *Server, path string, form url.Values, cookies ...*http.Cookie) *http.Response {
+	t.Helper()
+
+	req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return resp
+}
+
+// responseCookie returns a cookie set by a response
+func responseCookie(resp *http.Response, name string) *http.Cookie {
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == name && cookie.Value != "" {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestTOTPEnrollmentHandlers(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	cookie := login(t, server, "alice", "correct horse")
+
+	status, _ := withSession(t, server, "POST", "/api/v1/session/totp/confirm", TOTPCodeRequest{Code: "123456"}, cookie)
+	if status != fiber.StatusConflict {
+		t.Errorf("expected confirming without enrolling to conflict, got %d", status)
+	}
+
+	_, codes := enrollTOTPViaAPI(t, server, cookie)
+	if len(codes) != 10 {
+		t.Errorf("expected 10 recovery codes, got %d", len(codes))
+	}
+
+	status, payload := withSession(t, server, "GET", "/api/v1/session/totp", nil, cookie)
+	if status != fiber.StatusOK || payload["enabled"] != true || payload["recovery_codes_remaining"].(float64) != 10 {
+		t.Errorf("expected TOTP to be enabled, got %d %v", status, payload)
+	}
+
+	status, payload = withSession(t, server, "POST", "/api/v1/session/totp/recovery-codes", TOTPPasswordRequest{Password: "correct horse"}, cookie)
+	if status != fiber.StatusOK || len(payload["recovery_codes"].([]interface{})) != 10 {
+		t.Errorf("expected new recovery codes, got %d %v", status, payload)
+	}
+
+	status, _ = withSession(t, server, "DELETE", "/api/v1/session/totp", TOTPPasswordRequest{Password: "wrong password"}, cookie)
+	if status != fiber.StatusUnauthorized {
+		t.Errorf("expected disabling to check the password, got %d", status)
+	}
+	status, _ = withSession(t, server, "DELETE", "/api/v1/session/totp", TOTPPasswordRequest{Password: "correct horse"}, cookie)
+	if status != fiber.StatusOK {
+		t.Errorf("expected TOTP to be disabled, got %d", status)
+	}
+
+	// Tokens have no user to enroll
+	if status, _ := doJSON(t, server, "POST", "/api/v1/session/totp", nil, bearer("admin-token")); status != fiber.StatusBadRequest {
+		t.Errorf("expected tokens to be refused enrollment, got %d", status)
+	}
+}
+
+func TestLoginWithTOTP(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	secret, recovery := enrollTOTPViaAPI(t, server, login(t, server, "alice", "correct horse"))
+
+	// The password alone only starts the sign-in
+	status, payload := doJSON(t, server, "POST", "/login", LoginRequest{Username: "alice", Password: "correct horse"}, nil)
+	if status != fiber.StatusAccepted || payload["totp_required"] != true {
+		t.Fatalf("expected a two-factor challenge, got %d %v", status, payload)
+	}
+
+	resp := postLoginForm(t, server, "/login", url.Values{"username": {"alice"}, "password": {"correct horse"}, "next": {"/dashboard/ambient"}})
+	challenge := responseCookie(resp, challengeCookie)
+	if resp.StatusCode != fiber.StatusOK || challenge == nil || responseCookie(resp, sessionCookie) != nil {
+		t.Fatalf("expected a challenge cookie and no session, got %d", resp.StatusCode)
+	}
+	if status, _ := withSession(t, server, "GET", "/api/v1/session", nil, &http.Cookie{Name: sessionCookie, Value: challenge.Value}); status != fiber.StatusUnauthorized {
+		t.Errorf("expected the challenge to grant no access, got %d", status)
+	}
+
+	resp = postLoginForm(t, server, "/login/verify", url.Values{"code": {"000000"}, "next": {"/dashboard/ambient"}}, challenge)
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("expected a wrong code to be rejected, got %d", resp.StatusCode)
+	}
+
+	// The enrollment code was used, so verify with the next one
+	resp = postLoginForm(t, server, "/login/verify", url.Values{"code": {totpAt(t, secret, 1)}, "next": {"/dashboard/ambient"}}, challenge)
+	if resp.StatusCode != fiber.StatusSeeOther || resp.Header.Get("Location") != "/dashboard/ambient" {
+		t.Fatalf("expected a redirect after verifying, got %d %q", resp.StatusCode, resp.Header.Get("Location"))
+	}
+	session := responseCookie(resp, sessionCookie)
+	if session == nil {
+		t.Fatalf("expected a session cookie")
+	}
+	if status, _ := withSession(t, server, "GET", "/api/v1/session", nil, session); status != fiber.StatusOK {
+		t.Errorf("expected the verified session to work, got %d", status)
+	}
+
+	// Recovery codes complete a sign-in once
+	resp = postLoginForm(t, server, "/login", url.Values{"username": {"alice"}, "password": {"correct horse"}})
+	challenge = responseCookie(resp, challengeCookie)
+	resp = postLoginForm(t, server, "/login/verify", url.Values{"code": {recovery[0].(string)}}, challenge)
+	if resp.StatusCode != fiber.StatusSeeOther {
+		t.Errorf("expected a recovery code to sign in, got %d", resp.StatusCode)
+	}
+	resp = postLoginForm(t, server, "/login/verify", url.Values{"code": {recovery[0].(string)}}, challenge)
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Errorf("expected the used challenge to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestLoginWithTOTPLockout(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	enrollTOTPViaAPI(t, server, login(t, server, "alice", "correct horse"))
+
+	// Every wrong code is sent on a fresh sign-in
+	for i := 0; i < 5; i++ {
+		resp := postLoginForm(t, server, "/login", url.Values{"username": {"alice"}, "password": {"correct horse"}})
+		challenge := responseCookie(resp, challengeCookie)
+		if challenge == nil {
+			t.Fatalf("expected a challenge on sign-in %d, got %d", i+1, resp.StatusCode)
+		}
+		postLoginForm(t, server, "/login/verify", url.Values{"code": {"000000"}}, challenge)
+	}
+
+	status, payload := doJSON(t, server, "POST", "/login", LoginRequest{Username: "alice", Password: "correct horse"}, nil)
+	if status != fiber.StatusTooManyRequests || payload["success"] != false {
+		t.Errorf("expected signing in after 5 wrong codes to be rejected, got %d %v", status, payload)
+	}
+}
+
+func TestResetUserTOTPHandler(t *testing.T) {
+	server := createAccountsTestServer(t)
+	defer server.app.Shutdown()
+
+	enrollTOTPViaAPI(t, server, login(t, server, "alice", "correct horse"))
+
+	if status, _ := doJSON(t, server, "DELETE", "/api/v1/users/alice/totp", nil, bearer("acme-token")); status != fiber.StatusForbidden {
+		t.Errorf("expected tenants to be refused, got %d", status)
+	}
+	if status, _ := doJSON(t, server, "DELETE", "/api/v1/users/alice/totp", nil, bearer("admin-token")); status != fiber.StatusOK {
+		t.Fatalf("expected admins to reset TOTP, got %d", status)
+	}
+	login(t, server, "alice", "correct horse")
+}
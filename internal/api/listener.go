@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+)
+
+// systemdFirstFD is the first file descriptor systemd passes to an activated
+// service (SD_LISTEN_FDS_START)
+const systemdFirstFD = 3
+
+// listen opens the listener the server accepts connections on: a socket
+// passed by systemd socket activation, a unix socket at server.socket, or
+// TCP on server.host:server.port. It returns a description for logging.
+func (s *Server) listen() (net.Listener, string, error) {
+	if count := systemdListenFDs(); count > 0 {
+		if count > 1 {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithFields(map[string]interface{}{"fds": count}).
+				Warn("systemd passed several sockets; using the first")
+		}
+		file := os.NewFile(systemdFirstFD, "systemd-socket")
+		listener, err := net.FileListener(file)
+		file.Close() // FileListener holds its own copy
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to use systemd socket: %w", err)
+		}
+		return listener, "systemd:" + listener.Addr().String(), nil
+	}
+
+	if path := s.currentConfig().Server.Socket; path != "" {
+		listener, err := listenUnix(path, s.currentConfig().Server.SocketMode)
+		if err != nil {
+			return nil, "", err
+		}
+		return listener, "unix:" + path, nil
+	}
+
+	address := s.currentConfig().Server.Host + ":" + s.currentConfig().Server.Port
+	var lc net.ListenConfig
+	if s.currentConfig().Server.ReusePort {
+		if reusePortSupported {
+			lc.Control = reusePortControl
+		} else {
+			s.logger.WithComponent(logging.ComponentAPI).
+				Warn("server.reusePort is not supported on this platform; ignoring")
+		}
+	}
+	listener, err := lc.Listen(context.Background(), "tcp", address)
+	if err != nil {
+		return nil, "", err
+	}
+	return listener, address, nil
+}
+
+// systemdListenFDs returns how many sockets systemd passed to this process,
+// and clears the variables so child processes do not claim them too
+func systemdListenFDs() int {
+	defer os.Unsetenv("LISTEN_PID")
+	defer os.Unsetenv("LISTEN_FDS")
+	defer os.Unsetenv("LISTEN_FDNAMES")
+
+	if pid, err := strconv.Atoi(os.Getenv("LISTEN_PID")); err != nil || pid != os.Getpid() {
+		return 0
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 0 {
+		return 0
+	}
+	return count
+}
+
+// listenUnix listens on a unix socket, replacing a stale socket left by a
+// previous run, and sets its permissions (octal, default 0660)
+func listenUnix(path, mode string) (net.Listener, error) {
+	perm := fs.FileMode(0o660)
+	if mode != "" {
+		parsed, err := strconv.ParseUint(mode, 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid socket mode %q: %w", mode, err)
+		}
+		perm = fs.FileMode(parsed)
+	}
+
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&fs.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to check socket path: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, perm); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+	return listener, nil
+}
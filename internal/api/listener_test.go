@@ -0,0 +1,199 @@
+package api
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestSystemdListenFDs(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+
+	tests := []struct {
+		name      string
+		listenPID string
+		listenFDs string
+		want      int
+	}{
+		{name: "not activated", want: 0},
+		{name: "one socket", listenPID: pid, listenFDs: "1", want: 1},
+		{name: "several sockets", listenPID: pid, listenFDs: "2", want: 2},
+		{name: "meant for another process", listenPID: "1", listenFDs: "1", want: 0},
+		{name: "malformed count", listenPID: pid, listenFDs: "many", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LISTEN_PID", tt.listenPID)
+			t.Setenv("LISTEN_FDS", tt.listenFDs)
+
+			if got := systemdListenFDs(); got != tt.want {
+				t.Errorf("systemdListenFDs() = %d, want %d", got, tt.want)
+			}
+			if os.Getenv("LISTEN_PID") != "" || os.Getenv("LISTEN_FDS") != "" {
+				t.Errorf("expected the activation variables to be cleared")
+			}
+		})
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hallmonitor.sock")
+
+	// A socket left by a previous run is replaced
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatalf("failed to create stale socket: %v", err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	listener, err := listenUnix(path, "0600")
+	if err != nil {
+		t.Fatalf("listenUnix() error = %v", err)
+	}
+	defer listener.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("expected socket mode 0600, got %o", perm)
+	}
+
+	// Anything else at the path is left alone
+	regular := filepath.Join(dir, "config.yml")
+	if err := os.WriteFile(regular, []byte("server: {}\n"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := listenUnix(regular, ""); err == nil {
+		t.Errorf("expected an error for a path that is not a socket")
+	}
+}
+
+// serveOnSocket starts the server on a unix socket and returns a client for it
+func serveOnSocket(t *testing.T, server *Server) *http.Client {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "hallmonitor.sock")
+	server.currentConfig().Server.Socket = path
+	go server.Start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("server did not create %s", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", path)
+		},
+	}}
+}
+
+// socketGet sends a GET over the socket client and returns the status
+func socketGet(t *testing.T, client *http.Client, path string, headers map[string]string) int {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "http://hallmonitor"+path, nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServeUnixSocket(t *testing.T) {
+	server := createTestServer(t)
+	server.currentConfig().Server.AdminAllowlist = []string{"198.51.100.0/24"}
+	client := serveOnSocket(t, server)
+	defer server.app.Shutdown()
+
+	if status := socketGet(t, client, "/health", nil); status != fiber.StatusOK {
+		t.Errorf("expected 200 from /health, got %d", status)
+	}
+
+	// The local proxy's forwarded headers identify the client
+	if status := socketGet(t, client, "/api/v1/config", map[string]string{fiber.HeaderXForwardedFor: "198.51.100.4"}); status != fiber.StatusOK {
+		t.Errorf("expected 200 for a forwarded allowlisted client, got %d", status)
+	}
+	if status := socketGet(t, client, "/api/v1/config", map[string]string{fiber.HeaderXForwardedFor: "192.0.2.1"}); status != fiber.StatusForbidden {
+		t.Errorf("expected 403 for a forwarded client outside the allowlist, got %d", status)
+	}
+}
+
+func TestStopDrainsRequests(t *testing.T) {
+	server := createTestServer(t)
+	server.currentConfig().Server.ShutdownGracePeriod = models.Duration(5 * time.Second)
+	started := make(chan struct{})
+	server.app.Get("/slow", func(c *fiber.Ctx) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		return c.SendString("done")
+	})
+	client := serveOnSocket(t, server)
+
+	// An open event stream must not hold the drain open
+	stream, err := client.Get("http://hallmonitor/api/v1/stream")
+	if err != nil {
+		t.Fatalf("failed to open stream: %v", err)
+	}
+	defer stream.Body.Close()
+
+	status := make(chan int, 1)
+	go func() { status <- socketGet(t, client, "/slow", nil) }()
+	<-started
+
+	begin := time.Now()
+	if err := server.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed > 3*time.Second {
+		t.Errorf("expected Stop to return once requests finished, took %s", elapsed)
+	}
+	if got := <-status; got != fiber.StatusOK {
+		t.Errorf("expected the in-flight request to complete, got %d", got)
+	}
+}
+
+func TestReusePort(t *testing.T) {
+	if !reusePortSupported {
+		t.Skip("SO_REUSEPORT is not supported on this platform")
+	}
+
+	lc := net.ListenConfig{Control: reusePortControl}
+	first, err := lc.Listen(context.Background(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer first.Close()
+
+	// A replacement process binds the same port while the first still listens
+	second, err := lc.Listen(context.Background(), "tcp", first.Addr().String())
+	if err != nil {
+		t.Fatalf("expected a second listener on %s, got %v", first.Addr(), err)
+	}
+	second.Close()
+}
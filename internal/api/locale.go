@@ -0,0 +1,21 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/i18n"
+)
+
+// requestCatalog returns the message catalog for a page request: a supported
+// ?lang= override, then server.locale, then the browser's Accept-Language
+func (s *Server) requestCatalog(c *fiber.Ctx) *i18n.Catalog {
+	c.Vary(fiber.HeaderAcceptLanguage)
+
+	if lang := c.Query("lang"); i18n.Supported(lang) {
+		return i18n.Get(lang)
+	}
+	if locale := s.currentConfig().Server.Locale; locale != "" {
+		return i18n.Get(locale)
+	}
+	return i18n.Get(i18n.Negotiate(c.Get(fiber.HeaderAcceptLanguage)))
+}
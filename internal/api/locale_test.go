@@ -0,0 +1,61 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestDashboardLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		acceptLanguage string
+		locale         string
+		wantLang       string
+		wantText       string
+	}{
+		{name: "default", path: "/dashboard", wantLang: "en", wantText: "Monitor Performance"},
+		{name: "accept language", path: "/dashboard", acceptLanguage: "de-DE,de;q=0.9,en;q=0.8", wantLang: "de", wantText: "Monitor-Leistung"},
+		{name: "unsupported language", path: "/dashboard", acceptLanguage: "ja", wantLang: "en", wantText: "Monitor Performance"},
+		{name: "server locale", path: "/dashboard/ambient", acceptLanguage: "de", locale: "fr", wantLang: "fr", wantText: "Tous les systèmes sont opérationnels"},
+		{name: "query override", path: "/dashboard/ambient?lang=es", acceptLanguage: "de", locale: "fr", wantLang: "es", wantText: "Todos los sistemas operativos"},
+		{name: "unsupported query", path: "/dashboard?lang=xx", acceptLanguage: "fr", wantLang: "fr", wantText: "Performance des moniteurs"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(t)
+			defer server.app.Shutdown()
+			server.currentConfig().Server.Locale = tt.locale
+
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.acceptLanguage != "" {
+				req.Header.Set(fiber.HeaderAcceptLanguage, tt.acceptLanguage)
+			}
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("expected status 200, got %d", resp.StatusCode)
+			}
+			if vary := resp.Header.Get(fiber.HeaderVary); !strings.Contains(vary, fiber.HeaderAcceptLanguage) {
+				t.Errorf("expected Vary to include Accept-Language, got %q", vary)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if want := `<html lang="` + tt.wantLang + `"`; !strings.Contains(string(body), want) {
+				t.Errorf("expected %s in the page", want)
+			}
+			if !strings.Contains(string(body), tt.wantText) {
+				t.Errorf("expected %q in the page", tt.wantText)
+			}
+		})
+	}
+}
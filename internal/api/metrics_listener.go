@@ -0,0 +1,158 @@
+package api
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+)
+
+// metricsPath is where metrics are served, /metrics unless configured
+func (s *Server) metricsPath() string {
+	if s.currentConfig().Metrics.Path != "" {
+		return s.currentConfig().Metrics.Path
+	}
+	return "/metrics"
+}
+
+// setupMetricsRoutes serves metrics, and accepts pushes when enabled, on the
+// dashboard listener or on an app of their own when metrics.listen is set
+func (s *Server) setupMetricsRoutes() {
+	app := s.app
+	if s.currentConfig().Metrics.Listen != "" {
+		s.metricsApp = s.newMetricsApp()
+		app = s.metricsApp
+	}
+	app.Get(s.metricsPath(), s.metricsAuth, s.metricsHandler)
+
+	if s.currentConfig().Metrics.AcceptPush {
+		gatherer, _ := s.prometheusReg.(prometheus.Gatherer)
+		s.pushedMetrics = metrics.NewPushedMetrics(gatherer)
+		s.setupPushRoutes(app)
+	}
+}
+
+// newMetricsApp creates the app behind the dedicated metrics listener
+func (s *Server) newMetricsApp() *fiber.App {
+	app := fiber.New(fiber.Config{
+		AppName:               "Hall Monitor metrics",
+		DisableStartupMessage: true,
+		ServerHeader:          "HallMonitor",
+		ErrorHandler:          errorHandler(s.logger),
+		ReadTimeout:           30 * time.Second,
+		WriteTimeout:          30 * time.Second,
+		IdleTimeout:           120 * time.Second,
+	})
+	app.Use(recover.New())
+	return app
+}
+
+// metricsAuth requires the configured basic auth credentials, if any
+func (s *Server) metricsAuth(c *fiber.Ctx) error {
+	auth := s.currentConfig().Metrics.BasicAuth
+	if auth.Username == "" {
+		return c.Next()
+	}
+
+	username, password, ok := parseBasicAuth(c.Get(fiber.HeaderAuthorization))
+	userMatch := subtle.ConstantTimeCompare([]byte(username), []byte(auth.Username)) == 1
+	passwordMatch := subtle.ConstantTimeCompare([]byte(password), []byte(auth.Password)) == 1
+	if !ok || !userMatch || !passwordMatch {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="hallmonitor metrics"`)
+		return c.Status(fiber.StatusUnauthorized).SendString("Unauthorized")
+	}
+	return c.Next()
+}
+
+// parseBasicAuth decodes an Authorization header with the Basic scheme
+func parseBasicAuth(header string) (username, password string, ok bool) {
+	req := http.Request{Header: http.Header{"Authorization": {header}}}
+	return req.BasicAuth()
+}
+
+// listenMetrics opens the dedicated metrics listener, wrapped in TLS when
+// metrics.tls is configured
+func (s *Server) listenMetrics() (net.Listener, error) {
+	listener, err := net.Listen("tcp", s.currentConfig().Metrics.Listen)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := metricsTLSConfig(s.currentConfig().Metrics.TLS)
+	if err != nil {
+		listener.Close()
+		return nil, err
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+	return listener, nil
+}
+
+// startMetrics serves the dedicated metrics listener in the background
+func (s *Server) startMetrics() error {
+	listener, err := s.listenMetrics()
+	if err != nil {
+		return err
+	}
+
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"address": listener.Addr().String(),
+			"tls":     s.currentConfig().Metrics.TLS.CertFile != "",
+			"mtls":    s.currentConfig().Metrics.TLS.ClientCAFile != "",
+		}).
+		Info("Starting metrics server")
+
+	go func() {
+		if err := s.metricsApp.Listener(listener); err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Error("Metrics server stopped")
+		}
+	}()
+	return nil
+}
+
+// metricsTLSConfig loads the metrics certificate and, for mutual TLS, the
+// CAs client certificates must be signed by. It returns nil without TLS.
+func metricsTLSConfig(cfg config.MetricsTLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load metrics certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metrics client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.ClientCAFile)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+)
+
+// metricsTestConfig returns a config file with the given metrics section
+func metricsTestConfig(metricsYAML string) string {
+	return `
+server:
+  port: "7878"
+  host: "127.0.0.1"
+
+metrics:
+` + metricsYAML
+}
+
+// serveMetrics starts the dedicated metrics listener and returns its address
+func serveMetrics(t *testing.T, server *Server) string {
+	t.Helper()
+
+	listener, err := server.listenMetrics()
+	if err != nil {
+		t.Fatalf("listenMetrics() error = %v", err)
+	}
+	go server.metricsApp.Listener(listener)
+	t.Cleanup(func() { server.metricsApp.Shutdown() })
+	return listener.Addr().String()
+}
+
+func TestMetricsBasicAuth(t *testing.T) {
+	server := createYAMLTestServer(t, metricsTestConfig(`
+  basicAuth:
+    username: "prometheus"
+    password: "scrape"
+`), nil)
+	defer server.app.Shutdown()
+
+	tests := []struct {
+		name       string
+		username   string
+		password   string
+		wantStatus int
+	}{
+		{name: "no credentials", wantStatus: fiber.StatusUnauthorized},
+		{name: "wrong password", username: "prometheus", password: "guess", wantStatus: fiber.StatusUnauthorized},
+		{name: "valid credentials", username: "prometheus", password: "scrape", wantStatus: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.username != "" {
+				req.SetBasicAuth(tt.username, tt.password)
+			}
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestMetricsListener(t *testing.T) {
+	server := createYAMLTestServer(t, metricsTestConfig(`
+  listen: "127.0.0.1:0"
+  path: "/internal/metrics"
+`), nil)
+	defer server.app.Shutdown()
+	address := serveMetrics(t, server)
+
+	resp, err := http.Get("http://" + address + "/internal/metrics")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Errorf("expected 200 from the metrics listener, got %d", resp.StatusCode)
+	}
+
+	// Metrics are no longer served next to the dashboard
+	resp, err = server.app.Test(httptest.NewRequest("GET", "/internal/metrics", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusNotFound {
+		t.Errorf("expected 404 from the dashboard listener, got %d", resp.StatusCode)
+	}
+}
+
+func TestMetricsListenerMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	caCert, caKey := writeTestCertificate(t, dir, "ca", nil, nil)
+	writeTestCertificate(t, dir, "server", caCert, caKey)
+	writeTestCertificate(t, dir, "client", caCert, caKey)
+
+	server := createYAMLTestServer(t, metricsTestConfig(fmt.Sprintf(`
+  listen: "127.0.0.1:0"
+  tls:
+    certFile: %q
+    keyFile: %q
+    clientCAFile: %q
+`, filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"), filepath.Join(dir, "ca.crt"))), nil)
+	defer server.app.Shutdown()
+	address := serveMetrics(t, server)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	get := func(certificates []tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certificates,
+			ServerName:   "localhost",
+		}}}
+		resp, err := client.Get("https://" + address + "/metrics")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusOK {
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	if err := get(nil); err == nil {
+		t.Error("expected scrapes without a client certificate to be refused")
+	}
+	pair, err := tls.LoadX509KeyPair(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"))
+	if err != nil {
+		t.Fatalf("failed to load client certificate: %v", err)
+	}
+	if err := get([]tls.Certificate{pair}); err != nil {
+		t.Errorf("expected a scrape with a client certificate to succeed: %v", err)
+	}
+}
+
+func TestAcceptPushedMetrics(t *testing.T) {
+	receiver := createYAMLTestServer(t, metricsTestConfig(`
+  listen: "127.0.0.1:0"
+  acceptPush: true
+`), nil)
+	defer receiver.app.Shutdown()
+	address := serveMetrics(t, receiver)
+
+	// A probe pushes its registry, Pushgateway style
+	probe := prometheus.NewRegistry()
+	probeMetrics := metrics.NewMetrics(probe)
+	probeMetrics.MonitorUp.WithLabelValues("api", "http", "edge").Set(1)
+	pusher := metrics.NewPusher(config.MetricsPushConfig{URL: "http://" + address, Instance: "probe-1"}, probe, receiver.logger)
+	if err := pusher.Push(context.Background()); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	scrape := func() string {
+		resp, err := http.Get("http://" + address + "/metrics")
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	if body := scrape(); !strings.Contains(body, `hallmonitor_monitor_up{group="edge",instance="probe-1",job="hallmonitor",monitor="api",type="http"} 1`) {
+		t.Errorf("expected the pushed metric in the scrape, got:\n%s", body)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, "http://"+address+"/metrics/job/hallmonitor/instance/probe-1", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	if body := scrape(); strings.Contains(body, `instance="probe-1"`) {
+		t.Errorf("expected the deleted push to be gone, got:\n%s", body)
+	}
+}
+
+// writeTestCertificate writes name.crt and name.key to dir, signed by the
+// parent certificate or self-signed as a CA when parent is nil
+func writeTestCertificate(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert, key
+}
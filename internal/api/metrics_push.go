@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+)
+
+// setupPushRoutes accepts metrics pushed by other instances on the same app
+// that serves /metrics, using the Pushgateway's URL layout so either can be
+// the target of metrics.push
+func (s *Server) setupPushRoutes(app *fiber.App) {
+	for _, path := range []string{"/metrics/job/:job", "/metrics/job/:job/instance/:instance"} {
+		app.Put(path, s.metricsAuth, s.pushMetricsHandler)
+		app.Delete(path, s.metricsAuth, s.deletePushedMetricsHandler)
+	}
+}
+
+// pushMetricsHandler replaces the metrics pushed for a job and instance. The
+// body is in the Prometheus text or delimited protobuf format.
+func (s *Server) pushMetricsHandler(c *fiber.Ctx) error {
+	job, instance := c.Params("job"), c.Params("instance")
+
+	header := http.Header{}
+	header.Set(fiber.HeaderContentType, c.Get(fiber.HeaderContentType))
+	decoder := expfmt.NewDecoder(bytes.NewReader(c.Body()), expfmt.ResponseFormat(header))
+
+	var families []*dto.MetricFamily
+	for {
+		family := &dto.MetricFamily{}
+		if err := decoder.Decode(family); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid metrics: " + err.Error(),
+			})
+		}
+		families = append(families, family)
+	}
+
+	if err := s.pushedMetrics.Replace(job, instance, families); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   true,
+			"message": err.Error(),
+		})
+	}
+
+	s.logger.WithComponent(logging.ComponentMetrics).
+		WithFields(map[string]interface{}{
+			"job":      job,
+			"instance": instance,
+			"families": len(families),
+		}).
+		Debug("Received pushed metrics")
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// deletePushedMetricsHandler drops the metrics pushed for a job and instance
+func (s *Server) deletePushedMetricsHandler(c *fiber.Ctx) error {
+	s.pushedMetrics.Delete(c.Params("job"), c.Params("instance"))
+	return c.SendStatus(fiber.StatusAccepted)
+}
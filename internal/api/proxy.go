@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+)
+
+// headerXRealIP is set by nginx-style proxies to the client they serve
+const headerXRealIP = "X-Real-IP"
+
+// clientIP returns the address of the client that sent the request. Requests
+// relayed by a proxy in server.trustedProxies, or over the unix socket, are
+// attributed to the client in X-Forwarded-For or X-Real-IP; from any other
+// peer those headers are ignored.
+func (s *Server) clientIP(c *fiber.Ctx) net.IP {
+	peer := net.ParseIP(c.IP())
+	// Only a local reverse proxy can reach the unix socket
+	local := c.Context().RemoteAddr().Network() == "unix"
+	networks := s.settings.Load().trustedProxies
+	if len(networks) == 0 && !local {
+		return peer
+	}
+
+	trusted := func(ip net.IP) bool {
+		for _, network := range networks {
+			if network.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+	return resolveClientIP(peer, local || trusted(peer), c.Get(fiber.HeaderXForwardedFor), c.Get(headerXRealIP), trusted)
+}
+
+// trustedProxyNetworks parses server.trustedProxies once, rather than on
+// every request. They are validated when the config loads, so an error only
+// leaves no proxy trusted.
+func trustedProxyNetworks(cfg *config.Config) []*net.IPNet {
+	networks, err := monitors.ParseNetworks(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil
+	}
+	return networks
+}
+
+// resolveClientIP returns the client behind a chain of trusted proxies. It
+// walks X-Forwarded-For from the nearest hop, since only the entries added by
+// trusted proxies can be believed, and stops at the first untrusted address.
+func resolveClientIP(peer net.IP, peerTrusted bool, forwardedFor, realIP string, trusted func(net.IP) bool) net.IP {
+	if peer == nil || !peerTrusted {
+		return peer
+	}
+
+	if forwardedFor != "" {
+		hops := strings.Split(forwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				return peer
+			}
+			if !trusted(ip) {
+				return ip
+			}
+			peer = ip
+		}
+		return peer
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(realIP)); ip != nil {
+		return ip
+	}
+	return peer
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestResolveClientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	trusted := func(ip net.IP) bool { return proxies.Contains(ip) }
+
+	tests := []struct {
+		name         string
+		peer         string
+		local        bool
+		forwardedFor string
+		realIP       string
+		want         string
+	}{
+		{name: "direct client", peer: "203.0.113.7", want: "203.0.113.7"},
+		{name: "headers from untrusted peer", peer: "203.0.113.7", forwardedFor: "198.51.100.4", realIP: "198.51.100.4", want: "203.0.113.7"},
+		{name: "forwarded by trusted proxy", peer: "10.0.0.2", forwardedFor: "198.51.100.4", want: "198.51.100.4"},
+		{name: "spoofed entries before the proxy's", peer: "10.0.0.2", forwardedFor: "192.0.2.1, 198.51.100.4", want: "198.51.100.4"},
+		{name: "chain of trusted proxies", peer: "10.0.0.2", forwardedFor: "198.51.100.4, 10.0.0.3", want: "198.51.100.4"},
+		{name: "real ip header", peer: "10.0.0.2", realIP: "198.51.100.4", want: "198.51.100.4"},
+		{name: "malformed hop", peer: "10.0.0.2", forwardedFor: "unknown", want: "10.0.0.2"},
+		{name: "no headers", peer: "10.0.0.2", want: "10.0.0.2"},
+		{name: "unix socket peer", peer: "0.0.0.0", local: true, forwardedFor: "198.51.100.4", want: "198.51.100.4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			peer := net.ParseIP(tt.peer)
+			got := resolveClientIP(peer, tt.local || trusted(peer), tt.forwardedFor, tt.realIP, trusted)
+			if !got.Equal(net.ParseIP(tt.want)) {
+				t.Errorf("resolveClientIP() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrustedProxies(t *testing.T) {
+	// Test requests come from 0.0.0.0, standing in for the proxy
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		forwardedFor   string
+		wantStatus     int
+	}{
+		{name: "forwarded by trusted proxy", trustedProxies: []string{"0.0.0.0/32"}, forwardedFor: "198.51.100.4", wantStatus: fiber.StatusOK},
+		{name: "forwarded from outside the allowlist", trustedProxies: []string{"0.0.0.0/32"}, forwardedFor: "192.0.2.1", wantStatus: fiber.StatusForbidden},
+		{name: "spoofed without trusted proxies", forwardedFor: "198.51.100.4", wantStatus: fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createTestServer(t)
+			defer server.app.Shutdown()
+			server.currentConfig().Server.AdminAllowlist = []string{"198.51.100.0/24"}
+			server.currentConfig().Server.TrustedProxies = tt.trustedProxies
+			server.setConfig(server.currentConfig())
+
+			status, _ := doJSON(t, server, "GET", "/api/v1/config", nil, map[string]string{fiber.HeaderXForwardedFor: tt.forwardedFor})
+			if status != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, status)
+			}
+		})
+	}
+}
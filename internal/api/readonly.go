@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// readOnlyAllowedPosts are POST endpoints that only read, so stay available
+// in read-only mode
+var readOnlyAllowedPosts = map[string]bool{
+	"/api/v1/query":      true, // Grafana queries
+	"/api/v1/query/tags": true,
+	"/api/v1/reload":     true, // Re-reads the config file; nothing is written
+}
+
+// readOnlyMiddleware rejects API requests that would change configuration or
+// stored data when the server runs against a read-only store
+func (s *Server) readOnlyMiddleware(c *fiber.Ctx) error {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return c.Next()
+	case fiber.MethodPost:
+		if readOnlyAllowedPosts[c.Path()] {
+			return c.Next()
+		}
+	}
+
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"success": false,
+		"message": "Server is in read-only mode",
+	})
+}
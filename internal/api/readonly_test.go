@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// createReadOnlyTestServer serves a Badger store, written beforehand with a
+// single down result for "api", opened read-only
+func createReadOnlyTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	dir := t.TempDir()
+	writer, err := storage.NewBadgerStore(dir, 7, testLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	err = writer.StoreResult(&models.MonitorResult{
+		Monitor:   "api",
+		Type:      models.MonitorTypeHTTP,
+		Group:     "core",
+		Status:    models.StatusDown,
+		Timestamp: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("failed to store result: %v", err)
+	}
+	writer.Close()
+
+	store, err := storage.NewBadgerStoreWithOptions(dir, 7, storage.BadgerOptions{ReadOnly: true}, testLogger(t))
+	if err != nil {
+		t.Fatalf("failed to open store read-only: %v", err)
+	}
+
+	server := createYAMLTestServer(t, `
+server:
+  port: "7878"
+  host: "0.0.0.0"
+
+monitoring:
+  groups:
+    - name: "core"
+      monitors:
+        - type: "http"
+          name: "api"
+          url: "https://api.example.com"
+`, store)
+	t.Cleanup(func() { _ = server.Stop() })
+	return server
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	server := createReadOnlyTestServer(t)
+
+	if err := server.scheduler.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start scheduler: %v", err)
+	}
+	if server.scheduler.IsRunning() {
+		t.Fatal("expected no checks to be scheduled in read-only mode")
+	}
+
+	// Status comes from the store
+	resp, err := server.app.Test(httptest.NewRequest("GET", "/api/v1/monitors/api", nil), -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	var status MonitorStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	resp.Body.Close()
+	if status.Status != string(models.StatusDown) {
+		t.Errorf("expected the stored status, got %q", status.Status)
+	}
+
+	tests := []struct {
+		method, path string
+		body         string
+		wantBlocked  bool
+	}{
+		{method: "GET", path: "/api/v1/monitors"},
+		{method: "POST", path: "/api/v1/query", body: `{"targets":[]}`},
+		{method: "POST", path: "/api/v1/monitors", body: `{}`, wantBlocked: true},
+		{method: "PUT", path: "/api/v1/config", body: `{}`, wantBlocked: true},
+		{method: "DELETE", path: "/api/v1/monitors/api", wantBlocked: true},
+		{method: "POST", path: "/api/v1/annotations", body: `{"text":"deploy"}`, wantBlocked: true},
+		{method: "POST", path: "/api/v1/monitors/api/fault", body: `{}`, wantBlocked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if blocked := resp.StatusCode == fiber.StatusForbidden; blocked != tt.wantBlocked {
+				t.Errorf("blocked = %v (status %d), want %v", blocked, resp.StatusCode, tt.wantBlocked)
+			}
+		})
+	}
+
+	// The monitor was not deleted
+	if server.monitorManager.GetMonitorByName("api") == nil {
+		t.Error("expected the monitor to remain")
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package api
+
+import (
+	"errors"
+	"syscall"
+)
+
+// reusePortSupported reports whether server.reusePort can take effect
+const reusePortSupported = false
+
+// reusePortControl fails where SO_REUSEPORT is unavailable
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on this platform")
+}
@@ -4,26 +4,34 @@ package api
 
 import (
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"html/template"
 	"io/fs"
+	"net"
 	"net/http"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/filesystem"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/timeout"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/1broseidon/hallmonitor/internal/accounts"
 	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/i18n"
 	"github.com/1broseidon/hallmonitor/internal/logging"
 	"github.com/1broseidon/hallmonitor/internal/metrics"
 	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/internal/reports"
 	"github.com/1broseidon/hallmonitor/internal/scheduler"
 	"github.com/1broseidon/hallmonitor/internal/storage"
 	"github.com/1broseidon/hallmonitor/pkg/models"
@@ -39,6 +47,8 @@ var (
 	dashboardTpl *template.Template
 	ambientTpl   *template.Template
 	configTpl    *template.Template
+	statusTpl    *template.Template
+	loginTpl     *template.Template
 )
 
 func init() {
@@ -70,26 +80,54 @@ func init() {
 	if err != nil {
 		panic(err)
 	}
+
+	// Parse the standalone tenant status page template
+	statusTpl, err = template.ParseFS(templatesFS, "templates/status.html")
+	if err != nil {
+		panic(err)
+	}
+
+	// Parse the standalone sign-in page template
+	loginTpl, err = template.ParseFS(templatesFS, "templates/login.html")
+	if err != nil {
+		panic(err)
+	}
 }
 
-// DashboardData holds data passed to dashboard templates
+// DashboardData holds data passed to dashboard templates. The embedded
+// catalog translates page text for the viewer's language.
 type DashboardData struct {
+	*i18n.Catalog
 	IsAmbient   bool
 	CurrentView string
+	Title       string
+	Tenant      string // Set when a tenant is viewing the dashboard
+	Username    string // Set when a signed-in user is viewing the dashboard
 }
 
 // Server represents the API server
 type Server struct {
 	app            *fiber.App
-	config         *config.Config
+	metricsApp     *fiber.App                     // Serves metrics on their own listener when metrics.listen is set
+	settings       atomic.Pointer[serverSettings] // Replaced as a whole on reload; read with currentConfig
 	configPath     string
 	logger         *logging.Logger
 	metrics        *metrics.Metrics
+	pushedMetrics  *metrics.PushedMetrics // Metrics pushed by other instances, when metrics.acceptPush is set
 	monitorManager *monitors.MonitorManager
 	scheduler      *scheduler.Scheduler
 	prometheusReg  prometheus.Registerer
 	storage        storage.ResultStore
 	aggregator     dashboardAggregator
+	events         *eventBroker
+	annotations    *annotationLog
+	cache          *responseCache
+	readOnly       bool // Storage is read-only; checks and changes are disabled
+	reports        *reports.Runner
+	accounts       *accounts.Manager
+
+	// configMu serializes config writes
+	configMu sync.Mutex
 }
 
 // dashboardAggregator interface for dashboard-specific aggregation methods
@@ -104,9 +142,11 @@ func NewServer(cfg *config.Config, configPath string, logger *logging.Logger, pr
 
 	// Create monitor manager
 	monitorManager := monitors.NewMonitorManager(logger, metricsInstance)
+	configureMonitorNetwork(monitorManager, cfg, logger)
 
 	// Create scheduler without storage
 	schedulerInstance := scheduler.NewScheduler(logger, metricsInstance, monitorManager)
+	configureScheduler(schedulerInstance, cfg)
 
 	// Create Fiber app with configuration
 	app := fiber.New(fiber.Config{
@@ -122,7 +162,6 @@ func NewServer(cfg *config.Config, configPath string, logger *logging.Logger, pr
 
 	s := &Server{
 		app:            app,
-		config:         cfg,
 		configPath:     configPath,
 		logger:         logger,
 		metrics:        metricsInstance,
@@ -130,7 +169,16 @@ func NewServer(cfg *config.Config, configPath string, logger *logging.Logger, pr
 		scheduler:      schedulerInstance,
 		prometheusReg:  prometheusReg,
 		aggregator:     nil, // No aggregation available without storage
+		events:         newEventBroker(),
+		annotations:    newAnnotationLog(nil),
+		cache:          newResponseCache(cfg.Server.CacheTTL.ToDuration(), metricsInstance),
 	}
+	s.setConfig(cfg)
+
+	// Stream results to SSE subscribers and drop cached figures they change
+	schedulerInstance.AddResultHandler(s.events)
+	schedulerInstance.AddResultHandler(s.cache)
+	schedulerInstance.SetStallHandler(scheduler.StallHandlerFunc(s.annotateStall))
 
 	// Setup middleware
 	s.setupMiddleware()
@@ -148,9 +196,18 @@ func NewServerWithStorage(cfg *config.Config, configPath string, logger *logging
 
 	// Create monitor manager
 	monitorManager := monitors.NewMonitorManager(logger, metricsInstance)
+	configureMonitorNetwork(monitorManager, cfg, logger)
 
 	// Create scheduler with storage
 	schedulerInstance := scheduler.NewSchedulerWithStorage(logger, metricsInstance, monitorManager, persistentStore, aggregator)
+	configureScheduler(schedulerInstance, cfg)
+
+	// A read-only instance shows what another instance stored
+	readOnly := resultStore != nil && resultStore.Capabilities().ReadOnly
+	if resultStore != nil {
+		storage.ApplyTenants(resultStore, cfg)
+	}
+	schedulerInstance.SetReadOnly(readOnly)
 
 	// Create Fiber app with configuration
 	app := fiber.New(fiber.Config{
@@ -176,7 +233,6 @@ func NewServerWithStorage(cfg *config.Config, configPath string, logger *logging
 
 	s := &Server{
 		app:            app,
-		config:         cfg,
 		configPath:     configPath,
 		logger:         logger,
 		metrics:        metricsInstance,
@@ -185,7 +241,17 @@ func NewServerWithStorage(cfg *config.Config, configPath string, logger *logging
 		prometheusReg:  prometheusReg,
 		storage:        resultStore,
 		aggregator:     dashboardAgg,
+		events:         newEventBroker(),
+		annotations:    newAnnotationLog(resultStore),
+		cache:          newResponseCache(cfg.Server.CacheTTL.ToDuration(), metricsInstance),
+		readOnly:       readOnly,
 	}
+	s.setConfig(cfg)
+
+	// Stream results to SSE subscribers and drop cached figures they change
+	schedulerInstance.AddResultHandler(s.events)
+	schedulerInstance.AddResultHandler(s.cache)
+	schedulerInstance.SetStallHandler(scheduler.StallHandlerFunc(s.annotateStall))
 
 	// Setup middleware
 	s.setupMiddleware()
@@ -196,6 +262,23 @@ func NewServerWithStorage(cfg *config.Config, configPath string, logger *logging
 	return s
 }
 
+// serverSettings is the config handlers read, swapped as a whole on reload
+type serverSettings struct {
+	config         *config.Config
+	trustedProxies []*net.IPNet // Parsed server.trustedProxies
+}
+
+// currentConfig returns the config in effect. Callers should not hold on to
+// it beyond a request, since a reload replaces it.
+func (s *Server) currentConfig() *config.Config {
+	return s.settings.Load().config
+}
+
+// setConfig replaces the config handlers read
+func (s *Server) setConfig(cfg *config.Config) {
+	s.settings.Store(&serverSettings{config: cfg, trustedProxies: trustedProxyNetworks(cfg)})
+}
+
 // setupMiddleware configures Fiber middleware
 func (s *Server) setupMiddleware() {
 	// Recovery middleware
@@ -203,22 +286,26 @@ func (s *Server) setupMiddleware() {
 		EnableStackTrace: true,
 	}))
 
-	// Request logger middleware
+	// Request logger middleware, logging the client behind trusted proxies
 	s.app.Use(logger.New(logger.Config{
-		Format: "${time} | ${status} | ${latency} | ${method} ${path}\n",
+		Format: "${time} | ${status} | ${latency} | ${ip} | ${method} ${path}\n",
 		Output: nil, // Will use default (os.Stdout)
+		CustomTags: map[string]logger.LogFunc{
+			logger.TagIP: func(output logger.Buffer, c *fiber.Ctx, _ *logger.Data, _ string) (int, error) {
+				return output.WriteString(s.clientIP(c).String())
+			},
+		},
 	}))
 
 	// CORS middleware
-	corsOrigins := "*"
-	if len(s.config.Server.CORSOrigins) > 0 {
-		corsOrigins = strings.Join(s.config.Server.CORSOrigins, ",")
+	for _, handler := range s.newCORS(s.currentConfig().Server.CORSPolicy()) {
+		s.app.Use(handler)
+	}
+
+	// Response compression
+	if s.currentConfig().Server.Compression {
+		s.app.Use(newCompression())
 	}
-	s.app.Use(cors.New(cors.Config{
-		AllowOrigins: corsOrigins,
-		AllowMethods: "GET,POST,PUT,DELETE,OPTIONS",
-		AllowHeaders: "Origin,Content-Type,Accept,Authorization",
-	}))
 
 	// Global timeout middleware
 	s.app.Use(timeout.NewWithContext(func(c *fiber.Ctx) error {
@@ -246,44 +333,117 @@ func (s *Server) setupRoutes() {
 	// Health and metrics endpoints
 	s.app.Get("/health", s.healthHandler)
 	s.app.Get("/ready", s.readyHandler)
-	s.app.Get("/metrics", s.metricsHandler)
+	s.setupMetricsRoutes()
 
 	// Dashboard (if enabled)
-	if s.config.Server.EnableDashboard {
-		s.app.Get("/", s.dashboardHandler)
-		s.app.Get("/dashboard", s.dashboardHandler)
-		s.app.Get("/dashboard/ambient", s.dashboardAmbientHandler)
-		s.app.Get("/config", s.configPageHandler)
+	if s.currentConfig().Server.EnableDashboard {
+		s.app.Get("/", s.authMiddleware, s.dashboardHandler)
+		s.app.Get("/dashboard", s.authMiddleware, s.dashboardHandler)
+		s.app.Get("/dashboard/ambient", s.authMiddleware, s.dashboardAmbientHandler)
+		s.app.Get("/config", s.authMiddleware, s.requireAdmin, s.configPageHandler)
 	}
 
+	// Tenant status pages, public when the tenant allows it
+	s.app.Get("/status/:tenant", s.statusPageHandler)
+
+	// Sign-in with local accounts
+	s.app.Get("/login", s.loginPageHandler)
+	s.app.Post("/login", s.loginHandler)
+	s.app.Post("/login/verify", s.verifyLoginHandler)
+	s.app.Post("/logout", s.logoutHandler)
+
 	// API v1 routes
-	api := s.app.Group("/api/v1")
+	api := s.app.Group("/api/v1", s.authMiddleware)
+	if s.readOnly {
+		api.Use(s.readOnlyMiddleware)
+	}
+
+	// Live status is revalidated with ETags; history and statistics may be
+	// reused briefly
+	live, history := cacheControl(cacheRevalidate), cacheControl(cacheShort)
 
 	// Monitor status endpoints
-	api.Get("/monitors", s.getMonitorsHandler)
-	api.Get("/monitors/:name", s.getMonitorHandler)
-	api.Get("/monitors/:name/history", s.getMonitorHistoryHandler)
-	api.Get("/monitors/:name/uptime", s.getMonitorUptimeHandler)
-	api.Get("/groups", s.getGroupsHandler)
-	api.Get("/groups/:name", s.getGroupHandler)
+	api.Get("/monitors", live, conditional, s.getMonitorsHandler)
+	api.Get("/monitors/:name", live, conditional, s.requireMonitorAccess, s.getMonitorHandler)
+	api.Get("/monitors/:name/history", history, conditional, s.requireMonitorAccess, s.getMonitorHistoryHandler)
+	api.Get("/monitors/:name/results/:id", history, conditional, s.requireMonitorAccess, s.getResultHandler)
+	api.Get("/monitors/:name/uptime", history, conditional, s.requireMonitorAccess, s.getMonitorUptimeHandler)
+	api.Get("/monitors/:name/stats", history, conditional, s.requireMonitorAccess, s.getMonitorStatsHandler)
+	api.Get("/monitors/:name/heatmap", history, conditional, s.requireMonitorAccess, s.getMonitorHeatmapHandler)
+	api.Get("/monitors/:name/aggregates", history, conditional, s.requireMonitorAccess, s.getMonitorAggregatesHandler)
+	api.Get("/monitors/:name/certs", live, conditional, s.requireMonitorAccess, s.getMonitorCertsHandler)
+	api.Get("/results/:id", history, conditional, s.getResultHandler)
+	api.Get("/groups", live, conditional, s.getGroupsHandler)
+	api.Get("/groups/:name", live, conditional, s.requireGroupAccess, s.getGroupHandler)
+	api.Get("/timeouts", live, conditional, s.getTimeoutsHandler)
+	api.Get("/search/results", history, conditional, s.searchResultsHandler)
+	api.Get("/reports/latest", s.requireAdmin, s.getLatestReportHandler)
+	api.Get("/reports/sla", history, conditional, s.getSLAReportHandler)
+
+	// Server-Sent Events stream of status updates and alerts
+	api.Get("/stream", s.streamHandler)
 
 	// Configuration endpoints
-	api.Post("/reload", s.reloadConfigHandler)
-	api.Get("/config", s.getConfigHandler)
-	api.Put("/config", s.updateConfigHandler)
+	api.Post("/reload", s.requireAdmin, s.reloadConfigHandler)
+	api.Get("/config", s.requireAdmin, s.getConfigHandler)
+	api.Put("/config", s.requireAdmin, s.updateConfigHandler)
 
 	// Monitor CRUD endpoints
-	api.Post("/monitors", s.createMonitorHandler)
-	api.Put("/monitors/:name", s.updateMonitorHandler)
-	api.Delete("/monitors/:name", s.deleteMonitorHandler)
+	api.Post("/monitors", s.requireAdmin, s.createMonitorHandler)
+	api.Post("/monitors/bulk", s.requireAdmin, s.bulkMonitorsHandler)
+	api.Put("/monitors/:name", s.requireAdmin, s.updateMonitorHandler)
+	api.Delete("/monitors/:name", s.requireAdmin, s.deleteMonitorHandler)
+	api.Post("/monitors/:name/enable", s.requireAdmin, s.setMonitorEnabledHandler(true))
+	api.Post("/monitors/:name/disable", s.requireAdmin, s.setMonitorEnabledHandler(false))
+	api.Get("/monitors/:name/definition", s.requireAdmin, s.getMonitorDefinitionHandler)
+	api.Post("/monitors/:name/clone", s.requireAdmin, s.cloneMonitorHandler)
+	api.Post("/monitors/:name/debug-check", s.requireAdmin, s.debugCheckHandler)
+	api.Post("/check", s.requireAdmin, s.checkHandler)
+
+	// Storage maintenance
+	api.Get("/admin/storage", s.requireAdmin, s.getStorageHealthHandler)
+	api.Post("/admin/aggregate/recompute", s.requireAdmin, s.recomputeAggregatesHandler)
 
 	// Group CRUD endpoints
-	api.Post("/groups", s.createGroupHandler)
-	api.Put("/groups/:name", s.updateGroupHandler)
-	api.Delete("/groups/:name", s.deleteGroupHandler)
+	api.Post("/groups", s.requireAdmin, s.createGroupHandler)
+	api.Put("/groups/:name", s.requireAdmin, s.updateGroupHandler)
+	api.Delete("/groups/:name", s.requireAdmin, s.deleteGroupHandler)
+	api.Post("/groups/:name/enable", s.requireAdmin, s.setGroupEnabledHandler(true))
+	api.Post("/groups/:name/disable", s.requireAdmin, s.setGroupEnabledHandler(false))
+
+	// Import monitors from other tools
+	api.Post("/import/uptime-kuma", s.requireAdmin, s.importUptimeKumaHandler)
+	api.Post("/import/blackbox", s.requireAdmin, s.importBlackboxHandler)
+
+	// Simulated failures for testing alerting
+	api.Get("/faults", s.getFaultsHandler)
+	api.Post("/monitors/:name/fault", s.requireAdmin, s.injectFaultHandler)
+	api.Delete("/monitors/:name/fault", s.requireAdmin, s.clearFaultHandler)
+
+	// Scheduling queue for debugging check cadence
+	api.Get("/scheduler/queue", live, s.getSchedulerQueueHandler)
+	api.Post("/scheduler/queue/:name/defer", s.requireAdmin, s.deferMonitorHandler)
+
+	// Monitor templates
+	api.Get("/templates", s.requireAdmin, s.getTemplatesHandler)
+	api.Post("/templates/:name/expand", s.requireAdmin, s.expandTemplateHandler)
+
+	// Signed-in session and local user management
+	api.Get("/session", s.getSessionHandler)
+	api.Put("/session/password", s.requireUser, s.changePasswordHandler)
+	api.Get("/session/totp", s.requireUser, s.getTOTPHandler)
+	api.Post("/session/totp", s.requireUser, s.beginTOTPHandler)
+	api.Post("/session/totp/confirm", s.requireUser, s.confirmTOTPHandler)
+	api.Delete("/session/totp", s.requireUser, s.disableTOTPHandler)
+	api.Post("/session/totp/recovery-codes", s.requireUser, s.regenerateRecoveryCodesHandler)
+	api.Get("/users", s.requireAdmin, s.getUsersHandler)
+	api.Post("/users", s.requireAdmin, s.createUserHandler)
+	api.Put("/users/:username", s.requireAdmin, s.updateUserHandler)
+	api.Delete("/users/:username", s.requireAdmin, s.deleteUserHandler)
+	api.Delete("/users/:username/totp", s.requireAdmin, s.resetUserTOTPHandler)
 
 	// Grafana export endpoint (disabled for now)
-	// if s.config.Server.EnableDashboard {
+	// if s.currentConfig().Server.EnableDashboard {
 	//	api.Get("/grafana/dashboard", s.exportGrafanaDashboardHandler)
 	// }
 
@@ -291,11 +451,21 @@ func (s *Server) setupRoutes() {
 	api.Post("/query", s.grafanaQueryHandler)
 	api.Post("/query/tags", s.grafanaTagsHandler)
 	api.Get("/annotations", s.grafanaAnnotationsHandler)
+	api.Post("/annotations", s.createAnnotationHandler)
 }
 
 // Start starts the server
 func (s *Server) Start() error {
-	address := s.config.Server.Host + ":" + s.config.Server.Port
+	if s.metricsApp != nil {
+		if err := s.startMetrics(); err != nil {
+			return fmt.Errorf("failed to start metrics listener: %w", err)
+		}
+	}
+
+	listener, address, err := s.listen()
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
 
 	s.logger.WithComponent(logging.ComponentAPI).
 		WithFields(map[string]interface{}{
@@ -303,12 +473,34 @@ func (s *Server) Start() error {
 		}).
 		Info("Starting HTTP server")
 
-	return s.app.Listen(address)
+	return s.app.Listener(listener)
 }
 
 // Stop gracefully stops the server
 func (s *Server) Stop() error {
-	s.logger.WithComponent(logging.ComponentAPI).Info("Stopping HTTP server")
+	grace := s.currentConfig().Server.ShutdownGracePeriod.ToDuration()
+	s.logger.WithComponent(logging.ComponentAPI).
+		WithFields(map[string]interface{}{
+			"grace_period": grace.String(),
+		}).
+		Info("Stopping HTTP server")
+
+	// Event streams never finish on their own, so end them before draining
+	s.events.close()
+
+	// Stop accepting connections and let in-flight requests finish, up to the
+	// grace period, before closing the storage they read from
+	err := s.app.ShutdownWithTimeout(grace)
+	if err != nil {
+		err = fmt.Errorf("requests still running after %s: %w", grace, err)
+	}
+	if s.metricsApp != nil {
+		if err := s.metricsApp.ShutdownWithTimeout(grace); err != nil {
+			s.logger.WithComponent(logging.ComponentAPI).
+				WithError(err).
+				Warn("Error stopping metrics server")
+		}
+	}
 
 	// Close storage if present
 	if s.storage != nil {
@@ -319,7 +511,7 @@ func (s *Server) Stop() error {
 		}
 	}
 
-	return s.app.Shutdown()
+	return err
 }
 
 // GetMonitorManager returns the monitor manager
@@ -332,42 +524,177 @@ func (s *Server) GetScheduler() *scheduler.Scheduler {
 	return s.scheduler
 }
 
-// ReloadConfig reloads the configuration and restarts monitors
-func (s *Server) ReloadConfig(ctx context.Context) error {
+// SetReportRunner enables the scheduled reports endpoints
+func (s *Server) SetReportRunner(runner *reports.Runner) {
+	s.reports = runner
+}
+
+// SetAccountManager enables sign-in with local user accounts
+func (s *Server) SetAccountManager(manager *accounts.Manager) {
+	s.accounts = manager
+}
+
+// ReloadConfig reloads the configuration and reschedules monitors whose spec
+// changed, returning the monitor diff
+func (s *Server) ReloadConfig(ctx context.Context) (*monitors.ReloadDiff, error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	return s.reloadConfigLocked(ctx)
+}
+
+// ConfigRevision returns the current config revision. It is derived from the
+// config file's contents, so it survives restarts and changes with edits made
+// outside the API.
+func (s *Server) ConfigRevision() string {
+	// A missing file has the revision of an empty one
+	data, _ := os.ReadFile(s.configPath)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// requireConfigRevision checks the revision a write is based on, taken from
+// the request body, an If-Match header, or a revision query parameter. It
+// writes an error response and returns false if the write must be rejected.
+func (s *Server) requireConfigRevision(c *fiber.Ctx, bodyRevision *string) (bool, error) {
+	revision := ""
+	switch {
+	case bodyRevision != nil:
+		revision = *bodyRevision
+	case c.Get(fiber.HeaderIfMatch) != "":
+		revision = strings.Trim(strings.TrimPrefix(c.Get(fiber.HeaderIfMatch), "W/"), `"`)
+	default:
+		revision = c.Query("revision")
+	}
+
+	current := s.ConfigRevision()
+	if revision == "" {
+		return false, c.Status(fiber.StatusPreconditionRequired).JSON(fiber.Map{
+			"success":  false,
+			"message":  "Config revision is required; fetch the current revision from GET /api/v1/config",
+			"revision": current,
+		})
+	}
+
+	if revision != current {
+		return false, c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"success":  false,
+			"message":  fmt.Sprintf("Configuration changed since revision %s; reload and retry", revision),
+			"revision": current,
+		})
+	}
+
+	return true, nil
+}
+
+// reloadConfigLocked reloads the configuration; callers must hold configMu.
+// Only monitors whose spec changed are rescheduled, so unchanged monitors keep
+// their schedule, history, and backoff state.
+func (s *Server) reloadConfigLocked(ctx context.Context) (*monitors.ReloadDiff, error) {
 	s.logger.WithComponent(logging.ComponentAPI).Info("Reloading configuration")
 
-	// Load new configuration from file
-	newConfig, err := config.LoadConfig(s.configPath)
+	// Load new configuration from file, keeping the active profile
+	newConfig, err := config.LoadConfigWithProfile(s.configPath, s.currentConfig().Profile)
 	if err != nil {
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
 
 	// Validate new configuration
 	if err := newConfig.Validate(); err != nil {
-		return fmt.Errorf("invalid configuration: %w", err)
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	// Apply network settings before rebuilding monitors so they pick them up
+	if err := s.monitorManager.SetEgressPolicy(&newConfig.Monitoring.Egress); err != nil {
+		return nil, fmt.Errorf("invalid egress policy: %w", err)
 	}
+	s.monitorManager.SetNetworkDefaults(networkDefaults(newConfig))
+	s.monitorManager.SetPluginDir(newConfig.Monitoring.PluginDir)
 
 	// Reload monitors with new configuration
-	if err := s.monitorManager.Reload(newConfig.Monitoring.Groups); err != nil {
-		return fmt.Errorf("failed to reload monitors: %w", err)
+	diff, err := s.monitorManager.Reload(newConfig.Monitoring.Groups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload monitors: %w", err)
 	}
 
-	// Reload scheduler to pick up new monitors
-	if err := s.scheduler.Reload(ctx); err != nil {
-		return fmt.Errorf("failed to reload scheduler: %w", err)
+	// Reschedule only the monitors that changed; the worker pool size only
+	// changes on restart
+	s.scheduler.SetGroupLimits(groupConcurrencyLimits(newConfig))
+	s.scheduler.SetWarmUp(newConfig.Monitoring.WarmUp.ToDuration())
+	s.scheduler.SetMaxChecksPerSecond(newConfig.Monitoring.MaxChecksPerSecond)
+	s.scheduler.SetStallDetection(newConfig.Monitoring.StallThreshold.ToDuration(), newConfig.Monitoring.CatchUpAfterStall)
+	if s.storage != nil {
+		// Before rescheduling, so results land in the tenant they now belong to
+		storage.ApplyTenants(s.storage, newConfig)
 	}
+	s.scheduler.ApplyReload(diff)
 
-	// Update server config reference
-	s.config = newConfig
+	// Swap the config handlers read; requests in flight keep the old one
+	s.setConfig(newConfig)
+
+	// Cached figures may depend on monitors and groups that just changed
+	s.cache.Clear()
 
 	s.logger.WithComponent(logging.ComponentAPI).
 		WithFields(map[string]interface{}{
 			"total_monitors": len(s.monitorManager.GetMonitors()),
 			"total_groups":   len(s.monitorManager.GetGroups()),
+			"added":          diff.Added,
+			"removed":        diff.Removed,
+			"changed":        diff.Changed,
+			"unchanged":      diff.Unchanged,
 		}).
 		Info("Configuration reloaded successfully")
 
-	return nil
+	return diff, nil
+}
+
+// configureMonitorNetwork applies the global egress policy, network defaults,
+// WASM runtime, and plugin directory to manager
+func configureMonitorNetwork(manager *monitors.MonitorManager, cfg *config.Config, logger *logging.Logger) {
+	if err := manager.SetEgressPolicy(&cfg.Monitoring.Egress); err != nil {
+		logger.WithComponent(logging.ComponentMonitor).
+			WithError(err).
+			Error("Invalid egress policy; monitors will not be restricted")
+	}
+	manager.SetNetworkDefaults(networkDefaults(cfg))
+	manager.SetPluginDir(cfg.Monitoring.PluginDir)
+}
+
+// configureScheduler applies the worker pool size, autoscaling, per-group
+// concurrency limits, and startup pacing
+func configureScheduler(sched *scheduler.Scheduler, cfg *config.Config) {
+	sched.SetWorkerCount(cfg.Monitoring.Workers)
+	sched.SetAutoscale(scheduler.AutoscaleConfig{
+		MaxWorkers:     cfg.Monitoring.Autoscale.MaxWorkers,
+		QueueThreshold: cfg.Monitoring.Autoscale.QueueThreshold,
+		LagThreshold:   cfg.Monitoring.Autoscale.LagThreshold.ToDuration(),
+		IdleTimeout:    cfg.Monitoring.Autoscale.IdleTimeout.ToDuration(),
+	})
+	sched.SetGroupLimits(groupConcurrencyLimits(cfg))
+	sched.SetWarmUp(cfg.Monitoring.WarmUp.ToDuration())
+	sched.SetMaxChecksPerSecond(cfg.Monitoring.MaxChecksPerSecond)
+	sched.SetStallDetection(cfg.Monitoring.StallThreshold.ToDuration(), cfg.Monitoring.CatchUpAfterStall)
+}
+
+// groupConcurrencyLimits returns the maxConcurrent setting of each limited group
+func groupConcurrencyLimits(cfg *config.Config) map[string]int {
+	limits := make(map[string]int)
+	for _, group := range cfg.Monitoring.Groups {
+		if group.MaxConcurrent > 0 {
+			limits[group.Name] = group.MaxConcurrent
+		}
+	}
+	return limits
+}
+
+// networkDefaults returns the global monitor network settings from cfg
+func networkDefaults(cfg *config.Config) monitors.NetworkDefaults {
+	return monitors.NetworkDefaults{
+		Resolver:        &cfg.Monitoring.Resolver,
+		SourceIP:        cfg.Monitoring.SourceIP,
+		SourceInterface: cfg.Monitoring.SourceInterface,
+	}
 }
 
 // errorHandler handles Fiber errors
@@ -4,15 +4,26 @@ console.log('Ambient Dashboard JS loaded - zen mode');
 // Alpine.js theme manager
 function themeManager() {
     return {
+        colorblind: false,
         init() {
             const savedTheme = localStorage.getItem('hallmonitor_theme') || 'dark';
             document.documentElement.dataset.theme = savedTheme;
+            const savedPalette = localStorage.getItem('hallmonitor_palette') || 'default';
+            document.documentElement.dataset.palette = savedPalette;
+            this.colorblind = savedPalette === 'colorblind';
         },
         toggle() {
             const html = document.documentElement;
             const newTheme = html.dataset.theme === 'dark' ? 'light' : 'dark';
             html.dataset.theme = newTheme;
             localStorage.setItem('hallmonitor_theme', newTheme);
+        },
+        // Switches status colors to a palette safe for red-green color blindness
+        togglePalette() {
+            this.colorblind = !this.colorblind;
+            const palette = this.colorblind ? 'colorblind' : 'default';
+            document.documentElement.dataset.palette = palette;
+            localStorage.setItem('hallmonitor_palette', palette);
         }
     };
 }
@@ -55,14 +66,14 @@ function updateZenUI(data) {
 
         // Update gradient and glow color based on health
         if (uptime >= 99.9) {
-            zenUptimeEl.style.background = 'linear-gradient(135deg, #48c78e 0%, #667eea 100%)';
-            zenUptimeEl.style.setProperty('--zen-glow', '72,199,142'); // Green glow
+            zenUptimeEl.style.background = 'linear-gradient(135deg, var(--status-up) 0%, #667eea 100%)';
+            zenUptimeEl.style.setProperty('--zen-glow', 'var(--status-up-rgb)'); // Green glow
         } else if (uptime >= 95) {
-            zenUptimeEl.style.background = 'linear-gradient(135deg, #ffdd57 0%, #667eea 100%)';
-            zenUptimeEl.style.setProperty('--zen-glow', '255,221,87'); // Yellow glow
+            zenUptimeEl.style.background = 'linear-gradient(135deg, var(--status-warn) 0%, #667eea 100%)';
+            zenUptimeEl.style.setProperty('--zen-glow', 'var(--status-warn-rgb)'); // Yellow glow
         } else {
-            zenUptimeEl.style.background = 'linear-gradient(135deg, #f14668 0%, #667eea 100%)';
-            zenUptimeEl.style.setProperty('--zen-glow', '241,70,104'); // Red glow
+            zenUptimeEl.style.background = 'linear-gradient(135deg, var(--status-down) 0%, #667eea 100%)';
+            zenUptimeEl.style.setProperty('--zen-glow', 'var(--status-down-rgb)'); // Red glow
         }
         zenUptimeEl.style.webkitBackgroundClip = 'text';
         zenUptimeEl.style.webkitTextFillColor = 'transparent';
@@ -79,19 +90,19 @@ function updateZenUI(data) {
     const zenIncidentsEl = document.getElementById('zen-incidents');
     if (zenIncidentsEl) {
         zenIncidentsEl.textContent = down;
-        zenIncidentsEl.style.color = down === 0 ? '#48c78e' : '#f14668';
+        zenIncidentsEl.style.color = down === 0 ? 'var(--status-up)' : 'var(--status-down)';
     }
 
     // Update message
     const zenMessageEl = document.getElementById('zen-message');
     if (zenMessageEl) {
         if (down === 0) {
-            zenMessageEl.textContent = 'All systems operational';
+            zenMessageEl.textContent = t('ambient.operational');
         } else if (down === 1) {
             const troubled = monitors.find(m => m.status !== 'up');
-            zenMessageEl.textContent = troubled ? `${troubled.name} is experiencing issues` : '1 monitor degraded';
+            zenMessageEl.textContent = troubled ? t('ambient.monitor_issues', troubled.name) : tn('ambient.attention', down);
         } else {
-            zenMessageEl.textContent = `${down} monitors require attention`;
+            zenMessageEl.textContent = tn('ambient.attention', down);
         }
     }
 }
@@ -1,18 +1,68 @@
 // Configuration Page JS
 console.log('Config page JS loaded');
 
+// Go duration syntax, as the config loader parses it
+const durationPattern = /^(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+$/;
+const durationUnits = { ns: 1e-6, us: 1e-3, 'µs': 1e-3, ms: 1, s: 1000, m: 60000, h: 3600000 };
+
+// Longest monitor name the config accepts, in bytes
+const maxMonitorNameLength = 255;
+
+// Longest timeout the config accepts, in milliseconds
+const maxTimeout = 5 * 60 * 1000;
+
+// Monitor settings the form edits; everything else on an edited monitor is kept
+const monitorFormFields = [
+    'url', 'target', 'query', 'queryType', 'expectedStatus', 'expectedResponse', 'httpVersion',
+    'headers', 'count', 'blocklists', 'expiryWarningDays', 'owner', 'team', 'runbookURL', 'description'
+];
+
+// parseDuration returns a Go duration string in milliseconds, or null if it is invalid
+function parseDuration(value) {
+    if (!durationPattern.test(value)) return null;
+    let total = 0;
+    for (const [, amount, , unit] of value.matchAll(/(\d+(\.\d+)?)(ns|us|µs|ms|s|m|h)/g)) {
+        total += parseFloat(amount) * durationUnits[unit];
+    }
+    return total;
+}
+
+// isWebURL reports whether value is an absolute http or https URL
+function isWebURL(value) {
+    try {
+        const parsed = new URL(value);
+        return (parsed.protocol === 'http:' || parsed.protocol === 'https:') && parsed.host !== '';
+    } catch {
+        return false;
+    }
+}
+
+// How long a bulk change can be undone, in milliseconds
+const undoWindow = 10000;
+
 // Alpine.js theme manager
 function themeManager() {
     return {
+        colorblind: false,
         init() {
             const savedTheme = localStorage.getItem('hallmonitor_theme') || 'dark';
             document.documentElement.dataset.theme = savedTheme;
+            const savedPalette = localStorage.getItem('hallmonitor_palette') || 'default';
+            document.documentElement.dataset.palette = savedPalette;
+            this.colorblind = savedPalette === 'colorblind';
         },
         toggle() {
             const html = document.documentElement;
             const newTheme = html.dataset.theme === 'dark' ? 'light' : 'dark';
             html.dataset.theme = newTheme;
             localStorage.setItem('hallmonitor_theme', newTheme);
+        },
+        // Switches status colors to a palette safe for red-green color blindness
+        togglePalette() {
+            this.colorblind = !this.colorblind;
+            const palette = this.colorblind ? 'colorblind' : 'default';
+            document.documentElement.dataset.palette = palette;
+            localStorage.setItem('hallmonitor_palette', palette);
         }
     };
 }
@@ -27,9 +77,18 @@ function configPageManager() {
         showGroupModal: false,
         editingMonitor: null,
         editingGroup: null,
+        revision: null,
         showToast: false,
         toastMessage: '',
         toastType: 'success',
+        monitorErrors: {},
+        testing: false,
+        testResult: null,
+        selectedMonitors: [],
+        bulkGroup: '',
+        undoRequest: null,
+        undoMessage: '',
+        undoTimer: null,
         monitorForm: {
             type: 'http',
             name: '',
@@ -40,6 +99,16 @@ function configPageManager() {
             interval: '30s',
             timeout: '10s',
             expectedStatus: 200,
+            expectedResponse: '',
+            httpVersion: '',
+            headersText: '',
+            count: '',
+            blocklistsText: '',
+            expiryWarningDays: '',
+            owner: '',
+            team: '',
+            runbookURL: '',
+            description: '',
             enabled: true,
             group: ''
         },
@@ -82,6 +151,9 @@ function configPageManager() {
                 const response = await fetch('/api/v1/config');
                 const data = await response.json();
 
+                // Track the revision our edits are based on
+                this.revision = data.revision;
+
                 // Extract monitors from groups
                 this.monitors = [];
                 this.groups = data.monitoring.groups || [];
@@ -98,6 +170,10 @@ function configPageManager() {
                     }
                 });
 
+                // Drop selected monitors that no longer exist
+                const names = new Set(this.monitors.map(monitor => monitor.name));
+                this.selectedMonitors = this.selectedMonitors.filter(name => names.has(name));
+
                 // Load storage configuration
                 if (data.storage) {
                     this.storageForm.backend = data.storage.backend || 'badger';
@@ -144,6 +220,16 @@ function configPageManager() {
                 interval: '30s',
                 timeout: '10s',
                 expectedStatus: 200,
+                expectedResponse: '',
+                httpVersion: '',
+                headersText: '',
+                count: '',
+                blocklistsText: '',
+                expiryWarningDays: '',
+                owner: '',
+                team: '',
+                runbookURL: '',
+                description: '',
                 enabled: true,
                 group: this.groups.length > 0 ? this.groups[0].name : ''
             };
@@ -160,45 +246,229 @@ function configPageManager() {
         openAddMonitor() {
             this.editingMonitor = null;
             this.monitorForm = this.getEmptyMonitorForm();
+            this.resetMonitorChecks();
             this.showMonitorModal = true;
         },
 
         openEditMonitor(monitor) {
             this.editingMonitor = monitor;
-            this.monitorForm = { ...monitor };
+            this.monitorForm = {
+                ...this.getEmptyMonitorForm(),
+                ...monitor,
+                blocklistsText: (monitor.blocklists || []).join(', '),
+                headersText: Object.entries(monitor.headers || {}).map(([name, value]) => `${name}: ${value}`).join('\n')
+            };
+            this.resetMonitorChecks();
             this.showMonitorModal = true;
         },
 
-        async saveMonitor() {
+        resetMonitorChecks() {
+            this.monitorErrors = {};
+            this.testResult = null;
+        },
+
+        // Checks the form against the rules the server applies when saving, so
+        // mistakes show next to their fields instead of as a failed save
+        validateMonitorForm() {
+            const form = this.monitorForm;
+            const errors = {};
+            const text = field => String(form[field] ?? '').trim();
+
+            const name = text('name');
+            if (!name) {
+                errors.name = 'Name is required';
+            } else if (new TextEncoder().encode(name).length > maxMonitorNameLength) {
+                errors.name = `Name must be at most ${maxMonitorNameLength} bytes`;
+            } else if (/[\u0000-\u001f\u007f-\u009f]/.test(name)) {
+                errors.name = 'Name cannot contain control characters';
+            } else if (this.monitors.some(monitor => monitor.name === name && monitor.name !== this.editingMonitor?.name)) {
+                errors.name = 'A monitor with this name already exists';
+            }
+            if (!this.editingMonitor && !form.group) {
+                errors.group = 'Choose a group';
+            }
+
+            switch (form.type) {
+            case 'http':
+                if (!text('url')) {
+                    errors.url = 'URL is required';
+                } else if (!isWebURL(text('url'))) {
+                    errors.url = 'URL must start with http:// or https://';
+                }
+                if (form.expectedStatus !== '' && form.expectedStatus != null &&
+                    !(Number.isInteger(Number(form.expectedStatus)) && form.expectedStatus >= 100 && form.expectedStatus <= 599)) {
+                    errors.expectedStatus = 'Status code must be between 100 and 599';
+                }
+                if (this.parseHeaders() === null) {
+                    errors.headers = 'Write one header per line as Name: value';
+                }
+                break;
+            case 'tcp':
+                if (!text('target')) {
+                    errors.target = 'Target is required';
+                } else if (!/^.+:\d{1,5}$/.test(text('target'))) {
+                    errors.target = 'Target must be host:port';
+                }
+                break;
+            case 'dns':
+                if (!text('target')) errors.target = 'DNS server is required';
+                if (!text('query')) errors.query = 'Query is required';
+                break;
+            case 'ping':
+                if (!text('target')) errors.target = 'Target is required';
+                if (text('count') && !(Number.isInteger(Number(form.count)) && form.count > 0)) {
+                    errors.count = 'Count must be a positive whole number';
+                }
+                break;
+            default:
+                if (!text('target')) errors.target = 'Target is required';
+            }
+            if (form.type === 'domain' && text('expiryWarningDays') && Number(form.expiryWarningDays) < 0) {
+                errors.expiryWarningDays = 'Expiry warning days cannot be negative';
+            }
+
+            if (text('interval')) {
+                const interval = parseDuration(text('interval'));
+                if (interval === null) {
+                    errors.interval = 'Use a duration such as 30s, 5m or 1h';
+                } else if (interval > 0 && interval < 1000) {
+                    errors.interval = 'Interval must be at least 1s';
+                }
+            }
+            if (text('timeout')) {
+                const timeout = parseDuration(text('timeout'));
+                if (timeout === null) {
+                    errors.timeout = 'Use a duration such as 10s or 1m';
+                } else if (timeout > maxTimeout) {
+                    errors.timeout = 'Timeout must be at most 5m';
+                }
+            }
+            if (text('runbookURL') && !isWebURL(text('runbookURL'))) {
+                errors.runbookURL = 'Runbook URL must start with http:// or https://';
+            }
+
+            this.monitorErrors = errors;
+            return Object.keys(errors).length === 0;
+        },
+
+        // Parses the headers text into an object, or returns null if a line is malformed
+        parseHeaders() {
+            const headers = {};
+            for (const line of (this.monitorForm.headersText || '').split('\n')) {
+                if (!line.trim()) continue;
+                const separator = line.indexOf(':');
+                const name = line.slice(0, separator).trim();
+                if (separator < 0 || !name || /\s/.test(name)) return null;
+                headers[name] = line.slice(separator + 1).trim();
+            }
+            return headers;
+        },
+
+        buildMonitorPayload() {
+            const form = this.monitorForm;
+
+            // Start from the monitor being edited so settings the form does not
+            // show, such as labels and quiet hours, are kept
+            const { group, ...payload } = this.editingMonitor || {};
+            for (const field of monitorFormFields) delete payload[field];
+
+            Object.assign(payload, {
+                type: form.type,
+                name: form.name.trim(),
+                interval: form.interval || (form.type === 'domain' ? '24h' : '30s'),
+                timeout: form.timeout || '10s',
+                enabled: form.enabled
+            });
+
+            // Ownership metadata, omitted when blank
+            for (const field of ['owner', 'team', 'runbookURL', 'description']) {
+                const value = (form[field] || '').trim();
+                if (value) payload[field] = value;
+            }
+
+            // Add type-specific fields
+            if (form.type === 'http') {
+                payload.url = form.url.trim();
+                if (form.expectedStatus) payload.expectedStatus = parseInt(form.expectedStatus);
+                if ((form.expectedResponse || '').trim()) payload.expectedResponse = form.expectedResponse.trim();
+                if (form.httpVersion) payload.httpVersion = form.httpVersion;
+                const headers = this.parseHeaders();
+                if (headers && Object.keys(headers).length) payload.headers = headers;
+            } else if (form.type === 'tcp') {
+                payload.target = form.target.trim();
+            } else if (form.type === 'ping') {
+                payload.target = form.target.trim();
+                if (form.count) payload.count = parseInt(form.count);
+            } else if (form.type === 'dns') {
+                payload.target = form.target.trim();
+                payload.query = form.query.trim();
+                payload.queryType = form.queryType || 'A';
+            } else if (form.type === 'rbl') {
+                payload.target = form.target.trim();
+                const blocklists = (form.blocklistsText || '')
+                    .split(',').map(zone => zone.trim()).filter(Boolean);
+                if (blocklists.length) payload.blocklists = blocklists;
+            } else if (form.type === 'domain') {
+                payload.target = form.target.trim();
+                if (form.expiryWarningDays) payload.expiryWarningDays = parseInt(form.expiryWarningDays);
+            }
+            return payload;
+        },
+
+        // Runs the form's monitor once on the server without saving it
+        async testMonitor() {
+            if (!this.validateMonitor()) return;
+
+            this.testing = true;
+            this.testResult = null;
             try {
-                const payload = {
-                    type: this.monitorForm.type,
-                    name: this.monitorForm.name,
-                    interval: this.monitorForm.interval || '30s',
-                    timeout: this.monitorForm.timeout || '10s',
-                    enabled: this.monitorForm.enabled
-                };
+                const response = await fetch('/api/v1/check', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify({
+                        group_name: this.editingMonitor ? this.editingMonitor.group : this.monitorForm.group,
+                        monitor: this.buildMonitorPayload()
+                    })
+                });
+                const result = await response.json();
 
-                // Add type-specific fields
-                if (this.monitorForm.type === 'http') {
-                    payload.url = this.monitorForm.url;
-                    if (this.monitorForm.expectedStatus) {
-                        payload.expectedStatus = parseInt(this.monitorForm.expectedStatus);
-                    }
-                } else if (this.monitorForm.type === 'tcp' || this.monitorForm.type === 'ping') {
-                    payload.target = this.monitorForm.target;
-                } else if (this.monitorForm.type === 'dns') {
-                    payload.query = this.monitorForm.query;
-                    payload.queryType = this.monitorForm.queryType || 'A';
+                if (!response.ok) {
+                    this.testResult = { status: 'invalid', error: result.error || result.message, trace: [] };
+                    return;
                 }
+                this.testResult = {
+                    status: result.result?.status || 'down',
+                    duration: result.result ? `${(result.result.duration / 1e6).toFixed(1)} ms` : '',
+                    error: result.error || result.result?.error || '',
+                    trace: result.trace || []
+                };
+            } catch (error) {
+                console.error('Failed to test monitor:', error);
+                this.testResult = { status: 'invalid', error: 'Failed to reach the server', trace: [] };
+            } finally {
+                this.testing = false;
+            }
+        },
+
+        validateMonitor() {
+            const valid = this.validateMonitorForm();
+            if (!valid) this.toast('Fix the highlighted fields', 'error');
+            return valid;
+        },
+
+        async saveMonitor() {
+            if (!this.validateMonitor()) return;
+
+            try {
+                const payload = this.buildMonitorPayload();
 
                 let response;
                 if (this.editingMonitor) {
                     // Update existing monitor
-                    response = await fetch(`/api/v1/monitors/${this.editingMonitor.name}`, {
+                    response = await fetch(`/api/v1/monitors/${encodeURIComponent(this.editingMonitor.name)}`, {
                         method: 'PUT',
                         headers: { 'Content-Type': 'application/json' },
-                        body: JSON.stringify({ monitor: payload })
+                        body: JSON.stringify({ monitor: payload, revision: this.revision })
                     });
                 } else {
                     // Create new monitor
@@ -207,7 +477,8 @@ function configPageManager() {
                         headers: { 'Content-Type': 'application/json' },
                         body: JSON.stringify({
                             group_name: this.monitorForm.group,
-                            monitor: payload
+                            monitor: payload,
+                            revision: this.revision
                         })
                     });
                 }
@@ -219,6 +490,8 @@ function configPageManager() {
                     await this.loadData();
                     this.toast(result.message, 'success');
                 } else {
+                    // Someone else changed the config; refresh so the next save uses the latest revision
+                    if (response.status === 409) await this.loadData();
                     this.toast(result.error || result.message, 'error');
                 }
             } catch (error) {
@@ -227,20 +500,57 @@ function configPageManager() {
             }
         },
 
+        async toggleMonitorEnabled(monitor) {
+            await this.toggleEnabled('monitors', monitor.name, monitor.enabled);
+        },
+
+        async toggleGroupEnabled(group) {
+            await this.toggleEnabled('groups', group.name, group.enabled !== false);
+        },
+
+        async toggleEnabled(collection, name, enabled) {
+            const action = enabled ? 'disable' : 'enable';
+
+            try {
+                const response = await fetch(`/api/v1/${collection}/${name}/${action}`, {
+                    method: 'POST',
+                    headers: { 'If-Match': `"${this.revision}"` }
+                });
+
+                const result = await response.json();
+
+                if (result.success) {
+                    await this.loadData();
+                    this.toast(result.message, 'success');
+                } else {
+                    // Someone else changed the config; refresh so the next save uses the latest revision
+                    if (response.status === 409) await this.loadData();
+                    this.toast(result.error || result.message, 'error');
+                }
+            } catch (error) {
+                console.error(`Failed to ${action} ${name}:`, error);
+                this.toast(`Failed to ${action} ${name}`, 'error');
+            }
+        },
+
         async confirmDeleteMonitor(monitor) {
             if (!confirm(`Delete monitor "${monitor.name}"?`)) return;
 
             try {
-                const response = await fetch(`/api/v1/monitors/${monitor.name}`, {
-                    method: 'DELETE'
+                const response = await fetch(`/api/v1/monitors/${encodeURIComponent(monitor.name)}`, {
+                    method: 'DELETE',
+                    headers: { 'If-Match': `"${this.revision}"` }
                 });
 
                 const result = await response.json();
 
                 if (result.success) {
+                    this.showMonitorModal = false;
                     await this.loadData();
                     this.toast(result.message, 'success');
                 } else {
+                    // Someone else changed the config; refresh so the next save uses the latest revision
+                    if (response.status === 409) await this.loadData();
                     this.toast(result.error || result.message, 'error');
                 }
             } catch (error) {
@@ -249,6 +559,101 @@ function configPageManager() {
             }
         },
 
+        isSelected(monitor) {
+            return this.selectedMonitors.includes(monitor.name);
+        },
+
+        toggleSelected(monitor) {
+            if (this.isSelected(monitor)) {
+                this.selectedMonitors = this.selectedMonitors.filter(name => name !== monitor.name);
+            } else {
+                this.selectedMonitors.push(monitor.name);
+            }
+        },
+
+        allSelected() {
+            return this.monitors.length > 0 && this.selectedMonitors.length === this.monitors.length;
+        },
+
+        toggleSelectAll() {
+            this.selectedMonitors = this.allSelected() ? [] : this.monitors.map(monitor => monitor.name);
+        },
+
+        // Adds every monitor of a group to the selection
+        selectGroup(groupName) {
+            const names = this.monitors.filter(monitor => monitor.group === groupName).map(monitor => monitor.name);
+            this.selectedMonitors = [...new Set([...this.selectedMonitors, ...names])];
+        },
+
+        // Applies an action to every selected monitor in one config change.
+        // Deleting and moving ask first; every change can be undone for a while.
+        async runBulkAction(action) {
+            const names = [...this.selectedMonitors];
+            if (names.length === 0) return;
+
+            const list = names.length > 10 ? `${names.slice(0, 10).join('\n')}\n...and ${names.length - 10} more` : names.join('\n');
+            if (action === 'delete' && !confirm(`Delete ${names.length} monitor(s)?\n\n${list}`)) return;
+            if (action === 'move') {
+                if (!this.bulkGroup) {
+                    this.toast('Choose a group to move the monitors to', 'error');
+                    return;
+                }
+                if (!confirm(`Move ${names.length} monitor(s) to "${this.bulkGroup}"? They will take on the group's defaults.\n\n${list}`)) return;
+            }
+
+            const request = { action, monitors: names, revision: this.revision };
+            if (action === 'move') request.group_name = this.bulkGroup;
+
+            const result = await this.postBulk(request);
+            if (!result) return;
+
+            this.selectedMonitors = [];
+            this.offerUndo(result.undo, result.message);
+        },
+
+        offerUndo(undo, message) {
+            clearTimeout(this.undoTimer);
+            this.undoRequest = undo || null;
+            this.undoMessage = message;
+            this.undoTimer = setTimeout(() => {
+                this.undoRequest = null;
+            }, undoWindow);
+        },
+
+        async undoBulkAction() {
+            const undo = this.undoRequest;
+            if (!undo) return;
+
+            clearTimeout(this.undoTimer);
+            this.undoRequest = null;
+            const result = await this.postBulk({ ...undo, revision: this.revision });
+            if (result) this.toast('Change undone', 'success');
+        },
+
+        async postBulk(request) {
+            try {
+                const response = await fetch('/api/v1/monitors/bulk', {
+                    method: 'POST',
+                    headers: { 'Content-Type': 'application/json' },
+                    body: JSON.stringify(request)
+                });
+
+                const result = await response.json();
+
+                if (result.success) {
+                    await this.loadData();
+                    return result;
+                }
+                // Someone else changed the config; refresh so the next save uses the latest revision
+                if (response.status === 409) await this.loadData();
+                this.toast(result.error || result.message, 'error');
+            } catch (error) {
+                console.error(`Failed to ${request.action} monitors:`, error);
+                this.toast(`Failed to ${request.action} monitors`, 'error');
+            }
+            return null;
+        },
+
         openAddGroup() {
             this.editingGroup = null;
             this.groupForm = this.getEmptyGroupForm();
@@ -264,9 +669,13 @@ function configPageManager() {
         async saveGroup() {
             try {
                 const payload = {
+                    // Keep group-level defaults (timeout, headers, labels, retries, ...)
+                    ...this.groupForm,
                     name: this.groupForm.name,
                     interval: this.groupForm.interval || '30s',
-                    monitors: this.groupForm.monitors || []
+                    monitors: this.groupForm.monitors || [],
+                    // Keep template expansions; their monitors are regenerated on save
+                    expand: this.groupForm.expand || []
                 };
 
                 let response;
@@ -275,14 +684,14 @@ function configPageManager() {
                     response = await fetch(`/api/v1/groups/${this.editingGroup.name}`, {
                         method: 'PUT',
                         headers: { 'Content-Type': 'application/json' },
-                        body: JSON.stringify({ group: payload })
+                        body: JSON.stringify({ group: payload, revision: this.revision })
                     });
                 } else {
                     // Create new group
                     response = await fetch('/api/v1/groups', {
                         method: 'POST',
                         headers: { 'Content-Type': 'application/json' },
-                        body: JSON.stringify({ group: payload })
+                        body: JSON.stringify({ group: payload, revision: this.revision })
                     });
                 }
 
@@ -293,6 +702,8 @@ function configPageManager() {
                     await this.loadData();
                     this.toast(result.message, 'success');
                 } else {
+                    // Someone else changed the config; refresh so the next save uses the latest revision
+                    if (response.status === 409) await this.loadData();
                     this.toast(result.error || result.message, 'error');
                 }
             } catch (error) {
@@ -306,7 +717,8 @@ function configPageManager() {
 
             try {
                 const response = await fetch(`/api/v1/groups/${group.name}`, {
-                    method: 'DELETE'
+                    method: 'DELETE',
+                    headers: { 'If-Match': `"${this.revision}"` }
                 });
 
                 const result = await response.json();
@@ -315,6 +727,8 @@ function configPageManager() {
                     await this.loadData();
                     this.toast(result.message, 'success');
                 } else {
+                    // Someone else changed the config; refresh so the next save uses the latest revision
+                    if (response.status === 409) await this.loadData();
                     this.toast(result.error || result.message, 'error');
                 }
             } catch (error) {
@@ -361,7 +775,7 @@ function configPageManager() {
                 const response = await fetch('/api/v1/config', {
                     method: 'PUT',
                     headers: { 'Content-Type': 'application/json' },
-                    body: JSON.stringify({ config: currentConfig })
+                    body: JSON.stringify({ config: currentConfig, revision: currentConfig.revision })
                 });
 
                 const result = await response.json();
@@ -369,6 +783,8 @@ function configPageManager() {
                 if (result.success) {
                     this.toast('Storage configuration saved successfully. Restart required for changes to take effect.', 'success');
                 } else {
+                    // Someone else changed the config; refresh so the next save uses the latest revision
+                    if (response.status === 409) await this.loadData();
                     this.toast(result.error || result.message, 'error');
                 }
             } catch (error) {
@@ -4,15 +4,26 @@ console.log('Dashboard JS loaded - v2.0 with null checks');
 // Alpine.js theme manager
 function themeManager() {
     return {
+        colorblind: false,
         init() {
             const savedTheme = localStorage.getItem('hallmonitor_theme') || 'dark';
             document.documentElement.dataset.theme = savedTheme;
+            const savedPalette = localStorage.getItem('hallmonitor_palette') || 'default';
+            document.documentElement.dataset.palette = savedPalette;
+            this.colorblind = savedPalette === 'colorblind';
         },
         toggle() {
             const html = document.documentElement;
             const newTheme = html.dataset.theme === 'dark' ? 'light' : 'dark';
             html.dataset.theme = newTheme;
             localStorage.setItem('hallmonitor_theme', newTheme);
+        },
+        // Switches status colors to a palette safe for red-green color blindness
+        togglePalette() {
+            this.colorblind = !this.colorblind;
+            const palette = this.colorblind ? 'colorblind' : 'default';
+            document.documentElement.dataset.palette = palette;
+            localStorage.setItem('hallmonitor_palette', palette);
         }
     };
 }
@@ -77,7 +88,7 @@ async function loadData() {
 async function loadUptimeHistory() {
     try {
         // Fetch historical data from new history API
-        const monitors = monitorsData?.monitors || [];
+        const monitors = (monitorsData?.monitors || []).filter(m => m.enabled !== false);
         if (monitors.length === 0) {
             generateHeatmap(null);
             return;
@@ -90,14 +101,9 @@ async function loadUptimeHistory() {
             return;
         }
 
-        // Fetch last 30 days of history
-        const end = new Date();
-        const start = new Date();
-        start.setDate(start.getDate() - 30);
-
+        // Fetch daily availability for the longest heatmap range in one call
         const response = await fetch(
-            `${API_ENDPOINT}/monitors/${encodeURIComponent(monitorName)}/history?` +
-            `start=${start.toISOString()}&end=${end.toISOString()}&limit=10000`
+            `${API_ENDPOINT}/monitors/${encodeURIComponent(monitorName)}/heatmap?period=90d&bucket=1d`
         );
 
         if (!response.ok) {
@@ -105,7 +111,7 @@ async function loadUptimeHistory() {
         }
 
         const data = await response.json();
-        uptimeHistory = parseHistoricalResults(data.results || []);
+        uptimeHistory = parseHeatmapBuckets(data.buckets || []);
         generateHeatmap(uptimeHistory);
     } catch (error) {
         console.error('Failed to load uptime history:', error);
@@ -114,37 +120,22 @@ async function loadUptimeHistory() {
     }
 }
 
-function parseHistoricalResults(results) {
-    // Convert historical results into daily uptime data
-    const uptimeData = {};
-
-    for (const result of results) {
-        const date = result.timestamp.split('T')[0];
-        if (!uptimeData[date]) {
-            uptimeData[date] = { up: 0, down: 0, total: 0 };
-        }
-
-        uptimeData[date].total++;
-        if (result.status === 'up') {
-            uptimeData[date].up++;
-        } else {
-            uptimeData[date].down++;
-        }
-    }
-
-    // Convert to array of uptime values (0-1 range)
+function parseHeatmapBuckets(buckets) {
+    // Convert daily buckets into uptime values (0-1 range) keyed by date;
+    // days without checks are left out so they render as no data
     const dailyUptimes = {};
-    for (const [date, stats] of Object.entries(uptimeData)) {
-        dailyUptimes[date] = stats.total > 0 ? stats.up / stats.total : 1.0;
+    for (const bucket of buckets) {
+        if (bucket.total_checks > 0) {
+            dailyUptimes[bucket.start.split('T')[0]] = bucket.uptime_percent / 100;
+        }
     }
-
     return dailyUptimes;
 }
 
 async function loadUptimeStats() {
     try {
         // Load uptime statistics for different periods
-        const monitors = monitorsData?.monitors || [];
+        const monitors = (monitorsData?.monitors || []).filter(m => m.enabled !== false);
         if (monitors.length === 0) return;
 
         const monitorName = monitors[0]?.name;
@@ -206,7 +197,9 @@ function parseDuration(durationStr) {
 }
 
 function updateUI(data) {
-    const monitors = data.monitors || [];
+    // Disabled monitors are listed but do not count towards health
+    const allMonitors = data.monitors || [];
+    const monitors = allMonitors.filter(m => m.enabled !== false);
     const total = monitors.length;
     const down = monitors.filter(m => m.status !== 'up').length;
     const uptime = total > 0 ? ((total - down) / total * 100) : 100;
@@ -237,21 +230,21 @@ function updateUI(data) {
 	}
 	const heroSubEl = document.getElementById('hero-sublabel');
 	if (heroSubEl) {
-		heroSubEl.textContent = `${total - down} of ${total} monitors healthy`;
+		heroSubEl.textContent = t('dashboard.healthy_of', total - down, total);
 	}
 	const heroValue = document.getElementById('hero-ring');
 	const heroCaption = document.querySelector('.hero-caption');
 	const heroNarrative = document.getElementById('hero-narrative');
 	const meetsSLA = uptime >= SLA_THRESHOLD;
-	const glowColor = meetsSLA ? '72,199,142' : '241,70,104';
+	const glowColor = meetsSLA ? 'var(--status-up-rgb)' : 'var(--status-down-rgb)';
 	if (heroValue) {
 		heroValue.style.setProperty('--hero-glow', glowColor);
 	}
 	if (heroCaption) {
-		heroCaption.textContent = meetsSLA ? 'uptime' : 'below target';
+		heroCaption.textContent = meetsSLA ? t('dashboard.uptime') : t('dashboard.below_target');
 	}
 	if (heroNarrative) {
-		heroNarrative.textContent = meetsSLA ? 'All services are within expected ranges.' : `${down} monitor${down > 1 ? 's' : ''} degraded · see Focus list.`;
+		heroNarrative.textContent = meetsSLA ? t('dashboard.narrative_ok') : tn('dashboard.narrative_degraded', down);
 	}
 
 	// Update compact metrics (with null checks)
@@ -268,7 +261,7 @@ function updateUI(data) {
 	if (errorRateValueEl) {
 		errorRateValueEl.textContent = errorRate.toFixed(2) + '%';
 		// Update color
-		errorRateValueEl.style.color = errorRate === 0 ? '#48c78e' : errorRate < 1 ? '#ffdd57' : '#f14668';
+		errorRateValueEl.style.color = errorRate === 0 ? 'var(--status-up)' : errorRate < 1 ? 'var(--status-warn)' : 'var(--status-down)';
 	}
 
 	const heroMonitors = document.getElementById('hero-pill-monitors');
@@ -279,15 +272,15 @@ function updateUI(data) {
 	const heroLastAlert = document.getElementById('hero-last-alert');
 	if (heroLastAlert) {
 		if (down === 0) {
-			heroLastAlert.textContent = 'All systems steady';
+			heroLastAlert.textContent = t('dashboard.all_steady');
 		} else {
 			const troubled = monitors.find(m => m.status !== 'up');
-			heroLastAlert.textContent = troubled ? `${troubled.name} · ${troubled.group || troubled.type}` : `${down} monitors degraded`;
+			heroLastAlert.textContent = troubled ? `${troubled.name} · ${troubled.group || troubled.type}` : tn('dashboard.monitors_degraded', down);
 		}
 	}
 
     // Update monitor list
-    updateMonitorList(monitors);
+    updateMonitorList(allMonitors);
 }
 
 let filteredMonitors = [];
@@ -311,6 +304,8 @@ function handleSearch(query) {
             (monitor.hostname && monitor.hostname.toLowerCase().includes(searchQuery)) ||
             (monitor.ip_address && monitor.ip_address.toLowerCase().includes(searchQuery)) ||
             (monitor.query && monitor.query.toLowerCase().includes(searchQuery)) ||
+            (monitor.owner && monitor.owner.toLowerCase().includes(searchQuery)) ||
+            (monitor.team && monitor.team.toLowerCase().includes(searchQuery)) ||
             (monitor.labels && Object.values(monitor.labels).some(v => v.toLowerCase().includes(searchQuery)))
         );
     }
@@ -320,7 +315,7 @@ function handleSearch(query) {
 }
 
 function formatTimeAgo(timestamp) {
-    if (!timestamp) return 'Never';
+    if (!timestamp) return t('dashboard.never');
     const date = new Date(timestamp);
     const now = new Date();
     const diffMs = now - date;
@@ -328,10 +323,10 @@ function formatTimeAgo(timestamp) {
     const diffMin = Math.floor(diffSec / 60);
     const diffHour = Math.floor(diffMin / 60);
 
-    if (diffSec < 60) return `${diffSec}s ago`;
-    if (diffMin < 60) return `${diffMin}m ago`;
-    if (diffHour < 24) return `${diffHour}h ago`;
-    return `${Math.floor(diffHour / 24)}d ago`;
+    if (diffSec < 60) return t('dashboard.seconds_ago', diffSec);
+    if (diffMin < 60) return t('dashboard.minutes_ago', diffMin);
+    if (diffHour < 24) return t('dashboard.hours_ago', diffHour);
+    return t('dashboard.days_ago', Math.floor(diffHour / 24));
 }
 
 function formatDate(value) {
@@ -358,8 +353,8 @@ function renderTable() {
             <tr>
                 <td colspan="6">
                     <div class="empty-state">
-                        <i class="fas fa-search"></i>
-                        <p>No monitors found</p>
+                        <i class="fas fa-search" aria-hidden="true"></i>
+                        <p>${t('dashboard.no_monitors_found')}</p>
                     </div>
                 </td>
             </tr>
@@ -367,15 +362,14 @@ function renderTable() {
         return;
     }
 
-    // Sort: down monitors first, then by name
+    // Sort: down monitors first, disabled monitors last, then by name
+    const rank = m => m.status === 'disabled' ? 2 : m.status === 'up' ? 1 : 0;
     const sortedMonitors = [...visibleMonitors].sort((a, b) => {
-        const aUp = a.status === 'up' ? 1 : 0;
-        const bUp = b.status === 'up' ? 1 : 0;
-        if (aUp !== bUp) return aUp - bUp;
+        if (rank(a) !== rank(b)) return rank(a) - rank(b);
         return a.name.localeCompare(b.name);
     });
 
-    tableBody.innerHTML = sortedMonitors.map(monitor => {
+    tableBody.innerHTML = sortedMonitors.map((monitor, index) => {
         const isUp = monitor.status === 'up';
         const responseTime = parseDuration(monitor.duration);
 
@@ -425,13 +419,16 @@ function renderTable() {
         const dnsResult = monitor.dns_result;
 
         const monitorId = escapeHtml(monitor.name);
+        const detailId = `monitor-detail-${index}`;
+        const disabledStyle = monitor.status === 'disabled' ? ' style="opacity: 0.5;"' : '';
         return `
-            <tr class="main-row"
+            <tr class="main-row"${disabledStyle}
                 :class="{ 'expanded': expandedRow === '${monitorId}' }"
                 @click="expandedRow = expandedRow === '${monitorId}' ? null : '${monitorId}'">
                 <td>
                     <div class="monitor-name-cell">
-                        <span class="status-dot ${monitor.status}"></span>
+                        <span class="status-dot ${monitor.status}" aria-hidden="true"></span>
+                        <span class="sr-only">${statusLabel(monitor.status)}</span>
                         <div class="monitor-primary">
                             <span class="monitor-name">${monitor.name}</span>
                             <span class="monitor-type">${monitor.type}</span>
@@ -445,12 +442,15 @@ function renderTable() {
                 <td><span class="metric-value ${responseClass}">${responseTime > 0 ? responseTime.toFixed(1) : '--'}ms</span></td>
                 <td><span style="opacity: 0.5; font-size: 0.9375rem;">${formatTimeAgo(monitor.last_check)}</span></td>
                 <td class="expand-cell">
-                    <div class="expand-icon">
-                        <i class="fas fa-chevron-down"></i>
-                    </div>
+                    <button type="button" class="expand-icon"
+                            aria-controls="${detailId}"
+                            :aria-expanded="(expandedRow === '${monitorId}').toString()"
+                            aria-label="${t('dashboard.show_details', monitorId)}">
+                        <i class="fas fa-chevron-down" aria-hidden="true"></i>
+                    </button>
                 </td>
             </tr>
-            <tr class="detail-row"
+            <tr class="detail-row" id="${detailId}"
                 x-show="expandedRow === '${monitorId}'"
                 x-collapse>
                 <td colspan="6">
@@ -463,6 +463,14 @@ function renderTable() {
     }).join('');
 }
 
+// statusLabel names a monitor status for screen readers
+function statusLabel(status) {
+    if (status === 'up' || status === 'disabled' || status === 'unknown') {
+        return t(`dashboard.status.${status}`);
+    }
+    return t('dashboard.status.down');
+}
+
 function renderDetailBlocks(monitor) {
     const blocks = [];
     const addBlock = (label, value, options = {}) => {
@@ -480,69 +488,89 @@ function renderDetailBlocks(monitor) {
         `);
     };
 
-    addBlock('Last check', formatTimeAgo(monitor.last_check));
-    addBlock('Monitor type', monitor.type?.toUpperCase());
-    addBlock('Current status', monitor.status === 'up' ? '<span style="color:#48c78e">Up</span>' : '<span style="color:#f14668">Down</span>', { raw: true });
+    addBlock(t('dashboard.detail.last_check'), formatTimeAgo(monitor.last_check));
+    addBlock(t('dashboard.detail.monitor_type'), monitor.type?.toUpperCase());
+    const statusStyles = {
+        up: 'color:var(--status-up)',
+        disabled: 'opacity:0.6',
+        unknown: 'opacity:0.6'
+    };
+    const statusStyle = statusStyles[monitor.status] || 'color:var(--status-down)';
+    addBlock(t('dashboard.detail.current_status'), `<span style="${statusStyle}">${statusLabel(monitor.status)}</span>`, { raw: true });
 
     const target = monitor.url || monitor.target || monitor.query;
     if (target) {
-        addBlock('Target', target, { full: true });
+        addBlock(t('dashboard.detail.target'), target, { full: true });
+    }
+
+    if (monitor.description) {
+        addBlock(t('dashboard.detail.description'), monitor.description, { full: true });
+    }
+    addBlock(t('dashboard.detail.owner'), monitor.owner);
+    addBlock(t('dashboard.detail.team'), monitor.team);
+    if (monitor.runbook_url) {
+        const runbook = escapeHtml(monitor.runbook_url);
+        addBlock(t('dashboard.detail.runbook'), `<a href="${runbook}" target="_blank" rel="noopener noreferrer">${runbook}</a>`, { raw: true, full: true });
     }
 
     if (monitor.error) {
-        addBlock('Current error', `<span style="color:#f14668;">${escapeHtml(monitor.error)}</span>`, { raw: true, full: true });
+        addBlock(t('dashboard.detail.current_error'), `<span style="color:var(--status-down);">${escapeHtml(monitor.error)}</span>`, { raw: true, full: true });
     }
 
     const httpResult = monitor.http_result || {};
     if (httpResult.status_code) {
-        addBlock('HTTP status', `${httpResult.status_code}`);
+        addBlock(t('dashboard.detail.http_status'), `${httpResult.status_code}`);
     }
     if (httpResult.response_size) {
-        addBlock('Response size', `${httpResult.response_size} bytes`);
+        addBlock(t('dashboard.detail.response_size'), t('dashboard.detail.bytes', httpResult.response_size));
     }
     if (httpResult.ssl_cert_expiry) {
-        addBlock('SSL expires', formatDate(httpResult.ssl_cert_expiry));
+        addBlock(t('dashboard.detail.ssl_expires'), formatDate(httpResult.ssl_cert_expiry));
+    }
+    if (httpResult.headers && Object.keys(httpResult.headers).length) {
+        const headers = Object.entries(httpResult.headers).map(([key, value]) => `<span class="tag">${escapeHtml(key)}: ${escapeHtml(value)}</span>`).join('');
+        addBlock(t('dashboard.detail.response_headers'), `<div style="margin-top:0.25rem; display:flex; flex-wrap:wrap; gap:0.25rem;">${headers}</div>`, { raw: true, full: true });
     }
 
     const pingResult = monitor.ping_result || {};
     if (pingResult.packet_loss !== undefined) {
-        addBlock('Packet loss', `${pingResult.packet_loss.toFixed(1)}%`);
+        addBlock(t('dashboard.detail.packet_loss'), `${pingResult.packet_loss.toFixed(1)}%`);
     }
     if (pingResult.avg_rtt) {
-        addBlock('Average RTT', `${pingResult.avg_rtt}`);
+        addBlock(t('dashboard.detail.average_rtt'), `${pingResult.avg_rtt}`);
     }
 
     const tcpResult = monitor.tcp_result || {};
     if (tcpResult.port) {
-        addBlock('TCP port', tcpResult.port);
+        addBlock(t('dashboard.detail.tcp_port'), tcpResult.port);
     }
     if (tcpResult.response_time) {
-        addBlock('Connect time', `${tcpResult.response_time}`);
+        addBlock(t('dashboard.detail.connect_time'), `${tcpResult.response_time}`);
     }
 
     const dnsResult = monitor.dns_result || {};
     if (dnsResult.query_type) {
-        addBlock('DNS query type', dnsResult.query_type);
+        addBlock(t('dashboard.detail.dns_query_type'), dnsResult.query_type);
     }
     if (Array.isArray(dnsResult.answers) && dnsResult.answers.length > 0) {
         const answers = dnsResult.answers.map(ans => `<span class="tag">${escapeHtml(ans)}</span>`).join('');
-        addBlock('DNS answers', `<div style="margin-top:0.25rem; display:flex; flex-wrap:wrap; gap:0.25rem;">${answers}</div>`, { raw: true, full: true });
+        addBlock(t('dashboard.detail.dns_answers'), `<div style="margin-top:0.25rem; display:flex; flex-wrap:wrap; gap:0.25rem;">${answers}</div>`, { raw: true, full: true });
     }
 
     if (monitor.labels && Object.keys(monitor.labels).length) {
         const tags = Object.entries(monitor.labels).map(([key, value]) => `<span class="tag">${escapeHtml(key)}:${escapeHtml(value)}</span>`).join('');
-        addBlock('Labels', `<div style="margin-top:0.25rem; display:flex; flex-wrap:wrap; gap:0.25rem;">${tags}</div>`, { raw: true, full: true });
+        addBlock(t('dashboard.detail.labels'), `<div style="margin-top:0.25rem; display:flex; flex-wrap:wrap; gap:0.25rem;">${tags}</div>`, { raw: true, full: true });
     }
 
     if (monitor.metadata && typeof monitor.metadata === 'object') {
         const entries = Object.entries(monitor.metadata).map(([key, value]) => `<span class="tag">${escapeHtml(key)}:${escapeHtml(String(value))}</span>`).join('');
         if (entries) {
-            addBlock('Metadata', `<div style="margin-top:0.25rem; display:flex; flex-wrap:wrap; gap:0.25rem;">${entries}</div>`, { raw: true, full: true });
+            addBlock(t('dashboard.detail.metadata'), `<div style="margin-top:0.25rem; display:flex; flex-wrap:wrap; gap:0.25rem;">${entries}</div>`, { raw: true, full: true });
         }
     }
 
     if (!blocks.length) {
-        addBlock('Details', 'No additional data');
+        addBlock(t('dashboard.detail.details'), t('dashboard.detail.no_data'));
     }
 
     return blocks.join('');
@@ -663,31 +691,33 @@ function _generateHeatmapCells(grid, days, today, todayStr, historyData) {
         if (isFuture) {
             // Future date - not yet occurred
             cell.className = 'heatmap-cell level-future';
-            tooltipText = `${date.toLocaleDateString()}: Not yet occurred`;
+            tooltipText = t('dashboard.heatmap.future', date.toLocaleDateString());
         } else if (hasHistoricalData) {
             // Has actual historical data from BadgerDB
             const uptimeValue = historyData[dateStr]; // 0-1 range
             const level = calculateUptimeLevel(uptimeValue);
             const uptimePercent = (uptimeValue * 100).toFixed(1);
             cell.className = `heatmap-cell level-${level}`;
-            tooltipText = `${date.toLocaleDateString()}: ${uptimePercent}% uptime`;
+            tooltipText = t('dashboard.heatmap.uptime', date.toLocaleDateString(), uptimePercent);
         } else if (isToday && monitorsData) {
             // Current day - use live monitor status
-            const monitors = monitorsData.monitors || [];
+            const monitors = (monitorsData.monitors || []).filter(m => m.enabled !== false);
             const total = monitors.length;
             const up = monitors.filter(m => m.status === 'up').length;
             const uptimeValue = total > 0 ? (up / total) : 1.0;
             const level = calculateUptimeLevel(uptimeValue);
             const uptimePercent = (uptimeValue * 100).toFixed(1);
             cell.className = `heatmap-cell level-${level}`;
-            tooltipText = `${date.toLocaleDateString()}: ${uptimePercent}% uptime (current)`;
+            tooltipText = t('dashboard.heatmap.uptime_current', date.toLocaleDateString(), uptimePercent);
         } else {
             // Past date with no data - Hall Monitor wasn't running
             cell.className = 'heatmap-cell level-past-nodata';
-            tooltipText = `${date.toLocaleDateString()}: No data (Hall Monitor not running)`;
+            tooltipText = t('dashboard.heatmap.no_data', date.toLocaleDateString());
         }
 
         cell.title = tooltipText;
+        cell.setAttribute('role', 'img');
+        cell.setAttribute('aria-label', tooltipText);
         grid.appendChild(cell);
     }
 }
@@ -0,0 +1,13 @@
+// Translation helpers for page scripts. Pages embed their messages in
+// window.HM_MESSAGES as format strings using %s, %d, and %%.
+function t(key, ...args) {
+    const messages = window.HM_MESSAGES || {};
+    const message = messages[key] ?? key;
+    let next = 0;
+    return message.replace(/%([%sd])/g, (match, verb) => verb === '%' ? '%' : String(args[next++]));
+}
+
+// tn formats the .one or .other form of a message for count
+function tn(key, count, ...args) {
+    return t(`${key}.${count === 1 ? 'one' : 'other'}`, count, ...args);
+}
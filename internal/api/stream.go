@@ -0,0 +1,339 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	// streamHistorySize is the number of recent events kept for Last-Event-ID resume
+	streamHistorySize = 500
+	// streamSubscriberBuffer is the per-client event buffer; a client that
+	// falls further behind is disconnected
+	streamSubscriberBuffer = 64
+	// streamHeartbeatInterval keeps idle connections alive through proxies
+	streamHeartbeatInterval = 15 * time.Second
+)
+
+// streamMaxDuration bounds a single SSE connection below the server write
+// timeout; EventSource clients reconnect automatically and resume via Last-Event-ID.
+var streamMaxDuration = 25 * time.Second
+
+// streamEvent is a single server-sent event
+type streamEvent struct {
+	ID    uint64
+	Type  string
+	Group string // Group of the event's monitor, for tenant filtering
+	Data  []byte
+}
+
+// StreamStatusEvent is the payload of a "status" event
+type StreamStatusEvent struct {
+	Monitor   string    `json:"monitor"`
+	Type      string    `json:"type"`
+	Group     string    `json:"group"`
+	Status    string    `json:"status"`
+	Duration  string    `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// StreamAlertEvent is the payload of an "alert" event emitted on status transitions
+type StreamAlertEvent struct {
+	Monitor        string    `json:"monitor"`
+	Group          string    `json:"group"`
+	State          string    `json:"state"` // "firing" or "resolved"
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status"`
+	Error          string    `json:"error,omitempty"`
+	Quiet          string    `json:"quiet,omitempty"` // Quiet-hours action in effect: "suppress" or "downgrade"
+	Timestamp      time.Time `json:"timestamp"`
+}
+
+// StreamContentChangedEvent is the payload of a "content_changed" event emitted
+// when an HTTP monitor's response body differs from the previous check
+type StreamContentChangedEvent struct {
+	Monitor      string    `json:"monitor"`
+	Group        string    `json:"group"`
+	Hash         string    `json:"hash"`
+	PreviousHash string    `json:"previous_hash"`
+	Snippet      string    `json:"snippet,omitempty"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// StreamTargetChangedEvent is the payload of a "target_changed" event emitted
+// when a monitor with detectTargetChanges reaches a different address or
+// certificate than on the previous check
+type StreamTargetChangedEvent struct {
+	Monitor            string    `json:"monitor"`
+	Group              string    `json:"group"`
+	PreviousIP         string    `json:"previous_ip,omitempty"`
+	IP                 string    `json:"ip,omitempty"`
+	PreviousCertSerial string    `json:"previous_cert_serial,omitempty"`
+	CertSerial         string    `json:"cert_serial,omitempty"`
+	Timestamp          time.Time `json:"timestamp"`
+}
+
+// eventBroker fans monitor results out to SSE subscribers and keeps a short
+// history so reconnecting clients can resume from their last event ID
+type eventBroker struct {
+	mu          sync.RWMutex
+	nextID      uint64
+	history     []streamEvent
+	subscribers map[chan streamEvent]struct{}
+	lastStatus  map[string]models.MonitorStatus
+
+	// done is closed on shutdown to end open streams
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newEventBroker creates an empty event broker
+func newEventBroker() *eventBroker {
+	return &eventBroker{
+		history:     make([]streamEvent, 0, streamHistorySize),
+		subscribers: make(map[chan streamEvent]struct{}),
+		lastStatus:  make(map[string]models.MonitorStatus),
+		done:        make(chan struct{}),
+	}
+}
+
+// close ends every open stream. Clients reconnect after the retry delay, to
+// this process if it is still serving or to its replacement.
+func (b *eventBroker) close() {
+	b.closeOnce.Do(func() { close(b.done) })
+}
+
+// HandleResult publishes a status event, a content_changed event when an HTTP
+// body changed, a target_changed event when the target moved, and, on
+// transitions, an alert event
+func (b *eventBroker) HandleResult(result *models.MonitorResult) {
+	if result == nil {
+		return
+	}
+
+	status := StreamStatusEvent{
+		Monitor:   result.Monitor,
+		Type:      string(result.Type),
+		Group:     result.Group,
+		Status:    string(result.Status),
+		Duration:  result.Duration.String(),
+		Error:     result.Error,
+		Timestamp: result.Timestamp,
+	}
+	b.publish("status", result.Group, status)
+
+	if result.HTTPResult != nil && result.HTTPResult.ContentChanged {
+		b.publish("content_changed", result.Group, StreamContentChangedEvent{
+			Monitor:      result.Monitor,
+			Group:        result.Group,
+			Hash:         result.HTTPResult.BodyHash,
+			PreviousHash: result.HTTPResult.PreviousBodyHash,
+			Snippet:      result.HTTPResult.BodySnippet,
+			Timestamp:    result.Timestamp,
+		})
+	}
+
+	if result.TargetChange != nil {
+		b.publish("target_changed", result.Group, StreamTargetChangedEvent{
+			Monitor:            result.Monitor,
+			Group:              result.Group,
+			PreviousIP:         result.TargetChange.PreviousIP,
+			IP:                 result.TargetChange.IP,
+			PreviousCertSerial: result.TargetChange.PreviousCertSerial,
+			CertSerial:         result.TargetChange.CertSerial,
+			Timestamp:          result.Timestamp,
+		})
+	}
+
+	b.mu.Lock()
+	previous, seen := b.lastStatus[result.Monitor]
+	b.lastStatus[result.Monitor] = result.Status
+	b.mu.Unlock()
+
+	if !seen || previous == result.Status || result.Status == models.StatusUnknown {
+		return
+	}
+
+	state := ""
+	switch {
+	case result.Status == models.StatusDown:
+		state = "firing"
+	case previous == models.StatusDown:
+		state = "resolved"
+	default:
+		return
+	}
+
+	b.publish("alert", result.Group, StreamAlertEvent{
+		Monitor:        result.Monitor,
+		Group:          result.Group,
+		State:          state,
+		Status:         string(result.Status),
+		PreviousStatus: string(previous),
+		Error:          result.Error,
+		Quiet:          result.Quiet,
+		Timestamp:      result.Timestamp,
+	})
+}
+
+// publish assigns an ID to an event, records it, and delivers it to subscribers
+func (b *eventBroker) publish(eventType, group string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := streamEvent{ID: b.nextID, Type: eventType, Group: group, Data: data}
+
+	if len(b.history) >= streamHistorySize {
+		b.history = append(b.history[:0], b.history[1:]...)
+	}
+	b.history = append(b.history, event)
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber is not keeping up. Rather than skip the event, end
+			// its stream once the buffer drains, so it reconnects and replays
+			// from its last event ID.
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// subscribe registers a new subscriber and returns events after lastID for
+// replay. The channel is closed if the subscriber falls behind.
+func (b *eventBroker) subscribe(lastID uint64) ([]streamEvent, chan streamEvent, func()) {
+	ch := make(chan streamEvent, streamSubscriberBuffer)
+
+	b.mu.Lock()
+	var replay []streamEvent
+	if lastID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastID {
+				replay = append(replay, event)
+			}
+		}
+	}
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+
+	return replay, ch, unsubscribe
+}
+
+// writeStreamEvent writes an event in text/event-stream format
+func writeStreamEvent(w *bufio.Writer, event streamEvent) error {
+	if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// streamHandler serves monitor status and alert events as Server-Sent Events
+func (s *Server) streamHandler(c *fiber.Ctx) error {
+	lastEventID := c.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("lastEventId")
+	}
+
+	var lastID uint64
+	if lastEventID != "" {
+		parsed, err := strconv.ParseUint(lastEventID, 10, 64)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error":   true,
+				"message": "Invalid Last-Event-ID",
+			})
+		}
+		lastID = parsed
+	}
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// Tenant tokens only see events for their own groups
+	visible := func(streamEvent) bool { return true }
+	if tenant := requestTenant(c); tenant != nil {
+		tenantName := tenant.Name
+		visible = func(event streamEvent) bool { return s.groupTenant(event.Group) == tenantName }
+	}
+
+	replay, events, unsubscribe := s.events.subscribe(lastID)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer unsubscribe()
+
+		// Tell clients how quickly to reconnect when the stream ends
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", time.Second.Milliseconds()); err != nil {
+			return
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		for _, event := range replay {
+			if !visible(event) {
+				continue
+			}
+			if err := writeStreamEvent(w, event); err != nil {
+				return
+			}
+		}
+
+		heartbeat := time.NewTicker(streamHeartbeatInterval)
+		defer heartbeat.Stop()
+		deadline := time.NewTimer(streamMaxDuration)
+		defer deadline.Stop()
+
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if !visible(event) {
+					continue
+				}
+				if err := writeStreamEvent(w, event); err != nil {
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": heartbeat\n\n"); err != nil {
+					return
+				}
+				if err := w.Flush(); err != nil {
+					return
+				}
+			case <-deadline.C:
+				return
+			case <-s.events.done:
+				return
+			}
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestEventBrokerEmitsAlertsOnTransitions(t *testing.T) {
+	broker := newEventBroker()
+
+	statuses := []models.MonitorStatus{models.StatusUp, models.StatusUp, models.StatusDown, models.StatusUp}
+	for _, status := range statuses {
+		broker.HandleResult(&models.MonitorResult{
+			Monitor:   "api",
+			Type:      models.MonitorTypeHTTP,
+			Group:     "core",
+			Status:    status,
+			Timestamp: time.Now(),
+		})
+	}
+
+	var types []string
+	var alerts []string
+	for _, event := range broker.history {
+		types = append(types, event.Type)
+		if event.Type == "alert" {
+			alerts = append(alerts, string(event.Data))
+		}
+	}
+
+	if len(types) != 6 {
+		t.Fatalf("expected 6 events (4 status, 2 alert), got %d: %v", len(types), types)
+	}
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 alerts, got %d", len(alerts))
+	}
+	if !strings.Contains(alerts[0], `"state":"firing"`) {
+		t.Errorf("expected first alert to be firing, got %s", alerts[0])
+	}
+	if !strings.Contains(alerts[1], `"state":"resolved"`) {
+		t.Errorf("expected second alert to be resolved, got %s", alerts[1])
+	}
+}
+
+func TestEventBrokerSubscribeReplaysAfterLastID(t *testing.T) {
+	broker := newEventBroker()
+	for i := 0; i < 3; i++ {
+		broker.publish("status", "", map[string]int{"n": i})
+	}
+
+	replay, ch, unsubscribe := broker.subscribe(1)
+	defer unsubscribe()
+
+	if len(replay) != 2 || replay[0].ID != 2 || replay[1].ID != 3 {
+		t.Fatalf("expected replay of events 2 and 3, got %+v", replay)
+	}
+
+	broker.publish("status", "", map[string]int{"n": 3})
+	select {
+	case event := <-ch:
+		if event.ID != 4 {
+			t.Errorf("expected live event 4, got %d", event.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected live event to be delivered")
+	}
+}
+
+func TestEventBrokerDisconnectsSlowSubscriber(t *testing.T) {
+	broker := newEventBroker()
+	_, ch, unsubscribe := broker.subscribe(0)
+	defer unsubscribe()
+
+	// Fill the buffer, then publish one event more than it holds
+	for i := 0; i <= streamSubscriberBuffer; i++ {
+		broker.publish("status", "", map[string]int{"n": i})
+	}
+
+	var last uint64
+	for event := range ch {
+		last = event.ID
+	}
+	if last != streamSubscriberBuffer {
+		t.Fatalf("expected the buffered events up to %d before the channel closed, got %d", streamSubscriberBuffer, last)
+	}
+	if len(broker.subscribers) != 0 {
+		t.Errorf("expected the slow subscriber to be removed, got %d subscribers", len(broker.subscribers))
+	}
+
+	// The event that did not fit is replayed on reconnect
+	replay, _, resubscribe := broker.subscribe(last)
+	defer resubscribe()
+	if len(replay) != 1 || replay[0].ID != streamSubscriberBuffer+1 {
+		t.Errorf("expected replay of event %d, got %+v", streamSubscriberBuffer+1, replay)
+	}
+}
+
+func TestStreamHandlerResumesFromLastEventID(t *testing.T) {
+	original := streamMaxDuration
+	streamMaxDuration = 100 * time.Millisecond
+	defer func() { streamMaxDuration = original }()
+
+	server := createTestServer(t)
+
+	for _, status := range []models.MonitorStatus{models.StatusUp, models.StatusDown} {
+		server.events.HandleResult(&models.MonitorResult{
+			Monitor:   "api",
+			Type:      models.MonitorTypeHTTP,
+			Group:     "core",
+			Status:    status,
+			Timestamp: time.Now(),
+		})
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream content type, got %q", ct)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	stream := string(body)
+
+	if strings.Contains(stream, "id: 1\n") {
+		t.Errorf("expected event 1 to be skipped, got %s", stream)
+	}
+	if !strings.Contains(stream, "id: 2\nevent: status\n") {
+		t.Errorf("expected status event 2 to be replayed, got %s", stream)
+	}
+	if !strings.Contains(stream, "id: 3\nevent: alert\n") {
+		t.Errorf("expected alert event 3 to be replayed, got %s", stream)
+	}
+}
+
+func TestStreamHandlerRejectsInvalidLastEventID(t *testing.T) {
+	server := createTestServer(t)
+
+	req := httptest.NewRequest("GET", "/api/v1/stream?lastEventId=abc", nil)
+	resp, err := server.app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 400 {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestEventBrokerEmitsContentChanged(t *testing.T) {
+	broker := newEventBroker()
+
+	for _, changed := range []bool{false, true} {
+		broker.HandleResult(&models.MonitorResult{
+			Monitor:   "pricing",
+			Type:      models.MonitorTypeHTTP,
+			Group:     "web",
+			Status:    models.StatusUp,
+			Timestamp: time.Now(),
+			HTTPResult: &models.HTTPResult{
+				BodyHash:         "new",
+				PreviousBodyHash: "old",
+				ContentChanged:   changed,
+			},
+		})
+	}
+
+	var changes []string
+	for _, event := range broker.history {
+		if event.Type == "content_changed" {
+			changes = append(changes, string(event.Data))
+		}
+	}
+	if len(changes) != 1 {
+		t.Fatalf("expected 1 content_changed event, got %d", len(changes))
+	}
+	if !strings.Contains(changes[0], `"previous_hash":"old"`) {
+		t.Errorf("expected previous hash in event, got %s", changes[0])
+	}
+}
@@ -0,0 +1,382 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/i18n"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	// tokenCookie carries a token given as ?token= on a page so the
+	// dashboard's own API requests are authorized
+	tokenCookie = "hallmonitor_token"
+
+	// scopeLocal is the fiber.Ctx local holding the request's *accessScope
+	scopeLocal = "accessScope"
+)
+
+// accessScope is what a request's token or session grants: everything for
+// admin tokens and users without a tenant, or the groups of one tenant
+type accessScope struct {
+	tenant *config.TenantConfig // Nil for full access
+	user   *models.User         // Set for signed-in users
+}
+
+// authRequired reports whether API and dashboard requests need a token or a
+// signed-in user
+func (s *Server) authRequired() bool {
+	cfg := s.currentConfig()
+	return len(cfg.Tenants) > 0 || len(cfg.Server.AdminTokens) > 0 || s.accounts != nil
+}
+
+// resolveToken returns the scope a token grants, or nil if it grants none
+func (s *Server) resolveToken(token string) *accessScope {
+	cfg := s.currentConfig()
+	if token == "" {
+		return nil
+	}
+
+	// Compare every token in constant time so timing reveals nothing about them
+	var scope *accessScope
+	for _, admin := range cfg.Server.AdminTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(admin)) == 1 {
+			scope = &accessScope{}
+		}
+	}
+	for i := range cfg.Tenants {
+		for _, tenantToken := range cfg.Tenants[i].Tokens {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(tenantToken)) == 1 {
+				scope = &accessScope{tenant: &cfg.Tenants[i]}
+			}
+		}
+	}
+	return scope
+}
+
+// requestToken returns the token from the Authorization header, the token
+// query parameter (for browsers and EventSource), or the token cookie
+func requestToken(c *fiber.Ctx) string {
+	if auth := c.Get(fiber.HeaderAuthorization); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+	}
+	if token := c.Query("token"); token != "" {
+		return token
+	}
+	return c.Cookies(tokenCookie)
+}
+
+// resolveSession returns the scope of the request's signed-in user, or nil if
+// there is none
+func (s *Server) resolveSession(c *fiber.Ctx) *accessScope {
+	if s.accounts == nil {
+		return nil
+	}
+
+	user, err := s.accounts.Session(c.Cookies(sessionCookie))
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentAPI).
+			WithError(err).
+			Warn("Failed to look up session")
+		return nil
+	}
+	if user == nil {
+		return nil
+	}
+
+	scope := &accessScope{user: user}
+	if user.Tenant != "" {
+		// Users of a tenant that was removed from the config have no access
+		if scope.tenant = s.currentConfig().GetTenant(user.Tenant); scope.tenant == nil {
+			return nil
+		}
+	}
+	return scope
+}
+
+// authMiddleware rejects requests without a valid token or session when
+// either is configured and records the request's scope for handlers
+func (s *Server) authMiddleware(c *fiber.Ctx) error {
+	if !s.authRequired() {
+		return c.Next()
+	}
+
+	scope := s.resolveToken(requestToken(c))
+	if scope == nil {
+		scope = s.resolveSession(c)
+	}
+	if scope == nil {
+		// Send people opening a dashboard page to the sign-in page
+		if s.accounts != nil && c.Method() == fiber.MethodGet && !strings.HasPrefix(c.Path(), "/api/") {
+			return c.Redirect("/login?next=" + url.QueryEscape(c.OriginalURL()))
+		}
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="hallmonitor"`)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error":   true,
+			"message": "Missing or invalid API token",
+		})
+	}
+
+	// Remember a token opened in the browser for the page's API requests
+	if token := c.Query("token"); token != "" {
+		c.Cookie(&fiber.Cookie{
+			Name:     tokenCookie,
+			Value:    token,
+			Path:     "/",
+			Expires:  time.Now().Add(30 * 24 * time.Hour),
+			HTTPOnly: true,
+			Secure:   c.Protocol() == "https",
+			SameSite: fiber.CookieSameSiteStrictMode,
+		})
+	}
+
+	c.Locals(scopeLocal, scope)
+	return c.Next()
+}
+
+// requestTenant returns the tenant the request is limited to, or nil when it
+// may see everything
+func requestTenant(c *fiber.Ctx) *config.TenantConfig {
+	if scope, ok := c.Locals(scopeLocal).(*accessScope); ok {
+		return scope.tenant
+	}
+	return nil
+}
+
+// requestUser returns the signed-in user making the request, or nil for
+// token and anonymous requests
+func requestUser(c *fiber.Ctx) *models.User {
+	if scope, ok := c.Locals(scopeLocal).(*accessScope); ok {
+		return scope.user
+	}
+	return nil
+}
+
+// requireAdmin rejects tenant-scoped requests and clients outside the admin
+// allowlist, for endpoints that manage the whole instance
+func (s *Server) requireAdmin(c *fiber.Ctx) error {
+	if requestTenant(c) != nil {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":   true,
+			"message": "This endpoint requires an admin token",
+		})
+	}
+	return s.requireAdminNetwork(c)
+}
+
+// requireMonitorAccess hides monitors outside the request's tenant as if they
+// did not exist
+func (s *Server) requireMonitorAccess(c *fiber.Ctx) error {
+	if monitor := s.monitorManager.GetMonitorByName(c.Params("name")); monitor != nil && !s.canAccessGroup(c, monitor.GetGroup()) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "Monitor not found",
+		})
+	}
+	return c.Next()
+}
+
+// requireGroupAccess hides groups outside the request's tenant as if they did
+// not exist
+func (s *Server) requireGroupAccess(c *fiber.Ctx) error {
+	if !s.canAccessGroup(c, c.Params("name")) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "Group not found",
+		})
+	}
+	return c.Next()
+}
+
+// groupTenant returns the tenant a group belongs to, or "" for none
+func (s *Server) groupTenant(group string) string {
+	for _, g := range s.currentConfig().Monitoring.Groups {
+		if g.Name == group {
+			return g.Tenant
+		}
+	}
+	return ""
+}
+
+// canAccessGroup reports whether the request may see a group
+func (s *Server) canAccessGroup(c *fiber.Ctx, group string) bool {
+	tenant := requestTenant(c)
+	return tenant == nil || s.groupTenant(group) == tenant.Name
+}
+
+// accessibleGroups returns the groups with monitors the request may see
+func (s *Server) accessibleGroups(c *fiber.Ctx) []string {
+	groups := s.monitorManager.GetGroups()
+	if requestTenant(c) == nil {
+		return groups
+	}
+
+	visible := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if s.canAccessGroup(c, group) {
+			visible = append(visible, group)
+		}
+	}
+	return visible
+}
+
+// accessibleMonitors returns the monitors the request may see
+func (s *Server) accessibleMonitors(c *fiber.Ctx) []monitors.Monitor {
+	all := s.monitorManager.GetMonitors()
+	if requestTenant(c) == nil {
+		return all
+	}
+
+	groups := make(map[string]bool)
+	for _, group := range s.accessibleGroups(c) {
+		groups[group] = true
+	}
+	visible := make([]monitors.Monitor, 0, len(all))
+	for _, monitor := range all {
+		if groups[monitor.GetGroup()] {
+			visible = append(visible, monitor)
+		}
+	}
+	return visible
+}
+
+// canAccessMonitor reports whether the request may see the named monitor;
+// unknown monitors are reported accessible so handlers can answer 404
+func (s *Server) canAccessMonitor(c *fiber.Ctx, name string) bool {
+	monitor := s.monitorManager.GetMonitorByName(name)
+	return monitor == nil || s.canAccessGroup(c, monitor.GetGroup())
+}
+
+// requestCacheScope keys cached responses that list groups by tenant
+func requestCacheScope(c *fiber.Ctx) string {
+	if tenant := requestTenant(c); tenant != nil {
+		return tenant.Name
+	}
+	return ""
+}
+
+// dashboardData returns the template data for a dashboard view, titled for the
+// viewing tenant
+func (s *Server) dashboardData(c *fiber.Ctx, ambient bool, view string) DashboardData {
+	data := DashboardData{
+		Catalog:     s.requestCatalog(c),
+		IsAmbient:   ambient,
+		CurrentView: view,
+		Title:       "Hall Monitor",
+	}
+	if user := requestUser(c); user != nil {
+		data.Username = user.Username
+	}
+	if tenant := requestTenant(c); tenant != nil {
+		data.Tenant = tenant.Name
+		data.Title = tenantTitle(tenant)
+	}
+	return data
+}
+
+// tenantTitle returns a tenant's display title
+func tenantTitle(tenant *config.TenantConfig) string {
+	if tenant.Title != "" {
+		return tenant.Title
+	}
+	return tenant.Name
+}
+
+// StatusPageData holds data passed to the tenant status page template
+type StatusPageData struct {
+	*i18n.Catalog
+	Title     string
+	Status    string // "up" when every monitor is up, "down" when any is down
+	Groups    []StatusPageGroup
+	UpdatedAt time.Time
+}
+
+// StatusPageGroup is a group listed on a status page
+type StatusPageGroup struct {
+	Name     string
+	Monitors []StatusPageMonitor
+}
+
+// StatusPageMonitor is a monitor's current status on a status page
+type StatusPageMonitor struct {
+	Name      string
+	Status    string
+	LastCheck time.Time
+}
+
+// statusPageHandler serves a tenant's status page listing the current status
+// of its enabled monitors. Pages of tenants without publicStatusPage need a
+// token or user of the tenant, or full access.
+func (s *Server) statusPageHandler(c *fiber.Ctx) error {
+	tenant := s.currentConfig().GetTenant(c.Params("tenant"))
+	if tenant == nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error":   true,
+			"message": "Status page not found",
+		})
+	}
+
+	if !tenant.PublicStatusPage {
+		scope := s.resolveToken(requestToken(c))
+		if scope == nil {
+			scope = s.resolveSession(c)
+		}
+		if scope == nil || scope.tenant != nil && scope.tenant.Name != tenant.Name {
+			c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="hallmonitor"`)
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":   true,
+				"message": "Missing or invalid API token",
+			})
+		}
+	}
+
+	data := StatusPageData{
+		Catalog:   s.requestCatalog(c),
+		Title:     tenantTitle(tenant),
+		Status:    string(models.StatusUp),
+		UpdatedAt: time.Now(),
+	}
+	for _, group := range s.currentConfig().Monitoring.Groups {
+		if group.Tenant != tenant.Name {
+			continue
+		}
+
+		pageGroup := StatusPageGroup{Name: group.Name}
+		for _, monitor := range s.monitorManager.GetMonitorsByGroup(group.Name) {
+			if !monitor.IsEnabled() {
+				continue
+			}
+			pageMonitor := StatusPageMonitor{Name: monitor.GetName(), Status: string(models.StatusUnknown)}
+			if result := s.scheduler.GetLatestResult(monitor.GetName()); result != nil {
+				pageMonitor.Status = string(result.Status)
+				pageMonitor.LastCheck = result.Timestamp
+			}
+			switch {
+			case pageMonitor.Status == string(models.StatusDown):
+				data.Status = string(models.StatusDown)
+			case pageMonitor.Status != string(models.StatusUp) && data.Status == string(models.StatusUp):
+				data.Status = string(models.StatusUnknown)
+			}
+			pageGroup.Monitors = append(pageGroup.Monitors, pageMonitor)
+		}
+		data.Groups = append(data.Groups, pageGroup)
+	}
+
+	var buf bytes.Buffer
+	if err := statusTpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Type("html", "utf-8")
+	return c.Send(buf.Bytes())
+}
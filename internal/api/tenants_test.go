@@ -0,0 +1,215 @@
+package api
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// tenantTestConfig puts the core group in tenant acme and the edge group in
+// tenant globex
+const tenantTestConfig = `
+server:
+  port: "7878"
+  host: "0.0.0.0"
+  adminTokens: ["admin-token"]
+
+tenants:
+  - name: "acme"
+    title: "Acme Corp"
+    tokens: ["acme-token"]
+    publicStatusPage: true
+  - name: "globex"
+    tokens: ["globex-token"]
+
+monitoring:
+  groups:
+    - name: "core"
+      tenant: "acme"
+      monitors:
+        - type: "http"
+          name: "api"
+          url: "https://api.example.com"
+    - name: "edge"
+      tenant: "globex"
+      monitors:
+        - type: "http"
+          name: "cdn"
+          url: "https://cdn.example.com"
+`
+
+func bearer(token string) map[string]string {
+	return map[string]string{"Authorization": "Bearer " + token}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	server := createYAMLTestServer(t, tenantTestConfig, nil)
+	defer server.app.Shutdown()
+
+	tests := []struct {
+		name       string
+		headers    map[string]string
+		path       string
+		wantStatus int
+	}{
+		{name: "missing token", path: "/api/v1/monitors", wantStatus: fiber.StatusUnauthorized},
+		{name: "invalid token", headers: bearer("nope"), path: "/api/v1/monitors", wantStatus: fiber.StatusUnauthorized},
+		{name: "admin token", headers: bearer("admin-token"), path: "/api/v1/monitors", wantStatus: fiber.StatusOK},
+		{name: "tenant token", headers: bearer("acme-token"), path: "/api/v1/monitors", wantStatus: fiber.StatusOK},
+		{name: "query token", path: "/api/v1/monitors?token=acme-token", wantStatus: fiber.StatusOK},
+		{name: "health stays open", path: "/health", wantStatus: fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := doJSON(t, server, "GET", tt.path, nil, tt.headers)
+			if status != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, status)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareDisabledWithoutTokens(t *testing.T) {
+	server := createYAMLTestServer(t, annotationTestConfig, nil)
+	defer server.app.Shutdown()
+
+	if status, _ := doJSON(t, server, "GET", "/api/v1/monitors", nil, nil); status != fiber.StatusOK {
+		t.Errorf("expected open access without configured tokens, got %d", status)
+	}
+}
+
+func TestTenantIsolation(t *testing.T) {
+	server := createYAMLTestServer(t, tenantTestConfig, nil)
+	defer server.app.Shutdown()
+
+	status, payload := doJSON(t, server, "GET", "/api/v1/monitors", nil, bearer("acme-token"))
+	if status != fiber.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	monitors := payload["monitors"].([]interface{})
+	if len(monitors) != 1 || monitors[0].(map[string]interface{})["name"] != "api" {
+		t.Errorf("expected acme to see only api, got %v", monitors)
+	}
+
+	_, payload = doJSON(t, server, "GET", "/api/v1/groups", nil, bearer("globex-token"))
+	groups := payload["groups"].([]interface{})
+	if len(groups) != 1 || groups[0].(map[string]interface{})["name"] != "edge" {
+		t.Errorf("expected globex to see only edge, got %v", groups)
+	}
+
+	// Cached group lists must not leak between tenants
+	_, payload = doJSON(t, server, "GET", "/api/v1/groups", nil, bearer("admin-token"))
+	if total := payload["total"].(float64); total != 2 {
+		t.Errorf("expected admin to see both groups, got %v", total)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "own monitor", method: "GET", path: "/api/v1/monitors/api", wantStatus: fiber.StatusOK},
+		{name: "other tenant's monitor", method: "GET", path: "/api/v1/monitors/cdn", wantStatus: fiber.StatusNotFound},
+		{name: "other tenant's history", method: "GET", path: "/api/v1/monitors/cdn/history", wantStatus: fiber.StatusNotFound},
+		{name: "other tenant's group", method: "GET", path: "/api/v1/groups/edge", wantStatus: fiber.StatusNotFound},
+		{name: "fault on own monitor", method: "POST", path: "/api/v1/monitors/api/fault", wantStatus: fiber.StatusForbidden},
+		{name: "clear fault on own monitor", method: "DELETE", path: "/api/v1/monitors/api/fault", wantStatus: fiber.StatusForbidden},
+		{name: "fault on other tenant's monitor", method: "POST", path: "/api/v1/monitors/cdn/fault", wantStatus: fiber.StatusForbidden},
+		{name: "defer own monitor", method: "POST", path: "/api/v1/scheduler/queue/api/defer", wantStatus: fiber.StatusForbidden},
+		{name: "config", method: "GET", path: "/api/v1/config", wantStatus: fiber.StatusForbidden},
+		{name: "reload", method: "POST", path: "/api/v1/reload", wantStatus: fiber.StatusForbidden},
+		{name: "delete monitor", method: "DELETE", path: "/api/v1/monitors/api", wantStatus: fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, _ := doJSON(t, server, tt.method, tt.path, nil, bearer("acme-token"))
+			if status != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, status)
+			}
+		})
+	}
+}
+
+func TestTenantAnnotations(t *testing.T) {
+	server := createYAMLTestServer(t, tenantTestConfig, nil)
+	defer server.app.Shutdown()
+
+	status, _ := doJSON(t, server, "POST", "/api/v1/annotations", map[string]interface{}{"text": "deploy", "monitor": "cdn"}, bearer("acme-token"))
+	if status != fiber.StatusNotFound {
+		t.Errorf("expected 404 annotating another tenant's monitor, got %d", status)
+	}
+	status, _ = doJSON(t, server, "POST", "/api/v1/annotations", map[string]interface{}{"text": "deploy"}, bearer("acme-token"))
+	if status != fiber.StatusForbidden {
+		t.Errorf("expected 403 for a tenant's global annotation, got %d", status)
+	}
+
+	for _, monitor := range []string{"api", "cdn"} {
+		status, _ = doJSON(t, server, "POST", "/api/v1/annotations", map[string]interface{}{"text": "deploy " + monitor, "monitor": monitor}, bearer("admin-token"))
+		if status != fiber.StatusCreated {
+			t.Fatalf("expected 201, got %d", status)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/api/v1/annotations", nil)
+	req.Header.Set("Authorization", "Bearer acme-token")
+	resp, err := server.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "deploy api") || strings.Contains(string(body), "deploy cdn") {
+		t.Errorf("expected only acme's annotation, got %s", body)
+	}
+}
+
+func TestStatusPageHandler(t *testing.T) {
+	server := createYAMLTestServer(t, tenantTestConfig, nil)
+	defer server.app.Shutdown()
+
+	tests := []struct {
+		name       string
+		path       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "public page", path: "/status/acme", wantStatus: fiber.StatusOK, wantBody: "Acme Corp"},
+		{name: "private page without token", path: "/status/globex", wantStatus: fiber.StatusUnauthorized},
+		{name: "private page with another tenant's token", path: "/status/globex", token: "acme-token", wantStatus: fiber.StatusUnauthorized},
+		{name: "private page with tenant token", path: "/status/globex", token: "globex-token", wantStatus: fiber.StatusOK, wantBody: "cdn"},
+		{name: "private page with admin token", path: "/status/globex", token: "admin-token", wantStatus: fiber.StatusOK, wantBody: "edge"},
+		{name: "unknown tenant", path: "/status/initech", wantStatus: fiber.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			resp, err := server.app.Test(req, -1)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, resp.StatusCode)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if tt.wantBody != "" && !strings.Contains(string(body), tt.wantBody) {
+				t.Errorf("expected body to contain %q, got %s", tt.wantBody, body)
+			}
+			if tt.path == "/status/acme" && strings.Contains(string(body), "cdn") {
+				t.Errorf("expected acme's page to omit globex's monitors")
+			}
+		})
+	}
+}
@@ -3,20 +3,47 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 
+	"github.com/1broseidon/hallmonitor/internal/i18n"
+	"github.com/1broseidon/hallmonitor/internal/plugin"
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
+// maxRetries caps per-check retries so a failing monitor cannot stall a worker
+const maxRetries = 10
+
+// maxSampledInterval is the interval below which monitors may sample the
+// successful results they persist
+const maxSampledInterval = 10 * time.Second
+
+// maxMonitorNameLength bounds monitor names, which are embedded in storage
+// keys, metric labels, and URLs
+const maxMonitorNameLength = 255
+
+// tenantNamePattern restricts tenant names, which appear in URLs
+var tenantNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// defaultDomainInterval is the check interval for domain monitors without one
+const defaultDomainInterval = 24 * time.Hour
+
 // Config represents the application configuration
 type Config struct {
 	Server     ServerConfig     `yaml:"server" mapstructure:"server"`
@@ -26,30 +53,209 @@ type Config struct {
 	Storage    StorageConfig    `yaml:"storage" mapstructure:"storage"`
 	Alerting   AlertingConfig   `yaml:"alerting" mapstructure:"alerting"`
 	Webhooks   []WebhookConfig  `yaml:"webhooks" mapstructure:"webhooks"`
+
+	ResultWebhooks []ResultWebhookConfig `yaml:"resultWebhooks,omitempty" mapstructure:"resultWebhooks"`
+	ResultHooks    []ResultHookConfig    `yaml:"resultHooks,omitempty" mapstructure:"resultHooks"`
+	GeoIP          GeoIPConfig           `yaml:"geoip,omitempty" mapstructure:"geoip"`
+	MQTT           MQTTConfig            `yaml:"mqtt,omitempty" mapstructure:"mqtt"`
+	SNMP           SNMPConfig            `yaml:"snmp,omitempty" mapstructure:"snmp"`
+	Graphite       GraphiteConfig        `yaml:"graphite,omitempty" mapstructure:"graphite"`
+	Zabbix         ZabbixConfig          `yaml:"zabbix,omitempty" mapstructure:"zabbix"`
+	Nagios         NagiosConfig          `yaml:"nagios,omitempty" mapstructure:"nagios"`
+	Reports        ReportsConfig         `yaml:"reports,omitempty" mapstructure:"reports"`
+
+	// Tenants are isolated namespaces of monitor groups, each reached with its own tokens
+	Tenants []TenantConfig `yaml:"tenants,omitempty" mapstructure:"tenants"`
+
+	// Templates are monitor definitions expanded by group expand entries
+	Templates []MonitorTemplate `yaml:"templates,omitempty" mapstructure:"templates"`
+
+	// Profiles are named environments merged over the base configuration
+	Profiles map[string]interface{} `yaml:"profiles,omitempty" mapstructure:"profiles"`
+	// Profile is the active profile, if any; it is chosen at load time and never written
+	Profile string `yaml:"-" mapstructure:"-"`
+
+	// Secrets are encrypted values referenced elsewhere as ${secret:name}
+	Secrets map[string]string `yaml:"secrets,omitempty" mapstructure:"secrets" json:"-"`
+
+	// generated maps monitors produced by ExpandTemplates to their template
+	generated map[string]string
+	// secrets records resolved secret references so they are written back
+	secrets *secretState
+	// declared and declaredGroups record monitors and groups as written in
+	// the file, so defaults applied at load are not written back
+	declared       map[string]declaredMonitor
+	declaredGroups map[string]declaredGroup
 }
 
 // ServerConfig contains server configuration
 type ServerConfig struct {
-	Port            string   `yaml:"port" mapstructure:"port" json:"port"`
-	Host            string   `yaml:"host" mapstructure:"host" json:"host"`
-	CORSOrigins     []string `yaml:"corsOrigins" mapstructure:"corsOrigins" json:"corsOrigins"`
-	EnableDashboard bool     `yaml:"enableDashboard" mapstructure:"enableDashboard" json:"enableDashboard"`
+	Port            string          `yaml:"port" mapstructure:"port" json:"port"`
+	Host            string          `yaml:"host" mapstructure:"host" json:"host"`
+	CORSOrigins     []string        `yaml:"corsOrigins" mapstructure:"corsOrigins" json:"corsOrigins"` // Deprecated: use cors.allowOrigins
+	EnableDashboard bool            `yaml:"enableDashboard" mapstructure:"enableDashboard" json:"enableDashboard"`
+	CacheTTL        models.Duration `yaml:"cacheTTL,omitempty" mapstructure:"cacheTTL" json:"cacheTTL"` // How long uptime and group summaries are cached (default 30s, 0 disables)
+	Compression     bool            `yaml:"compression" mapstructure:"compression" json:"compression"`  // Compress responses with brotli or gzip (default true)
+
+	// Socket is a unix socket path to listen on instead of host:port, for
+	// deployments behind a local reverse proxy. A socket passed by systemd
+	// socket activation takes precedence over both.
+	Socket     string `yaml:"socket,omitempty" mapstructure:"socket" json:"socket,omitempty"`
+	SocketMode string `yaml:"socketMode,omitempty" mapstructure:"socketMode" json:"socketMode,omitempty"` // Octal permissions of the socket file (default "0660")
+
+	// ReusePort sets SO_REUSEPORT on the TCP listener so a new process can
+	// bind the same port while the old one drains
+	ReusePort bool `yaml:"reusePort,omitempty" mapstructure:"reusePort" json:"reusePort,omitempty"`
+
+	// ShutdownGracePeriod is how long in-flight requests may run on shutdown
+	// before their connections are closed (default 15s, 0 waits indefinitely)
+	ShutdownGracePeriod models.Duration `yaml:"shutdownGracePeriod,omitempty" mapstructure:"shutdownGracePeriod" json:"shutdownGracePeriod,omitempty"`
+
+	// AdminTokens grant full API and dashboard access. When admin tokens or
+	// tenants are configured, every API and dashboard request needs a token.
+	AdminTokens []string `yaml:"adminTokens,omitempty" mapstructure:"adminTokens" json:"-"`
+
+	// Accounts enables local user accounts that sign in to the dashboard
+	Accounts AccountsConfig `yaml:"accounts,omitempty" mapstructure:"accounts" json:"-"`
+
+	// AdminAllowlist limits admin endpoints (configuration, monitor and group
+	// changes, users) to clients in these IPs or CIDRs, on top of tokens
+	AdminAllowlist []string `yaml:"adminAllowlist,omitempty" mapstructure:"adminAllowlist" json:"-"`
+
+	// TrustedProxies are reverse proxies (IPs or CIDRs) whose X-Forwarded-For
+	// and X-Real-IP headers identify the client. Other peers' are ignored.
+	TrustedProxies []string `yaml:"trustedProxies,omitempty" mapstructure:"trustedProxies" json:"-"`
+
+	// CORS is the policy for browsers calling the API from other sites
+	CORS CORSConfig `yaml:"cors,omitempty" mapstructure:"cors" json:"cors"`
+
+	// Locale is the language of dashboard pages and notifications (en, de, fr,
+	// or es). Unset, pages follow the browser's Accept-Language and
+	// notifications are in English.
+	Locale string `yaml:"locale,omitempty" mapstructure:"locale" json:"locale,omitempty"`
+}
+
+// CORSConfig is the cross-origin resource sharing policy. Origins are
+// "scheme://host[:port]", may use a "*." subdomain wildcard after the scheme,
+// or are "*" for any site.
+type CORSConfig struct {
+	AllowOrigins     []string        `yaml:"allowOrigins,omitempty" mapstructure:"allowOrigins" json:"allowOrigins,omitempty"`
+	AllowMethods     []string        `yaml:"allowMethods,omitempty" mapstructure:"allowMethods" json:"allowMethods,omitempty"`
+	AllowHeaders     []string        `yaml:"allowHeaders,omitempty" mapstructure:"allowHeaders" json:"allowHeaders,omitempty"`
+	ExposeHeaders    []string        `yaml:"exposeHeaders,omitempty" mapstructure:"exposeHeaders" json:"exposeHeaders,omitempty"`
+	AllowCredentials bool            `yaml:"allowCredentials,omitempty" mapstructure:"allowCredentials" json:"allowCredentials,omitempty"` // Send cookies and auth headers cross-origin; needs explicit origins
+	MaxAge           models.Duration `yaml:"maxAge,omitempty" mapstructure:"maxAge" json:"maxAge,omitempty"`                               // How long browsers cache preflight results
+
+	// Strict rejects requests whose Origin is neither allowed nor this server,
+	// instead of only leaving out the CORS headers
+	Strict bool `yaml:"strict,omitempty" mapstructure:"strict" json:"strict,omitempty"`
+}
+
+// CORSPolicy returns the CORS policy with the deprecated corsOrigins applied
+// when cors.allowOrigins is not set
+func (s ServerConfig) CORSPolicy() CORSConfig {
+	policy := s.CORS
+	if len(policy.AllowOrigins) == 0 {
+		policy.AllowOrigins = s.CORSOrigins
+	}
+	if len(policy.AllowMethods) == 0 {
+		policy.AllowMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	}
+	if len(policy.AllowHeaders) == 0 {
+		policy.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	}
+	return policy
+}
+
+// AccountsConfig configures local user accounts. Accounts and their sessions
+// are kept in the storage backend.
+type AccountsConfig struct {
+	Enabled    bool            `yaml:"enabled" mapstructure:"enabled"`
+	SessionTTL models.Duration `yaml:"sessionTTL,omitempty" mapstructure:"sessionTTL"` // How long a sign-in lasts (default 24h)
+
+	// InitialAdmin is created with full access when no accounts exist yet
+	InitialAdmin InitialAdminConfig `yaml:"initialAdmin,omitempty" mapstructure:"initialAdmin"`
+}
+
+// InitialAdminConfig is the first account, created on an empty store
+type InitialAdminConfig struct {
+	Username string `yaml:"username" mapstructure:"username"`
+	Password string `yaml:"password" mapstructure:"password"`
 }
 
+// MinPasswordLength is the shortest password accepted for local accounts
+const MinPasswordLength = 8
+
 // MetricsConfig contains Prometheus metrics configuration
 type MetricsConfig struct {
 	Enabled               bool   `yaml:"enabled" mapstructure:"enabled"`
 	Path                  string `yaml:"path" mapstructure:"path"`
 	IncludeProcessMetrics bool   `yaml:"includeProcessMetrics" mapstructure:"includeProcessMetrics"`
 	IncludeGoMetrics      bool   `yaml:"includeGoMetrics" mapstructure:"includeGoMetrics"`
+
+	// Listen serves metrics on their own host:port instead of the dashboard
+	// listener, so scraping can stay on an internal interface
+	Listen string `yaml:"listen,omitempty" mapstructure:"listen"`
+
+	// BasicAuth requires scrapers to send these credentials
+	BasicAuth MetricsBasicAuthConfig `yaml:"basicAuth,omitempty" mapstructure:"basicAuth"`
+
+	// TLS serves the metrics listener over HTTPS
+	TLS MetricsTLSConfig `yaml:"tls,omitempty" mapstructure:"tls"`
+
+	// Push periodically sends metrics to a Prometheus Pushgateway or another
+	// Hall Monitor, for probes in networks that cannot be scraped
+	Push MetricsPushConfig `yaml:"push,omitempty" mapstructure:"push"`
+
+	// AcceptPush lets other Hall Monitor instances push their metrics here,
+	// Pushgateway style, to be served alongside this instance's own
+	AcceptPush bool `yaml:"acceptPush,omitempty" mapstructure:"acceptPush"`
+}
+
+// MetricsPushConfig configures pushing metrics to a Pushgateway
+type MetricsPushConfig struct {
+	Enabled  bool          `yaml:"enabled" mapstructure:"enabled"`
+	URL      string        `yaml:"url" mapstructure:"url"`                     // Pushgateway or Hall Monitor base URL
+	Job      string        `yaml:"job,omitempty" mapstructure:"job"`           // Defaults to hallmonitor
+	Instance string        `yaml:"instance,omitempty" mapstructure:"instance"` // Defaults to the hostname
+	Interval time.Duration `yaml:"interval,omitempty" mapstructure:"interval"` // Defaults to 30s
+	Username string        `yaml:"username,omitempty" mapstructure:"username"` // Basic auth, if the receiver requires it
+	Password string        `yaml:"password,omitempty" mapstructure:"password"`
+}
+
+// MetricsBasicAuthConfig is the username and password scrapers authenticate with
+type MetricsBasicAuthConfig struct {
+	Username string `yaml:"username" mapstructure:"username"`
+	Password string `yaml:"password" mapstructure:"password"`
+}
+
+// MetricsTLSConfig configures HTTPS for the metrics listener. Setting
+// clientCAFile enables mutual TLS: scrapers must present a certificate
+// signed by one of its CAs.
+type MetricsTLSConfig struct {
+	CertFile     string `yaml:"certFile" mapstructure:"certFile"`
+	KeyFile      string `yaml:"keyFile" mapstructure:"keyFile"`
+	ClientCAFile string `yaml:"clientCAFile,omitempty" mapstructure:"clientCAFile"`
 }
 
 // LoggingConfig contains logging configuration
 type LoggingConfig struct {
-	Level  string            `yaml:"level" mapstructure:"level"`
-	Format string            `yaml:"format" mapstructure:"format"`
-	Output string            `yaml:"output" mapstructure:"output"`
-	Fields map[string]string `yaml:"fields" mapstructure:"fields"`
+	Level           string            `yaml:"level" mapstructure:"level"`
+	Format          string            `yaml:"format" mapstructure:"format"`
+	Output          string            `yaml:"output" mapstructure:"output"`
+	Outputs         []string          `yaml:"outputs,omitempty" mapstructure:"outputs"`
+	Rotation        LogRotationConfig `yaml:"rotation,omitempty" mapstructure:"rotation"`
+	ComponentLevels map[string]string `yaml:"componentLevels,omitempty" mapstructure:"componentLevels"`
+	Fields          map[string]string `yaml:"fields" mapstructure:"fields"`
+}
+
+// LogRotationConfig contains rotation settings for file log outputs
+type LogRotationConfig struct {
+	MaxSizeMB  int           `yaml:"maxSizeMB,omitempty" mapstructure:"maxSizeMB"`
+	MaxAge     time.Duration `yaml:"maxAge,omitempty" mapstructure:"maxAge"`
+	MaxBackups int           `yaml:"maxBackups,omitempty" mapstructure:"maxBackups"`
+	Interval   time.Duration `yaml:"interval,omitempty" mapstructure:"interval"`
+	Compress   bool          `yaml:"compress,omitempty" mapstructure:"compress"`
 }
 
 // MonitoringConfig contains monitoring configuration
@@ -58,6 +264,25 @@ type MonitoringConfig struct {
 	DefaultTimeout                  models.Duration       `yaml:"defaultTimeout" mapstructure:"defaultTimeout"`
 	DefaultSSLCertExpiryWarningDays int                   `yaml:"defaultSSLCertExpiryWarningDays" mapstructure:"defaultSSLCertExpiryWarningDays"`
 	Groups                          []models.MonitorGroup `yaml:"groups" mapstructure:"groups"`
+	Egress                          models.EgressPolicy   `yaml:"egress,omitempty" mapstructure:"egress"`                         // Addresses monitors may connect to
+	Resolver                        models.ResolverConfig `yaml:"resolver,omitempty" mapstructure:"resolver"`                     // DNS used by monitors without their own
+	SourceIP                        string                `yaml:"sourceIP,omitempty" mapstructure:"sourceIP"`                     // Local address checks originate from
+	SourceInterface                 string                `yaml:"sourceInterface,omitempty" mapstructure:"sourceInterface"`       // Interface checks originate from
+	Workers                         int                   `yaml:"workers,omitempty" mapstructure:"workers"`                       // Checks run at once across all monitors (default 10)
+	Autoscale                       AutoscaleConfig       `yaml:"autoscale,omitempty" mapstructure:"autoscale"`                   // Grow the worker pool while checks back up
+	WarmUp                          models.Duration       `yaml:"warmUp,omitempty" mapstructure:"warmUp"`                         // Spread first checks evenly over this window on start and reload
+	MaxChecksPerSecond              int                   `yaml:"maxChecksPerSecond,omitempty" mapstructure:"maxChecksPerSecond"` // Checks started per second across all monitors (0 = unlimited)
+	StallThreshold                  models.Duration       `yaml:"stallThreshold,omitempty" mapstructure:"stallThreshold"`         // How late the scheduler must run to report a stall (default 15s)
+	CatchUpAfterStall               bool                  `yaml:"catchUpAfterStall,omitempty" mapstructure:"catchUpAfterStall"`   // Check every monitor right after a stall
+	PluginDir                       string                `yaml:"pluginDir,omitempty" mapstructure:"pluginDir" json:"-"`          // Directory plugins are named relative to; plugins are refused without it
+}
+
+// AutoscaleConfig lets the worker pool grow from workers up to maxWorkers
+type AutoscaleConfig struct {
+	MaxWorkers     int             `yaml:"maxWorkers,omitempty" mapstructure:"maxWorkers"`         // 0 disables autoscaling
+	QueueThreshold int             `yaml:"queueThreshold,omitempty" mapstructure:"queueThreshold"` // Queued checks that add a worker while all are busy (default 1)
+	LagThreshold   models.Duration `yaml:"lagThreshold,omitempty" mapstructure:"lagThreshold"`     // Queue wait that adds a worker (default 2s)
+	IdleTimeout    models.Duration `yaml:"idleTimeout,omitempty" mapstructure:"idleTimeout"`       // Idle time before an extra worker exits (default 1m)
 }
 
 // StorageConfig contains persistent storage configuration
@@ -66,6 +291,10 @@ type StorageConfig struct {
 	Badger   BadgerConfig   `yaml:"badger" mapstructure:"badger"`
 	Postgres PostgresConfig `yaml:"postgres" mapstructure:"postgres"`
 	InfluxDB InfluxDBConfig `yaml:"influxdb" mapstructure:"influxdb"`
+	ReadOnly bool           `yaml:"readOnly" mapstructure:"readOnly"` // Serve stored results without running checks
+
+	// Keep failures, and the results around them, longer than other results
+	FailureRetention FailureRetentionConfig `yaml:"failureRetention,omitempty" mapstructure:"failureRetention"`
 
 	// Deprecated: Use Backend and backend-specific fields instead. Kept for backward compatibility.
 	Enabled           bool   `yaml:"enabled" mapstructure:"enabled"`
@@ -74,12 +303,21 @@ type StorageConfig struct {
 	EnableAggregation bool   `yaml:"enableAggregation" mapstructure:"enableAggregation"`
 }
 
+// FailureRetentionConfig keeps down results past the backend's retention so
+// incidents can still be analysed after the rest of their history expires
+type FailureRetentionConfig struct {
+	RetentionDays int             `yaml:"retentionDays" mapstructure:"retentionDays"` // Days to keep failures; only takes effect when longer than the backend's retention
+	Context       models.Duration `yaml:"context" mapstructure:"context"`             // Results this close to a failure are kept as long
+}
+
 // BadgerConfig contains BadgerDB-specific configuration
 type BadgerConfig struct {
 	Enabled           bool   `yaml:"enabled" mapstructure:"enabled"`
 	Path              string `yaml:"path" mapstructure:"path"`
 	RetentionDays     int    `yaml:"retentionDays" mapstructure:"retentionDays"`
 	EnableAggregation bool   `yaml:"enableAggregation" mapstructure:"enableAggregation"`
+	Encoding          string `yaml:"encoding" mapstructure:"encoding"`       // "json" (default) or "msgpack"
+	Compression       string `yaml:"compression" mapstructure:"compression"` // "zstd" (default) or "none"
 }
 
 // PostgresConfig contains PostgreSQL-specific configuration
@@ -91,14 +329,16 @@ type PostgresConfig struct {
 	Password      string `yaml:"password" mapstructure:"password"`
 	SSLMode       string `yaml:"sslmode" mapstructure:"sslmode"`
 	RetentionDays int    `yaml:"retentionDays" mapstructure:"retentionDays"`
+	Timescale     string `yaml:"timescale" mapstructure:"timescale"` // "auto" (default, use TimescaleDB if installed) or "off"
 }
 
 // InfluxDBConfig contains InfluxDB-specific configuration
 type InfluxDBConfig struct {
-	URL    string `yaml:"url" mapstructure:"url"`
-	Token  string `yaml:"token" mapstructure:"token"`
-	Org    string `yaml:"org" mapstructure:"org"`
-	Bucket string `yaml:"bucket" mapstructure:"bucket"`
+	URL     string `yaml:"url" mapstructure:"url"`
+	Token   string `yaml:"token" mapstructure:"token"`
+	Org     string `yaml:"org" mapstructure:"org"`
+	Bucket  string `yaml:"bucket" mapstructure:"bucket"`   // Database name on InfluxDB 3
+	Version string `yaml:"version" mapstructure:"version"` // "auto" (default), "2" (Flux), or "3" (SQL)
 }
 
 // AlertingConfig contains alerting configuration
@@ -117,10 +357,203 @@ type AlertRule struct {
 	Annotations map[string]string `yaml:"annotations" mapstructure:"annotations"`
 }
 
-// WebhookConfig contains webhook configuration
+// WebhookConfig configures a notification webhook. Transitions in the same
+// group within GroupWindow are sent as one digest message.
 type WebhookConfig struct {
-	URL    string   `yaml:"url" mapstructure:"url"`
-	Events []string `yaml:"events" mapstructure:"events"`
+	URL         string        `yaml:"url,omitempty" mapstructure:"url"`
+	Events      []string      `yaml:"events" mapstructure:"events"`
+	GroupWindow time.Duration `yaml:"groupWindow,omitempty" mapstructure:"groupWindow"`
+	Timeout     time.Duration `yaml:"timeout,omitempty" mapstructure:"timeout"`
+
+	// Plugin is an executable that delivers notifications instead of posting to URL,
+	// run with PluginArgs and PluginEnv and passed Options with each notification
+	Plugin     string            `yaml:"plugin,omitempty" mapstructure:"plugin"`
+	PluginArgs []string          `yaml:"pluginArgs,omitempty" mapstructure:"pluginArgs"`
+	PluginEnv  map[string]string `yaml:"pluginEnv,omitempty" mapstructure:"pluginEnv"`
+	Options    map[string]string `yaml:"options,omitempty" mapstructure:"options"`
+
+	// Locale is the language of notification text (default server.locale)
+	Locale string `yaml:"locale,omitempty" mapstructure:"locale"`
+}
+
+// ResultWebhookConfig configures a webhook that receives every completed check
+// result as batched NDJSON
+type ResultWebhookConfig struct {
+	URL           string            `yaml:"url" mapstructure:"url"`
+	OnlyFailures  bool              `yaml:"onlyFailures,omitempty" mapstructure:"onlyFailures"`
+	BatchSize     int               `yaml:"batchSize,omitempty" mapstructure:"batchSize"`
+	FlushInterval time.Duration     `yaml:"flushInterval,omitempty" mapstructure:"flushInterval"`
+	Timeout       time.Duration     `yaml:"timeout,omitempty" mapstructure:"timeout"`
+	Headers       map[string]string `yaml:"headers,omitempty" mapstructure:"headers"`
+}
+
+// GeoIPConfig names MaxMind databases (GeoLite2 or GeoIP2, in .mmdb format)
+// used to locate the addresses checks reach. Either may be left empty.
+type GeoIPConfig struct {
+	CountryDatabase string `yaml:"countryDatabase,omitempty" mapstructure:"countryDatabase"` // Country or City database
+	ASNDatabase     string `yaml:"asnDatabase,omitempty" mapstructure:"asnDatabase"`
+}
+
+// Enabled reports whether any database is configured
+func (g GeoIPConfig) Enabled() bool {
+	return g.CountryDatabase != "" || g.ASNDatabase != ""
+}
+
+// ResultHookConfig is a step of the pipeline check results pass through before
+// they are stored and sent anywhere. Steps run in order on the results they
+// match, and their actions apply in the order of the fields below.
+type ResultHookConfig struct {
+	Name  string          `yaml:"name,omitempty" mapstructure:"name"`
+	Match ResultHookMatch `yaml:"match,omitempty" mapstructure:"match"`
+
+	CopyMonitorLabels bool              `yaml:"copyMonitorLabels,omitempty" mapstructure:"copyMonitorLabels"` // Start from the monitor's labels
+	SetLabels         map[string]string `yaml:"setLabels,omitempty" mapstructure:"setLabels"`
+	RenameLabels      map[string]string `yaml:"renameLabels,omitempty" mapstructure:"renameLabels"` // Old name to new name
+	DropLabels        []string          `yaml:"dropLabels,omitempty" mapstructure:"dropLabels"`
+	SetMetadata       map[string]string `yaml:"setMetadata,omitempty" mapstructure:"setMetadata"`
+	DropFields        []string          `yaml:"dropFields,omitempty" mapstructure:"dropFields"` // Dotted JSON paths such as http_result.headers
+
+	// Plugin is an executable that rewrites matching results, run with PluginArgs
+	// and PluginEnv and passed Options; results pass through unchanged if it fails
+	Plugin     string            `yaml:"plugin,omitempty" mapstructure:"plugin"`
+	PluginArgs []string          `yaml:"pluginArgs,omitempty" mapstructure:"pluginArgs"`
+	PluginEnv  map[string]string `yaml:"pluginEnv,omitempty" mapstructure:"pluginEnv"`
+	Options    map[string]string `yaml:"options,omitempty" mapstructure:"options"`
+	Timeout    time.Duration     `yaml:"timeout,omitempty" mapstructure:"timeout"` // Per result (default 5s)
+}
+
+// ResultHookMatch selects the results a hook applies to. Empty fields match
+// everything; monitor and group names may be glob patterns.
+type ResultHookMatch struct {
+	Monitors []string          `yaml:"monitors,omitempty" mapstructure:"monitors"`
+	Groups   []string          `yaml:"groups,omitempty" mapstructure:"groups"`
+	Types    []string          `yaml:"types,omitempty" mapstructure:"types"`
+	Statuses []string          `yaml:"statuses,omitempty" mapstructure:"statuses"`
+	Labels   map[string]string `yaml:"labels,omitempty" mapstructure:"labels"` // Monitor labels that must all be equal
+}
+
+// MQTTConfig configures publishing of monitor states to an MQTT broker
+type MQTTConfig struct {
+	Enabled        bool          `yaml:"enabled" mapstructure:"enabled"`
+	Broker         string        `yaml:"broker" mapstructure:"broker"` // tcp://host:1883 or ssl://host:8883
+	ClientID       string        `yaml:"clientId,omitempty" mapstructure:"clientId"`
+	Username       string        `yaml:"username,omitempty" mapstructure:"username"`
+	Password       string        `yaml:"password,omitempty" mapstructure:"password"`
+	TopicPrefix    string        `yaml:"topicPrefix,omitempty" mapstructure:"topicPrefix"`
+	QoS            int           `yaml:"qos,omitempty" mapstructure:"qos"`
+	KeepAlive      time.Duration `yaml:"keepAlive,omitempty" mapstructure:"keepAlive"`
+	ConnectTimeout time.Duration `yaml:"connectTimeout,omitempty" mapstructure:"connectTimeout"`
+
+	HomeAssistant HomeAssistantConfig `yaml:"homeAssistant,omitempty" mapstructure:"homeAssistant"`
+}
+
+// HomeAssistantConfig enables Home Assistant MQTT discovery for monitors
+type HomeAssistantConfig struct {
+	Enabled         bool   `yaml:"enabled" mapstructure:"enabled"`
+	DiscoveryPrefix string `yaml:"discoveryPrefix,omitempty" mapstructure:"discoveryPrefix"` // Defaults to homeassistant
+}
+
+// GraphiteConfig configures exporting check durations and up/down states to
+// Graphite over the Carbon plaintext protocol
+type GraphiteConfig struct {
+	Enabled  bool          `yaml:"enabled" mapstructure:"enabled"`
+	Host     string        `yaml:"host" mapstructure:"host"`
+	Port     int           `yaml:"port,omitempty" mapstructure:"port"`         // Defaults to 2003
+	Prefix   string        `yaml:"prefix,omitempty" mapstructure:"prefix"`     // Defaults to hallmonitor
+	Interval time.Duration `yaml:"interval,omitempty" mapstructure:"interval"` // How often results are sent (default 60s)
+}
+
+// ZabbixConfig configures sending monitor statuses to a Zabbix server or
+// proxy as trapper items
+type ZabbixConfig struct {
+	Enabled   bool          `yaml:"enabled" mapstructure:"enabled"`
+	Server    string        `yaml:"server" mapstructure:"server"`                 // host or host:port (default port 10051)
+	Host      string        `yaml:"host,omitempty" mapstructure:"host"`           // Zabbix host the items belong to; defaults to the hostname
+	KeyPrefix string        `yaml:"keyPrefix,omitempty" mapstructure:"keyPrefix"` // Defaults to hallmonitor
+	Interval  time.Duration `yaml:"interval,omitempty" mapstructure:"interval"`   // How often statuses are sent (default 30s)
+}
+
+// NagiosConfig configures submitting results to Nagios or Icinga as passive
+// service checks, through the external command file or NRDP
+type NagiosConfig struct {
+	Enabled     bool          `yaml:"enabled" mapstructure:"enabled"`
+	CommandFile string        `yaml:"commandFile,omitempty" mapstructure:"commandFile"` // e.g. /var/lib/nagios/rw/nagios.cmd
+	NRDP        NRDPConfig    `yaml:"nrdp,omitempty" mapstructure:"nrdp"`
+	Host        string        `yaml:"host,omitempty" mapstructure:"host"`         // Host the services belong to; defaults to the monitor's group
+	Interval    time.Duration `yaml:"interval,omitempty" mapstructure:"interval"` // How often results are submitted (default 30s)
+}
+
+// NRDPConfig is a Nagios Remote Data Processor endpoint
+type NRDPConfig struct {
+	URL   string `yaml:"url" mapstructure:"url"`
+	Token string `yaml:"token" mapstructure:"token"`
+}
+
+// SNMPConfig configures SNMP traps sent when monitors go down or recover
+type SNMPConfig struct {
+	Enabled       bool               `yaml:"enabled" mapstructure:"enabled"`
+	EnterpriseOID string             `yaml:"enterpriseOid,omitempty" mapstructure:"enterpriseOid"` // Root OID of the Hall Monitor MIB
+	EngineID      string             `yaml:"engineId,omitempty" mapstructure:"engineId"`           // Hex engine ID for SNMPv3, generated if empty; a set one counts its boots in BadgerDB or PostgreSQL storage
+	Targets       []SNMPTargetConfig `yaml:"targets,omitempty" mapstructure:"targets"`
+}
+
+// SNMPTargetConfig is a trap receiver
+type SNMPTargetConfig struct {
+	Address      string `yaml:"address" mapstructure:"address"`                     // host:port, port defaults to 162
+	Version      string `yaml:"version,omitempty" mapstructure:"version"`           // v2c (default) or v3
+	Community    string `yaml:"community,omitempty" mapstructure:"community"`       // v2c only, defaults to public
+	User         string `yaml:"user,omitempty" mapstructure:"user"`                 // v3 security name
+	AuthProtocol string `yaml:"authProtocol,omitempty" mapstructure:"authProtocol"` // MD5 or SHA
+	AuthPassword string `yaml:"authPassword,omitempty" mapstructure:"authPassword"`
+	PrivProtocol string `yaml:"privProtocol,omitempty" mapstructure:"privProtocol"` // AES
+	PrivPassword string `yaml:"privPassword,omitempty" mapstructure:"privPassword"`
+}
+
+// TenantConfig is a namespace whose groups (those with a matching tenant) are
+// visible only to its own tokens and to admin tokens
+type TenantConfig struct {
+	Name             string   `yaml:"name" mapstructure:"name"`
+	Title            string   `yaml:"title,omitempty" mapstructure:"title"` // Dashboard and status page heading; defaults to the name
+	Tokens           []string `yaml:"tokens" mapstructure:"tokens"`
+	PublicStatusPage bool     `yaml:"publicStatusPage,omitempty" mapstructure:"publicStatusPage"` // Serve /status/{name} without a token
+}
+
+// GetTenant returns the tenant with the given name, or nil
+func (c *Config) GetTenant(name string) *TenantConfig {
+	for i := range c.Tenants {
+		if c.Tenants[i].Name == name {
+			return &c.Tenants[i]
+		}
+	}
+	return nil
+}
+
+// ReportsConfig configures scheduled uptime reports
+type ReportsConfig struct {
+	SMTP      SMTPConfig             `yaml:"smtp,omitempty" mapstructure:"smtp"`
+	Schedules []ReportScheduleConfig `yaml:"schedules,omitempty" mapstructure:"schedules"`
+}
+
+// SMTPConfig is the mail server reports are sent through
+type SMTPConfig struct {
+	Host     string `yaml:"host" mapstructure:"host"`
+	Port     int    `yaml:"port,omitempty" mapstructure:"port"` // Defaults to 587
+	Username string `yaml:"username,omitempty" mapstructure:"username"`
+	Password string `yaml:"password,omitempty" mapstructure:"password"`
+	From     string `yaml:"from" mapstructure:"from"`
+}
+
+// ReportScheduleConfig is a report generated on a cron schedule and sent by
+// email, to a webhook, or both
+type ReportScheduleConfig struct {
+	Name       string   `yaml:"name" mapstructure:"name"`
+	Title      string   `yaml:"title,omitempty" mapstructure:"title"`           // Defaults to the name
+	Cron       string   `yaml:"cron" mapstructure:"cron"`                       // e.g. "0 8 * * 1" or "@weekly"
+	Timezone   string   `yaml:"timezone,omitempty" mapstructure:"timezone"`     // IANA name; defaults to local time
+	Period     string   `yaml:"period,omitempty" mapstructure:"period"`         // Lookback, e.g. 7d (default) or 24h
+	Groups     []string `yaml:"groups,omitempty" mapstructure:"groups"`         // Defaults to all groups
+	Email      []string `yaml:"email,omitempty" mapstructure:"email"`           // HTML email recipients
+	WebhookURL string   `yaml:"webhookUrl,omitempty" mapstructure:"webhookUrl"` // Receives {"title","text"} with markdown text
 }
 
 // stringToDurationHookFunc is a mapstructure decode hook that converts strings to durations
@@ -177,8 +610,46 @@ func stringToDurationHookFunc() mapstructure.DecodeHookFunc {
 	}
 }
 
-// LoadConfig loads configuration from file
+// LoadConfig loads the base configuration from file, without applying a profile
 func LoadConfig(configPath string) (*Config, error) {
+	return LoadConfigWithProfile(configPath, "")
+}
+
+// LoadConfigWithProfile loads configuration from file and merges the named
+// profile over it. An empty profile loads the base configuration.
+func LoadConfigWithProfile(configPath, profile string) (*Config, error) {
+	v := newViper()
+
+	// Set config file
+	if configPath != "" {
+		v.SetConfigFile(configPath)
+	} else {
+		v.SetConfigName("config")
+		v.SetConfigType("yaml")
+		v.AddConfigPath(".")
+		v.AddConfigPath("/etc/hallmonitor")
+	}
+
+	// Read config
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return decodeConfig(v, profile)
+}
+
+// ParseConfig loads configuration from YAML data and merges the named profile
+// over it, as LoadConfigWithProfile does for a file
+func ParseConfig(data []byte, profile string) (*Config, error) {
+	v := newViper()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	return decodeConfig(v, profile)
+}
+
+// newViper returns a viper instance with the configuration defaults
+func newViper() *viper.Viper {
 	v := viper.New()
 
 	// Set defaults
@@ -186,6 +657,10 @@ func LoadConfig(configPath string) (*Config, error) {
 	v.SetDefault("server.host", "0.0.0.0")
 	v.SetDefault("server.corsOrigins", []string{"http://localhost:3000", "http://localhost:7878"})
 	v.SetDefault("server.enableDashboard", true)
+	v.SetDefault("server.cacheTTL", "30s")
+	v.SetDefault("server.compression", true)
+	v.SetDefault("server.shutdownGracePeriod", "15s")
+	v.SetDefault("server.accounts.sessionTTL", "24h")
 	v.SetDefault("metrics.enabled", true)
 	v.SetDefault("metrics.path", "/metrics")
 	v.SetDefault("metrics.includeProcessMetrics", true)
@@ -201,6 +676,7 @@ func LoadConfig(configPath string) (*Config, error) {
 	v.SetDefault("storage.badger.path", "./data/hallmonitor.db")
 	v.SetDefault("storage.badger.retentionDays", 30)
 	v.SetDefault("storage.badger.enableAggregation", true)
+	v.SetDefault("storage.badger.compression", "zstd")
 	// PostgreSQL defaults
 	v.SetDefault("storage.postgres.host", "localhost")
 	v.SetDefault("storage.postgres.port", 5432)
@@ -208,10 +684,12 @@ func LoadConfig(configPath string) (*Config, error) {
 	v.SetDefault("storage.postgres.user", "hallmonitor")
 	v.SetDefault("storage.postgres.sslmode", "disable")
 	v.SetDefault("storage.postgres.retentionDays", 30)
+	v.SetDefault("storage.postgres.timescale", "auto")
 	// InfluxDB defaults
 	v.SetDefault("storage.influxdb.url", "http://localhost:8086")
 	v.SetDefault("storage.influxdb.org", "hallmonitor")
 	v.SetDefault("storage.influxdb.bucket", "monitor_results")
+	v.SetDefault("storage.influxdb.version", "auto")
 	// Backward compatibility defaults
 	v.SetDefault("storage.enabled", true)
 	v.SetDefault("storage.path", "./data/hallmonitor.db")
@@ -223,20 +701,19 @@ func LoadConfig(configPath string) (*Config, error) {
 	// Enable environment variable substitution
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	v.AutomaticEnv()
+	return v
+}
 
-	// Set config file
-	if configPath != "" {
-		v.SetConfigFile(configPath)
-	} else {
-		v.SetConfigName("config")
-		v.SetConfigType("yaml")
-		v.AddConfigPath(".")
-		v.AddConfigPath("/etc/hallmonitor")
-	}
-
-	// Read config
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config: %w", err)
+// decodeConfig decodes the configuration read into v, merging the named
+// profile over it and filling in monitor defaults
+func decodeConfig(v *viper.Viper, profile string) (*Config, error) {
+	// Merge the selected profile over the base configuration
+	var overrides []MonitorOverride
+	if profile != "" {
+		var err error
+		if overrides, err = applyProfile(v, profile); err != nil {
+			return nil, err
+		}
 	}
 
 	var config Config
@@ -245,6 +722,17 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	// Expand templates first so profiles can override generated monitors
+	if err := config.ExpandTemplates(); err != nil {
+		return nil, err
+	}
+
+	config.Profile = profile
+	config.recordWritten(v.Get("monitoring.groups"))
+	if err := applyMonitorOverrides(&config, overrides); err != nil {
+		return nil, err
+	}
+
 	// Apply defaults to monitors
 	for i := range config.Monitoring.Groups {
 		group := &config.Monitoring.Groups[i]
@@ -252,25 +740,22 @@ func LoadConfig(configPath string) (*Config, error) {
 			group.Interval = config.Monitoring.DefaultInterval
 		}
 
+		if group.Timeout == 0 {
+			group.Timeout = config.Monitoring.DefaultTimeout
+		}
+		if group.SSLCertExpiryWarningDays == 0 {
+			group.SSLCertExpiryWarningDays = config.Monitoring.DefaultSSLCertExpiryWarningDays
+		}
+
 		for j := range group.Monitors {
-			monitor := &group.Monitors[j]
-			if monitor.Interval == 0 {
-				monitor.Interval = group.Interval
-			}
-			if monitor.Timeout == 0 {
-				monitor.Timeout = config.Monitoring.DefaultTimeout
-			}
-			if monitor.SSLCertExpiryWarningDays == 0 {
-				monitor.SSLCertExpiryWarningDays = config.Monitoring.DefaultSSLCertExpiryWarningDays
-			}
-			// Default to enabled if not explicitly set
-			if monitor.Enabled == nil {
-				enabled := true
-				monitor.Enabled = &enabled
-			}
+			applyMonitorDefaults(group, &group.Monitors[j], config.explicitFor(group.Monitors[j].Name))
 		}
 	}
 
+	if err := config.resolveSecrets(); err != nil {
+		return nil, err
+	}
+	config.recordLoaded()
 	return &config, nil
 }
 
@@ -280,67 +765,110 @@ func (c *Config) Validate() error {
 	if c.Server.Port == "" {
 		return fmt.Errorf("server.port is required")
 	}
+	if c.Server.CacheTTL < 0 {
+		return fmt.Errorf("server.cacheTTL cannot be negative")
+	}
+	if c.Server.ShutdownGracePeriod < 0 {
+		return fmt.Errorf("server.shutdownGracePeriod cannot be negative")
+	}
+	if c.Server.SocketMode != "" {
+		if mode, err := strconv.ParseUint(c.Server.SocketMode, 8, 32); err != nil || mode > 0o777 {
+			return fmt.Errorf("server.socketMode must be octal permissions such as 0660: %q", c.Server.SocketMode)
+		}
+	}
+	if err := validateAddresses(c.Server.AdminAllowlist); err != nil {
+		return fmt.Errorf("server.adminAllowlist: %w", err)
+	}
+	if err := validateAddresses(c.Server.TrustedProxies); err != nil {
+		return fmt.Errorf("server.trustedProxies: %w", err)
+	}
+	if c.Server.Locale != "" && !i18n.Supported(c.Server.Locale) {
+		return fmt.Errorf("server.locale %q is not supported (use %s)", c.Server.Locale, strings.Join(i18n.Languages, ", "))
+	}
+	if err := c.validateMetrics(); err != nil {
+		return err
+	}
 
 	// Validate monitoring groups
-	monitorNames := make(map[string]bool)
+	monitorTenants := make(map[string]string)
 	for _, group := range c.Monitoring.Groups {
 		if group.Name == "" {
 			return fmt.Errorf("group name is required")
 		}
+		if group.Timeout.ToDuration() < 0 || group.Timeout.ToDuration() > 5*time.Minute {
+			return fmt.Errorf("group %s timeout must be between 0 and 5 minutes: %v", group.Name, group.Timeout)
+		}
+		if group.Retries < 0 || group.Retries > maxRetries {
+			return fmt.Errorf("group %s retries must be between 0 and %d", group.Name, maxRetries)
+		}
+		if group.RecoveryThreshold < 0 {
+			return fmt.Errorf("group %s recoveryThreshold cannot be negative", group.Name)
+		}
+		if group.MaxConcurrent < 0 {
+			return fmt.Errorf("group %s maxConcurrent cannot be negative", group.Name)
+		}
+		if group.SLO < 0 || group.SLO > 100 {
+			return fmt.Errorf("group %s slo must be between 0 and 100", group.Name)
+		}
+		for i, window := range group.QuietHours {
+			if err := window.Validate(); err != nil {
+				return fmt.Errorf("group %s quietHours[%d]: %w", group.Name, i, err)
+			}
+		}
 
 		for _, monitor := range group.Monitors {
 			if monitor.Name == "" {
 				return fmt.Errorf("monitor name is required in group %s", group.Name)
 			}
-
-			// Check for duplicate monitor names
-			if monitorNames[monitor.Name] {
-				return fmt.Errorf("duplicate monitor name: %s", monitor.Name)
+			if err := validateMonitorName(monitor.Name); err != nil {
+				return fmt.Errorf("monitor %q in group %s: %w", monitor.Name, group.Name, err)
 			}
-			monitorNames[monitor.Name] = true
 
-			// Validate monitor type
-			switch monitor.Type {
-			case models.MonitorTypePing:
-				if monitor.Target == "" {
-					return fmt.Errorf("ping monitor %s requires target", monitor.Name)
-				}
-			case models.MonitorTypeHTTP:
-				if monitor.URL == "" {
-					return fmt.Errorf("http monitor %s requires url", monitor.Name)
-				}
-			case models.MonitorTypeTCP:
-				if monitor.Target == "" {
-					return fmt.Errorf("tcp monitor %s requires target", monitor.Name)
+			// Check for duplicate monitor names. The API and storage look
+			// monitors up by name, so names stay unique across tenants.
+			if tenant, exists := monitorTenants[monitor.Name]; exists {
+				if tenant != group.Tenant {
+					return fmt.Errorf("duplicate monitor name: %s (monitor names are unique across tenants)", monitor.Name)
 				}
-			case models.MonitorTypeDNS:
-				if monitor.Target == "" || monitor.Query == "" {
-					return fmt.Errorf("dns monitor %s requires target and query", monitor.Name)
-				}
-			default:
-				return fmt.Errorf("invalid monitor type: %s", monitor.Type)
+				return fmt.Errorf("duplicate monitor name: %s", monitor.Name)
 			}
+			monitorTenants[monitor.Name] = group.Tenant
 
-			// Validate timeout and interval
-			if monitor.Timeout.ToDuration() < 0 {
-				return fmt.Errorf("monitor %s has negative timeout: %v", monitor.Name, monitor.Timeout)
-			}
-			if monitor.Timeout.ToDuration() > 5*time.Minute {
-				return fmt.Errorf("monitor %s timeout too long (max 5 minutes): %v", monitor.Name, monitor.Timeout)
-			}
-			if monitor.Interval.ToDuration() < 0 {
-				return fmt.Errorf("monitor %s has negative interval: %v", monitor.Name, monitor.Interval)
-			}
-			if monitor.Interval.ToDuration() > 0 && monitor.Interval.ToDuration() < time.Second {
-				return fmt.Errorf("monitor %s interval too short (min 1 second): %v", monitor.Name, monitor.Interval)
+			if err := c.validateMonitor(group, monitor); err != nil {
+				return err
 			}
 		}
 	}
 
+	if err := validateEgressPolicy(&c.Monitoring.Egress); err != nil {
+		return fmt.Errorf("monitoring.egress: %w", err)
+	}
+	if err := validateResolver(&c.Monitoring.Resolver); err != nil {
+		return fmt.Errorf("monitoring.resolver: %w", err)
+	}
+	if err := validateSource(c.Monitoring.SourceIP, c.Monitoring.SourceInterface); err != nil {
+		return fmt.Errorf("monitoring: %w", err)
+	}
+
 	// Validate global defaults
 	if c.Monitoring.DefaultTimeout.ToDuration() < 0 {
 		return fmt.Errorf("monitoring.defaultTimeout cannot be negative")
 	}
+	if c.Monitoring.Workers < 0 {
+		return fmt.Errorf("monitoring.workers cannot be negative")
+	}
+	if err := validateAutoscale(&c.Monitoring.Autoscale, c.Monitoring.Workers); err != nil {
+		return fmt.Errorf("monitoring.autoscale: %w", err)
+	}
+	if c.Monitoring.WarmUp < 0 {
+		return fmt.Errorf("monitoring.warmUp cannot be negative")
+	}
+	if c.Monitoring.MaxChecksPerSecond < 0 {
+		return fmt.Errorf("monitoring.maxChecksPerSecond cannot be negative")
+	}
+	if c.Monitoring.StallThreshold < 0 {
+		return fmt.Errorf("monitoring.stallThreshold cannot be negative")
+	}
 	if c.Monitoring.DefaultInterval.ToDuration() < 0 {
 		return fmt.Errorf("monitoring.defaultInterval cannot be negative")
 	}
@@ -348,16 +876,398 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("monitoring.defaultInterval too short (min 1 second)")
 	}
 
+	// Validate notification webhooks
+	for i, webhook := range c.Webhooks {
+		switch {
+		case webhook.URL == "" && webhook.Plugin == "":
+			return fmt.Errorf("webhooks[%d] requires url or plugin", i)
+		case webhook.URL != "" && webhook.Plugin != "":
+			return fmt.Errorf("webhooks[%d] cannot set both url and plugin", i)
+		}
+		if webhook.Plugin != "" {
+			if _, err := plugin.Resolve(c.Monitoring.PluginDir, webhook.Plugin); err != nil {
+				return fmt.Errorf("webhooks[%d]: %w", i, err)
+			}
+			if err := plugin.ValidateEnv(webhook.PluginEnv); err != nil {
+				return fmt.Errorf("webhooks[%d]: %w", i, err)
+			}
+		}
+		for _, event := range webhook.Events {
+			switch strings.ToLower(event) {
+			case "down", "recovered", "target_changed":
+			default:
+				return fmt.Errorf("webhooks[%d] has unknown event %q (use down, recovered, or target_changed)", i, event)
+			}
+		}
+		if webhook.GroupWindow < 0 {
+			return fmt.Errorf("webhooks[%d] groupWindow cannot be negative", i)
+		}
+		if webhook.Locale != "" && !i18n.Supported(webhook.Locale) {
+			return fmt.Errorf("webhooks[%d] locale %q is not supported (use %s)", i, webhook.Locale, strings.Join(i18n.Languages, ", "))
+		}
+	}
+
+	// Validate result hooks
+	for i, hook := range c.ResultHooks {
+		if err := validateResultHook(hook, c.Monitoring.PluginDir); err != nil {
+			return fmt.Errorf("resultHooks[%d]: %w", i, err)
+		}
+	}
+
+	// Validate result webhooks
+	for i, webhook := range c.ResultWebhooks {
+		if webhook.URL == "" {
+			return fmt.Errorf("resultWebhooks[%d] requires url", i)
+		}
+		if webhook.BatchSize < 0 {
+			return fmt.Errorf("resultWebhooks[%d] batchSize cannot be negative", i)
+		}
+	}
+
+	// Validate MQTT publishing
+	if c.MQTT.Enabled {
+		if c.MQTT.Broker == "" {
+			return fmt.Errorf("mqtt.broker is required when mqtt is enabled")
+		}
+		if c.MQTT.QoS < 0 || c.MQTT.QoS > 1 {
+			return fmt.Errorf("mqtt.qos must be 0 or 1")
+		}
+	}
+
+	// Validate SNMP traps
+	if c.SNMP.Enabled {
+		if len(c.SNMP.Targets) == 0 {
+			return fmt.Errorf("snmp.targets is required when snmp is enabled")
+		}
+		for i, target := range c.SNMP.Targets {
+			if target.Address == "" {
+				return fmt.Errorf("snmp.targets[%d] requires address", i)
+			}
+			switch strings.ToLower(target.Version) {
+			case "", "v2c":
+			case "v3":
+				if target.User == "" {
+					return fmt.Errorf("snmp.targets[%d] requires user for v3", i)
+				}
+			default:
+				return fmt.Errorf("snmp.targets[%d] has unsupported version: %s", i, target.Version)
+			}
+		}
+	}
+
+	// Validate Graphite export
+	if c.Graphite.Enabled {
+		if c.Graphite.Host == "" {
+			return fmt.Errorf("graphite.host is required when graphite is enabled")
+		}
+		if c.Graphite.Port < 0 || c.Graphite.Port > 65535 {
+			return fmt.Errorf("graphite.port must be between 1 and 65535")
+		}
+		if c.Graphite.Interval < 0 {
+			return fmt.Errorf("graphite.interval cannot be negative")
+		}
+	}
+
+	// Validate Zabbix sender
+	if c.Zabbix.Enabled {
+		if c.Zabbix.Server == "" {
+			return fmt.Errorf("zabbix.server is required when zabbix is enabled")
+		}
+		if c.Zabbix.Interval < 0 {
+			return fmt.Errorf("zabbix.interval cannot be negative")
+		}
+	}
+
+	// Validate Nagios passive checks
+	if c.Nagios.Enabled {
+		if (c.Nagios.CommandFile == "") == (c.Nagios.NRDP.URL == "") {
+			return fmt.Errorf("nagios requires exactly one of commandFile or nrdp.url")
+		}
+		if c.Nagios.NRDP.URL != "" {
+			if u, err := url.Parse(c.Nagios.NRDP.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("nagios.nrdp.url must be an http or https URL: %q", c.Nagios.NRDP.URL)
+			}
+			if c.Nagios.NRDP.Token == "" {
+				return fmt.Errorf("nagios.nrdp.token is required")
+			}
+		}
+		if c.Nagios.Interval < 0 {
+			return fmt.Errorf("nagios.interval cannot be negative")
+		}
+	}
+
+	// Validate the CORS policy
+	if err := validateCORS(c.Server.CORSPolicy()); err != nil {
+		return fmt.Errorf("server.cors: %w", err)
+	}
+
+	// Validate local accounts
+	if err := c.validateAccounts(); err != nil {
+		return err
+	}
+
+	// Validate tenants
+	if err := c.validateTenants(); err != nil {
+		return err
+	}
+
+	// Validate reports
+	if err := c.validateReports(); err != nil {
+		return err
+	}
+
+	// Validate templates
+	if err := c.validateTemplates(); err != nil {
+		return err
+	}
+
+	// Validate storage
+	switch c.Storage.Badger.Encoding {
+	case "", "json", "msgpack":
+	default:
+		return fmt.Errorf("storage.badger.encoding must be json or msgpack: %s", c.Storage.Badger.Encoding)
+	}
+	switch c.Storage.Badger.Compression {
+	case "", "zstd", "none":
+	default:
+		return fmt.Errorf("storage.badger.compression must be zstd or none: %s", c.Storage.Badger.Compression)
+	}
+	switch c.Storage.Postgres.Timescale {
+	case "", "auto", "off":
+	default:
+		return fmt.Errorf("storage.postgres.timescale must be auto or off: %s", c.Storage.Postgres.Timescale)
+	}
+	switch c.Storage.InfluxDB.Version {
+	case "", "auto", "2", "3":
+	default:
+		return fmt.Errorf("storage.influxdb.version must be auto, 2, or 3: %s", c.Storage.InfluxDB.Version)
+	}
+	if c.Storage.ReadOnly && c.Storage.Backend == "none" {
+		return fmt.Errorf("storage.readOnly requires a storage backend")
+	}
+	if c.Storage.FailureRetention.RetentionDays < 0 || c.Storage.FailureRetention.Context < 0 {
+		return fmt.Errorf("storage.failureRetention values cannot be negative")
+	}
+
+	// Validate logging rotation
+	if c.Logging.Rotation.MaxSizeMB < 0 || c.Logging.Rotation.MaxBackups < 0 {
+		return fmt.Errorf("logging.rotation values cannot be negative")
+	}
+	if c.Logging.Rotation.MaxAge < 0 || c.Logging.Rotation.Interval < 0 {
+		return fmt.Errorf("logging.rotation durations cannot be negative")
+	}
+
+	return nil
+}
+
+// validateTenants checks tenant names and tokens and that every group's
+// tenant exists
+func (c *Config) validateTenants() error {
+	tokens := make(map[string]bool)
+	for _, token := range c.Server.AdminTokens {
+		if token == "" {
+			return fmt.Errorf("server.adminTokens cannot contain empty tokens")
+		}
+		tokens[token] = true
+	}
+
+	names := make(map[string]bool)
+	for i, tenant := range c.Tenants {
+		if !tenantNamePattern.MatchString(tenant.Name) {
+			return fmt.Errorf("tenants[%d] name %q must be letters, digits, '-' or '_'", i, tenant.Name)
+		}
+		if names[tenant.Name] {
+			return fmt.Errorf("duplicate tenant name: %s", tenant.Name)
+		}
+		names[tenant.Name] = true
+
+		if len(tenant.Tokens) == 0 {
+			return fmt.Errorf("tenant %s requires at least one token", tenant.Name)
+		}
+		for _, token := range tenant.Tokens {
+			if token == "" {
+				return fmt.Errorf("tenant %s cannot have empty tokens", tenant.Name)
+			}
+			if tokens[token] {
+				return fmt.Errorf("tenant %s reuses a token of another tenant or admin", tenant.Name)
+			}
+			tokens[token] = true
+		}
+	}
+
+	for _, group := range c.Monitoring.Groups {
+		if group.Tenant != "" && !names[group.Tenant] {
+			return fmt.Errorf("group %s has unknown tenant %s", group.Name, group.Tenant)
+		}
+	}
+	return nil
+}
+
+// validateCORS checks origin patterns and that credentials are only shared
+// with explicit origins
+func validateCORS(policy CORSConfig) error {
+	if policy.AllowCredentials && len(policy.AllowOrigins) == 0 {
+		return fmt.Errorf("allowCredentials requires allowOrigins")
+	}
+	for _, origin := range policy.AllowOrigins {
+		if origin == "*" {
+			if policy.AllowCredentials {
+				return fmt.Errorf("allowCredentials cannot be used with the \"*\" origin")
+			}
+			continue
+		}
+		if err := validateOrigin(origin); err != nil {
+			return err
+		}
+	}
+	for _, method := range policy.AllowMethods {
+		if method == "" || strings.ToUpper(method) != method || strings.ContainsAny(method, " ,") {
+			return fmt.Errorf("invalid method %q", method)
+		}
+	}
+	if policy.MaxAge < 0 {
+		return fmt.Errorf("maxAge cannot be negative")
+	}
+	return nil
+}
+
+// validateOrigin checks an origin or "*." subdomain pattern
+func validateOrigin(origin string) error {
+	candidate := strings.Replace(origin, "://*.", "://", 1)
+	parsed, err := url.Parse(candidate)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+		strings.Contains(parsed.Host, "*") || (parsed.Path != "" && parsed.Path != "/") || parsed.RawQuery != "" {
+		return fmt.Errorf("invalid origin %q (want scheme://host[:port], optionally with a *. subdomain wildcard)", origin)
+	}
+	return nil
+}
+
+// validateRunbookURL checks that a runbook link is an absolute http(s) URL,
+// since the dashboard renders it as a link
+func validateRunbookURL(runbookURL string) error {
+	parsed, err := url.Parse(runbookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid runbookURL %q (want an http or https URL)", runbookURL)
+	}
+	return nil
+}
+
+// validateAccounts checks local account settings
+func (c *Config) validateAccounts() error {
+	accounts := c.Server.Accounts
+	if accounts.SessionTTL < 0 {
+		return fmt.Errorf("server.accounts.sessionTTL cannot be negative")
+	}
+
+	admin := accounts.InitialAdmin
+	if admin.Username == "" && admin.Password == "" {
+		return nil
+	}
+	if admin.Username == "" {
+		return fmt.Errorf("server.accounts.initialAdmin requires username")
+	}
+	if len(admin.Password) < MinPasswordLength {
+		return fmt.Errorf("server.accounts.initialAdmin password must be at least %d characters", MinPasswordLength)
+	}
+	return nil
+}
+
+// validateMetrics checks the metrics listener and its authentication
+func (c *Config) validateMetrics() error {
+	metrics := c.Metrics
+	if metrics.Listen != "" {
+		if _, port, err := net.SplitHostPort(metrics.Listen); err != nil || port == "" {
+			return fmt.Errorf("metrics.listen must be host:port: %q", metrics.Listen)
+		}
+	}
+
+	auth := metrics.BasicAuth
+	if (auth.Username == "") != (auth.Password == "") {
+		return fmt.Errorf("metrics.basicAuth requires both username and password")
+	}
+
+	tls := metrics.TLS
+	if tls.CertFile != "" || tls.KeyFile != "" || tls.ClientCAFile != "" {
+		if metrics.Listen == "" {
+			return fmt.Errorf("metrics.tls requires metrics.listen")
+		}
+		if tls.CertFile == "" || tls.KeyFile == "" {
+			return fmt.Errorf("metrics.tls requires certFile and keyFile")
+		}
+	}
+
+	push := metrics.Push
+	if push.Enabled {
+		if u, err := url.Parse(push.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("metrics.push.url must be an http or https URL: %q", push.URL)
+		}
+		if push.Interval < 0 {
+			return fmt.Errorf("metrics.push.interval cannot be negative")
+		}
+		if push.Password != "" && push.Username == "" {
+			return fmt.Errorf("metrics.push.password requires username")
+		}
+	}
+	return nil
+}
+
+// validateReports checks report schedules. Cron expressions are parsed when
+// the reports are scheduled.
+func (c *Config) validateReports() error {
+	names := make(map[string]bool)
+	for i, report := range c.Reports.Schedules {
+		if report.Name == "" {
+			return fmt.Errorf("reports.schedules[%d] requires name", i)
+		}
+		if names[report.Name] {
+			return fmt.Errorf("duplicate report name: %s", report.Name)
+		}
+		names[report.Name] = true
+
+		if report.Cron == "" {
+			return fmt.Errorf("report %s requires cron", report.Name)
+		}
+		if report.Period != "" {
+			if _, err := models.ParsePeriod(report.Period); err != nil {
+				return fmt.Errorf("report %s: %w", report.Name, err)
+			}
+		}
+		if len(report.Email) == 0 && report.WebhookURL == "" {
+			return fmt.Errorf("report %s requires email recipients or webhookUrl", report.Name)
+		}
+		if len(report.Email) > 0 && (c.Reports.SMTP.Host == "" || c.Reports.SMTP.From == "") {
+			return fmt.Errorf("report %s sends email, which requires reports.smtp host and from", report.Name)
+		}
+	}
 	return nil
 }
 
 // WriteConfig writes the configuration to a file atomically
 func (c *Config) WriteConfig(path string) error {
+	// Monitors generated from templates are rebuilt on load, so only the
+	// templates and expansions are written. Defaults filled in at load are
+	// left out too.
+	out := *c
+	out.Monitoring.Groups = make([]models.MonitorGroup, len(c.Monitoring.Groups))
+	for i, group := range c.Monitoring.Groups {
+		group.Monitors = c.withoutGenerated(group.Monitors)
+		out.Monitoring.Groups[i] = c.asWritten(group)
+	}
+
 	// Marshal config to YAML
-	data, err := yaml.Marshal(c)
+	data, err := yaml.Marshal(&out)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
+	if data, err = c.writeExplicitZeroes(data); err != nil {
+		return err
+	}
+	// Resolved secrets are written as their references
+	if c.secrets != nil {
+		if data, err = c.secrets.hideSecrets(data); err != nil {
+			return err
+		}
+	}
 
 	// Get original file permissions (default to 0644 if file doesn't exist)
 	var perm os.FileMode = 0644
@@ -456,6 +1366,9 @@ func (c *Config) UpdateMonitor(monitorName string, updated models.Monitor) error
 	if !found {
 		return fmt.Errorf("monitor %s not found", monitorName)
 	}
+	if template, ok := c.GeneratedBy(monitorName); ok {
+		return fmt.Errorf("%w: %s comes from template %s; edit the template or group expansion instead", ErrGeneratedMonitor, monitorName, template)
+	}
 
 	// If name is being changed, check for duplicates
 	if updated.Name != monitorName {
@@ -470,12 +1383,30 @@ func (c *Config) UpdateMonitor(monitorName string, updated models.Monitor) error
 	return nil
 }
 
+// SetMonitorEnabled enables or disables a monitor. Disabled monitors stay
+// configured and loaded but are not scheduled.
+func (c *Config) SetMonitorEnabled(monitorName string, enabled bool) error {
+	groupIdx, monitorIdx, found := c.FindMonitor(monitorName)
+	if !found {
+		return fmt.Errorf("monitor %s not found", monitorName)
+	}
+	if template, ok := c.GeneratedBy(monitorName); ok {
+		return fmt.Errorf("%w: %s comes from template %s; edit the template or group expansion instead", ErrGeneratedMonitor, monitorName, template)
+	}
+
+	c.Monitoring.Groups[groupIdx].Monitors[monitorIdx].Enabled = &enabled
+	return nil
+}
+
 // DeleteMonitor deletes a monitor by name
 func (c *Config) DeleteMonitor(monitorName string) error {
 	groupIdx, monitorIdx, found := c.FindMonitor(monitorName)
 	if !found {
 		return fmt.Errorf("monitor %s not found", monitorName)
 	}
+	if template, ok := c.GeneratedBy(monitorName); ok {
+		return fmt.Errorf("%w: %s comes from template %s; edit the template or group expansion instead", ErrGeneratedMonitor, monitorName, template)
+	}
 
 	// Remove monitor from slice
 	group := &c.Monitoring.Groups[groupIdx]
@@ -484,6 +1415,30 @@ func (c *Config) DeleteMonitor(monitorName string) error {
 	return nil
 }
 
+// MoveMonitor moves a monitor to the end of another group
+func (c *Config) MoveMonitor(monitorName, groupName string) error {
+	groupIdx, monitorIdx, found := c.FindMonitor(monitorName)
+	if !found {
+		return fmt.Errorf("monitor %s not found", monitorName)
+	}
+	if template, ok := c.GeneratedBy(monitorName); ok {
+		return fmt.Errorf("%w: %s comes from template %s; edit the template or group expansion instead", ErrGeneratedMonitor, monitorName, template)
+	}
+	targetIdx, found := c.FindGroup(groupName)
+	if !found {
+		return fmt.Errorf("group %s not found", groupName)
+	}
+	if targetIdx == groupIdx {
+		return nil
+	}
+
+	group := &c.Monitoring.Groups[groupIdx]
+	monitor := group.Monitors[monitorIdx]
+	group.Monitors = append(group.Monitors[:monitorIdx], group.Monitors[monitorIdx+1:]...)
+	c.Monitoring.Groups[targetIdx].Monitors = append(c.Monitoring.Groups[targetIdx].Monitors, monitor)
+	return nil
+}
+
 // FindGroup finds a group by name and returns its index
 func (c *Config) FindGroup(groupName string) (int, bool) {
 	for i, group := range c.Monitoring.Groups {
@@ -527,6 +1482,18 @@ func (c *Config) UpdateGroup(groupName string, updated models.MonitorGroup) erro
 	return nil
 }
 
+// SetGroupEnabled enables or disables a group. Monitors in a disabled group
+// stay configured and loaded but are not scheduled, whatever their own flag.
+func (c *Config) SetGroupEnabled(groupName string, enabled bool) error {
+	groupIdx, found := c.FindGroup(groupName)
+	if !found {
+		return fmt.Errorf("group %s not found", groupName)
+	}
+
+	c.Monitoring.Groups[groupIdx].Enabled = &enabled
+	return nil
+}
+
 // DeleteGroup deletes a group by name
 func (c *Config) DeleteGroup(groupName string) error {
 	groupIdx, found := c.FindGroup(groupName)
@@ -539,3 +1506,365 @@ func (c *Config) DeleteGroup(groupName string) error {
 
 	return nil
 }
+
+// applyMonitorDefaults fills in a monitor's unset settings from its group,
+// whose own defaults are already applied. Zero values of the
+// zeroableSettings in explicit are kept.
+func applyMonitorDefaults(group *models.MonitorGroup, monitor *models.Monitor, explicit map[string]bool) {
+	if monitor.Interval == 0 && monitor.Type == models.MonitorTypeDomain {
+		// Registration data changes rarely and registries rate-limit lookups
+		monitor.Interval = models.Duration(defaultDomainInterval)
+	}
+	if monitor.Interval == 0 {
+		monitor.Interval = group.Interval
+	}
+	if monitor.Timeout == 0 {
+		monitor.Timeout = group.Timeout
+	}
+	if monitor.SSLCertExpiryWarningDays == 0 {
+		monitor.SSLCertExpiryWarningDays = group.SSLCertExpiryWarningDays
+	}
+	if monitor.Retries == 0 && !explicit["retries"] {
+		monitor.Retries = group.Retries
+	}
+	if monitor.RecoveryThreshold == 0 && !explicit["recoveryThreshold"] {
+		monitor.RecoveryThreshold = group.RecoveryThreshold
+	}
+	if monitor.SLO == 0 {
+		monitor.SLO = group.SLO
+	}
+	if len(monitor.QuietHours) == 0 {
+		monitor.QuietHours = group.QuietHours
+	}
+	monitor.Labels = mergeStringMaps(group.Labels, monitor.Labels)
+	if monitor.Type == models.MonitorTypeHTTP {
+		monitor.Headers = mergeStringMaps(group.Headers, monitor.Headers)
+		if len(group.CaptureHeaders) > 0 {
+			monitor.CaptureHeaders = append(append([]string{}, group.CaptureHeaders...), monitor.CaptureHeaders...)
+		}
+		if monitor.ExpectedStatus == 0 && !explicit["expectedStatus"] {
+			monitor.ExpectedStatus = group.ExpectedStatus
+		}
+	}
+	// Default to enabled if not explicitly set
+	if monitor.Enabled == nil {
+		enabled := true
+		monitor.Enabled = &enabled
+	}
+}
+
+// PrepareMonitor applies the defaults of a group to a monitor that is not in
+// the config, and validates it, as if it were added to that group. Unknown
+// groups get the global defaults.
+func (c *Config) PrepareMonitor(groupName string, monitor models.Monitor) (models.Monitor, error) {
+	group := models.MonitorGroup{
+		Name:                     groupName,
+		Interval:                 c.Monitoring.DefaultInterval,
+		Timeout:                  c.Monitoring.DefaultTimeout,
+		SSLCertExpiryWarningDays: c.Monitoring.DefaultSSLCertExpiryWarningDays,
+	}
+	for _, existing := range c.Monitoring.Groups {
+		if existing.Name == groupName {
+			group = existing
+			break
+		}
+	}
+
+	if monitor.Name == "" {
+		return monitor, fmt.Errorf("monitor name is required")
+	}
+	if err := validateMonitorName(monitor.Name); err != nil {
+		return monitor, fmt.Errorf("monitor %q: %w", monitor.Name, err)
+	}
+	applyMonitorDefaults(&group, &monitor, nil)
+	if err := c.validateMonitor(group, monitor); err != nil {
+		return monitor, err
+	}
+	return monitor, nil
+}
+
+// validateMonitor checks one monitor's settings, apart from its name
+func (c *Config) validateMonitor(group models.MonitorGroup, monitor models.Monitor) error {
+	// Validate monitor type
+	switch monitor.Type {
+	case models.MonitorTypePing:
+		if monitor.Target == "" {
+			return fmt.Errorf("ping monitor %s requires target", monitor.Name)
+		}
+	case models.MonitorTypeHTTP:
+		if monitor.URL == "" {
+			return fmt.Errorf("http monitor %s requires url", monitor.Name)
+		}
+		switch monitor.HTTPVersion {
+		case "", "auto", "1.1", "2":
+		default:
+			return fmt.Errorf("http monitor %s has invalid httpVersion %q (must be auto, 1.1, or 2)", monitor.Name, monitor.HTTPVersion)
+		}
+		for _, pin := range monitor.PinnedKeys {
+			if _, err := models.ParseKeyPin(pin); err != nil {
+				return fmt.Errorf("http monitor %s: %w", monitor.Name, err)
+			}
+		}
+		if len(monitor.PinnedKeys) > 0 && !strings.HasPrefix(strings.ToLower(monitor.URL), "https://") {
+			return fmt.Errorf("http monitor %s: pinnedKeys requires an https url", monitor.Name)
+		}
+	case models.MonitorTypeTCP:
+		if monitor.Target == "" {
+			return fmt.Errorf("tcp monitor %s requires target", monitor.Name)
+		}
+	case models.MonitorTypeDNS:
+		if monitor.Target == "" || monitor.Query == "" {
+			return fmt.Errorf("dns monitor %s requires target and query", monitor.Name)
+		}
+	case models.MonitorTypeRBL:
+		if monitor.Target == "" {
+			return fmt.Errorf("rbl monitor %s requires target", monitor.Name)
+		}
+	case models.MonitorTypeDomain:
+		if monitor.Target == "" {
+			return fmt.Errorf("domain monitor %s requires target", monitor.Name)
+		}
+		if monitor.ExpiryWarningDays < 0 {
+			return fmt.Errorf("domain monitor %s expiryWarningDays cannot be negative", monitor.Name)
+		}
+	case models.MonitorTypePlugin:
+		if monitor.Plugin == "" {
+			return fmt.Errorf("plugin monitor %s requires plugin", monitor.Name)
+		}
+		if _, err := plugin.Resolve(c.Monitoring.PluginDir, monitor.Plugin); err != nil {
+			return fmt.Errorf("plugin monitor %s: %w", monitor.Name, err)
+		}
+		if err := plugin.ValidateEnv(monitor.PluginEnv); err != nil {
+			return fmt.Errorf("plugin monitor %s: %w", monitor.Name, err)
+		}
+	case models.MonitorTypeWasm:
+		if monitor.Module == "" {
+			return fmt.Errorf("wasm monitor %s requires module", monitor.Name)
+		}
+		if monitor.Limits != nil && monitor.Limits.MemoryMB < 0 {
+			return fmt.Errorf("wasm monitor %s limits.memoryMB cannot be negative", monitor.Name)
+		}
+	default:
+		if !IsCustomMonitorType(monitor.Type) {
+			return fmt.Errorf("invalid monitor type: %s", monitor.Type)
+		}
+	}
+
+	// Validate timeout and interval
+	if monitor.Timeout.ToDuration() < 0 {
+		return fmt.Errorf("monitor %s has negative timeout: %v", monitor.Name, monitor.Timeout)
+	}
+	if monitor.Timeout.ToDuration() > 5*time.Minute {
+		return fmt.Errorf("monitor %s timeout too long (max 5 minutes): %v", monitor.Name, monitor.Timeout)
+	}
+	if monitor.Interval.ToDuration() < 0 {
+		return fmt.Errorf("monitor %s has negative interval: %v", monitor.Name, monitor.Interval)
+	}
+	if monitor.Interval.ToDuration() > 0 && monitor.Interval.ToDuration() < time.Second {
+		return fmt.Errorf("monitor %s interval too short (min 1 second): %v", monitor.Name, monitor.Interval)
+	}
+	if monitor.Retries < 0 || monitor.Retries > maxRetries {
+		return fmt.Errorf("monitor %s retries must be between 0 and %d", monitor.Name, maxRetries)
+	}
+	if monitor.RecoveryThreshold < 0 {
+		return fmt.Errorf("monitor %s recoveryThreshold cannot be negative", monitor.Name)
+	}
+	for _, header := range monitor.CaptureHeaders {
+		if header == "" || strings.ContainsAny(header, " \t\r\n:") {
+			return fmt.Errorf("monitor %s captureHeaders has invalid header name %q", monitor.Name, header)
+		}
+	}
+	if monitor.MaxDuration.ToDuration() < 0 {
+		return fmt.Errorf("monitor %s has negative maxDuration: %v", monitor.Name, monitor.MaxDuration)
+	}
+	if monitor.MaxConnections < 0 {
+		return fmt.Errorf("monitor %s maxConnections cannot be negative", monitor.Name)
+	}
+	if monitor.SLO < 0 || monitor.SLO > 100 {
+		return fmt.Errorf("monitor %s slo must be between 0 and 100", monitor.Name)
+	}
+	if monitor.SampleRate < 0 {
+		return fmt.Errorf("monitor %s sampleRate cannot be negative", monitor.Name)
+	}
+	if monitor.SampleRate > 1 {
+		interval := monitor.Interval.ToDuration()
+		if interval == 0 {
+			interval = group.Interval.ToDuration()
+		}
+		if interval == 0 {
+			interval = c.Monitoring.DefaultInterval.ToDuration()
+		}
+		if interval >= maxSampledInterval {
+			return fmt.Errorf("monitor %s sampleRate requires an interval under %s", monitor.Name, maxSampledInterval)
+		}
+		if c.Storage.Backend == "influxdb" {
+			return fmt.Errorf("monitor %s sampleRate is not supported with influxdb storage", monitor.Name)
+		}
+	}
+	if monitor.RunbookURL != "" {
+		if err := validateRunbookURL(monitor.RunbookURL); err != nil {
+			return fmt.Errorf("monitor %s: %w", monitor.Name, err)
+		}
+	}
+	for i, window := range monitor.QuietHours {
+		if err := window.Validate(); err != nil {
+			return fmt.Errorf("monitor %s quietHours[%d]: %w", monitor.Name, i, err)
+		}
+	}
+	if monitor.Egress != nil {
+		if err := validateEgressPolicy(monitor.Egress); err != nil {
+			return fmt.Errorf("monitor %s egress: %w", monitor.Name, err)
+		}
+	}
+	if monitor.Resolver != nil {
+		if err := validateResolver(monitor.Resolver); err != nil {
+			return fmt.Errorf("monitor %s resolver: %w", monitor.Name, err)
+		}
+	}
+	if err := validateSource(monitor.SourceIP, monitor.SourceInterface); err != nil {
+		return fmt.Errorf("monitor %s: %w", monitor.Name, err)
+	}
+	for host, address := range monitor.Hosts {
+		if net.ParseIP(strings.TrimSpace(address)) == nil {
+			return fmt.Errorf("monitor %s hosts: %q for %s is not an IP address", monitor.Name, address, host)
+		}
+	}
+	if monitor.ExpectedIP != "" || len(monitor.ExpectedIPs) > 0 {
+		if monitor.Type != models.MonitorTypeHTTP && monitor.Type != models.MonitorTypeTCP {
+			return fmt.Errorf("monitor %s: expectedIP is only supported for http and tcp monitors", monitor.Name)
+		}
+		expected := monitor.ExpectedIPs
+		if monitor.ExpectedIP != "" {
+			expected = append([]string{monitor.ExpectedIP}, expected...)
+		}
+		if err := validateAddresses(expected); err != nil {
+			return fmt.Errorf("monitor %s expectedIP: %w", monitor.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateMonitorName rejects names that cannot be stored or displayed
+// reliably. Any other characters, including ':', are allowed.
+func validateMonitorName(name string) error {
+	if len(name) > maxMonitorNameLength {
+		return fmt.Errorf("name longer than %d bytes", maxMonitorNameLength)
+	}
+	if !utf8.ValidString(name) {
+		return fmt.Errorf("name is not valid UTF-8")
+	}
+	if strings.ContainsFunc(name, unicode.IsControl) {
+		return fmt.Errorf("name contains control characters")
+	}
+	return nil
+}
+
+// resultIdentityFields are result fields hooks may not drop
+var resultIdentityFields = map[string]bool{
+	"monitor":   true,
+	"type":      true,
+	"group":     true,
+	"status":    true,
+	"timestamp": true,
+}
+
+// validateResultHook checks a result hook's patterns, fields, and plugin
+func validateResultHook(hook ResultHookConfig, pluginDir string) error {
+	for _, pattern := range append(append([]string{}, hook.Match.Monitors...), hook.Match.Groups...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	for _, status := range hook.Match.Statuses {
+		switch models.MonitorStatus(status) {
+		case models.StatusUp, models.StatusDown, models.StatusUnknown:
+		default:
+			return fmt.Errorf("unknown status %q (use up, down, or unknown)", status)
+		}
+	}
+	for _, field := range hook.DropFields {
+		if field == "" || strings.HasPrefix(field, ".") || strings.HasSuffix(field, ".") {
+			return fmt.Errorf("invalid field %q", field)
+		}
+		if resultIdentityFields[field] {
+			return fmt.Errorf("field %s cannot be dropped", field)
+		}
+	}
+	if hook.Plugin != "" {
+		if _, err := plugin.Resolve(pluginDir, hook.Plugin); err != nil {
+			return err
+		}
+		if err := plugin.ValidateEnv(hook.PluginEnv); err != nil {
+			return err
+		}
+	}
+	if hook.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	return nil
+}
+
+// validateEgressPolicy checks that allow and deny entries are CIDRs or IPs
+func validateEgressPolicy(policy *models.EgressPolicy) error {
+	for _, entries := range [][]string{policy.Allow, policy.Deny} {
+		if err := validateAddresses(entries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAutoscale checks the worker autoscaling settings against the base pool size
+func validateAutoscale(autoscale *AutoscaleConfig, workers int) error {
+	if autoscale.MaxWorkers < 0 || autoscale.QueueThreshold < 0 || autoscale.LagThreshold < 0 || autoscale.IdleTimeout < 0 {
+		return fmt.Errorf("settings cannot be negative")
+	}
+	if autoscale.MaxWorkers > 0 && workers > 0 && autoscale.MaxWorkers < workers {
+		return fmt.Errorf("maxWorkers (%d) cannot be less than workers (%d)", autoscale.MaxWorkers, workers)
+	}
+	return nil
+}
+
+// validateAddresses checks that every entry is an IP address or CIDR
+func validateAddresses(entries []string) error {
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if _, _, err := net.ParseCIDR(entry); err == nil {
+			continue
+		}
+		if net.ParseIP(entry) == nil {
+			return fmt.Errorf("%q is not an IP address or CIDR", entry)
+		}
+	}
+	return nil
+}
+
+// validateResolver checks that resolver servers are IP addresses with an optional port
+func validateResolver(resolver *models.ResolverConfig) error {
+	for _, server := range resolver.Servers {
+		server = strings.TrimSpace(server)
+		if net.ParseIP(server) != nil {
+			continue
+		}
+		host, port, err := net.SplitHostPort(server)
+		if err != nil || net.ParseIP(host) == nil {
+			return fmt.Errorf("server %q must be an IP address or ip:port", server)
+		}
+		if portNum, err := strconv.Atoi(port); err != nil || portNum < 1 || portNum > 65535 {
+			return fmt.Errorf("server %q has an invalid port", server)
+		}
+	}
+	return nil
+}
+
+// validateSource checks the source address settings; the interface itself is
+// only looked up when monitors are created, since it may appear later
+func validateSource(sourceIP, sourceInterface string) error {
+	if sourceIP != "" && sourceInterface != "" {
+		return fmt.Errorf("sourceIP and sourceInterface are mutually exclusive")
+	}
+	if sourceIP != "" && net.ParseIP(strings.TrimSpace(sourceIP)) == nil {
+		return fmt.Errorf("sourceIP %q is not an IP address", sourceIP)
+	}
+	return nil
+}
@@ -2,10 +2,13 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/1broseidon/hallmonitor/pkg/models"
+	"gopkg.in/yaml.v3"
 )
 
 func writeTempConfig(t *testing.T, content string) string {
@@ -92,6 +95,221 @@ monitoring:
 	}
 }
 
+const groupDefaultsConfigYAML = `
+monitoring:
+  defaultTimeout: "10s"
+  defaultSSLCertExpiryWarningDays: 30
+  groups:
+    - name: "api"
+      timeout: "3s"
+      retries: 2
+      recoveryThreshold: 3
+      expectedStatus: 204
+      sslCertExpiryWarningDays: 14
+      slo: 99.9
+      quietHours:
+        - days: ["sat", "sun"]
+          start: "00:00"
+          end: "24:00"
+          timezone: "UTC"
+      headers:
+        Authorization: "Bearer group"
+        Accept: "application/json"
+      captureHeaders: ["X-Cache"]
+      labels:
+        team: "platform"
+      monitors:
+        - type: "http"
+          name: "inherits"
+          url: "https://a.example.com"
+        - type: "http"
+          name: "overrides"
+          url: "https://b.example.com"
+          timeout: "7s"
+          retries: 1
+          recoveryThreshold: 1
+          expectedStatus: 200
+          sslCertExpiryWarningDays: 60
+          slo: 99.5
+          quietHours:
+            - start: "22:00"
+              end: "06:00"
+              action: "downgrade"
+          headers:
+            Authorization: "Bearer monitor"
+          captureHeaders: ["CF-Ray"]
+          labels:
+            tier: "edge"
+        - type: "tcp"
+          name: "socket"
+          target: "db:5432"
+        - type: "http"
+          name: "zeroes"
+          url: "https://c.example.com"
+          retries: 0
+          recoveryThreshold: 0
+          expectedStatus: 0
+`
+
+func TestLoadConfigAppliesGroupDefaults(t *testing.T) {
+	path := writeTempConfig(t, groupDefaultsConfigYAML)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	monitors := cfg.Monitoring.Groups[0].Monitors
+
+	inherits := monitors[0]
+	if inherits.Timeout != models.Duration(3*time.Second) || inherits.Retries != 2 {
+		t.Errorf("expected group timeout 3s and retries 2, got %s and %d", inherits.Timeout, inherits.Retries)
+	}
+	if inherits.ExpectedStatus != 204 || inherits.SSLCertExpiryWarningDays != 14 {
+		t.Errorf("expected group status 204 and ssl days 14, got %d and %d", inherits.ExpectedStatus, inherits.SSLCertExpiryWarningDays)
+	}
+	if inherits.RecoveryThreshold != 3 {
+		t.Errorf("expected group recoveryThreshold 3, got %d", inherits.RecoveryThreshold)
+	}
+	if inherits.SLO != 99.9 {
+		t.Errorf("expected group slo 99.9, got %v", inherits.SLO)
+	}
+	if len(inherits.QuietHours) != 1 || inherits.QuietHours[0].Timezone != "UTC" || len(inherits.QuietHours[0].Days) != 2 {
+		t.Errorf("expected group quiet hours, got %+v", inherits.QuietHours)
+	}
+	if inherits.Headers["authorization"] != "Bearer group" || inherits.Labels["team"] != "platform" {
+		t.Errorf("expected group headers and labels, got %v and %v", inherits.Headers, inherits.Labels)
+	}
+
+	overrides := monitors[1]
+	if overrides.Timeout != models.Duration(7*time.Second) || overrides.Retries != 1 {
+		t.Errorf("expected monitor timeout 7s and retries 1, got %s and %d", overrides.Timeout, overrides.Retries)
+	}
+	if overrides.ExpectedStatus != 200 || overrides.SSLCertExpiryWarningDays != 60 {
+		t.Errorf("expected monitor status 200 and ssl days 60, got %d and %d", overrides.ExpectedStatus, overrides.SSLCertExpiryWarningDays)
+	}
+	if overrides.RecoveryThreshold != 1 {
+		t.Errorf("expected monitor recoveryThreshold 1, got %d", overrides.RecoveryThreshold)
+	}
+	if overrides.SLO != 99.5 {
+		t.Errorf("expected monitor slo 99.5, got %v", overrides.SLO)
+	}
+	if len(overrides.QuietHours) != 1 || overrides.QuietHours[0].Action != models.QuietDowngrade {
+		t.Errorf("expected monitor quiet hours to replace the group's, got %+v", overrides.QuietHours)
+	}
+	if overrides.Headers["authorization"] != "Bearer monitor" || overrides.Headers["accept"] != "application/json" {
+		t.Errorf("expected monitor headers merged over group headers, got %v", overrides.Headers)
+	}
+	if len(overrides.CaptureHeaders) != 2 || overrides.CaptureHeaders[0] != "X-Cache" || overrides.CaptureHeaders[1] != "CF-Ray" {
+		t.Errorf("expected group capture headers added to the monitor's, got %v", overrides.CaptureHeaders)
+	}
+	if overrides.Labels["team"] != "platform" || overrides.Labels["tier"] != "edge" {
+		t.Errorf("expected monitor labels merged over group labels, got %v", overrides.Labels)
+	}
+
+	socket := monitors[2]
+	if socket.ExpectedStatus != 0 || len(socket.Headers) != 0 {
+		t.Errorf("expected HTTP-only defaults to skip tcp monitor, got status %d headers %v", socket.ExpectedStatus, socket.Headers)
+	}
+	if socket.Labels["team"] != "platform" {
+		t.Errorf("expected labels to apply to every monitor type, got %v", socket.Labels)
+	}
+
+	// Explicit zeroes override the group instead of inheriting it
+	zeroes := monitors[3]
+	for name, value := range map[string]int{"retries": zeroes.Retries, "recoveryThreshold": zeroes.RecoveryThreshold, "expectedStatus": zeroes.ExpectedStatus} {
+		if value != 0 {
+			t.Errorf("expected explicit %s 0 to override the group, got %d", name, value)
+		}
+	}
+}
+
+func TestWriteConfigKeepsMonitorsAsWritten(t *testing.T) {
+	cfg, err := LoadConfig(writeTempConfig(t, groupDefaultsConfigYAML))
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	// Raise the group default and disable a monitor without editing the others
+	cfg.Monitoring.Groups[0].Retries = 4
+	if err := cfg.SetMonitorEnabled("socket", false); err != nil {
+		t.Fatalf("SetMonitorEnabled returned error: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "config.yml")
+	if err := cfg.WriteConfig(out); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read written config: %v", err)
+	}
+
+	var written struct {
+		Monitoring struct {
+			Groups []models.MonitorGroup `yaml:"groups"`
+		} `yaml:"monitoring"`
+	}
+	if err := yaml.Unmarshal(data, &written); err != nil {
+		t.Fatalf("failed to parse written config: %v", err)
+	}
+	group := written.Monitoring.Groups[0]
+	if group.Interval != 0 || group.Retries != 4 {
+		t.Errorf("expected group written without the global interval and with retries 4, got %s and %d", group.Interval, group.Retries)
+	}
+	inherits := group.Monitors[0]
+	if inherits.Retries != 0 || inherits.ExpectedStatus != 0 || inherits.Timeout != 0 || inherits.Enabled != nil || len(inherits.Labels) != 0 || len(inherits.Headers) != 0 {
+		t.Errorf("expected inherited defaults to be left out, got %+v", inherits)
+	}
+	if socket := group.Monitors[2]; socket.Enabled == nil || *socket.Enabled || len(socket.Labels) != 0 {
+		t.Errorf("expected socket written disabled without inherited labels, got %+v", socket)
+	}
+	if !strings.Contains(string(data), "retries: 0") {
+		t.Errorf("expected explicit retries 0 to be written, got:\n%s", data)
+	}
+
+	reloaded, err := LoadConfig(out)
+	if err != nil {
+		t.Fatalf("LoadConfig after write returned error: %v", err)
+	}
+	if got := reloaded.Monitoring.Groups[0].Monitors[0].Retries; got != 4 {
+		t.Errorf("expected monitor to inherit the new group retries, got %d", got)
+	}
+	if got := reloaded.Monitoring.Groups[0].Monitors[3].Retries; got != 0 {
+		t.Errorf("expected explicit retries 0 to survive the write, got %d", got)
+	}
+}
+
+func TestLoadConfigDomainMonitorInterval(t *testing.T) {
+	configYAML := `
+monitoring:
+  defaultInterval: "30s"
+  groups:
+    - name: "domains"
+      monitors:
+        - type: "domain"
+          name: "daily"
+          target: "example.com"
+        - type: "domain"
+          name: "hourly"
+          target: "example.org"
+          interval: "1h"
+`
+
+	cfg, err := LoadConfig(writeTempConfig(t, configYAML))
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	monitors := cfg.Monitoring.Groups[0].Monitors
+	if monitors[0].Interval != models.Duration(defaultDomainInterval) {
+		t.Errorf("expected domain monitor to default to %s, got %s", defaultDomainInterval, monitors[0].Interval)
+	}
+	if monitors[1].Interval != models.Duration(time.Hour) {
+		t.Errorf("expected explicit interval to be kept, got %s", monitors[1].Interval)
+	}
+}
+
 func TestLoadConfigEnvironmentOverrides(t *testing.T) {
 	configYAML := `
 monitoring:
@@ -250,4 +468,910 @@ func TestConfigValidateErrors(t *testing.T) {
 	if err := invalidIntervalConfig.Validate(); err == nil {
 		t.Fatalf("expected short interval validation error")
 	}
+
+	invalidRetriesConfig := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Monitoring: MonitoringConfig{
+			Groups: []models.MonitorGroup{
+				{
+					Name:    "group",
+					Retries: maxRetries + 1,
+				},
+			},
+		},
+	}
+
+	if err := invalidRetriesConfig.Validate(); err == nil {
+		t.Fatalf("expected group retries validation error")
+	}
+
+	invalidEgressConfig := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Monitoring: MonitoringConfig{
+			Egress: models.EgressPolicy{Deny: []string{"10.0.0.0/33"}},
+		},
+	}
+
+	if err := invalidEgressConfig.Validate(); err == nil {
+		t.Fatalf("expected egress policy validation error")
+	}
+
+	invalidHostsConfig := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Monitoring: MonitoringConfig{
+			Resolver: models.ResolverConfig{Servers: []string{"10.0.0.53:5353"}},
+			Groups: []models.MonitorGroup{
+				{
+					Name: "group",
+					Monitors: []models.Monitor{
+						{Type: models.MonitorTypeHTTP, Name: "cutover", URL: "https://app.example.com", Hosts: map[string]string{"app.example.com": "new-lb"}},
+					},
+				},
+			},
+		},
+	}
+
+	if err := invalidHostsConfig.Validate(); err == nil {
+		t.Fatalf("expected hosts override validation error")
+	}
+
+	unsupportedHTTPVersionConfig := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Monitoring: MonitoringConfig{
+			Groups: []models.MonitorGroup{
+				{
+					Name: "group",
+					Monitors: []models.Monitor{
+						{Type: models.MonitorTypeHTTP, Name: "quic", URL: "https://example.com", HTTPVersion: "3"},
+					},
+				},
+			},
+		},
+	}
+
+	if err := unsupportedHTTPVersionConfig.Validate(); err == nil {
+		t.Fatalf("expected httpVersion validation error")
+	}
+
+	invalidExpectedIPConfig := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Monitoring: MonitoringConfig{
+			Groups: []models.MonitorGroup{
+				{
+					Name: "group",
+					Monitors: []models.Monitor{
+						{Type: models.MonitorTypeTCP, Name: "pinned", Target: "db.example.com:5432", ExpectedIPs: []string{"10.0.0.0/33"}},
+					},
+				},
+			},
+		},
+	}
+
+	if err := invalidExpectedIPConfig.Validate(); err == nil {
+		t.Fatalf("expected expectedIPs validation error")
+	}
+
+	conflictingSourceConfig := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Monitoring: MonitoringConfig{
+			SourceIP:        "192.168.10.5",
+			SourceInterface: "eth1",
+		},
+	}
+
+	if err := conflictingSourceConfig.Validate(); err == nil {
+		t.Fatalf("expected conflicting source validation error")
+	}
+	mqttWithoutBroker := &Config{
+		Server: ServerConfig{Port: "7878"},
+		MQTT:   MQTTConfig{Enabled: true},
+	}
+
+	if err := mqttWithoutBroker.Validate(); err == nil {
+		t.Fatalf("expected mqtt broker validation error")
+	}
+
+	mqttInvalidQoS := &Config{
+		Server: ServerConfig{Port: "7878"},
+		MQTT:   MQTTConfig{Enabled: true, Broker: "tcp://localhost:1883", QoS: 2},
+	}
+
+	if err := mqttInvalidQoS.Validate(); err == nil {
+		t.Fatalf("expected mqtt qos validation error")
+	}
+	snmpWithoutTargets := &Config{
+		Server: ServerConfig{Port: "7878"},
+		SNMP:   SNMPConfig{Enabled: true},
+	}
+
+	if err := snmpWithoutTargets.Validate(); err == nil {
+		t.Fatalf("expected snmp targets validation error")
+	}
+
+	snmpV3WithoutUser := &Config{
+		Server: ServerConfig{Port: "7878"},
+		SNMP: SNMPConfig{
+			Enabled: true,
+			Targets: []SNMPTargetConfig{{Address: "localhost:162", Version: "v3"}},
+		},
+	}
+
+	if err := snmpV3WithoutUser.Validate(); err == nil {
+		t.Fatalf("expected snmp v3 user validation error")
+	}
+
+	badgerInvalidCompression := &Config{
+		Server:  ServerConfig{Port: "7878"},
+		Storage: StorageConfig{Badger: BadgerConfig{Compression: "gzip"}},
+	}
+
+	if err := badgerInvalidCompression.Validate(); err == nil {
+		t.Fatalf("expected badger compression validation error")
+	}
+
+	badgerInvalidEncoding := &Config{
+		Server:  ServerConfig{Port: "7878"},
+		Storage: StorageConfig{Badger: BadgerConfig{Encoding: "cbor"}},
+	}
+
+	if err := badgerInvalidEncoding.Validate(); err == nil {
+		t.Fatalf("expected badger encoding validation error")
+	}
+
+	postgresInvalidTimescale := &Config{
+		Server:  ServerConfig{Port: "7878"},
+		Storage: StorageConfig{Postgres: PostgresConfig{Timescale: "on"}},
+	}
+
+	if err := postgresInvalidTimescale.Validate(); err == nil {
+		t.Fatalf("expected postgres timescale validation error")
+	}
+
+	influxInvalidVersion := &Config{
+		Server:  ServerConfig{Port: "7878"},
+		Storage: StorageConfig{InfluxDB: InfluxDBConfig{Version: "1.8"}},
+	}
+
+	if err := influxInvalidVersion.Validate(); err == nil {
+		t.Fatalf("expected influxdb version validation error")
+	}
+
+	readOnlyWithoutStorage := &Config{
+		Server:  ServerConfig{Port: "7878"},
+		Storage: StorageConfig{Backend: "none", ReadOnly: true},
+	}
+
+	if err := readOnlyWithoutStorage.Validate(); err == nil {
+		t.Fatalf("expected read-only storage validation error")
+	}
+
+	negativeFailureContext := &Config{
+		Server:  ServerConfig{Port: "7878"},
+		Storage: StorageConfig{FailureRetention: FailureRetentionConfig{RetentionDays: 90, Context: models.Duration(-time.Minute)}},
+	}
+
+	if err := negativeFailureContext.Validate(); err == nil {
+		t.Fatalf("expected failure retention validation error")
+	}
+
+	invalidSLO := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Monitoring: MonitoringConfig{
+			Groups: []models.MonitorGroup{
+				{
+					Name: "group-a",
+					Monitors: []models.Monitor{
+						{Type: models.MonitorTypeHTTP, Name: "web", URL: "https://example.com", SLO: 999},
+					},
+				},
+			},
+		},
+	}
+
+	if err := invalidSLO.Validate(); err == nil {
+		t.Fatalf("expected slo validation error")
+	}
+
+	reportWithoutDelivery := &Config{
+		Server:  ServerConfig{Port: "7878"},
+		Reports: ReportsConfig{Schedules: []ReportScheduleConfig{{Name: "weekly", Cron: "@weekly"}}},
+	}
+
+	if err := reportWithoutDelivery.Validate(); err == nil {
+		t.Fatalf("expected report delivery validation error")
+	}
+
+	reportEmailWithoutSMTP := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Reports: ReportsConfig{Schedules: []ReportScheduleConfig{
+			{Name: "weekly", Cron: "@weekly", Email: []string{"ops@example.com"}},
+		}},
+	}
+
+	if err := reportEmailWithoutSMTP.Validate(); err == nil {
+		t.Fatalf("expected report smtp validation error")
+	}
+
+	reportInvalidPeriod := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Reports: ReportsConfig{Schedules: []ReportScheduleConfig{
+			{Name: "weekly", Cron: "@weekly", Period: "week", WebhookURL: "https://chat.example.com/hook"},
+		}},
+	}
+
+	if err := reportInvalidPeriod.Validate(); err == nil {
+		t.Fatalf("expected report period validation error")
+	}
+}
+
+func TestValidateTenants(t *testing.T) {
+	groups := func(tenant string) MonitoringConfig {
+		return MonitoringConfig{Groups: []models.MonitorGroup{{
+			Name:     "core",
+			Tenant:   tenant,
+			Monitors: []models.Monitor{{Type: models.MonitorTypeTCP, Name: "ssh", Target: "localhost:22"}},
+		}}}
+	}
+
+	tests := []struct {
+		name        string
+		adminTokens []string
+		tenants     []TenantConfig
+		monitoring  MonitoringConfig
+		wantErr     bool
+	}{
+		{
+			name:        "valid",
+			adminTokens: []string{"admin"},
+			tenants:     []TenantConfig{{Name: "acme", Tokens: []string{"a"}}, {Name: "globex", Tokens: []string{"g"}}},
+			monitoring:  groups("acme"),
+		},
+		{
+			name:    "invalid name",
+			tenants: []TenantConfig{{Name: "acme corp", Tokens: []string{"a"}}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate name",
+			tenants: []TenantConfig{{Name: "acme", Tokens: []string{"a"}}, {Name: "acme", Tokens: []string{"b"}}},
+			wantErr: true,
+		},
+		{
+			name:    "no tokens",
+			tenants: []TenantConfig{{Name: "acme"}},
+			wantErr: true,
+		},
+		{
+			name:    "token shared between tenants",
+			tenants: []TenantConfig{{Name: "acme", Tokens: []string{"a"}}, {Name: "globex", Tokens: []string{"a"}}},
+			wantErr: true,
+		},
+		{
+			name:        "token shared with admin",
+			adminTokens: []string{"a"},
+			tenants:     []TenantConfig{{Name: "acme", Tokens: []string{"a"}}},
+			wantErr:     true,
+		},
+		{
+			name:        "empty admin token",
+			adminTokens: []string{""},
+			wantErr:     true,
+		},
+		{
+			name:       "unknown group tenant",
+			tenants:    []TenantConfig{{Name: "acme", Tokens: []string{"a"}}},
+			monitoring: groups("globex"),
+			wantErr:    true,
+		},
+		{
+			name:    "monitor name reused by another tenant",
+			tenants: []TenantConfig{{Name: "acme", Tokens: []string{"a"}}, {Name: "globex", Tokens: []string{"g"}}},
+			monitoring: MonitoringConfig{Groups: append(groups("acme").Groups, models.MonitorGroup{
+				Name:     "edge",
+				Tenant:   "globex",
+				Monitors: []models.Monitor{{Type: models.MonitorTypeTCP, Name: "ssh", Target: "localhost:2222"}},
+			})},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:     ServerConfig{Port: "7878", AdminTokens: tt.adminTokens},
+				Tenants:    tt.tenants,
+				Monitoring: tt.monitoring,
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateAccounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		accounts AccountsConfig
+		wantErr  bool
+	}{
+		{name: "disabled", accounts: AccountsConfig{}},
+		{name: "valid", accounts: AccountsConfig{Enabled: true, SessionTTL: models.Duration(time.Hour), InitialAdmin: InitialAdminConfig{Username: "admin", Password: "correct horse"}}},
+		{name: "negative session TTL", accounts: AccountsConfig{Enabled: true, SessionTTL: models.Duration(-time.Hour)}, wantErr: true},
+		{name: "admin without username", accounts: AccountsConfig{Enabled: true, InitialAdmin: InitialAdminConfig{Password: "correct horse"}}, wantErr: true},
+		{name: "admin with short password", accounts: AccountsConfig{Enabled: true, InitialAdmin: InitialAdminConfig{Username: "admin", Password: "short"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878", Accounts: tt.accounts}}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMetrics(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics MetricsConfig
+		wantErr bool
+	}{
+		{name: "default", metrics: MetricsConfig{Enabled: true, Path: "/metrics"}},
+		{name: "separate listener", metrics: MetricsConfig{Listen: "127.0.0.1:9090"}},
+		{name: "all interfaces", metrics: MetricsConfig{Listen: ":9090"}},
+		{name: "listener without port", metrics: MetricsConfig{Listen: "127.0.0.1"}, wantErr: true},
+		{name: "basic auth", metrics: MetricsConfig{BasicAuth: MetricsBasicAuthConfig{Username: "prometheus", Password: "scrape"}}},
+		{name: "basic auth without password", metrics: MetricsConfig{BasicAuth: MetricsBasicAuthConfig{Username: "prometheus"}}, wantErr: true},
+		{name: "mutual TLS", metrics: MetricsConfig{Listen: ":9090", TLS: MetricsTLSConfig{CertFile: "metrics.crt", KeyFile: "metrics.key", ClientCAFile: "ca.crt"}}},
+		{name: "TLS on the dashboard listener", metrics: MetricsConfig{TLS: MetricsTLSConfig{CertFile: "metrics.crt", KeyFile: "metrics.key"}}, wantErr: true},
+		{name: "client CA without certificate", metrics: MetricsConfig{Listen: ":9090", TLS: MetricsTLSConfig{ClientCAFile: "ca.crt"}}, wantErr: true},
+		{name: "push", metrics: MetricsConfig{Push: MetricsPushConfig{Enabled: true, URL: "https://pushgateway.example.com", Interval: time.Minute}}},
+		{name: "push without URL", metrics: MetricsConfig{Push: MetricsPushConfig{Enabled: true}}, wantErr: true},
+		{name: "push to a non-HTTP URL", metrics: MetricsConfig{Push: MetricsPushConfig{Enabled: true, URL: "tcp://pushgateway:9091"}}, wantErr: true},
+		{name: "push password without username", metrics: MetricsConfig{Push: MetricsPushConfig{Enabled: true, URL: "http://pushgateway:9091", Password: "secret"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878"}, Metrics: tt.metrics}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateServerNetworks(t *testing.T) {
+	tests := []struct {
+		name           string
+		allowlist      []string
+		trustedProxies []string
+		wantErr        bool
+	}{
+		{name: "cidrs and addresses", allowlist: []string{"10.0.0.0/8", "192.0.2.10", "2001:db8::/32"}},
+		{name: "hostname", allowlist: []string{"admin.example.com"}, wantErr: true},
+		{name: "bad cidr", allowlist: []string{"10.0.0.0/33"}, wantErr: true},
+		{name: "trusted proxies", trustedProxies: []string{"127.0.0.1", "172.16.0.0/12"}},
+		{name: "trusted proxy hostname", trustedProxies: []string{"traefik"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878", AdminAllowlist: tt.allowlist, TrustedProxies: tt.trustedProxies}}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSocketMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{mode: ""},
+		{mode: "0660"},
+		{mode: "600"},
+		{mode: "0888", wantErr: true},
+		{mode: "01777", wantErr: true},
+		{mode: "rw-rw----", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878", Socket: "/run/hallmonitor/http.sock", SocketMode: tt.mode}}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateShutdownGracePeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		grace   models.Duration
+		wantErr bool
+	}{
+		{name: "default", grace: models.Duration(15 * time.Second)},
+		{name: "wait indefinitely", grace: 0},
+		{name: "negative", grace: models.Duration(-time.Second), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878", ShutdownGracePeriod: tt.grace}}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLocale(t *testing.T) {
+	tests := []struct {
+		locale  string
+		wantErr bool
+	}{
+		{locale: ""},
+		{locale: "de"},
+		{locale: "ES"},
+		{locale: "de-AT", wantErr: true},
+		{locale: "ja", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878", Locale: tt.locale}}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateGraphite(t *testing.T) {
+	tests := []struct {
+		name     string
+		graphite GraphiteConfig
+		wantErr  bool
+	}{
+		{name: "disabled", graphite: GraphiteConfig{}},
+		{name: "valid", graphite: GraphiteConfig{Enabled: true, Host: "carbon", Port: 2003, Interval: time.Minute}},
+		{name: "no host", graphite: GraphiteConfig{Enabled: true}, wantErr: true},
+		{name: "bad port", graphite: GraphiteConfig{Enabled: true, Host: "carbon", Port: 70000}, wantErr: true},
+		{name: "negative interval", graphite: GraphiteConfig{Enabled: true, Host: "carbon", Interval: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878"}, Graphite: tt.graphite}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateZabbix(t *testing.T) {
+	tests := []struct {
+		name    string
+		zabbix  ZabbixConfig
+		wantErr bool
+	}{
+		{name: "disabled", zabbix: ZabbixConfig{}},
+		{name: "valid", zabbix: ZabbixConfig{Enabled: true, Server: "zabbix.example.com:10051", Host: "hallmonitor"}},
+		{name: "no server", zabbix: ZabbixConfig{Enabled: true}, wantErr: true},
+		{name: "negative interval", zabbix: ZabbixConfig{Enabled: true, Server: "zabbix", Interval: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878"}, Zabbix: tt.zabbix}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateNagios(t *testing.T) {
+	tests := []struct {
+		name    string
+		nagios  NagiosConfig
+		wantErr bool
+	}{
+		{name: "disabled", nagios: NagiosConfig{}},
+		{name: "command file", nagios: NagiosConfig{Enabled: true, CommandFile: "/var/lib/nagios/rw/nagios.cmd"}},
+		{name: "nrdp", nagios: NagiosConfig{Enabled: true, NRDP: NRDPConfig{URL: "https://nagios.example.com/nrdp/", Token: "secret"}}},
+		{name: "no destination", nagios: NagiosConfig{Enabled: true}, wantErr: true},
+		{name: "both destinations", nagios: NagiosConfig{Enabled: true, CommandFile: "nagios.cmd", NRDP: NRDPConfig{URL: "https://nagios.example.com/nrdp/", Token: "secret"}}, wantErr: true},
+		{name: "nrdp without token", nagios: NagiosConfig{Enabled: true, NRDP: NRDPConfig{URL: "https://nagios.example.com/nrdp/"}}, wantErr: true},
+		{name: "nrdp with bad URL", nagios: NagiosConfig{Enabled: true, NRDP: NRDPConfig{URL: "nagios.example.com", Token: "secret"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Server: ServerConfig{Port: "7878"}, Nagios: tt.nagios}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		webhook WebhookConfig
+		wantErr bool
+	}{
+		{name: "all events", webhook: WebhookConfig{URL: "https://hooks.example.com/x"}},
+		{name: "digest", webhook: WebhookConfig{URL: "https://hooks.example.com/x", Events: []string{"down", "Recovered"}, GroupWindow: 30 * time.Second}},
+		{name: "missing url", webhook: WebhookConfig{Events: []string{"down"}}, wantErr: true},
+		{name: "target changes", webhook: WebhookConfig{URL: "https://hooks.example.com/x", Events: []string{"target_changed"}}},
+		{name: "unknown event", webhook: WebhookConfig{URL: "https://hooks.example.com/x", Events: []string{"flapping"}}, wantErr: true},
+		{name: "negative window", webhook: WebhookConfig{URL: "https://hooks.example.com/x", GroupWindow: -time.Second}, wantErr: true},
+		{name: "plugin", webhook: WebhookConfig{Plugin: "notifiers/xmpp", Options: map[string]string{"jid": "ops@example.com"}}},
+		{name: "plugin outside pluginDir", webhook: WebhookConfig{Plugin: "/usr/bin/xmpp"}, wantErr: true},
+		{name: "url and plugin", webhook: WebhookConfig{URL: "https://hooks.example.com/x", Plugin: "notifiers/xmpp"}, wantErr: true},
+		{name: "locale", webhook: WebhookConfig{URL: "https://hooks.example.com/x", Locale: "fr"}},
+		{name: "unknown locale", webhook: WebhookConfig{URL: "https://hooks.example.com/x", Locale: "klingon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:     ServerConfig{Port: "7878"},
+				Monitoring: MonitoringConfig{PluginDir: "/opt/hallmonitor/plugins"},
+				Webhooks:   []WebhookConfig{tt.webhook},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidatePinnedKeys(t *testing.T) {
+	pin := "sha256/" + strings.Repeat("A", 43) + "="
+
+	tests := []struct {
+		name    string
+		url     string
+		pins    []string
+		wantErr bool
+	}{
+		{name: "valid pin", url: "https://example.com", pins: []string{pin}},
+		{name: "invalid pin", url: "https://example.com", pins: []string{"sha256/short"}, wantErr: true},
+		{name: "plain http", url: "http://example.com", pins: []string{pin}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server: ServerConfig{Port: "7878"},
+				Monitoring: MonitoringConfig{Groups: []models.MonitorGroup{{
+					Name:     "web",
+					Monitors: []models.Monitor{{Type: models.MonitorTypeHTTP, Name: "site", URL: tt.url, PinnedKeys: tt.pins}},
+				}}},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateRunbookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		runbook string
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "https", runbook: "https://wiki.example.com/runbooks/site"},
+		{name: "relative", runbook: "/runbooks/site", wantErr: true},
+		{name: "script", runbook: "javascript:alert(1)", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server: ServerConfig{Port: "7878"},
+				Monitoring: MonitoringConfig{Groups: []models.MonitorGroup{{
+					Name:     "web",
+					Monitors: []models.Monitor{{Type: models.MonitorTypeHTTP, Name: "site", URL: "https://example.com", Owner: "alice", RunbookURL: tt.runbook}},
+				}}},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSampleRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		interval   time.Duration
+		sampleRate int
+		backend    string
+		wantErr    bool
+	}{
+		{name: "unsampled", interval: time.Minute},
+		{name: "fast", interval: 2 * time.Second, sampleRate: 10},
+		{name: "default interval", sampleRate: 10, wantErr: true},
+		{name: "slow", interval: 10 * time.Second, sampleRate: 10, wantErr: true},
+		{name: "negative", interval: 2 * time.Second, sampleRate: -1, wantErr: true},
+		{name: "influxdb", interval: 2 * time.Second, sampleRate: 10, backend: "influxdb", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:  ServerConfig{Port: "7878"},
+				Storage: StorageConfig{Backend: tt.backend},
+				Monitoring: MonitoringConfig{
+					DefaultInterval: models.Duration(30 * time.Second),
+					Groups: []models.MonitorGroup{{
+						Name: "web",
+						Monitors: []models.Monitor{{
+							Type:       models.MonitorTypeHTTP,
+							Name:       "site",
+							URL:        "https://example.com",
+							Interval:   models.Duration(tt.interval),
+							SampleRate: tt.sampleRate,
+						}},
+					}},
+				},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCORS(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  ServerConfig
+		wantErr bool
+	}{
+		{name: "legacy origins", server: ServerConfig{CORSOrigins: []string{"http://localhost:3000"}}},
+		{name: "policy", server: ServerConfig{CORS: CORSConfig{
+			AllowOrigins:     []string{"https://status.example.com", "https://*.example.org"},
+			AllowMethods:     []string{"GET", "POST"},
+			AllowCredentials: true,
+			MaxAge:           models.Duration(time.Hour),
+		}}},
+		{name: "any origin", server: ServerConfig{CORS: CORSConfig{AllowOrigins: []string{"*"}}}},
+		{name: "credentials with any origin", server: ServerConfig{CORS: CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}}, wantErr: true},
+		{name: "credentials without origins", server: ServerConfig{CORS: CORSConfig{AllowCredentials: true}}, wantErr: true},
+		{name: "origin without scheme", server: ServerConfig{CORS: CORSConfig{AllowOrigins: []string{"example.com"}}}, wantErr: true},
+		{name: "origin with path", server: ServerConfig{CORS: CORSConfig{AllowOrigins: []string{"https://example.com/app"}}}, wantErr: true},
+		{name: "wildcard inside host", server: ServerConfig{CORS: CORSConfig{AllowOrigins: []string{"https://app.*.example.com"}}}, wantErr: true},
+		{name: "lowercase method", server: ServerConfig{CORS: CORSConfig{AllowMethods: []string{"get"}}}, wantErr: true},
+		{name: "negative max age", server: ServerConfig{CORS: CORSConfig{MaxAge: models.Duration(-time.Second)}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.Port = "7878"
+			cfg := &Config{Server: tt.server}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateMonitorName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "api", wantErr: false},
+		{name: "db:primary:5432", wantErr: false},
+		{name: "ünïcödé ${x} 'quoted'", wantErr: false},
+		{name: strings.Repeat("x", maxMonitorNameLength), wantErr: false},
+		{name: strings.Repeat("x", maxMonitorNameLength+1), wantErr: true},
+		{name: "line\nbreak", wantErr: true},
+		{name: "tab\there", wantErr: true},
+		{name: "bad\xffutf8", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server: ServerConfig{Port: "7878"},
+				Monitoring: MonitoringConfig{
+					Groups: []models.MonitorGroup{{
+						Name:     "group",
+						Monitors: []models.Monitor{{Type: models.MonitorTypeTCP, Name: tt.name, Target: "localhost:22"}},
+					}},
+				},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPrepareMonitor(t *testing.T) {
+	cfg := &Config{
+		Monitoring: MonitoringConfig{
+			DefaultInterval: models.Duration(30 * time.Second),
+			DefaultTimeout:  models.Duration(10 * time.Second),
+			Groups: []models.MonitorGroup{{
+				Name:     "core",
+				Interval: models.Duration(time.Minute),
+				Timeout:  models.Duration(5 * time.Second),
+				Headers:  map[string]string{"X-Team": "core"},
+			}},
+		},
+	}
+
+	monitor, err := cfg.PrepareMonitor("core", models.Monitor{Type: models.MonitorTypeHTTP, Name: "draft", URL: "https://example.com"})
+	if err != nil {
+		t.Fatalf("PrepareMonitor() error = %v", err)
+	}
+	if monitor.Interval.ToDuration() != time.Minute || monitor.Timeout.ToDuration() != 5*time.Second {
+		t.Errorf("expected the group's interval and timeout, got %v and %v", monitor.Interval, monitor.Timeout)
+	}
+	if monitor.Headers["X-Team"] != "core" {
+		t.Errorf("expected the group's headers, got %v", monitor.Headers)
+	}
+	if monitor.Enabled == nil || !*monitor.Enabled {
+		t.Errorf("expected the monitor to default to enabled")
+	}
+
+	monitor, err = cfg.PrepareMonitor("new", models.Monitor{Type: models.MonitorTypeTCP, Name: "draft", Target: "localhost:22"})
+	if err != nil {
+		t.Fatalf("PrepareMonitor() error = %v", err)
+	}
+	if monitor.Timeout.ToDuration() != 10*time.Second {
+		t.Errorf("expected the global timeout for an unknown group, got %v", monitor.Timeout)
+	}
+
+	if _, err := cfg.PrepareMonitor("core", models.Monitor{Type: models.MonitorTypeHTTP, Name: "draft"}); err == nil {
+		t.Error("expected an error for an http monitor without url")
+	}
+	if _, err := cfg.PrepareMonitor("core", models.Monitor{Type: models.MonitorTypeTCP, Target: "localhost:22"}); err == nil {
+		t.Error("expected an error for a monitor without name")
+	}
+}
+
+func TestParseConfigCustomMonitorType(t *testing.T) {
+	data := []byte(`
+monitoring:
+  groups:
+    - name: "jobs"
+      monitors:
+        - type: "queue-depth"
+          name: "orders"
+          options:
+            queue: "orders"
+`)
+
+	cfg, err := ParseConfig(data, "")
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	monitor := cfg.Monitoring.Groups[0].Monitors[0]
+	if monitor.Options["queue"] != "orders" || monitor.Interval == 0 {
+		t.Errorf("unexpected monitor %+v", monitor)
+	}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected unregistered monitor type to be rejected")
+	}
+
+	RegisterMonitorType("queue-depth")
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestValidatePlugin(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name      string
+		pluginDir string
+		plugin    string
+		wantErr   bool
+	}{
+		{name: "without pluginDir", plugin: "checks/queue", wantErr: true},
+		{name: "missing plugin", pluginDir: dir, plugin: "", wantErr: true},
+		{name: "inside pluginDir", pluginDir: dir, plugin: filepath.Join("checks", "queue"), wantErr: false},
+		{name: "absolute path", pluginDir: dir, plugin: filepath.Join(dir, "checks", "queue"), wantErr: true},
+		{name: "escapes pluginDir", pluginDir: dir, plugin: filepath.Join("checks", "..", "..", "queue"), wantErr: true},
+		{name: "pluginDir itself", pluginDir: dir, plugin: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server: ServerConfig{Port: "7878"},
+				Monitoring: MonitoringConfig{
+					PluginDir: tt.pluginDir,
+					Groups: []models.MonitorGroup{{
+						Name:     "group",
+						Monitors: []models.Monitor{{Type: models.MonitorTypePlugin, Name: "queue", Plugin: tt.plugin}},
+					}},
+				},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateWasm(t *testing.T) {
+	tests := []struct {
+		name    string
+		monitor models.Monitor
+		wantErr bool
+	}{
+		{name: "module", monitor: models.Monitor{Module: "./checks/queue.wasm"}, wantErr: false},
+		{name: "limits", monitor: models.Monitor{Module: "./checks/queue.wasm", Limits: &models.WasmLimits{MemoryMB: 16, Fuel: 1000}}, wantErr: false},
+		{name: "missing module", monitor: models.Monitor{}, wantErr: true},
+		{name: "negative memory", monitor: models.Monitor{Module: "./checks/queue.wasm", Limits: &models.WasmLimits{MemoryMB: -1}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor := tt.monitor
+			monitor.Type = models.MonitorTypeWasm
+			monitor.Name = "queue"
+			cfg := &Config{
+				Server: ServerConfig{Port: "7878"},
+				Monitoring: MonitoringConfig{
+					Groups: []models.MonitorGroup{{Name: "group", Monitors: []models.Monitor{monitor}}},
+				},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateResultHooks(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name    string
+		hook    ResultHookConfig
+		wantErr bool
+	}{
+		{name: "labels", hook: ResultHookConfig{SetLabels: map[string]string{"team": "core"}}, wantErr: false},
+		{name: "glob match", hook: ResultHookConfig{Match: ResultHookMatch{Monitors: []string{"api-*"}}}, wantErr: false},
+		{name: "bad glob", hook: ResultHookConfig{Match: ResultHookMatch{Groups: []string{"[core"}}}, wantErr: true},
+		{name: "bad status", hook: ResultHookConfig{Match: ResultHookMatch{Statuses: []string{"degraded"}}}, wantErr: true},
+		{name: "nested field", hook: ResultHookConfig{DropFields: []string{"http_result.headers"}}, wantErr: false},
+		{name: "identity field", hook: ResultHookConfig{DropFields: []string{"status"}}, wantErr: true},
+		{name: "empty field", hook: ResultHookConfig{DropFields: []string{"metadata."}}, wantErr: true},
+		{name: "plugin inside pluginDir", hook: ResultHookConfig{Plugin: "geoip"}, wantErr: false},
+		{name: "plugin outside pluginDir", hook: ResultHookConfig{Plugin: "/bin/sh"}, wantErr: true},
+		{name: "plugin escapes pluginDir", hook: ResultHookConfig{Plugin: "../geoip"}, wantErr: true},
+		{name: "negative timeout", hook: ResultHookConfig{Timeout: -time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{
+				Server:      ServerConfig{Port: "7878"},
+				Monitoring:  MonitoringConfig{PluginDir: dir},
+				ResultHooks: []ResultHookConfig{tt.hook},
+			}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
 }
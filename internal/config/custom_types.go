@@ -0,0 +1,23 @@
+package config
+
+import (
+	"sync"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// customMonitorTypes are monitor types implemented outside this module
+var customMonitorTypes sync.Map
+
+// RegisterMonitorType lets monitors of a custom type pass validation. Their
+// settings are checked by the implementation when the monitor is created.
+func RegisterMonitorType(monitorType models.MonitorType) {
+	customMonitorTypes.Store(monitorType, true)
+}
+
+// IsCustomMonitorType reports whether monitorType was registered with
+// RegisterMonitorType
+func IsCustomMonitorType(monitorType models.MonitorType) bool {
+	_, ok := customMonitorTypes.Load(monitorType)
+	return ok
+}
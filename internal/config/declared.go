@@ -0,0 +1,288 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// zeroableSettings are the monitor settings a group default fills in whose
+// zero is a meaningful value, by YAML key. A zero written for one of them in
+// the file or in a profile override is kept instead of replaced by the
+// group's default.
+var zeroableSettings = map[string]func(*models.Monitor) *int{
+	"expectedStatus":    func(m *models.Monitor) *int { return &m.ExpectedStatus },
+	"retries":           func(m *models.Monitor) *int { return &m.Retries },
+	"recoveryThreshold": func(m *models.Monitor) *int { return &m.RecoveryThreshold },
+}
+
+// declaredMonitor is a monitor as written in the config file, before profile
+// overrides and group defaults were applied, together with the form it was
+// loaded as
+type declaredMonitor struct {
+	written models.Monitor
+	loaded  models.Monitor
+
+	// explicit holds the zeroableSettings set in the file or a profile
+	explicit map[string]bool
+}
+
+// declaredGroup is a group as written in the config file, before the global
+// defaults were applied, together with the form it was loaded as. Monitors
+// are tracked separately.
+type declaredGroup struct {
+	written models.MonitorGroup
+	loaded  models.MonitorGroup
+}
+
+// recordWritten remembers groups and monitors as they are written in the
+// file. raw is the monitoring.groups setting as read, which tells settings
+// written as zero apart from settings left out.
+func (c *Config) recordWritten(raw interface{}) {
+	explicit := explicitSettings(raw)
+	c.declared = make(map[string]declaredMonitor)
+	c.declaredGroups = make(map[string]declaredGroup)
+	for _, group := range c.Monitoring.Groups {
+		for _, monitor := range group.Monitors {
+			c.declared[monitor.Name] = declaredMonitor{written: monitor, explicit: explicit[monitor.Name]}
+		}
+		group.Monitors = nil
+		c.declaredGroups[group.Name] = declaredGroup{written: group}
+	}
+}
+
+// explicitSettings returns the zeroableSettings each monitor of raw groups
+// sets, by monitor name
+func explicitSettings(raw interface{}) map[string]map[string]bool {
+	explicit := make(map[string]map[string]bool)
+	groups, _ := raw.([]interface{})
+	for _, group := range groups {
+		groupSettings, _ := group.(map[string]interface{})
+		monitors, _ := groupSettings["monitors"].([]interface{})
+		for _, monitor := range monitors {
+			settings, _ := monitor.(map[string]interface{})
+			name, _ := settings["name"].(string)
+			for key := range settings {
+				for setting := range zeroableSettings {
+					// Keys are lower case once read
+					if strings.EqualFold(key, setting) {
+						if explicit[name] == nil {
+							explicit[name] = make(map[string]bool)
+						}
+						explicit[name][setting] = true
+					}
+				}
+			}
+		}
+	}
+	return explicit
+}
+
+// setExplicit records that a profile override sets one of a monitor's
+// zeroableSettings
+func (c *Config) setExplicit(monitor, setting string) {
+	declared, ok := c.declared[monitor]
+	if !ok {
+		return
+	}
+	if declared.explicit == nil {
+		declared.explicit = make(map[string]bool)
+	}
+	declared.explicit[setting] = true
+	c.declared[monitor] = declared
+}
+
+// explicitFor returns the zeroableSettings a monitor sets explicitly
+func (c *Config) explicitFor(monitor string) map[string]bool {
+	return c.declared[monitor].explicit
+}
+
+// recordLoaded remembers groups and monitors as they were loaded, so
+// WriteConfig can tell which settings changed since
+func (c *Config) recordLoaded() {
+	for _, group := range c.Monitoring.Groups {
+		for _, monitor := range group.Monitors {
+			if declared, ok := c.declared[monitor.Name]; ok {
+				declared.loaded = monitor
+				c.declared[monitor.Name] = declared
+			}
+		}
+		if declared, ok := c.declaredGroups[group.Name]; ok {
+			group.Monitors = nil
+			declared.loaded = group
+			c.declaredGroups[group.Name] = declared
+		}
+	}
+}
+
+// asWritten returns a group as it should be written. Settings filled in at
+// load that are unchanged since keep their file form, so inherited defaults
+// and profile overrides are not written out; settings changed since are
+// written as they are now.
+func (c *Config) asWritten(group models.MonitorGroup) models.MonitorGroup {
+	monitors := make([]models.Monitor, len(group.Monitors))
+	for i, monitor := range group.Monitors {
+		if declared, ok := c.declared[monitor.Name]; ok {
+			declared.restore(&monitor)
+		}
+		monitors[i] = monitor
+	}
+
+	if declared, ok := c.declaredGroups[group.Name]; ok {
+		keep(&group.Interval, declared.loaded.Interval, declared.written.Interval)
+		keep(&group.Timeout, declared.loaded.Timeout, declared.written.Timeout)
+		keep(&group.SSLCertExpiryWarningDays, declared.loaded.SSLCertExpiryWarningDays, declared.written.SSLCertExpiryWarningDays)
+	}
+	group.Monitors = monitors
+	return group
+}
+
+// restore puts back the written values of the settings group defaults and
+// profile overrides fill in, where monitor still has the loaded value
+func (d declaredMonitor) restore(monitor *models.Monitor) {
+	loaded, written := &d.loaded, &d.written
+
+	// Group defaults
+	keep(&monitor.Interval, loaded.Interval, written.Interval)
+	keep(&monitor.Timeout, loaded.Timeout, written.Timeout)
+	keep(&monitor.SSLCertExpiryWarningDays, loaded.SSLCertExpiryWarningDays, written.SSLCertExpiryWarningDays)
+	keep(&monitor.ExpectedStatus, loaded.ExpectedStatus, written.ExpectedStatus)
+	keep(&monitor.Retries, loaded.Retries, written.Retries)
+	keep(&monitor.RecoveryThreshold, loaded.RecoveryThreshold, written.RecoveryThreshold)
+	keep(&monitor.SLO, loaded.SLO, written.SLO)
+	if slices.EqualFunc(monitor.QuietHours, loaded.QuietHours, sameQuietHours) {
+		monitor.QuietHours = written.QuietHours
+	}
+	if slices.Equal(monitor.CaptureHeaders, loaded.CaptureHeaders) {
+		monitor.CaptureHeaders = written.CaptureHeaders
+	}
+	if sameBool(monitor.Enabled, loaded.Enabled) {
+		monitor.Enabled = written.Enabled
+	}
+
+	// Group defaults and profile overrides
+	keepMap(&monitor.Labels, loaded.Labels, written.Labels)
+	keepMap(&monitor.Headers, loaded.Headers, written.Headers)
+
+	// Profile overrides only
+	keep(&monitor.Target, loaded.Target, written.Target)
+	keep(&monitor.URL, loaded.URL, written.URL)
+	keep(&monitor.Query, loaded.Query, written.Query)
+	keep(&monitor.Port, loaded.Port, written.Port)
+	keepMap(&monitor.Hosts, loaded.Hosts, written.Hosts)
+}
+
+// keep sets *current back to written while it still equals loaded
+func keep[T comparable](current *T, loaded, written T) {
+	if *current == loaded {
+		*current = written
+	}
+}
+
+// keepMap is keep for string maps
+func keepMap(current *map[string]string, loaded, written map[string]string) {
+	if maps.Equal(*current, loaded) {
+		*current = written
+	}
+}
+
+// sameQuietHours reports whether two quiet hours windows are the same
+func sameQuietHours(a, b models.QuietHours) bool {
+	return slices.Equal(a.Days, b.Days) && a.Start == b.Start && a.End == b.End && a.Timezone == b.Timezone && a.Action == b.Action
+}
+
+// sameBool reports whether two optional booleans are both unset or equal
+func sameBool(a, b *bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// writeExplicitZeroes adds the zeroableSettings that are explicitly zero to
+// marshaled config YAML, which leaves zero values out
+func (c *Config) writeExplicitZeroes(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	changed := false
+	for _, group := range sequenceItems(mappingValue(mappingValue(documentRoot(&doc), "monitoring"), "groups")) {
+		for _, node := range sequenceItems(mappingValue(group, "monitors")) {
+			name := mappingValue(node, "name")
+			if name == nil {
+				continue
+			}
+			groupIdx, monitorIdx, found := c.FindMonitor(name.Value)
+			if !found {
+				continue
+			}
+			monitor := &c.Monitoring.Groups[groupIdx].Monitors[monitorIdx]
+			for _, setting := range sortedSettings(c.explicitFor(name.Value)) {
+				if *zeroableSettings[setting](monitor) != 0 || mappingValue(node, setting) != nil {
+					continue
+				}
+				node.Content = append(node.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: setting},
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "0"})
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return data, nil
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return out, nil
+}
+
+// sortedSettings returns the set settings in a stable order
+func sortedSettings(settings map[string]bool) []string {
+	names := make([]string, 0, len(settings))
+	for name, set := range settings {
+		if set {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// documentRoot returns the top-level node of a parsed document
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0]
+	}
+	return nil
+}
+
+// mappingValue returns the value of key in a mapping node, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceItems returns the items of a sequence node, or nil
+func sequenceItems(node *yaml.Node) []*yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// ProfileEnvVar selects a profile when none is given on the command line
+const ProfileEnvVar = "HALLMONITOR_PROFILE"
+
+// profileMonitorsKey holds per-monitor overrides inside a profile; every other
+// key in a profile is merged over the base configuration
+const profileMonitorsKey = "monitors"
+
+// MonitorOverride replaces fields of a base monitor, matched by name, when a
+// profile is active. Zero values leave the base setting unchanged.
+type MonitorOverride struct {
+	Name           string            `yaml:"name" mapstructure:"name"`
+	Target         string            `yaml:"target,omitempty" mapstructure:"target"`
+	URL            string            `yaml:"url,omitempty" mapstructure:"url"`
+	Query          string            `yaml:"query,omitempty" mapstructure:"query"`
+	Port           int               `yaml:"port,omitempty" mapstructure:"port"`
+	Interval       models.Duration   `yaml:"interval,omitempty" mapstructure:"interval"`
+	Timeout        models.Duration   `yaml:"timeout,omitempty" mapstructure:"timeout"`
+	Enabled        *bool             `yaml:"enabled,omitempty" mapstructure:"enabled"`
+	ExpectedStatus *int              `yaml:"expectedStatus,omitempty" mapstructure:"expectedStatus"`
+	Retries        *int              `yaml:"retries,omitempty" mapstructure:"retries"`
+	Headers        map[string]string `yaml:"headers,omitempty" mapstructure:"headers"`
+	Labels         map[string]string `yaml:"labels,omitempty" mapstructure:"labels"`
+	Hosts          map[string]string `yaml:"hosts,omitempty" mapstructure:"hosts"`
+}
+
+// ProfileNames returns the profiles defined in the configuration, sorted
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile merges the named profile over the base configuration held by v
+// and returns its monitor overrides. Profile names are case-insensitive.
+func applyProfile(v *viper.Viper, profile string) ([]MonitorOverride, error) {
+	profiles := v.GetStringMap("profiles")
+	raw, ok := profiles[strings.ToLower(profile)]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown profile %q (available: %s)", profile, strings.Join(names, ", "))
+	}
+
+	settings, ok := raw.(map[string]interface{})
+	if !ok {
+		if raw == nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("profile %q must be a mapping", profile)
+	}
+
+	// Copy so the profile itself stays intact for WriteConfig round-trips
+	overlay := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		overlay[key] = value
+	}
+
+	var overrides []MonitorOverride
+	if monitors, ok := overlay[profileMonitorsKey]; ok {
+		delete(overlay, profileMonitorsKey)
+
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       stringToDurationHookFunc(),
+			WeaklyTypedInput: true,
+			Result:           &overrides,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create decoder: %w", err)
+		}
+		if err := decoder.Decode(monitors); err != nil {
+			return nil, fmt.Errorf("invalid monitors in profile %q: %w", profile, err)
+		}
+	}
+
+	if err := v.MergeConfigMap(overlay); err != nil {
+		return nil, fmt.Errorf("failed to apply profile %q: %w", profile, err)
+	}
+
+	return overrides, nil
+}
+
+// applyMonitorOverrides updates base monitors with profile overrides
+func applyMonitorOverrides(cfg *Config, overrides []MonitorOverride) error {
+	for _, override := range overrides {
+		if override.Name == "" {
+			return fmt.Errorf("profile %q has a monitor override without a name", cfg.Profile)
+		}
+
+		groupIdx, monitorIdx, found := cfg.FindMonitor(override.Name)
+		if !found {
+			return fmt.Errorf("profile %q overrides unknown monitor %q", cfg.Profile, override.Name)
+		}
+		override.apply(&cfg.Monitoring.Groups[groupIdx].Monitors[monitorIdx])
+		if override.ExpectedStatus != nil {
+			cfg.setExplicit(override.Name, "expectedStatus")
+		}
+		if override.Retries != nil {
+			cfg.setExplicit(override.Name, "retries")
+		}
+	}
+	return nil
+}
+
+// apply copies the non-zero override fields onto monitor
+func (o MonitorOverride) apply(monitor *models.Monitor) {
+	if o.Target != "" {
+		monitor.Target = o.Target
+	}
+	if o.URL != "" {
+		monitor.URL = o.URL
+	}
+	if o.Query != "" {
+		monitor.Query = o.Query
+	}
+	if o.Port != 0 {
+		monitor.Port = o.Port
+	}
+	if o.Interval != 0 {
+		monitor.Interval = o.Interval
+	}
+	if o.Timeout != 0 {
+		monitor.Timeout = o.Timeout
+	}
+	if o.Enabled != nil {
+		enabled := *o.Enabled
+		monitor.Enabled = &enabled
+	}
+	if o.ExpectedStatus != nil {
+		monitor.ExpectedStatus = *o.ExpectedStatus
+	}
+	if o.Retries != nil {
+		monitor.Retries = *o.Retries
+	}
+	monitor.Headers = mergeStringMaps(monitor.Headers, o.Headers)
+	monitor.Labels = mergeStringMaps(monitor.Labels, o.Labels)
+	monitor.Hosts = mergeStringMaps(monitor.Hosts, o.Hosts)
+}
+
+// mergeStringMaps returns a new map of base with overrides applied
+func mergeStringMaps(base, overrides map[string]string) map[string]string {
+	if len(base) == 0 && len(overrides) == 0 {
+		return overrides
+	}
+	merged := make(map[string]string, len(base)+len(overrides))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		merged[key] = value
+	}
+	return merged
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profileConfigYAML = `
+server:
+  port: "7878"
+monitoring:
+  defaultInterval: "30s"
+  groups:
+    - name: "web"
+      retries: 3
+      monitors:
+        - type: "http"
+          name: "API"
+          url: "https://api.example.com/health"
+          headers:
+            Accept: "application/json"
+        - type: "tcp"
+          name: "db"
+          target: "db.internal:5432"
+profiles:
+  staging:
+    server:
+      port: "8080"
+    monitoring:
+      defaultInterval: "2m"
+    monitors:
+      - name: "API"
+        url: "https://api.staging.example.com/health"
+        interval: "1m"
+        retries: 0
+        headers:
+          X-Env: "staging"
+      - name: "db"
+        enabled: false
+  dev:
+    server:
+      port: "9090"
+`
+
+func TestLoadConfigWithProfile(t *testing.T) {
+	path := writeTempConfig(t, profileConfigYAML)
+
+	cfg, err := LoadConfigWithProfile(path, "Staging")
+	if err != nil {
+		t.Fatalf("LoadConfigWithProfile returned error: %v", err)
+	}
+
+	if cfg.Profile != "Staging" {
+		t.Errorf("expected active profile Staging, got %q", cfg.Profile)
+	}
+	if cfg.Server.Port != "8080" {
+		t.Errorf("expected profile server port 8080, got %s", cfg.Server.Port)
+	}
+
+	api := cfg.Monitoring.Groups[0].Monitors[0]
+	if api.URL != "https://api.staging.example.com/health" {
+		t.Errorf("expected staging url, got %s", api.URL)
+	}
+	if api.Interval.ToDuration() != time.Minute {
+		t.Errorf("expected overridden interval 1m, got %v", api.Interval.ToDuration())
+	}
+	if api.Retries != 0 {
+		t.Errorf("expected overridden retries 0 to beat the group's 3, got %d", api.Retries)
+	}
+	// Viper lowercases map keys; header names are case-insensitive anyway
+	wantHeaders := map[string]string{"accept": "application/json", "x-env": "staging"}
+	if !reflect.DeepEqual(api.Headers, wantHeaders) {
+		t.Errorf("expected merged headers %v, got %v", wantHeaders, api.Headers)
+	}
+
+	db := cfg.Monitoring.Groups[0].Monitors[1]
+	if db.Enabled == nil || *db.Enabled {
+		t.Error("expected db monitor to be disabled by profile")
+	}
+	if db.Interval.ToDuration() != 2*time.Minute {
+		t.Errorf("expected profile default interval 2m for db, got %v", db.Interval.ToDuration())
+	}
+	if db.Retries != 3 {
+		t.Errorf("expected group retries 3 for db, got %d", db.Retries)
+	}
+	if db.Target != "db.internal:5432" {
+		t.Errorf("expected base target to be kept, got %s", db.Target)
+	}
+}
+
+func TestLoadConfigWithoutProfileKeepsBase(t *testing.T) {
+	path := writeTempConfig(t, profileConfigYAML)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	if cfg.Server.Port != "7878" {
+		t.Errorf("expected base port 7878, got %s", cfg.Server.Port)
+	}
+	if got := cfg.Monitoring.Groups[0].Monitors[0].URL; got != "https://api.example.com/health" {
+		t.Errorf("expected base url, got %s", got)
+	}
+	if got := cfg.ProfileNames(); !reflect.DeepEqual(got, []string{"dev", "staging"}) {
+		t.Errorf("unexpected profile names: %v", got)
+	}
+
+	// Profiles must survive a write so API edits keep them
+	out := filepath.Join(t.TempDir(), "config.yml")
+	if err := cfg.WriteConfig(out); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+	reloaded, err := LoadConfigWithProfile(out, "staging")
+	if err != nil {
+		t.Fatalf("LoadConfigWithProfile after write returned error: %v", err)
+	}
+	if got := reloaded.Monitoring.Groups[0].Monitors[0].URL; got != "https://api.staging.example.com/health" {
+		t.Errorf("expected profile to survive write, got url %s", got)
+	}
+}
+
+func TestLoadConfigWithProfileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		profile string
+		wantErr string
+	}{
+		{
+			name:    "unknown profile",
+			yaml:    profileConfigYAML,
+			profile: "prod",
+			wantErr: `unknown profile "prod"`,
+		},
+		{
+			name: "unknown monitor override",
+			yaml: `
+monitoring:
+  groups:
+    - name: "web"
+      monitors:
+        - type: "http"
+          name: "api"
+          url: "https://example.com"
+profiles:
+  prod:
+    monitors:
+      - name: "missing"
+        url: "https://prod.example.com"
+`,
+			profile: "prod",
+			wantErr: `unknown monitor "missing"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTempConfig(t, tt.yaml)
+
+			_, err := LoadConfigWithProfile(path, tt.profile)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,345 @@
+package config
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/internal/secrets"
+)
+
+// Environment variables holding the key that decrypts config secrets
+const (
+	SecretKeyEnvVar     = "HALLMONITOR_SECRET_KEY"
+	SecretKeyFileEnvVar = "HALLMONITOR_SECRET_KEY_FILE"
+)
+
+// secretPrefix marks an encrypted secret: AES-256-GCM, with the nonce before
+// the ciphertext, base64 encoded
+const secretPrefix = "enc:v1:"
+
+// secretKeySize is the AES-256 key length in bytes
+const secretKeySize = 32
+
+// minSecretMatch is the shortest secret value WriteConfig replaces inside
+// other strings; shorter values would match unrelated text
+const minSecretMatch = 8
+
+// secretScheme refers to the config's own secrets block
+const secretScheme = "secret"
+
+// secretRefPattern matches ${secret:name} references to the secrets block,
+// and ${vault:...}, ${aws-sm:...}, and ${gcp-sm:...} references to secret
+// stores, in config strings
+var secretRefPattern = regexp.MustCompile(`\$\{(secret|vault|aws-sm|gcp-sm):([^}]+)\}`)
+
+// secretNamePattern restricts the names of the secrets block
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// secretResolver fetches references to secret stores
+var secretResolver = secrets.Default
+
+// secretFreeFields are config fields that are never resolved. Templates and
+// profiles keep their references and are resolved where they are applied.
+var secretFreeFields = map[string]bool{
+	"Secrets":   true,
+	"Profiles":  true,
+	"Templates": true,
+}
+
+// secretState remembers how secrets were resolved so WriteConfig can put the
+// references back
+type secretState struct {
+	refs   map[string]string // Resolved string to the string with references it came from
+	values map[string]string // Reference, such as secret:name, to plaintext
+}
+
+// GenerateSecretKey returns a new random key for config secrets, base64 encoded
+func GenerateSecretKey() (string, error) {
+	key := make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("failed to generate key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// LoadSecretKey reads the secrets key from $HALLMONITOR_SECRET_KEY, or from
+// the file named by $HALLMONITOR_SECRET_KEY_FILE
+func LoadSecretKey() ([]byte, error) {
+	encoded := os.Getenv(SecretKeyEnvVar)
+	if encoded == "" {
+		path := os.Getenv(SecretKeyFileEnvVar)
+		if path == "" {
+			return nil, fmt.Errorf("config secrets need a key in $%s or $%s", SecretKeyEnvVar, SecretKeyFileEnvVar)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret key: %w", err)
+		}
+		encoded = string(data)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(key) != secretKeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes, base64 encoded", secretKeySize)
+	}
+	return key, nil
+}
+
+// EncryptSecret encrypts a secret value for the secrets block. The name is
+// bound to the ciphertext, so a value cannot be moved to another secret.
+func EncryptSecret(key []byte, name, plaintext string) (string, error) {
+	gcm, err := secretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), secretAAD(name))
+	return secretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptSecret decrypts a value written by EncryptSecret
+func DecryptSecret(key []byte, name, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, secretPrefix)
+	if !ok {
+		return "", fmt.Errorf("secret %s is not encrypted", name)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("secret %s is not valid base64: %w", name, err)
+	}
+	gcm, err := secretCipher(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("secret %s is truncated", name)
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], secretAAD(name))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret %s: wrong key or corrupted value", name)
+	}
+	return string(plaintext), nil
+}
+
+// secretCipher returns the AES-GCM cipher for key
+func secretCipher(key []byte) (cipher.AEAD, error) {
+	if len(key) != secretKeySize {
+		return nil, fmt.Errorf("secret key must be %d bytes", secretKeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// secretAAD is the additional data sealed with a secret. Names are compared
+// in lower case, as config keys are case-insensitive.
+func secretAAD(name string) []byte {
+	return []byte("hallmonitor-secret:" + strings.ToLower(name))
+}
+
+// resolveSecrets replaces secret references in the config with their
+// values: ${secret:name} with the decrypted value from the secrets block, and
+// references to secret stores with the value fetched from the store. The key
+// is only needed when the secrets block is referenced.
+func (c *Config) resolveSecrets() error {
+	encrypted := make(map[string]string, len(c.Secrets))
+	for name, value := range c.Secrets {
+		if !strings.HasPrefix(value, secretPrefix) {
+			return fmt.Errorf("secret %s is not encrypted; encrypt it with \"hallmonitor secret encrypt\"", name)
+		}
+		encrypted[strings.ToLower(name)] = value
+	}
+
+	var key []byte
+	var resolveErr error
+	state := secretState{refs: make(map[string]string), values: make(map[string]string)}
+	lookup := func(scheme, ref string) (string, error) {
+		if scheme != secretScheme {
+			return secretResolver.Resolve(context.Background(), scheme, ref)
+		}
+		if !secretNamePattern.MatchString(ref) {
+			return "", fmt.Errorf("invalid secret name %q", ref)
+		}
+		value, ok := encrypted[ref]
+		if !ok {
+			return "", fmt.Errorf("undefined secret %q", ref)
+		}
+		if key == nil {
+			var err error
+			if key, err = LoadSecretKey(); err != nil {
+				return "", err
+			}
+		}
+		return DecryptSecret(key, ref, value)
+	}
+	resolve := func(s string) string {
+		if resolveErr != nil || !strings.Contains(s, "${") {
+			return s
+		}
+		resolved := secretRefPattern.ReplaceAllStringFunc(s, func(match string) string {
+			parts := secretRefPattern.FindStringSubmatch(match)
+			scheme, ref := parts[1], parts[2]
+			if scheme == secretScheme {
+				ref = strings.ToLower(ref)
+			}
+			if plaintext, ok := state.values[scheme+":"+ref]; ok {
+				return plaintext
+			}
+			plaintext, err := lookup(scheme, ref)
+			if err != nil {
+				if resolveErr == nil {
+					resolveErr = err
+				}
+				return match
+			}
+			state.values[scheme+":"+ref] = plaintext
+			return plaintext
+		})
+		if resolved != s {
+			state.refs[resolved] = s
+		}
+		return resolved
+	}
+
+	v := reflect.ValueOf(c).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.IsExported() && !secretFreeFields[field.Name] {
+			rewriteStrings(v.Field(i), resolve)
+		}
+	}
+	if resolveErr != nil {
+		return resolveErr
+	}
+	if len(state.refs) > 0 {
+		c.secrets = &state
+	}
+	return nil
+}
+
+// rewriteStrings rewrites every string reachable from v in place
+func rewriteStrings(v reflect.Value, rewrite func(string) string) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(rewrite(v.String()))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				rewriteStrings(v.Field(i), rewrite)
+			}
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			rewriteStrings(v.Elem(), rewrite)
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			return
+		}
+		// Values held by interfaces are not addressable, so rewrite a copy
+		copied := reflect.New(v.Elem().Type()).Elem()
+		copied.Set(v.Elem())
+		rewriteStrings(copied, rewrite)
+		v.Set(copied)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			rewriteStrings(v.Index(i), rewrite)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		for _, key := range v.MapKeys() {
+			copied := reflect.New(v.Type().Elem()).Elem()
+			copied.Set(v.MapIndex(key))
+			rewriteStrings(copied, rewrite)
+			v.SetMapIndex(key, copied)
+		}
+	}
+}
+
+// CopySecrets gives c the secrets of another config, for configs built from
+// API requests, which never carry secrets
+func (c *Config) CopySecrets(from *Config) {
+	c.Secrets = from.Secrets
+	c.secrets = from.secrets
+}
+
+// hideSecrets puts secret references back into marshaled config YAML. Strings
+// that were resolved from references get their references back, and any
+// other string containing a secret value has the value replaced by a
+// reference, so values pasted through the API are not written in plaintext.
+func (s *secretState) hideSecrets(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config for secrets: %w", err)
+	}
+
+	// Longest values first, so a secret containing another is replaced whole
+	refs := make([]string, 0, len(s.values))
+	for ref, value := range s.values {
+		if len(value) >= minSecretMatch {
+			refs = append(refs, ref)
+		}
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if len(s.values[refs[i]]) != len(s.values[refs[j]]) {
+			return len(s.values[refs[i]]) > len(s.values[refs[j]])
+		}
+		return refs[i] < refs[j]
+	})
+
+	var hide func(node *yaml.Node, top bool)
+	hide = func(node *yaml.Node, top bool) {
+		switch node.Kind {
+		case yaml.DocumentNode:
+			for _, child := range node.Content {
+				hide(child, true)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if top && node.Content[i].Value == "secrets" {
+					continue
+				}
+				hide(node.Content[i+1], false)
+			}
+		case yaml.SequenceNode:
+			for _, child := range node.Content {
+				hide(child, false)
+			}
+		case yaml.ScalarNode:
+			if node.Tag != "!!str" {
+				return
+			}
+			if original, ok := s.refs[node.Value]; ok {
+				node.Value = original
+				return
+			}
+			for _, ref := range refs {
+				node.Value = strings.ReplaceAll(node.Value, s.values[ref], "${"+ref+"}")
+			}
+		}
+	}
+	hide(&doc, false)
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,281 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/internal/secrets"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func setSecretKey(t *testing.T) []byte {
+	t.Helper()
+	encoded, err := GenerateSecretKey()
+	if err != nil {
+		t.Fatalf("GenerateSecretKey() error = %v", err)
+	}
+	t.Setenv(SecretKeyEnvVar, encoded)
+	t.Setenv(SecretKeyFileEnvVar, "")
+	key, err := LoadSecretKey()
+	if err != nil {
+		t.Fatalf("LoadSecretKey() error = %v", err)
+	}
+	return key
+}
+
+func secretsConfigYAML(t *testing.T, key []byte) string {
+	t.Helper()
+	token, err := EncryptSecret(key, "apiToken", "tok-0123456789")
+	if err != nil {
+		t.Fatalf("EncryptSecret() error = %v", err)
+	}
+	hook, err := EncryptSecret(key, "hookurl", "https://hooks.example.com/T0/secret-path")
+	if err != nil {
+		t.Fatalf("EncryptSecret() error = %v", err)
+	}
+	return fmt.Sprintf(`
+secrets:
+  apiToken: %q
+  hookurl: %q
+webhooks:
+  - url: "${secret:hookurl}"
+monitoring:
+  groups:
+    - name: core
+      monitors:
+        - name: api
+          type: http
+          url: https://api.example.com/health
+          headers:
+            Authorization: "Bearer ${secret:APITOKEN}"
+`, token, hook)
+}
+
+func TestEncryptSecret(t *testing.T) {
+	key := setSecretKey(t)
+	value, err := EncryptSecret(key, "token", "hunter2")
+	if err != nil {
+		t.Fatalf("EncryptSecret() error = %v", err)
+	}
+	if !strings.HasPrefix(value, secretPrefix) || strings.Contains(value, "hunter2") {
+		t.Fatalf("unexpected encrypted value %q", value)
+	}
+	if again, _ := EncryptSecret(key, "token", "hunter2"); again == value {
+		t.Error("expected a fresh nonce for each encryption")
+	}
+
+	if plaintext, err := DecryptSecret(key, "TOKEN", value); err != nil || plaintext != "hunter2" {
+		t.Errorf("DecryptSecret() = %q, %v", plaintext, err)
+	}
+	if _, err := DecryptSecret(key, "other", value); err == nil {
+		t.Error("expected a value moved to another name not to decrypt")
+	}
+	otherKey := make([]byte, secretKeySize)
+	if _, err := DecryptSecret(otherKey, "token", value); err == nil {
+		t.Error("expected the wrong key to fail")
+	}
+	if _, err := DecryptSecret(key, "token", "hunter2"); err == nil {
+		t.Error("expected plaintext to be rejected")
+	}
+}
+
+func TestLoadSecretKeyFromFile(t *testing.T) {
+	encoded, err := GenerateSecretKey()
+	if err != nil {
+		t.Fatalf("GenerateSecretKey() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "secret.key")
+	if err := os.WriteFile(path, []byte(encoded+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(SecretKeyEnvVar, "")
+	t.Setenv(SecretKeyFileEnvVar, path)
+	if _, err := LoadSecretKey(); err != nil {
+		t.Errorf("LoadSecretKey() error = %v", err)
+	}
+
+	t.Setenv(SecretKeyFileEnvVar, "")
+	if _, err := LoadSecretKey(); err == nil {
+		t.Error("expected an error without a key")
+	}
+	t.Setenv(SecretKeyEnvVar, "c2hvcnQ=")
+	if _, err := LoadSecretKey(); err == nil {
+		t.Error("expected an error for a short key")
+	}
+}
+
+func TestLoadConfigResolvesSecrets(t *testing.T) {
+	key := setSecretKey(t)
+	cfg, err := ParseConfig([]byte(secretsConfigYAML(t, key)), "")
+	if err != nil {
+		t.Fatalf("ParseConfig() error = %v", err)
+	}
+	monitor := cfg.Monitoring.Groups[0].Monitors[0]
+	if got := monitor.Headers["authorization"]; got != "Bearer tok-0123456789" {
+		t.Errorf("expected the header to be resolved, got %q", got)
+	}
+	if got := cfg.Webhooks[0].URL; got != "https://hooks.example.com/T0/secret-path" {
+		t.Errorf("expected the webhook URL to be resolved, got %q", got)
+	}
+}
+
+func TestLoadConfigSecretErrors(t *testing.T) {
+	key := setSecretKey(t)
+	valid := secretsConfigYAML(t, key)
+
+	tests := []struct {
+		name    string
+		yaml    string
+		setup   func(t *testing.T)
+		wantErr string
+	}{
+		{
+			name:    "undefined secret",
+			yaml:    strings.Replace(valid, "${secret:hookurl}", "${secret:missing}", 1),
+			wantErr: `undefined secret "missing"`,
+		},
+		{
+			name:    "plaintext secret",
+			yaml:    "secrets:\n  token: hunter2\n",
+			wantErr: "secret token is not encrypted",
+		},
+		{
+			name:    "no key",
+			yaml:    valid,
+			setup:   func(t *testing.T) { t.Setenv(SecretKeyEnvVar, "") },
+			wantErr: SecretKeyEnvVar,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+			_, err := ParseConfig([]byte(tt.yaml), "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Unreferenced secrets do not need the key
+	t.Setenv(SecretKeyEnvVar, "")
+	noRefs := strings.Replace(strings.Replace(valid, "${secret:hookurl}", "https://example.com", 1), "Bearer ${secret:APITOKEN}", "none", 1)
+	if _, err := ParseConfig([]byte(noRefs), ""); err != nil {
+		t.Errorf("expected a config without references to load without a key, got %v", err)
+	}
+}
+
+func TestWriteConfigKeepsSecretReferences(t *testing.T) {
+	key := setSecretKey(t)
+	path := writeTempConfig(t, secretsConfigYAML(t, key))
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	// A monitor added through the API with a secret pasted in plaintext
+	if err := cfg.AddMonitor("core", models.Monitor{
+		Name:    "admin",
+		Type:    models.MonitorTypeHTTP,
+		URL:     "https://api.example.com/admin",
+		Headers: map[string]string{"X-Token": "tok-0123456789"},
+	}); err != nil {
+		t.Fatalf("AddMonitor() error = %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "config.yml")
+	if err := cfg.WriteConfig(out); err != nil {
+		t.Fatalf("WriteConfig() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written := string(data)
+	for _, plaintext := range []string{"tok-0123456789", "secret-path"} {
+		if strings.Contains(written, plaintext) {
+			t.Errorf("written config contains plaintext %q:\n%s", plaintext, written)
+		}
+	}
+	for _, ref := range []string{"Bearer ${secret:APITOKEN}", "${secret:hookurl}", "${secret:apitoken}"} {
+		if !strings.Contains(written, ref) {
+			t.Errorf("written config is missing %q:\n%s", ref, written)
+		}
+	}
+
+	reloaded, err := LoadConfig(out)
+	if err != nil {
+		t.Fatalf("LoadConfig() after write error = %v", err)
+	}
+	if got := reloaded.Monitoring.Groups[0].Monitors[1].Headers["x-token"]; got != "tok-0123456789" {
+		t.Errorf("expected the pasted secret to resolve after reload, got %q", got)
+	}
+}
+
+// storeSecrets serves secret store references from a map
+type storeSecrets map[string]string
+
+func (s storeSecrets) Fetch(_ context.Context, ref string) (secrets.Secret, error) {
+	value, ok := s[ref]
+	if !ok {
+		return secrets.Secret{}, fmt.Errorf("no secret %s", ref)
+	}
+	return secrets.Secret{Value: value}, nil
+}
+
+func TestLoadConfigResolvesSecretStores(t *testing.T) {
+	resolver := secrets.NewResolver()
+	resolver.Register(secrets.SchemeVault, storeSecrets{"secret/data/db#password": "db-password-1"})
+	resolver.Register(secrets.SchemeAWS, storeSecrets{"prod/app#token": "aws-token-123"})
+	previous := secretResolver
+	secretResolver = resolver
+	defer func() { secretResolver = previous }()
+
+	path := writeTempConfig(t, `
+storage:
+  backend: postgres
+  postgres:
+    password: "${vault:secret/data/db#password}"
+monitoring:
+  groups:
+    - name: core
+      monitors:
+        - name: api
+          type: http
+          url: https://api.example.com/health
+          headers:
+            Authorization: "Bearer ${aws-sm:prod/app#token}"
+`)
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := cfg.Storage.Postgres.Password; got != "db-password-1" {
+		t.Errorf("expected the Vault reference to be resolved, got %q", got)
+	}
+	if got := cfg.Monitoring.Groups[0].Monitors[0].Headers["authorization"]; got != "Bearer aws-token-123" {
+		t.Errorf("expected the AWS reference to be resolved, got %q", got)
+	}
+
+	out := filepath.Join(t.TempDir(), "config.yml")
+	if err := cfg.WriteConfig(out); err != nil {
+		t.Fatalf("WriteConfig() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "db-password-1") || !strings.Contains(string(data), "${vault:secret/data/db#password}") {
+		t.Errorf("expected the reference to be written back:\n%s", data)
+	}
+
+	if _, err := ParseConfig([]byte("webhooks:\n  - url: \"${gcp-sm:projects/p/secrets/hook}\"\n"), ""); err == nil ||
+		!strings.Contains(err.Error(), "unknown secret store") {
+		t.Errorf("expected an unregistered store to fail, got %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// templateVarPattern matches {{name}} placeholders in template string fields
+var templateVarPattern = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// ErrGeneratedMonitor is returned when editing a monitor produced by a template
+var ErrGeneratedMonitor = errors.New("monitor is generated from a template")
+
+// templateValueVar is the variable bound by TemplateExpansion.Values
+const templateValueVar = "value"
+
+// MonitorTemplate is a monitor definition whose string fields may contain
+// {{var}} placeholders, expanded once per variable set
+type MonitorTemplate struct {
+	Name    string         `yaml:"name" mapstructure:"name" json:"name"`
+	Monitor models.Monitor `yaml:"monitor" mapstructure:"monitor" json:"monitor"`
+}
+
+// Expand returns a copy of the template monitor with placeholders replaced.
+// Variable names are case-insensitive; unresolved placeholders are an error.
+func (t MonitorTemplate) Expand(vars map[string]string) (models.Monitor, error) {
+	lookup := make(map[string]string, len(vars))
+	for key, value := range vars {
+		lookup[strings.ToLower(key)] = value
+	}
+
+	var missing []string
+	replace := func(s string) string {
+		return templateVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+			name := strings.ToLower(templateVarPattern.FindStringSubmatch(match)[1])
+			value, ok := lookup[name]
+			if !ok {
+				missing = append(missing, name)
+				return match
+			}
+			return value
+		})
+	}
+
+	monitor := t.Monitor
+	substituteStrings(reflect.ValueOf(&monitor).Elem(), replace)
+
+	if len(missing) > 0 {
+		return models.Monitor{}, fmt.Errorf("template %s: undefined variable %q", t.Name, missing[0])
+	}
+	return monitor, nil
+}
+
+// substituteStrings rewrites every string reachable from v, copying maps and
+// pointers so the template itself is never modified
+func substituteStrings(v reflect.Value, replace func(string) string) {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(replace(v.String()))
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				substituteStrings(v.Field(i), replace)
+			}
+		}
+	case reflect.Ptr:
+		if v.IsNil() || v.Elem().Kind() != reflect.Struct {
+			return
+		}
+		copied := reflect.New(v.Elem().Type())
+		copied.Elem().Set(v.Elem())
+		substituteStrings(copied.Elem(), replace)
+		v.Set(copied)
+	case reflect.Map:
+		if v.IsNil() || v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), reflect.ValueOf(replace(iter.Value().String())).Convert(v.Type().Elem()))
+		}
+		v.Set(copied)
+	}
+}
+
+// FindTemplate returns the template with the given name
+func (c *Config) FindTemplate(name string) (*MonitorTemplate, bool) {
+	for i := range c.Templates {
+		if c.Templates[i].Name == name {
+			return &c.Templates[i], true
+		}
+	}
+	return nil, false
+}
+
+// ExpandTemplate generates the monitors described by an expansion
+func (c *Config) ExpandTemplate(expansion models.TemplateExpansion) ([]models.Monitor, error) {
+	template, ok := c.FindTemplate(expansion.Template)
+	if !ok {
+		return nil, fmt.Errorf("template %s not found", expansion.Template)
+	}
+
+	varSets := make([]map[string]string, 0, len(expansion.Values)+len(expansion.Vars))
+	for _, value := range expansion.Values {
+		varSets = append(varSets, map[string]string{templateValueVar: value})
+	}
+	varSets = append(varSets, expansion.Vars...)
+
+	monitors := make([]models.Monitor, 0, len(varSets))
+	for _, vars := range varSets {
+		monitor, err := template.Expand(vars)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, monitor)
+	}
+	return monitors, nil
+}
+
+// ExpandTemplates regenerates monitors for every group expansion, replacing
+// monitors generated by a previous call. Generated monitors are omitted by
+// WriteConfig, so the file keeps only the templates and expansions.
+func (c *Config) ExpandTemplates() error {
+	c.stripGenerated()
+
+	generated := make(map[string]string)
+	for i := range c.Monitoring.Groups {
+		group := &c.Monitoring.Groups[i]
+		for _, expansion := range group.Expand {
+			monitors, err := c.ExpandTemplate(expansion)
+			if err != nil {
+				return fmt.Errorf("group %s: %w", group.Name, err)
+			}
+			for _, monitor := range monitors {
+				generated[monitor.Name] = expansion.Template
+			}
+			group.Monitors = append(group.Monitors, monitors...)
+		}
+	}
+
+	c.generated = generated
+	return nil
+}
+
+// GeneratedBy returns the template a monitor was expanded from, if any
+func (c *Config) GeneratedBy(monitorName string) (string, bool) {
+	template, ok := c.generated[monitorName]
+	return template, ok
+}
+
+// stripGenerated removes monitors added by ExpandTemplates
+func (c *Config) stripGenerated() {
+	if len(c.generated) == 0 {
+		return
+	}
+	for i := range c.Monitoring.Groups {
+		c.Monitoring.Groups[i].Monitors = c.withoutGenerated(c.Monitoring.Groups[i].Monitors)
+	}
+	c.generated = nil
+}
+
+// withoutGenerated returns monitors not produced by template expansion
+func (c *Config) withoutGenerated(monitors []models.Monitor) []models.Monitor {
+	kept := make([]models.Monitor, 0, len(monitors))
+	for _, monitor := range monitors {
+		if _, ok := c.generated[monitor.Name]; !ok {
+			kept = append(kept, monitor)
+		}
+	}
+	return kept
+}
+
+// validateTemplates checks template definitions and expansion references
+func (c *Config) validateTemplates() error {
+	names := make(map[string]bool)
+	for i, template := range c.Templates {
+		if template.Name == "" {
+			return fmt.Errorf("templates[%d] requires name", i)
+		}
+		if names[template.Name] {
+			return fmt.Errorf("duplicate template name: %s", template.Name)
+		}
+		names[template.Name] = true
+	}
+
+	for _, group := range c.Monitoring.Groups {
+		for _, expansion := range group.Expand {
+			if !names[expansion.Template] {
+				return fmt.Errorf("group %s expands unknown template %s", group.Name, expansion.Template)
+			}
+		}
+	}
+	return nil
+}
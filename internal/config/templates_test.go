@@ -0,0 +1,215 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const templateConfigYAML = `
+templates:
+  - name: "https-service"
+    monitor:
+      type: "http"
+      name: "{{value}}-https"
+      url: "https://{{value}}/health"
+      expectedStatus: 200
+      interval: "1m"
+      headers:
+        Host: "{{value}}"
+  - name: "tcp-port"
+    monitor:
+      type: "tcp"
+      name: "{{host}}-{{port}}"
+      target: "{{host}}:{{port}}"
+monitoring:
+  defaultTimeout: "5s"
+  groups:
+    - name: "fleet"
+      monitors:
+        - type: "http"
+          name: "manual"
+          url: "https://manual.example.com"
+      expand:
+        - template: "https-service"
+          values: ["a.example.com", "b.example.com"]
+        - template: "tcp-port"
+          vars:
+            - host: "db1"
+              port: "5432"
+`
+
+func TestLoadConfigExpandsTemplates(t *testing.T) {
+	path := writeTempConfig(t, templateConfigYAML)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	monitors := cfg.Monitoring.Groups[0].Monitors
+	if len(monitors) != 4 {
+		t.Fatalf("expected 4 monitors (1 manual + 3 generated), got %d", len(monitors))
+	}
+
+	a := monitors[1]
+	if a.Name != "a.example.com-https" || a.URL != "https://a.example.com/health" {
+		t.Errorf("unexpected expansion: name=%s url=%s", a.Name, a.URL)
+	}
+	if a.Interval.ToDuration() != time.Minute || a.Timeout.ToDuration() != 5*time.Second {
+		t.Errorf("expected template interval and default timeout, got %v/%v", a.Interval, a.Timeout)
+	}
+	if a.Headers["host"] != "a.example.com" {
+		t.Errorf("expected header substitution, got %v", a.Headers)
+	}
+
+	db := monitors[3]
+	if db.Name != "db1-5432" || db.Target != "db1:5432" {
+		t.Errorf("unexpected vars expansion: name=%s target=%s", db.Name, db.Target)
+	}
+
+	if template, ok := cfg.GeneratedBy("b.example.com-https"); !ok || template != "https-service" {
+		t.Errorf("expected b.example.com-https to be generated by https-service, got %q", template)
+	}
+	if _, ok := cfg.GeneratedBy("manual"); ok {
+		t.Error("expected manual monitor not to be marked generated")
+	}
+}
+
+func TestWriteConfigOmitsGeneratedMonitors(t *testing.T) {
+	path := writeTempConfig(t, templateConfigYAML)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	out := filepath.Join(t.TempDir(), "config.yml")
+	if err := cfg.WriteConfig(out); err != nil {
+		t.Fatalf("WriteConfig returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read written config: %v", err)
+	}
+	if strings.Contains(string(data), "a.example.com-https") {
+		t.Error("expected generated monitors to be omitted from written config")
+	}
+
+	reloaded, err := LoadConfig(out)
+	if err != nil {
+		t.Fatalf("LoadConfig after write returned error: %v", err)
+	}
+	if got := len(reloaded.Monitoring.Groups[0].Monitors); got != 4 {
+		t.Errorf("expected 4 monitors after round-trip, got %d", got)
+	}
+
+	// In-memory config still holds the generated monitors
+	if got := len(cfg.Monitoring.Groups[0].Monitors); got != 4 {
+		t.Errorf("expected WriteConfig not to modify the config, got %d monitors", got)
+	}
+}
+
+func TestGeneratedMonitorsCannotBeEdited(t *testing.T) {
+	path := writeTempConfig(t, templateConfigYAML)
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+
+	if err := cfg.DeleteMonitor("a.example.com-https"); !errors.Is(err, ErrGeneratedMonitor) {
+		t.Errorf("expected ErrGeneratedMonitor from delete, got %v", err)
+	}
+	if err := cfg.UpdateMonitor("db1-5432", models.Monitor{Name: "db1-5432"}); !errors.Is(err, ErrGeneratedMonitor) {
+		t.Errorf("expected ErrGeneratedMonitor from update, got %v", err)
+	}
+	if err := cfg.SetMonitorEnabled("db1-5432", false); !errors.Is(err, ErrGeneratedMonitor) {
+		t.Errorf("expected ErrGeneratedMonitor from disable, got %v", err)
+	}
+	if err := cfg.DeleteMonitor("manual"); err != nil {
+		t.Errorf("expected manual monitor delete to succeed, got %v", err)
+	}
+}
+
+func TestMonitorTemplateExpand(t *testing.T) {
+	template := MonitorTemplate{
+		Name: "svc",
+		Monitor: models.Monitor{
+			Type:    models.MonitorTypeHTTP,
+			Name:    "{{ Host }}",
+			URL:     "https://{{host}}:{{port}}",
+			Labels:  map[string]string{"env": "{{env}}"},
+			Metrics: &models.MonitorMetricsConfig{Labels: map[string]string{"host": "{{host}}"}},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		vars    map[string]string
+		wantURL string
+		wantErr string
+	}{
+		{
+			name:    "all variables",
+			vars:    map[string]string{"HOST": "api", "port": "8443", "env": "prod"},
+			wantURL: "https://api:8443",
+		},
+		{
+			name:    "missing variable",
+			vars:    map[string]string{"host": "api", "env": "prod"},
+			wantErr: `undefined variable "port"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, err := template.Expand(tt.vars)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand returned error: %v", err)
+			}
+			if monitor.URL != tt.wantURL || monitor.Name != "api" {
+				t.Errorf("unexpected expansion: name=%s url=%s", monitor.Name, monitor.URL)
+			}
+			if monitor.Labels["env"] != "prod" || monitor.Metrics.Labels["host"] != "api" {
+				t.Errorf("expected nested substitution, got %v / %v", monitor.Labels, monitor.Metrics.Labels)
+			}
+		})
+	}
+
+	// The template itself must be left untouched
+	if template.Monitor.Labels["env"] != "{{env}}" || template.Monitor.Metrics.Labels["host"] != "{{host}}" {
+		t.Error("expected template to be unmodified by expansion")
+	}
+}
+
+func TestValidateRejectsUnknownTemplate(t *testing.T) {
+	cfg := &Config{
+		Server: ServerConfig{Port: "7878"},
+		Monitoring: MonitoringConfig{
+			Groups: []models.MonitorGroup{{
+				Name:   "fleet",
+				Expand: []models.TemplateExpansion{{Template: "missing", Values: []string{"a"}}},
+			}},
+		},
+	}
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "unknown template missing") {
+		t.Fatalf("expected unknown template error, got %v", err)
+	}
+}
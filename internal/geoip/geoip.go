@@ -0,0 +1,80 @@
+// Package geoip looks up the country and autonomous system of IP addresses
+// in MaxMind GeoLite2 or GeoIP2 databases.
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// DB looks up addresses in a country database, an ASN database, or both
+type DB struct {
+	country *mmdb
+	asn     *mmdb
+}
+
+// Open reads the databases named in cfg. Either may be empty.
+func Open(cfg config.GeoIPConfig) (*DB, error) {
+	db := &DB{}
+	if cfg.CountryDatabase != "" {
+		country, err := openMMDB(cfg.CountryDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open country database: %w", err)
+		}
+		db.country = country
+	}
+	if cfg.ASNDatabase != "" {
+		asn, err := openMMDB(cfg.ASNDatabase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open ASN database: %w", err)
+		}
+		db.asn = asn
+	}
+	return db, nil
+}
+
+// Lookup returns what the databases know about ip, or nil if they know
+// nothing or ip is not an address
+func (db *DB) Lookup(ip string) *models.GeoInfo {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return nil
+	}
+
+	info := &models.GeoInfo{}
+	if db.country != nil {
+		if record, err := db.country.lookup(addr); err == nil {
+			fields, _ := record.(map[string]interface{})
+			// City and Country databases share the country record; fall back
+			// to the registered country for anycast and satellite ranges
+			country, _ := fields["country"].(map[string]interface{})
+			if country == nil {
+				country, _ = fields["registered_country"].(map[string]interface{})
+			}
+			info.Country, _ = country["iso_code"].(string)
+			if names, ok := country["names"].(map[string]interface{}); ok {
+				info.CountryName, _ = names["en"].(string)
+			}
+			if city, ok := fields["city"].(map[string]interface{}); ok {
+				if names, ok := city["names"].(map[string]interface{}); ok {
+					info.City, _ = names["en"].(string)
+				}
+			}
+		}
+	}
+	if db.asn != nil {
+		if record, err := db.asn.lookup(addr); err == nil {
+			fields, _ := record.(map[string]interface{})
+			info.ASN = toUint(fields["autonomous_system_number"])
+			info.Organization, _ = fields["autonomous_system_organization"].(string)
+		}
+	}
+
+	if *info == (models.GeoInfo{}) {
+		return nil
+	}
+	return info
+}
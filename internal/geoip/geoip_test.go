@@ -0,0 +1,303 @@
+package geoip
+
+import (
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// pointerTo is encoded in full the first time its name is seen and as a
+// pointer to that copy afterwards
+type pointerTo struct {
+	name  string
+	value interface{}
+}
+
+// encodeValue appends v to data in the MaxMind DB data format
+func encodeValue(t *testing.T, data []byte, v interface{}, pointers map[string]int) []byte {
+	t.Helper()
+	header := func(kind, size int) {
+		if kind > 7 {
+			data = append(data, byte(size))
+			data = append(data, byte(kind-7))
+			if size >= 29 {
+				t.Fatalf("extended type size %d not supported", size)
+			}
+			return
+		}
+		switch {
+		case size < 29:
+			data = append(data, byte(kind<<5|size))
+		case size < 285:
+			data = append(data, byte(kind<<5|29), byte(size-29))
+		default:
+			data = append(data, byte(kind<<5|30), byte((size-285)>>8), byte(size-285))
+		}
+	}
+
+	switch v := v.(type) {
+	case pointerTo:
+		offset, ok := pointers[v.name]
+		if !ok {
+			pointers[v.name] = len(data)
+			return encodeValue(t, data, v.value, pointers)
+		}
+		if offset >= 2048 {
+			t.Fatalf("pointer offset %d not supported", offset)
+		}
+		return append(data, byte(typePointer<<5|offset>>8), byte(offset))
+	case string:
+		header(typeString, len(v))
+		return append(data, v...)
+	case uint16:
+		header(typeUint16, 2)
+		return binary.BigEndian.AppendUint16(data, v)
+	case uint32:
+		header(typeUint32, 4)
+		return binary.BigEndian.AppendUint32(data, v)
+	case bool:
+		size := 0
+		if v {
+			size = 1
+		}
+		header(typeBool, size)
+		return data
+	case []interface{}:
+		header(typeArray, len(v))
+		for _, item := range v {
+			data = encodeValue(t, data, item, pointers)
+		}
+		return data
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		header(typeMap, len(v))
+		for _, name := range names {
+			data = encodeValue(t, data, name, pointers)
+			data = encodeValue(t, data, v[name], pointers)
+		}
+		return data
+	}
+	t.Fatalf("cannot encode %T", v)
+	return nil
+}
+
+// buildMMDB writes a database holding records for CIDR networks
+func buildMMDB(t *testing.T, recordSize, ipVersion int, networks map[string]interface{}) []byte {
+	t.Helper()
+	const empty = -1
+	nodes := [][2]int{{empty, empty}}
+	var data []byte
+	pointers := make(map[string]int)
+
+	cidrs := make([]string, 0, len(networks))
+	for cidr := range networks {
+		cidrs = append(cidrs, cidr)
+	}
+	sort.Strings(cidrs)
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("invalid network %s: %v", cidr, err)
+		}
+		ip := network.IP
+		ones, _ := network.Mask.Size()
+		if ip4 := ip.To4(); ip4 != nil && ipVersion == 6 {
+			ip = append(make(net.IP, 12), ip4...)
+			ones += 96
+		}
+
+		offset := len(data)
+		data = encodeValue(t, data, networks[cidr], pointers)
+
+		node := 0
+		for i := 0; i < ones; i++ {
+			bit := int(ip[i/8]>>(7-uint(i%8))) & 1
+			if i == ones-1 {
+				nodes[node][bit] = -2 - offset
+				break
+			}
+			if nodes[node][bit] == empty {
+				nodes = append(nodes, [2]int{empty, empty})
+				nodes[node][bit] = len(nodes) - 1
+			}
+			node = nodes[node][bit]
+		}
+	}
+
+	nodeCount := len(nodes)
+	resolve := func(record int) uint32 {
+		switch {
+		case record == empty:
+			return uint32(nodeCount)
+		case record <= -2:
+			return uint32(nodeCount + dataSeparator - 2 - record)
+		}
+		return uint32(record)
+	}
+	var tree []byte
+	for _, node := range nodes {
+		left, right := resolve(node[0]), resolve(node[1])
+		switch recordSize {
+		case 24:
+			tree = append(tree, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+		case 28:
+			tree = append(tree, byte(left>>16), byte(left>>8), byte(left), byte(left>>24<<4|right>>24&0x0f),
+				byte(right>>16), byte(right>>8), byte(right))
+		case 32:
+			tree = binary.BigEndian.AppendUint32(tree, left)
+			tree = binary.BigEndian.AppendUint32(tree, right)
+		}
+	}
+
+	buf := append(tree, make([]byte, dataSeparator)...)
+	buf = append(buf, data...)
+	buf = append(buf, metadataMarker...)
+	return encodeValue(t, buf, map[string]interface{}{
+		"node_count":    uint32(nodeCount),
+		"record_size":   uint16(recordSize),
+		"ip_version":    uint16(ipVersion),
+		"database_type": "Test",
+		"languages":     []interface{}{"en"},
+	}, make(map[string]int))
+}
+
+func countryRecord(code, name string) map[string]interface{} {
+	return map[string]interface{}{
+		"iso_code": code,
+		"names":    map[string]interface{}{"en": name},
+	}
+}
+
+func TestLookup(t *testing.T) {
+	australia := countryRecord("AU", "Australia")
+	networks := map[string]interface{}{
+		"1.1.1.0/24":     map[string]interface{}{"country": pointerTo{"australia", australia}},
+		"1.0.0.0/24":     map[string]interface{}{"country": pointerTo{"australia", australia}, "is_anycast": true},
+		"2606:4700::/32": map[string]interface{}{"country": countryRecord("US", "United States")},
+	}
+
+	tests := []struct {
+		name string
+		ip   string
+		want string
+	}{
+		{name: "IPv4", ip: "1.1.1.1", want: "AU"},
+		{name: "shared record", ip: "1.0.0.1", want: "AU"},
+		{name: "IPv6", ip: "2606:4700::1111", want: "US"},
+		{name: "IPv4 not found", ip: "8.8.8.8", want: ""},
+		{name: "IPv6 not found", ip: "2001:db8::1", want: ""},
+	}
+
+	for _, recordSize := range []int{24, 28, 32} {
+		db, err := newMMDB(buildMMDB(t, recordSize, 6, networks))
+		if err != nil {
+			t.Fatalf("record size %d: newMMDB() error = %v", recordSize, err)
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				record, err := db.lookup(net.ParseIP(tt.ip))
+				if err != nil {
+					t.Fatalf("lookup() error = %v", err)
+				}
+				fields, _ := record.(map[string]interface{})
+				country, _ := fields["country"].(map[string]interface{})
+				if got, _ := country["iso_code"].(string); got != tt.want {
+					t.Errorf("record size %d: country = %q, want %q", recordSize, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestLookupIPv4Database(t *testing.T) {
+	db, err := newMMDB(buildMMDB(t, 24, 4, map[string]interface{}{
+		"10.0.0.0/8": map[string]interface{}{"autonomous_system_number": uint32(64512)},
+	}))
+	if err != nil {
+		t.Fatalf("newMMDB() error = %v", err)
+	}
+	record, err := db.lookup(net.ParseIP("10.1.2.3"))
+	if err != nil || record == nil {
+		t.Fatalf("lookup() = %v, %v, want a record", record, err)
+	}
+	if record, _ := db.lookup(net.ParseIP("2001:db8::1")); record != nil {
+		t.Errorf("lookup(IPv6) = %v, want nil in an IPv4 database", record)
+	}
+}
+
+func TestNewMMDBInvalid(t *testing.T) {
+	if _, err := newMMDB([]byte("not a database")); err == nil {
+		t.Error("newMMDB() error = nil, want error for data without metadata")
+	}
+	valid := buildMMDB(t, 24, 6, map[string]interface{}{"1.1.1.0/24": "x"})
+	if _, err := newMMDB(valid[dataSeparator+6:]); err == nil {
+		t.Error("newMMDB() error = nil, want error for a truncated tree")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, networks map[string]interface{}) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, buildMMDB(t, 24, 6, networks), 0o644); err != nil {
+			t.Fatalf("failed to write database: %v", err)
+		}
+		return path
+	}
+	countryPath := write("country.mmdb", map[string]interface{}{
+		"1.1.1.0/24": map[string]interface{}{
+			"country": countryRecord("AU", "Australia"),
+			"city":    map[string]interface{}{"names": map[string]interface{}{"en": "Sydney"}},
+		},
+		"192.0.2.0/24": map[string]interface{}{"registered_country": countryRecord("NL", "Netherlands")},
+	})
+	asnPath := write("asn.mmdb", map[string]interface{}{
+		"1.1.1.0/24": map[string]interface{}{
+			"autonomous_system_number":       uint32(13335),
+			"autonomous_system_organization": "CLOUDFLARENET",
+		},
+	})
+
+	db, err := Open(config.GeoIPConfig{CountryDatabase: countryPath, ASNDatabase: asnPath})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ip   string
+		want *models.GeoInfo
+	}{
+		{
+			name: "both databases",
+			ip:   "1.1.1.1",
+			want: &models.GeoInfo{Country: "AU", CountryName: "Australia", City: "Sydney", ASN: 13335, Organization: "CLOUDFLARENET"},
+		},
+		{name: "registered country", ip: "192.0.2.10", want: &models.GeoInfo{Country: "NL", CountryName: "Netherlands"}},
+		{name: "unknown address", ip: "8.8.8.8", want: nil},
+		{name: "not an address", ip: "example.com", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := db.Lookup(tt.ip); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lookup(%s) = %+v, want %+v", tt.ip, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Open(config.GeoIPConfig{ASNDatabase: filepath.Join(dir, "missing.mmdb")}); err == nil {
+		t.Error("Open() error = nil, want error for a missing database")
+	}
+}
@@ -0,0 +1,236 @@
+// Package graphite exports monitor check durations and up/down states to
+// Graphite using the Carbon plaintext protocol.
+package graphite
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	defaultPort     = 2003
+	defaultPrefix   = "hallmonitor"
+	defaultInterval = 60 * time.Second
+	dialTimeout     = 10 * time.Second
+)
+
+// Emitter sends the latest result of each monitor to Carbon every interval,
+// as {prefix}.{group}.{monitor}.up (1 or 0) and .duration_ms. Monitors
+// without a new result since the last send are skipped.
+type Emitter struct {
+	address  string
+	prefix   string
+	interval time.Duration
+	logger   *logging.Logger
+
+	pending map[string]*models.MonitorResult
+	mu      sync.Mutex
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+}
+
+// NewEmitter creates a Graphite emitter from configuration
+func NewEmitter(cfg config.GraphiteConfig, logger *logging.Logger) *Emitter {
+	port := cfg.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	prefix := strings.Trim(cfg.Prefix, ".")
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &Emitter{
+		address:  net.JoinHostPort(cfg.Host, strconv.Itoa(port)),
+		prefix:   prefix,
+		interval: interval,
+		logger:   logger,
+		pending:  make(map[string]*models.MonitorResult),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// HandleResult keeps the result to send with the next batch
+func (e *Emitter) HandleResult(result *models.MonitorResult) {
+	if result == nil || result.Status == models.StatusUnknown {
+		return
+	}
+
+	e.mu.Lock()
+	e.pending[result.Group+"/"+result.Monitor] = result
+	e.mu.Unlock()
+}
+
+// Start sends batches every interval until stopped
+func (e *Emitter) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return nil
+	}
+
+	e.logger.WithComponent(logging.ComponentGraphite).
+		WithFields(map[string]interface{}{
+			"address":  e.address,
+			"prefix":   e.prefix,
+			"interval": e.interval.String(),
+		}).
+		Info("Starting Graphite emitter")
+
+	e.wg.Add(1)
+	go e.sendLoop(ctx)
+
+	e.running = true
+	return nil
+}
+
+// Stop sends any pending results and stops the emitter
+func (e *Emitter) Stop() error {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return nil
+	}
+	e.running = false
+	e.mu.Unlock()
+
+	e.logger.WithComponent(logging.ComponentGraphite).Info("Stopping Graphite emitter")
+	close(e.stopCh)
+	e.wg.Wait()
+	return nil
+}
+
+// sendLoop sends pending results on every tick, and once more on the way out
+func (e *Emitter) sendLoop(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopCh:
+			e.flush(context.Background())
+			return
+		case <-ticker.C:
+			e.flush(ctx)
+		}
+	}
+}
+
+// flush sends the pending results, keeping them for the next attempt when
+// Carbon cannot be reached unless newer results replace them
+func (e *Emitter) flush(ctx context.Context) {
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = make(map[string]*models.MonitorResult)
+	e.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := e.send(ctx, batch); err != nil {
+		e.logger.WithComponent(logging.ComponentGraphite).
+			WithError(err).
+			WithFields(map[string]interface{}{
+				"address": e.address,
+				"results": len(batch),
+			}).
+			Warn("Failed to send results to Graphite")
+
+		e.mu.Lock()
+		for key, result := range batch {
+			if _, newer := e.pending[key]; !newer {
+				e.pending[key] = result
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// send writes the results to Carbon over a new connection
+func (e *Emitter) send(ctx context.Context, results map[string]*models.MonitorResult) error {
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", e.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+	} else {
+		conn.SetWriteDeadline(time.Now().Add(dialTimeout))
+	}
+
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	w := bufio.NewWriter(conn)
+	for _, key := range keys {
+		for _, line := range e.lines(results[key]) {
+			if _, err := w.WriteString(line + "\n"); err != nil {
+				return fmt.Errorf("failed to write: %w", err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return nil
+}
+
+// lines formats a result as Carbon plaintext lines: "path value timestamp"
+func (e *Emitter) lines(result *models.MonitorResult) []string {
+	path := e.prefix + "." + sanitize(result.Group) + "." + sanitize(result.Monitor)
+	timestamp := result.Timestamp.Unix()
+
+	up := 0
+	if result.Status == models.StatusUp {
+		up = 1
+	}
+	duration := strconv.FormatFloat(float64(result.Duration)/float64(time.Millisecond), 'f', 3, 64)
+
+	return []string{
+		fmt.Sprintf("%s.up %d %d", path, up, timestamp),
+		fmt.Sprintf("%s.duration_ms %s %d", path, duration, timestamp),
+	}
+}
+
+// sanitize makes a name safe as a single Graphite path node
+func sanitize(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, name)
+}
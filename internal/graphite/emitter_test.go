@@ -0,0 +1,122 @@
+package graphite
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func testLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	return logger
+}
+
+// carbonServer accepts plaintext connections and delivers each line received
+func carbonServer(t *testing.T) (net.Listener, <-chan string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	lines := make(chan string, 100)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				lines <- scanner.Text()
+			}
+			conn.Close()
+		}
+	}()
+	return listener, lines
+}
+
+func TestEmitterLines(t *testing.T) {
+	emitter := NewEmitter(config.GraphiteConfig{Host: "carbon", Prefix: "ops.hallmonitor."}, testLogger(t))
+	timestamp := time.Unix(1700000000, 0)
+
+	tests := []struct {
+		name   string
+		result *models.MonitorResult
+		want   []string
+	}{
+		{
+			name:   "up",
+			result: &models.MonitorResult{Monitor: "api", Group: "core", Status: models.StatusUp, Duration: 123456 * time.Microsecond, Timestamp: timestamp},
+			want:   []string{"ops.hallmonitor.core.api.up 1 1700000000", "ops.hallmonitor.core.api.duration_ms 123.456 1700000000"},
+		},
+		{
+			name:   "down with unsafe names",
+			result: &models.MonitorResult{Monitor: "web/api.v2", Group: "edge nodes", Status: models.StatusDown, Duration: 2 * time.Second, Timestamp: timestamp},
+			want:   []string{"ops.hallmonitor.edge_nodes.web_api_v2.up 0 1700000000", "ops.hallmonitor.edge_nodes.web_api_v2.duration_ms 2000.000 1700000000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := emitter.lines(tt.result)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d lines, got %v", len(tt.want), got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("line %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEmitterSendsLatestResults(t *testing.T) {
+	listener, lines := carbonServer(t)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	emitter := NewEmitter(config.GraphiteConfig{Host: "127.0.0.1", Port: port, Interval: time.Hour}, testLogger(t))
+	if err := emitter.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start emitter: %v", err)
+	}
+
+	timestamp := time.Unix(1700000000, 0)
+	emitter.HandleResult(&models.MonitorResult{Monitor: "api", Group: "core", Status: models.StatusDown, Timestamp: timestamp})
+	emitter.HandleResult(&models.MonitorResult{Monitor: "api", Group: "core", Status: models.StatusUp, Timestamp: timestamp.Add(time.Minute)})
+	emitter.HandleResult(&models.MonitorResult{Monitor: "db", Group: "core", Status: models.StatusUnknown, Timestamp: timestamp})
+
+	// Stopping sends what is pending
+	if err := emitter.Stop(); err != nil {
+		t.Fatalf("failed to stop emitter: %v", err)
+	}
+
+	want := []string{"hallmonitor.core.api.up 1 1700000060", "hallmonitor.core.api.duration_ms 0.000 1700000060"}
+	for _, expected := range want {
+		select {
+		case line := <-lines:
+			if line != expected {
+				t.Errorf("got line %q, want %q", line, expected)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", expected)
+		}
+	}
+	select {
+	case line := <-lines:
+		t.Errorf("unexpected line %q", line)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,157 @@
+// Package i18n holds the message catalogs for dashboard pages and
+// notifications, and picks a language from an Accept-Language header.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed catalogs/*.json
+var catalogsFS embed.FS
+
+// DefaultLanguage is used when no supported language is requested, and for
+// messages missing from another catalog
+const DefaultLanguage = "en"
+
+// Languages lists the supported languages
+var Languages = []string{"en", "de", "fr", "es"}
+
+// Catalog holds one language's messages, keyed by dotted message IDs.
+// Messages are fmt format strings.
+type Catalog struct {
+	lang     string
+	messages map[string]string
+}
+
+var catalogs = make(map[string]*Catalog)
+
+func init() {
+	for _, lang := range Languages {
+		data, err := catalogsFS.ReadFile("catalogs/" + lang + ".json")
+		if err != nil {
+			panic(err)
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("invalid %s catalog: %v", lang, err))
+		}
+		catalogs[lang] = &Catalog{lang: lang, messages: messages}
+	}
+}
+
+// Supported reports whether lang has a catalog
+func Supported(lang string) bool {
+	_, ok := catalogs[strings.ToLower(lang)]
+	return ok
+}
+
+// Get returns the catalog for lang, or the default language's catalog
+func Get(lang string) *Catalog {
+	if catalog, ok := catalogs[strings.ToLower(lang)]; ok {
+		return catalog
+	}
+	return catalogs[DefaultLanguage]
+}
+
+// Lang returns the catalog's language code
+func (c *Catalog) Lang() string {
+	if c == nil {
+		return DefaultLanguage
+	}
+	return c.lang
+}
+
+// T formats the message key with args. Keys missing from the catalog fall
+// back to the default language, then to the key itself. A nil catalog uses
+// the default language.
+func (c *Catalog) T(key string, args ...interface{}) string {
+	message, ok := c.lookup(key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// lookup finds the unformatted message for key
+func (c *Catalog) lookup(key string) (string, bool) {
+	if c != nil {
+		if message, ok := c.messages[key]; ok {
+			return message, true
+		}
+	}
+	message, ok := catalogs[DefaultLanguage].messages[key]
+	return message, ok
+}
+
+// Messages returns the unformatted messages whose keys start with prefix,
+// filled in from the default language, for scripts that render text in the
+// browser
+func (c *Catalog) Messages(prefix string) map[string]string {
+	messages := make(map[string]string)
+	for key := range catalogs[DefaultLanguage].messages {
+		if strings.HasPrefix(key, prefix) {
+			messages[key], _ = c.lookup(key)
+		}
+	}
+	return messages
+}
+
+// Negotiate picks the supported language an Accept-Language header prefers
+// most, matching regional tags such as de-AT by their primary language. It
+// returns DefaultLanguage when none is acceptable.
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		lang    string
+		quality float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(name) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				q = 0
+			}
+			quality = q
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		lang, _, _ := strings.Cut(tag, "-")
+		if tag == "*" {
+			lang = DefaultLanguage
+		}
+		if Supported(lang) {
+			candidates = append(candidates, candidate{lang: lang, quality: quality})
+		}
+	}
+	if len(candidates) == 0 {
+		return DefaultLanguage
+	}
+
+	// Equally preferred languages keep the header's order
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].lang
+}
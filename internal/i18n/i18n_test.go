@@ -0,0 +1,95 @@
+package i18n
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+// verbPattern matches fmt verbs in messages
+var verbPattern = regexp.MustCompile(`%[%sdvq]`)
+
+func TestCatalogsMatchDefault(t *testing.T) {
+	base := catalogs[DefaultLanguage].messages
+	for _, lang := range Languages {
+		catalog := catalogs[lang].messages
+		for key, message := range base {
+			translated, ok := catalog[key]
+			if !ok {
+				t.Errorf("%s: missing %s", lang, key)
+				continue
+			}
+			// Arguments are passed in the same order in every language
+			if want, got := verbPattern.FindAllString(message, -1), verbPattern.FindAllString(translated, -1); !reflect.DeepEqual(want, got) {
+				t.Errorf("%s: %s uses verbs %v, want %v", lang, key, got, want)
+			}
+		}
+		for key := range catalog {
+			if _, ok := base[key]; !ok {
+				t.Errorf("%s: %s is not in the %s catalog", lang, key, DefaultLanguage)
+			}
+		}
+	}
+}
+
+func TestCatalogT(t *testing.T) {
+	tests := []struct {
+		name    string
+		catalog *Catalog
+		key     string
+		args    []interface{}
+		want    string
+	}{
+		{name: "english", catalog: Get("en"), key: "notify.title.down", args: []interface{}{"api"}, want: "api is down"},
+		{name: "german", catalog: Get("de"), key: "notify.title.down", args: []interface{}{"api"}, want: "api ist ausgefallen"},
+		{name: "no args", catalog: Get("fr"), key: "nav.close", want: "Fermer"},
+		{name: "unknown language", catalog: Get("xx"), key: "nav.close", want: "Close"},
+		{name: "nil catalog", catalog: nil, key: "nav.close", want: "Close"},
+		{name: "unknown key", catalog: Get("es"), key: "missing.key", want: "missing.key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.catalog.T(tt.key, tt.args...); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCatalogMessages(t *testing.T) {
+	messages := Get("de").Messages("ambient.")
+	if got := messages["ambient.monitors"]; got != "Monitore" {
+		t.Errorf("expected the German message, got %q", got)
+	}
+	if _, ok := messages["dashboard.refresh"]; ok {
+		t.Error("expected only messages under the prefix")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: "en"},
+		{header: "de", want: "de"},
+		{header: "fr-CA,fr;q=0.9,en;q=0.8", want: "fr"},
+		{header: "ES-mx", want: "es"},
+		{header: "ja,de;q=0.5", want: "de"},
+		{header: "en;q=0.4,es;q=0.7", want: "es"},
+		{header: "de;q=0.8,fr;q=0.8", want: "de"},
+		{header: "fr;q=0,es", want: "es"},
+		{header: "ja,zh", want: "en"},
+		{header: "*", want: "en"},
+		{header: "de;q=abc,fr", want: "fr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := Negotiate(tt.header); got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
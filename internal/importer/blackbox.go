@@ -0,0 +1,312 @@
+package importer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// DefaultBlackboxGroup holds monitors imported from blackbox_exporter
+const DefaultBlackboxGroup = "blackbox"
+
+// blackboxConfig is the part of a blackbox_exporter config that is converted
+type blackboxConfig struct {
+	Modules map[string]blackboxModule `yaml:"modules"`
+}
+
+// blackboxModule is a probe definition
+type blackboxModule struct {
+	Prober  string        `yaml:"prober"`
+	Timeout time.Duration `yaml:"timeout"`
+	HTTP    blackboxHTTP  `yaml:"http"`
+	TCP     blackboxTCP   `yaml:"tcp"`
+	DNS     blackboxDNS   `yaml:"dns"`
+}
+
+// blackboxHTTP holds http prober settings
+type blackboxHTTP struct {
+	ValidStatusCodes           []int             `yaml:"valid_status_codes"`
+	ValidHTTPVersions          []string          `yaml:"valid_http_versions"`
+	Method                     string            `yaml:"method"`
+	Headers                    map[string]string `yaml:"headers"`
+	NoFollowRedirects          bool              `yaml:"no_follow_redirects"`
+	FailIfSSL                  bool              `yaml:"fail_if_ssl"`
+	FailIfNotSSL               bool              `yaml:"fail_if_not_ssl"`
+	FailIfBodyMatchesRegexp    []string          `yaml:"fail_if_body_matches_regexp"`
+	FailIfBodyNotMatchesRegexp []string          `yaml:"fail_if_body_not_matches_regexp"`
+	FailIfHeaderMatches        []interface{}     `yaml:"fail_if_header_matches"`
+	FailIfHeaderNotMatches     []interface{}     `yaml:"fail_if_header_not_matches"`
+	BasicAuth                  *struct {
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+	} `yaml:"basic_auth"`
+	BearerToken string            `yaml:"bearer_token"`
+	TLSConfig   blackboxTLSConfig `yaml:"tls_config"`
+}
+
+// blackboxTCP holds tcp prober settings
+type blackboxTCP struct {
+	QueryResponse []interface{} `yaml:"query_response"`
+	TLS           bool          `yaml:"tls"`
+}
+
+// blackboxDNS holds dns prober settings
+type blackboxDNS struct {
+	QueryName         string                 `yaml:"query_name"`
+	QueryType         string                 `yaml:"query_type"`
+	ValidRcodes       []string               `yaml:"valid_rcodes"`
+	ValidateAnswerRRs map[string]interface{} `yaml:"validate_answer_rrs"`
+}
+
+// blackboxTLSConfig holds TLS settings shared by probers
+type blackboxTLSConfig struct {
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// targetGroup is an entry of a Prometheus file_sd target list
+type targetGroup struct {
+	Targets []string          `yaml:"targets"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+// ReadBlackbox converts a blackbox_exporter config and a target list into
+// monitors in group. Targets are a Prometheus file_sd list in YAML or JSON;
+// each entry names its module with a module or __param_module label, the
+// other labels become monitor labels, and a name label sets the monitor name.
+// http, tcp, icmp, and dns modules are converted.
+func ReadBlackbox(configData, targetData []byte, group string) (*Import, error) {
+	var cfg blackboxConfig
+	if err := yaml.Unmarshal(configData, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse blackbox_exporter config: %w", err)
+	}
+	if len(cfg.Modules) == 0 {
+		return nil, fmt.Errorf("blackbox_exporter config has no modules")
+	}
+	var targets []targetGroup
+	if err := yaml.Unmarshal(targetData, &targets); err != nil {
+		return nil, fmt.Errorf("failed to parse target list: %w", err)
+	}
+	if group == "" {
+		group = DefaultBlackboxGroup
+	}
+
+	imp := &Import{Source: "blackbox"}
+	var monitors []models.Monitor
+	names := make(map[string]bool)
+	for _, entry := range targets {
+		moduleName := entry.Labels["module"]
+		if moduleName == "" {
+			moduleName = entry.Labels["__param_module"]
+		}
+		module, ok := cfg.Modules[moduleName]
+		if !ok {
+			for _, target := range entry.Targets {
+				if moduleName == "" {
+					imp.warnf("%s: no module label; skipped", target)
+				} else {
+					imp.warnf("%s: module %s is not in the config; skipped", target, moduleName)
+				}
+			}
+			continue
+		}
+
+		for _, target := range entry.Targets {
+			monitor, warnings, err := convertBlackboxProbe(moduleName, module, target)
+			for _, warning := range warnings {
+				imp.warnf("%s: %s", target, warning)
+			}
+			if err != nil {
+				imp.warnf("%s: %v; skipped", target, err)
+				continue
+			}
+
+			name := entry.Labels["name"]
+			if name == "" {
+				name = target
+			}
+			monitor.Name = name
+			monitor.Labels = probeLabels(entry.Labels)
+
+			// The same target may be probed by several modules
+			if names[monitor.Name] {
+				monitor.Name = fmt.Sprintf("%s (%s)", name, moduleName)
+			}
+			for i := 2; names[monitor.Name]; i++ {
+				monitor.Name = fmt.Sprintf("%s (%s %d)", name, moduleName, i)
+			}
+			names[monitor.Name] = true
+			monitors = append(monitors, monitor)
+		}
+	}
+
+	if len(monitors) > 0 {
+		imp.Groups = []models.MonitorGroup{{Name: group, Monitors: monitors}}
+	}
+	return imp, nil
+}
+
+// convertBlackboxProbe converts a module probing one target. Settings that
+// cannot be carried over are returned as warnings; probes that would behave
+// differently are rejected.
+func convertBlackboxProbe(moduleName string, module blackboxModule, target string) (models.Monitor, []string, error) {
+	var warnings []string
+	monitor := models.Monitor{Timeout: models.Duration(module.Timeout)}
+
+	switch module.Prober {
+	case "http":
+		monitor.Type = models.MonitorTypeHTTP
+		monitor.URL = target
+		if !strings.Contains(target, "://") {
+			monitor.URL = "http://" + target // As blackbox_exporter does
+		}
+		warnings = convertBlackboxHTTP(module.HTTP, &monitor)
+
+	case "tcp":
+		if _, _, err := net.SplitHostPort(target); err != nil {
+			return monitor, nil, fmt.Errorf("tcp target must be host:port")
+		}
+		monitor.Type = models.MonitorTypeTCP
+		monitor.Target = target
+		if len(module.TCP.QueryResponse) > 0 {
+			warnings = append(warnings, "query_response is not supported; only the connection is checked")
+		}
+		if module.TCP.TLS {
+			warnings = append(warnings, "TLS is not negotiated on tcp checks")
+		}
+
+	case "icmp":
+		monitor.Type = models.MonitorTypePing
+		monitor.Target = target
+
+	case "dns":
+		monitor.Type = models.MonitorTypeDNS
+		monitor.Target = target
+		monitor.Query = strings.TrimSuffix(module.DNS.QueryName, ".")
+		monitor.QueryType = strings.ToUpper(module.DNS.QueryType)
+		if monitor.QueryType == "" {
+			monitor.QueryType = "ANY" // blackbox_exporter's default
+		}
+		switch monitor.QueryType {
+		case "A", "AAAA", "CNAME", "MX", "TXT", "NS":
+		default:
+			return monitor, nil, fmt.Errorf("DNS query type %s is not supported", monitor.QueryType)
+		}
+		if monitor.Query == "" {
+			return monitor, nil, fmt.Errorf("module %s has no query_name", moduleName)
+		}
+		if len(module.DNS.ValidateAnswerRRs) > 0 {
+			warnings = append(warnings, "answer validation is not supported and was dropped")
+		}
+		for _, rcode := range module.DNS.ValidRcodes {
+			if rcode != "NOERROR" {
+				warnings = append(warnings, fmt.Sprintf("valid rcode %s is not supported; only NOERROR is up", rcode))
+			}
+		}
+
+	default:
+		return monitor, nil, fmt.Errorf("prober %q of module %s is not supported", module.Prober, moduleName)
+	}
+	return monitor, warnings, nil
+}
+
+// convertBlackboxHTTP carries over http prober settings
+func convertBlackboxHTTP(settings blackboxHTTP, monitor *models.Monitor) []string {
+	var warnings []string
+
+	switch len(settings.ValidStatusCodes) {
+	case 0:
+		// blackbox_exporter accepts any 2xx; Hall Monitor expects 200
+	case 1:
+		monitor.ExpectedStatus = settings.ValidStatusCodes[0]
+	default:
+		codes := make([]string, len(settings.ValidStatusCodes))
+		for i, code := range settings.ValidStatusCodes {
+			codes[i] = fmt.Sprint(code)
+		}
+		warnings = append(warnings, fmt.Sprintf("only one valid status code is supported; expecting %s of %s", codes[0], strings.Join(codes, ", ")))
+		monitor.ExpectedStatus = settings.ValidStatusCodes[0]
+	}
+
+	if method := strings.ToUpper(settings.Method); method != "" && method != "GET" {
+		warnings = append(warnings, fmt.Sprintf("checks are sent as GET instead of %s", method))
+	}
+	if len(settings.Headers) > 0 {
+		monitor.Headers = make(map[string]string, len(settings.Headers))
+		for key, value := range settings.Headers {
+			monitor.Headers[key] = value
+		}
+	}
+	switch {
+	case settings.BasicAuth != nil:
+		if monitor.Headers == nil {
+			monitor.Headers = make(map[string]string)
+		}
+		credentials := settings.BasicAuth.Username + ":" + settings.BasicAuth.Password
+		monitor.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	case settings.BearerToken != "":
+		if monitor.Headers == nil {
+			monitor.Headers = make(map[string]string)
+		}
+		monitor.Headers["Authorization"] = "Bearer " + settings.BearerToken
+	}
+
+	if versions := settings.ValidHTTPVersions; len(versions) == 1 && (versions[0] == "HTTP/2" || versions[0] == "HTTP/2.0") {
+		monitor.HTTPVersion = "2"
+	}
+
+	// A literal body match carries over as the expected response
+	switch patterns := settings.FailIfBodyNotMatchesRegexp; {
+	case len(patterns) == 1 && isLiteralPattern(patterns[0]):
+		monitor.ExpectedResponse = patterns[0]
+	case len(patterns) > 0:
+		warnings = append(warnings, "fail_if_body_not_matches_regexp is only supported with a single literal string and was dropped")
+	}
+	if len(settings.FailIfBodyMatchesRegexp) > 0 {
+		warnings = append(warnings, "fail_if_body_matches_regexp is not supported and was dropped")
+	}
+	if len(settings.FailIfHeaderMatches) > 0 || len(settings.FailIfHeaderNotMatches) > 0 {
+		warnings = append(warnings, "header matching is not supported and was dropped")
+	}
+	if settings.FailIfSSL || settings.FailIfNotSSL {
+		warnings = append(warnings, "fail_if_ssl and fail_if_not_ssl are not supported and were dropped")
+	}
+	if settings.NoFollowRedirects {
+		warnings = append(warnings, "redirects are followed")
+	}
+	if settings.TLSConfig.InsecureSkipVerify {
+		warnings = append(warnings, "TLS certificate errors are no longer ignored")
+	}
+	return warnings
+}
+
+// isLiteralPattern reports whether a regexp matches only its own text
+func isLiteralPattern(pattern string) bool {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false
+	}
+	prefix, complete := re.LiteralPrefix()
+	return complete && prefix == pattern
+}
+
+// probeLabels keeps the target labels that describe the monitor
+func probeLabels(labels map[string]string) map[string]string {
+	var result map[string]string
+	for key, value := range labels {
+		if key == "module" || key == "name" || strings.HasPrefix(key, "__") {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const testBlackboxConfig = `
+modules:
+  http_2xx:
+    prober: http
+    timeout: 5s
+  http_health:
+    prober: http
+    http:
+      valid_status_codes: [204]
+      method: POST
+      headers:
+        X-Probe: blackbox
+      bearer_token: s3cret
+      fail_if_body_not_matches_regexp: ["healthy"]
+      tls_config:
+        insecure_skip_verify: true
+  tcp_connect:
+    prober: tcp
+    timeout: 3s
+  icmp:
+    prober: icmp
+  dns_mx:
+    prober: dns
+    dns:
+      query_name: "example.com."
+      query_type: "MX"
+  dns_any:
+    prober: dns
+    dns:
+      query_name: "example.com"
+  grpc:
+    prober: grpc
+`
+
+func TestReadBlackbox(t *testing.T) {
+	targets := `
+- targets: ["https://example.com", "example.org"]
+  labels:
+    module: http_2xx
+    env: prod
+- targets: ["https://example.com/health"]
+  labels:
+    __param_module: http_health
+    name: api-health
+- targets: ["db.internal:5432", "db.internal"]
+  labels: {module: tcp_connect}
+- targets: ["10.0.0.1"]
+  labels: {module: icmp}
+- targets: ["8.8.8.8", "1.1.1.1"]
+  labels: {module: dns_mx}
+- targets: ["8.8.8.8"]
+  labels: {module: dns_any}
+- targets: ["10.0.0.1"]
+  labels: {module: http_2xx}
+- targets: ["grpc.internal:443"]
+  labels: {module: grpc}
+- targets: ["orphan"]
+  labels: {module: missing}
+- targets: ["unlabeled"]
+`
+	imp, err := ReadBlackbox([]byte(testBlackboxConfig), []byte(targets), "")
+	if err != nil {
+		t.Fatalf("ReadBlackbox failed: %v", err)
+	}
+
+	want := []models.MonitorGroup{{Name: DefaultBlackboxGroup, Monitors: []models.Monitor{
+		{Type: models.MonitorTypeHTTP, Name: "https://example.com", URL: "https://example.com", Timeout: models.Duration(5 * time.Second), Labels: map[string]string{"env": "prod"}},
+		{Type: models.MonitorTypeHTTP, Name: "example.org", URL: "http://example.org", Timeout: models.Duration(5 * time.Second), Labels: map[string]string{"env": "prod"}},
+		{
+			Type: models.MonitorTypeHTTP, Name: "api-health", URL: "https://example.com/health",
+			ExpectedStatus: 204, ExpectedResponse: "healthy",
+			Headers: map[string]string{"X-Probe": "blackbox", "Authorization": "Bearer s3cret"},
+		},
+		{Type: models.MonitorTypeTCP, Name: "db.internal:5432", Target: "db.internal:5432", Timeout: models.Duration(3 * time.Second)},
+		{Type: models.MonitorTypePing, Name: "10.0.0.1", Target: "10.0.0.1"},
+		{Type: models.MonitorTypeDNS, Name: "8.8.8.8", Target: "8.8.8.8", Query: "example.com", QueryType: "MX"},
+		{Type: models.MonitorTypeDNS, Name: "1.1.1.1", Target: "1.1.1.1", Query: "example.com", QueryType: "MX"},
+		{Type: models.MonitorTypeHTTP, Name: "10.0.0.1 (http_2xx)", URL: "http://10.0.0.1", Timeout: models.Duration(5 * time.Second)},
+	}}}
+	if !reflect.DeepEqual(imp.Groups, want) {
+		t.Errorf("groups = %+v\nwant %+v", imp.Groups, want)
+	}
+
+	wantWarnings := []string{
+		"https://example.com/health: checks are sent as GET instead of POST",
+		"https://example.com/health: TLS certificate errors are no longer ignored",
+		"db.internal: tcp target must be host:port; skipped",
+		"8.8.8.8: DNS query type ANY is not supported; skipped",
+		`grpc.internal:443: prober "grpc" of module grpc is not supported; skipped`,
+		"orphan: module missing is not in the config; skipped",
+		"unlabeled: no module label; skipped",
+	}
+	if !reflect.DeepEqual(imp.Warnings, wantWarnings) {
+		t.Errorf("warnings = %q\nwant %q", imp.Warnings, wantWarnings)
+	}
+	if imp.HasHistory() {
+		t.Error("blackbox imports have no history")
+	}
+}
+
+func TestReadBlackboxJSONTargets(t *testing.T) {
+	targets := `[{"targets": ["https://example.com"], "labels": {"module": "http_2xx"}}]`
+	imp, err := ReadBlackbox([]byte(testBlackboxConfig), []byte(targets), "probes")
+	if err != nil {
+		t.Fatalf("ReadBlackbox failed: %v", err)
+	}
+	if len(imp.Groups) != 1 || imp.Groups[0].Name != "probes" || len(imp.Groups[0].Monitors) != 1 {
+		t.Errorf("unexpected groups %+v", imp.Groups)
+	}
+}
+
+func TestIsLiteralPattern(t *testing.T) {
+	tests := map[string]bool{
+		"healthy":       true,
+		`"status":"ok"`: true,
+		"ok|healthy":    false,
+		"^ok$":          false,
+		"version [0-9]": false,
+		"(":             false,
+	}
+	for pattern, want := range tests {
+		if got := isLiteralPattern(pattern); got != want {
+			t.Errorf("isLiteralPattern(%q) = %v, want %v", pattern, got, want)
+		}
+	}
+}
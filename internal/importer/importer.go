@@ -0,0 +1,104 @@
+// Package importer converts monitors, and where available their history,
+// from other monitoring tools into Hall Monitor configuration and results.
+package importer
+
+import (
+	"fmt"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Import holds what was read from another tool
+type Import struct {
+	Source string
+	Groups []models.MonitorGroup
+
+	// Warnings describe monitors that were skipped and settings that could
+	// not be carried over
+	Warnings []string
+
+	// history streams past results when the source has them
+	history func(fn func(*models.MonitorResult) error) error
+}
+
+// MonitorCount returns the number of imported monitors
+func (imp *Import) MonitorCount() int {
+	count := 0
+	for _, group := range imp.Groups {
+		count += len(group.Monitors)
+	}
+	return count
+}
+
+// Merge adds the imported monitors to cfg, into existing groups of the same
+// name or as new groups. Monitors whose names are already taken are skipped
+// with a warning. It returns the names of the monitors added.
+func (imp *Import) Merge(cfg *config.Config) []string {
+	var added []string
+	for _, group := range imp.Groups {
+		var monitors []models.Monitor
+		for _, monitor := range group.Monitors {
+			if _, _, found := cfg.FindMonitor(monitor.Name); found {
+				imp.warnf("%s: a monitor with this name already exists; skipped", monitor.Name)
+				continue
+			}
+			monitors = append(monitors, monitor)
+			added = append(added, monitor.Name)
+		}
+		if len(monitors) == 0 {
+			continue
+		}
+
+		if i, found := cfg.FindGroup(group.Name); found {
+			cfg.Monitoring.Groups[i].Monitors = append(cfg.Monitoring.Groups[i].Monitors, monitors...)
+			continue
+		}
+		group.Monitors = monitors
+		cfg.Monitoring.Groups = append(cfg.Monitoring.Groups, group)
+	}
+	return added
+}
+
+// HasHistory reports whether the source has past results to backfill
+func (imp *Import) HasHistory() bool {
+	return imp.history != nil
+}
+
+// History calls fn with each past result of the named monitors, until fn
+// returns an error
+func (imp *Import) History(monitors []string, fn func(*models.MonitorResult) error) error {
+	if imp.history == nil {
+		return nil
+	}
+	include := make(map[string]bool, len(monitors))
+	for _, name := range monitors {
+		include[name] = true
+	}
+	return imp.history(func(result *models.MonitorResult) error {
+		if !include[result.Monitor] {
+			return nil
+		}
+		return fn(result)
+	})
+}
+
+// Backfill stores the past results of the named monitors and returns how
+// many were stored
+func (imp *Import) Backfill(store storage.ResultStore, monitors []string) (int, error) {
+	stored := 0
+	err := imp.History(monitors, func(result *models.MonitorResult) error {
+		if err := store.StoreResult(result); err != nil {
+			return fmt.Errorf("failed to store result for %s: %w", result.Monitor, err)
+		}
+		stored++
+		return nil
+	})
+	return stored, err
+}
+
+// warnf records a warning
+func (imp *Import) warnf(format string, args ...interface{}) {
+	imp.Warnings = append(imp.Warnings, fmt.Sprintf(format, args...))
+}
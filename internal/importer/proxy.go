@@ -0,0 +1,525 @@
+package importer
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Reverse proxy config formats read by ScanProxyConfig
+const (
+	ProxyCaddy   = "caddy"
+	ProxyTraefik = "traefik"
+	ProxyNginx   = "nginx"
+)
+
+// proxySite is a virtual host found in a proxy config
+type proxySite struct {
+	scheme string
+	host   string // Host, with the port when it is not the scheme's default
+	path   string
+}
+
+// DetectProxyFormat guesses the format of a proxy config from its file name
+// and content. It returns "" when the format cannot be told.
+func DetectProxyFormat(path string, data []byte) string {
+	name := strings.ToLower(filepath.Base(path))
+	switch {
+	case strings.Contains(name, "caddyfile"):
+		return ProxyCaddy
+	case strings.HasSuffix(name, ".conf"):
+		return ProxyNginx
+	case strings.HasSuffix(name, ".yml"), strings.HasSuffix(name, ".yaml"), strings.HasSuffix(name, ".json"):
+		return ProxyTraefik
+	}
+
+	content := string(data)
+	switch {
+	case strings.Contains(content, "routers"):
+		return ProxyTraefik
+	case strings.Contains(content, "server_name"):
+		return ProxyNginx
+	case strings.Contains(content, "reverse_proxy"), strings.Contains(content, "file_server"):
+		return ProxyCaddy
+	}
+	return ""
+}
+
+// ScanProxyConfig proposes an HTTP monitor for each virtual host of a
+// reverse proxy config: the sites of a Caddyfile, the Host rules of Traefik
+// dynamic config routers, or the server_name of nginx server blocks. Monitors
+// go into group, which defaults to the format name. A host served over both
+// HTTP and HTTPS is only checked over HTTPS.
+func ScanProxyConfig(data []byte, format, group string) (*Import, error) {
+	imp := &Import{Source: format}
+
+	var sites []proxySite
+	switch format {
+	case ProxyCaddy:
+		sites = scanCaddyfile(string(data), imp)
+	case ProxyTraefik:
+		var err error
+		if sites, err = scanTraefik(data, imp); err != nil {
+			return nil, err
+		}
+	case ProxyNginx:
+		sites = scanNginx(string(data), imp)
+	default:
+		return nil, fmt.Errorf("unknown proxy config format %q (expected %s, %s, or %s)", format, ProxyCaddy, ProxyTraefik, ProxyNginx)
+	}
+	if group == "" {
+		group = format
+	}
+
+	// The monitor name is the URL without its scheme, so one name covers
+	// both schemes of a host
+	var names []string
+	urls := make(map[string]string)
+	for _, site := range sites {
+		name := site.host + site.path
+		if existing, ok := urls[name]; ok {
+			if strings.HasPrefix(existing, "http://") && site.scheme == "https" {
+				urls[name] = site.url()
+			}
+			continue
+		}
+		names = append(names, name)
+		urls[name] = site.url()
+	}
+
+	if len(names) > 0 {
+		monitors := make([]models.Monitor, len(names))
+		for i, name := range names {
+			monitors[i] = models.Monitor{Type: models.MonitorTypeHTTP, Name: name, URL: urls[name]}
+		}
+		imp.Groups = []models.MonitorGroup{{Name: group, Monitors: monitors}}
+	}
+	return imp, nil
+}
+
+// url returns the address checked for the site
+func (s proxySite) url() string {
+	path := s.path
+	if path == "" {
+		path = "/"
+	}
+	return s.scheme + "://" + s.host + path
+}
+
+// newProxySite builds a site for host, dropping the port when it is the
+// scheme's default
+func newProxySite(scheme, host, port, path string) proxySite {
+	if port != "" && !(scheme == "https" && port == "443") && !(scheme == "http" && port == "80") {
+		host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		host = "[" + host + "]"
+	}
+	if path == "/" {
+		path = ""
+	}
+	return proxySite{scheme: scheme, host: host, path: path}
+}
+
+// scanCaddyfile reads the site addresses of a Caddyfile. Snippets, the
+// global options block, and addresses without a host are skipped.
+func scanCaddyfile(content string, imp *Import) []proxySite {
+	var sites []proxySite
+	var addresses []string
+	depth := 0
+	first := true
+	single := false // The Caddyfile has one site without braces
+	for _, line := range strings.Split(content, "\n") {
+		tokens := caddyTokens(line)
+		if len(tokens) == 0 {
+			continue
+		}
+
+		if depth > 0 {
+			for _, token := range tokens {
+				switch token {
+				case "{":
+					depth++
+				case "}":
+					depth--
+				}
+			}
+			continue
+		}
+
+		opens := tokens[len(tokens)-1] == "{"
+		if opens {
+			tokens = tokens[:len(tokens)-1]
+			depth++
+		}
+		if single {
+			continue
+		}
+		addresses = append(addresses, tokens...)
+
+		// Addresses continue on the next line after a trailing comma
+		if !opens && strings.HasSuffix(tokens[len(tokens)-1], ",") {
+			continue
+		}
+
+		switch {
+		case len(addresses) == 0:
+			// Global options block
+		case strings.HasPrefix(addresses[0], "("):
+			// Snippet
+		case addresses[0] == "import":
+			imp.warnf("import %s is not followed", strings.Join(addresses[1:], " "))
+		case opens || first:
+			// Without braces a Caddyfile has one site, on its first line
+			single = !opens
+			for _, address := range addresses {
+				if address = strings.TrimSuffix(address, ","); address != "" {
+					if site, ok := caddySite(address, imp); ok {
+						sites = append(sites, site)
+					}
+				}
+			}
+		}
+		first = false
+		addresses = nil
+	}
+	return sites
+}
+
+// caddyTokens splits a Caddyfile line into tokens, dropping comments
+func caddyTokens(line string) []string {
+	var tokens []string
+	var token strings.Builder
+	inQuote := false
+	started := false
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			started = true
+		case inQuote:
+			token.WriteRune(r)
+		case r == ' ' || r == '\t' || r == '\r':
+			if started {
+				tokens = append(tokens, token.String())
+				token.Reset()
+				started = false
+			}
+		case r == '#' && !started:
+			return tokens
+		default:
+			token.WriteRune(r)
+			started = true
+		}
+	}
+	if started {
+		tokens = append(tokens, token.String())
+	}
+	return tokens
+}
+
+// caddySite converts a Caddy site address. Addresses without a scheme are
+// served over HTTPS unless their port is 80, as Caddy does.
+func caddySite(address string, imp *Import) (proxySite, bool) {
+	if strings.Contains(address, "{") {
+		imp.warnf("%s: placeholder addresses are not expanded; skipped", address)
+		return proxySite{}, false
+	}
+
+	raw := address
+	if !strings.Contains(raw, "://") {
+		scheme := "https"
+		if hostPort, _, _ := strings.Cut(raw, "/"); strings.HasSuffix(hostPort, ":80") {
+			scheme = "http"
+		}
+		raw = scheme + "://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		imp.warnf("%s: not an HTTP site address; skipped", address)
+		return proxySite{}, false
+	}
+	host := u.Hostname()
+	switch {
+	case host == "":
+		imp.warnf("%s: no host name; skipped", address)
+		return proxySite{}, false
+	case strings.Contains(host, "*"):
+		imp.warnf("%s: wildcard hosts cannot be checked; skipped", address)
+		return proxySite{}, false
+	}
+	path := strings.TrimSuffix(u.Path, "*")
+	return newProxySite(u.Scheme, host, u.Port(), path), true
+}
+
+// traefikConfig is the part of a Traefik dynamic config that is scanned
+type traefikConfig struct {
+	HTTP struct {
+		Routers map[string]traefikRouter `yaml:"routers"`
+	} `yaml:"http"`
+}
+
+// traefikRouter is an HTTP router
+type traefikRouter struct {
+	Rule        string      `yaml:"rule"`
+	EntryPoints []string    `yaml:"entryPoints"`
+	TLS         interface{} `yaml:"tls"`
+}
+
+var (
+	traefikHostRule = regexp.MustCompile(`\bHost\(([^)]*)\)`)
+	traefikPathRule = regexp.MustCompile("\\b(?:PathPrefix|Path)\\(\\s*[`\"]([^`\"]*)[`\"]")
+	traefikString   = regexp.MustCompile("[`\"]([^`\"]*)[`\"]")
+)
+
+// scanTraefik reads the Host rules of the routers in a Traefik dynamic
+// config in YAML or JSON. Routers with TLS, or on an entry point named
+// websecure or https, are checked over HTTPS.
+func scanTraefik(data []byte, imp *Import) ([]proxySite, error) {
+	var cfg traefikConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse Traefik dynamic config: %w", err)
+	}
+
+	names := make([]string, 0, len(cfg.HTTP.Routers))
+	for name := range cfg.HTTP.Routers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sites []proxySite
+	for _, name := range names {
+		router := cfg.HTTP.Routers[name]
+		scheme := "http"
+		if router.TLS != nil {
+			scheme = "https"
+		}
+		for _, entryPoint := range router.EntryPoints {
+			if entryPoint == "websecure" || entryPoint == "https" {
+				scheme = "https"
+			}
+		}
+
+		path := ""
+		if match := traefikPathRule.FindStringSubmatch(router.Rule); match != nil {
+			if strings.Contains(match[1], "{") {
+				imp.warnf("router %s: path patterns are not expanded; checking /", name)
+			} else {
+				path = match[1]
+			}
+		}
+
+		var hosts []string
+		for _, match := range traefikHostRule.FindAllStringSubmatch(router.Rule, -1) {
+			for _, host := range traefikString.FindAllStringSubmatch(match[1], -1) {
+				hosts = append(hosts, host[1])
+			}
+		}
+		if len(hosts) == 0 {
+			imp.warnf("router %s: no Host rule; skipped", name)
+			continue
+		}
+		for _, host := range hosts {
+			sites = append(sites, newProxySite(scheme, host, "", path))
+		}
+	}
+	return sites, nil
+}
+
+// nginxServer collects the directives of a server block
+type nginxServer struct {
+	names   []string
+	listens [][]string
+	sslOn   bool
+}
+
+// scanNginx reads the server blocks of an nginx config. Regex and catch-all
+// server names are skipped, and included files are not followed.
+func scanNginx(content string, imp *Import) []proxySite {
+	var sites []proxySite
+	var blocks []string // Names of the enclosing blocks
+	var server *nginxServer
+	var args []string
+	for _, token := range nginxTokens(content) {
+		switch token {
+		case "{":
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			if name == "server" && server == nil && !nginxInside(blocks, "stream", "mail") {
+				server = &nginxServer{}
+			}
+			blocks = append(blocks, name)
+			args = nil
+
+		case "}":
+			if len(blocks) == 0 {
+				continue
+			}
+			closed := blocks[len(blocks)-1]
+			blocks = blocks[:len(blocks)-1]
+			if closed == "server" && server != nil && !nginxInside(blocks, "server") {
+				sites = append(sites, server.sites(imp)...)
+				server = nil
+			}
+			args = nil
+
+		case ";":
+			if len(args) == 0 {
+				continue
+			}
+			switch {
+			case args[0] == "include":
+				imp.warnf("include %s is not followed", strings.Join(args[1:], " "))
+			case server != nil && len(blocks) > 0 && blocks[len(blocks)-1] == "server":
+				switch args[0] {
+				case "server_name":
+					server.names = append(server.names, args[1:]...)
+				case "listen":
+					server.listens = append(server.listens, args[1:])
+				case "ssl":
+					server.sslOn = len(args) > 1 && args[1] == "on"
+				}
+			}
+			args = nil
+
+		default:
+			args = append(args, token)
+		}
+	}
+	return sites
+}
+
+// nginxInside reports whether any enclosing block has one of names
+func nginxInside(blocks []string, names ...string) bool {
+	for _, block := range blocks {
+		for _, name := range names {
+			if block == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sites converts the server names of a block, checked on its first TLS
+// listener, or its first listener when it has none
+func (s *nginxServer) sites(imp *Import) []proxySite {
+	var chosen []string
+	for _, listen := range s.listens {
+		if len(listen) == 0 || strings.HasPrefix(listen[0], "unix:") {
+			continue
+		}
+		if chosen == nil {
+			chosen = listen
+		}
+		if nginxSSL(listen) {
+			chosen = listen
+			break
+		}
+	}
+	scheme, port := "http", "80"
+	if chosen != nil {
+		port = nginxPort(chosen[0])
+		if s.sslOn || nginxSSL(chosen) {
+			scheme = "https"
+		}
+	}
+
+	var sites []proxySite
+	for _, name := range s.names {
+		switch {
+		case name == "_" || name == "":
+			// Catch-all server
+		case strings.HasPrefix(name, "~"):
+			imp.warnf("server_name %s: regex names cannot be checked; skipped", name)
+		case strings.Contains(name, "*"), strings.Contains(name, "$"):
+			imp.warnf("server_name %s: wildcard names cannot be checked; skipped", name)
+		default:
+			// .example.com matches example.com and its subdomains
+			sites = append(sites, newProxySite(scheme, strings.TrimPrefix(name, "."), port, ""))
+		}
+	}
+	if len(s.names) == 0 {
+		imp.warnf("server block on port %s has no server_name; skipped", port)
+	}
+	return sites
+}
+
+// nginxSSL reports whether a listen directive has the ssl flag
+func nginxSSL(listen []string) bool {
+	for _, flag := range listen[1:] {
+		if flag == "ssl" {
+			return true
+		}
+	}
+	return false
+}
+
+// nginxPort returns the port of a listen address, which may be a port, an
+// address, or address:port
+func nginxPort(address string) string {
+	if _, port, err := net.SplitHostPort(address); err == nil {
+		return port
+	}
+	if strings.Trim(address, "0123456789") == "" {
+		return address
+	}
+	return "80"
+}
+
+// nginxTokens splits an nginx config into words and the ;, {, and }
+// punctuation, dropping comments and quotes
+func nginxTokens(content string) []string {
+	var tokens []string
+	var token strings.Builder
+	started := false
+	flush := func() {
+		if started {
+			tokens = append(tokens, token.String())
+			token.Reset()
+			started = false
+		}
+	}
+
+	var quote rune
+	comment := false
+	for _, r := range content {
+		switch {
+		case comment:
+			if r == '\n' {
+				comment = false
+			}
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				token.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			started = true
+		case r == '#':
+			flush()
+			comment = true
+		case r == ';' || r == '{' || r == '}':
+			flush()
+			tokens = append(tokens, string(r))
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			flush()
+		default:
+			token.WriteRune(r)
+			started = true
+		}
+	}
+	flush()
+	return tokens
+}
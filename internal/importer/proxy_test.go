@@ -0,0 +1,269 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// proxyURLs returns the name and URL of each scanned monitor
+func proxyURLs(imp *Import) map[string]string {
+	urls := make(map[string]string)
+	for _, group := range imp.Groups {
+		for _, monitor := range group.Monitors {
+			urls[monitor.Name] = monitor.URL
+		}
+	}
+	return urls
+}
+
+func TestScanCaddyfile(t *testing.T) {
+	caddyfile := `
+{
+	email admin@example.com
+}
+
+(common) {
+	encode gzip
+}
+
+# Main site
+example.com, www.example.com {
+	import common
+	reverse_proxy localhost:8080 {
+		header_up Host {host}
+	}
+}
+
+http://intranet.local, api.example.com:8443,
+docs.example.com/v2/* {
+	file_server
+}
+
+:8080 {
+	respond "ok"
+}
+
+*.example.com {
+	respond "wildcard"
+}
+
+{$DOMAIN} {
+	respond "env"
+}
+
+import sites/*.caddy
+`
+	imp, err := ScanProxyConfig([]byte(caddyfile), ProxyCaddy, "")
+	if err != nil {
+		t.Fatalf("ScanProxyConfig failed: %v", err)
+	}
+
+	want := map[string]string{
+		"example.com":          "https://example.com/",
+		"www.example.com":      "https://www.example.com/",
+		"intranet.local":       "http://intranet.local/",
+		"api.example.com:8443": "https://api.example.com:8443/",
+		"docs.example.com/v2/": "https://docs.example.com/v2/",
+	}
+	if got := proxyURLs(imp); !reflect.DeepEqual(got, want) {
+		t.Errorf("monitors = %v\nwant %v", got, want)
+	}
+	if imp.Groups[0].Name != ProxyCaddy {
+		t.Errorf("expected group %s, got %s", ProxyCaddy, imp.Groups[0].Name)
+	}
+
+	wantWarnings := []string{
+		":8080: no host name; skipped",
+		"*.example.com: wildcard hosts cannot be checked; skipped",
+		"{$DOMAIN}: placeholder addresses are not expanded; skipped",
+		"import sites/*.caddy is not followed",
+	}
+	if !reflect.DeepEqual(imp.Warnings, wantWarnings) {
+		t.Errorf("warnings = %q\nwant %q", imp.Warnings, wantWarnings)
+	}
+}
+
+func TestScanCaddyfileSingleSite(t *testing.T) {
+	caddyfile := "localhost:80\n\nreverse_proxy app:3000 {\n\tlb_policy first\n}\n"
+	imp, err := ScanProxyConfig([]byte(caddyfile), ProxyCaddy, "sites")
+	if err != nil {
+		t.Fatalf("ScanProxyConfig failed: %v", err)
+	}
+
+	want := []models.MonitorGroup{{Name: "sites", Monitors: []models.Monitor{
+		{Type: models.MonitorTypeHTTP, Name: "localhost", URL: "http://localhost/"},
+	}}}
+	if !reflect.DeepEqual(imp.Groups, want) {
+		t.Errorf("groups = %+v\nwant %+v", imp.Groups, want)
+	}
+}
+
+func TestScanTraefik(t *testing.T) {
+	config := "http:\n" +
+		"  routers:\n" +
+		"    app:\n" +
+		"      rule: \"Host(`app.example.com`) && PathPrefix(`/api`)\"\n" +
+		"      entryPoints: [websecure]\n" +
+		"    blog:\n" +
+		"      rule: \"Host(`blog.example.com`, `www.blog.example.com`)\"\n" +
+		"      tls:\n" +
+		"        certResolver: le\n" +
+		"    legacy:\n" +
+		"      rule: \"Host(`legacy.example.com`) || Host(`old.example.com`)\"\n" +
+		"      entryPoints: [web]\n" +
+		"    catchall:\n" +
+		"      rule: \"PathPrefix(`/`)\"\n" +
+		"    regexp:\n" +
+		"      rule: \"HostRegexp(`{sub:[a-z]+}.example.com`)\"\n"
+
+	imp, err := ScanProxyConfig([]byte(config), ProxyTraefik, "")
+	if err != nil {
+		t.Fatalf("ScanProxyConfig failed: %v", err)
+	}
+
+	want := map[string]string{
+		"app.example.com/api":  "https://app.example.com/api",
+		"blog.example.com":     "https://blog.example.com/",
+		"www.blog.example.com": "https://www.blog.example.com/",
+		"legacy.example.com":   "http://legacy.example.com/",
+		"old.example.com":      "http://old.example.com/",
+	}
+	if got := proxyURLs(imp); !reflect.DeepEqual(got, want) {
+		t.Errorf("monitors = %v\nwant %v", got, want)
+	}
+
+	wantWarnings := []string{
+		"router catchall: no Host rule; skipped",
+		"router regexp: no Host rule; skipped",
+	}
+	if !reflect.DeepEqual(imp.Warnings, wantWarnings) {
+		t.Errorf("warnings = %q\nwant %q", imp.Warnings, wantWarnings)
+	}
+}
+
+func TestScanTraefikJSON(t *testing.T) {
+	config := `{"http": {"routers": {"app": {"rule": "Host(` + "`app.example.com`" + `)", "tls": {}}}}}`
+	imp, err := ScanProxyConfig([]byte(config), ProxyTraefik, "")
+	if err != nil {
+		t.Fatalf("ScanProxyConfig failed: %v", err)
+	}
+	if got := proxyURLs(imp); got["app.example.com"] != "https://app.example.com/" {
+		t.Errorf("unexpected monitors %v", got)
+	}
+}
+
+func TestScanNginx(t *testing.T) {
+	config := `
+events {}
+
+stream {
+	server {
+		listen 53 udp;
+		server_name dns.example.com;
+	}
+}
+
+http {
+	include mime.types;
+
+	upstream app {
+		server 127.0.0.1:3000;
+	}
+
+	# Redirect to HTTPS
+	server {
+		listen 80;
+		server_name example.com www.example.com;
+		return 301 https://$host$request_uri;
+	}
+
+	server {
+		listen 80;
+		listen [::]:443 ssl http2;
+		server_name example.com www.example.com;
+		location / {
+			proxy_pass http://app;
+		}
+	}
+
+	server {
+		listen 127.0.0.1:8080;
+		server_name "internal.example.com" .static.example.com;
+	}
+
+	server {
+		listen 8443;
+		ssl on;
+		server_name secure.example.com;
+	}
+
+	server {
+		listen 80 default_server;
+		server_name _;
+		return 444;
+	}
+
+	server {
+		server_name ~^(?<user>.+)\.users\.example\.com$ *.example.org;
+	}
+
+	server {
+		listen 81;
+	}
+}
+`
+	imp, err := ScanProxyConfig([]byte(config), ProxyNginx, "")
+	if err != nil {
+		t.Fatalf("ScanProxyConfig failed: %v", err)
+	}
+
+	want := map[string]string{
+		"example.com":               "https://example.com/",
+		"www.example.com":           "https://www.example.com/",
+		"internal.example.com:8080": "http://internal.example.com:8080/",
+		"static.example.com:8080":   "http://static.example.com:8080/",
+		"secure.example.com:8443":   "https://secure.example.com:8443/",
+	}
+	if got := proxyURLs(imp); !reflect.DeepEqual(got, want) {
+		t.Errorf("monitors = %v\nwant %v", got, want)
+	}
+
+	wantWarnings := []string{
+		"include mime.types is not followed",
+		`server_name ~^(?<user>.+)\.users\.example\.com$: regex names cannot be checked; skipped`,
+		"server_name *.example.org: wildcard names cannot be checked; skipped",
+		"server block on port 81 has no server_name; skipped",
+	}
+	if !reflect.DeepEqual(imp.Warnings, wantWarnings) {
+		t.Errorf("warnings = %q\nwant %q", imp.Warnings, wantWarnings)
+	}
+}
+
+func TestDetectProxyFormat(t *testing.T) {
+	tests := []struct {
+		path    string
+		content string
+		want    string
+	}{
+		{"/etc/caddy/Caddyfile", "", ProxyCaddy},
+		{"/etc/nginx/sites-enabled/app.conf", "", ProxyNginx},
+		{"dynamic.yml", "", ProxyTraefik},
+		{"/etc/nginx/sites-enabled/default", "server {\n\tserver_name example.com;\n}", ProxyNginx},
+		{"-", "http:\n  routers: {}", ProxyTraefik},
+		{"sites", "example.com {\n\treverse_proxy app:80\n}", ProxyCaddy},
+		{"unknown", "hello", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectProxyFormat(tt.path, []byte(tt.content)); got != tt.want {
+			t.Errorf("DetectProxyFormat(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestScanProxyConfigUnknownFormat(t *testing.T) {
+	if _, err := ScanProxyConfig(nil, "apache", ""); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
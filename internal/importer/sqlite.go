@@ -0,0 +1,443 @@
+package importer
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// sqliteMagic starts every SQLite 3 database file
+const sqliteMagic = "SQLite format 3\x00"
+
+// Page types of table b-trees; index b-trees are never read
+const (
+	pageTableInterior = 0x05
+	pageTableLeaf     = 0x0d
+)
+
+// sqliteDB reads tables of a SQLite 3 database file. It understands just
+// enough of the file format to scan rowid tables, so imports need neither
+// cgo nor a database driver. Changes still in a -wal file are not seen.
+type sqliteDB struct {
+	r        io.ReaderAt
+	pageSize int
+	usable   int // Page size less the reserved bytes at the end of each page
+	pages    uint32
+}
+
+// sqliteTable describes a table from the schema
+type sqliteTable struct {
+	name     string
+	rootPage uint32
+	columns  []string
+	rowidCol int // Index of the INTEGER PRIMARY KEY column, or -1
+}
+
+// openSQLite checks the database header
+func openSQLite(r io.ReaderAt, size int64) (*sqliteDB, error) {
+	header := make([]byte, 100)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("failed to read database header: %w", err)
+	}
+	if string(header[:16]) != sqliteMagic {
+		return nil, errors.New("not a SQLite 3 database")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(header[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	if encoding := binary.BigEndian.Uint32(header[56:60]); encoding > 1 {
+		return nil, errors.New("only UTF-8 databases are supported")
+	}
+
+	return &sqliteDB{
+		r:        r,
+		pageSize: pageSize,
+		usable:   pageSize - int(header[20]),
+		pages:    uint32(size / int64(pageSize)),
+	}, nil
+}
+
+// page reads a page by its 1-based number
+func (db *sqliteDB) page(number uint32) ([]byte, error) {
+	if number == 0 || number > db.pages {
+		return nil, fmt.Errorf("page %d out of range", number)
+	}
+	buf := make([]byte, db.pageSize)
+	if _, err := db.r.ReadAt(buf, int64(number-1)*int64(db.pageSize)); err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %w", number, err)
+	}
+	return buf, nil
+}
+
+// table looks a table up in the schema
+func (db *sqliteDB) table(name string) (*sqliteTable, error) {
+	var found *sqliteTable
+	err := db.scanTree(1, func(_ int64, values []interface{}) error {
+		if len(values) < 5 || found != nil {
+			return nil
+		}
+		kind, _ := values[0].(string)
+		tableName, _ := values[1].(string)
+		if kind != "table" || !strings.EqualFold(tableName, name) {
+			return nil
+		}
+		rootPage, _ := values[3].(int64)
+		sql, _ := values[4].(string)
+		if strings.Contains(strings.ToUpper(sql), "WITHOUT ROWID") {
+			return fmt.Errorf("table %s is a WITHOUT ROWID table", name)
+		}
+		columns, rowidCol := parseColumns(sql)
+		found = &sqliteTable{name: tableName, rootPage: uint32(rootPage), columns: columns, rowidCol: rowidCol}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+	if found == nil {
+		return nil, fmt.Errorf("table %s not found", name)
+	}
+	return found, nil
+}
+
+// scan calls fn with each row of a table, keyed by column name. Columns
+// added after a row was written are missing from its map.
+func (db *sqliteDB) scan(table *sqliteTable, fn func(row map[string]interface{}) error) error {
+	return db.scanTree(table.rootPage, func(rowid int64, values []interface{}) error {
+		row := make(map[string]interface{}, len(table.columns))
+		for i, column := range table.columns {
+			if i < len(values) {
+				row[column] = values[i]
+			}
+		}
+		if table.rowidCol >= 0 {
+			row[table.columns[table.rowidCol]] = rowid
+		}
+		return fn(row)
+	})
+}
+
+// scanTree walks a table b-tree in rowid order
+func (db *sqliteDB) scanTree(root uint32, fn func(rowid int64, values []interface{}) error) error {
+	return db.walk(root, fn, 0)
+}
+
+const (
+	// maxTreeDepth stops cycles in a corrupt file from recursing forever
+	maxTreeDepth = 64
+
+	// maxPayloadSize bounds a single row, far above anything an import reads
+	maxPayloadSize = 64 << 20
+)
+
+// walk visits the cells of a b-tree page and its children
+
+func (db *sqliteDB) walk(number uint32, fn func(rowid int64, values []interface{}) error, depth int) error {
+	if depth > maxTreeDepth {
+		return errors.New("b-tree too deep; the file may be corrupt")
+	}
+	page, err := db.page(number)
+	if err != nil {
+		return err
+	}
+
+	// Page 1 starts with the database header
+	offset := 0
+	if number == 1 {
+		offset = 100
+	}
+	if len(page) < offset+12 {
+		return fmt.Errorf("page %d truncated", number)
+	}
+	kind := page[offset]
+	cells := int(binary.BigEndian.Uint16(page[offset+3:]))
+	headerSize := 8
+	if kind == pageTableInterior {
+		headerSize = 12
+	}
+	pointers := offset + headerSize
+	if pointers+2*cells > len(page) {
+		return fmt.Errorf("page %d has an invalid cell count", number)
+	}
+
+	for i := 0; i < cells; i++ {
+		cell := int(binary.BigEndian.Uint16(page[pointers+2*i:]))
+		if cell >= len(page) {
+			return fmt.Errorf("page %d has an invalid cell pointer", number)
+		}
+
+		switch kind {
+		case pageTableInterior:
+			if cell+4 > len(page) {
+				return fmt.Errorf("page %d has a truncated cell", number)
+			}
+			if err := db.walk(binary.BigEndian.Uint32(page[cell:]), fn, depth+1); err != nil {
+				return err
+			}
+		case pageTableLeaf:
+			rowid, values, err := db.leafCell(page, cell)
+			if err != nil {
+				return fmt.Errorf("page %d: %w", number, err)
+			}
+			if err := fn(rowid, values); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("page %d is not a table b-tree page", number)
+		}
+	}
+
+	if kind == pageTableInterior {
+		return db.walk(binary.BigEndian.Uint32(page[offset+8:]), fn, depth+1)
+	}
+	return nil
+}
+
+// leafCell decodes a table leaf cell, following overflow pages
+func (db *sqliteDB) leafCell(page []byte, cell int) (int64, []interface{}, error) {
+	payloadSize, n := readVarint(page[cell:])
+	if n == 0 {
+		return 0, nil, errors.New("truncated cell")
+	}
+	if payloadSize > maxPayloadSize {
+		return 0, nil, fmt.Errorf("payload of %d bytes is too large", payloadSize)
+	}
+	cell += n
+	rowid, n := readVarint(page[cell:])
+	if n == 0 {
+		return 0, nil, errors.New("truncated cell")
+	}
+	cell += n
+
+	// The part of the payload stored on the page, per the file format
+	total := int(payloadSize)
+	local := total
+	maxLocal := db.usable - 35
+	if total > maxLocal {
+		minLocal := (db.usable-12)*32/255 - 23
+		local = minLocal + (total-minLocal)%(db.usable-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if cell+local > len(page) {
+		return 0, nil, errors.New("truncated payload")
+	}
+
+	payload := make([]byte, 0, total)
+	payload = append(payload, page[cell:cell+local]...)
+	if local < total {
+		if cell+local+4 > len(page) {
+			return 0, nil, errors.New("truncated overflow pointer")
+		}
+		next := binary.BigEndian.Uint32(page[cell+local:])
+		for len(payload) < total {
+			if next == 0 {
+				return 0, nil, errors.New("overflow chain ends early")
+			}
+			overflow, err := db.page(next)
+			if err != nil {
+				return 0, nil, err
+			}
+			next = binary.BigEndian.Uint32(overflow)
+			chunk := overflow[4:db.usable]
+			if remaining := total - len(payload); len(chunk) > remaining {
+				chunk = chunk[:remaining]
+			}
+			payload = append(payload, chunk...)
+		}
+	}
+
+	values, err := decodeRecord(payload)
+	return int64(rowid), values, err
+}
+
+// decodeRecord decodes a record into int64, float64, string, []byte, or nil values
+func decodeRecord(payload []byte) ([]interface{}, error) {
+	headerSize, n := readVarint(payload)
+	if n == 0 || int(headerSize) > len(payload) {
+		return nil, errors.New("invalid record header")
+	}
+
+	var types []uint64
+	for pos := n; pos < int(headerSize); {
+		serialType, n := readVarint(payload[pos:int(headerSize)])
+		if n == 0 {
+			return nil, errors.New("invalid record header")
+		}
+		types = append(types, serialType)
+		pos += n
+	}
+
+	values := make([]interface{}, len(types))
+	body := payload[headerSize:]
+	for i, serialType := range types {
+		size := serialSize(serialType)
+		if size > len(body) {
+			return nil, errors.New("truncated record")
+		}
+		data := body[:size]
+		body = body[size:]
+
+		switch {
+		case serialType == 0:
+			values[i] = nil
+		case serialType <= 6:
+			values[i] = readInt(data)
+		case serialType == 7:
+			values[i] = math.Float64frombits(binary.BigEndian.Uint64(data))
+		case serialType == 8:
+			values[i] = int64(0)
+		case serialType == 9:
+			values[i] = int64(1)
+		case serialType >= 12 && serialType%2 == 0:
+			values[i] = append([]byte(nil), data...)
+		case serialType >= 13:
+			values[i] = string(data)
+		default:
+			return nil, fmt.Errorf("invalid serial type %d", serialType)
+		}
+	}
+	return values, nil
+}
+
+// serialSize is the length of a value of a serial type
+func serialSize(serialType uint64) int {
+	switch {
+	case serialType <= 4:
+		return [...]int{0, 1, 2, 3, 4}[serialType]
+	case serialType == 5:
+		return 6
+	case serialType == 6 || serialType == 7:
+		return 8
+	case serialType < 12:
+		return 0
+	default:
+		return int((serialType - 12) / 2)
+	}
+}
+
+// readInt reads a big-endian two's complement integer
+func readInt(data []byte) int64 {
+	var v int64
+	for i, b := range data {
+		if i == 0 {
+			v = int64(int8(b))
+		} else {
+			v = v<<8 | int64(b)
+		}
+	}
+	return v
+}
+
+// readVarint reads a SQLite varint and returns it with its length, or a
+// length of 0 when the data ends first
+func readVarint(data []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9; i++ {
+		if i >= len(data) {
+			return 0, 0
+		}
+		if i == 8 {
+			return v<<8 | uint64(data[i]), 9
+		}
+		v = v<<7 | uint64(data[i]&0x7f)
+		if data[i] < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v, 9
+}
+
+// parseColumns extracts column names from a CREATE TABLE statement, and the
+// index of the INTEGER PRIMARY KEY column whose value is the rowid
+func parseColumns(sql string) ([]string, int) {
+	start := strings.Index(sql, "(")
+	end := strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil, -1
+	}
+
+	var columns []string
+	rowidCol := -1
+	for _, definition := range splitTopLevel(sql[start+1 : end]) {
+		definition = strings.TrimSpace(definition)
+		upper := strings.ToUpper(definition)
+		if isTableConstraint(upper) {
+			continue
+		}
+		name, rest := splitIdentifier(definition)
+		fields := strings.Fields(strings.ToUpper(rest))
+		if len(fields) > 0 && fields[0] == "INTEGER" && strings.Contains(strings.Join(fields, " "), "PRIMARY KEY") {
+			rowidCol = len(columns)
+		}
+		columns = append(columns, name)
+	}
+	return columns, rowidCol
+}
+
+// isTableConstraint reports whether a definition is a table constraint rather
+// than a column
+func isTableConstraint(definition string) bool {
+	for _, keyword := range []string{"CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN"} {
+		if strings.HasPrefix(definition, keyword) {
+			rest := definition[len(keyword):]
+			if rest == "" || rest[0] == ' ' || rest[0] == '(' || rest[0] == '\t' || rest[0] == '\n' {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitTopLevel splits a column list on commas outside parentheses and quotes
+func splitTopLevel(list string) []string {
+	var parts []string
+	var quote byte
+	depth, start := 0, 0
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, list[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, list[start:])
+}
+
+// splitIdentifier splits the possibly quoted column name off a definition
+func splitIdentifier(definition string) (string, string) {
+	if definition == "" {
+		return "", ""
+	}
+	closing := map[byte]byte{'"': '"', '`': '`', '[': ']', '\'': '\''}[definition[0]]
+	if closing == 0 {
+		fields := strings.Fields(definition)
+		return fields[0], strings.TrimPrefix(definition, fields[0])
+	}
+	end := strings.IndexByte(definition[1:], closing)
+	if end < 0 {
+		return definition[1:], ""
+	}
+	return definition[1 : end+1], definition[end+2:]
+}
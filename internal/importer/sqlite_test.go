@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadVarint(t *testing.T) {
+	tests := []struct {
+		data  []byte
+		value uint64
+		size  int
+	}{
+		{data: []byte{0x00}, value: 0, size: 1},
+		{data: []byte{0x7f}, value: 127, size: 1},
+		{data: []byte{0x81, 0x00}, value: 128, size: 2},
+		{data: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, value: 1<<64 - 1, size: 9},
+		{data: []byte{0x81}, value: 0, size: 0}, // Truncated
+	}
+	for _, tt := range tests {
+		value, size := readVarint(tt.data)
+		if value != tt.value || size != tt.size {
+			t.Errorf("readVarint(%x) = %d, %d; want %d, %d", tt.data, value, size, tt.value, tt.size)
+		}
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		columns  []string
+		rowidCol int
+	}{
+		{
+			name:     "knex",
+			sql:      `CREATE TABLE "monitor" ("id" integer not null primary key autoincrement, "name" varchar(150), "accepted_statuscodes_json" text not null default '["200-299"]', "parent" INTEGER REFERENCES "monitor" ("id") ON DELETE SET NULL)`,
+			columns:  []string{"id", "name", "accepted_statuscodes_json", "parent"},
+			rowidCol: 0,
+		},
+		{
+			name:     "table constraints",
+			sql:      "CREATE TABLE t ([key] TEXT, `value` NUMERIC(10, 2), PRIMARY KEY (key), UNIQUE (value))",
+			columns:  []string{"key", "value"},
+			rowidCol: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, rowidCol := parseColumns(tt.sql)
+			if !reflect.DeepEqual(columns, tt.columns) || rowidCol != tt.rowidCol {
+				t.Errorf("parseColumns() = %q, %d; want %q, %d", columns, rowidCol, tt.columns, tt.rowidCol)
+			}
+		})
+	}
+}
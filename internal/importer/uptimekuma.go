@@ -0,0 +1,354 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// DefaultUptimeKumaGroup holds imported monitors that are not in an Uptime
+// Kuma group
+const DefaultUptimeKumaGroup = "uptime-kuma"
+
+// Uptime Kuma heartbeat statuses
+const (
+	kumaDown        = 0
+	kumaUp          = 1
+	kumaPending     = 2
+	kumaMaintenance = 3
+)
+
+// kumaTimeLayouts are the formats heartbeat times are stored in, in UTC
+var kumaTimeLayouts = []string{"2006-01-02 15:04:05.000", "2006-01-02 15:04:05", time.RFC3339Nano}
+
+// kumaMonitor is an imported Uptime Kuma monitor
+type kumaMonitor struct {
+	name  string
+	group string
+	kind  models.MonitorType
+}
+
+// ReadUptimeKuma reads monitors from an Uptime Kuma database (kuma.db).
+// HTTP, keyword, TCP port, ping, and DNS monitors are converted; Uptime Kuma
+// groups become groups, and monitors outside one go in defaultGroup. The
+// reader must stay open while History is used.
+func ReadUptimeKuma(r io.ReaderAt, size int64, defaultGroup string) (*Import, error) {
+	db, err := openSQLite(r, size)
+	if err != nil {
+		return nil, err
+	}
+	monitorTable, err := db.table("monitor")
+	if err != nil {
+		return nil, fmt.Errorf("not an Uptime Kuma database: %w", err)
+	}
+	if defaultGroup == "" {
+		defaultGroup = DefaultUptimeKumaGroup
+	}
+
+	var rows []map[string]interface{}
+	if err := db.scan(monitorTable, func(row map[string]interface{}) error {
+		rows = append(rows, row)
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("failed to read monitors: %w", err)
+	}
+
+	// Group monitors first, so members can find their group's name
+	groupNames := make(map[int64]string)
+	for _, row := range rows {
+		if kumaString(row, "type") == "group" {
+			groupNames[kumaInt(row, "id")] = kumaString(row, "name")
+		}
+	}
+
+	imp := &Import{Source: "uptime-kuma"}
+	groups := make(map[string]*models.MonitorGroup)
+	var groupOrder []string
+	monitors := make(map[int64]kumaMonitor)
+	names := make(map[string]bool)
+
+	for _, row := range rows {
+		if kumaString(row, "type") == "group" {
+			continue
+		}
+		monitor, warnings, err := convertKumaMonitor(row)
+		name := kumaString(row, "name")
+		for _, warning := range warnings {
+			imp.warnf("%s: %s", name, warning)
+		}
+		if err != nil {
+			imp.warnf("%s: %v; skipped", name, err)
+			continue
+		}
+
+		// Uptime Kuma allows duplicate names; Hall Monitor does not
+		for i := 2; names[monitor.Name]; i++ {
+			monitor.Name = fmt.Sprintf("%s (%d)", name, i)
+		}
+		if monitor.Name != name {
+			imp.warnf("%s: name already used; imported as %s", name, monitor.Name)
+		}
+		names[monitor.Name] = true
+
+		groupName := groupNames[kumaInt(row, "parent")]
+		if groupName == "" {
+			groupName = defaultGroup
+		}
+		group, ok := groups[groupName]
+		if !ok {
+			group = &models.MonitorGroup{Name: groupName}
+			groups[groupName] = group
+			groupOrder = append(groupOrder, groupName)
+		}
+		group.Monitors = append(group.Monitors, monitor)
+		monitors[kumaInt(row, "id")] = kumaMonitor{name: monitor.Name, group: groupName, kind: monitor.Type}
+	}
+
+	sort.Strings(groupOrder)
+	for _, name := range groupOrder {
+		imp.Groups = append(imp.Groups, *groups[name])
+	}
+
+	if heartbeats, err := db.table("heartbeat"); err == nil {
+		imp.history = func(fn func(*models.MonitorResult) error) error {
+			return db.scan(heartbeats, func(row map[string]interface{}) error {
+				monitor, ok := monitors[kumaInt(row, "monitor_id")]
+				if !ok {
+					return nil
+				}
+				result, ok := convertHeartbeat(row, monitor)
+				if !ok {
+					return nil
+				}
+				return fn(result)
+			})
+		}
+	}
+	return imp, nil
+}
+
+// convertKumaMonitor converts a row of the monitor table. Settings that
+// cannot be carried over are returned as warnings; monitors that would
+// behave differently are rejected.
+func convertKumaMonitor(row map[string]interface{}) (models.Monitor, []string, error) {
+	var warnings []string
+	monitor := models.Monitor{
+		Name:        kumaString(row, "name"),
+		Description: kumaString(row, "description"),
+		Retries:     int(kumaInt(row, "maxretries")),
+	}
+	if interval := kumaInt(row, "interval"); interval > 0 {
+		monitor.Interval = models.Duration(time.Duration(interval) * time.Second)
+	}
+	if timeout := kumaFloat(row, "timeout"); timeout > 0 {
+		monitor.Timeout = models.Duration(time.Duration(timeout * float64(time.Second)))
+	}
+	if _, ok := row["active"]; ok && kumaInt(row, "active") == 0 {
+		enabled := false
+		monitor.Enabled = &enabled
+	}
+	if kumaInt(row, "upside_down") != 0 {
+		return monitor, nil, fmt.Errorf("upside down mode is not supported")
+	}
+
+	kind := kumaString(row, "type")
+	switch kind {
+	case "http", "keyword":
+		monitor.Type = models.MonitorTypeHTTP
+		monitor.URL = kumaString(row, "url")
+		if kind == "keyword" {
+			if kumaInt(row, "invert_keyword") != 0 {
+				return monitor, nil, fmt.Errorf("inverted keywords are not supported")
+			}
+			monitor.ExpectedResponse = kumaString(row, "keyword")
+		}
+		warnings = append(warnings, convertKumaHTTP(row, &monitor)...)
+
+	case "port":
+		monitor.Type = models.MonitorTypeTCP
+		monitor.Target = net.JoinHostPort(kumaString(row, "hostname"), strconv.FormatInt(kumaInt(row, "port"), 10))
+
+	case "ping":
+		monitor.Type = models.MonitorTypePing
+		monitor.Target = kumaString(row, "hostname")
+
+	case "dns":
+		monitor.Type = models.MonitorTypeDNS
+		monitor.Query = kumaString(row, "hostname")
+		monitor.QueryType = strings.ToUpper(kumaString(row, "dns_resolve_type"))
+		switch monitor.QueryType {
+		case "", "A", "AAAA", "CNAME", "MX", "TXT", "NS":
+		default:
+			return monitor, nil, fmt.Errorf("DNS record type %s is not supported", monitor.QueryType)
+		}
+		server := kumaString(row, "dns_resolve_server")
+		if server == "" {
+			server = "1.1.1.1" // Uptime Kuma's default
+		}
+		monitor.Target = server
+		if port := kumaInt(row, "port"); port != 0 && port != 53 {
+			monitor.Target = net.JoinHostPort(server, strconv.FormatInt(port, 10))
+		}
+
+	default:
+		return monitor, nil, fmt.Errorf("monitor type %q is not supported", kind)
+	}
+	return monitor, warnings, nil
+}
+
+// convertKumaHTTP carries over the request settings of HTTP and keyword monitors
+func convertKumaHTTP(row map[string]interface{}, monitor *models.Monitor) []string {
+	var warnings []string
+
+	if method := strings.ToUpper(kumaString(row, "method")); method != "" && method != "GET" {
+		warnings = append(warnings, fmt.Sprintf("checks are sent as GET instead of %s", method))
+	}
+	if kumaInt(row, "ignore_tls") != 0 {
+		warnings = append(warnings, "TLS certificate errors are no longer ignored")
+	}
+
+	if headers := kumaString(row, "headers"); strings.TrimSpace(headers) != "" {
+		values := make(map[string]interface{})
+		if err := json.Unmarshal([]byte(headers), &values); err != nil {
+			warnings = append(warnings, "headers are not a JSON object and were dropped")
+		} else {
+			monitor.Headers = make(map[string]string, len(values))
+			for key, value := range values {
+				monitor.Headers[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	user := kumaString(row, "basic_auth_user")
+	switch authMethod := kumaString(row, "auth_method"); {
+	case (authMethod == "" || authMethod == "basic") && user != "":
+		if monitor.Headers == nil {
+			monitor.Headers = make(map[string]string)
+		}
+		credentials := user + ":" + kumaString(row, "basic_auth_pass")
+		monitor.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+	case authMethod != "" && authMethod != "basic":
+		warnings = append(warnings, fmt.Sprintf("%s authentication is not supported and was dropped", authMethod))
+	}
+
+	// A single accepted code carries over; ranges fall back to expecting 200
+	var accepted []string
+	if err := json.Unmarshal([]byte(kumaString(row, "accepted_statuscodes_json")), &accepted); err == nil && len(accepted) > 0 {
+		if code, err := strconv.Atoi(accepted[0]); err == nil && len(accepted) == 1 {
+			monitor.ExpectedStatus = code
+		} else if !acceptsStatus(accepted, 200) {
+			warnings = append(warnings, fmt.Sprintf("accepted status codes %s cannot be carried over; 200 is expected", strings.Join(accepted, ", ")))
+		}
+	}
+	return warnings
+}
+
+// acceptsStatus reports whether Uptime Kuma's accepted codes, single codes
+// or ranges like "200-299", include code
+func acceptsStatus(accepted []string, code int) bool {
+	for _, entry := range accepted {
+		low, high, isRange := strings.Cut(entry, "-")
+		if !isRange {
+			high = low
+		}
+		lo, errLow := strconv.Atoi(strings.TrimSpace(low))
+		hi, errHigh := strconv.Atoi(strings.TrimSpace(high))
+		if errLow == nil && errHigh == nil && lo <= code && code <= hi {
+			return true
+		}
+	}
+	return false
+}
+
+// convertHeartbeat converts a row of the heartbeat table. Pending and
+// maintenance heartbeats have no Hall Monitor equivalent and are skipped.
+func convertHeartbeat(row map[string]interface{}, monitor kumaMonitor) (*models.MonitorResult, bool) {
+	var status models.MonitorStatus
+	switch kumaInt(row, "status") {
+	case kumaUp:
+		status = models.StatusUp
+	case kumaDown:
+		status = models.StatusDown
+	default:
+		return nil, false
+	}
+
+	timestamp, ok := parseKumaTime(kumaString(row, "time"))
+	if !ok {
+		return nil, false
+	}
+
+	result := &models.MonitorResult{
+		Monitor:   monitor.name,
+		Type:      monitor.kind,
+		Group:     monitor.group,
+		Status:    status,
+		Duration:  time.Duration(kumaFloat(row, "ping") * float64(time.Millisecond)),
+		Timestamp: timestamp,
+	}
+	if status == models.StatusDown {
+		result.Error = kumaString(row, "msg")
+	}
+	return result, true
+}
+
+// parseKumaTime parses a heartbeat time
+func parseKumaTime(value string) (time.Time, bool) {
+	for _, layout := range kumaTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// kumaString reads a text column, treating NULL as empty
+func kumaString(row map[string]interface{}, column string) string {
+	switch v := row[column].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// kumaInt reads an integer column, treating NULL as 0
+func kumaInt(row map[string]interface{}, column string) int64 {
+	switch v := row[column].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return n
+	}
+	return 0
+}
+
+// kumaFloat reads a numeric column, treating NULL as 0
+func kumaFloat(row map[string]interface{}, column string) float64 {
+	switch v := row[column].(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		n, _ := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package importer
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// testdata/kuma.db is an Uptime Kuma schema with 1 KiB pages, so the monitor
+// and heartbeat tables span interior pages and the first monitor's headers
+// overflow. That monitor predates the parent, description, timeout, and
+// invert_keyword columns.
+func readTestKuma(t *testing.T) *Import {
+	t.Helper()
+	file, err := os.Open("testdata/kuma.db")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { file.Close() })
+	info, err := file.Stat()
+	if err != nil {
+		t.Fatalf("failed to stat test database: %v", err)
+	}
+
+	imp, err := ReadUptimeKuma(file, info.Size(), "")
+	if err != nil {
+		t.Fatalf("ReadUptimeKuma failed: %v", err)
+	}
+	return imp
+}
+
+func TestReadUptimeKumaMonitors(t *testing.T) {
+	imp := readTestKuma(t)
+
+	disabled := false
+	want := []models.MonitorGroup{
+		{Name: "Infrastructure", Monitors: []models.Monitor{
+			{
+				Type: models.MonitorTypeHTTP, Name: "API health", URL: "https://api.example.com/health",
+				ExpectedResponse: `"ok"`, ExpectedStatus: 204, Description: "Public API",
+				Interval: models.Duration(20 * time.Second), Timeout: models.Duration(48 * time.Second),
+			},
+			{Type: models.MonitorTypeTCP, Name: "Postgres", Target: "db.internal:5432", Interval: models.Duration(20 * time.Second), Enabled: &disabled},
+			{Type: models.MonitorTypePing, Name: "Gateway", Target: "10.0.0.1", Interval: models.Duration(20 * time.Second)},
+		}},
+		{Name: DefaultUptimeKumaGroup, Monitors: []models.Monitor{
+			{
+				Type: models.MonitorTypeHTTP, Name: "Website", URL: "https://example.com",
+				Interval: models.Duration(time.Minute), Retries: 2,
+				Headers: map[string]string{
+					"X-Token":       "abc",
+					"X-Long":        strings.Repeat("x", 3000),
+					"Authorization": "Basic YWRtaW46c2VjcmV0",
+				},
+			},
+			{Type: models.MonitorTypeDNS, Name: "MX records", Target: "8.8.8.8", Query: "example.com", QueryType: "MX", Interval: models.Duration(20 * time.Second)},
+			{Type: models.MonitorTypeHTTP, Name: "Website (2)", URL: "https://www.example.com", Interval: models.Duration(20 * time.Second)},
+		}},
+	}
+	if !reflect.DeepEqual(imp.Groups, want) {
+		t.Errorf("groups = %+v\nwant %+v", imp.Groups, want)
+	}
+
+	wantWarnings := []string{
+		"Website: checks are sent as GET instead of POST",
+		"Website: TLS certificate errors are no longer ignored",
+		"Website: accepted status codes 300-399 cannot be carried over; 200 is expected",
+		"Website: name already used; imported as Website (2)",
+		`Docker: monitor type "docker" is not supported; skipped`,
+		"Inverted: upside down mode is not supported; skipped",
+		"SOA: DNS record type SOA is not supported; skipped",
+	}
+	if !reflect.DeepEqual(imp.Warnings, wantWarnings) {
+		t.Errorf("warnings = %q\nwant %q", imp.Warnings, wantWarnings)
+	}
+}
+
+func TestReadUptimeKumaHistory(t *testing.T) {
+	imp := readTestKuma(t)
+	if !imp.HasHistory() {
+		t.Fatal("expected history")
+	}
+
+	counts := make(map[string]map[models.MonitorStatus]int)
+	var first *models.MonitorResult
+	var firstDown *models.MonitorResult
+	err := imp.History([]string{"Website", "Website (2)"}, func(result *models.MonitorResult) error {
+		if counts[result.Monitor] == nil {
+			counts[result.Monitor] = make(map[models.MonitorStatus]int)
+		}
+		counts[result.Monitor][result.Status]++
+		if first == nil {
+			first = result
+		}
+		if firstDown == nil && result.Status == models.StatusDown {
+			firstDown = result
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("History failed: %v", err)
+	}
+
+	// Pending heartbeats are skipped
+	want := map[string]map[models.MonitorStatus]int{
+		"Website":     {models.StatusUp: 388, models.StatusDown: 8},
+		"Website (2)": {models.StatusUp: 1},
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	wantFirst := &models.MonitorResult{
+		Monitor:   "Website",
+		Type:      models.MonitorTypeHTTP,
+		Group:     DefaultUptimeKumaGroup,
+		Status:    models.StatusUp,
+		Duration:  100 * time.Millisecond,
+		Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if !reflect.DeepEqual(first, wantFirst) {
+		t.Errorf("first result = %+v, want %+v", first, wantFirst)
+	}
+	if firstDown.Error != "timeout of 48000ms exceeded" || firstDown.Duration != 0 {
+		t.Errorf("down result = %+v", firstDown)
+	}
+	if wantTime := time.Date(2024, 1, 1, 0, 0, 49, 49*int(time.Millisecond), time.UTC); !firstDown.Timestamp.Equal(wantTime) {
+		t.Errorf("down result timestamp = %v, want %v", firstDown.Timestamp, wantTime)
+	}
+}
+
+func TestImportMerge(t *testing.T) {
+	imp := readTestKuma(t)
+	cfg := &config.Config{Monitoring: config.MonitoringConfig{Groups: []models.MonitorGroup{
+		{Name: "Infrastructure", Monitors: []models.Monitor{{Type: models.MonitorTypePing, Name: "Gateway", Target: "10.0.0.254"}}},
+	}}}
+
+	added := imp.Merge(cfg)
+
+	wantAdded := []string{"API health", "Postgres", "Website", "MX records", "Website (2)"}
+	if !reflect.DeepEqual(added, wantAdded) {
+		t.Errorf("added = %v, want %v", added, wantAdded)
+	}
+	if len(cfg.Monitoring.Groups) != 2 {
+		t.Fatalf("expected 2 groups, got %d", len(cfg.Monitoring.Groups))
+	}
+	if got := len(cfg.Monitoring.Groups[0].Monitors); got != 3 {
+		t.Errorf("expected the existing group to have 3 monitors, got %d", got)
+	}
+	if target := cfg.Monitoring.Groups[0].Monitors[0].Target; target != "10.0.0.254" {
+		t.Errorf("existing monitor was replaced: target %s", target)
+	}
+	if last := imp.Warnings[len(imp.Warnings)-1]; last != "Gateway: a monitor with this name already exists; skipped" {
+		t.Errorf("unexpected last warning %q", last)
+	}
+}
+
+func TestReadUptimeKumaRejectsOtherFiles(t *testing.T) {
+	data := strings.NewReader("name,url\nexample,https://example.com\n")
+	if _, err := ReadUptimeKuma(data, data.Size(), ""); err == nil {
+		t.Fatal("expected an error for a file that is not a SQLite database")
+	}
+}
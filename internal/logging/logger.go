@@ -3,6 +3,7 @@
 package logging
 
 import (
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -14,9 +15,14 @@ import (
 
 // Logger wraps zerolog with additional context for Hall Monitor
 type Logger struct {
-	logger zerolog.Logger
+	logger          zerolog.Logger
+	componentLevels map[string]zerolog.Level
 }
 
+// globalComponentLevels are the component level overrides of the logger
+// InitLogger last set as global
+var globalComponentLevels map[string]zerolog.Level
+
 // LogEvent represents a monitoring event type
 type LogEvent string
 
@@ -29,6 +35,8 @@ const (
 	EventServerStop     LogEvent = "server_stop"
 	EventAlertFired     LogEvent = "alert_fired"
 	EventAlertResolved  LogEvent = "alert_resolved"
+	EventContentChanged LogEvent = "content_changed"
+	EventTargetChanged  LogEvent = "target_changed"
 )
 
 // LogComponent represents a component of the application
@@ -41,14 +49,24 @@ const (
 	ComponentConfig    LogComponent = "config"
 	ComponentMetrics   LogComponent = "metrics"
 	ComponentAlert     LogComponent = "alert"
+	ComponentWebhook   LogComponent = "webhook"
+	ComponentMQTT      LogComponent = "mqtt"
+	ComponentSNMP      LogComponent = "snmp"
+	ComponentGraphite  LogComponent = "graphite"
+	ComponentZabbix    LogComponent = "zabbix"
+	ComponentNagios    LogComponent = "nagios"
+	ComponentReports   LogComponent = "reports"
 )
 
 // Config represents logging configuration
 type Config struct {
-	Level  string            `yaml:"level"`
-	Format string            `yaml:"format"` // json or text
-	Output string            `yaml:"output"` // stdout, stderr, or file path
-	Fields map[string]string `yaml:"fields"` // Additional fields for all logs
+	Level           string            `yaml:"level"`
+	Format          string            `yaml:"format"`          // json or text
+	Output          string            `yaml:"output"`          // stdout, stderr, or file path
+	Outputs         []string          `yaml:"outputs"`         // Additional sinks written alongside Output
+	Rotation        RotationConfig    `yaml:"rotation"`        // Rotation applied to file sinks
+	ComponentLevels map[string]string `yaml:"componentLevels"` // Per-component level overrides (e.g. badger: warn)
+	Fields          map[string]string `yaml:"fields"`          // Additional fields for all logs
 }
 
 // InitLogger initializes the global logger
@@ -58,38 +76,56 @@ func InitLogger(config Config) (*Logger, error) {
 	if err != nil {
 		level = zerolog.InfoLevel
 	}
-	zerolog.SetGlobalLevel(level)
 
-	// Configure output
-	var output io.Writer = os.Stdout
-	switch strings.ToLower(config.Output) {
-	case "stderr":
-		output = os.Stderr
-	case "stdout", "":
-		output = os.Stdout
-	default:
-		// Assume it's a file path
-		file, err := os.OpenFile(config.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	// Parse per-component overrides; the global level must admit the most
+	// verbose override or zerolog drops those events before they are checked
+	componentLevels := make(map[string]zerolog.Level)
+	globalLevel := level
+	for component, levelStr := range config.ComponentLevels {
+		componentLevel, err := zerolog.ParseLevel(strings.ToLower(levelStr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid level %q for component %s: %w", levelStr, component, err)
+		}
+		componentLevels[strings.ToLower(component)] = componentLevel
+		if componentLevel < globalLevel {
+			globalLevel = componentLevel
+		}
+	}
+	zerolog.SetGlobalLevel(globalLevel)
+
+	// Configure outputs
+	sinks := append([]string{config.Output}, config.Outputs...)
+	writers := make([]io.Writer, 0, len(sinks))
+	seen := make(map[string]bool)
+	for _, sink := range sinks {
+		if seen[sink] {
+			continue
+		}
+		seen[sink] = true
+
+		writer, err := openSink(sink, config.Rotation)
 		if err != nil {
 			return nil, err
 		}
-		output = file
+
+		// Configure format
+		switch strings.ToLower(config.Format) {
+		case "text", "console":
+			writer = zerolog.ConsoleWriter{
+				Out:        writer,
+				TimeFormat: time.RFC3339,
+			}
+		}
+		writers = append(writers, writer)
 	}
 
-	// Configure format
-	var logger zerolog.Logger
-	switch strings.ToLower(config.Format) {
-	case "text", "console":
-		logger = zerolog.New(zerolog.ConsoleWriter{
-			Out:        output,
-			TimeFormat: time.RFC3339,
-		})
-	case "json", "":
-		logger = zerolog.New(output)
-	default:
-		logger = zerolog.New(output)
+	var output io.Writer = writers[0]
+	if len(writers) > 1 {
+		output = zerolog.MultiLevelWriter(writers...)
 	}
 
+	logger := zerolog.New(output).Level(level)
+
 	// Add timestamp and additional fields
 	logger = logger.With().
 		Timestamp().
@@ -103,45 +139,68 @@ func InitLogger(config Config) (*Logger, error) {
 
 	// Set as global logger
 	log.Logger = logger
+	globalComponentLevels = componentLevels
 
-	return &Logger{logger: logger}, nil
+	return &Logger{logger: logger, componentLevels: componentLevels}, nil
+}
+
+// openSink resolves an output name to a writer
+func openSink(sink string, rotation RotationConfig) (io.Writer, error) {
+	switch strings.ToLower(sink) {
+	case "stderr":
+		return os.Stderr, nil
+	case "stdout", "":
+		return os.Stdout, nil
+	}
+
+	// Assume it's a file path
+	if rotation.Enabled() {
+		return NewRotatingFile(sink, rotation)
+	}
+	file, err := os.OpenFile(sink, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// derive returns a Logger sharing this logger's component level overrides
+func (l *Logger) derive(logger zerolog.Logger) *Logger {
+	return &Logger{logger: logger, componentLevels: l.componentLevels}
 }
 
 // GetGlobalLogger returns a logger instance with global context
 func GetGlobalLogger() *Logger {
-	return &Logger{logger: log.Logger}
+	return &Logger{logger: log.Logger, componentLevels: globalComponentLevels}
 }
 
-// WithComponent adds component context to the logger
+// WithComponent adds component context to the logger, applying any
+// level override configured for that component
 func (l *Logger) WithComponent(component LogComponent) *Logger {
-	return &Logger{
-		logger: l.logger.With().Str("component", string(component)).Logger(),
+	logger := l.logger.With().Str("component", string(component)).Logger()
+	if level, ok := l.componentLevels[strings.ToLower(string(component))]; ok {
+		logger = logger.Level(level)
 	}
+	return l.derive(logger)
 }
 
 // WithMonitor adds monitor context to the logger
 func (l *Logger) WithMonitor(monitor, monitorType, group string) *Logger {
-	return &Logger{
-		logger: l.logger.With().
-			Str("monitor", monitor).
-			Str("type", monitorType).
-			Str("group", group).
-			Logger(),
-	}
+	return l.derive(l.logger.With().
+		Str("monitor", monitor).
+		Str("type", monitorType).
+		Str("group", group).
+		Logger())
 }
 
 // WithEvent adds event context to the logger
 func (l *Logger) WithEvent(event LogEvent) *Logger {
-	return &Logger{
-		logger: l.logger.With().Str("event", string(event)).Logger(),
-	}
+	return l.derive(l.logger.With().Str("event", string(event)).Logger())
 }
 
 // WithError adds error context to the logger
 func (l *Logger) WithError(err error) *Logger {
-	return &Logger{
-		logger: l.logger.With().AnErr("error", err).Logger(),
-	}
+	return l.derive(l.logger.With().AnErr("error", err).Logger())
 }
 
 // WithFields adds multiple fields to the logger
@@ -167,7 +226,7 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 			event = event.Interface(key, v)
 		}
 	}
-	return &Logger{logger: event.Logger()}
+	return l.derive(event.Logger())
 }
 
 // Debug logs a debug message
@@ -305,3 +305,108 @@ func TestLoggerWithFieldsVariants(t *testing.T) {
 		t.Errorf("expected bool_field to be true, got %v", entry["bool_field"])
 	}
 }
+
+func TestInitLoggerWritesToMultipleSinks(t *testing.T) {
+	prevLevel := zerolog.GlobalLevel()
+	prevLogger := zerologlog.Logger
+	t.Cleanup(func() {
+		zerolog.SetGlobalLevel(prevLevel)
+		zerologlog.Logger = prevLogger
+	})
+
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "second.log")
+
+	logger, err := InitLogger(Config{
+		Level:   "info",
+		Format:  "json",
+		Output:  first,
+		Outputs: []string{second},
+	})
+	if err != nil {
+		t.Fatalf("InitLogger returned error: %v", err)
+	}
+
+	logger.Info("fan out")
+
+	for _, path := range []string{first, second} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", path, err)
+		}
+		if !strings.Contains(string(data), "fan out") {
+			t.Fatalf("expected %s to contain log entry, got %q", path, data)
+		}
+	}
+}
+
+func TestComponentLevelOverrides(t *testing.T) {
+	prevLevel := zerolog.GlobalLevel()
+	prevLogger := zerologlog.Logger
+	prevComponentLevels := globalComponentLevels
+	t.Cleanup(func() {
+		zerolog.SetGlobalLevel(prevLevel)
+		zerologlog.Logger = prevLogger
+		globalComponentLevels = prevComponentLevels
+	})
+
+	logPath := filepath.Join(t.TempDir(), "components.log")
+	logger, err := InitLogger(Config{
+		Level:  "info",
+		Format: "json",
+		Output: logPath,
+		ComponentLevels: map[string]string{
+			"badger":    "warn",
+			"scheduler": "debug",
+		},
+	})
+	if err != nil {
+		t.Fatalf("InitLogger returned error: %v", err)
+	}
+
+	logger.Debug("base debug")
+	logger.WithComponent("badger").Info("badger info")
+	logger.WithComponent("badger").Warn("badger warn")
+	logger.WithComponent(ComponentScheduler).WithFields(map[string]interface{}{"n": 1}).Debug("scheduler debug")
+	GetGlobalLogger().WithComponent("badger").Info("global badger info")
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("failed to read log file: %v", err)
+	}
+	output := string(data)
+
+	if strings.Contains(output, "base debug") {
+		t.Errorf("expected base debug message to be filtered at info level")
+	}
+	if strings.Contains(output, "badger info") {
+		t.Errorf("expected badger info message to be filtered by warn override")
+	}
+	if strings.Contains(output, "global badger info") {
+		t.Errorf("expected the global logger to apply the badger override")
+	}
+	if !strings.Contains(output, "badger warn") {
+		t.Errorf("expected badger warn message in output")
+	}
+	if !strings.Contains(output, "scheduler debug") {
+		t.Errorf("expected scheduler debug message to pass debug override")
+	}
+}
+
+func TestInitLoggerRejectsInvalidComponentLevel(t *testing.T) {
+	prevLevel := zerolog.GlobalLevel()
+	prevLogger := zerologlog.Logger
+	t.Cleanup(func() {
+		zerolog.SetGlobalLevel(prevLevel)
+		zerologlog.Logger = prevLogger
+	})
+
+	_, err := InitLogger(Config{
+		Level:           "info",
+		ComponentLevels: map[string]string{"badger": "loud"},
+	})
+	if err == nil {
+		t.Fatalf("expected error for invalid component level")
+	}
+}
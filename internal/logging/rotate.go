@@ -0,0 +1,285 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp layout embedded in rotated file names
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotationConfig controls size and time based rotation of file log outputs
+type RotationConfig struct {
+	MaxSizeMB  int           `yaml:"maxSizeMB"`  // Rotate once the file exceeds this size (0 disables)
+	MaxAge     time.Duration `yaml:"maxAge"`     // Remove backups older than this (0 keeps forever)
+	MaxBackups int           `yaml:"maxBackups"` // Number of backups to keep (0 keeps all)
+	Interval   time.Duration `yaml:"interval"`   // Rotate after this much time has elapsed (0 disables)
+	Compress   bool          `yaml:"compress"`   // Gzip rotated files
+}
+
+// Enabled reports whether any rotation trigger is configured
+func (rc RotationConfig) Enabled() bool {
+	return rc.MaxSizeMB > 0 || rc.Interval > 0
+}
+
+// RotatingFile is an io.Writer that writes to a file and rotates it when it
+// grows past a size limit or has been open longer than a configured interval.
+type RotatingFile struct {
+	path     string
+	config   RotationConfig
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	mu       sync.Mutex
+
+	millMu sync.Mutex     // Serializes compressing and pruning backups
+	millWG sync.WaitGroup // Compression and pruning running in the background
+
+	// now is overridable for tests
+	now func() time.Time
+}
+
+// NewRotatingFile opens (or creates) the log file at path with rotation applied
+func NewRotatingFile(path string, config RotationConfig) (*RotatingFile, error) {
+	rf := &RotatingFile{
+		path:   path,
+		config: config,
+		now:    time.Now,
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+// Write implements io.Writer, rotating the underlying file when required
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.shouldRotate(int64(len(p))) {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Rotate forces an immediate rotation of the log file
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.rotate()
+}
+
+// Close closes the underlying file, after compressing and pruning backups
+// still in progress
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	defer rf.millWG.Wait()
+
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
+
+// shouldRotate determines whether writing n more bytes requires a rotation
+func (rf *RotatingFile) shouldRotate(n int64) bool {
+	if rf.config.MaxSizeMB > 0 && rf.size > 0 && rf.size+n > int64(rf.config.MaxSizeMB)*1024*1024 {
+		return true
+	}
+	if rf.config.Interval > 0 && rf.now().Sub(rf.openedAt) >= rf.config.Interval {
+		return true
+	}
+	return false
+}
+
+// open opens the log file for appending and records its current size
+func (rf *RotatingFile) open() error {
+	file, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	rf.openedAt = rf.now()
+	return nil
+}
+
+// rotate renames the current file to a timestamped backup and opens a new one
+func (rf *RotatingFile) rotate() error {
+	if rf.file != nil {
+		if err := rf.file.Close(); err != nil {
+			return fmt.Errorf("failed to close log file: %w", err)
+		}
+		rf.file = nil
+	}
+
+	backup := rf.backupName(rf.now())
+	if err := os.Rename(rf.path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	// Compressing a large backup takes a while, so writes don't wait for it
+	cutoff := rf.now().Add(-rf.config.MaxAge)
+	rf.millWG.Add(1)
+	go func() {
+		defer rf.millWG.Done()
+		if err := rf.mill(backup, cutoff); err != nil {
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// mill compresses a rotated backup if configured and prunes old backups,
+// removing those older than cutoff
+func (rf *RotatingFile) mill(backup string, cutoff time.Time) error {
+	rf.millMu.Lock()
+	defer rf.millMu.Unlock()
+
+	if rf.config.Compress {
+		if err := compressFile(backup); err != nil {
+			return err
+		}
+	}
+
+	return rf.pruneBackups(cutoff)
+}
+
+// backupName builds the rotated file name, e.g. app-2024-01-02T15-04-05.000.log
+func (rf *RotatingFile) backupName(t time.Time) string {
+	dir := filepath.Dir(rf.path)
+	base := filepath.Base(rf.path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext)
+	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", prefix, t.Format(backupTimeFormat), ext))
+}
+
+// pruneBackups removes backups exceeding MaxBackups or, when MaxAge is set,
+// older than cutoff
+func (rf *RotatingFile) pruneBackups(cutoff time.Time) error {
+	if rf.config.MaxBackups <= 0 && rf.config.MaxAge <= 0 {
+		return nil
+	}
+
+	backups, err := rf.listBackups()
+	if err != nil {
+		return err
+	}
+
+	for i, backup := range backups {
+		expired := rf.config.MaxAge > 0 && backup.timestamp.Before(cutoff)
+		excess := rf.config.MaxBackups > 0 && i >= rf.config.MaxBackups
+		if expired || excess {
+			if err := os.Remove(backup.path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove old log file: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+type logBackup struct {
+	path      string
+	timestamp time.Time
+}
+
+// listBackups returns rotated files for this log, newest first
+func (rf *RotatingFile) listBackups() ([]logBackup, error) {
+	dir := filepath.Dir(rf.path)
+	base := filepath.Base(rf.path)
+	ext := filepath.Ext(base)
+	prefix := strings.TrimSuffix(base, ext) + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read log directory: %w", err)
+	}
+
+	var backups []logBackup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		stamp := strings.TrimPrefix(name, prefix)
+		stamp = strings.TrimSuffix(stamp, ".gz")
+		stamp = strings.TrimSuffix(stamp, ext)
+
+		ts, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, logBackup{path: filepath.Join(dir, name), timestamp: ts})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].timestamp.After(backups[j].timestamp)
+	})
+
+	return backups, nil
+}
+
+// compressFile gzips path into path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open log file for compression: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create compressed log file: %w", err)
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		gz.Close()
+		dst.Close()
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to compress log file: %w", err)
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("failed to close compressed log file: %w", err)
+	}
+
+	return os.Remove(path)
+}
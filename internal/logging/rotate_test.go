@@ -0,0 +1,94 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := NewRotatingFile(path, RotationConfig{MaxSizeMB: 1, MaxBackups: 1})
+	if err != nil {
+		t.Fatalf("NewRotatingFile returned error: %v", err)
+	}
+	defer rf.Close()
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	chunk := []byte(strings.Repeat("x", 600*1024))
+	for i := 0; i < 3; i++ {
+		if _, err := rf.Write(chunk); err != nil {
+			t.Fatalf("write %d failed: %v", i, err)
+		}
+	}
+
+	rf.millWG.Wait()
+
+	backups, err := rf.listBackups()
+	if err != nil {
+		t.Fatalf("listBackups returned error: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("expected 1 backup after pruning, got %d", len(backups))
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat active log: %v", err)
+	}
+	if info.Size() != int64(len(chunk)) {
+		t.Fatalf("expected active log to hold one chunk, got %d bytes", info.Size())
+	}
+}
+
+func TestRotatingFileRotatesByInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := NewRotatingFile(path, RotationConfig{Interval: time.Hour, Compress: true})
+	if err != nil {
+		t.Fatalf("NewRotatingFile returned error: %v", err)
+	}
+	defer rf.Close()
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rf.now = func() time.Time { return start }
+	rf.openedAt = start
+
+	if _, err := rf.Write([]byte("first\n")); err != nil {
+		t.Fatalf("first write failed: %v", err)
+	}
+
+	rf.now = func() time.Time { return start.Add(2 * time.Hour) }
+	if _, err := rf.Write([]byte("second\n")); err != nil {
+		t.Fatalf("second write failed: %v", err)
+	}
+
+	// Compression runs in the background
+	rf.millWG.Wait()
+
+	matches, err := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	if err != nil {
+		t.Fatalf("glob failed: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 compressed backup, got %d", len(matches))
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read active log: %v", err)
+	}
+	if string(data) != "second\n" {
+		t.Fatalf("expected active log to contain only the second write, got %q", data)
+	}
+}
@@ -5,6 +5,7 @@ package metrics
 import (
 	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -13,15 +14,22 @@ import (
 // Metrics holds all Prometheus metrics for Hall Monitor
 type Metrics struct {
 	// Counters
-	ChecksTotal *prometheus.CounterVec
-	ErrorsTotal *prometheus.CounterVec
-	AlertsTotal *prometheus.CounterVec
+	ChecksTotal   *prometheus.CounterVec
+	ErrorsTotal   *prometheus.CounterVec
+	AlertsTotal   *prometheus.CounterVec
+	WorkerScaling *prometheus.CounterVec
+	TimeoutHits   *prometheus.CounterVec
+	CacheLookups  *prometheus.CounterVec
+	Stalls        *prometheus.CounterVec
+	StallSeconds  *prometheus.CounterVec
+	MissedChecks  *prometheus.CounterVec
 
 	// Gauges
 	MonitorUp          *prometheus.GaugeVec
 	MonitorsConfigured *prometheus.GaugeVec
 	MonitorsEnabled    *prometheus.GaugeVec
 	MonitorsRunning    prometheus.Gauge
+	WorkerPoolSize     prometheus.Gauge
 	ConfigReloads      prometheus.Gauge
 	LastConfigReload   prometheus.Gauge
 
@@ -33,10 +41,14 @@ type Metrics struct {
 	TCPConnectTime   *prometheus.HistogramVec
 
 	// Monitor-specific metrics
-	HTTPStatusCodes  *prometheus.CounterVec
-	DNSResponseCodes *prometheus.CounterVec
-	PingPacketLoss   *prometheus.GaugeVec
-	SSLCertExpiry    *prometheus.GaugeVec
+	HTTPStatusCodes    *prometheus.CounterVec
+	HTTPContentChanges *prometheus.CounterVec
+	TargetChanges      *prometheus.CounterVec
+	DNSResponseCodes   *prometheus.CounterVec
+	PingPacketLoss     *prometheus.GaugeVec
+	SSLCertExpiry      *prometheus.GaugeVec
+	RBLListed          *prometheus.GaugeVec
+	DomainExpiry       *prometheus.GaugeVec
 }
 
 // NewMetrics creates and registers all Prometheus metrics
@@ -99,6 +111,61 @@ func NewMetrics(registry prometheus.Registerer) *Metrics {
 			},
 		),
 
+		TimeoutHits: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hallmonitor_check_timeout_pressure_total",
+				Help: "Total number of check attempts that ran near (80%+) or over their timeout",
+			},
+			[]string{"monitor", "type", "group", "kind"},
+		),
+
+		WorkerPoolSize: promauto.With(registry).NewGauge(
+			prometheus.GaugeOpts{
+				Name: "hallmonitor_scheduler_workers",
+				Help: "Current number of scheduler workers",
+			},
+		),
+
+		WorkerScaling: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hallmonitor_scheduler_worker_scaling_total",
+				Help: "Total number of worker pool autoscaling events by direction",
+			},
+			[]string{"direction"},
+		),
+
+		Stalls: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hallmonitor_scheduler_stalls_total",
+				Help: "Total number of gaps in which the scheduler did not run on time, by kind (stall or clock_jump)",
+			},
+			[]string{"kind"},
+		),
+
+		StallSeconds: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hallmonitor_scheduler_stall_seconds_total",
+				Help: "Total seconds the scheduler fell behind, by kind (stall or clock_jump)",
+			},
+			[]string{"kind"},
+		),
+
+		MissedChecks: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hallmonitor_missed_checks_total",
+				Help: "Total number of scheduled checks that did not run because the scheduler fell behind",
+			},
+			[]string{"monitor", "group"},
+		),
+
+		CacheLookups: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hallmonitor_api_cache_lookups_total",
+				Help: "Total number of API cache lookups by cache and result (hit or miss)",
+			},
+			[]string{"cache", "result"},
+		),
+
 		ConfigReloads: promauto.With(registry).NewGauge(
 			prometheus.GaugeOpts{
 				Name: "hallmonitor_config_reloads_total",
@@ -168,6 +235,22 @@ func NewMetrics(registry prometheus.Registerer) *Metrics {
 			[]string{"monitor", "group", "status_code", "method"},
 		),
 
+		HTTPContentChanges: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hallmonitor_http_content_changes_total",
+				Help: "Total HTTP response body changes detected",
+			},
+			[]string{"monitor", "group"},
+		),
+
+		TargetChanges: promauto.With(registry).NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "hallmonitor_target_changes_total",
+				Help: "Total changes of the address or certificate a monitor reaches",
+			},
+			[]string{"monitor", "group", "kind"},
+		),
+
 		DNSResponseCodes: promauto.With(registry).NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "hallmonitor_dns_response_codes_total",
@@ -192,13 +275,30 @@ func NewMetrics(registry prometheus.Registerer) *Metrics {
 			},
 			[]string{"monitor", "group", "subject"},
 		),
+
+		RBLListed: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hallmonitor_rbl_listed",
+				Help: "Whether a monitored target is listed (1) or not (0) on a DNS blocklist",
+			},
+			[]string{"monitor", "group", "blocklist"},
+		),
+
+		DomainExpiry: promauto.With(registry).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "hallmonitor_domain_expiry_seconds",
+				Help: "Domain registration expiry time in seconds from epoch",
+			},
+			[]string{"monitor", "group", "domain"},
+		),
 	}
 
 	return m
 }
 
-// RecordCheck records a monitor check
-func (m *Metrics) RecordCheck(monitor, monitorType, group, status string, duration time.Duration) {
+// RecordCheck records a monitor check. A non-empty resultID is attached to
+// the duration histogram as an exemplar linking to the stored result.
+func (m *Metrics) RecordCheck(monitor, monitorType, group, status string, duration time.Duration, resultID string) {
 	labels := prometheus.Labels{
 		"monitor": monitor,
 		"type":    monitorType,
@@ -207,11 +307,11 @@ func (m *Metrics) RecordCheck(monitor, monitorType, group, status string, durati
 	}
 
 	m.ChecksTotal.With(labels).Inc()
-	m.CheckDuration.With(prometheus.Labels{
+	observe(m.CheckDuration.With(prometheus.Labels{
 		"monitor": monitor,
 		"type":    monitorType,
 		"group":   group,
-	}).Observe(duration.Seconds())
+	}), duration.Seconds(), monitor, resultID)
 }
 
 // RecordError records a monitor error
@@ -238,15 +338,15 @@ func (m *Metrics) SetMonitorStatus(monitor, monitorType, group string, up bool)
 }
 
 // RecordHTTPCheck records HTTP-specific metrics
-func (m *Metrics) RecordHTTPCheck(monitor, group, method string, statusCode int, duration time.Duration) {
+func (m *Metrics) RecordHTTPCheck(monitor, group, method string, statusCode int, duration time.Duration, resultID string) {
 	statusStr := strconv.Itoa(statusCode)
 
-	m.HTTPResponseTime.With(prometheus.Labels{
+	observe(m.HTTPResponseTime.With(prometheus.Labels{
 		"monitor":     monitor,
 		"group":       group,
 		"method":      method,
 		"status_code": statusStr,
-	}).Observe(duration.Seconds())
+	}), duration.Seconds(), monitor, resultID)
 
 	m.HTTPStatusCodes.With(prometheus.Labels{
 		"monitor":     monitor,
@@ -256,14 +356,32 @@ func (m *Metrics) RecordHTTPCheck(monitor, group, method string, statusCode int,
 	}).Inc()
 }
 
+// RecordHTTPContentChange records a detected change in an HTTP response body
+func (m *Metrics) RecordHTTPContentChange(monitor, group string) {
+	m.HTTPContentChanges.With(prometheus.Labels{
+		"monitor": monitor,
+		"group":   group,
+	}).Inc()
+}
+
+// RecordTargetChange records a change of a monitor's remote address ("ip")
+// or certificate ("certificate")
+func (m *Metrics) RecordTargetChange(monitor, group, kind string) {
+	m.TargetChanges.With(prometheus.Labels{
+		"monitor": monitor,
+		"group":   group,
+		"kind":    kind,
+	}).Inc()
+}
+
 // RecordDNSCheck records DNS-specific metrics
-func (m *Metrics) RecordDNSCheck(monitor, group, queryType, server string, rcode int, duration time.Duration) {
-	m.DNSQueryTime.With(prometheus.Labels{
+func (m *Metrics) RecordDNSCheck(monitor, group, queryType, server string, rcode int, duration time.Duration, resultID string) {
+	observe(m.DNSQueryTime.With(prometheus.Labels{
 		"monitor":    monitor,
 		"group":      group,
 		"query_type": queryType,
 		"server":     server,
-	}).Observe(duration.Seconds())
+	}), duration.Seconds(), monitor, resultID)
 
 	m.DNSResponseCodes.With(prometheus.Labels{
 		"monitor":    monitor,
@@ -274,11 +392,11 @@ func (m *Metrics) RecordDNSCheck(monitor, group, queryType, server string, rcode
 }
 
 // RecordPingCheck records ping-specific metrics
-func (m *Metrics) RecordPingCheck(monitor, group string, rtt time.Duration, packetLoss float64) {
-	m.PingRTT.With(prometheus.Labels{
+func (m *Metrics) RecordPingCheck(monitor, group string, rtt time.Duration, packetLoss float64, resultID string) {
+	observe(m.PingRTT.With(prometheus.Labels{
 		"monitor": monitor,
 		"group":   group,
-	}).Observe(rtt.Seconds())
+	}), rtt.Seconds(), monitor, resultID)
 
 	m.PingPacketLoss.With(prometheus.Labels{
 		"monitor": monitor,
@@ -287,12 +405,29 @@ func (m *Metrics) RecordPingCheck(monitor, group string, rtt time.Duration, pack
 }
 
 // RecordTCPCheck records TCP-specific metrics
-func (m *Metrics) RecordTCPCheck(monitor, group string, port int, duration time.Duration) {
-	m.TCPConnectTime.With(prometheus.Labels{
+func (m *Metrics) RecordTCPCheck(monitor, group string, port int, duration time.Duration, resultID string) {
+	observe(m.TCPConnectTime.With(prometheus.Labels{
 		"monitor": monitor,
 		"group":   group,
 		"port":    strconv.Itoa(port),
-	}).Observe(duration.Seconds())
+	}), duration.Seconds(), monitor, resultID)
+}
+
+// observe records a histogram observation, with an exemplar naming the
+// monitor and result ID when one is given. The monitor is left out when the
+// labels would exceed Prometheus' exemplar size limit.
+func observe(observer prometheus.Observer, value float64, monitor, resultID string) {
+	exemplarObserver, ok := observer.(prometheus.ExemplarObserver)
+	if !ok || resultID == "" {
+		observer.Observe(value)
+		return
+	}
+
+	labels := prometheus.Labels{"monitor": monitor, "result_id": resultID}
+	if utf8.RuneCountInString("monitor"+monitor+"result_id"+resultID) > prometheus.ExemplarMaxRunes {
+		delete(labels, "monitor")
+	}
+	exemplarObserver.ObserveWithExemplar(value, labels)
 }
 
 // RecordSSLCertExpiry records SSL certificate expiry
@@ -304,6 +439,28 @@ func (m *Metrics) RecordSSLCertExpiry(monitor, group, subject string, expiry tim
 	}).Set(float64(expiry.Unix()))
 }
 
+// RecordDomainExpiry records domain registration expiry
+func (m *Metrics) RecordDomainExpiry(monitor, group, domain string, expiry time.Time) {
+	m.DomainExpiry.With(prometheus.Labels{
+		"monitor": monitor,
+		"group":   group,
+		"domain":  domain,
+	}).Set(float64(expiry.Unix()))
+}
+
+// SetRBLListed records whether a target is listed on a DNS blocklist
+func (m *Metrics) SetRBLListed(monitor, group, blocklist string, listed bool) {
+	value := 0.0
+	if listed {
+		value = 1.0
+	}
+	m.RBLListed.With(prometheus.Labels{
+		"monitor":   monitor,
+		"group":     group,
+		"blocklist": blocklist,
+	}).Set(value)
+}
+
 // RecordAlert records an alert firing
 func (m *Metrics) RecordAlert(monitor, monitorType, group, severity, rule string) {
 	m.AlertsTotal.With(prometheus.Labels{
@@ -318,7 +475,7 @@ func (m *Metrics) RecordAlert(monitor, monitorType, group, severity, rule string
 // UpdateMonitorCounts updates the configured and enabled monitor counts
 func (m *Metrics) UpdateMonitorCounts(monitorCounts map[string]int, enabledCounts map[string]int) {
 	// Reset all counts to 0 first
-	for _, monitorType := range []string{"ping", "http", "tcp", "dns"} {
+	for _, monitorType := range []string{"ping", "http", "tcp", "dns", "rbl", "domain", "plugin", "wasm"} {
 		m.MonitorsConfigured.With(prometheus.Labels{"type": monitorType}).Set(0)
 		m.MonitorsEnabled.With(prometheus.Labels{"type": monitorType}).Set(0)
 	}
@@ -343,6 +500,41 @@ func (m *Metrics) DecrementRunningMonitors() {
 	m.MonitorsRunning.Dec()
 }
 
+// RecordTimeoutPressure counts a check attempt that ran near ("near") or over ("overrun") its timeout
+func (m *Metrics) RecordTimeoutPressure(monitor, monitorType, group, kind string) {
+	m.TimeoutHits.WithLabelValues(monitor, monitorType, group, kind).Inc()
+}
+
+// SetWorkerPoolSize records the current number of scheduler workers
+func (m *Metrics) SetWorkerPoolSize(size int) {
+	m.WorkerPoolSize.Set(float64(size))
+}
+
+// RecordWorkerScaling counts a worker pool scale-up or scale-down
+func (m *Metrics) RecordWorkerScaling(direction string) {
+	m.WorkerScaling.WithLabelValues(direction).Inc()
+}
+
+// RecordStall counts a scheduler gap of the given kind ("stall" or "clock_jump")
+func (m *Metrics) RecordStall(kind string, gap time.Duration) {
+	m.Stalls.WithLabelValues(kind).Inc()
+	m.StallSeconds.WithLabelValues(kind).Add(gap.Seconds())
+}
+
+// RecordMissedChecks counts checks of a monitor skipped during a scheduler gap
+func (m *Metrics) RecordMissedChecks(monitor, group string, count int) {
+	m.MissedChecks.WithLabelValues(monitor, group).Add(float64(count))
+}
+
+// RecordCacheLookup counts an API cache hit or miss
+func (m *Metrics) RecordCacheLookup(cache string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.CacheLookups.WithLabelValues(cache, result).Inc()
+}
+
 // RecordConfigReload records a configuration reload
 func (m *Metrics) RecordConfigReload() {
 	m.ConfigReloads.Inc()
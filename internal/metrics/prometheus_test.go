@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"math"
+	"strings"
 	"testing"
 	"time"
 
@@ -68,7 +69,7 @@ func TestNewMetricsRegistersCollectors(t *testing.T) {
 func TestRecordCheckUpdatesCountersAndHistogram(t *testing.T) {
 	metrics, reg := newTestMetrics(t)
 
-	metrics.RecordCheck("homepage", "http", "default", "up", 500*time.Millisecond)
+	metrics.RecordCheck("homepage", "http", "default", "up", 500*time.Millisecond, "")
 
 	if got := testutil.ToFloat64(metrics.ChecksTotal.WithLabelValues("homepage", "http", "default", "up")); got != 1 {
 		t.Fatalf("expected ChecksTotal counter to be 1, got %v", got)
@@ -110,7 +111,7 @@ func TestSetMonitorStatus(t *testing.T) {
 func TestRecordHTTPCheckUpdatesMetrics(t *testing.T) {
 	metrics, reg := newTestMetrics(t)
 
-	metrics.RecordHTTPCheck("homepage", "default", "GET", 204, 200*time.Millisecond)
+	metrics.RecordHTTPCheck("homepage", "default", "GET", 204, 200*time.Millisecond, "")
 
 	if got := testutil.ToFloat64(metrics.HTTPStatusCodes.WithLabelValues("homepage", "default", "204", "GET")); got != 1 {
 		t.Fatalf("expected HTTPStatusCodes counter to be 1, got %v", got)
@@ -135,7 +136,7 @@ func TestRecordHTTPCheckUpdatesMetrics(t *testing.T) {
 func TestRecordDNSCheckUpdatesMetrics(t *testing.T) {
 	metrics, reg := newTestMetrics(t)
 
-	metrics.RecordDNSCheck("resolver", "edge", "A", "1.1.1.1", 0, 150*time.Millisecond)
+	metrics.RecordDNSCheck("resolver", "edge", "A", "1.1.1.1", 0, 150*time.Millisecond, "")
 
 	if got := testutil.ToFloat64(metrics.DNSResponseCodes.WithLabelValues("resolver", "edge", "0", "A")); got != 1 {
 		t.Fatalf("expected DNS response counter to be 1, got %v", got)
@@ -156,7 +157,7 @@ func TestRecordDNSCheckUpdatesMetrics(t *testing.T) {
 func TestRecordPingCheckUpdatesMetrics(t *testing.T) {
 	metrics, reg := newTestMetrics(t)
 
-	metrics.RecordPingCheck("icmp", "core", 20*time.Millisecond, 12.5)
+	metrics.RecordPingCheck("icmp", "core", 20*time.Millisecond, 12.5, "")
 
 	if got := testutil.ToFloat64(metrics.PingPacketLoss.WithLabelValues("icmp", "core")); math.Abs(got-12.5) > 0.0001 {
 		t.Fatalf("expected packet loss gauge 12.5, got %v", got)
@@ -175,7 +176,7 @@ func TestRecordPingCheckUpdatesMetrics(t *testing.T) {
 func TestRecordTCPCheckUpdatesMetrics(t *testing.T) {
 	metrics, reg := newTestMetrics(t)
 
-	metrics.RecordTCPCheck("redis", "cache", 6379, 30*time.Millisecond)
+	metrics.RecordTCPCheck("redis", "cache", 6379, 30*time.Millisecond, "")
 
 	hist := getHistogram(t, reg, "hallmonitor_tcp_connect_time_seconds", map[string]string{
 		"monitor": "redis",
@@ -252,3 +253,66 @@ func TestRecordConfigReload(t *testing.T) {
 		t.Fatalf("expected last config reload timestamp to be set, got %v", got)
 	}
 }
+
+func TestWorkerPoolMetrics(t *testing.T) {
+	metrics, _ := newTestMetrics(t)
+
+	metrics.SetWorkerPoolSize(12)
+	metrics.RecordWorkerScaling("up")
+	metrics.RecordWorkerScaling("up")
+	metrics.RecordWorkerScaling("down")
+
+	if got := testutil.ToFloat64(metrics.WorkerPoolSize); got != 12 {
+		t.Fatalf("expected worker pool size 12, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.WorkerScaling.WithLabelValues("up")); got != 2 {
+		t.Fatalf("expected 2 scale-ups, got %v", got)
+	}
+}
+
+func TestRecordHTTPCheckAttachesExemplar(t *testing.T) {
+	longName := strings.Repeat("m", 120)
+
+	tests := []struct {
+		name        string
+		monitor     string
+		wantMonitor bool
+	}{
+		{name: "with monitor", monitor: "homepage", wantMonitor: true},
+		{name: "monitor too long", monitor: longName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, reg := newTestMetrics(t)
+			metrics.RecordHTTPCheck(tt.monitor, "default", "GET", 200, 200*time.Millisecond, "1700000000000000000")
+
+			hist := getHistogram(t, reg, "hallmonitor_http_response_time_seconds", map[string]string{
+				"monitor":     tt.monitor,
+				"group":       "default",
+				"method":      "GET",
+				"status_code": "200",
+			})
+			if hist == nil {
+				t.Fatalf("expected histogram data for HTTP response time")
+			}
+
+			var labels map[string]string
+			for _, bucket := range hist.GetBucket() {
+				if exemplar := bucket.GetExemplar(); exemplar != nil {
+					labels = make(map[string]string)
+					for _, lp := range exemplar.GetLabel() {
+						labels[lp.GetName()] = lp.GetValue()
+					}
+					break
+				}
+			}
+			if labels["result_id"] != "1700000000000000000" {
+				t.Fatalf("expected an exemplar with the result ID, got %v", labels)
+			}
+			if _, ok := labels["monitor"]; ok != tt.wantMonitor {
+				t.Errorf("expected monitor label present=%v, got %v", tt.wantMonitor, labels)
+			}
+		})
+	}
+}
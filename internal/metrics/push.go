@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+)
+
+const (
+	defaultPushJob      = "hallmonitor"
+	defaultPushInterval = 30 * time.Second
+)
+
+// Pusher periodically pushes gathered metrics to a Prometheus Pushgateway,
+// or to a Hall Monitor accepting pushes, replacing the previous push for the
+// same job and instance
+type Pusher struct {
+	pusher   *push.Pusher
+	url      string
+	job      string
+	instance string
+	interval time.Duration
+	logger   *logging.Logger
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	running bool
+}
+
+// NewPusher creates a pusher for the metrics of gatherer
+func NewPusher(cfg config.MetricsPushConfig, gatherer prometheus.Gatherer, logger *logging.Logger) *Pusher {
+	job := cfg.Job
+	if job == "" {
+		job = defaultPushJob
+	}
+	instance := cfg.Instance
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultPushInterval
+	}
+
+	pusher := push.New(cfg.URL, job).Gatherer(gatherer)
+	if instance != "" {
+		pusher = pusher.Grouping("instance", instance)
+	}
+	if cfg.Username != "" {
+		pusher = pusher.BasicAuth(cfg.Username, cfg.Password)
+	}
+
+	return &Pusher{
+		pusher:   pusher,
+		url:      cfg.URL,
+		job:      job,
+		instance: instance,
+		interval: interval,
+		logger:   logger,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Push sends the current metrics once
+func (p *Pusher) Push(ctx context.Context) error {
+	return p.pusher.PushContext(ctx)
+}
+
+// Start pushes metrics every interval until stopped
+func (p *Pusher) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return nil
+	}
+
+	p.logger.WithComponent(logging.ComponentMetrics).
+		WithFields(map[string]interface{}{
+			"url":      p.url,
+			"job":      p.job,
+			"instance": p.instance,
+			"interval": p.interval.String(),
+		}).
+		Info("Starting metrics push")
+
+	p.wg.Add(1)
+	go p.pushLoop(ctx)
+
+	p.running = true
+	return nil
+}
+
+// Stop pushes the final metrics and stops pushing
+func (p *Pusher) Stop() error {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return nil
+	}
+	p.running = false
+	p.mu.Unlock()
+
+	p.logger.WithComponent(logging.ComponentMetrics).Info("Stopping metrics push")
+	close(p.stopCh)
+	p.wg.Wait()
+	return nil
+}
+
+// pushLoop pushes on every tick, and once more on the way out
+func (p *Pusher) pushLoop(ctx context.Context) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.pushOnce(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.stopCh:
+			p.pushOnce(context.Background())
+			return
+		case <-ticker.C:
+			p.pushOnce(ctx)
+		}
+	}
+}
+
+// pushOnce pushes within one interval, logging failures; the next tick retries
+func (p *Pusher) pushOnce(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, p.interval)
+	defer cancel()
+
+	if err := p.Push(ctx); err != nil {
+		p.logger.WithComponent(logging.ComponentMetrics).
+			WithError(err).
+			WithFields(map[string]interface{}{"url": p.url}).
+			Warn("Failed to push metrics")
+	}
+}
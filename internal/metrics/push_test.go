@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+)
+
+func TestPusherPush(t *testing.T) {
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+
+	var method, path, username, password string
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		username, password, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	metrics, reg := newTestMetrics(t)
+	metrics.MonitorsRunning.Set(3)
+
+	pusher := NewPusher(config.MetricsPushConfig{
+		URL:      gateway.URL,
+		Instance: "probe-1",
+		Username: "prometheus",
+		Password: "scrape",
+	}, reg, logger)
+	if err := pusher.Push(context.Background()); err != nil {
+		t.Fatalf("Push() error = %v", err)
+	}
+
+	if method != http.MethodPut || path != "/metrics/job/hallmonitor/instance/probe-1" {
+		t.Errorf("expected PUT /metrics/job/hallmonitor/instance/probe-1, got %s %s", method, path)
+	}
+	if username != "prometheus" || password != "scrape" {
+		t.Errorf("expected basic auth credentials, got %q/%q", username, password)
+	}
+}
+
+func TestPushedMetrics(t *testing.T) {
+	gauge := func(name, help string, value float64) *dto.MetricFamily {
+		return &dto.MetricFamily{
+			Name:   proto.String(name),
+			Help:   proto.String(help),
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(value)}}},
+		}
+	}
+
+	local := prometheus.NewRegistry()
+	NewMetrics(local)
+	pushed := NewPushedMetrics(local)
+
+	if err := pushed.Replace("hallmonitor", "probe-1", []*dto.MetricFamily{gauge("hallmonitor_monitors_running", "Number of currently running monitor checks", 2)}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+	if err := pushed.Replace("hallmonitor", "probe-2", []*dto.MetricFamily{gauge("hallmonitor_monitors_running", "Number of currently running monitor checks", 5)}); err != nil {
+		t.Fatalf("Replace() error = %v", err)
+	}
+
+	// Help that disagrees with the local registry would break every scrape
+	if err := pushed.Replace("hallmonitor", "probe-3", []*dto.MetricFamily{gauge("hallmonitor_monitors_running", "Something else", 1)}); err == nil {
+		t.Error("expected a push with conflicting help to be rejected")
+	}
+
+	families, err := pushed.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	values := make(map[string]float64)
+	var pushTimes int
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			switch family.GetName() {
+			case "hallmonitor_monitors_running":
+				if !metricMatchesLabels(metric, map[string]string{"job": "hallmonitor", "instance": labelValue(metric, "instance")}) {
+					t.Errorf("unexpected labels %v", metric.GetLabel())
+				}
+				values[labelValue(metric, "instance")] = metric.GetGauge().GetValue()
+			case pushTimeMetric:
+				pushTimes++
+			}
+		}
+	}
+	if len(values) != 2 || values["probe-1"] != 2 || values["probe-2"] != 5 {
+		t.Errorf("expected both probes' values, got %v", values)
+	}
+	if pushTimes != 2 {
+		t.Errorf("expected a push time per probe, got %d", pushTimes)
+	}
+
+	pushed.Delete("hallmonitor", "probe-1")
+	pushed.Delete("hallmonitor", "probe-2")
+	if families, _ := pushed.Gather(); len(families) != 0 {
+		t.Errorf("expected nothing after deleting every push, got %d families", len(families))
+	}
+}
+
+func labelValue(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// pushTimeMetric records when each job and instance last pushed, so stale
+// probes can be alerted on
+const pushTimeMetric = "hallmonitor_push_time_seconds"
+
+// pushKey identifies a group of pushed metrics
+type pushKey struct {
+	job      string
+	instance string
+}
+
+// pushedGroup is the latest push for a job and instance
+type pushedGroup struct {
+	families []*dto.MetricFamily
+	received time.Time
+}
+
+// PushedMetrics holds metrics pushed by remote probes. It gathers them with
+// job and instance labels, so they can be served next to the local registry.
+type PushedMetrics struct {
+	local  prometheus.Gatherer
+	mu     sync.RWMutex
+	groups map[pushKey]pushedGroup
+}
+
+// NewPushedMetrics creates an empty store. Pushes are checked against the
+// local gatherer so the combined output stays valid.
+func NewPushedMetrics(local prometheus.Gatherer) *PushedMetrics {
+	return &PushedMetrics{
+		local:  local,
+		groups: make(map[pushKey]pushedGroup),
+	}
+}
+
+// Replace stores a push, replacing any earlier push for the same job and
+// instance. Pushes that conflict with other metrics are rejected.
+func (p *PushedMetrics) Replace(job, instance string, families []*dto.MetricFamily) error {
+	if job == "" {
+		return fmt.Errorf("job is required")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := pushKey{job: job, instance: instance}
+	previous, existed := p.groups[key]
+	p.groups[key] = pushedGroup{families: families, received: time.Now()}
+
+	gatherers := prometheus.Gatherers{prometheus.GathererFunc(p.gatherLocked)}
+	if p.local != nil {
+		gatherers = append(gatherers, p.local)
+	}
+	if _, err := gatherers.Gather(); err != nil {
+		if existed {
+			p.groups[key] = previous
+		} else {
+			delete(p.groups, key)
+		}
+		return fmt.Errorf("pushed metrics are inconsistent: %w", err)
+	}
+	return nil
+}
+
+// Delete drops the metrics pushed for a job and instance
+func (p *PushedMetrics) Delete(job, instance string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.groups, pushKey{job: job, instance: instance})
+}
+
+// Gather returns every pushed metric labelled with its job and instance,
+// plus the time of each push
+func (p *PushedMetrics) Gather() ([]*dto.MetricFamily, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.gatherLocked()
+}
+
+// gatherLocked builds the gathered families; callers must hold p.mu
+func (p *PushedMetrics) gatherLocked() ([]*dto.MetricFamily, error) {
+	if len(p.groups) == 0 {
+		return nil, nil
+	}
+
+	byName := make(map[string]*dto.MetricFamily)
+	pushTimes := &dto.MetricFamily{
+		Name: proto.String(pushTimeMetric),
+		Help: proto.String("Last time a remote instance pushed metrics, in seconds since the epoch"),
+		Type: dto.MetricType_GAUGE.Enum(),
+	}
+	byName[pushTimeMetric] = pushTimes
+
+	for key, group := range p.groups {
+		labels := []*dto.LabelPair{
+			{Name: proto.String("job"), Value: proto.String(key.job)},
+			{Name: proto.String("instance"), Value: proto.String(key.instance)},
+		}
+		pushTimes.Metric = append(pushTimes.Metric, &dto.Metric{
+			Label: labels,
+			Gauge: &dto.Gauge{Value: proto.Float64(float64(group.received.UnixNano()) / 1e9)},
+		})
+
+		for _, family := range group.families {
+			merged, ok := byName[family.GetName()]
+			if !ok {
+				merged = &dto.MetricFamily{Name: family.Name, Help: family.Help, Type: family.Type, Unit: family.Unit}
+				byName[family.GetName()] = merged
+			} else if merged.GetType() != family.GetType() || merged.GetHelp() != family.GetHelp() {
+				return nil, fmt.Errorf("metric %s was pushed with a different type or help by job %q instance %q", family.GetName(), key.job, key.instance)
+			}
+			for _, metric := range family.Metric {
+				labelled := proto.Clone(metric).(*dto.Metric)
+				labelled.Label = withGroupLabels(labelled.Label, labels)
+				merged.Metric = append(merged.Metric, labelled)
+			}
+		}
+	}
+
+	families := make([]*dto.MetricFamily, 0, len(byName))
+	for _, family := range byName {
+		families = append(families, family)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].GetName() < families[j].GetName()
+	})
+	return families, nil
+}
+
+// withGroupLabels sets the job and instance labels, replacing any the
+// pushed metric carried itself
+func withGroupLabels(labels, group []*dto.LabelPair) []*dto.LabelPair {
+	result := make([]*dto.LabelPair, 0, len(labels)+len(group))
+	for _, label := range labels {
+		if label.GetName() != "job" && label.GetName() != "instance" {
+			result = append(result, label)
+		}
+	}
+	result = append(result, group...)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].GetName() < result[j].GetName()
+	})
+	return result
+}
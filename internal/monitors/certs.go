@@ -0,0 +1,80 @@
+package monitors
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// certificateChain describes the certificates a server presented, leaf first
+func certificateChain(certs []*x509.Certificate) []models.Certificate {
+	chain := make([]models.Certificate, 0, len(certs))
+	for _, cert := range certs {
+		chain = append(chain, describeCertificate(cert))
+	}
+	return chain
+}
+
+// describeCertificate extracts the fields reported for a certificate
+func describeCertificate(cert *x509.Certificate) models.Certificate {
+	fingerprint := sha256.Sum256(cert.Raw)
+
+	description := models.Certificate{
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		SerialNumber:       strings.ToUpper(cert.SerialNumber.Text(16)),
+		NotBefore:          cert.NotBefore,
+		NotAfter:           cert.NotAfter,
+		DNSNames:           cert.DNSNames,
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		IsCA:               cert.IsCA,
+		Fingerprint:        hex.EncodeToString(fingerprint[:]),
+		KeyPin:             models.FormatKeyPin(cert.RawSubjectPublicKeyInfo),
+	}
+	for _, ip := range cert.IPAddresses {
+		description.IPAddresses = append(description.IPAddresses, ip.String())
+	}
+	return description
+}
+
+// parseKeyPins decodes a monitor's pinnedKeys
+func parseKeyPins(pins []string) ([][]byte, error) {
+	digests := make([][]byte, 0, len(pins))
+	for _, pin := range pins {
+		digest, err := models.ParseKeyPin(pin)
+		if err != nil {
+			return nil, err
+		}
+		digests = append(digests, digest)
+	}
+	return digests, nil
+}
+
+// verifyKeyPins checks that a certificate in the served chain has one of the
+// pinned public keys. Any certificate may match, so pinning an intermediate
+// survives leaf renewals.
+func verifyKeyPins(state *tls.ConnectionState, pins [][]byte) error {
+	if len(pins) == 0 {
+		return nil
+	}
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("certificate pinning requires an HTTPS response")
+	}
+
+	for _, cert := range state.PeerCertificates {
+		digest := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+		for _, pin := range pins {
+			if bytes.Equal(digest[:], pin) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("certificate pin mismatch: no certificate in the served chain matches pinnedKeys (leaf key %s)",
+		models.FormatKeyPin(state.PeerCertificates[0].RawSubjectPublicKeyInfo))
+}
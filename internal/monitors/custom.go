@@ -0,0 +1,112 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// CheckFunc checks a monitor of a custom type. It may return a result with
+// details of its own, or nil when the monitor is up; an error marks the
+// monitor down.
+type CheckFunc func(ctx context.Context, config *models.Monitor) (*models.MonitorResult, error)
+
+// CustomFactory validates the settings of a custom monitor and returns the
+// function that checks it
+type CustomFactory func(config *models.Monitor) (CheckFunc, error)
+
+// customTypes holds the factories registered with RegisterType
+var (
+	customTypesMu sync.RWMutex
+	customTypes   = make(map[models.MonitorType]CustomFactory)
+)
+
+// RegisterType adds a monitor type implemented outside this package. It
+// applies to every monitor manager in the process, and monitors of the type
+// pass config validation.
+func RegisterType(monitorType models.MonitorType, factory CustomFactory) error {
+	if monitorType == "" || factory == nil {
+		return fmt.Errorf("monitor type and factory are required")
+	}
+	switch monitorType {
+	case models.MonitorTypePing, models.MonitorTypeHTTP, models.MonitorTypeTCP,
+		models.MonitorTypeDNS, models.MonitorTypeRBL, models.MonitorTypeDomain, models.MonitorTypePlugin, models.MonitorTypeWasm:
+		return fmt.Errorf("monitor type %s is built in", monitorType)
+	}
+
+	customTypesMu.Lock()
+	defer customTypesMu.Unlock()
+	if _, exists := customTypes[monitorType]; exists {
+		return fmt.Errorf("monitor type %s is already registered", monitorType)
+	}
+	customTypes[monitorType] = factory
+	config.RegisterMonitorType(monitorType)
+	return nil
+}
+
+// customFactory returns the factory registered for monitorType
+func customFactory(monitorType models.MonitorType) (CustomFactory, bool) {
+	customTypesMu.RLock()
+	defer customTypesMu.RUnlock()
+	factory, ok := customTypes[monitorType]
+	return factory, ok
+}
+
+// CustomMonitor runs checks of a registered monitor type
+type CustomMonitor struct {
+	*BaseMonitor
+	check CheckFunc
+}
+
+// NewCustomMonitor creates a monitor that checks with check
+func NewCustomMonitor(config *models.Monitor, group string, check CheckFunc, logger *logging.Logger, metrics *metrics.Metrics) *CustomMonitor {
+	return &CustomMonitor{
+		BaseMonitor: NewBaseMonitor(config, group, logger, metrics),
+		check:       check,
+	}
+}
+
+// Check runs the check function, filling in the common result fields it
+// leaves out
+func (c *CustomMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
+	startTime := time.Now()
+	result, err := c.check(ctx, c.Config)
+	duration := time.Since(startTime)
+
+	if result == nil {
+		result = c.CreateResult(models.StatusUp, duration, err)
+	} else {
+		result.Monitor = c.Config.Name
+		result.Type = c.Config.Type
+		result.Group = c.Group
+		if result.Status == "" {
+			result.Status = models.StatusUp
+		}
+		if result.Duration == 0 {
+			result.Duration = duration
+		}
+		if result.Timestamp.IsZero() {
+			result.Timestamp = time.Now()
+		}
+		if err != nil {
+			result.Status = models.StatusDown
+			result.Error = err.Error()
+		}
+	}
+
+	c.RecordMetrics(result)
+	c.LogResult(result)
+	return result, nil
+}
+
+// Validate is a no-op; the factory validates the settings when the monitor
+// is created
+func (c *CustomMonitor) Validate() error {
+	return nil
+}
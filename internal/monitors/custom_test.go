@@ -0,0 +1,100 @@
+package monitors
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestRegisterType(t *testing.T) {
+	check := func(ctx context.Context, config *models.Monitor) (*models.MonitorResult, error) {
+		return nil, nil
+	}
+	factory := func(config *models.Monitor) (CheckFunc, error) {
+		return check, nil
+	}
+
+	if err := RegisterType("custom-register", factory); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tests := []struct {
+		name        string
+		monitorType models.MonitorType
+		factory     CustomFactory
+	}{
+		{name: "duplicate", monitorType: "custom-register", factory: factory},
+		{name: "built in", monitorType: models.MonitorTypeHTTP, factory: factory},
+		{name: "no type", monitorType: "", factory: factory},
+		{name: "no factory", monitorType: "custom-nil", factory: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterType(tt.monitorType, tt.factory); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestCustomMonitorCheck(t *testing.T) {
+	var response *models.MonitorResult
+	var responseErr error
+	err := RegisterType("custom-check", func(config *models.Monitor) (CheckFunc, error) {
+		if config.Options["region"] == "" {
+			return nil, errors.New("region option is required")
+		}
+		return func(ctx context.Context, config *models.Monitor) (*models.MonitorResult, error) {
+			return response, responseErr
+		}, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to register type: %v", err)
+	}
+
+	manager := setupTestManager(t)
+	config := &models.Monitor{
+		Name:     "queue",
+		Type:     "custom-check",
+		Interval: models.Duration(30 * time.Second),
+		Timeout:  models.Duration(5 * time.Second),
+	}
+	if _, err := manager.factory.CreateMonitor(config, "jobs"); err == nil {
+		t.Fatal("expected the factory's validation error")
+	}
+	config.Options = map[string]string{"region": "eu"}
+	monitor, err := manager.factory.CreateMonitor(config, "jobs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		response   *models.MonitorResult
+		err        error
+		wantStatus models.MonitorStatus
+		wantError  string
+	}{
+		{name: "nil result", wantStatus: models.StatusUp},
+		{name: "error", err: errors.New("queue is stuck"), wantStatus: models.StatusDown, wantError: "queue is stuck"},
+		{name: "own result", response: &models.MonitorResult{Status: models.StatusUnknown}, wantStatus: models.StatusUnknown},
+		{name: "result and error", response: &models.MonitorResult{}, err: errors.New("backlog"), wantStatus: models.StatusDown, wantError: "backlog"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, responseErr = tt.response, tt.err
+			result, err := monitor.Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != tt.wantStatus || result.Error != tt.wantError {
+				t.Errorf("got status %s error %q, want %s %q", result.Status, result.Error, tt.wantStatus, tt.wantError)
+			}
+			if result.Monitor != "queue" || result.Group != "jobs" || result.Type != "custom-check" || result.Timestamp.IsZero() {
+				t.Errorf("common fields not filled in: %+v", result)
+			}
+		})
+	}
+}
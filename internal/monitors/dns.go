@@ -47,18 +47,20 @@ func NewDNSMonitor(config *models.Monitor, group string, logger *logging.Logger,
 		timeout = 5 * time.Second
 	}
 
+	base := NewBaseMonitor(config, group, logger, metrics)
+	if err := base.initSource(); err != nil {
+		return nil, err
+	}
+
 	resolver := &net.Resolver{
 		PreferGo: true,
 		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
-			d := net.Dialer{
-				Timeout: timeout,
-			}
-			return d.DialContext(ctx, network, net.JoinHostPort(server, port))
+			return base.newDialer(network, timeout).DialContext(ctx, network, net.JoinHostPort(server, port))
 		},
 	}
 
 	return &DNSMonitor{
-		BaseMonitor: NewBaseMonitor(config, group, logger, metrics),
+		BaseMonitor: base,
 		resolver:    resolver,
 		server:      server,
 		port:        port,
@@ -74,6 +76,9 @@ func (d *DNSMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
 	var err error
 	var rcode int = 0 // NOERROR
 
+	trace := traceFrom(ctx)
+	trace.Add(TracePhaseDNS, "Querying %s for %s %s", net.JoinHostPort(d.server, d.port), d.queryType, d.Config.Query)
+
 	// Perform DNS query based on type
 	switch strings.ToUpper(d.queryType) {
 	case "A":
@@ -145,6 +150,11 @@ func (d *DNSMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
 	}
 
 	duration := time.Since(startTime)
+	if err != nil {
+		trace.Add(TracePhaseDNS, "Query failed with rcode %d: %v", rcode, err)
+	} else {
+		trace.Add(TracePhaseDNS, "Answered %v", answers)
+	}
 
 	// Create DNS result data
 	dnsResult := &models.DNSResult{
@@ -194,6 +204,7 @@ func (d *DNSMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
 			d.server,
 			rcode,
 			duration,
+			result.ID(),
 		)
 	}
 
@@ -0,0 +1,436 @@
+package monitors
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+var (
+	// rdapBootstrapURL is the IANA registry mapping TLDs to RDAP servers
+	rdapBootstrapURL = "https://data.iana.org/rdap/dns.json"
+
+	// whoisRootServer answers which WHOIS server is authoritative for a TLD
+	whoisRootServer = "whois.iana.org:43"
+
+	// errNoRDAPServer reports a TLD without an RDAP service in the bootstrap
+	errNoRDAPServer = errors.New("no RDAP server for TLD")
+
+	// whoisExpiryFields are the WHOIS keys registries use for the expiry date
+	whoisExpiryFields = []string{
+		"registry expiry date",
+		"registrar registration expiration date",
+		"expiration date",
+		"expiry date",
+		"expire date",
+		"expires on",
+		"expires",
+		"paid-till",
+		"renewal date",
+	}
+
+	// whoisDateLayouts are the date formats seen in WHOIS responses
+	whoisDateLayouts = []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05Z",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"2006.01.02",
+		"2006/01/02",
+		"02-Jan-2006",
+		"02.01.2006",
+	}
+)
+
+const (
+	// rdapBootstrapTTL is how long the IANA bootstrap is cached
+	rdapBootstrapTTL = 24 * time.Hour
+
+	// maxRDAPBytes bounds RDAP and bootstrap response sizes
+	maxRDAPBytes = 4 << 20
+
+	// defaultExpiryWarningDays is used when expiryWarningDays is unset
+	defaultExpiryWarningDays = 30
+)
+
+// rdapBootstrap caches the IANA TLD to RDAP server mapping across monitors
+var rdapBootstrap struct {
+	mu        sync.Mutex
+	servers   map[string]string
+	fetchedAt time.Time
+}
+
+// DomainMonitor checks domain registration expiry using RDAP, falling back
+// to WHOIS for TLDs without an RDAP service
+type DomainMonitor struct {
+	*BaseMonitor
+	domain   string
+	client   *http.Client
+	dialer   func() *net.Dialer
+	resolver *hostResolver
+}
+
+// NewDomainMonitor creates a new domain expiry monitor
+func NewDomainMonitor(config *models.Monitor, group string, logger *logging.Logger, metrics *metrics.Metrics) (*DomainMonitor, error) {
+	domain := normalizeHost(config.Target)
+	if err := validateDomainName(domain); err != nil {
+		return nil, err
+	}
+
+	timeout := config.Timeout.ToDuration()
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	base := NewBaseMonitor(config, group, logger, metrics)
+	if err := base.initSource(); err != nil {
+		return nil, err
+	}
+	newDialer := func(network string) *net.Dialer {
+		return base.newDialer(network, timeout)
+	}
+
+	resolver, err := newHostResolver(config, newDialer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolver settings: %w", err)
+	}
+
+	dialer := newDialer("tcp")
+	client := &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return resolver.DialContext(ctx, dialer, network, address)
+			},
+			// Checks are daily; there is nothing worth keeping alive
+			DisableKeepAlives: true,
+		},
+	}
+
+	return &DomainMonitor{
+		BaseMonitor: base,
+		domain:      domain,
+		client:      client,
+		dialer:      func() *net.Dialer { return newDialer("tcp") },
+		resolver:    resolver,
+	}, nil
+}
+
+// Check looks up the domain's expiry date and reports days remaining. The
+// monitor goes down once the registration has expired.
+func (d *DomainMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
+	startTime := time.Now()
+
+	domainResult, err := d.lookupRDAP(ctx)
+	if errors.Is(err, errNoRDAPServer) {
+		domainResult, err = d.lookupWHOIS(ctx)
+	}
+	duration := time.Since(startTime)
+
+	if err != nil {
+		result := d.CreateResult(models.StatusDown, duration, err)
+		d.RecordMetrics(result)
+		d.LogResult(result)
+		return result, nil
+	}
+
+	domainResult.DaysRemaining = int(time.Until(domainResult.ExpiresAt).Hours() / 24)
+
+	var checkErr error
+	status := models.StatusUp
+	if !time.Now().Before(domainResult.ExpiresAt) {
+		status = models.StatusDown
+		checkErr = fmt.Errorf("domain %s expired on %s", d.domain, domainResult.ExpiresAt.Format("2006-01-02"))
+	} else {
+		warningDays := d.Config.ExpiryWarningDays
+		if warningDays == 0 {
+			warningDays = defaultExpiryWarningDays
+		}
+		if domainResult.DaysRemaining < warningDays && d.Logger != nil {
+			d.Logger.WithComponent(logging.ComponentMonitor).
+				WithFields(map[string]interface{}{
+					"monitor":                d.Config.Name,
+					"domain":                 d.domain,
+					"expires_at":             domainResult.ExpiresAt,
+					"days_left":              domainResult.DaysRemaining,
+					"warning_threshold_days": warningDays,
+				}).
+				Warn("Domain registration expires soon")
+		}
+	}
+
+	if d.Metrics != nil {
+		d.Metrics.RecordDomainExpiry(d.Config.Name, d.Group, d.domain, domainResult.ExpiresAt)
+	}
+
+	result := d.CreateResult(status, duration, checkErr)
+	result.DomainResult = domainResult
+
+	d.RecordMetrics(result)
+	d.LogResult(result)
+
+	return result, nil
+}
+
+// Validate validates the domain monitor configuration
+func (d *DomainMonitor) Validate() error {
+	if d.Config.Target == "" {
+		return fmt.Errorf("domain monitor requires target")
+	}
+	return validateDomainName(normalizeHost(d.Config.Target))
+}
+
+// lookupRDAP queries the domain's RDAP server for its expiration event
+func (d *DomainMonitor) lookupRDAP(ctx context.Context) (*models.DomainResult, error) {
+	server := d.Config.RDAPServer
+	if server == "" {
+		var err error
+		if server, err = d.rdapServer(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var response struct {
+		Events []struct {
+			Action string `json:"eventAction"`
+			Date   string `json:"eventDate"`
+		} `json:"events"`
+		Entities []struct {
+			Roles []string        `json:"roles"`
+			VCard json.RawMessage `json:"vcardArray"`
+		} `json:"entities"`
+	}
+	if err := d.getJSON(ctx, strings.TrimSuffix(server, "/")+"/domain/"+d.domain, &response); err != nil {
+		return nil, fmt.Errorf("RDAP lookup failed: %w", err)
+	}
+
+	result := &models.DomainResult{Domain: d.domain, Source: "rdap"}
+	for _, event := range response.Events {
+		if event.Action != "expiration" {
+			continue
+		}
+		expiresAt, err := time.Parse(time.RFC3339, event.Date)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RDAP expiration date %q: %w", event.Date, err)
+		}
+		result.ExpiresAt = expiresAt
+	}
+	if result.ExpiresAt.IsZero() {
+		return nil, fmt.Errorf("RDAP response for %s has no expiration date", d.domain)
+	}
+
+	for _, entity := range response.Entities {
+		for _, role := range entity.Roles {
+			if role == "registrar" {
+				result.Registrar = vcardName(entity.VCard)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// rdapServer finds the RDAP base URL for the domain's TLD in the IANA bootstrap
+func (d *DomainMonitor) rdapServer(ctx context.Context) (string, error) {
+	rdapBootstrap.mu.Lock()
+	defer rdapBootstrap.mu.Unlock()
+
+	if rdapBootstrap.servers == nil || time.Since(rdapBootstrap.fetchedAt) > rdapBootstrapTTL {
+		var bootstrap struct {
+			Services [][][]string `json:"services"`
+		}
+		if err := d.getJSON(ctx, rdapBootstrapURL, &bootstrap); err != nil {
+			if rdapBootstrap.servers == nil {
+				return "", fmt.Errorf("failed to load RDAP bootstrap: %w", err)
+			}
+			// Keep using the stale copy rather than failing every check
+		} else {
+			servers := make(map[string]string)
+			for _, service := range bootstrap.Services {
+				if len(service) < 2 || len(service[1]) == 0 {
+					continue
+				}
+				for _, tld := range service[0] {
+					servers[strings.ToLower(tld)] = service[1][0]
+				}
+			}
+			rdapBootstrap.servers = servers
+			rdapBootstrap.fetchedAt = time.Now()
+		}
+	}
+
+	// Prefer the longest matching suffix, e.g. "co.uk" over "uk"
+	labels := strings.Split(d.domain, ".")
+	for i := 1; i < len(labels); i++ {
+		if server, ok := rdapBootstrap.servers[strings.Join(labels[i:], ".")]; ok {
+			return server, nil
+		}
+	}
+	return "", errNoRDAPServer
+}
+
+// getJSON fetches url and decodes the JSON response into v
+func (d *DomainMonitor) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+	req.Header.Set("User-Agent", "HallMonitor/1.0")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxRDAPBytes)).Decode(v)
+}
+
+// lookupWHOIS finds the TLD's WHOIS server through IANA and parses the
+// expiry date from the domain's record
+func (d *DomainMonitor) lookupWHOIS(ctx context.Context) (*models.DomainResult, error) {
+	tld := d.domain[strings.LastIndex(d.domain, ".")+1:]
+	referral, err := d.queryWHOIS(ctx, whoisRootServer, tld)
+	if err != nil {
+		return nil, fmt.Errorf("WHOIS lookup failed: %w", err)
+	}
+	server := whoisField(referral, "whois", "refer")
+	if server == "" {
+		return nil, fmt.Errorf("no RDAP or WHOIS server for .%s", tld)
+	}
+
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "43")
+	}
+	record, err := d.queryWHOIS(ctx, server, d.domain)
+	if err != nil {
+		return nil, fmt.Errorf("WHOIS lookup failed: %w", err)
+	}
+
+	expiry := whoisField(record, whoisExpiryFields...)
+	if expiry == "" {
+		return nil, fmt.Errorf("WHOIS record for %s has no expiry date", d.domain)
+	}
+	expiresAt, err := parseWHOISDate(expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DomainResult{
+		Domain:    d.domain,
+		ExpiresAt: expiresAt,
+		Registrar: whoisField(record, "registrar", "registrar name"),
+		Source:    "whois",
+	}, nil
+}
+
+// queryWHOIS sends query to a WHOIS server and returns its response
+func (d *DomainMonitor) queryWHOIS(ctx context.Context, server, query string) (string, error) {
+	conn, err := d.resolver.DialContext(ctx, d.dialer(), "tcp", server)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := fmt.Fprintf(conn, "%s\r\n", query); err != nil {
+		return "", err
+	}
+
+	response, err := io.ReadAll(io.LimitReader(conn, maxRDAPBytes))
+	if err != nil {
+		return "", err
+	}
+	return string(response), nil
+}
+
+// whoisField returns the value of the first of keys present in a WHOIS
+// response; keys are matched case-insensitively
+func whoisField(response string, keys ...string) string {
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(response))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, "%") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if _, seen := values[key]; !seen {
+			values[key] = strings.TrimSpace(value)
+		}
+	}
+
+	for _, key := range keys {
+		if value := values[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// parseWHOISDate parses the expiry date formats used by common registries
+func parseWHOISDate(value string) (time.Time, error) {
+	// Drop trailing annotations such as "2025-01-01 (YYYY-MM-DD)"
+	if fields := strings.Fields(value); len(fields) > 0 && !strings.Contains(value, ":") {
+		value = fields[0]
+	}
+	for _, layout := range whoisDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized WHOIS expiry date %q", value)
+}
+
+// vcardName extracts the "fn" property from a jCard array
+func vcardName(raw json.RawMessage) string {
+	var card []interface{}
+	if err := json.Unmarshal(raw, &card); err != nil || len(card) < 2 {
+		return ""
+	}
+	properties, ok := card[1].([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, property := range properties {
+		fields, ok := property.([]interface{})
+		if !ok || len(fields) < 4 || fields[0] != "fn" {
+			continue
+		}
+		if name, ok := fields[3].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// validateDomainName checks that target is a registrable domain name
+func validateDomainName(domain string) error {
+	if domain == "" {
+		return fmt.Errorf("domain monitor requires target")
+	}
+	if net.ParseIP(domain) != nil || strings.ContainsAny(domain, "/: ") || !strings.Contains(domain, ".") {
+		return fmt.Errorf("invalid domain %q", domain)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package monitors
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// newRDAPServer serves an RDAP domain response expiring at expiresAt
+func newRDAPServer(t *testing.T, expiresAt time.Time) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/domain/") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/rdap+json")
+		fmt.Fprintf(w, `{
+			"ldhName": %q,
+			"events": [
+				{"eventAction": "registration", "eventDate": "2000-01-01T00:00:00Z"},
+				{"eventAction": "expiration", "eventDate": %q}
+			],
+			"entities": [
+				{"roles": ["registrar"], "vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Example Registrar"]]]}
+			]
+		}`, strings.TrimPrefix(r.URL.Path, "/domain/"), expiresAt.Format(time.RFC3339))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDomainMonitorRDAP(t *testing.T) {
+	tests := []struct {
+		name       string
+		expiresAt  time.Time
+		wantStatus models.MonitorStatus
+		wantDays   int
+	}{
+		{name: "valid", expiresAt: time.Now().Add(90*24*time.Hour + time.Hour), wantStatus: models.StatusUp, wantDays: 90},
+		{name: "expiring soon", expiresAt: time.Now().Add(5*24*time.Hour + time.Hour), wantStatus: models.StatusUp, wantDays: 5},
+		{name: "expired", expiresAt: time.Now().Add(-48 * time.Hour), wantStatus: models.StatusDown, wantDays: -2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newRDAPServer(t, tt.expiresAt)
+			monitor, err := NewDomainMonitor(&models.Monitor{
+				Type:       models.MonitorTypeDomain,
+				Name:       "example-domain",
+				Target:     "Example.com",
+				RDAPServer: server.URL,
+			}, "domains", nil, nil)
+			if err != nil {
+				t.Fatalf("NewDomainMonitor failed: %v", err)
+			}
+
+			result, err := monitor.Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s (%s)", tt.wantStatus, result.Status, result.Error)
+			}
+
+			domain := result.DomainResult
+			if domain == nil {
+				t.Fatal("expected domain result")
+			}
+			if domain.DaysRemaining != tt.wantDays {
+				t.Errorf("expected %d days remaining, got %d", tt.wantDays, domain.DaysRemaining)
+			}
+			if domain.Domain != "example.com" || domain.Registrar != "Example Registrar" || domain.Source != "rdap" {
+				t.Errorf("unexpected domain result: %+v", domain)
+			}
+		})
+	}
+}
+
+func TestDomainMonitorBootstrapAndWHOISFallback(t *testing.T) {
+	rdap := newRDAPServer(t, time.Now().Add(30*24*time.Hour))
+	bootstrap := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"services": [[["com", "net"], [%q]], [["co.uk"], [%q]]]}`, rdap.URL+"/", rdap.URL+"/")
+	}))
+	defer bootstrap.Close()
+
+	// A WHOIS server that refers TLD queries to itself
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			query, _ := bufio.NewReader(conn).ReadString('\n')
+			switch strings.TrimSpace(query) {
+			case "example":
+				fmt.Fprintf(conn, "%% IANA WHOIS server\r\nrefer:        %s\r\n", listener.Addr())
+			case "shop.example":
+				fmt.Fprint(conn, "Domain Name: SHOP.EXAMPLE\r\nRegistrar: WHOIS Registrar\r\nRegistry Expiry Date: 2099-01-02T00:00:00Z\r\n")
+			}
+			conn.Close()
+		}
+	}()
+
+	previousBootstrap, previousRoot := rdapBootstrapURL, whoisRootServer
+	rdapBootstrapURL, whoisRootServer = bootstrap.URL, listener.Addr().String()
+	rdapBootstrap.servers = nil
+	t.Cleanup(func() {
+		rdapBootstrapURL, whoisRootServer = previousBootstrap, previousRoot
+		rdapBootstrap.servers = nil
+	})
+
+	tests := []struct {
+		target     string
+		wantSource string
+	}{
+		{target: "example.com", wantSource: "rdap"},
+		{target: "example.co.uk", wantSource: "rdap"},
+		{target: "shop.example", wantSource: "whois"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			monitor, err := NewDomainMonitor(&models.Monitor{
+				Type:    models.MonitorTypeDomain,
+				Name:    tt.target,
+				Target:  tt.target,
+				Timeout: models.Duration(2 * time.Second),
+			}, "domains", nil, nil)
+			if err != nil {
+				t.Fatalf("NewDomainMonitor failed: %v", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			result, _ := monitor.Check(ctx)
+			if result.Status != models.StatusUp {
+				t.Fatalf("expected up, got %s (%s)", result.Status, result.Error)
+			}
+			if result.DomainResult.Source != tt.wantSource {
+				t.Errorf("expected source %s, got %s", tt.wantSource, result.DomainResult.Source)
+			}
+		})
+	}
+}
+
+func TestParseWHOISDate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "2026-08-13T04:00:00Z", want: "2026-08-13"},
+		{value: "2026-08-13T04:00:00.0Z", want: "2026-08-13"},
+		{value: "2026-08-13 04:00:00", want: "2026-08-13"},
+		{value: "2026-08-13", want: "2026-08-13"},
+		{value: "2026.08.13", want: "2026-08-13"},
+		{value: "13-Aug-2026", want: "2026-08-13"},
+		{value: "2026-08-13 (YYYY-MM-DD)", want: "2026-08-13"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseWHOISDate(tt.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Format("2006-01-02") != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got.Format("2006-01-02"))
+			}
+		})
+	}
+
+	if _, err := parseWHOISDate("next tuesday"); err == nil {
+		t.Error("expected error for unrecognized date")
+	}
+}
+
+func TestDomainMonitorValidate(t *testing.T) {
+	for _, target := range []string{"", "192.0.2.1", "https://example.com", "localhost"} {
+		if _, err := NewDomainMonitor(&models.Monitor{Type: models.MonitorTypeDomain, Name: "bad", Target: target}, "domains", nil, nil); err == nil {
+			t.Errorf("expected error for target %q", target)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package monitors
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"syscall"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// metadataNetworks are cloud metadata endpoints outside the link-local ranges
+var metadataNetworks = mustparseNetworks(
+	"fd00:ec2::254/128",  // AWS IPv6 instance metadata
+	"100.100.100.200/32", // Alibaba Cloud metadata
+	"168.63.129.16/32",   // Azure wire server
+)
+
+// EgressGuard enforces one or more egress policies on outgoing connections.
+// An address must be permitted by every policy to be reachable.
+type EgressGuard struct {
+	rules []egressRule
+}
+
+type egressRule struct {
+	allow         []*net.IPNet
+	deny          []*net.IPNet
+	denyPrivate   bool
+	denyLinkLocal bool
+}
+
+// NewEgressGuard compiles the given policies, skipping nil or empty ones. It
+// returns nil when no policy places any restriction.
+func NewEgressGuard(policies ...*models.EgressPolicy) (*EgressGuard, error) {
+	var rules []egressRule
+	for _, policy := range policies {
+		if policy.IsZero() {
+			continue
+		}
+
+		allow, err := ParseNetworks(policy.Allow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid egress allow entry: %w", err)
+		}
+		deny, err := ParseNetworks(policy.Deny)
+		if err != nil {
+			return nil, fmt.Errorf("invalid egress deny entry: %w", err)
+		}
+
+		rules = append(rules, egressRule{
+			allow:         allow,
+			deny:          deny,
+			denyPrivate:   policy.DenyPrivate,
+			denyLinkLocal: policy.DenyLinkLocal,
+		})
+	}
+
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &EgressGuard{rules: rules}, nil
+}
+
+// CheckIP returns an error wrapping ErrEgressDenied if ip may not be reached
+func (g *EgressGuard) CheckIP(ip net.IP) error {
+	if g == nil {
+		return nil
+	}
+	for _, rule := range g.rules {
+		if reason := rule.denies(ip); reason != "" {
+			return fmt.Errorf("%w: %s %s", ErrEgressDenied, ip, reason)
+		}
+	}
+	return nil
+}
+
+// Control is a net.Dialer Control hook that checks the resolved address right
+// before connecting, so DNS answers cannot be used to bypass the policy
+func (g *EgressGuard) Control(network, address string, _ syscall.RawConn) error {
+	if g == nil {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: cannot parse address %q", ErrEgressDenied, address)
+	}
+	return g.CheckIP(ip)
+}
+
+// denies returns why the rule blocks ip, or an empty string if it is allowed
+func (r egressRule) denies(ip net.IP) string {
+	for _, network := range r.deny {
+		if network.Contains(ip) {
+			return "is in denied network " + network.String()
+		}
+	}
+	if r.denyPrivate && (ip.IsPrivate() || ip.IsLoopback() || ip.IsUnspecified()) {
+		return "is a private address"
+	}
+	if r.denyLinkLocal {
+		if ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+			return "is a link-local address"
+		}
+		for _, network := range metadataNetworks {
+			if network.Contains(ip) {
+				return "is a cloud metadata address"
+			}
+		}
+	}
+	if len(r.allow) > 0 {
+		for _, network := range r.allow {
+			if network.Contains(ip) {
+				return ""
+			}
+		}
+		return "is not in an allowed network"
+	}
+	return ""
+}
+
+// ParseNetworks parses CIDRs or single IP addresses into networks
+func ParseNetworks(entries []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR", entry)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func mustparseNetworks(entries ...string) []*net.IPNet {
+	networks, err := ParseNetworks(entries)
+	if err != nil {
+		panic(err)
+	}
+	return networks
+}
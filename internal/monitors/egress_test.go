@@ -0,0 +1,155 @@
+package monitors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestEgressGuardCheckIP(t *testing.T) {
+	tests := []struct {
+		name     string
+		policies []*models.EgressPolicy
+		ip       string
+		denied   bool
+	}{
+		{
+			name:     "private denied",
+			policies: []*models.EgressPolicy{{DenyPrivate: true}},
+			ip:       "10.1.2.3",
+			denied:   true,
+		},
+		{
+			name:     "loopback denied as private",
+			policies: []*models.EgressPolicy{{DenyPrivate: true}},
+			ip:       "127.0.0.1",
+			denied:   true,
+		},
+		{
+			name:     "public allowed when denying private",
+			policies: []*models.EgressPolicy{{DenyPrivate: true}},
+			ip:       "93.184.216.34",
+		},
+		{
+			name:     "metadata endpoint denied",
+			policies: []*models.EgressPolicy{{DenyLinkLocal: true}},
+			ip:       "169.254.169.254",
+			denied:   true,
+		},
+		{
+			name:     "ipv6 metadata endpoint denied",
+			policies: []*models.EgressPolicy{{DenyLinkLocal: true}},
+			ip:       "fd00:ec2::254",
+			denied:   true,
+		},
+		{
+			name:     "outside allow list",
+			policies: []*models.EgressPolicy{{Allow: []string{"192.168.0.0/16"}}},
+			ip:       "10.0.0.1",
+			denied:   true,
+		},
+		{
+			name:     "inside allow list",
+			policies: []*models.EgressPolicy{{Allow: []string{"192.168.0.0/16"}}},
+			ip:       "192.168.4.4",
+		},
+		{
+			name:     "deny wins over allow",
+			policies: []*models.EgressPolicy{{Allow: []string{"192.168.0.0/16"}, Deny: []string{"192.168.4.4"}}},
+			ip:       "192.168.4.4",
+			denied:   true,
+		},
+		{
+			name: "monitor policy cannot widen global policy",
+			policies: []*models.EgressPolicy{
+				{DenyPrivate: true},
+				{Allow: []string{"10.0.0.0/8"}},
+			},
+			ip:     "10.0.0.1",
+			denied: true,
+		},
+		{
+			name:     "ipv4-mapped ipv6 matched as ipv4",
+			policies: []*models.EgressPolicy{{Deny: []string{"10.0.0.0/8"}}},
+			ip:       "::ffff:10.0.0.1",
+			denied:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard, err := NewEgressGuard(tt.policies...)
+			if err != nil {
+				t.Fatalf("NewEgressGuard returned error: %v", err)
+			}
+
+			err = guard.CheckIP(net.ParseIP(tt.ip))
+			if tt.denied != (err != nil) {
+				t.Fatalf("expected denied=%v, got %v", tt.denied, err)
+			}
+			if err != nil && !errors.Is(err, ErrEgressDenied) {
+				t.Errorf("expected ErrEgressDenied, got %v", err)
+			}
+		})
+	}
+}
+
+func TestNewEgressGuard(t *testing.T) {
+	guard, err := NewEgressGuard(nil, &models.EgressPolicy{})
+	if err != nil || guard != nil {
+		t.Fatalf("expected nil guard for empty policies, got %v, %v", guard, err)
+	}
+	if err := guard.CheckIP(net.ParseIP("10.0.0.1")); err != nil {
+		t.Errorf("expected nil guard to allow everything, got %v", err)
+	}
+
+	if _, err := NewEgressGuard(&models.EgressPolicy{Deny: []string{"not-a-cidr"}}); err == nil {
+		t.Error("expected error for invalid deny entry")
+	}
+}
+
+func TestEgressPolicyBlocksChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	factory := NewMonitorFactory(nil, nil)
+	if err := factory.SetEgressPolicy(&models.EgressPolicy{DenyPrivate: true}); err != nil {
+		t.Fatalf("SetEgressPolicy returned error: %v", err)
+	}
+
+	configs := []*models.Monitor{
+		{Type: models.MonitorTypeHTTP, Name: "http", URL: server.URL},
+		{Type: models.MonitorTypeTCP, Name: "tcp", Target: listener.Addr().String()},
+	}
+
+	for _, config := range configs {
+		t.Run(config.Name, func(t *testing.T) {
+			monitor, err := factory.CreateMonitor(config, "test-group")
+			if err != nil {
+				t.Fatalf("CreateMonitor returned error: %v", err)
+			}
+
+			result, err := monitor.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if result.Status != models.StatusDown || !strings.Contains(result.Error, ErrEgressDenied.Error()) {
+				t.Errorf("expected egress denial, got status %s error %q", result.Status, result.Error)
+			}
+		})
+	}
+}
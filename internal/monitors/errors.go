@@ -27,6 +27,12 @@ var (
 
 	// ErrUnexpectedResponse indicates an unexpected response
 	ErrUnexpectedResponse = errors.New("unexpected response")
+
+	// ErrEgressDenied indicates the egress policy blocked a connection
+	ErrEgressDenied = errors.New("egress denied")
+
+	// ErrUnexpectedIP indicates a target resolved to an address outside its expected IPs
+	ErrUnexpectedIP = errors.New("unexpected IP address")
 )
 
 // MonitorError represents a structured monitor error with context
@@ -1,12 +1,22 @@
 package monitors
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"maps"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/1broseidon/hallmonitor/internal/logging"
@@ -17,9 +27,47 @@ import (
 // HTTPMonitor implements HTTP/HTTPS monitoring
 type HTTPMonitor struct {
 	*BaseMonitor
-	client *http.Client
+	client    *http.Client
+	transport *http.Transport
+
+	resolver    *hostResolver
+	expectedIPs []*net.IPNet
+
+	// captureHeaders are the canonical names of response headers kept in results
+	captureHeaders []string
+
+	// keyPins are the SHA-256 digests of pinned public keys
+	keyPins [][]byte
+
+	// lastBodyHash is the body hash from the previous check when detecting content changes
+	lastBodyHash string
+	bodyMu       sync.Mutex
+
+	// Validators from the last full response, sent with conditional requests
+	lastModified string
+	etag         string
+	validatorMu  sync.Mutex
 }
 
+const (
+	// maxDrainBytes bounds how much of a response body is read so its connection
+	// can be reused; larger bodies are abandoned and the connection closed
+	maxDrainBytes = 64 << 10
+
+	// maxContentBytes bounds how much of a response body is searched for the
+	// expected response and hashed for change detection
+	maxContentBytes = 1 << 20
+
+	// bodySnippetBytes is the length of the body excerpt kept with each result
+	bodySnippetBytes = 200
+
+	// cacheBustParam is the query parameter cacheBust adds to requests
+	cacheBustParam = "_hm"
+)
+
+// defaultCaptureHeaders are the response headers every HTTP result records
+var defaultCaptureHeaders = []string{"Content-Type", "Server", "Cache-Control", "Alt-Svc"}
+
 // NewHTTPMonitor creates a new HTTP monitor
 func NewHTTPMonitor(config *models.Monitor, group string, logger *logging.Logger, metrics *metrics.Metrics) (*HTTPMonitor, error) {
 	// Create HTTP client with timeout
@@ -33,27 +81,81 @@ func NewHTTPMonitor(config *models.Monitor, group string, logger *logging.Logger
 		InsecureSkipVerify: false, // Always verify certificates
 	}
 
-	client := &http.Client{
-		Timeout: timeout,
-		Transport: &http.Transport{
-			TLSClientConfig:    tlsConfig,
-			MaxIdleConns:       10,
-			IdleConnTimeout:    30 * time.Second,
-			DisableCompression: false,
-			DisableKeepAlives:  false,
+	base := NewBaseMonitor(config, group, logger, metrics)
+	if err := base.initSource(); err != nil {
+		return nil, err
+	}
+	newDialer := func(network string) *net.Dialer {
+		return base.newDialer(network, timeout)
+	}
+
+	// Enforce the source address and egress policy on every connection,
+	// including redirects
+	dialer := newDialer("tcp")
+
+	resolver, err := newHostResolver(config, newDialer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolver settings: %w", err)
+	}
+
+	protocols, err := httpProtocols(config.HTTPVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	expectedIPs, err := expectedNetworks(config)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPins, err := parseKeyPins(config.PinnedKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	// One transport per monitor keeps its connections alive between checks
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return resolver.DialContext(ctx, dialer, network, address)
 		},
+		TLSClientConfig:     tlsConfig,
+		MaxIdleConns:        10,
+		MaxIdleConnsPerHost: 10,
+		MaxConnsPerHost:     config.MaxConnections,
+		IdleConnTimeout:     30 * time.Second,
+		DisableCompression:  false,
+		DisableKeepAlives:   config.DisableConnectionReuse,
+		Protocols:           protocols,
+	}
+
+	// Keep idle connections around at least as long as the check interval
+	if interval := config.Interval.ToDuration(); interval > 0 && !config.DisableConnectionReuse {
+		transport.IdleConnTimeout = max(transport.IdleConnTimeout, interval+timeout)
+	}
+
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			// Allow up to 5 redirects
 			if len(via) >= 5 {
 				return fmt.Errorf("too many redirects")
 			}
+			if req.Response != nil {
+				traceFrom(req.Context()).Add(TracePhaseRedirect, "%s redirected to %s", req.Response.Status, req.URL)
+			}
 			return nil
 		},
 	}
 
 	return &HTTPMonitor{
-		BaseMonitor: NewBaseMonitor(config, group, logger, metrics),
-		client:      client,
+		BaseMonitor:    base,
+		client:         client,
+		transport:      transport,
+		resolver:       resolver,
+		expectedIPs:    expectedIPs,
+		captureHeaders: captureHeaderNames(config.CaptureHeaders),
+		keyPins:        keyPins,
 	}, nil
 }
 
@@ -63,6 +165,11 @@ func (h *HTTPMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 
 	// Create HTTP request
 	req, err := http.NewRequestWithContext(ctx, "GET", h.Config.URL, nil)
+	if err == nil && h.Config.CacheBust {
+		query := req.URL.Query()
+		query.Set(cacheBustParam, strconv.FormatInt(startTime.UnixNano(), 36))
+		req.URL.RawQuery = query.Encode()
+	}
 	if err != nil {
 		duration := time.Since(startTime)
 		result := h.CreateResult(models.StatusDown, duration, err)
@@ -71,6 +178,24 @@ func (h *HTTPMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 		return result, nil
 	}
 
+	// Catch DNS changes or hijacks before sending the request
+	if len(h.expectedIPs) > 0 {
+		if err := h.resolver.VerifyIPs(ctx, req.URL.Hostname(), h.expectedIPs); err != nil {
+			result := h.CreateResult(models.StatusDown, time.Since(startTime), err)
+			h.RecordMetrics(result)
+			h.LogResult(result)
+			return result, nil
+		}
+	}
+
+	if h.Config.NoCache {
+		req.Header.Set("Cache-Control", "no-cache")
+		req.Header.Set("Pragma", "no-cache")
+	}
+	if h.Config.ConditionalRequests {
+		h.setValidators(req)
+	}
+
 	// Add custom headers
 	if h.Config.Headers != nil {
 		for key, value := range h.Config.Headers {
@@ -81,33 +206,62 @@ func (h *HTTPMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 	// Set User-Agent
 	req.Header.Set("User-Agent", "HallMonitor/1.0")
 
+	// Record the connection used and whether it was a kept-alive one
+	var connReused bool
+	var remote string
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			connReused = info.Reused
+			if info.Conn != nil {
+				remote = remoteIP(info.Conn.RemoteAddr())
+			}
+		},
+	}))
+	if trace := traceFrom(ctx); trace != nil {
+		trace.Add(TracePhaseRequest, "%s %s", req.Method, req.URL)
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace.clientTrace()))
+	}
+
 	// Perform the request
 	resp, err := h.client.Do(req)
 	duration := time.Since(startTime)
 
 	if err != nil {
 		result := h.CreateResult(models.StatusDown, duration, err)
+		result.RemoteIP = remote
+		h.DetectTargetChange(result)
 		h.RecordMetrics(result)
 		h.LogResult(result)
 		return result, nil
 	}
-	defer resp.Body.Close()
+	defer func() {
+		// Drain the body so the connection can go back to the pool
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+		resp.Body.Close()
+	}()
+
+	if trace := traceFrom(ctx); trace != nil {
+		trace.Add(TracePhaseResponse, "%s %s", resp.Proto, resp.Status)
+		for _, name := range slices.Sorted(maps.Keys(resp.Header)) {
+			trace.Add(TracePhaseResponse, "%s: %s", name, strings.Join(resp.Header[name], ", "))
+		}
+	}
 
 	// Create HTTP result data
 	httpResult := &models.HTTPResult{
-		StatusCode:   resp.StatusCode,
-		ResponseTime: duration,
-		ResponseSize: resp.ContentLength,
-		Headers:      make(map[string]string),
-	}
-
-	// Capture important response headers
-	for key, values := range resp.Header {
-		if len(values) > 0 {
-			switch strings.ToLower(key) {
-			case "content-type", "server", "cache-control":
-				httpResult.Headers[key] = values[0]
-			}
+		StatusCode:       resp.StatusCode,
+		ResponseTime:     duration,
+		ResponseSize:     resp.ContentLength,
+		Headers:          make(map[string]string),
+		ConnectionReused: connReused,
+		Protocol:         resp.Proto,
+		HTTP3Advertised:  advertisesHTTP3(resp.Header.Values("Alt-Svc")),
+	}
+
+	// Capture the default and configured response headers
+	for _, name := range h.captureHeaders {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			httpResult.Headers[name] = strings.Join(values, ", ")
 		}
 	}
 
@@ -115,6 +269,7 @@ func (h *HTTPMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
 		cert := resp.TLS.PeerCertificates[0]
 		httpResult.SSLCertExpiry = &cert.NotAfter
+		httpResult.CertChain = certificateChain(resp.TLS.PeerCertificates)
 
 		// Record SSL certificate expiry in metrics
 		if h.Metrics != nil {
@@ -153,16 +308,60 @@ func (h *HTTPMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 		expectedStatus = 200 // Default expected status
 	}
 
-	if resp.StatusCode == expectedStatus {
+	pinErr := verifyKeyPins(resp.TLS, h.keyPins)
+
+	switch {
+	case pinErr != nil:
+		status = models.StatusDown
+		checkError = pinErr
+	case h.Config.HTTPVersion == "2" && resp.ProtoMajor != 2:
+		status = models.StatusDown
+		checkError = fmt.Errorf("unexpected protocol: %s (expected HTTP/2)", resp.Proto)
+	case h.Config.ConditionalRequests && resp.StatusCode == http.StatusNotModified:
+		status = models.StatusUp
+	case resp.StatusCode == expectedStatus:
 		status = models.StatusUp
-	} else {
+	default:
 		status = models.StatusDown
 		checkError = fmt.Errorf("unexpected status code: %d (expected %d)", resp.StatusCode, expectedStatus)
 	}
 
+	notModified := resp.StatusCode == http.StatusNotModified
+
+	// Only read healthy responses, so error pages don't count as content
+	// changes; a 304 has no body to read
+	keyword := h.Config.ExpectedResponse
+	if (keyword != "" || h.Config.DetectContentChanges) && status == models.StatusUp && !notModified {
+		content, err := io.ReadAll(io.LimitReader(resp.Body, maxContentBytes))
+		switch {
+		case keyword != "" && err != nil:
+			status = models.StatusDown
+			checkError = fmt.Errorf("failed to read response body: %w", err)
+		case keyword != "" && !bytes.Contains(content, []byte(keyword)):
+			status = models.StatusDown
+			checkError = fmt.Errorf("response does not contain %q", keyword)
+		case !h.Config.DetectContentChanges:
+		case err != nil:
+			h.Logger.WithComponent(logging.ComponentMonitor).
+				WithError(err).
+				WithFields(map[string]interface{}{
+					"monitor": h.Config.Name,
+				}).
+				Warn("Failed to read response body for change detection")
+		default:
+			h.detectContentChange(content, httpResult)
+		}
+	}
+
+	if h.Config.ConditionalRequests && status == models.StatusUp && !notModified {
+		h.recordValidators(resp)
+	}
+
 	// Create monitor result
 	result := h.CreateResult(status, duration, checkError)
 	result.HTTPResult = httpResult
+	result.RemoteIP = remote
+	h.DetectTargetChange(result)
 
 	// Record metrics
 	if h.Metrics != nil {
@@ -174,6 +373,7 @@ func (h *HTTPMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 			method,
 			resp.StatusCode,
 			duration,
+			result.ID(),
 		)
 	}
 
@@ -183,6 +383,111 @@ func (h *HTTPMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 	return result, nil
 }
 
+// setValidators makes the request conditional on the last full response
+func (h *HTTPMonitor) setValidators(req *http.Request) {
+	h.validatorMu.Lock()
+	defer h.validatorMu.Unlock()
+
+	if h.lastModified != "" {
+		req.Header.Set("If-Modified-Since", h.lastModified)
+	}
+	if h.etag != "" {
+		req.Header.Set("If-None-Match", h.etag)
+	}
+}
+
+// recordValidators keeps a full response's Last-Modified and ETag for the
+// next conditional request
+func (h *HTTPMonitor) recordValidators(resp *http.Response) {
+	h.validatorMu.Lock()
+	defer h.validatorMu.Unlock()
+
+	h.lastModified = resp.Header.Get("Last-Modified")
+	h.etag = resp.Header.Get("ETag")
+}
+
+// detectContentChange hashes the response body and compares it with the
+// previous check. The first check only records a baseline.
+func (h *HTTPMonitor) detectContentChange(content []byte, httpResult *models.HTTPResult) {
+	sum := sha256.Sum256(content)
+	httpResult.BodyHash = hex.EncodeToString(sum[:])
+	httpResult.BodySnippet = strings.ToValidUTF8(string(content[:min(len(content), bodySnippetBytes)]), "")
+
+	h.bodyMu.Lock()
+	previous := h.lastBodyHash
+	h.lastBodyHash = httpResult.BodyHash
+	h.bodyMu.Unlock()
+
+	if previous == "" || previous == httpResult.BodyHash {
+		return
+	}
+
+	httpResult.ContentChanged = true
+	httpResult.PreviousBodyHash = previous
+
+	if h.Metrics != nil {
+		h.Metrics.RecordHTTPContentChange(h.Config.Name, h.Group)
+	}
+	if h.Logger != nil {
+		h.Logger.WithComponent(logging.ComponentMonitor).
+			WithEvent(logging.EventContentChanged).
+			WithFields(map[string]interface{}{
+				"monitor":       h.Config.Name,
+				"group":         h.Group,
+				"previous_hash": previous,
+				"hash":          httpResult.BodyHash,
+			}).
+			Info("Response content changed")
+	}
+}
+
+// httpProtocols maps an httpVersion setting to the protocols the transport may use
+func httpProtocols(version string) (*http.Protocols, error) {
+	var protocols http.Protocols
+	switch version {
+	case "", "auto":
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(true)
+	case "1.1":
+		protocols.SetHTTP1(true)
+	case "2":
+		protocols.SetHTTP2(true)
+		protocols.SetUnencryptedHTTP2(true)
+	default:
+		return nil, fmt.Errorf("invalid httpVersion %q (must be auto, 1.1, or 2)", version)
+	}
+	return &protocols, nil
+}
+
+// advertisesHTTP3 reports whether Alt-Svc header values offer HTTP/3, e.g.
+// `h3=":443"; ma=86400`. Draft versions such as h3-29 count as well.
+func advertisesHTTP3(values []string) bool {
+	for _, value := range values {
+		for _, service := range strings.Split(value, ",") {
+			protocol, _, _ := strings.Cut(strings.TrimSpace(service), "=")
+			if protocol == "h3" || strings.HasPrefix(protocol, "h3-") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// captureHeaderNames returns the default and configured header names in
+// canonical form, without duplicates
+func captureHeaderNames(configured []string) []string {
+	names := make([]string, 0, len(defaultCaptureHeaders)+len(configured))
+	seen := make(map[string]bool)
+	for _, name := range append(append([]string{}, defaultCaptureHeaders...), configured...) {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // Validate validates the HTTP monitor configuration
 func (h *HTTPMonitor) Validate() error {
 	if h.Config.URL == "" {
@@ -200,6 +505,14 @@ func (h *HTTPMonitor) Validate() error {
 		return fmt.Errorf("URL must use http or https scheme")
 	}
 
+	if h.Config.MaxConnections < 0 {
+		return fmt.Errorf("maxConnections cannot be negative: %d", h.Config.MaxConnections)
+	}
+
+	if _, err := httpProtocols(h.Config.HTTPVersion); err != nil {
+		return err
+	}
+
 	// Validate expected status code if provided
 	if h.Config.ExpectedStatus != 0 {
 		if h.Config.ExpectedStatus < 100 || h.Config.ExpectedStatus > 599 {
@@ -209,3 +522,9 @@ func (h *HTTPMonitor) Validate() error {
 
 	return nil
 }
+
+// Close releases the monitor's idle pooled connections
+func (h *HTTPMonitor) Close() error {
+	h.transport.CloseIdleConnections()
+	return nil
+}
@@ -2,8 +2,11 @@ package monitors
 
 import (
 	"context"
+	"crypto/x509"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -143,6 +146,10 @@ func TestHTTPMonitorCheckSuccess(t *testing.T) {
 		t.Fatalf("expected status code 200, got %d", result.HTTPResult.StatusCode)
 	}
 
+	if result.RemoteIP != "127.0.0.1" {
+		t.Fatalf("expected remote IP 127.0.0.1, got %q", result.RemoteIP)
+	}
+
 	if result.Duration == 0 {
 		t.Fatalf("expected non-zero duration")
 	}
@@ -257,3 +264,427 @@ func TestHTTPMonitorCheckCustomHeaders(t *testing.T) {
 		t.Fatalf("expected authorization header to be sent, got %s", receivedHeaders["Authorization"])
 	}
 }
+
+func TestHTTPMonitorCaptureHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "edge")
+		w.Header().Set("X-Cache", "HIT")
+		w.Header().Add("Via", "1.1 a")
+		w.Header().Add("Via", "1.1 b")
+		w.Header().Set("X-Internal", "secret")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := &models.Monitor{
+		Type:           models.MonitorTypeHTTP,
+		Name:           "cdn",
+		URL:            server.URL,
+		Timeout:        models.Duration(5 * time.Second),
+		CaptureHeaders: []string{"x-cache", "Via", "CF-Ray"},
+	}
+
+	monitor, err := NewHTTPMonitor(config, "test-group", nil, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPMonitor failed: %v", err)
+	}
+
+	result, err := monitor.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	want := map[string]string{
+		"Server":  "edge",
+		"X-Cache": "HIT",
+		"Via":     "1.1 a, 1.1 b",
+	}
+	headers := result.HTTPResult.Headers
+	for name, value := range want {
+		if headers[name] != value {
+			t.Errorf("expected header %s %q, got %q", name, value, headers[name])
+		}
+	}
+	if _, ok := headers["X-Internal"]; ok {
+		t.Errorf("expected headers outside the allowlist to be dropped")
+	}
+	if _, ok := headers["Cf-Ray"]; ok {
+		t.Errorf("expected absent headers to be omitted")
+	}
+}
+
+func TestHTTPMonitorCertChain(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := &models.Monitor{
+		Type:    models.MonitorTypeHTTP,
+		Name:    "tls",
+		URL:     server.URL,
+		Timeout: models.Duration(5 * time.Second),
+	}
+
+	monitor, err := NewHTTPMonitor(config, "test-group", nil, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPMonitor failed: %v", err)
+	}
+	// Trust the test server's self-signed certificate
+	monitor.transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+	result, err := monitor.Check(context.Background())
+	if err != nil {
+		t.Fatalf("Check failed: %v", err)
+	}
+
+	chain := result.HTTPResult.CertChain
+	if len(chain) == 0 {
+		t.Fatalf("expected a certificate chain, got none")
+	}
+	leaf := server.Certificate()
+	if chain[0].NotAfter != leaf.NotAfter || chain[0].SignatureAlgorithm != leaf.SignatureAlgorithm.String() {
+		t.Errorf("unexpected leaf certificate %+v", chain[0])
+	}
+	if len(chain[0].DNSNames) == 0 || len(chain[0].IPAddresses) == 0 || len(chain[0].Fingerprint) != 64 {
+		t.Errorf("expected SANs and a SHA-256 fingerprint, got %+v", chain[0])
+	}
+}
+
+func TestHTTPMonitorPinnedKeys(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	served := models.FormatKeyPin(server.Certificate().RawSubjectPublicKeyInfo)
+	other := models.FormatKeyPin([]byte("another key"))
+
+	tests := []struct {
+		name       string
+		pins       []string
+		wantStatus models.MonitorStatus
+	}{
+		{name: "not pinned", wantStatus: models.StatusUp},
+		{name: "served key pinned", pins: []string{other, served}, wantStatus: models.StatusUp},
+		{name: "served key not pinned", pins: []string{other}, wantStatus: models.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &models.Monitor{
+				Type:       models.MonitorTypeHTTP,
+				Name:       "pinned",
+				URL:        server.URL,
+				Timeout:    models.Duration(5 * time.Second),
+				PinnedKeys: tt.pins,
+			}
+
+			monitor, err := NewHTTPMonitor(config, "test-group", nil, nil)
+			if err != nil {
+				t.Fatalf("NewHTTPMonitor failed: %v", err)
+			}
+			monitor.transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig
+
+			result, err := monitor.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check failed: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Fatalf("expected status %s, got %s (%s)", tt.wantStatus, result.Status, result.Error)
+			}
+			if tt.wantStatus == models.StatusDown && !strings.Contains(result.Error, served) {
+				t.Errorf("expected the error to name the served key, got %q", result.Error)
+			}
+			if got := result.HTTPResult.CertChain[0].KeyPin; got != served {
+				t.Errorf("expected key_pin %s, got %s", served, got)
+			}
+		})
+	}
+}
+
+func TestHTTPMonitorCacheOptions(t *testing.T) {
+	var queries, cacheControls, conditions []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get(cacheBustParam))
+		cacheControls = append(cacheControls, r.Header.Get("Cache-Control"))
+		conditions = append(conditions, r.Header.Get("If-None-Match")+"|"+r.Header.Get("If-Modified-Since"))
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", "Mon, 12 Oct 2026 08:00:00 GMT")
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	config := &models.Monitor{
+		Type:                 models.MonitorTypeHTTP,
+		Name:                 "cached",
+		URL:                  server.URL + "/health?probe=1",
+		Timeout:              models.Duration(5 * time.Second),
+		CacheBust:            true,
+		NoCache:              true,
+		ConditionalRequests:  true,
+		DetectContentChanges: true,
+	}
+
+	monitor, err := NewHTTPMonitor(config, "test-group", nil, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPMonitor failed: %v", err)
+	}
+
+	var results []*models.MonitorResult
+	for i := 0; i < 2; i++ {
+		result, err := monitor.Check(context.Background())
+		if err != nil {
+			t.Fatalf("Check failed: %v", err)
+		}
+		results = append(results, result)
+	}
+
+	if results[1].Status != models.StatusUp || results[1].HTTPResult.StatusCode != http.StatusNotModified {
+		t.Fatalf("expected the revalidated check to be up with 304, got %s %d", results[1].Status, results[1].HTTPResult.StatusCode)
+	}
+	if results[1].HTTPResult.ContentChanged {
+		t.Errorf("expected a 304 not to count as a content change")
+	}
+	if queries[0] == "" || queries[0] == queries[1] {
+		t.Errorf("expected a unique cache-busting parameter per request, got %q", queries)
+	}
+	if cacheControls[0] != "no-cache" {
+		t.Errorf("expected Cache-Control: no-cache, got %q", cacheControls[0])
+	}
+	if conditions[0] != "|" || conditions[1] != `"v1"|Mon, 12 Oct 2026 08:00:00 GMT` {
+		t.Errorf("expected validators only on the second request, got %q", conditions)
+	}
+}
+
+func TestHTTPMonitorConnectionReuse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 1024)))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		disable    bool
+		wantReused bool
+	}{
+		{name: "reused by default", wantReused: true},
+		{name: "reuse disabled", disable: true, wantReused: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, err := NewHTTPMonitor(&models.Monitor{
+				Type:                   models.MonitorTypeHTTP,
+				Name:                   "pooled",
+				URL:                    server.URL,
+				DisableConnectionReuse: tt.disable,
+			}, "test-group", nil, nil)
+			if err != nil {
+				t.Fatalf("NewHTTPMonitor failed: %v", err)
+			}
+			defer monitor.Close()
+
+			first, _ := monitor.Check(context.Background())
+			second, _ := monitor.Check(context.Background())
+			if first.HTTPResult.ConnectionReused {
+				t.Error("expected first check to open a new connection")
+			}
+			if second.HTTPResult.ConnectionReused != tt.wantReused {
+				t.Errorf("expected second check reused=%v, got %v", tt.wantReused, second.HTTPResult.ConnectionReused)
+			}
+		})
+	}
+}
+
+func TestHTTPMonitorDetectContentChanges(t *testing.T) {
+	bodies := []string{"version 1", "version 1", "version 2", "error page"}
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := atomic.AddInt32(&requests, 1) - 1
+		if i == 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte(bodies[i]))
+	}))
+	defer server.Close()
+
+	monitor, err := NewHTTPMonitor(&models.Monitor{
+		Type:                 models.MonitorTypeHTTP,
+		Name:                 "watched",
+		URL:                  server.URL,
+		DetectContentChanges: true,
+	}, "test-group", nil, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPMonitor failed: %v", err)
+	}
+	defer monitor.Close()
+
+	tests := []struct {
+		name        string
+		wantChanged bool
+		wantSnippet string
+	}{
+		{name: "baseline", wantChanged: false, wantSnippet: "version 1"},
+		{name: "unchanged", wantChanged: false, wantSnippet: "version 1"},
+		{name: "changed", wantChanged: true, wantSnippet: "version 2"},
+		{name: "error response ignored", wantChanged: false, wantSnippet: ""},
+	}
+
+	var previousHash string
+	for _, tt := range tests {
+		result, _ := monitor.Check(context.Background())
+		httpResult := result.HTTPResult
+		if httpResult.ContentChanged != tt.wantChanged {
+			t.Errorf("%s: expected changed=%v, got %v", tt.name, tt.wantChanged, httpResult.ContentChanged)
+		}
+		if httpResult.BodySnippet != tt.wantSnippet {
+			t.Errorf("%s: expected snippet %q, got %q", tt.name, tt.wantSnippet, httpResult.BodySnippet)
+		}
+		if tt.wantChanged && httpResult.PreviousBodyHash != previousHash {
+			t.Errorf("%s: expected previous hash %s, got %s", tt.name, previousHash, httpResult.PreviousBodyHash)
+		}
+		if httpResult.BodyHash != "" {
+			previousHash = httpResult.BodyHash
+		}
+	}
+}
+
+func TestHTTPMonitorExpectedResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok","db":"connected"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		expected   string
+		wantStatus models.MonitorStatus
+	}{
+		{name: "contains keyword", expected: `"db":"connected"`, wantStatus: models.StatusUp},
+		{name: "missing keyword", expected: "degraded", wantStatus: models.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, err := NewHTTPMonitor(&models.Monitor{
+				Type:             models.MonitorTypeHTTP,
+				Name:             "keyword",
+				URL:              server.URL,
+				ExpectedResponse: tt.expected,
+			}, "test-group", nil, nil)
+			if err != nil {
+				t.Fatalf("NewHTTPMonitor failed: %v", err)
+			}
+			defer monitor.Close()
+
+			result, _ := monitor.Check(context.Background())
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s (error %q)", tt.wantStatus, result.Status, result.Error)
+			}
+		})
+	}
+}
+
+func TestHTTPMonitorHTTPVersion(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Alt-Svc", `h3=":443"; ma=86400`)
+		w.Write([]byte(r.Proto))
+	})
+
+	http1Server := httptest.NewTLSServer(handler)
+	defer http1Server.Close()
+
+	http2Server := httptest.NewUnstartedServer(handler)
+	http2Server.EnableHTTP2 = true
+	http2Server.StartTLS()
+	defer http2Server.Close()
+
+	tests := []struct {
+		name         string
+		server       *httptest.Server
+		version      string
+		wantStatus   models.MonitorStatus
+		wantProtocol string
+	}{
+		{name: "auto negotiates http2", server: http2Server, version: "", wantStatus: models.StatusUp, wantProtocol: "HTTP/2.0"},
+		{name: "auto falls back to http1", server: http1Server, version: "auto", wantStatus: models.StatusUp, wantProtocol: "HTTP/1.1"},
+		{name: "http1 forced", server: http2Server, version: "1.1", wantStatus: models.StatusUp, wantProtocol: "HTTP/1.1"},
+		{name: "http2 forced", server: http2Server, version: "2", wantStatus: models.StatusUp, wantProtocol: "HTTP/2.0"},
+		{name: "http2 forced without server support", server: http1Server, version: "2", wantStatus: models.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, err := NewHTTPMonitor(&models.Monitor{
+				Type:        models.MonitorTypeHTTP,
+				Name:        "protocol",
+				URL:         tt.server.URL,
+				HTTPVersion: tt.version,
+			}, "test-group", nil, nil)
+			if err != nil {
+				t.Fatalf("NewHTTPMonitor failed: %v", err)
+			}
+			defer monitor.Close()
+
+			roots := x509.NewCertPool()
+			roots.AddCert(tt.server.Certificate())
+			monitor.transport.TLSClientConfig.RootCAs = roots
+
+			result, _ := monitor.Check(context.Background())
+			if result.Status != tt.wantStatus {
+				t.Fatalf("expected status %s, got %s (error: %s)", tt.wantStatus, result.Status, result.Error)
+			}
+			if tt.wantProtocol == "" {
+				return
+			}
+			if result.HTTPResult.Protocol != tt.wantProtocol {
+				t.Errorf("expected protocol %s, got %s", tt.wantProtocol, result.HTTPResult.Protocol)
+			}
+			if !result.HTTPResult.HTTP3Advertised {
+				t.Error("expected HTTP/3 to be advertised via Alt-Svc")
+			}
+		})
+	}
+}
+
+func TestHTTPMonitorHTTPVersionInvalid(t *testing.T) {
+	for _, version := range []string{"3", "1.0"} {
+		_, err := NewHTTPMonitor(&models.Monitor{
+			Type:        models.MonitorTypeHTTP,
+			Name:        "protocol",
+			URL:         "https://example.com",
+			HTTPVersion: version,
+		}, "test-group", nil, nil)
+		if err == nil {
+			t.Errorf("expected error for httpVersion %q", version)
+		}
+	}
+}
+
+func TestAdvertisesHTTP3(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   bool
+	}{
+		{name: "none", values: nil, want: false},
+		{name: "h3", values: []string{`h3=":443"; ma=86400`}, want: true},
+		{name: "draft in list", values: []string{`h2=":443", h3-29=":443"`}, want: true},
+		{name: "h2 only", values: []string{`h2="alt.example.com:443"`}, want: false},
+		{name: "clear", values: []string{"clear"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := advertisesHTTP3(tt.values); got != tt.want {
+				t.Errorf("advertisesHTTP3(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
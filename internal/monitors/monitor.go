@@ -4,7 +4,15 @@ package monitors
 
 import (
 	"context"
+	"fmt"
+	"io"
+	"net"
+	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/1broseidon/hallmonitor/internal/logging"
@@ -42,6 +50,10 @@ type BaseMonitor struct {
 	Group   string
 	Logger  *logging.Logger
 	Metrics *metrics.Metrics
+
+	egress atomic.Pointer[EgressGuard]
+	source net.IP
+	target targetState
 }
 
 // NewBaseMonitor creates a new base monitor
@@ -82,6 +94,21 @@ func (b *BaseMonitor) IsEnabled() bool {
 	return *b.Config.Enabled
 }
 
+// SetEgressGuard sets the egress policy enforced on the monitor's connections
+func (b *BaseMonitor) SetEgressGuard(guard *EgressGuard) {
+	b.egress.Store(guard)
+}
+
+// EgressGuard returns the egress policy enforced on the monitor's connections
+func (b *BaseMonitor) EgressGuard() *EgressGuard {
+	return b.egress.Load()
+}
+
+// dialControl is a net.Dialer Control hook enforcing the current egress guard
+func (b *BaseMonitor) dialControl(network, address string, c syscall.RawConn) error {
+	return b.EgressGuard().Control(network, address, c)
+}
+
 // CreateResult creates a monitor result with common fields populated
 func (b *BaseMonitor) CreateResult(status models.MonitorStatus, duration time.Duration, err error) *models.MonitorResult {
 	result := &models.MonitorResult{
@@ -98,6 +125,12 @@ func (b *BaseMonitor) CreateResult(status models.MonitorStatus, duration time.Du
 		result.Status = models.StatusDown
 	}
 
+	// Treat successful but slow checks as down
+	if maxDuration := b.Config.MaxDuration.ToDuration(); maxDuration > 0 && result.Status == models.StatusUp && duration > maxDuration {
+		result.Status = models.StatusDown
+		result.Error = fmt.Sprintf("check took %s, exceeding maxDuration %s", duration.Round(time.Millisecond), maxDuration)
+	}
+
 	return result
 }
 
@@ -119,6 +152,7 @@ func (b *BaseMonitor) RecordMetrics(result *models.MonitorResult) {
 		result.Group,
 		status,
 		result.Duration,
+		result.ID(),
 	)
 
 	// Set monitor status
@@ -195,6 +229,22 @@ func (e *CheckError) Error() string {
 type MonitorFactory struct {
 	logger  *logging.Logger
 	metrics *metrics.Metrics
+	egress  atomic.Pointer[models.EgressPolicy]
+	network atomic.Pointer[NetworkDefaults]
+	plugins atomic.Pointer[string]
+}
+
+// NetworkDefaults holds global network settings applied to monitors that do
+// not configure their own
+type NetworkDefaults struct {
+	Resolver        *models.ResolverConfig
+	SourceIP        string
+	SourceInterface string
+}
+
+// egressTarget is implemented by monitors that enforce an egress guard
+type egressTarget interface {
+	SetEgressGuard(guard *EgressGuard)
 }
 
 // NewMonitorFactory creates a new monitor factory
@@ -205,8 +255,86 @@ func NewMonitorFactory(logger *logging.Logger, metrics *metrics.Metrics) *Monito
 	}
 }
 
+// SetEgressPolicy sets the global egress policy applied to monitors it creates
+func (f *MonitorFactory) SetEgressPolicy(policy *models.EgressPolicy) error {
+	if _, err := NewEgressGuard(policy); err != nil {
+		return err
+	}
+	f.egress.Store(policy)
+	return nil
+}
+
+// SetNetworkDefaults sets the network settings used by monitors that do not configure their own
+func (f *MonitorFactory) SetNetworkDefaults(defaults NetworkDefaults) {
+	f.network.Store(&defaults)
+}
+
+// SetPluginDir sets the directory plugin monitors run executables from;
+// empty refuses plugin monitors
+func (f *MonitorFactory) SetPluginDir(dir string) {
+	f.plugins.Store(&dir)
+}
+
+// pluginDir returns the directory set with SetPluginDir
+func (f *MonitorFactory) pluginDir() string {
+	if dir := f.plugins.Load(); dir != nil {
+		return *dir
+	}
+	return ""
+}
+
 // CreateMonitor creates a monitor instance based on the configuration
 func (f *MonitorFactory) CreateMonitor(config *models.Monitor, group string) (Monitor, error) {
+	config = f.withNetworkDefaults(config)
+
+	monitor, err := f.createMonitor(config, group)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.applyEgress(monitor); err != nil {
+		return nil, err
+	}
+	return monitor, nil
+}
+
+// withNetworkDefaults returns config with unset network settings filled from
+// the global defaults, copying it only when something changes. Filling them in
+// keeps the defaults visible to reload diffing.
+func (f *MonitorFactory) withNetworkDefaults(config *models.Monitor) *models.Monitor {
+	defaults := f.network.Load()
+	if defaults == nil {
+		return config
+	}
+
+	merged := *config
+	if merged.Resolver == nil && !defaults.Resolver.IsZero() {
+		merged.Resolver = defaults.Resolver
+	}
+	if merged.SourceIP == "" && merged.SourceInterface == "" {
+		merged.SourceIP = defaults.SourceIP
+		merged.SourceInterface = defaults.SourceInterface
+	}
+	if reflect.DeepEqual(&merged, config) {
+		return config
+	}
+	return &merged
+}
+
+// applyEgress combines the global and per-monitor egress policies on monitor
+func (f *MonitorFactory) applyEgress(monitor Monitor) error {
+	target, ok := monitor.(egressTarget)
+	if !ok {
+		return nil
+	}
+	guard, err := NewEgressGuard(f.egress.Load(), monitor.GetConfig().Egress)
+	if err != nil {
+		return err
+	}
+	target.SetEgressGuard(guard)
+	return nil
+}
+
+func (f *MonitorFactory) createMonitor(config *models.Monitor, group string) (Monitor, error) {
 	switch config.Type {
 	case models.MonitorTypePing:
 		return NewPingMonitor(config, group, f.logger, f.metrics)
@@ -216,8 +344,24 @@ func (f *MonitorFactory) CreateMonitor(config *models.Monitor, group string) (Mo
 		return NewTCPMonitor(config, group, f.logger, f.metrics)
 	case models.MonitorTypeDNS:
 		return NewDNSMonitor(config, group, f.logger, f.metrics)
+	case models.MonitorTypeRBL:
+		return NewRBLMonitor(config, group, f.logger, f.metrics)
+	case models.MonitorTypeDomain:
+		return NewDomainMonitor(config, group, f.logger, f.metrics)
+	case models.MonitorTypePlugin:
+		return NewPluginMonitor(config, group, f.pluginDir(), f.logger, f.metrics)
+	case models.MonitorTypeWasm:
+		return NewWasmMonitor(config, group, f.logger, f.metrics)
 	default:
-		return nil, &UnsupportedMonitorTypeError{Type: config.Type}
+		factory, ok := customFactory(config.Type)
+		if !ok {
+			return nil, &UnsupportedMonitorTypeError{Type: config.Type}
+		}
+		check, err := factory(config)
+		if err != nil {
+			return nil, err
+		}
+		return NewCustomMonitor(config, group, check, f.logger, f.metrics), nil
 	}
 }
 
@@ -236,6 +380,20 @@ type MonitorManager struct {
 	factory  *MonitorFactory
 	logger   *logging.Logger
 	metrics  *metrics.Metrics
+	mu       sync.RWMutex
+}
+
+// ReloadDiff describes how a reload changed the set of loaded monitors
+type ReloadDiff struct {
+	Added     []string `json:"added"`
+	Removed   []string `json:"removed"`
+	Changed   []string `json:"changed"`
+	Unchanged int      `json:"unchanged"`
+}
+
+// HasChanges reports whether the reload added, removed, or changed any monitor
+func (d *ReloadDiff) HasChanges() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
 }
 
 // NewMonitorManager creates a new monitor manager
@@ -248,15 +406,56 @@ func NewMonitorManager(logger *logging.Logger, metrics *metrics.Metrics) *Monito
 	}
 }
 
+// SetEgressPolicy sets the global egress policy enforced on every monitor.
+// It takes effect for monitors created or reloaded afterwards.
+func (m *MonitorManager) SetEgressPolicy(policy *models.EgressPolicy) error {
+	return m.factory.SetEgressPolicy(policy)
+}
+
+// SetNetworkDefaults sets the global resolver and source address for monitors
+// without their own. It takes effect for monitors created or reloaded afterwards.
+func (m *MonitorManager) SetNetworkDefaults(defaults NetworkDefaults) {
+	m.factory.SetNetworkDefaults(defaults)
+}
+
+// SetPluginDir sets the directory plugin monitors run executables from. It
+// takes effect for monitors created or reloaded afterwards.
+func (m *MonitorManager) SetPluginDir(dir string) {
+	m.factory.SetPluginDir(dir)
+}
+
 // LoadMonitors loads monitors from configuration
 func (m *MonitorManager) LoadMonitors(groups []models.MonitorGroup) error {
+	newMonitors := m.buildMonitors(groups)
+
+	// Replace current monitors
+	m.mu.Lock()
+	m.monitors = newMonitors
+	m.mu.Unlock()
+
+	// Update metrics
+	m.updateMonitorCountMetrics()
+
+	m.logger.WithComponent(logging.ComponentMonitor).
+		WithFields(map[string]interface{}{
+			"total_monitors": len(newMonitors),
+		}).
+		Info("Monitors loaded successfully")
+
+	return nil
+}
+
+// buildMonitors creates and validates monitors for every entry in groups.
+// Disabled monitors are kept so they can be listed and re-enabled; the
+// scheduler skips them. Every monitor in a disabled group is disabled.
+func (m *MonitorManager) buildMonitors(groups []models.MonitorGroup) []Monitor {
 	var newMonitors []Monitor
 
 	for _, group := range groups {
 		for _, monitorConfig := range group.Monitors {
-			// Skip disabled monitors
-			if monitorConfig.Enabled != nil && !*monitorConfig.Enabled {
-				continue
+			if !group.IsEnabled() {
+				disabled := false
+				monitorConfig.Enabled = &disabled
 			}
 
 			monitor, err := m.factory.CreateMonitor(&monitorConfig, group.Name)
@@ -289,29 +488,19 @@ func (m *MonitorManager) LoadMonitors(groups []models.MonitorGroup) error {
 		}
 	}
 
-	// Replace current monitors
-	m.monitors = newMonitors
-
-	// Update metrics
-	m.updateMonitorCountMetrics()
-
-	m.logger.WithComponent(logging.ComponentMonitor).
-		WithFields(map[string]interface{}{
-			"total_monitors": len(m.monitors),
-		}).
-		Info("Monitors loaded successfully")
-
-	return nil
+	return newMonitors
 }
 
 // GetMonitors returns all loaded monitors
 func (m *MonitorManager) GetMonitors() []Monitor {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.monitors
 }
 
 // GetMonitorByName returns a monitor by name
 func (m *MonitorManager) GetMonitorByName(name string) Monitor {
-	for _, monitor := range m.monitors {
+	for _, monitor := range m.GetMonitors() {
 		if monitor.GetName() == name {
 			return monitor
 		}
@@ -319,10 +508,33 @@ func (m *MonitorManager) GetMonitorByName(name string) Monitor {
 	return nil
 }
 
+// NewDetachedMonitor creates a fresh instance of a loaded monitor that records
+// no metrics and shares no state with it, such as kept-alive connections or
+// change detection baselines. It returns nil if there is no such monitor.
+func (m *MonitorManager) NewDetachedMonitor(name string) (Monitor, error) {
+	monitor := m.GetMonitorByName(name)
+	if monitor == nil {
+		return nil, nil
+	}
+
+	return m.NewDetachedMonitorFromSpec(monitor.GetConfig(), monitor.GetGroup())
+}
+
+// NewDetachedMonitorFromSpec creates a monitor from a definition that need not
+// be loaded, with the manager's egress, network, and plugin settings. Like
+// NewDetachedMonitor, it records no metrics.
+func (m *MonitorManager) NewDetachedMonitorFromSpec(spec *models.Monitor, group string) (Monitor, error) {
+	factory := NewMonitorFactory(m.logger, nil)
+	factory.egress.Store(m.factory.egress.Load())
+	factory.network.Store(m.factory.network.Load())
+	factory.plugins.Store(m.factory.plugins.Load())
+	return factory.CreateMonitor(spec, group)
+}
+
 // GetMonitorsByGroup returns monitors in a specific group
 func (m *MonitorManager) GetMonitorsByGroup(group string) []Monitor {
 	var groupMonitors []Monitor
-	for _, monitor := range m.monitors {
+	for _, monitor := range m.GetMonitors() {
 		if monitor.GetGroup() == group {
 			groupMonitors = append(groupMonitors, monitor)
 		}
@@ -333,7 +545,7 @@ func (m *MonitorManager) GetMonitorsByGroup(group string) []Monitor {
 // GetGroups returns all unique group names
 func (m *MonitorManager) GetGroups() []string {
 	groups := make(map[string]bool)
-	for _, monitor := range m.monitors {
+	for _, monitor := range m.GetMonitors() {
 		groups[monitor.GetGroup()] = true
 	}
 
@@ -344,10 +556,77 @@ func (m *MonitorManager) GetGroups() []string {
 	return groupList
 }
 
-// Reload replaces all monitors with new ones from the provided groups
-func (m *MonitorManager) Reload(groups []models.MonitorGroup) error {
+// Reload replaces monitors with new ones from the provided groups and reports
+// what changed. Monitors whose spec and group are unchanged keep their existing
+// instance so any state they hold survives the reload.
+func (m *MonitorManager) Reload(groups []models.MonitorGroup) (*ReloadDiff, error) {
 	m.logger.WithComponent(logging.ComponentMonitor).Info("Reloading monitors")
-	return m.LoadMonitors(groups)
+
+	built := m.buildMonitors(groups)
+
+	m.mu.Lock()
+	previous := make(map[string]Monitor, len(m.monitors))
+	for _, monitor := range m.monitors {
+		previous[monitor.GetName()] = monitor
+	}
+
+	diff := &ReloadDiff{Added: []string{}, Removed: []string{}, Changed: []string{}}
+	var replaced []Monitor
+	newMonitors := make([]Monitor, 0, len(built))
+	for _, monitor := range built {
+		name := monitor.GetName()
+		old, exists := previous[name]
+		delete(previous, name)
+
+		switch {
+		case !exists:
+			diff.Added = append(diff.Added, name)
+		case sameSpec(old, monitor):
+			diff.Unchanged++
+			// Keep the old instance but pick up any global egress change; the
+			// policy already compiled for the new instance, so it cannot fail
+			_ = m.factory.applyEgress(old)
+			monitor = old
+		default:
+			diff.Changed = append(diff.Changed, name)
+			replaced = append(replaced, old)
+		}
+		newMonitors = append(newMonitors, monitor)
+	}
+	for name, old := range previous {
+		diff.Removed = append(diff.Removed, name)
+		replaced = append(replaced, old)
+	}
+	sort.Strings(diff.Removed)
+
+	m.monitors = newMonitors
+	m.mu.Unlock()
+
+	// Release pooled connections held by instances that were dropped
+	for _, monitor := range replaced {
+		if closer, ok := monitor.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+
+	m.updateMonitorCountMetrics()
+
+	m.logger.WithComponent(logging.ComponentMonitor).
+		WithFields(map[string]interface{}{
+			"total_monitors": len(newMonitors),
+			"added":          diff.Added,
+			"removed":        diff.Removed,
+			"changed":        diff.Changed,
+			"unchanged":      diff.Unchanged,
+		}).
+		Info("Monitors reloaded")
+
+	return diff, nil
+}
+
+// sameSpec reports whether two monitors were built from identical configuration
+func sameSpec(a, b Monitor) bool {
+	return a.GetGroup() == b.GetGroup() && reflect.DeepEqual(a.GetConfig(), b.GetConfig())
 }
 
 // updateMonitorCountMetrics updates Prometheus metrics for monitor counts
@@ -359,7 +638,7 @@ func (m *MonitorManager) updateMonitorCountMetrics() {
 	monitorCounts := make(map[string]int)
 	enabledCounts := make(map[string]int)
 
-	for _, monitor := range m.monitors {
+	for _, monitor := range m.GetMonitors() {
 		monitorType := string(monitor.GetType())
 		monitorCounts[monitorType]++
 		if monitor.IsEnabled() {
@@ -1,6 +1,8 @@
 package monitors
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -91,7 +93,7 @@ func TestMonitorManagerLoadMonitors(t *testing.T) {
 	}
 }
 
-func TestMonitorManagerLoadMonitorsSkipsDisabled(t *testing.T) {
+func TestMonitorManagerLoadMonitorsKeepsDisabled(t *testing.T) {
 	manager := setupTestManager(t)
 
 	groups := []models.MonitorGroup{
@@ -123,13 +125,17 @@ func TestMonitorManagerLoadMonitorsSkipsDisabled(t *testing.T) {
 		t.Fatalf("LoadMonitors failed: %v", err)
 	}
 
+	// Disabled monitors stay loaded so they can be listed and re-enabled
 	monitors := manager.GetMonitors()
-	if len(monitors) != 1 {
-		t.Errorf("expected 1 monitor (disabled should be skipped), got %d", len(monitors))
+	if len(monitors) != 2 {
+		t.Fatalf("expected 2 monitors, got %d", len(monitors))
 	}
 
-	if monitors[0].GetName() != "enabled-monitor" {
-		t.Errorf("expected enabled-monitor, got %s", monitors[0].GetName())
+	if !manager.GetMonitorByName("enabled-monitor").IsEnabled() {
+		t.Error("expected enabled-monitor to be enabled")
+	}
+	if manager.GetMonitorByName("disabled-monitor").IsEnabled() {
+		t.Error("expected disabled-monitor to be disabled")
 	}
 }
 
@@ -466,3 +472,170 @@ func TestBaseMonitorGetters(t *testing.T) {
 		t.Error("expected GetConfig to return the same config pointer")
 	}
 }
+
+func TestBaseMonitorCreateResultMaxDuration(t *testing.T) {
+	base := NewBaseMonitor(&models.Monitor{
+		Name:        "slow",
+		Type:        models.MonitorTypeTCP,
+		MaxDuration: models.Duration(100 * time.Millisecond),
+	}, "test-group", nil, nil)
+
+	tests := []struct {
+		name       string
+		status     models.MonitorStatus
+		duration   time.Duration
+		wantStatus models.MonitorStatus
+		wantError  string
+	}{
+		{name: "fast enough", status: models.StatusUp, duration: 50 * time.Millisecond, wantStatus: models.StatusUp},
+		{name: "too slow", status: models.StatusUp, duration: 250 * time.Millisecond, wantStatus: models.StatusDown, wantError: "exceeding maxDuration 100ms"},
+		{name: "already down", status: models.StatusDown, duration: 250 * time.Millisecond, wantStatus: models.StatusDown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := base.CreateResult(tt.status, tt.duration, nil)
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, result.Status)
+			}
+			if !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("expected error containing %q, got %q", tt.wantError, result.Error)
+			}
+		})
+	}
+}
+
+func TestMonitorManagerReloadDiff(t *testing.T) {
+	manager := setupTestManager(t)
+
+	httpMonitor := func(name, url string) models.Monitor {
+		return models.Monitor{
+			Name:     name,
+			Type:     "http",
+			Interval: models.Duration(30 * time.Second),
+			Timeout:  models.Duration(5 * time.Second),
+			URL:      url,
+		}
+	}
+
+	initial := []models.MonitorGroup{{
+		Name: "web",
+		Monitors: []models.Monitor{
+			httpMonitor("kept", "https://kept.example.com"),
+			httpMonitor("edited", "https://old.example.com"),
+			httpMonitor("dropped", "https://dropped.example.com"),
+		},
+	}}
+	if err := manager.LoadMonitors(initial); err != nil {
+		t.Fatalf("LoadMonitors failed: %v", err)
+	}
+	kept := manager.GetMonitorByName("kept")
+
+	updated := []models.MonitorGroup{{
+		Name: "web",
+		Monitors: []models.Monitor{
+			httpMonitor("kept", "https://kept.example.com"),
+			httpMonitor("edited", "https://new.example.com"),
+			httpMonitor("fresh", "https://fresh.example.com"),
+		},
+	}}
+	diff, err := manager.Reload(updated)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(diff.Added, []string{"fresh"}) {
+		t.Errorf("expected added [fresh], got %v", diff.Added)
+	}
+	if !reflect.DeepEqual(diff.Removed, []string{"dropped"}) {
+		t.Errorf("expected removed [dropped], got %v", diff.Removed)
+	}
+	if !reflect.DeepEqual(diff.Changed, []string{"edited"}) {
+		t.Errorf("expected changed [edited], got %v", diff.Changed)
+	}
+	if diff.Unchanged != 1 {
+		t.Errorf("expected 1 unchanged monitor, got %d", diff.Unchanged)
+	}
+
+	if manager.GetMonitorByName("kept") != kept {
+		t.Error("expected unchanged monitor to keep its instance")
+	}
+	if got := manager.GetMonitorByName("edited").GetConfig().URL; got != "https://new.example.com" {
+		t.Errorf("expected changed monitor to use new URL, got %s", got)
+	}
+	if manager.GetMonitorByName("dropped") != nil {
+		t.Error("expected removed monitor to be gone")
+	}
+
+	// Moving a monitor to another group counts as a change
+	moved := []models.MonitorGroup{{
+		Name: "api",
+		Monitors: []models.Monitor{
+			httpMonitor("kept", "https://kept.example.com"),
+		},
+	}}
+	diff, err = manager.Reload(moved)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if !reflect.DeepEqual(diff.Changed, []string{"kept"}) {
+		t.Errorf("expected group move to be a change, got %v", diff.Changed)
+	}
+	if !diff.HasChanges() {
+		t.Error("expected HasChanges to be true")
+	}
+
+	diff, err = manager.Reload(moved)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if diff.HasChanges() {
+		t.Errorf("expected no changes for identical reload, got %+v", diff)
+	}
+}
+
+func TestMonitorManagerDisabledGroup(t *testing.T) {
+	manager := setupTestManager(t)
+
+	groups := []models.MonitorGroup{
+		{
+			Name:    "maintenance",
+			Enabled: ptr(false),
+			Monitors: []models.Monitor{
+				{Name: "web", Type: "http", Enabled: ptr(true), URL: "https://example.com"},
+			},
+		},
+		{
+			Name: "live",
+			Monitors: []models.Monitor{
+				{Name: "api", Type: "http", URL: "https://api.example.com"},
+			},
+		},
+	}
+
+	if err := manager.LoadMonitors(groups); err != nil {
+		t.Fatalf("LoadMonitors failed: %v", err)
+	}
+	if manager.GetMonitorByName("web").IsEnabled() {
+		t.Error("expected a monitor in a disabled group to be disabled")
+	}
+	if !manager.GetMonitorByName("api").IsEnabled() {
+		t.Error("expected a monitor in an enabled group to be enabled")
+	}
+	if groups[0].Monitors[0].Enabled == nil || !*groups[0].Monitors[0].Enabled {
+		t.Error("expected the monitor's own configuration to be left alone")
+	}
+
+	// Re-enabling the group reports its monitors as changed so they are rescheduled
+	groups[0].Enabled = ptr(true)
+	diff, err := manager.Reload(groups)
+	if err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0] != "web" {
+		t.Errorf("expected web to be changed, got %+v", diff)
+	}
+	if !manager.GetMonitorByName("web").IsEnabled() {
+		t.Error("expected web to be enabled after the group is")
+	}
+}
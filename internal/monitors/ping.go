@@ -20,6 +20,7 @@ type pinger interface {
 	Privileged() bool
 	SetCount(int)
 	SetTimeout(time.Duration)
+	SetSource(string)
 	Statistics() *probing.Statistics
 }
 
@@ -35,6 +36,10 @@ func (p *probingPinger) SetTimeout(timeout time.Duration) {
 	p.Pinger.Timeout = timeout
 }
 
+func (p *probingPinger) SetSource(source string) {
+	p.Pinger.Source = source
+}
+
 func defaultPingerFactory(target string) (pinger, error) {
 	p, err := probing.NewPinger(target)
 	if err != nil {
@@ -49,19 +54,32 @@ type PingMonitor struct {
 	target    net.IP
 	isIPv6    bool
 	count     int
+	resolver  *hostResolver
 	newPinger func(string) (pinger, error)
 }
 
 // NewPingMonitor creates a new ping monitor
 func NewPingMonitor(config *models.Monitor, group string, logger *logging.Logger, metrics *metrics.Metrics) (*PingMonitor, error) {
+	base := NewBaseMonitor(config, group, logger, metrics)
+	if err := base.initSource(); err != nil {
+		return nil, err
+	}
+
+	resolver, err := newHostResolver(config, func(network string) *net.Dialer {
+		return base.newDialer(network, config.Timeout.ToDuration())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolver settings: %w", err)
+	}
+
 	// Resolve target to IP address
-	ip, err := net.ResolveIPAddr("ip", config.Target)
+	ip, err := resolver.ResolveIP(context.Background(), config.Target)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve target '%s': %w", config.Target, err)
 	}
 
 	// Determine if IPv6
-	isIPv6 := ip.IP.To4() == nil
+	isIPv6 := ip.To4() == nil
 
 	// Set default count
 	count := config.Count
@@ -70,10 +88,11 @@ func NewPingMonitor(config *models.Monitor, group string, logger *logging.Logger
 	}
 
 	return &PingMonitor{
-		BaseMonitor: NewBaseMonitor(config, group, logger, metrics),
-		target:      ip.IP,
+		BaseMonitor: base,
+		target:      ip,
 		isIPv6:      isIPv6,
 		count:       count,
+		resolver:    resolver,
 		newPinger:   defaultPingerFactory,
 	}, nil
 }
@@ -83,7 +102,7 @@ func (p *PingMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 	startTime := time.Now()
 
 	// Perform ping
-	result, err := p.performPing(ctx)
+	result, remote, err := p.performPing(ctx)
 	if err != nil {
 		duration := time.Since(startTime)
 		monitorResult := p.CreateResult(models.StatusDown, duration, err)
@@ -109,6 +128,8 @@ func (p *PingMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 	duration := time.Since(startTime)
 	monitorResult := p.CreateResult(status, duration, checkError)
 	monitorResult.PingResult = result
+	monitorResult.RemoteIP = remote
+	p.DetectTargetChange(monitorResult)
 
 	// Record ping-specific metrics
 	if p.Metrics != nil {
@@ -117,6 +138,7 @@ func (p *PingMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 			p.Group,
 			result.AvgRTT,
 			result.PacketLoss,
+			monitorResult.ID(),
 		)
 	}
 
@@ -126,25 +148,46 @@ func (p *PingMonitor) Check(ctx context.Context) (*models.MonitorResult, error)
 	return monitorResult, nil
 }
 
-// performPing executes the actual ICMP ping operation
-func (p *PingMonitor) performPing(ctx context.Context) (*models.PingResult, error) {
+// performPing executes the actual ICMP ping operation and returns the
+// address pinged
+func (p *PingMonitor) performPing(ctx context.Context) (*models.PingResult, string, error) {
 	timeout := p.Config.Timeout.ToDuration()
 	if timeout == 0 {
 		timeout = 3 * time.Second
 	}
 
+	// Resolve and vet the target before each check so DNS changes cannot
+	// bypass the egress policy, then ping the vetted address. Custom resolver
+	// settings also need resolving here since the pinger uses the system resolver.
+	target := p.Config.Target
+	guard := p.EgressGuard()
+	if guard != nil || p.resolver != nil {
+		ip, err := p.resolver.ResolveIP(ctx, target)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve target '%s': %w", target, err)
+		}
+		if err := guard.CheckIP(ip); err != nil {
+			return nil, "", err
+		}
+		target = ip.String()
+	}
+
 	// Create pinger
-	pinger, err := p.newPinger(p.Config.Target)
+	pinger, err := p.newPinger(target)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create pinger: %w", err)
+		return nil, "", fmt.Errorf("failed to create pinger: %w", err)
 	}
 
 	// Configure pinger
 	pinger.SetCount(p.count)
 	pinger.SetTimeout(timeout)
+	if p.source != nil {
+		pinger.SetSource(p.source.String())
+	}
 
 	// Try privileged mode first (ICMP), fall back to unprivileged if needed
 	pinger.SetPrivileged(true)
+	traceFrom(ctx).Add(TracePhaseConnect, "Pinging %s with %d packets, timeout %s", target, p.count, timeout)
 
 	// Handle context cancellation
 	done := make(chan error, 1)
@@ -156,7 +199,7 @@ func (p *PingMonitor) performPing(ctx context.Context) (*models.PingResult, erro
 	select {
 	case <-ctx.Done():
 		pinger.Stop()
-		return nil, ctx.Err()
+		return nil, "", ctx.Err()
 	case err := <-done:
 		if err != nil {
 			// If privileged mode failed, try unprivileged (UDP-based)
@@ -166,6 +209,7 @@ func (p *PingMonitor) performPing(ctx context.Context) (*models.PingResult, erro
 					"target":  p.Config.Target,
 				}).
 				Debug("Privileged ICMP failed, trying unprivileged mode")
+			traceFrom(ctx).Add(TracePhaseConnect, "Privileged ICMP failed, trying unprivileged mode: %v", err)
 
 			// Retry with unprivileged mode
 			pinger.SetPrivileged(false)
@@ -178,10 +222,10 @@ func (p *PingMonitor) performPing(ctx context.Context) (*models.PingResult, erro
 			select {
 			case <-ctx.Done():
 				pinger.Stop()
-				return nil, ctx.Err()
+				return nil, "", ctx.Err()
 			case err2 := <-done2:
 				if err2 != nil {
-					return nil, fmt.Errorf("ping failed in both privileged and unprivileged mode: %w", err2)
+					return nil, "", fmt.Errorf("ping failed in both privileged and unprivileged mode: %w", err2)
 				}
 			}
 		}
@@ -205,7 +249,13 @@ func (p *PingMonitor) performPing(ctx context.Context) (*models.PingResult, erro
 			"packet_loss":  stats.PacketLoss,
 		}).
 		Debug("Ping completed successfully")
+	traceFrom(ctx).Add(TracePhaseResponse, "%s: %d sent, %d received, rtt min %s avg %s max %s",
+		mode, stats.PacketsSent, stats.PacketsRecv, stats.MinRtt, stats.AvgRtt, stats.MaxRtt)
 
+	var remote string
+	if stats.IPAddr != nil {
+		remote = stats.IPAddr.IP.String()
+	}
 	return &models.PingResult{
 		PacketsSent:     stats.PacketsSent,
 		PacketsReceived: stats.PacketsRecv,
@@ -213,7 +263,7 @@ func (p *PingMonitor) performPing(ctx context.Context) (*models.PingResult, erro
 		MinRTT:          stats.MinRtt,
 		MaxRTT:          stats.MaxRtt,
 		AvgRTT:          stats.AvgRtt,
-	}, nil
+	}, remote, nil
 }
 
 // Validate validates the ping monitor configuration
@@ -223,7 +273,7 @@ func (p *PingMonitor) Validate() error {
 	}
 
 	// Try to resolve the target
-	_, err := net.ResolveIPAddr("ip", p.Config.Target)
+	_, err := p.resolver.ResolveIP(context.Background(), p.Config.Target)
 	if err != nil {
 		return fmt.Errorf("cannot resolve target '%s': %w", p.Config.Target, err)
 	}
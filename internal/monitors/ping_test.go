@@ -22,6 +22,7 @@ type fakePinger struct {
 	runCount    int
 	count       int
 	timeout     time.Duration
+	source      string
 }
 
 func (f *fakePinger) Run() error {
@@ -50,6 +51,10 @@ func (f *fakePinger) SetTimeout(timeout time.Duration) {
 	f.timeout = timeout
 }
 
+func (f *fakePinger) SetSource(source string) {
+	f.source = source
+}
+
 func (f *fakePinger) Statistics() *probing.Statistics {
 	return f.stats
 }
@@ -0,0 +1,151 @@
+package monitors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/internal/plugin"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// PluginProtocolVersion is the version of the plugin protocol sent in
+// requests, so plugins can reject versions they do not understand
+const PluginProtocolVersion = plugin.ProtocolVersion
+
+// PluginRequest is written to a plugin's stdin for each check
+type PluginRequest struct {
+	Protocol  int             `json:"protocol"`
+	Monitor   *models.Monitor `json:"monitor"`
+	Group     string          `json:"group"`
+	TimeoutMs int64           `json:"timeout_ms"`
+}
+
+// PluginResponse is read from a plugin's stdout after a check. All fields
+// are optional: without a status the exit code decides, 0 meaning up.
+type PluginResponse struct {
+	Status     models.MonitorStatus `json:"status,omitempty"`
+	Error      string               `json:"error,omitempty"`
+	DurationMs float64              `json:"duration_ms,omitempty"` // Replaces the measured run time, e.g. with the latency of what was checked
+	Metadata   interface{}          `json:"metadata,omitempty"`
+}
+
+// PluginMonitor checks by running an external executable that speaks JSON
+// over stdin and stdout
+type PluginMonitor struct {
+	*BaseMonitor
+	path string
+}
+
+// NewPluginMonitor creates a new plugin monitor that runs config.Plugin from
+// pluginDir
+func NewPluginMonitor(config *models.Monitor, group, pluginDir string, logger *logging.Logger, metrics *metrics.Metrics) (*PluginMonitor, error) {
+	path, err := plugin.Resolve(pluginDir, config.Plugin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid plugin: %w", err)
+	}
+	if path, err = exec.LookPath(path); err != nil {
+		return nil, fmt.Errorf("invalid plugin: %w", err)
+	}
+	return &PluginMonitor{
+		BaseMonitor: NewBaseMonitor(config, group, logger, metrics),
+		path:        path,
+	}, nil
+}
+
+// Check runs the plugin once and converts its response to a result
+func (p *PluginMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
+	return checkPlugin(ctx, p.BaseMonitor, p.path, plugin.Command(p.path, p.Config.PluginArgs, p.Config.PluginEnv)), nil
+}
+
+// checkPlugin runs a plugin for b's monitor and records the result. name
+// identifies the plugin in logs.
+func checkPlugin(ctx context.Context, b *BaseMonitor, name string, start plugin.Starter) *models.MonitorResult {
+	startTime := time.Now()
+	response, err := runPlugin(ctx, b, name, start)
+	duration := time.Since(startTime)
+
+	var result *models.MonitorResult
+	if err != nil {
+		result = b.CreateResult(models.StatusDown, duration, err)
+	} else {
+		if response.DurationMs > 0 {
+			duration = time.Duration(response.DurationMs * float64(time.Millisecond))
+		}
+		// The error is kept without forcing the status down, so plugins can
+		// explain unknown results
+		result = b.CreateResult(response.Status, duration, nil)
+		if result.Error == "" {
+			result.Error = response.Error
+		}
+		result.Metadata = response.Metadata
+	}
+
+	b.RecordMetrics(result)
+	b.LogResult(result)
+	return result
+}
+
+// runPlugin runs a plugin with a request for b's monitor and parses its
+// response
+func runPlugin(ctx context.Context, b *BaseMonitor, name string, start plugin.Starter) (*PluginResponse, error) {
+	request := PluginRequest{
+		Protocol: PluginProtocolVersion,
+		Monitor:  b.Config,
+		Group:    b.Group,
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		request.TimeoutMs = time.Until(deadline).Milliseconds()
+	}
+
+	output, err := plugin.RunWith(ctx, start, request)
+	if output != nil && output.Stderr != "" && b.Logger != nil {
+		b.Logger.WithComponent(logging.ComponentMonitor).
+			WithFields(map[string]interface{}{
+				"monitor": b.Config.Name,
+				"command": name,
+				"stderr":  output.Stderr,
+			}).
+			Debug("Plugin wrote to stderr")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PluginResponse{}
+	if len(output.Stdout) > 0 {
+		if err := json.Unmarshal(output.Stdout, response); err != nil {
+			return nil, fmt.Errorf("invalid plugin response: %w", err)
+		}
+	}
+
+	switch response.Status {
+	case models.StatusUp, models.StatusDown, models.StatusUnknown:
+	case "":
+		response.Status = models.StatusUp
+		if output.ExitCode != 0 {
+			response.Status = models.StatusDown
+		}
+	default:
+		return nil, fmt.Errorf("invalid plugin status %q", response.Status)
+	}
+	if response.Status == models.StatusDown && response.Error == "" {
+		response.Error = "plugin reported down"
+		if output.Stderr != "" || output.ExitCode != 0 {
+			response.Error = output.Failure()
+		}
+	}
+	return response, nil
+}
+
+// Validate checks that the plugin is set
+func (p *PluginMonitor) Validate() error {
+	if p.Config.Plugin == "" {
+		return fmt.Errorf("plugin monitor requires plugin")
+	}
+	return nil
+}
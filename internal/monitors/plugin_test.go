@@ -0,0 +1,196 @@
+package monitors
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// writePlugin writes a shell script plugin and returns its path
+func writePlugin(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "plugin.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	return path
+}
+
+func TestPluginMonitorCheck(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("plugin tests need /bin/sh")
+	}
+
+	tests := []struct {
+		name       string
+		script     string
+		timeout    time.Duration
+		wantStatus models.MonitorStatus
+		wantError  string
+	}{
+		{
+			name:       "json up",
+			script:     `echo '{"status":"up","duration_ms":42}'`,
+			wantStatus: models.StatusUp,
+		},
+		{
+			name:       "json down",
+			script:     `echo '{"status":"down","error":"queue is stuck"}'`,
+			wantStatus: models.StatusDown,
+			wantError:  "queue is stuck",
+		},
+		{
+			name:       "unknown keeps its error",
+			script:     `echo '{"status":"unknown","error":"broker unreachable"}'`,
+			wantStatus: models.StatusUnknown,
+			wantError:  "broker unreachable",
+		},
+		{
+			name:       "exit zero without output",
+			script:     `exit 0`,
+			wantStatus: models.StatusUp,
+		},
+		{
+			name:       "exit status with stderr",
+			script:     "echo starting >&2\necho 'disk 95% full' >&2\nexit 2",
+			wantStatus: models.StatusDown,
+			wantError:  "disk 95% full",
+		},
+		{
+			name:       "exit status without stderr",
+			script:     `exit 3`,
+			wantStatus: models.StatusDown,
+			wantError:  "plugin exited with status 3",
+		},
+		{
+			name:       "invalid json",
+			script:     `echo 'all good'`,
+			wantStatus: models.StatusDown,
+			wantError:  "invalid plugin response",
+		},
+		{
+			name:       "invalid status",
+			script:     `echo '{"status":"sideways"}'`,
+			wantStatus: models.StatusDown,
+			wantError:  `invalid plugin status "sideways"`,
+		},
+		{
+			name:       "timeout",
+			script:     `exec sleep 5`,
+			timeout:    200 * time.Millisecond,
+			wantStatus: models.StatusDown,
+			wantError:  "plugin did not finish",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &models.Monitor{
+				Name:   "plugin-test",
+				Type:   models.MonitorTypePlugin,
+				Plugin: "plugin.sh",
+			}
+			monitor, err := NewPluginMonitor(config, "test-group", filepath.Dir(writePlugin(t, tt.script+"\n")), nil, nil)
+			if err != nil {
+				t.Fatalf("NewPluginMonitor failed: %v", err)
+			}
+
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			result, err := monitor.Check(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("status = %s, want %s (error %q)", result.Status, tt.wantStatus, result.Error)
+			}
+			if !strings.Contains(result.Error, tt.wantError) || (tt.wantError == "" && result.Error != "") {
+				t.Errorf("error = %q, want %q", result.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestPluginMonitorRequest(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("plugin tests need /bin/sh")
+	}
+
+	// Only pluginEnv and a few basics of the server's environment reach plugins
+	t.Setenv("HALLMONITOR_TEST_SECRET", "hunter2")
+
+	// The plugin echoes its request, arguments, and environment back as metadata
+	script := `printf '{"metadata":{"request":%s,"arg":"%s","protocol":"%s","region":"%s","secret":"%s"}}' "$(cat)" "$1" "$HALLMONITOR_PLUGIN_PROTOCOL" "$REGION" "$HALLMONITOR_TEST_SECRET"` + "\n"
+	config := &models.Monitor{
+		Name:       "queue",
+		Type:       models.MonitorTypePlugin,
+		Plugin:     "plugin.sh",
+		PluginArgs: []string{"--verbose"},
+		PluginEnv:  map[string]string{"region": "eu"},
+		Options:    map[string]string{"queue": "orders"},
+	}
+	monitor, err := NewPluginMonitor(config, "jobs", filepath.Dir(writePlugin(t, script)), nil, nil)
+	if err != nil {
+		t.Fatalf("NewPluginMonitor failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	result, err := monitor.Check(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Status != models.StatusUp {
+		t.Fatalf("status = %s, error %q", result.Status, result.Error)
+	}
+
+	metadata, _ := result.Metadata.(map[string]interface{})
+	request, _ := metadata["request"].(map[string]interface{})
+	monitorConfig, _ := request["monitor"].(map[string]interface{})
+	options, _ := monitorConfig["options"].(map[string]interface{})
+	if request["group"] != "jobs" || request["protocol"] != float64(PluginProtocolVersion) || options["queue"] != "orders" {
+		t.Errorf("unexpected request %+v", request)
+	}
+	if timeout, _ := request["timeout_ms"].(float64); timeout <= 0 {
+		t.Errorf("expected the timeout in the request, got %v", request["timeout_ms"])
+	}
+	if metadata["arg"] != "--verbose" || metadata["protocol"] != "1" {
+		t.Errorf("unexpected metadata %+v", metadata)
+	}
+	if metadata["region"] != "eu" || metadata["secret"] != "" {
+		t.Errorf("expected pluginEnv and none of the server's other variables, got %+v", metadata)
+	}
+}
+
+func TestNewPluginMonitorInvalidPlugin(t *testing.T) {
+	dir := filepath.Dir(writePlugin(t, "exit 0\n"))
+	tests := []struct {
+		name      string
+		pluginDir string
+		plugin    string
+	}{
+		{name: "missing", pluginDir: dir, plugin: "missing"},
+		{name: "no plugin dir", plugin: "plugin.sh"},
+		{name: "absolute", pluginDir: dir, plugin: filepath.Join(dir, "plugin.sh")},
+		{name: "outside plugin dir", pluginDir: t.TempDir(), plugin: filepath.Join("..", filepath.Base(dir), "plugin.sh")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &models.Monitor{Name: "plugin-test", Type: models.MonitorTypePlugin, Plugin: tt.plugin}
+			if _, err := NewPluginMonitor(config, "test-group", tt.pluginDir, nil, nil); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
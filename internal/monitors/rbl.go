@@ -0,0 +1,222 @@
+package monitors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+var (
+	// defaultIPBlocklists are queried for IP targets when none are configured
+	defaultIPBlocklists = []string{"zen.spamhaus.org", "bl.spamcop.net", "b.barracudacentral.org"}
+
+	// defaultDomainBlocklists are queried for domain targets when none are configured
+	defaultDomainBlocklists = []string{"dbl.spamhaus.org", "multi.surbl.org"}
+
+	// blocklistErrorNet holds the return codes blocklists use to refuse a query,
+	// such as Spamhaus rejecting lookups made through public resolvers
+	blocklistErrorNet = &net.IPNet{IP: net.IPv4(127, 255, 255, 0), Mask: net.CIDRMask(24, 32)}
+
+	// blocklistListedNet holds the return codes that indicate a listing
+	blocklistListedNet = &net.IPNet{IP: net.IPv4(127, 0, 0, 0), Mask: net.CIDRMask(8, 32)}
+)
+
+// RBLMonitor checks whether an IP or domain is listed on DNS blocklists
+type RBLMonitor struct {
+	*BaseMonitor
+	target     string
+	queryBase  string // Target in DNSBL query form, e.g. reversed IP octets
+	blocklists []string
+	lookup     func(ctx context.Context, name string) ([]net.IP, error)
+}
+
+// NewRBLMonitor creates a new DNSBL monitor
+func NewRBLMonitor(config *models.Monitor, group string, logger *logging.Logger, metrics *metrics.Metrics) (*RBLMonitor, error) {
+	target := normalizeHost(config.Target)
+	queryBase, isIP, err := rblQueryBase(target)
+	if err != nil {
+		return nil, err
+	}
+
+	blocklists := make([]string, 0, len(config.Blocklists))
+	for _, zone := range config.Blocklists {
+		if zone = normalizeHost(zone); zone != "" {
+			blocklists = append(blocklists, zone)
+		}
+	}
+	if len(blocklists) == 0 {
+		blocklists = defaultDomainBlocklists
+		if isIP {
+			blocklists = defaultIPBlocklists
+		}
+	}
+
+	timeout := config.Timeout.ToDuration()
+	if timeout == 0 {
+		timeout = 5 * time.Second
+	}
+
+	base := NewBaseMonitor(config, group, logger, metrics)
+	if err := base.initSource(); err != nil {
+		return nil, err
+	}
+
+	resolver, err := newHostResolver(config, func(network string) *net.Dialer {
+		return base.newDialer(network, timeout)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolver settings: %w", err)
+	}
+
+	return &RBLMonitor{
+		BaseMonitor: base,
+		target:      target,
+		queryBase:   queryBase,
+		blocklists:  blocklists,
+		lookup:      resolver.LookupIP,
+	}, nil
+}
+
+// Check queries every blocklist concurrently and reports down when the target
+// is listed on any of them
+func (r *RBLMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
+	startTime := time.Now()
+
+	type answer struct {
+		blocklist string
+		codes     []string
+		err       error
+	}
+
+	answers := make([]answer, len(r.blocklists))
+	var wg sync.WaitGroup
+	for i, zone := range r.blocklists {
+		wg.Add(1)
+		go func(i int, zone string) {
+			defer wg.Done()
+			codes, err := r.query(ctx, zone)
+			answers[i] = answer{blocklist: zone, codes: codes, err: err}
+		}(i, zone)
+	}
+	wg.Wait()
+
+	duration := time.Since(startTime)
+
+	rblResult := &models.RBLResult{
+		Target:  r.target,
+		Checked: len(r.blocklists),
+	}
+	for _, a := range answers {
+		switch {
+		case a.err != nil:
+			if rblResult.Errors == nil {
+				rblResult.Errors = make(map[string]string)
+			}
+			rblResult.Errors[a.blocklist] = a.err.Error()
+		case len(a.codes) > 0:
+			rblResult.Listed = append(rblResult.Listed, models.RBLListing{Blocklist: a.blocklist, Codes: a.codes})
+		}
+
+		if r.Metrics != nil && a.err == nil {
+			r.Metrics.SetRBLListed(r.Config.Name, r.Group, a.blocklist, len(a.codes) > 0)
+		}
+	}
+
+	var err error
+	status := models.StatusUp
+	switch {
+	case len(rblResult.Listed) > 0:
+		zones := make([]string, len(rblResult.Listed))
+		for i, listing := range rblResult.Listed {
+			zones[i] = listing.Blocklist
+		}
+		status = models.StatusDown
+		err = fmt.Errorf("%s is listed on %s", r.target, strings.Join(zones, ", "))
+	case len(rblResult.Errors) == len(r.blocklists):
+		status = models.StatusDown
+		err = fmt.Errorf("all blocklist lookups failed: %s", rblResult.Errors[r.blocklists[0]])
+	}
+
+	result := r.CreateResult(status, duration, err)
+	result.RBLResult = rblResult
+
+	r.RecordMetrics(result)
+	r.LogResult(result)
+
+	return result, nil
+}
+
+// query looks the target up on one blocklist, returning the listing codes or
+// nil when the target is not listed
+func (r *RBLMonitor) query(ctx context.Context, zone string) ([]string, error) {
+	ips, err := r.lookup(ctx, r.queryBase+"."+zone+".")
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var codes []string
+	for _, ip := range ips {
+		switch {
+		case blocklistErrorNet.Contains(ip):
+			return nil, fmt.Errorf("query refused by %s (code %s)", zone, ip)
+		case blocklistListedNet.Contains(ip):
+			codes = append(codes, ip.String())
+		default:
+			return nil, fmt.Errorf("unexpected answer %s from %s", ip, zone)
+		}
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// Validate validates the RBL monitor configuration
+func (r *RBLMonitor) Validate() error {
+	if r.Config.Target == "" {
+		return fmt.Errorf("RBL monitor requires target")
+	}
+	if _, _, err := rblQueryBase(normalizeHost(r.Config.Target)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// rblQueryBase converts a target to the form prepended to blocklist zones:
+// reversed octets for IPv4, reversed nibbles for IPv6, and the name itself for
+// domains. It also reports whether the target is an IP.
+func rblQueryBase(target string) (string, bool, error) {
+	if target == "" {
+		return "", false, fmt.Errorf("RBL monitor requires target")
+	}
+
+	ip := net.ParseIP(target)
+	if ip == nil {
+		if strings.ContainsAny(target, "/: ") || !strings.Contains(target, ".") {
+			return "", false, fmt.Errorf("invalid RBL target %q: must be an IP address or domain", target)
+		}
+		return target, false, nil
+	}
+
+	if ip4 := ip.To4(); ip4 != nil {
+		return fmt.Sprintf("%d.%d.%d.%d", ip4[3], ip4[2], ip4[1], ip4[0]), true, nil
+	}
+
+	const hexDigits = "0123456789abcdef"
+	nibbles := make([]string, 0, 32)
+	for i := len(ip) - 1; i >= 0; i-- {
+		nibbles = append(nibbles, string(hexDigits[ip[i]&0x0f]), string(hexDigits[ip[i]>>4]))
+	}
+	return strings.Join(nibbles, "."), true, nil
+}
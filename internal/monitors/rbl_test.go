@@ -0,0 +1,142 @@
+package monitors
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestRBLQueryBase(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		want      string
+		wantIP    bool
+		expectErr bool
+	}{
+		{name: "ipv4", target: "192.0.2.10", want: "10.2.0.192", wantIP: true},
+		{name: "ipv6", target: "2001:db8::1", want: "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2", wantIP: true},
+		{name: "domain", target: "mail.example.com", want: "mail.example.com"},
+		{name: "empty", target: "", expectErr: true},
+		{name: "url", target: "https://example.com", expectErr: true},
+		{name: "single label", target: "localhost", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isIP, err := rblQueryBase(tt.target)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want || isIP != tt.wantIP {
+				t.Errorf("expected (%q, %v), got (%q, %v)", tt.want, tt.wantIP, got, isIP)
+			}
+		})
+	}
+}
+
+func TestNewRBLMonitorDefaultBlocklists(t *testing.T) {
+	ipMonitor, err := NewRBLMonitor(&models.Monitor{Type: models.MonitorTypeRBL, Name: "mx-ip", Target: "192.0.2.10"}, "mail", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRBLMonitor failed: %v", err)
+	}
+	if ipMonitor.blocklists[0] != defaultIPBlocklists[0] {
+		t.Errorf("expected IP blocklists, got %v", ipMonitor.blocklists)
+	}
+
+	domainMonitor, err := NewRBLMonitor(&models.Monitor{Type: models.MonitorTypeRBL, Name: "mx-domain", Target: "example.com"}, "mail", nil, nil)
+	if err != nil {
+		t.Fatalf("NewRBLMonitor failed: %v", err)
+	}
+	if domainMonitor.blocklists[0] != defaultDomainBlocklists[0] {
+		t.Errorf("expected domain blocklists, got %v", domainMonitor.blocklists)
+	}
+}
+
+func TestRBLMonitorCheck(t *testing.T) {
+	notFound := &net.DNSError{Err: "no such host", IsNotFound: true}
+
+	tests := []struct {
+		name       string
+		answers    map[string][]net.IP
+		errs       map[string]error
+		wantStatus models.MonitorStatus
+		wantListed int
+		wantErrors int
+	}{
+		{
+			name:       "not listed",
+			errs:       map[string]error{"a.test": notFound, "b.test": notFound},
+			wantStatus: models.StatusUp,
+		},
+		{
+			name:       "listed on one",
+			answers:    map[string][]net.IP{"a.test": {net.IPv4(127, 0, 0, 2)}},
+			errs:       map[string]error{"b.test": notFound},
+			wantStatus: models.StatusDown,
+			wantListed: 1,
+		},
+		{
+			name:       "query refused",
+			answers:    map[string][]net.IP{"a.test": {net.IPv4(127, 255, 255, 254)}},
+			errs:       map[string]error{"b.test": notFound},
+			wantStatus: models.StatusUp,
+			wantErrors: 1,
+		},
+		{
+			name:       "all lookups fail",
+			errs:       map[string]error{"a.test": errors.New("i/o timeout"), "b.test": errors.New("i/o timeout")},
+			wantStatus: models.StatusDown,
+			wantErrors: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, err := NewRBLMonitor(&models.Monitor{
+				Type:       models.MonitorTypeRBL,
+				Name:       "mx",
+				Target:     "192.0.2.10",
+				Blocklists: []string{"a.test", "b.test"},
+			}, "mail", nil, nil)
+			if err != nil {
+				t.Fatalf("NewRBLMonitor failed: %v", err)
+			}
+
+			monitor.lookup = func(ctx context.Context, name string) ([]net.IP, error) {
+				zone := strings.TrimSuffix(strings.TrimPrefix(name, "10.2.0.192."), ".")
+				if err, ok := tt.errs[zone]; ok {
+					return nil, err
+				}
+				return tt.answers[zone], nil
+			}
+
+			result, err := monitor.Check(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s (%s)", tt.wantStatus, result.Status, result.Error)
+			}
+			if len(result.RBLResult.Listed) != tt.wantListed {
+				t.Errorf("expected %d listings, got %+v", tt.wantListed, result.RBLResult.Listed)
+			}
+			if len(result.RBLResult.Errors) != tt.wantErrors {
+				t.Errorf("expected %d errors, got %v", tt.wantErrors, result.RBLResult.Errors)
+			}
+			if result.RBLResult.Checked != 2 {
+				t.Errorf("expected 2 blocklists checked, got %d", result.RBLResult.Checked)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package monitors
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// hostResolver resolves monitor targets using static host overrides, custom
+// DNS servers, and search domains. A nil hostResolver uses the system resolver.
+type hostResolver struct {
+	hosts         map[string]net.IP
+	resolvers     []*net.Resolver
+	servers       []string // Address of each resolver, for tracing
+	searchDomains []string
+}
+
+// newHostResolver builds a resolver for the monitor's resolver and hosts
+// settings, returning nil when neither is configured. newDialer creates the
+// dialers used to reach the DNS servers.
+func newHostResolver(config *models.Monitor, newDialer func(network string) *net.Dialer) (*hostResolver, error) {
+	if config.Resolver.IsZero() && len(config.Hosts) == 0 {
+		return nil, nil
+	}
+
+	r := &hostResolver{hosts: make(map[string]net.IP, len(config.Hosts))}
+	for host, address := range config.Hosts {
+		ip := net.ParseIP(strings.TrimSpace(address))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP %q for host %s", address, host)
+		}
+		r.hosts[normalizeHost(host)] = ip
+	}
+
+	if config.Resolver != nil {
+		for _, server := range config.Resolver.Servers {
+			address, err := resolverAddress(server)
+			if err != nil {
+				return nil, err
+			}
+			r.servers = append(r.servers, address)
+			r.resolvers = append(r.resolvers, &net.Resolver{
+				PreferGo: true,
+				Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+					return newDialer(network).DialContext(ctx, network, address)
+				},
+			})
+		}
+		for _, domain := range config.Resolver.SearchDomains {
+			if domain = normalizeHost(domain); domain != "" {
+				r.searchDomains = append(r.searchDomains, domain)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// LookupIP resolves host to its IP addresses
+func (r *hostResolver) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	if r == nil {
+		return net.DefaultResolver.LookupIP(ctx, "ip", host)
+	}
+
+	var lastErr error
+	for _, name := range r.candidates(host) {
+		if ip, ok := r.hosts[name]; ok {
+			traceFrom(ctx).Add(TracePhaseDNS, "Using hosts override %s for %s", ip, name)
+			return []net.IP{ip}, nil
+		}
+
+		ips, err := r.lookup(ctx, name)
+		if err == nil && len(ips) > 0 {
+			return ips, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no addresses found for %s", host)
+	}
+	return nil, lastErr
+}
+
+// ResolveIP resolves host to a single address, preferring IPv4 like net.ResolveIPAddr
+func (r *hostResolver) ResolveIP(ctx context.Context, host string) (net.IP, error) {
+	ips, err := r.LookupIP(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip, nil
+		}
+	}
+	return ips[0], nil
+}
+
+// DialContext resolves the host in address and dials each of its addresses in
+// turn until one connects
+func (r *hostResolver) DialContext(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	if r == nil {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := r.LookupIP(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range ips {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		traceFrom(ctx).Add(TracePhaseConnect, "Dial %s failed, trying the next address: %v", ip, err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// VerifyIPs resolves host and returns an error wrapping ErrUnexpectedIP unless
+// every address it resolves to falls within expected
+func (r *hostResolver) VerifyIPs(ctx context.Context, host string, expected []*net.IPNet) error {
+	ips, err := r.LookupIP(ctx, host)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDNSResolutionFailed, err)
+	}
+
+	var unexpected []string
+	for _, ip := range ips {
+		if !slices.ContainsFunc(expected, func(network *net.IPNet) bool { return network.Contains(ip) }) {
+			unexpected = append(unexpected, ip.String())
+		}
+	}
+	if len(unexpected) > 0 {
+		return fmt.Errorf("%w: %s resolved to %s", ErrUnexpectedIP, host, strings.Join(unexpected, ", "))
+	}
+	return nil
+}
+
+// expectedNetworks parses a monitor's expectedIP and expectedIPs settings
+func expectedNetworks(config *models.Monitor) ([]*net.IPNet, error) {
+	entries := config.ExpectedIPs
+	if config.ExpectedIP != "" {
+		entries = append([]string{config.ExpectedIP}, entries...)
+	}
+	networks, err := ParseNetworks(entries)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected IP: %w", err)
+	}
+	return networks, nil
+}
+
+// lookup queries the configured DNS servers in order, or the system resolver
+// when only host overrides are configured
+func (r *hostResolver) lookup(ctx context.Context, name string) ([]net.IP, error) {
+	if len(r.resolvers) == 0 {
+		return net.DefaultResolver.LookupIP(ctx, "ip", name)
+	}
+
+	trace := traceFrom(ctx)
+	var lastErr error
+	for i, resolver := range r.resolvers {
+		trace.Add(TracePhaseDNS, "Querying %s for %s", r.servers[i], name)
+		ips, err := resolver.LookupIP(ctx, "ip", name)
+		if err == nil {
+			trace.Add(TracePhaseDNS, "%s answered %v", r.servers[i], ips)
+			return ips, nil
+		}
+		trace.Add(TracePhaseDNS, "%s failed: %v", r.servers[i], err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// candidates returns the names to try for host; names without a dot are
+// qualified with each search domain before being tried as-is
+func (r *hostResolver) candidates(host string) []string {
+	host = normalizeHost(host)
+	if strings.Contains(host, ".") || len(r.searchDomains) == 0 {
+		return []string{host}
+	}
+
+	names := make([]string, 0, len(r.searchDomains)+1)
+	for _, domain := range r.searchDomains {
+		names = append(names, host+"."+domain)
+	}
+	return append(names, host)
+}
+
+// resolverAddress normalizes a DNS server entry to ip:port
+func resolverAddress(server string) (string, error) {
+	server = strings.TrimSpace(server)
+	if ip := net.ParseIP(server); ip != nil {
+		return net.JoinHostPort(ip.String(), "53"), nil
+	}
+
+	host, port, err := parseDNSTarget(server)
+	if err != nil {
+		return "", fmt.Errorf("invalid resolver server %q: %w", server, err)
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("resolver server %q must be an IP address", server)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// normalizeHost lowercases a hostname and strips any trailing dot
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// remoteIP returns the IP address of addr, or an empty string if it has none
+func remoteIP(addr net.Addr) string {
+	switch a := addr.(type) {
+	case *net.TCPAddr:
+		return a.IP.String()
+	case *net.UDPAddr:
+		return a.IP.String()
+	case *net.IPAddr:
+		return a.IP.String()
+	}
+	return ""
+}
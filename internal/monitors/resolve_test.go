@@ -0,0 +1,192 @@
+package monitors
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestHostResolverLookupIP(t *testing.T) {
+	config := &models.Monitor{
+		Resolver: &models.ResolverConfig{SearchDomains: []string{"corp.test", "internal.test."}},
+		Hosts: map[string]string{
+			"db.internal.test": "10.0.0.5",
+			"API.example.com":  "192.0.2.10",
+		},
+	}
+
+	resolver, err := newHostResolver(config, nil)
+	if err != nil {
+		t.Fatalf("newHostResolver returned error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{name: "literal ip", host: "203.0.113.7", want: "203.0.113.7"},
+		{name: "override is case-insensitive", host: "api.EXAMPLE.com.", want: "192.0.2.10"},
+		{name: "search domain", host: "db", want: "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip, err := resolver.ResolveIP(context.Background(), tt.host)
+			if err != nil {
+				t.Fatalf("ResolveIP returned error: %v", err)
+			}
+			if ip.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, ip)
+			}
+		})
+	}
+}
+
+func TestNewHostResolver(t *testing.T) {
+	if resolver, err := newHostResolver(&models.Monitor{}, nil); resolver != nil || err != nil {
+		t.Fatalf("expected nil resolver without settings, got %v, %v", resolver, err)
+	}
+
+	invalid := []*models.Monitor{
+		{Hosts: map[string]string{"app": "not-an-ip"}},
+		{Resolver: &models.ResolverConfig{Servers: []string{"dns.example.com"}}},
+	}
+	for _, config := range invalid {
+		if _, err := newHostResolver(config, nil); err == nil {
+			t.Errorf("expected error for %+v", config)
+		}
+	}
+}
+
+func TestHostsOverrideChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(serverURL.Host)
+	hosts := map[string]string{"app.cutover.test": "127.0.0.1"}
+
+	tests := []struct {
+		name   string
+		create func() (Monitor, error)
+	}{
+		{
+			name: "http",
+			create: func() (Monitor, error) {
+				return NewHTTPMonitor(&models.Monitor{
+					Type:  models.MonitorTypeHTTP,
+					Name:  "http",
+					URL:   "http://app.cutover.test:" + port,
+					Hosts: hosts,
+				}, "test-group", nil, nil)
+			},
+		},
+		{
+			name: "tcp",
+			create: func() (Monitor, error) {
+				return NewTCPMonitor(&models.Monitor{
+					Type:   models.MonitorTypeTCP,
+					Name:   "tcp",
+					Target: "app.cutover.test:" + port,
+					Hosts:  hosts,
+				}, "test-group", nil, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			monitor, err := tt.create()
+			if err != nil {
+				t.Fatalf("failed to create monitor: %v", err)
+			}
+
+			result, err := monitor.Check(context.Background())
+			if err != nil {
+				t.Fatalf("Check returned error: %v", err)
+			}
+			if result.Status != models.StatusUp {
+				t.Errorf("expected status up via hosts override, got %s: %s", result.Status, result.Error)
+			}
+		})
+	}
+}
+
+func TestExpectedIPChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	_, port, _ := net.SplitHostPort(serverURL.Host)
+	hosts := map[string]string{"app.pinned.test": "127.0.0.1"}
+
+	tests := []struct {
+		name        string
+		expectedIP  string
+		expectedIPs []string
+		wantStatus  models.MonitorStatus
+	}{
+		{name: "matching ip", expectedIP: "127.0.0.1", wantStatus: models.StatusUp},
+		{name: "matching cidr", expectedIPs: []string{"192.0.2.0/24", "127.0.0.0/8"}, wantStatus: models.StatusUp},
+		{name: "unexpected ip", expectedIP: "192.0.2.10", wantStatus: models.StatusDown},
+	}
+
+	for _, tt := range tests {
+		for _, monitorType := range []models.MonitorType{models.MonitorTypeHTTP, models.MonitorTypeTCP} {
+			t.Run(tt.name+"/"+string(monitorType), func(t *testing.T) {
+				config := &models.Monitor{
+					Type:        monitorType,
+					Name:        "pinned",
+					Hosts:       hosts,
+					ExpectedIP:  tt.expectedIP,
+					ExpectedIPs: tt.expectedIPs,
+				}
+
+				var monitor Monitor
+				if monitorType == models.MonitorTypeHTTP {
+					config.URL = "http://app.pinned.test:" + port
+					monitor, err = NewHTTPMonitor(config, "test-group", nil, nil)
+				} else {
+					config.Target = "app.pinned.test:" + port
+					monitor, err = NewTCPMonitor(config, "test-group", nil, nil)
+				}
+				if err != nil {
+					t.Fatalf("failed to create monitor: %v", err)
+				}
+
+				result, _ := monitor.Check(context.Background())
+				if result.Status != tt.wantStatus {
+					t.Fatalf("expected status %s, got %s: %s", tt.wantStatus, result.Status, result.Error)
+				}
+				if tt.wantStatus == models.StatusDown && !strings.Contains(result.Error, "127.0.0.1") {
+					t.Errorf("expected error to name the unexpected address, got %q", result.Error)
+				}
+			})
+		}
+	}
+
+	if _, err := NewTCPMonitor(&models.Monitor{Target: "app.pinned.test:80", ExpectedIP: "not-an-ip"}, "test-group", nil, nil); err == nil {
+		t.Error("expected error for invalid expectedIP")
+	}
+}
@@ -0,0 +1,84 @@
+package monitors
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// sourceIP returns the local address checks for config originate from, or nil
+// to let the system choose. An interface resolves to its first IPv4 address,
+// falling back to its first global IPv6 address.
+func sourceIP(config *models.Monitor) (net.IP, error) {
+	if config.SourceIP != "" {
+		ip := net.ParseIP(strings.TrimSpace(config.SourceIP))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid sourceIP %q", config.SourceIP)
+		}
+		return ip, nil
+	}
+	if config.SourceInterface == "" {
+		return nil, nil
+	}
+
+	iface, err := net.InterfaceByName(config.SourceInterface)
+	if err != nil {
+		return nil, fmt.Errorf("sourceInterface %q: %w", config.SourceInterface, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("sourceInterface %q: %w", config.SourceInterface, err)
+	}
+
+	var fallback net.IP
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if ipNet.IP.To4() != nil {
+			return ipNet.IP, nil
+		}
+		if fallback == nil {
+			fallback = ipNet.IP
+		}
+	}
+	if fallback == nil {
+		return nil, fmt.Errorf("sourceInterface %q has no usable address", config.SourceInterface)
+	}
+	return fallback, nil
+}
+
+// initSource resolves the monitor's configured source address
+func (b *BaseMonitor) initSource() error {
+	ip, err := sourceIP(b.Config)
+	if err != nil {
+		return err
+	}
+	b.source = ip
+	return nil
+}
+
+// localAddr returns ip as a local address for network, or nil when ip is nil
+func localAddr(network string, ip net.IP) net.Addr {
+	if ip == nil {
+		return nil
+	}
+	if strings.HasPrefix(network, "udp") {
+		return &net.UDPAddr{IP: ip}
+	}
+	return &net.TCPAddr{IP: ip}
+}
+
+// newDialer returns a dialer for network that honors the monitor's source
+// address and egress policy
+func (b *BaseMonitor) newDialer(network string, timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout:   timeout,
+		LocalAddr: localAddr(network, b.source),
+		Control:   b.dialControl,
+	}
+}
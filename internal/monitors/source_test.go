@@ -0,0 +1,82 @@
+package monitors
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestSourceIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *models.Monitor
+		want    string
+		wantErr bool
+	}{
+		{name: "unset", config: &models.Monitor{}},
+		{name: "explicit ip", config: &models.Monitor{SourceIP: "192.0.2.4"}, want: "192.0.2.4"},
+		{name: "invalid ip", config: &models.Monitor{SourceIP: "eth0"}, wantErr: true},
+		{name: "unknown interface", config: &models.Monitor{SourceInterface: "does-not-exist0"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip, err := sourceIP(tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error=%v, got %v", tt.wantErr, err)
+			}
+			if tt.want == "" && ip != nil {
+				t.Errorf("expected no source address, got %s", ip)
+			}
+			if tt.want != "" && ip.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, ip)
+			}
+		})
+	}
+}
+
+func TestTCPMonitorUsesSourceIP(t *testing.T) {
+	// Only Linux routes all of 127.0.0.0/8 to loopback without extra setup
+	if runtime.GOOS != "linux" {
+		t.Skip("requires 127.0.0.2 on loopback")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	remote := make(chan net.Addr, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		remote <- conn.RemoteAddr()
+		conn.Close()
+	}()
+
+	monitor, err := NewTCPMonitor(&models.Monitor{
+		Type:     models.MonitorTypeTCP,
+		Name:     "bound",
+		Target:   listener.Addr().String(),
+		SourceIP: "127.0.0.2",
+	}, "test-group", nil, nil)
+	if err != nil {
+		t.Fatalf("NewTCPMonitor failed: %v", err)
+	}
+
+	result, err := monitor.Check(context.Background())
+	if err != nil || result.Status != models.StatusUp {
+		t.Fatalf("expected successful check, got %v / %+v", err, result)
+	}
+
+	addr := (<-remote).(*net.TCPAddr)
+	if addr.IP.String() != "127.0.0.2" {
+		t.Errorf("expected connection from 127.0.0.2, got %s", addr.IP)
+	}
+}
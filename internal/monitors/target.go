@@ -0,0 +1,73 @@
+package monitors
+
+import (
+	"sync"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// targetState is the address and certificate a monitor reached last
+type targetState struct {
+	mu         sync.Mutex
+	ip         string
+	certSerial string
+}
+
+// DetectTargetChange compares the remote address and leaf certificate of
+// result with the previous check and flags a change when detectTargetChanges
+// is enabled. The first check only records a baseline, and checks that
+// reached nothing keep it.
+func (b *BaseMonitor) DetectTargetChange(result *models.MonitorResult) {
+	if !b.Config.DetectTargetChanges {
+		return
+	}
+
+	var serial string
+	if result.HTTPResult != nil && len(result.HTTPResult.CertChain) > 0 {
+		serial = result.HTTPResult.CertChain[0].SerialNumber
+	}
+
+	change := models.TargetChange{}
+	b.target.mu.Lock()
+	if result.RemoteIP != "" {
+		if b.target.ip != "" && b.target.ip != result.RemoteIP {
+			change.PreviousIP, change.IP = b.target.ip, result.RemoteIP
+		}
+		b.target.ip = result.RemoteIP
+	}
+	if serial != "" {
+		if b.target.certSerial != "" && b.target.certSerial != serial {
+			change.PreviousCertSerial, change.CertSerial = b.target.certSerial, serial
+		}
+		b.target.certSerial = serial
+	}
+	b.target.mu.Unlock()
+
+	if change == (models.TargetChange{}) {
+		return
+	}
+	result.TargetChange = &change
+
+	if b.Metrics != nil {
+		if change.IP != "" {
+			b.Metrics.RecordTargetChange(b.Config.Name, b.Group, "ip")
+		}
+		if change.CertSerial != "" {
+			b.Metrics.RecordTargetChange(b.Config.Name, b.Group, "certificate")
+		}
+	}
+	if b.Logger != nil {
+		b.Logger.WithComponent(logging.ComponentMonitor).
+			WithEvent(logging.EventTargetChanged).
+			WithFields(map[string]interface{}{
+				"monitor":              b.Config.Name,
+				"group":                b.Group,
+				"previous_ip":          change.PreviousIP,
+				"ip":                   change.IP,
+				"previous_cert_serial": change.PreviousCertSerial,
+				"cert_serial":          change.CertSerial,
+			}).
+			Warn("Monitor target changed")
+	}
+}
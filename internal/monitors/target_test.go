@@ -0,0 +1,77 @@
+package monitors
+
+import (
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestDetectTargetChange(t *testing.T) {
+	type check struct {
+		ip     string
+		serial string
+		want   *models.TargetChange
+	}
+	tests := []struct {
+		name    string
+		enabled bool
+		checks  []check
+	}{
+		{
+			name:    "disabled",
+			enabled: false,
+			checks:  []check{{ip: "192.0.2.1"}, {ip: "192.0.2.2"}},
+		},
+		{
+			name:    "same address",
+			enabled: true,
+			checks:  []check{{ip: "192.0.2.1"}, {ip: "192.0.2.1"}},
+		},
+		{
+			name:    "address moved",
+			enabled: true,
+			checks: []check{
+				{ip: "192.0.2.1"},
+				{ip: "192.0.2.2", want: &models.TargetChange{PreviousIP: "192.0.2.1", IP: "192.0.2.2"}},
+				{ip: "192.0.2.2"},
+			},
+		},
+		{
+			name:    "failed check keeps baseline",
+			enabled: true,
+			checks: []check{
+				{ip: "192.0.2.1"},
+				{},
+				{ip: "192.0.2.2", want: &models.TargetChange{PreviousIP: "192.0.2.1", IP: "192.0.2.2"}},
+			},
+		},
+		{
+			name:    "certificate rotated",
+			enabled: true,
+			checks: []check{
+				{ip: "192.0.2.1", serial: "01"},
+				{ip: "192.0.2.1", serial: "02", want: &models.TargetChange{PreviousCertSerial: "01", CertSerial: "02"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := NewBaseMonitor(&models.Monitor{Name: "api", DetectTargetChanges: tt.enabled}, "core", nil, nil)
+			for i, c := range tt.checks {
+				result := &models.MonitorResult{Monitor: "api", RemoteIP: c.ip}
+				if c.serial != "" {
+					result.HTTPResult = &models.HTTPResult{CertChain: []models.Certificate{{SerialNumber: c.serial}}}
+				}
+				base.DetectTargetChange(result)
+
+				switch {
+				case c.want == nil && result.TargetChange != nil:
+					t.Errorf("check %d: unexpected change %+v", i, result.TargetChange)
+				case c.want != nil && (result.TargetChange == nil || *result.TargetChange != *c.want):
+					t.Errorf("check %d: TargetChange = %+v, want %+v", i, result.TargetChange, c.want)
+				}
+			}
+		})
+	}
+}
@@ -16,8 +16,10 @@ import (
 // TCPMonitor implements TCP port monitoring
 type TCPMonitor struct {
 	*BaseMonitor
-	host string
-	port int
+	host        string
+	port        int
+	resolver    *hostResolver
+	expectedIPs []*net.IPNet
 }
 
 // NewTCPMonitor creates a new TCP monitor
@@ -28,10 +30,29 @@ func NewTCPMonitor(config *models.Monitor, group string, logger *logging.Logger,
 		return nil, fmt.Errorf("invalid target format: %w", err)
 	}
 
+	base := NewBaseMonitor(config, group, logger, metrics)
+	if err := base.initSource(); err != nil {
+		return nil, err
+	}
+
+	resolver, err := newHostResolver(config, func(network string) *net.Dialer {
+		return base.newDialer(network, config.Timeout.ToDuration())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid resolver settings: %w", err)
+	}
+
+	expectedIPs, err := expectedNetworks(config)
+	if err != nil {
+		return nil, err
+	}
+
 	return &TCPMonitor{
-		BaseMonitor: NewBaseMonitor(config, group, logger, metrics),
+		BaseMonitor: base,
 		host:        host,
 		port:        port,
+		resolver:    resolver,
+		expectedIPs: expectedIPs,
 	}, nil
 }
 
@@ -45,15 +66,30 @@ func (t *TCPMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
 		timeout = 5 * time.Second
 	}
 
-	// Create a dialer with timeout
-	dialer := &net.Dialer{
-		Timeout: timeout,
+	// Catch DNS changes or hijacks before connecting to the target
+	if len(t.expectedIPs) > 0 {
+		if err := t.resolver.VerifyIPs(ctx, t.host, t.expectedIPs); err != nil {
+			result := t.CreateResult(models.StatusDown, time.Since(startTime), err)
+			t.RecordMetrics(result)
+			t.LogResult(result)
+			return result, nil
+		}
 	}
 
+	// Create a dialer with timeout
+	dialer := t.newDialer("tcp", timeout)
+
 	// Attempt to connect
 	address := net.JoinHostPort(t.host, strconv.Itoa(t.port))
-	conn, err := dialer.DialContext(ctx, "tcp", address)
+	trace := traceFrom(ctx)
+	trace.Add(TracePhaseConnect, "Dialing %s, timeout %s", address, timeout)
+	conn, err := t.resolver.DialContext(ctx, dialer, "tcp", address)
 	duration := time.Since(startTime)
+	if err != nil {
+		trace.Add(TracePhaseConnect, "Dial failed: %v", err)
+	} else {
+		trace.Add(TracePhaseConnect, "Connected from %s to %s", conn.LocalAddr(), conn.RemoteAddr())
+	}
 
 	// Create TCP result data
 	tcpResult := &models.TCPResult{
@@ -63,12 +99,14 @@ func (t *TCPMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
 	}
 
 	var status models.MonitorStatus
+	var remote string
 	if err != nil {
 		status = models.StatusDown
 	} else {
 		status = models.StatusUp
 		// Close the connection immediately after successful connect
 		if conn != nil {
+			remote = remoteIP(conn.RemoteAddr())
 			conn.Close()
 		}
 	}
@@ -76,6 +114,8 @@ func (t *TCPMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
 	// Create monitor result
 	result := t.CreateResult(status, duration, err)
 	result.TCPResult = tcpResult
+	result.RemoteIP = remote
+	t.DetectTargetChange(result)
 
 	// Record TCP-specific metrics
 	if t.Metrics != nil {
@@ -84,6 +124,7 @@ func (t *TCPMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
 			t.Group,
 			t.port,
 			duration,
+			result.ID(),
 		)
 	}
 
@@ -212,6 +212,10 @@ func TestTCPMonitorCheckSuccess(t *testing.T) {
 		t.Fatalf("expected port %d, got %d", addr.Port, result.TCPResult.Port)
 	}
 
+	if result.RemoteIP != addr.IP.String() {
+		t.Fatalf("expected remote IP %s, got %q", addr.IP, result.RemoteIP)
+	}
+
 	if result.Duration == 0 {
 		t.Fatalf("expected non-zero duration")
 	}
@@ -0,0 +1,163 @@
+package monitors
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http/httptrace"
+	"net/textproto"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Trace phases
+const (
+	TracePhaseDNS      = "dns"
+	TracePhaseConnect  = "connect"
+	TracePhaseTLS      = "tls"
+	TracePhaseRequest  = "request"
+	TracePhaseResponse = "response"
+	TracePhaseRedirect = "redirect"
+	TracePhaseCheck    = "check"
+)
+
+// TraceEvent is one step of a traced check
+type TraceEvent struct {
+	Elapsed float64 `json:"elapsed_ms"` // Since the trace started
+	Phase   string  `json:"phase"`
+	Message string  `json:"message"`
+}
+
+// Trace records what a check did step by step. Checks only record to a trace
+// attached to their context with WithTrace, so regular checks pay nothing.
+type Trace struct {
+	start  time.Time
+	mu     sync.Mutex
+	events []TraceEvent
+}
+
+type traceKey struct{}
+
+// sensitiveHeaders are request headers whose values traces leave out
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+}
+
+// NewTrace creates a trace starting now
+func NewTrace() *Trace {
+	return &Trace{start: time.Now()}
+}
+
+// WithTrace returns a context that makes checks record to trace
+func WithTrace(ctx context.Context, trace *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// traceFrom returns the trace attached to ctx, or nil
+func traceFrom(ctx context.Context) *Trace {
+	trace, _ := ctx.Value(traceKey{}).(*Trace)
+	return trace
+}
+
+// Add records an event. It does nothing on a nil trace.
+func (t *Trace) Add(phase, format string, args ...interface{}) {
+	if t == nil {
+		return
+	}
+	event := TraceEvent{
+		Elapsed: float64(time.Since(t.start).Microseconds()) / 1000,
+		Phase:   phase,
+		Message: fmt.Sprintf(format, args...),
+	}
+
+	t.mu.Lock()
+	t.events = append(t.events, event)
+	t.mu.Unlock()
+}
+
+// Events returns the recorded events in order
+func (t *Trace) Events() []TraceEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]TraceEvent{}, t.events...)
+}
+
+// clientTrace records the phases of an HTTP request to t
+func (t *Trace) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.Add(TracePhaseConnect, "Getting connection to %s", hostPort)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.Add(TracePhaseConnect, "Reusing connection to %s (idle %s)", info.Conn.RemoteAddr(), info.IdleTime)
+				return
+			}
+			t.Add(TracePhaseConnect, "Using new connection to %s", info.Conn.RemoteAddr())
+		},
+		DNSStart: func(info httptrace.DNSStartInfo) {
+			t.Add(TracePhaseDNS, "Resolving %s", info.Host)
+		},
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			if info.Err != nil {
+				t.Add(TracePhaseDNS, "Resolution failed: %v", info.Err)
+				return
+			}
+			addrs := make([]string, 0, len(info.Addrs))
+			for _, addr := range info.Addrs {
+				addrs = append(addrs, addr.String())
+			}
+			t.Add(TracePhaseDNS, "Resolved to %s", strings.Join(addrs, ", "))
+		},
+		ConnectStart: func(network, addr string) {
+			t.Add(TracePhaseConnect, "Dialing %s %s", network, addr)
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err != nil {
+				t.Add(TracePhaseConnect, "Dial %s %s failed: %v", network, addr, err)
+				return
+			}
+			t.Add(TracePhaseConnect, "Connected to %s", addr)
+		},
+		TLSHandshakeStart: func() {
+			t.Add(TracePhaseTLS, "Starting TLS handshake")
+		},
+		TLSHandshakeDone: func(state tls.ConnectionState, err error) {
+			if err != nil {
+				t.Add(TracePhaseTLS, "Handshake failed: %v", err)
+				return
+			}
+			t.Add(TracePhaseTLS, "Negotiated %s with %s, ALPN %q, server name %q, resumed %v",
+				tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite),
+				state.NegotiatedProtocol, state.ServerName, state.DidResume)
+			for i, cert := range state.PeerCertificates {
+				t.Add(TracePhaseTLS, "Certificate %d: subject %q, issuer %q, DNS names %v, valid %s to %s",
+					i, cert.Subject.String(), cert.Issuer.String(), cert.DNSNames,
+					cert.NotBefore.UTC().Format(time.RFC3339), cert.NotAfter.UTC().Format(time.RFC3339))
+			}
+		},
+		WroteHeaderField: func(key string, value []string) {
+			if sensitiveHeaders[textproto.CanonicalMIMEHeaderKey(key)] {
+				value = []string{"[hidden]"}
+			}
+			t.Add(TracePhaseRequest, "%s: %s", key, strings.Join(value, ", "))
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err != nil {
+				t.Add(TracePhaseRequest, "Writing request failed: %v", info.Err)
+				return
+			}
+			t.Add(TracePhaseRequest, "Request sent")
+		},
+		Got1xxResponse: func(code int, _ textproto.MIMEHeader) error {
+			t.Add(TracePhaseResponse, "Informational response %d", code)
+			return nil
+		},
+		GotFirstResponseByte: func() {
+			t.Add(TracePhaseResponse, "First response byte received")
+		},
+	}
+}
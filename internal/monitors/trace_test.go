@@ -0,0 +1,110 @@
+package monitors
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// traceMessages returns the messages of a trace's events in one phase
+func traceMessages(trace *Trace, phase string) []string {
+	var messages []string
+	for _, event := range trace.Events() {
+		if event.Phase == phase {
+			messages = append(messages, event.Message)
+		}
+	}
+	return messages
+}
+
+func TestTraceNil(t *testing.T) {
+	var trace *Trace
+	trace.Add(TracePhaseCheck, "ignored")
+	if traceFrom(context.Background()) != nil {
+		t.Error("expected no trace on a plain context")
+	}
+}
+
+func TestHTTPMonitorTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		w.Header().Set("X-Served-By", "test")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	monitor, err := NewHTTPMonitor(&models.Monitor{
+		Type:    models.MonitorTypeHTTP,
+		Name:    "traced",
+		URL:     server.URL + "/old",
+		Headers: map[string]string{"Authorization": "Bearer secret"},
+		Timeout: models.Duration(5 * time.Second),
+	}, "test-group", nil, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPMonitor failed: %v", err)
+	}
+
+	trace := NewTrace()
+	result, err := monitor.Check(WithTrace(context.Background(), trace))
+	if err != nil || result.Status != models.StatusUp {
+		t.Fatalf("Check failed: %v %+v", err, result)
+	}
+
+	for _, phase := range []string{TracePhaseConnect, TracePhaseRequest, TracePhaseResponse, TracePhaseRedirect} {
+		if len(traceMessages(trace, phase)) == 0 {
+			t.Errorf("expected %s events, got %+v", phase, trace.Events())
+		}
+	}
+	if redirects := traceMessages(trace, TracePhaseRedirect); !strings.HasSuffix(redirects[0], "/new") {
+		t.Errorf("redirect event = %q", redirects[0])
+	}
+	if !strings.Contains(strings.Join(traceMessages(trace, TracePhaseResponse), "\n"), "X-Served-By: test") {
+		t.Errorf("expected response headers in trace, got %v", traceMessages(trace, TracePhaseResponse))
+	}
+	request := strings.Join(traceMessages(trace, TracePhaseRequest), "\n")
+	if strings.Contains(request, "secret") || !strings.Contains(request, "Authorization: [hidden]") {
+		t.Errorf("expected the Authorization value to be hidden, got %s", request)
+	}
+}
+
+func TestHTTPMonitorTraceResolverAndTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// The test certificate is not trusted, so the handshake fails after the
+	// hosts override is used
+	serverURL, _ := url.Parse(server.URL)
+	monitor, err := NewHTTPMonitor(&models.Monitor{
+		Type:    models.MonitorTypeHTTP,
+		Name:    "traced",
+		URL:     "https://example.test:" + serverURL.Port(),
+		Hosts:   map[string]string{"example.test": "127.0.0.1"},
+		Timeout: models.Duration(5 * time.Second),
+	}, "test-group", nil, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPMonitor failed: %v", err)
+	}
+
+	trace := NewTrace()
+	result, _ := monitor.Check(WithTrace(context.Background(), trace))
+	if result.Status != models.StatusDown {
+		t.Fatalf("expected the untrusted certificate to fail the check")
+	}
+
+	if dns := traceMessages(trace, TracePhaseDNS); len(dns) != 1 || dns[0] != "Using hosts override 127.0.0.1 for example.test" {
+		t.Errorf("dns events = %v", dns)
+	}
+	tls := traceMessages(trace, TracePhaseTLS)
+	if len(tls) != 2 || !strings.HasPrefix(tls[1], "Handshake failed") {
+		t.Errorf("tls events = %v", tls)
+	}
+}
@@ -0,0 +1,196 @@
+package monitors
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"github.com/tetratelabs/wazero/experimental"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+	"github.com/tetratelabs/wazero/sys"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	// defaultWasmMemoryMB caps module memory when limits.memoryMB is unset
+	defaultWasmMemoryMB = 64
+
+	// wasmPageSize is the unit WebAssembly memory grows in
+	wasmPageSize = 64 << 10
+)
+
+// wasmMagic starts every WebAssembly binary module
+var wasmMagic = []byte("\x00asm")
+
+// errOutOfFuel stops a module that used up limits.fuel
+var errOutOfFuel = errors.New("module ran out of fuel (limits.fuel)")
+
+// WasmMonitor checks by running a WebAssembly module in process with wazero.
+// The module is compiled once, when the monitor is created, and each check
+// runs a fresh instance of it as a WASI command. Modules speak the plugin
+// protocol over stdin and stdout but get no filesystem, network, or
+// environment access beyond what the protocol passes.
+type WasmMonitor struct {
+	*BaseMonitor
+	module   string
+	fuel     uint64
+	runtime  wazero.Runtime
+	compiled wazero.CompiledModule
+}
+
+// NewWasmMonitor creates a new wasm monitor and compiles its module
+func NewWasmMonitor(config *models.Monitor, group string, logger *logging.Logger, metrics *metrics.Metrics) (*WasmMonitor, error) {
+	module, err := filepath.Abs(config.Module)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module: %w", err)
+	}
+	code, err := readWasmModule(module)
+	if err != nil {
+		return nil, err
+	}
+
+	memoryMB := defaultWasmMemoryMB
+	var fuel uint64
+	if limits := config.Limits; limits != nil {
+		if limits.MemoryMB > 0 {
+			memoryMB = limits.MemoryMB
+		}
+		fuel = limits.Fuel
+	}
+
+	// Functions are only metered when compiled with the listener in place
+	ctx := context.Background()
+	if fuel > 0 {
+		ctx = experimental.WithFunctionListenerFactory(ctx, fuelMeter{})
+	}
+
+	// Closing on context done stops a module at its timeout even inside a
+	// loop that makes no calls
+	runtime := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+		WithMemoryLimitPages(uint32(memoryMB<<20/wasmPageSize)).
+		WithCloseOnContextDone(true))
+	if _, err := wasi_snapshot_preview1.Instantiate(ctx, runtime); err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("failed to start wasm runtime: %w", err)
+	}
+	compiled, err := runtime.CompileModule(ctx, code)
+	if err != nil {
+		runtime.Close(ctx)
+		return nil, fmt.Errorf("invalid module: %w", err)
+	}
+
+	return &WasmMonitor{
+		BaseMonitor: NewBaseMonitor(config, group, logger, metrics),
+		module:      module,
+		fuel:        fuel,
+		runtime:     runtime,
+		compiled:    compiled,
+	}, nil
+}
+
+// readWasmModule reads path and verifies that it is a binary WebAssembly
+// module
+func readWasmModule(path string) ([]byte, error) {
+	code, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid module: %w", err)
+	}
+	if !bytes.HasPrefix(code, wasmMagic) {
+		return nil, fmt.Errorf("invalid module: %s is not a WebAssembly binary", path)
+	}
+	return code, nil
+}
+
+// Check runs the module once and converts its response to a result
+func (w *WasmMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
+	return checkPlugin(ctx, w.BaseMonitor, w.module, w.start), nil
+}
+
+// start runs a new instance of the module to completion. Only the protocol
+// version is set in its environment, and besides stdio it gets nothing but
+// clocks and random numbers.
+func (w *WasmMonitor) start(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+	ctx, stop := context.WithCancelCause(ctx)
+	defer stop(nil)
+	if w.fuel > 0 {
+		ctx = context.WithValue(ctx, fuelKey{}, &fuelTank{remaining: w.fuel, empty: stop})
+	}
+
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithArgs(filepath.Base(w.module)).
+		WithEnv("HALLMONITOR_PLUGIN_PROTOCOL", strconv.Itoa(PluginProtocolVersion)).
+		WithStdin(stdin).
+		WithStdout(stdout).
+		WithStderr(stderr).
+		WithSysWalltime().
+		WithSysNanotime().
+		WithRandSource(rand.Reader)
+
+	instance, err := w.runtime.InstantiateModule(ctx, w.compiled, config)
+	if instance != nil {
+		instance.Close(ctx)
+	}
+	if cause := context.Cause(ctx); errors.Is(cause, errOutOfFuel) {
+		return 0, cause
+	}
+
+	var exitErr *sys.ExitError
+	if errors.As(err, &exitErr) {
+		return int(exitErr.ExitCode()), nil
+	}
+	return 0, err
+}
+
+// Validate checks that the module is set
+func (w *WasmMonitor) Validate() error {
+	if w.Config.Module == "" {
+		return fmt.Errorf("wasm monitor requires module")
+	}
+	return nil
+}
+
+// fuelKey holds the fuelTank of a running instance in its context
+type fuelKey struct{}
+
+// fuelTank is the fuel an instance has left
+type fuelTank struct {
+	remaining uint64
+	empty     context.CancelCauseFunc
+}
+
+// fuelMeter charges one unit of fuel for every function call a module makes
+// and stops the instance when its tank is empty. Calls are what wazero lets
+// the host observe; a loop that makes none is bounded by the timeout instead.
+type fuelMeter struct{}
+
+func (fuelMeter) NewFunctionListener(api.FunctionDefinition) experimental.FunctionListener {
+	return fuelMeter{}
+}
+
+func (fuelMeter) Before(ctx context.Context, _ api.Module, _ api.FunctionDefinition, _ []uint64, _ experimental.StackIterator) {
+	tank, ok := ctx.Value(fuelKey{}).(*fuelTank)
+	if !ok {
+		return
+	}
+	if tank.remaining == 0 {
+		tank.empty(errOutOfFuel)
+		return
+	}
+	tank.remaining--
+}
+
+func (fuelMeter) After(context.Context, api.Module, api.FunctionDefinition, []uint64) {}
+
+func (fuelMeter) Abort(context.Context, api.Module, api.FunctionDefinition, error) {}
@@ -0,0 +1,229 @@
+package monitors
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// The modules below are assembled by hand so the tests need no toolchain.
+// Each is a WASI command whose _start function does the work.
+
+// wasmHeader starts a binary module of version 1
+var wasmHeader = []byte("\x00asm\x01\x00\x00\x00")
+
+// wasmULEB encodes n as unsigned LEB128
+func wasmULEB(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n & 0x7f)
+		n >>= 7
+		if n == 0 {
+			return append(out, b)
+		}
+		out = append(out, b|0x80)
+	}
+}
+
+// wasmBytes encodes a length-prefixed byte vector, such as a name
+func wasmBytes(data []byte) []byte {
+	return append(wasmULEB(len(data)), data...)
+}
+
+// wasmSection encodes a section
+func wasmSection(id byte, content ...[]byte) []byte {
+	var body []byte
+	for _, part := range content {
+		body = append(body, part...)
+	}
+	return append([]byte{id}, wasmBytes(body)...)
+}
+
+// wasmModule joins sections into a module
+func wasmModule(sections ...[]byte) []byte {
+	module := append([]byte{}, wasmHeader...)
+	for _, section := range sections {
+		module = append(module, section...)
+	}
+	return module
+}
+
+// respondingWasm returns a module that writes response to stdout
+func respondingWasm(response string) []byte {
+	// Memory holds an iovec pointing at the response, room for the count
+	// fd_write returns, then the response itself
+	memory := binary.LittleEndian.AppendUint32(nil, 16)
+	memory = binary.LittleEndian.AppendUint32(memory, uint32(len(response)))
+	memory = append(memory, make([]byte, 8)...)
+	memory = append(memory, response...)
+
+	return wasmModule(
+		wasmSection(1, []byte{2,
+			0x60, 4, 0x7f, 0x7f, 0x7f, 0x7f, 1, 0x7f, // fd_write(i32, i32, i32, i32) i32
+			0x60, 0, 0, // _start()
+		}),
+		wasmSection(2, []byte{1}, wasmBytes([]byte("wasi_snapshot_preview1")), wasmBytes([]byte("fd_write")), []byte{0x00, 0}),
+		wasmSection(3, []byte{1, 1}),
+		wasmSection(5, []byte{1, 0x00, 1}),
+		wasmSection(7, []byte{2}, wasmBytes([]byte("memory")), []byte{0x02, 0}, wasmBytes([]byte("_start")), []byte{0x00, 1}),
+		wasmSection(10, []byte{1}, wasmBytes([]byte{
+			0,                                  // no locals
+			0x41, 1, 0x41, 0, 0x41, 1, 0x41, 8, // fd 1, iovec at 0, 1 iovec, count at 8
+			0x10, 0, // call fd_write
+			0x1a, // drop
+			0x0b,
+		})),
+		wasmSection(11, []byte{1, 0x00, 0x41, 0, 0x0b}, wasmBytes(memory)),
+	)
+}
+
+// callingWasm returns a module that calls an empty function forever
+func callingWasm() []byte {
+	return wasmModule(
+		wasmSection(1, []byte{1, 0x60, 0, 0}),
+		wasmSection(3, []byte{2, 0, 0}),
+		wasmSection(7, []byte{1}, wasmBytes([]byte("_start")), []byte{0x00, 0}),
+		wasmSection(10, []byte{2},
+			wasmBytes([]byte{0, 0x03, 0x40, 0x10, 1, 0x0c, 0, 0x0b, 0x0b}), // loop: call 1, br 0
+			wasmBytes([]byte{0, 0x0b}),
+		),
+	)
+}
+
+// spinningWasm returns a module that loops forever without calling anything
+func spinningWasm() []byte {
+	return wasmModule(
+		wasmSection(1, []byte{1, 0x60, 0, 0}),
+		wasmSection(3, []byte{1, 0}),
+		wasmSection(7, []byte{1}, wasmBytes([]byte("_start")), []byte{0x00, 0}),
+		wasmSection(10, []byte{1}, wasmBytes([]byte{0, 0x03, 0x40, 0x0c, 0, 0x0b, 0x0b})), // loop: br 0
+	)
+}
+
+// memoryWasm returns a module that needs pages of memory to start
+func memoryWasm(pages int) []byte {
+	return wasmModule(wasmSection(5, []byte{1, 0x00}, wasmULEB(pages)))
+}
+
+// writeWasmModule writes module to a file and returns its path
+func writeWasmModule(t *testing.T, module []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "check.wasm")
+	if err := os.WriteFile(path, module, 0644); err != nil {
+		t.Fatalf("failed to write module: %v", err)
+	}
+	return path
+}
+
+func TestWasmMonitorCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		module     []byte
+		limits     *models.WasmLimits
+		timeout    time.Duration
+		wantStatus models.MonitorStatus
+		wantError  string
+		wantAnswer string
+	}{
+		{
+			name:       "up",
+			module:     respondingWasm(`{"status":"up","metadata":{"answer":"42"}}`),
+			wantStatus: models.StatusUp,
+			wantAnswer: "42",
+		},
+		{
+			name:       "down",
+			module:     respondingWasm(`{"status":"down","error":"threshold exceeded"}`),
+			wantStatus: models.StatusDown,
+			wantError:  "threshold exceeded",
+		},
+		{
+			name:       "within fuel",
+			module:     respondingWasm(`{"status":"up"}`),
+			limits:     &models.WasmLimits{Fuel: 10},
+			wantStatus: models.StatusUp,
+		},
+		{
+			name:       "out of fuel",
+			module:     callingWasm(),
+			limits:     &models.WasmLimits{Fuel: 1000},
+			wantStatus: models.StatusDown,
+			wantError:  "ran out of fuel",
+		},
+		{
+			name:       "timeout",
+			module:     spinningWasm(),
+			timeout:    200 * time.Millisecond,
+			wantStatus: models.StatusDown,
+			wantError:  "did not finish",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &models.Monitor{
+				Name:   "wasm-test",
+				Type:   models.MonitorTypeWasm,
+				Module: writeWasmModule(t, tt.module),
+				Limits: tt.limits,
+			}
+			monitor, err := NewWasmMonitor(config, "test-group", nil, nil)
+			if err != nil {
+				t.Fatalf("NewWasmMonitor failed: %v", err)
+			}
+
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			result, err := monitor.Check(ctx)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s (error %q)", result.Status, tt.wantStatus, result.Error)
+			}
+			if !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("error = %q, want %q", result.Error, tt.wantError)
+			}
+			if tt.wantAnswer != "" {
+				metadata, _ := result.Metadata.(map[string]interface{})
+				if metadata["answer"] != tt.wantAnswer {
+					t.Errorf("metadata = %v, want answer %q", result.Metadata, tt.wantAnswer)
+				}
+			}
+		})
+	}
+}
+
+func TestNewWasmMonitorErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		module  string
+		limits  *models.WasmLimits
+		wantErr string
+	}{
+		{name: "missing module", module: filepath.Join(t.TempDir(), "missing.wasm"), wantErr: "invalid module"},
+		{name: "text module", module: writeWasmModule(t, []byte("(module)")), wantErr: "not a WebAssembly binary"},
+		{name: "over memory limit", module: writeWasmModule(t, memoryWasm(32)), limits: &models.WasmLimits{MemoryMB: 1}, wantErr: "invalid module"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &models.Monitor{Name: "wasm-test", Type: models.MonitorTypeWasm, Module: tt.module, Limits: tt.limits}
+			_, err := NewWasmMonitor(config, "test-group", nil, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
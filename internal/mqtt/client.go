@@ -0,0 +1,377 @@
+// Package mqtt publishes monitor states to an MQTT broker using a minimal
+// MQTT 3.1.1 client that supports connecting, publishing and keepalives.
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MQTT 3.1.1 control packet types
+const (
+	packetConnect    byte = 1
+	packetConnack    byte = 2
+	packetPublish    byte = 3
+	packetPuback     byte = 4
+	packetPingreq    byte = 12
+	packetPingresp   byte = 13
+	packetDisconnect byte = 14
+)
+
+// Connect flags
+const (
+	flagCleanSession byte = 0x02
+	flagWill         byte = 0x04
+	flagWillRetain   byte = 0x20
+	flagPassword     byte = 0x40
+	flagUsername     byte = 0x80
+)
+
+// maxRemainingLength is the largest remaining length the protocol can encode
+const maxRemainingLength = 268435455
+
+// Message is an application message to publish
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// ClientOptions configures a broker connection
+type ClientOptions struct {
+	Broker    string // tcp://host:1883, ssl://host:8883
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+	Timeout   time.Duration
+	Will      *Message // Published by the broker if the connection drops
+}
+
+// Client is a publish-only MQTT 3.1.1 client. Packets that expect a reply
+// (CONNECT, QoS 1 PUBLISH, PINGREQ) are exchanged synchronously.
+type Client struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	timeout time.Duration
+	nextID  uint16
+	mu      sync.Mutex
+}
+
+// packet is a decoded control packet
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+// Dial connects to the broker and performs the CONNECT handshake
+func Dial(ctx context.Context, opts ClientOptions) (*Client, error) {
+	address, useTLS, host, err := parseBroker(opts.Broker)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if useTLS {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to broker %s: %w", address, err)
+	}
+
+	c := &Client{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		timeout: timeout,
+	}
+
+	if err := c.connect(opts); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// connect sends CONNECT and waits for a successful CONNACK
+func (c *Client) connect(opts ClientOptions) error {
+	flags := flagCleanSession
+	var payload []byte
+	payload = appendString(payload, opts.ClientID)
+	if opts.Will != nil {
+		flags |= flagWill | opts.Will.QoS<<3
+		if opts.Will.Retain {
+			flags |= flagWillRetain
+		}
+		payload = appendString(payload, opts.Will.Topic)
+		payload = appendString(payload, string(opts.Will.Payload))
+	}
+	if opts.Username != "" {
+		flags |= flagUsername
+		payload = appendString(payload, opts.Username)
+		if opts.Password != "" {
+			flags |= flagPassword
+			payload = appendString(payload, opts.Password)
+		}
+	}
+
+	keepAlive := opts.KeepAlive / time.Second
+	if keepAlive > 0xFFFF {
+		keepAlive = 0xFFFF
+	}
+
+	var body []byte
+	body = appendString(body, "MQTT")
+	body = append(body, 4, flags) // protocol level 4 (3.1.1)
+	body = binary.BigEndian.AppendUint16(body, uint16(keepAlive))
+	body = append(body, payload...)
+
+	if err := c.write(packetConnect<<4|0x00, body); err != nil {
+		return fmt.Errorf("failed to send connect: %w", err)
+	}
+
+	p, err := c.read()
+	if err != nil {
+		return fmt.Errorf("failed to read connack: %w", err)
+	}
+	if p.kind != packetConnack || len(p.body) != 2 {
+		return fmt.Errorf("unexpected packet type %d waiting for connack", p.kind)
+	}
+	if code := p.body[1]; code != 0 {
+		return fmt.Errorf("broker refused connection: %s", connackReason(code))
+	}
+
+	return nil
+}
+
+// Publish sends a message, waiting for PUBACK when QoS is 1
+func (c *Client) Publish(msg Message) error {
+	if msg.QoS > 1 {
+		return fmt.Errorf("unsupported QoS %d", msg.QoS)
+	}
+	if msg.Topic == "" || strings.ContainsAny(msg.Topic, "+#") {
+		return fmt.Errorf("invalid topic %q", msg.Topic)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	header := packetPublish<<4 | msg.QoS<<1
+	if msg.Retain {
+		header |= 0x01
+	}
+
+	var body []byte
+	body = appendString(body, msg.Topic)
+
+	var id uint16
+	if msg.QoS > 0 {
+		c.nextID++
+		if c.nextID == 0 {
+			c.nextID = 1
+		}
+		id = c.nextID
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	body = append(body, msg.Payload...)
+
+	if err := c.write(header, body); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", msg.Topic, err)
+	}
+
+	if msg.QoS == 0 {
+		return nil
+	}
+
+	for {
+		p, err := c.read()
+		if err != nil {
+			return fmt.Errorf("failed to read puback: %w", err)
+		}
+		if p.kind == packetPuback && len(p.body) >= 2 && binary.BigEndian.Uint16(p.body) == id {
+			return nil
+		}
+	}
+}
+
+// Ping sends PINGREQ and waits for PINGRESP
+func (c *Client) Ping() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.write(packetPingreq<<4, nil); err != nil {
+		return fmt.Errorf("failed to send ping: %w", err)
+	}
+
+	for {
+		p, err := c.read()
+		if err != nil {
+			return fmt.Errorf("failed to read ping response: %w", err)
+		}
+		if p.kind == packetPingresp {
+			return nil
+		}
+	}
+}
+
+// Close sends DISCONNECT and closes the connection
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_ = c.write(packetDisconnect<<4, nil)
+	return c.conn.Close()
+}
+
+// write sends a control packet with the given fixed header byte
+func (c *Client) write(header byte, body []byte) error {
+	if len(body) > maxRemainingLength {
+		return fmt.Errorf("packet too large: %d bytes", len(body))
+	}
+
+	buf := make([]byte, 0, len(body)+5)
+	buf = append(buf, header)
+	buf = appendRemainingLength(buf, len(body))
+	buf = append(buf, body...)
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return err
+	}
+	_, err := c.conn.Write(buf)
+	return err
+}
+
+// read reads the next control packet from the broker
+func (c *Client) read() (*packet, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, err
+	}
+	return readPacket(c.reader)
+}
+
+// readPacket decodes a single control packet
+func readPacket(r *bufio.Reader) (*packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+
+	length := 0
+	multiplier := 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return nil, errors.New("malformed remaining length")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		length += int(b&0x7F) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	return &packet{kind: header >> 4, flags: header & 0x0F, body: body}, nil
+}
+
+// appendRemainingLength encodes the variable-length remaining length field
+func appendRemainingLength(buf []byte, length int) []byte {
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if length == 0 {
+			return buf
+		}
+	}
+}
+
+// appendString encodes a length-prefixed UTF-8 string
+func appendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+// parseBroker resolves a broker URL into a dial address and TLS flag
+func parseBroker(broker string) (address string, useTLS bool, host string, err error) {
+	if broker == "" {
+		return "", false, "", errors.New("broker is required")
+	}
+	if !strings.Contains(broker, "://") {
+		broker = "tcp://" + broker
+	}
+
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", false, "", fmt.Errorf("invalid broker URL: %w", err)
+	}
+
+	port := "1883"
+	switch strings.ToLower(u.Scheme) {
+	case "tcp", "mqtt":
+	case "ssl", "tls", "mqtts":
+		useTLS = true
+		port = "8883"
+	default:
+		return "", false, "", fmt.Errorf("unsupported broker scheme: %s", u.Scheme)
+	}
+
+	host = u.Hostname()
+	if host == "" {
+		return "", false, "", fmt.Errorf("broker URL missing host: %s", broker)
+	}
+	if u.Port() != "" {
+		port = u.Port()
+	}
+
+	return net.JoinHostPort(host, port), useTLS, host, nil
+}
+
+// connackReason describes a CONNACK return code
+func connackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad username or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("return code %d", code)
+	}
+}
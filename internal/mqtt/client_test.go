@@ -0,0 +1,239 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testBroker is a minimal in-process broker that acknowledges packets and
+// records published messages
+type testBroker struct {
+	listener net.Listener
+	connack  byte
+
+	mu        sync.Mutex
+	connects  [][]byte
+	published []Message
+}
+
+func newTestBroker(t *testing.T) *testBroker {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	b := &testBroker{listener: listener}
+	go b.serve()
+	t.Cleanup(func() { listener.Close() })
+	return b
+}
+
+func (b *testBroker) address() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *testBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go b.handle(conn)
+	}
+}
+
+func (b *testBroker) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		p, err := readPacket(reader)
+		if err != nil {
+			return
+		}
+
+		switch p.kind {
+		case packetConnect:
+			b.mu.Lock()
+			b.connects = append(b.connects, p.body)
+			b.mu.Unlock()
+			conn.Write([]byte{packetConnack << 4, 2, 0, b.connack})
+		case packetPublish:
+			b.record(conn, p)
+		case packetPingreq:
+			conn.Write([]byte{packetPingresp << 4, 0})
+		case packetDisconnect:
+			return
+		}
+	}
+}
+
+func (b *testBroker) record(conn net.Conn, p *packet) {
+	topicLen := int(binary.BigEndian.Uint16(p.body))
+	msg := Message{
+		Topic:  string(p.body[2 : 2+topicLen]),
+		QoS:    (p.flags >> 1) & 0x03,
+		Retain: p.flags&0x01 == 1,
+	}
+	rest := p.body[2+topicLen:]
+	if msg.QoS > 0 {
+		conn.Write([]byte{packetPuback << 4, 2, rest[0], rest[1]})
+		rest = rest[2:]
+	}
+	msg.Payload = append([]byte(nil), rest...)
+
+	b.mu.Lock()
+	b.published = append(b.published, msg)
+	b.mu.Unlock()
+}
+
+func (b *testBroker) messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.published...)
+}
+
+func TestClientPublish(t *testing.T) {
+	broker := newTestBroker(t)
+
+	client, err := Dial(context.Background(), ClientOptions{
+		Broker:    broker.address(),
+		ClientID:  "test",
+		Username:  "user",
+		Password:  "pass",
+		KeepAlive: time.Minute,
+		Timeout:   time.Second,
+	})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Publish(Message{Topic: "a/b", Payload: []byte("up"), QoS: 1, Retain: true}); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if err := client.Publish(Message{Topic: "a/c", Payload: []byte("down")}); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	if err := client.Ping(); err != nil {
+		t.Fatalf("ping failed: %v", err)
+	}
+
+	msgs := broker.messages()
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(msgs))
+	}
+	if msgs[0].Topic != "a/b" || string(msgs[0].Payload) != "up" || !msgs[0].Retain || msgs[0].QoS != 1 {
+		t.Errorf("unexpected first message: %+v", msgs[0])
+	}
+	if msgs[1].Topic != "a/c" || string(msgs[1].Payload) != "down" || msgs[1].Retain {
+		t.Errorf("unexpected second message: %+v", msgs[1])
+	}
+}
+
+func TestClientConnectSendsWill(t *testing.T) {
+	broker := newTestBroker(t)
+
+	client, err := Dial(context.Background(), ClientOptions{
+		Broker:   broker.address(),
+		ClientID: "test",
+		Timeout:  time.Second,
+		Will:     &Message{Topic: "hm/status", Payload: []byte("offline"), Retain: true},
+	})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer client.Close()
+
+	broker.mu.Lock()
+	connect := broker.connects[0]
+	broker.mu.Unlock()
+
+	// Variable header: protocol name (6 bytes), level, flags
+	flags := connect[7]
+	if flags&flagWill == 0 || flags&flagWillRetain == 0 {
+		t.Errorf("expected will flags to be set, got %08b", flags)
+	}
+	if !bytes.Contains(connect, []byte("hm/status")) || !bytes.Contains(connect, []byte("offline")) {
+		t.Error("expected will topic and payload in connect packet")
+	}
+}
+
+func TestClientConnectRefused(t *testing.T) {
+	broker := newTestBroker(t)
+	broker.connack = 5
+
+	_, err := Dial(context.Background(), ClientOptions{Broker: broker.address(), ClientID: "test", Timeout: time.Second})
+	if err == nil {
+		t.Fatal("expected connection to be refused")
+	}
+}
+
+func TestClientPublishRejectsWildcardTopics(t *testing.T) {
+	broker := newTestBroker(t)
+
+	client, err := Dial(context.Background(), ClientOptions{Broker: broker.address(), ClientID: "test", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	defer client.Close()
+
+	if err := client.Publish(Message{Topic: "a/#"}); err == nil {
+		t.Error("expected wildcard topic to be rejected")
+	}
+}
+
+func TestParseBroker(t *testing.T) {
+	tests := []struct {
+		broker  string
+		address string
+		useTLS  bool
+		wantErr bool
+	}{
+		{broker: "tcp://localhost", address: "localhost:1883"},
+		{broker: "mqtt://10.0.0.1:1884", address: "10.0.0.1:1884"},
+		{broker: "ssl://broker.example.com", address: "broker.example.com:8883", useTLS: true},
+		{broker: "localhost:1883", address: "localhost:1883"},
+		{broker: "http://localhost", wantErr: true},
+		{broker: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.broker, func(t *testing.T) {
+			address, useTLS, _, err := parseBroker(tt.broker)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseBroker() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if address != tt.address || useTLS != tt.useTLS {
+				t.Errorf("parseBroker() = %s, %v; want %s, %v", address, useTLS, tt.address, tt.useTLS)
+			}
+		})
+	}
+}
+
+func TestRemainingLengthRoundTrip(t *testing.T) {
+	for _, length := range []int{0, 127, 128, 16383, 16384, 2097152} {
+		buf := []byte{packetPublish << 4}
+		buf = appendRemainingLength(buf, length)
+		buf = append(buf, make([]byte, length)...)
+
+		p, err := readPacket(bufio.NewReader(bytes.NewReader(buf)))
+		if err != nil {
+			t.Fatalf("length %d: readPacket failed: %v", length, err)
+		}
+		if len(p.body) != length {
+			t.Errorf("length %d: decoded %d", length, len(p.body))
+		}
+	}
+}
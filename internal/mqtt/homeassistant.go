@@ -0,0 +1,126 @@
+package mqtt
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// defaultDiscoveryPrefix is Home Assistant's default MQTT discovery prefix
+const defaultDiscoveryPrefix = "homeassistant"
+
+// discoveryConfig is a Home Assistant MQTT discovery payload for a binary_sensor
+type discoveryConfig struct {
+	Name                string          `json:"name"`
+	UniqueID            string          `json:"unique_id"`
+	ObjectID            string          `json:"object_id"`
+	DeviceClass         string          `json:"device_class"`
+	StateTopic          string          `json:"state_topic"`
+	PayloadOn           string          `json:"payload_on"`
+	PayloadOff          string          `json:"payload_off"`
+	JSONAttributesTopic string          `json:"json_attributes_topic"`
+	AvailabilityTopic   string          `json:"availability_topic"`
+	Device              discoveryDevice `json:"device"`
+}
+
+// discoveryDevice groups all monitor entities under a single Home Assistant device
+type discoveryDevice struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer"`
+	Model        string   `json:"model"`
+}
+
+// monitorAttributes is published to the JSON attributes topic after each check
+type monitorAttributes struct {
+	Monitor   string    `json:"monitor"`
+	Group     string    `json:"group"`
+	Type      string    `json:"type"`
+	LatencyMS float64   `json:"latency_ms"`
+	LastError string    `json:"last_error"`
+	LastCheck time.Time `json:"last_check"`
+}
+
+// AvailabilityTopic returns the topic carrying the publisher's online/offline state
+func (p *Publisher) AvailabilityTopic() string {
+	return p.topicPrefix + "/status"
+}
+
+// AttributesTopic returns the JSON attributes topic for a monitor
+func (p *Publisher) AttributesTopic(group, monitor string) string {
+	return p.topicPrefix + "/" + topicSegment(group) + "/" + topicSegment(monitor) + "/attributes"
+}
+
+// DiscoveryTopic returns the Home Assistant discovery config topic for a monitor
+func (p *Publisher) DiscoveryTopic(group, monitor string) string {
+	return p.discoveryPrefix + "/binary_sensor/" + objectID(p.topicPrefix) + "/" + objectID(group+"_"+monitor) + "/config"
+}
+
+// discoveryMessage builds the retained discovery config for a monitor
+func (p *Publisher) discoveryMessage(result *models.MonitorResult) (Message, error) {
+	id := objectID(p.topicPrefix + "_" + result.Group + "_" + result.Monitor)
+	payload, err := json.Marshal(discoveryConfig{
+		Name:                result.Monitor,
+		UniqueID:            id,
+		ObjectID:            id,
+		DeviceClass:         "connectivity",
+		StateTopic:          p.StatusTopic(result.Group, result.Monitor),
+		PayloadOn:           string(models.StatusUp),
+		PayloadOff:          string(models.StatusDown),
+		JSONAttributesTopic: p.AttributesTopic(result.Group, result.Monitor),
+		AvailabilityTopic:   p.AvailabilityTopic(),
+		Device: discoveryDevice{
+			Identifiers:  []string{objectID(p.topicPrefix)},
+			Name:         "Hall Monitor",
+			Manufacturer: "Hall Monitor",
+			Model:        "hallmonitor",
+		},
+	})
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Topic:   p.DiscoveryTopic(result.Group, result.Monitor),
+		Payload: payload,
+		QoS:     p.qos,
+		Retain:  true,
+	}, nil
+}
+
+// attributesMessage builds the retained latency and last error attributes for a monitor
+func (p *Publisher) attributesMessage(result *models.MonitorResult) (Message, error) {
+	payload, err := json.Marshal(monitorAttributes{
+		Monitor:   result.Monitor,
+		Group:     result.Group,
+		Type:      string(result.Type),
+		LatencyMS: float64(result.Duration.Microseconds()) / 1000.0,
+		LastError: result.Error,
+		LastCheck: result.Timestamp,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		Topic:   p.AttributesTopic(result.Group, result.Monitor),
+		Payload: payload,
+		QoS:     p.qos,
+		Retain:  true,
+	}, nil
+}
+
+// objectID converts a name into a Home Assistant safe identifier
+func objectID(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,346 @@
+package mqtt
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	defaultClientID       = "hallmonitor"
+	defaultTopicPrefix    = "hallmonitor"
+	defaultKeepAlive      = 60 * time.Second
+	defaultConnectTimeout = 10 * time.Second
+)
+
+// Publisher pushes monitor status changes to retained MQTT topics of the form
+// {prefix}/{group}/{monitor}/status so subscribers always see the latest state.
+// With Home Assistant discovery enabled it also announces each monitor as a
+// binary_sensor and publishes latency and last error attributes.
+type Publisher struct {
+	options         ClientOptions
+	topicPrefix     string
+	qos             byte
+	homeAssistant   bool
+	discoveryPrefix string
+	logger          *logging.Logger
+
+	client     *Client
+	lastStatus map[string]models.MonitorStatus
+	pending    map[string]Message
+	retained   map[string]Message // Latest retained message per topic, replayed on reconnect
+	mu         sync.Mutex
+
+	notifyCh chan struct{}
+	stopCh   chan struct{}
+	wg       sync.WaitGroup
+	running  bool
+}
+
+// NewPublisher creates an MQTT publisher from configuration
+func NewPublisher(cfg config.MQTTConfig, logger *logging.Logger) *Publisher {
+	clientID := cfg.ClientID
+	if clientID == "" {
+		clientID = defaultClientID
+	}
+	topicPrefix := strings.Trim(cfg.TopicPrefix, "/")
+	if topicPrefix == "" {
+		topicPrefix = defaultTopicPrefix
+	}
+	keepAlive := cfg.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = defaultKeepAlive
+	}
+	timeout := cfg.ConnectTimeout
+	if timeout <= 0 {
+		timeout = defaultConnectTimeout
+	}
+	discoveryPrefix := strings.Trim(cfg.HomeAssistant.DiscoveryPrefix, "/")
+	if discoveryPrefix == "" {
+		discoveryPrefix = defaultDiscoveryPrefix
+	}
+
+	p := &Publisher{
+		options: ClientOptions{
+			Broker:    cfg.Broker,
+			ClientID:  clientID,
+			Username:  cfg.Username,
+			Password:  cfg.Password,
+			KeepAlive: keepAlive,
+			Timeout:   timeout,
+		},
+		topicPrefix:     topicPrefix,
+		qos:             byte(cfg.QoS),
+		homeAssistant:   cfg.HomeAssistant.Enabled,
+		discoveryPrefix: discoveryPrefix,
+		logger:          logger,
+		lastStatus:      make(map[string]models.MonitorStatus),
+		pending:         make(map[string]Message),
+		retained:        make(map[string]Message),
+		notifyCh:        make(chan struct{}, 1),
+		stopCh:          make(chan struct{}),
+	}
+
+	// The broker marks us offline if the connection drops without a DISCONNECT
+	will := p.availabilityMessage("offline")
+	p.options.Will = &will
+
+	return p
+}
+
+// HandleResult queues a retained status message when a monitor's status changes
+func (p *Publisher) HandleResult(result *models.MonitorResult) {
+	if result == nil {
+		return
+	}
+
+	topic := p.StatusTopic(result.Group, result.Monitor)
+
+	p.mu.Lock()
+	previous, seen := p.lastStatus[topic]
+	changed := !seen || previous != result.Status
+
+	if p.homeAssistant {
+		if !seen {
+			p.queueJSON(p.discoveryMessage(result))
+		}
+		p.queueJSON(p.attributesMessage(result))
+	}
+
+	if changed {
+		p.lastStatus[topic] = result.Status
+		p.queue(Message{
+			Topic:   topic,
+			Payload: []byte(result.Status),
+			QoS:     p.qos,
+			Retain:  true,
+		})
+	}
+	p.mu.Unlock()
+
+	if !changed && !p.homeAssistant {
+		return
+	}
+
+	select {
+	case p.notifyCh <- struct{}{}:
+	default:
+	}
+}
+
+// queue schedules a message for delivery; callers must hold p.mu
+func (p *Publisher) queue(msg Message) {
+	p.pending[msg.Topic] = msg
+	if msg.Retain {
+		p.retained[msg.Topic] = msg
+	}
+}
+
+// queueJSON queues an encoded message, logging encoding failures; callers must hold p.mu
+func (p *Publisher) queueJSON(msg Message, err error) {
+	if err != nil {
+		p.logger.WithComponent(logging.ComponentMQTT).
+			WithError(err).
+			Warn("Failed to encode MQTT message")
+		return
+	}
+	p.queue(msg)
+}
+
+// availabilityMessage builds the retained online/offline message
+func (p *Publisher) availabilityMessage(state string) Message {
+	return Message{
+		Topic:   p.AvailabilityTopic(),
+		Payload: []byte(state),
+		QoS:     p.qos,
+		Retain:  true,
+	}
+}
+
+// StatusTopic returns the status topic for a monitor
+func (p *Publisher) StatusTopic(group, monitor string) string {
+	return p.topicPrefix + "/" + topicSegment(group) + "/" + topicSegment(monitor) + "/status"
+}
+
+// Start begins the background publish loop
+func (p *Publisher) Start(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		return nil
+	}
+
+	p.logger.WithComponent(logging.ComponentMQTT).
+		WithFields(map[string]interface{}{
+			"broker":       p.options.Broker,
+			"topic_prefix": p.topicPrefix,
+		}).
+		Info("Starting MQTT publisher")
+
+	p.wg.Add(1)
+	go p.publishLoop(ctx)
+
+	p.running = true
+	return nil
+}
+
+// Stop publishes any pending messages and disconnects from the broker
+func (p *Publisher) Stop() error {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return nil
+	}
+	p.running = false
+	p.mu.Unlock()
+
+	p.logger.WithComponent(logging.ComponentMQTT).Info("Stopping MQTT publisher")
+	close(p.stopCh)
+	p.wg.Wait()
+	return nil
+}
+
+// publishLoop delivers pending messages and keeps the connection alive
+func (p *Publisher) publishLoop(ctx context.Context) {
+	defer p.wg.Done()
+	defer p.shutdown()
+
+	ticker := time.NewTicker(p.options.KeepAlive / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.flush(context.Background())
+			return
+		case <-p.stopCh:
+			p.flush(context.Background())
+			return
+		case <-p.notifyCh:
+			p.flush(ctx)
+		case <-ticker.C:
+			p.keepAlive(ctx)
+		}
+	}
+}
+
+// keepAlive pings the broker, or retries pending messages when disconnected
+func (p *Publisher) keepAlive(ctx context.Context) {
+	if p.client == nil {
+		p.flush(ctx)
+		return
+	}
+
+	if err := p.client.Ping(); err != nil {
+		p.logger.WithComponent(logging.ComponentMQTT).
+			WithError(err).
+			Warn("MQTT keepalive failed, reconnecting")
+		p.disconnect()
+		p.flush(ctx)
+	}
+}
+
+// flush publishes all pending messages, keeping failed ones for retry.
+// It connects first if needed, so it is also used to re-establish the session.
+func (p *Publisher) flush(ctx context.Context) {
+	p.mu.Lock()
+	batch := p.pending
+	p.pending = make(map[string]Message)
+	p.mu.Unlock()
+
+	var err error
+	if p.client == nil {
+		err = p.connect(ctx, batch)
+	} else if len(batch) == 0 {
+		return
+	}
+
+	for topic, msg := range batch {
+		if err == nil {
+			err = p.client.Publish(msg)
+			if err == nil {
+				delete(batch, topic)
+			}
+		}
+	}
+
+	if err == nil {
+		return
+	}
+
+	p.logger.WithComponent(logging.ComponentMQTT).
+		WithError(err).
+		WithFields(map[string]interface{}{
+			"broker":  p.options.Broker,
+			"pending": len(batch),
+		}).
+		Warn("Failed to publish MQTT messages")
+	p.disconnect()
+
+	// Requeue unsent messages unless a newer state arrived meanwhile
+	p.mu.Lock()
+	for topic, msg := range batch {
+		if _, ok := p.pending[topic]; !ok {
+			p.pending[topic] = msg
+		}
+	}
+	p.mu.Unlock()
+}
+
+// connect dials the broker, announces availability and adds retained state to
+// batch so a broker that lost its retained messages is brought up to date
+func (p *Publisher) connect(ctx context.Context, batch map[string]Message) error {
+	client, err := Dial(ctx, p.options)
+	if err != nil {
+		return err
+	}
+	p.client = client
+
+	p.logger.WithComponent(logging.ComponentMQTT).
+		WithFields(map[string]interface{}{"broker": p.options.Broker}).
+		Info("Connected to MQTT broker")
+
+	p.mu.Lock()
+	for topic, msg := range p.retained {
+		if _, ok := batch[topic]; !ok {
+			batch[topic] = msg
+		}
+	}
+	p.mu.Unlock()
+
+	return p.client.Publish(p.availabilityMessage("online"))
+}
+
+// shutdown marks the publisher offline and disconnects
+func (p *Publisher) shutdown() {
+	if p.client != nil {
+		if err := p.client.Publish(p.availabilityMessage("offline")); err != nil {
+			p.logger.WithComponent(logging.ComponentMQTT).
+				WithError(err).
+				Warn("Failed to publish offline availability")
+		}
+	}
+	p.disconnect()
+}
+
+// disconnect closes the broker connection if open
+func (p *Publisher) disconnect() {
+	if p.client != nil {
+		p.client.Close()
+		p.client = nil
+	}
+}
+
+// topicSegment makes a name safe for use as a single topic level
+func topicSegment(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return strings.NewReplacer("/", "_", "+", "_", "#", "_").Replace(name)
+}
@@ -0,0 +1,158 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func testLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	return logger
+}
+
+// waitForMessages waits until n messages were published to topic
+func waitForMessages(t *testing.T, broker *testBroker, topic string, n int) []Message {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var msgs []Message
+		for _, msg := range broker.messages() {
+			if msg.Topic == topic {
+				msgs = append(msgs, msg)
+			}
+		}
+		if len(msgs) >= n || time.Now().After(deadline) {
+			if len(msgs) < n {
+				t.Fatalf("expected %d messages on %s, got %d", n, topic, len(msgs))
+			}
+			return msgs
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestPublisherPublishesStatusChanges(t *testing.T) {
+	broker := newTestBroker(t)
+
+	publisher := NewPublisher(config.MQTTConfig{Broker: broker.address()}, testLogger(t))
+	if err := publisher.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start publisher: %v", err)
+	}
+	defer publisher.Stop()
+
+	result := func(status models.MonitorStatus) *models.MonitorResult {
+		return &models.MonitorResult{Monitor: "web/api", Group: "core", Status: status}
+	}
+
+	topic := "hallmonitor/core/web_api/status"
+
+	publisher.HandleResult(result(models.StatusUp))
+	waitForMessages(t, broker, topic, 1)
+
+	// Unchanged status must not republish
+	publisher.HandleResult(result(models.StatusUp))
+	publisher.HandleResult(result(models.StatusDown))
+	msgs := waitForMessages(t, broker, topic, 2)
+
+	if len(msgs) != 2 {
+		t.Fatalf("expected 2 status messages, got %d", len(msgs))
+	}
+	if !msgs[0].Retain {
+		t.Error("expected retained message")
+	}
+	if string(msgs[0].Payload) != "up" || string(msgs[1].Payload) != "down" {
+		t.Errorf("unexpected payloads: %q, %q", msgs[0].Payload, msgs[1].Payload)
+	}
+
+	availability := waitForMessages(t, broker, "hallmonitor/status", 1)
+	if string(availability[0].Payload) != "online" {
+		t.Errorf("expected online availability, got %q", availability[0].Payload)
+	}
+}
+
+func TestPublisherHomeAssistantDiscovery(t *testing.T) {
+	broker := newTestBroker(t)
+
+	publisher := NewPublisher(config.MQTTConfig{
+		Broker:        broker.address(),
+		HomeAssistant: config.HomeAssistantConfig{Enabled: true},
+	}, testLogger(t))
+	if err := publisher.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start publisher: %v", err)
+	}
+
+	publisher.HandleResult(&models.MonitorResult{
+		Monitor:  "Web API",
+		Group:    "core",
+		Type:     models.MonitorTypeHTTP,
+		Status:   models.StatusDown,
+		Duration: 1500 * time.Microsecond,
+		Error:    "connection refused",
+	})
+
+	discovery := waitForMessages(t, broker, "homeassistant/binary_sensor/hallmonitor/core_web_api/config", 1)
+	var cfg map[string]interface{}
+	if err := json.Unmarshal(discovery[0].Payload, &cfg); err != nil {
+		t.Fatalf("invalid discovery payload: %v", err)
+	}
+	if !discovery[0].Retain {
+		t.Error("expected discovery config to be retained")
+	}
+	if cfg["state_topic"] != "hallmonitor/core/Web API/status" {
+		t.Errorf("unexpected state_topic: %v", cfg["state_topic"])
+	}
+	if cfg["device_class"] != "connectivity" || cfg["payload_on"] != "up" || cfg["payload_off"] != "down" {
+		t.Errorf("unexpected binary_sensor mapping: %v", cfg)
+	}
+	if cfg["availability_topic"] != "hallmonitor/status" {
+		t.Errorf("unexpected availability_topic: %v", cfg["availability_topic"])
+	}
+
+	attributes := waitForMessages(t, broker, "hallmonitor/core/Web API/attributes", 1)
+	var attrs monitorAttributes
+	if err := json.Unmarshal(attributes[0].Payload, &attrs); err != nil {
+		t.Fatalf("invalid attributes payload: %v", err)
+	}
+	if attrs.LatencyMS != 1.5 || attrs.LastError != "connection refused" {
+		t.Errorf("unexpected attributes: %+v", attrs)
+	}
+
+	// Stopping publishes the offline availability state
+	publisher.Stop()
+	availability := waitForMessages(t, broker, "hallmonitor/status", 2)
+	if string(availability[len(availability)-1].Payload) != "offline" {
+		t.Errorf("expected offline availability, got %q", availability[len(availability)-1].Payload)
+	}
+}
+
+func TestPublisherKeepsMessagesWhenBrokerUnavailable(t *testing.T) {
+	publisher := NewPublisher(config.MQTTConfig{
+		Broker:         "tcp://127.0.0.1:1",
+		ConnectTimeout: 100 * time.Millisecond,
+	}, testLogger(t))
+
+	publisher.HandleResult(&models.MonitorResult{Monitor: "api", Group: "core", Status: models.StatusDown})
+	publisher.flush(context.Background())
+
+	if len(publisher.pending) != 1 {
+		t.Errorf("expected message to remain pending, got %d", len(publisher.pending))
+	}
+}
+
+func TestStatusTopicUsesPrefix(t *testing.T) {
+	publisher := NewPublisher(config.MQTTConfig{Broker: "tcp://localhost", TopicPrefix: "/home/monitors/"}, testLogger(t))
+
+	if topic := publisher.StatusTopic("", "dns#1"); topic != "home/monitors/_/dns_1/status" {
+		t.Errorf("unexpected topic: %s", topic)
+	}
+}
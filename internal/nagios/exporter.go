@@ -0,0 +1,247 @@
+// Package nagios submits monitor results to Nagios or Icinga as passive
+// service check results, through the external command file or NRDP.
+package nagios
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	defaultInterval = 30 * time.Second
+	defaultHost     = "hallmonitor"
+
+	// maxOutputLength keeps each command line well under the pipe buffer so
+	// writes to the command file are atomic
+	maxOutputLength = 1024
+)
+
+// Service states of the Nagios plugin API
+const (
+	StateOK       = 0
+	StateWarning  = 1
+	StateCritical = 2
+	StateUnknown  = 3
+)
+
+// CheckResult is a passive service check result
+type CheckResult struct {
+	Host      string
+	Service   string
+	State     int
+	Output    string // Plugin output, including any performance data
+	Timestamp time.Time
+}
+
+// submitter delivers check results to Nagios
+type submitter interface {
+	Submit(ctx context.Context, results []CheckResult) error
+	String() string
+}
+
+// Exporter submits the latest result of each monitor every interval as a
+// passive check of a service named after the monitor. Up is OK, down is
+// CRITICAL, and unknown is UNKNOWN.
+type Exporter struct {
+	submitter submitter
+	host      string
+	interval  time.Duration
+	logger    *logging.Logger
+
+	pending map[string]*models.MonitorResult
+	mu      sync.Mutex
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+}
+
+// NewExporter creates an exporter submitting through the command file or
+// NRDP, whichever is configured
+func NewExporter(cfg config.NagiosConfig, logger *logging.Logger) *Exporter {
+	var target submitter
+	if cfg.CommandFile != "" {
+		target = &commandFile{path: cfg.CommandFile}
+	} else {
+		target = newNRDPClient(cfg.NRDP)
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &Exporter{
+		submitter: target,
+		host:      cfg.Host,
+		interval:  interval,
+		logger:    logger,
+		pending:   make(map[string]*models.MonitorResult),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// HandleResult keeps the result to submit with the next batch
+func (e *Exporter) HandleResult(result *models.MonitorResult) {
+	if result == nil {
+		return
+	}
+
+	e.mu.Lock()
+	e.pending[result.Monitor] = result
+	e.mu.Unlock()
+}
+
+// Start submits batches every interval until stopped
+func (e *Exporter) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return nil
+	}
+
+	e.logger.WithComponent(logging.ComponentNagios).
+		WithFields(map[string]interface{}{
+			"target":   e.submitter.String(),
+			"interval": e.interval.String(),
+		}).
+		Info("Starting Nagios passive checks")
+
+	e.wg.Add(1)
+	go e.submitLoop(ctx)
+
+	e.running = true
+	return nil
+}
+
+// Stop submits any pending results and stops the exporter
+func (e *Exporter) Stop() error {
+	e.mu.Lock()
+	if !e.running {
+		e.mu.Unlock()
+		return nil
+	}
+	e.running = false
+	e.mu.Unlock()
+
+	e.logger.WithComponent(logging.ComponentNagios).Info("Stopping Nagios passive checks")
+	close(e.stopCh)
+	e.wg.Wait()
+	return nil
+}
+
+// submitLoop submits pending results on every tick, and once more on the way out
+func (e *Exporter) submitLoop(ctx context.Context) {
+	defer e.wg.Done()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.stopCh:
+			e.flush(context.Background())
+			return
+		case <-ticker.C:
+			e.flush(ctx)
+		}
+	}
+}
+
+// flush submits the pending results, keeping them for the next attempt when
+// submission fails unless newer results replace them
+func (e *Exporter) flush(ctx context.Context) {
+	e.mu.Lock()
+	batch := e.pending
+	e.pending = make(map[string]*models.MonitorResult)
+	e.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(batch))
+	for name := range batch {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	results := make([]CheckResult, 0, len(batch))
+	for _, name := range names {
+		results = append(results, e.checkResult(batch[name]))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.interval)
+	defer cancel()
+
+	if err := e.submitter.Submit(ctx, results); err != nil {
+		e.logger.WithComponent(logging.ComponentNagios).
+			WithError(err).
+			WithFields(map[string]interface{}{
+				"target":  e.submitter.String(),
+				"results": len(results),
+			}).
+			Warn("Failed to submit passive check results")
+
+		e.mu.Lock()
+		for name, result := range batch {
+			if _, newer := e.pending[name]; !newer {
+				e.pending[name] = result
+			}
+		}
+		e.mu.Unlock()
+	}
+}
+
+// checkResult converts a monitor result to a passive check result
+func (e *Exporter) checkResult(result *models.MonitorResult) CheckResult {
+	host := e.host
+	if host == "" {
+		host = result.Group
+	}
+	if host == "" {
+		host = defaultHost
+	}
+
+	state, label := StateUnknown, "UNKNOWN"
+	switch result.Status {
+	case models.StatusUp:
+		state, label = StateOK, "OK"
+	case models.StatusDown:
+		state, label = StateCritical, "CRITICAL"
+	}
+
+	summary := fmt.Sprintf("%s check completed in %s", result.Type, result.Duration.Round(time.Millisecond))
+	if result.Error != "" {
+		summary = result.Error
+	}
+	perfdata := "duration=" + strconv.FormatFloat(result.Duration.Seconds(), 'f', 6, 64) + "s"
+
+	return CheckResult{
+		Host:      host,
+		Service:   result.Monitor,
+		State:     state,
+		Output:    label + " - " + sanitizeOutput(summary) + " | " + perfdata,
+		Timestamp: result.Timestamp,
+	}
+}
+
+// sanitizeOutput keeps plugin output on one line, without the pipe that
+// starts performance data, and bounded in length
+func sanitizeOutput(output string) string {
+	output = strings.NewReplacer("\r", " ", "\n", " ", "|", "/").Replace(output)
+	if len(output) > maxOutputLength {
+		output = output[:maxOutputLength]
+	}
+	return output
+}
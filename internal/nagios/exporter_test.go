@@ -0,0 +1,157 @@
+package nagios
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func testLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	return logger
+}
+
+func TestCheckResult(t *testing.T) {
+	timestamp := time.Unix(1700000000, 0)
+	tests := []struct {
+		name   string
+		host   string
+		result models.MonitorResult
+		want   CheckResult
+	}{
+		{
+			name:   "up",
+			host:   "web-01",
+			result: models.MonitorResult{Monitor: "api", Type: models.MonitorTypeHTTP, Group: "web", Status: models.StatusUp, Duration: 123 * time.Millisecond, Timestamp: timestamp},
+			want:   CheckResult{Host: "web-01", Service: "api", State: StateOK, Output: "OK - http check completed in 123ms | duration=0.123000s", Timestamp: timestamp},
+		},
+		{
+			name:   "down uses the group as host",
+			result: models.MonitorResult{Monitor: "api", Type: models.MonitorTypeHTTP, Group: "web", Status: models.StatusDown, Duration: time.Second, Error: "http 503\nbody | more", Timestamp: timestamp},
+			want:   CheckResult{Host: "web", Service: "api", State: StateCritical, Output: "CRITICAL - http 503 body / more | duration=1.000000s", Timestamp: timestamp},
+		},
+		{
+			name:   "unknown without group",
+			result: models.MonitorResult{Monitor: "db", Type: models.MonitorTypeTCP, Status: models.StatusUnknown, Error: "not checked yet", Timestamp: timestamp},
+			want:   CheckResult{Host: "hallmonitor", Service: "db", State: StateUnknown, Output: "UNKNOWN - not checked yet | duration=0.000000s", Timestamp: timestamp},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := NewExporter(config.NagiosConfig{CommandFile: "/dev/null", Host: tt.host}, testLogger(t))
+			if got := exporter.checkResult(&tt.result); got != tt.want {
+				t.Errorf("checkResult() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	got := formatCommand(CheckResult{
+		Host:      "web;01",
+		Service:   "api\nv2",
+		State:     StateCritical,
+		Output:    "CRITICAL - http 503",
+		Timestamp: time.Unix(1700000000, 0),
+	})
+	want := "[1700000000] PROCESS_SERVICE_CHECK_RESULT;web_01;api v2;2;CRITICAL - http 503\n"
+	if got != want {
+		t.Errorf("formatCommand() = %q, want %q", got, want)
+	}
+}
+
+func TestExporterWritesCommandFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nagios.cmd")
+	if err := os.WriteFile(path, nil, 0o600); err != nil {
+		t.Fatalf("failed to create command file: %v", err)
+	}
+
+	exporter := NewExporter(config.NagiosConfig{CommandFile: path, Host: "hallmonitor-01", Interval: time.Hour}, testLogger(t))
+	if err := exporter.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start exporter: %v", err)
+	}
+	exporter.HandleResult(&models.MonitorResult{Monitor: "web", Type: models.MonitorTypeHTTP, Status: models.StatusUp, Timestamp: time.Unix(1700000000, 0)})
+	exporter.HandleResult(&models.MonitorResult{Monitor: "db", Type: models.MonitorTypeTCP, Status: models.StatusDown, Error: "connection refused", Timestamp: time.Unix(1700000001, 0)})
+
+	// Stopping submits what is pending
+	if err := exporter.Stop(); err != nil {
+		t.Fatalf("failed to stop exporter: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read command file: %v", err)
+	}
+	want := "[1700000001] PROCESS_SERVICE_CHECK_RESULT;hallmonitor-01;db;2;CRITICAL - connection refused | duration=0.000000s\n" +
+		"[1700000000] PROCESS_SERVICE_CHECK_RESULT;hallmonitor-01;web;0;OK - http check completed in 0s | duration=0.000000s\n"
+	if string(data) != want {
+		t.Errorf("command file = %q, want %q", data, want)
+	}
+}
+
+func TestNRDPSubmit(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		reply   string
+		wantErr bool
+	}{
+		{name: "accepted", status: http.StatusOK, reply: "<result><status>0</status><message>OK</message></result>"},
+		{name: "bad token", status: http.StatusOK, reply: "<result><status>-1</status><message>BAD TOKEN</message></result>", wantErr: true},
+		{name: "server error", status: http.StatusInternalServerError, reply: "oops", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var form map[string][]string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				r.ParseForm()
+				form = r.PostForm
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.reply))
+			}))
+			defer server.Close()
+
+			client := newNRDPClient(config.NRDPConfig{URL: server.URL, Token: "secret"})
+			err := client.Submit(context.Background(), []CheckResult{
+				{Host: "web", Service: "api", State: StateCritical, Output: "CRITICAL - <b>503</b> & more"},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Submit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := form["token"]; len(got) != 1 || got[0] != "secret" {
+				t.Errorf("token = %v, want secret", got)
+			}
+			if got := form["cmd"]; len(got) != 1 || got[0] != "submitcheck" {
+				t.Errorf("cmd = %v, want submitcheck", got)
+			}
+			if len(form["XMLDATA"]) != 1 {
+				t.Fatalf("expected XMLDATA, got %v", form)
+			}
+			var document nrdpCheckResults
+			if err := xml.Unmarshal([]byte(strings.TrimPrefix(form["XMLDATA"][0], xml.Header)), &document); err != nil {
+				t.Fatalf("failed to decode XMLDATA: %v", err)
+			}
+			want := nrdpCheckResult{Type: "service", CheckType: 1, Hostname: "web", ServiceName: "api", State: StateCritical, Output: "CRITICAL - <b>503</b> & more"}
+			if len(document.Results) != 1 || document.Results[0] != want {
+				t.Errorf("XMLDATA results = %+v, want %+v", document.Results, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package nagios
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+)
+
+// commandFile writes PROCESS_SERVICE_CHECK_RESULT commands to the Nagios or
+// Icinga external command file, usually a named pipe
+type commandFile struct {
+	path string
+}
+
+// Submit writes one command per result. The file is opened without blocking
+// so a pipe nobody reads fails instead of hanging.
+func (f *commandFile) Submit(_ context.Context, results []CheckResult) error {
+	file, err := os.OpenFile(f.path, os.O_WRONLY|os.O_APPEND|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open command file: %w", err)
+	}
+	defer file.Close()
+
+	for _, result := range results {
+		// One write per line keeps each command atomic on a pipe
+		if _, err := file.WriteString(formatCommand(result)); err != nil {
+			return fmt.Errorf("failed to write command: %w", err)
+		}
+	}
+	return nil
+}
+
+// String describes the target for logs
+func (f *commandFile) String() string {
+	return f.path
+}
+
+// formatCommand formats a result as an external command line
+func formatCommand(result CheckResult) string {
+	return fmt.Sprintf("[%d] PROCESS_SERVICE_CHECK_RESULT;%s;%s;%d;%s\n",
+		result.Timestamp.Unix(), commandField(result.Host), commandField(result.Service), result.State, result.Output)
+}
+
+// commandField strips the separators of the command syntax from a field
+func commandField(value string) string {
+	return strings.NewReplacer(";", "_", "\n", " ", "\r", " ").Replace(value)
+}
+
+// nrdpClient submits results to a Nagios Remote Data Processor
+type nrdpClient struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// newNRDPClient creates an NRDP client
+func newNRDPClient(cfg config.NRDPConfig) *nrdpClient {
+	return &nrdpClient{
+		url:    cfg.URL,
+		token:  cfg.Token,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// nrdpCheckResults is the XMLDATA document of a submitcheck request
+type nrdpCheckResults struct {
+	XMLName xml.Name          `xml:"checkresults"`
+	Results []nrdpCheckResult `xml:"checkresult"`
+}
+
+// nrdpCheckResult is one passive service check in XMLDATA
+type nrdpCheckResult struct {
+	Type        string `xml:"type,attr"`
+	CheckType   int    `xml:"checktype,attr"`
+	Hostname    string `xml:"hostname"`
+	ServiceName string `xml:"servicename"`
+	State       int    `xml:"state"`
+	Output      string `xml:"output"`
+}
+
+// nrdpResponse is NRDP's reply; status 0 means the results were accepted
+type nrdpResponse struct {
+	Status  int    `xml:"status"`
+	Message string `xml:"message"`
+}
+
+// Submit posts the results as one submitcheck request
+func (n *nrdpClient) Submit(ctx context.Context, results []CheckResult) error {
+	document := nrdpCheckResults{}
+	for _, result := range results {
+		document.Results = append(document.Results, nrdpCheckResult{
+			Type:        "service",
+			CheckType:   1, // Passive
+			Hostname:    result.Host,
+			ServiceName: result.Service,
+			State:       result.State,
+			Output:      result.Output,
+		})
+	}
+	data, err := xml.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("failed to encode check results: %w", err)
+	}
+
+	form := url.Values{
+		"token":   {n.token},
+		"cmd":     {"submitcheck"},
+		"XMLDATA": {xml.Header + string(data)},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("NRDP returned HTTP %d", resp.StatusCode)
+	}
+
+	var reply nrdpResponse
+	if err := xml.Unmarshal(body, &reply); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if reply.Status != 0 {
+		return fmt.Errorf("NRDP rejected the results (status %d): %s", reply.Status, reply.Message)
+	}
+	return nil
+}
+
+// String describes the target for logs
+func (n *nrdpClient) String() string {
+	return n.url
+}
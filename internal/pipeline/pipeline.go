@@ -0,0 +1,292 @@
+// Package pipeline applies GeoIP lookups and the configured result hooks to
+// check results before they are stored: relabeling, metadata enrichment,
+// dropping noisy fields, and rewriting by plugins.
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/geoip"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/plugin"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// defaultPluginTimeout bounds a hook plugin run on one result
+const defaultPluginTimeout = 5 * time.Second
+
+// HookPluginRequest is written to a hook plugin's stdin for each result
+type HookPluginRequest struct {
+	Protocol int                   `json:"protocol"`
+	Options  map[string]string     `json:"options,omitempty"`
+	Monitor  *models.Monitor       `json:"monitor,omitempty"`
+	Result   *models.MonitorResult `json:"result"`
+}
+
+// Pipeline runs results through a list of hooks
+type Pipeline struct {
+	hooks     []config.ResultHookConfig
+	pluginDir string // monitoring.pluginDir, which hook plugins are relative to
+	geo       *geoip.DB
+	logger    *logging.Logger
+}
+
+// New creates a pipeline of hooks whose plugins run from pluginDir
+func New(hooks []config.ResultHookConfig, pluginDir string, logger *logging.Logger) *Pipeline {
+	return &Pipeline{hooks: hooks, pluginDir: pluginDir, logger: logger}
+}
+
+// SetGeoIP locates the remote address of each result in db before the hooks
+// run, so hooks can use or drop the location
+func (p *Pipeline) SetGeoIP(db *geoip.DB) {
+	p.geo = db
+}
+
+// ProcessResult applies each matching hook to result in order. Hooks that
+// fail are logged and leave the result as it was.
+func (p *Pipeline) ProcessResult(monitor *models.Monitor, result *models.MonitorResult) {
+	if p.geo != nil && result.RemoteIP != "" {
+		result.Geo = p.geo.Lookup(result.RemoteIP)
+	}
+	for i := range p.hooks {
+		hook := &p.hooks[i]
+		if !matches(hook.Match, monitor, result) {
+			continue
+		}
+		if err := apply(hook, p.pluginDir, monitor, result); err != nil {
+			name := hook.Name
+			if name == "" {
+				name = fmt.Sprintf("resultHooks[%d]", i)
+			}
+			p.logger.WithComponent(logging.ComponentScheduler).
+				WithError(err).
+				WithFields(map[string]interface{}{
+					"hook":    name,
+					"monitor": result.Monitor,
+				}).
+				Warn("Result hook failed")
+		}
+	}
+}
+
+// apply runs one hook's actions on result
+func apply(hook *config.ResultHookConfig, pluginDir string, monitor *models.Monitor, result *models.MonitorResult) error {
+	if hook.CopyMonitorLabels && monitor != nil {
+		for name, value := range monitor.Labels {
+			setLabel(result, name, value)
+		}
+	}
+	for name, value := range hook.SetLabels {
+		setLabel(result, name, value)
+	}
+	for from, to := range hook.RenameLabels {
+		if value, ok := result.Labels[from]; ok {
+			delete(result.Labels, from)
+			setLabel(result, to, value)
+		}
+	}
+	for _, name := range hook.DropLabels {
+		delete(result.Labels, name)
+	}
+	if len(result.Labels) == 0 {
+		result.Labels = nil
+	}
+
+	if len(hook.SetMetadata) > 0 {
+		metadata, err := metadataMap(result.Metadata)
+		if err != nil {
+			return err
+		}
+		for key, value := range hook.SetMetadata {
+			metadata[key] = value
+		}
+		result.Metadata = metadata
+	}
+
+	if len(hook.DropFields) > 0 {
+		if err := dropFields(result, hook.DropFields); err != nil {
+			return err
+		}
+	}
+
+	if hook.Plugin != "" {
+		return runPlugin(hook, pluginDir, monitor, result)
+	}
+	return nil
+}
+
+// matches reports whether a hook's match applies to a result
+func matches(match config.ResultHookMatch, monitor *models.Monitor, result *models.MonitorResult) bool {
+	if len(match.Monitors) > 0 && !matchesAny(match.Monitors, result.Monitor) {
+		return false
+	}
+	if len(match.Groups) > 0 && !matchesAny(match.Groups, result.Group) {
+		return false
+	}
+	if len(match.Types) > 0 && !contains(match.Types, string(result.Type)) {
+		return false
+	}
+	if len(match.Statuses) > 0 && !contains(match.Statuses, string(result.Status)) {
+		return false
+	}
+	for name, value := range match.Labels {
+		if monitor == nil || monitor.Labels[name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAny reports whether name matches one of the glob patterns
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func setLabel(result *models.MonitorResult, name, value string) {
+	if result.Labels == nil {
+		result.Labels = make(map[string]string)
+	}
+	result.Labels[name] = value
+}
+
+// metadataMap returns result metadata as a map that can take more keys.
+// Metadata that is not a JSON object is kept under "value".
+func metadataMap(metadata interface{}) (map[string]interface{}, error) {
+	switch m := metadata.(type) {
+	case nil:
+		return make(map[string]interface{}), nil
+	case map[string]interface{}:
+		return m, nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode metadata: %w", err)
+	}
+	var converted map[string]interface{}
+	if err := json.Unmarshal(data, &converted); err != nil || converted == nil {
+		return map[string]interface{}{"value": metadata}, nil
+	}
+	return converted, nil
+}
+
+// dropFields removes dotted JSON paths from result by round-tripping it
+// through its JSON form
+func dropFields(result *models.MonitorResult, fields []string) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+
+	dropped := false
+	for _, field := range fields {
+		if deletePath(doc, strings.Split(field, ".")) {
+			dropped = true
+		}
+	}
+	if !dropped {
+		return nil
+	}
+
+	data, err = json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	var replaced models.MonitorResult
+	if err := json.Unmarshal(data, &replaced); err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	*result = replaced
+	return nil
+}
+
+// deletePath removes the value at keys from doc, reporting whether it existed
+func deletePath(doc map[string]interface{}, keys []string) bool {
+	for len(keys) > 1 {
+		next, ok := doc[keys[0]].(map[string]interface{})
+		if !ok {
+			return false
+		}
+		doc, keys = next, keys[1:]
+	}
+	if _, ok := doc[keys[0]]; !ok {
+		return false
+	}
+	delete(doc, keys[0])
+	return true
+}
+
+// runPlugin passes result through a hook plugin. A plugin that prints
+// nothing leaves the result unchanged; otherwise it prints the new result.
+// Monitor, type, group, and timestamp cannot be changed.
+func runPlugin(hook *config.ResultHookConfig, pluginDir string, monitor *models.Monitor, result *models.MonitorResult) error {
+	path, err := plugin.Resolve(pluginDir, hook.Plugin)
+	if err != nil {
+		return err
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = defaultPluginTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	output, err := plugin.Run(ctx, path, hook.PluginArgs, hook.PluginEnv, HookPluginRequest{
+		Protocol: plugin.ProtocolVersion,
+		Options:  hook.Options,
+		Monitor:  monitor,
+		Result:   result,
+	})
+	if err != nil {
+		return err
+	}
+	if output.ExitCode != 0 {
+		return fmt.Errorf("plugin failed: %s", output.Failure())
+	}
+	if len(output.Stdout) == 0 {
+		return nil
+	}
+
+	var replaced models.MonitorResult
+	if err := json.Unmarshal(output.Stdout, &replaced); err != nil {
+		return fmt.Errorf("invalid plugin response: %w", err)
+	}
+	switch replaced.Status {
+	case models.StatusUp, models.StatusDown, models.StatusUnknown:
+	case "":
+		replaced.Status = result.Status
+	default:
+		return fmt.Errorf("invalid plugin status %q", replaced.Status)
+	}
+	replaced.Monitor = result.Monitor
+	replaced.Type = result.Type
+	replaced.Group = result.Group
+	replaced.Timestamp = result.Timestamp
+	*result = replaced
+	return nil
+}
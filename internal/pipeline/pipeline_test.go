@@ -0,0 +1,221 @@
+package pipeline
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func newTestLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stderr"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	return logger
+}
+
+func newResult() *models.MonitorResult {
+	return &models.MonitorResult{
+		Monitor:   "api",
+		Type:      models.MonitorTypeHTTP,
+		Group:     "core",
+		Status:    models.StatusUp,
+		Timestamp: time.Unix(1700000000, 0).UTC(),
+		HTTPResult: &models.HTTPResult{
+			StatusCode: 200,
+			Headers:    map[string]string{"Server": "nginx"},
+		},
+	}
+}
+
+func TestProcessResultLabels(t *testing.T) {
+	monitor := &models.Monitor{Name: "api", Labels: map[string]string{"env": "prod", "owner": "ops"}}
+	tests := []struct {
+		name string
+		hook config.ResultHookConfig
+		want map[string]string
+	}{
+		{name: "copy monitor labels", hook: config.ResultHookConfig{CopyMonitorLabels: true}, want: map[string]string{"env": "prod", "owner": "ops"}},
+		{name: "set", hook: config.ResultHookConfig{SetLabels: map[string]string{"team": "core"}}, want: map[string]string{"team": "core"}},
+		{
+			name: "rename",
+			hook: config.ResultHookConfig{CopyMonitorLabels: true, RenameLabels: map[string]string{"owner": "team"}},
+			want: map[string]string{"env": "prod", "team": "ops"},
+		},
+		{
+			name: "drop",
+			hook: config.ResultHookConfig{CopyMonitorLabels: true, DropLabels: []string{"owner"}},
+			want: map[string]string{"env": "prod"},
+		},
+		{name: "drop all", hook: config.ResultHookConfig{SetLabels: map[string]string{"a": "b"}, DropLabels: []string{"a"}}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newResult()
+			New([]config.ResultHookConfig{tt.hook}, "", newTestLogger(t)).ProcessResult(monitor, result)
+			if !reflect.DeepEqual(result.Labels, tt.want) {
+				t.Errorf("Labels = %v, want %v", result.Labels, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessResultMatch(t *testing.T) {
+	monitor := &models.Monitor{Name: "api", Labels: map[string]string{"env": "prod"}}
+	tests := []struct {
+		name  string
+		match config.ResultHookMatch
+		want  bool
+	}{
+		{name: "empty", match: config.ResultHookMatch{}, want: true},
+		{name: "monitor glob", match: config.ResultHookMatch{Monitors: []string{"a*"}}, want: true},
+		{name: "other monitor", match: config.ResultHookMatch{Monitors: []string{"db-*"}}, want: false},
+		{name: "group", match: config.ResultHookMatch{Groups: []string{"core"}}, want: true},
+		{name: "type", match: config.ResultHookMatch{Types: []string{"tcp"}}, want: false},
+		{name: "status", match: config.ResultHookMatch{Statuses: []string{"down"}}, want: false},
+		{name: "label", match: config.ResultHookMatch{Labels: map[string]string{"env": "prod"}}, want: true},
+		{name: "other label", match: config.ResultHookMatch{Labels: map[string]string{"env": "dev"}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newResult()
+			hook := config.ResultHookConfig{Match: tt.match, SetLabels: map[string]string{"hooked": "true"}}
+			New([]config.ResultHookConfig{hook}, "", newTestLogger(t)).ProcessResult(monitor, result)
+			if got := result.Labels["hooked"] == "true"; got != tt.want {
+				t.Errorf("hook applied = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessResultMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata interface{}
+		want     map[string]interface{}
+	}{
+		{name: "none", metadata: nil, want: map[string]interface{}{"region": "eu"}},
+		{
+			name:     "map",
+			metadata: map[string]interface{}{"queue": 3},
+			want:     map[string]interface{}{"queue": 3, "region": "eu"},
+		},
+		{
+			name:     "struct",
+			metadata: struct{ Depth int }{Depth: 3},
+			want:     map[string]interface{}{"Depth": float64(3), "region": "eu"},
+		},
+		{name: "scalar", metadata: "ok", want: map[string]interface{}{"value": "ok", "region": "eu"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := newResult()
+			result.Metadata = tt.metadata
+			hook := config.ResultHookConfig{SetMetadata: map[string]string{"region": "eu"}}
+			New([]config.ResultHookConfig{hook}, "", newTestLogger(t)).ProcessResult(nil, result)
+			if !reflect.DeepEqual(result.Metadata, tt.want) {
+				t.Errorf("Metadata = %#v, want %#v", result.Metadata, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessResultDropFields(t *testing.T) {
+	result := newResult()
+	result.Error = "noisy"
+	hook := config.ResultHookConfig{DropFields: []string{"http_result.headers", "error", "missing.field"}}
+	New([]config.ResultHookConfig{hook}, "", newTestLogger(t)).ProcessResult(nil, result)
+
+	if result.HTTPResult == nil || result.HTTPResult.StatusCode != 200 {
+		t.Fatalf("HTTPResult = %+v, want status code kept", result.HTTPResult)
+	}
+	if result.HTTPResult.Headers != nil {
+		t.Errorf("Headers = %v, want dropped", result.HTTPResult.Headers)
+	}
+	if result.Error != "" {
+		t.Errorf("Error = %q, want dropped", result.Error)
+	}
+	if result.Monitor != "api" || !result.Timestamp.Equal(newResult().Timestamp) {
+		t.Errorf("identity changed: %+v", result)
+	}
+}
+
+// writeHook writes a shell script plugin named hook and returns the directory
+// it is in
+func writeHook(t *testing.T, script string) string {
+	t.Helper()
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hook"), []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatalf("failed to write hook: %v", err)
+	}
+	return dir
+}
+
+func TestProcessResultPlugin(t *testing.T) {
+	tests := []struct {
+		name       string
+		script     string
+		wantStatus models.MonitorStatus
+		wantLabels map[string]string
+	}{
+		{
+			name:       "unchanged",
+			script:     "cat >/dev/null\n",
+			wantStatus: models.StatusUp,
+			wantLabels: map[string]string{"before": "plugin"},
+		},
+		{
+			name:       "replaced",
+			script:     "cat >/dev/null\necho '{\"monitor\":\"other\",\"status\":\"down\",\"error\":\"blocked\",\"labels\":{\"asn\":\"13335\"}}'\n",
+			wantStatus: models.StatusDown,
+			wantLabels: map[string]string{"asn": "13335"},
+		},
+		{
+			name:       "failed",
+			script:     "cat >/dev/null\necho 'lookup failed' >&2\nexit 1\n",
+			wantStatus: models.StatusUp,
+			wantLabels: map[string]string{"before": "plugin"},
+		},
+		{
+			name:       "invalid status",
+			script:     "cat >/dev/null\necho '{\"status\":\"degraded\"}'\n",
+			wantStatus: models.StatusUp,
+			wantLabels: map[string]string{"before": "plugin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hooks := []config.ResultHookConfig{
+				{SetLabels: map[string]string{"before": "plugin"}},
+				{Plugin: "hook"},
+			}
+			result := newResult()
+			New(hooks, writeHook(t, tt.script), newTestLogger(t)).ProcessResult(nil, result)
+
+			if result.Status != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", result.Status, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(result.Labels, tt.wantLabels) {
+				t.Errorf("Labels = %v, want %v", result.Labels, tt.wantLabels)
+			}
+			if result.Monitor != "api" || result.Group != "core" {
+				t.Errorf("identity changed: monitor %q group %q", result.Monitor, result.Group)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package plugin runs external executables that speak Hall Monitor's plugin
+// protocol: a JSON request on stdin, an optional JSON response on stdout, and
+// the exit status. Plugin monitors, notification plugins, and result hooks
+// build their requests and responses on it.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProtocolVersion is sent in every request and in the
+// HALLMONITOR_PLUGIN_PROTOCOL environment variable, so plugins can reject
+// versions they do not understand
+const ProtocolVersion = 1
+
+// protocolEnv is the environment variable holding ProtocolVersion
+const protocolEnv = "HALLMONITOR_PLUGIN_PROTOCOL"
+
+// inheritedEnv are the variables of Hall Monitor's own environment that
+// plugins see. The rest, which may hold credentials, is not passed on;
+// plugins that need more are given it with pluginEnv. SYSTEMROOT is needed
+// by most programs on Windows.
+var inheritedEnv = []string{"PATH", "HOME", "SYSTEMROOT"}
+
+const (
+	// MaxOutput caps how much of a plugin's stdout and stderr is kept
+	MaxOutput = 1 << 20
+
+	// waitDelay is how long a plugin's output is awaited after it is killed,
+	// in case it left children holding stdout open
+	waitDelay = 2 * time.Second
+)
+
+// Resolve returns the executable for the plugin name inside dir. Plugins only
+// run from monitoring.pluginDir: without it every plugin is refused, as are
+// absolute names and names that climb out of it with "..".
+func Resolve(dir, name string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("plugins require monitoring.pluginDir")
+	}
+	if !filepath.IsLocal(name) || filepath.Clean(name) == "." {
+		return "", fmt.Errorf("plugin %q must be a path inside monitoring.pluginDir", name)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// Output is what a plugin wrote and how it exited
+type Output struct {
+	Stdout   []byte
+	Stderr   string
+	ExitCode int
+}
+
+// Starter runs a plugin to completion with the given stdin, stdout, and
+// stderr and returns its exit status. The error is for plugins that could not
+// be run at all.
+type Starter func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (int, error)
+
+// ValidateEnv checks the variables a plugin's configuration sets. Names
+// are upper-cased when used, since config keys are read case-insensitively.
+// Variables that change how the executable is loaded, and the protocol
+// variable, cannot be set.
+func ValidateEnv(env map[string]string) error {
+	for name := range env {
+		upper := strings.ToUpper(name)
+		switch {
+		case !validEnvName(upper):
+			return fmt.Errorf("invalid pluginEnv name %q", name)
+		case strings.HasPrefix(upper, "LD_"), strings.HasPrefix(upper, "DYLD_"), upper == protocolEnv:
+			return fmt.Errorf("pluginEnv cannot set %s", upper)
+		}
+	}
+	return nil
+}
+
+// validEnvName reports whether name is a letter or underscore followed by
+// letters, digits, and underscores
+func validEnvName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// Environ returns the environment a plugin runs with: inheritedEnv, the
+// variables its configuration sets in env, and HALLMONITOR_PLUGIN_PROTOCOL
+func Environ(env map[string]string) []string {
+	var environ []string
+	for _, name := range inheritedEnv {
+		if value, ok := os.LookupEnv(name); ok {
+			environ = append(environ, name+"="+value)
+		}
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		environ = append(environ, strings.ToUpper(name)+"="+env[name])
+	}
+	return append(environ, fmt.Sprintf("%s=%d", protocolEnv, ProtocolVersion))
+}
+
+// Command returns a Starter that runs the executable path with args, in the
+// environment Environ builds from env
+func Command(path string, args []string, env map[string]string) Starter {
+	return func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer) (int, error) {
+		cmd := exec.CommandContext(ctx, path, args...)
+		cmd.Stdin = stdin
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		cmd.Env = Environ(env)
+		cmd.WaitDelay = waitDelay
+
+		err := cmd.Run()
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 0, err
+	}
+}
+
+// Run starts path with args and the variables in env, writes request to its
+// stdin as JSON, and waits for it to exit. Exiting with a non-zero status is not an error; see
+// ExitCode. Errors are returned when the plugin cannot be started, does not
+// finish before ctx is done, or writes more than MaxOutput to stdout.
+func Run(ctx context.Context, path string, args []string, env map[string]string, request interface{}) (*Output, error) {
+	return RunWith(ctx, Command(path, args, env), request)
+}
+
+// RunWith is Run for plugins that are not executables, such as WebAssembly
+// modules, started by start
+func RunWith(ctx context.Context, start Starter, request interface{}) (*Output, error) {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode plugin request: %w", err)
+	}
+
+	stdout := &cappedBuffer{max: MaxOutput}
+	stderr := &cappedBuffer{max: MaxOutput}
+	exitCode, runErr := start(ctx, bytes.NewReader(input), stdout, stderr)
+	output := &Output{Stdout: bytes.TrimSpace(stdout.Bytes()), Stderr: stderr.String(), ExitCode: exitCode}
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return output, fmt.Errorf("plugin did not finish: %w", ctxErr)
+	}
+	if runErr != nil {
+		return output, fmt.Errorf("failed to run plugin: %w", runErr)
+	}
+	if stdout.truncated {
+		return output, fmt.Errorf("plugin output exceeds %d bytes", MaxOutput)
+	}
+	return output, nil
+}
+
+// Failure describes why a plugin failed: the last line it wrote to stderr,
+// or else its exit status
+func (o *Output) Failure() string {
+	lines := strings.Split(strings.TrimSpace(o.Stderr), "\n")
+	if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+		return last
+	}
+	return fmt.Sprintf("plugin exited with status %d", o.ExitCode)
+}
+
+// cappedBuffer keeps the first max bytes written to it and discards the rest
+type cappedBuffer struct {
+	bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(data []byte) (int, error) {
+	if room := b.max - b.Len(); len(data) > room {
+		b.truncated = true
+		if room > 0 {
+			b.Buffer.Write(data[:room])
+		}
+		return len(data), nil
+	}
+	return b.Buffer.Write(data)
+}
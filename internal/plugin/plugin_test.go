@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	dir := filepath.Join("opt", "plugins")
+	tests := []struct {
+		name    string
+		dir     string
+		plugin  string
+		want    string
+		wantErr bool
+	}{
+		{name: "in dir", dir: dir, plugin: "check-queue", want: filepath.Join(dir, "check-queue")},
+		{name: "in subdirectory", dir: dir, plugin: "queues/check", want: filepath.Join(dir, "queues", "check")},
+		{name: "no plugin dir", plugin: "check-queue", wantErr: true},
+		{name: "absolute", dir: dir, plugin: "/bin/sh", wantErr: true},
+		{name: "parent", dir: dir, plugin: "../bin/sh", wantErr: true},
+		{name: "climbs out", dir: dir, plugin: "queues/../../sh", wantErr: true},
+		{name: "empty", dir: dir, wantErr: true},
+		{name: "plugin dir itself", dir: dir, plugin: "queues/..", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.dir, tt.plugin)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("PATH", "/usr/bin")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "hunter2")
+
+	got := Environ(map[string]string{"region": "eu", "API_URL": "https://api.example.com"})
+	if !slices.Contains(got, "PATH=/usr/bin") {
+		t.Errorf("expected PATH to be passed on, got %v", got)
+	}
+	for _, want := range []string{"API_URL=https://api.example.com", "REGION=eu"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("expected %s from pluginEnv, got %v", want, got)
+		}
+	}
+	if got[len(got)-1] != "HALLMONITOR_PLUGIN_PROTOCOL=1" {
+		t.Errorf("expected the protocol variable last, got %v", got)
+	}
+	for _, variable := range got {
+		if variable == "AWS_SECRET_ACCESS_KEY=hunter2" {
+			t.Errorf("expected the server's other variables to be left out, got %v", got)
+		}
+	}
+}
+
+func TestValidateEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "none"},
+		{name: "valid", env: map[string]string{"region": "eu", "API_TOKEN_2": "x"}},
+		{name: "empty name", env: map[string]string{"": "x"}, wantErr: true},
+		{name: "leading digit", env: map[string]string{"2FA": "x"}, wantErr: true},
+		{name: "equals sign", env: map[string]string{"A=B": "x"}, wantErr: true},
+		{name: "loader", env: map[string]string{"ld_preload": "/tmp/x.so"}, wantErr: true},
+		{name: "darwin loader", env: map[string]string{"DYLD_INSERT_LIBRARIES": "/tmp/x.dylib"}, wantErr: true},
+		{name: "protocol", env: map[string]string{"HALLMONITOR_PLUGIN_PROTOCOL": "2"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateEnv(tt.env); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateEnv() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
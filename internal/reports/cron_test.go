@@ -0,0 +1,63 @@
+package reports
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@fortnightly",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseSchedule(expr); err == nil {
+				t.Errorf("expected ParseSchedule(%q) to fail", expr)
+			}
+		})
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Wednesday
+	from := time.Date(2024, 3, 13, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{expr: "*/15 * * * *", want: time.Date(2024, 3, 13, 10, 45, 0, 0, time.UTC)},
+		{expr: "0 8 * * *", want: time.Date(2024, 3, 14, 8, 0, 0, 0, time.UTC)},
+		{expr: "0 8 * * 1", want: time.Date(2024, 3, 18, 8, 0, 0, 0, time.UTC)},
+		{expr: "0 9 * * 1-5", want: time.Date(2024, 3, 14, 9, 0, 0, 0, time.UTC)},
+		{expr: "0 0 * * 7", want: time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{expr: "@weekly", want: time.Date(2024, 3, 17, 0, 0, 0, 0, time.UTC)},
+		{expr: "@monthly", want: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{expr: "30 10 13 3 *", want: time.Date(2025, 3, 13, 10, 30, 0, 0, time.UTC)},
+		// Both day fields restricted: the 20th or any Friday, whichever is first
+		{expr: "0 0 20 * 5", want: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 29 2 *", want: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{expr: "0 0 30 2 *", want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseSchedule failed: %v", err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDF page geometry in points (A4)
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+)
+
+// PDF fonts, referenced by resource name; all are standard Type 1 fonts that
+// need no embedding
+const (
+	pdfFontRegular = "F1"
+	pdfFontBold    = "F2"
+	pdfFontMono    = "F3"
+)
+
+var pdfFonts = []struct{ name, base string }{
+	{pdfFontRegular, "Helvetica"},
+	{pdfFontBold, "Helvetica-Bold"},
+	{pdfFontMono, "Courier"},
+}
+
+// pdfLine is one line of text; gap adds space above it
+type pdfLine struct {
+	font string
+	size float64
+	gap  float64
+	text string
+}
+
+// RenderPDF renders a report as a plain A4 PDF document. Tables are laid out
+// in a monospaced font, so the output needs no font metrics or dependencies.
+func RenderPDF(report *Report) ([]byte, error) {
+	return writePDF(report.Title, pdfReportLines(report)), nil
+}
+
+// pdfReportLines lays out a report as lines of text
+func pdfReportLines(report *Report) []pdfLine {
+	lines := []pdfLine{
+		{font: pdfFontBold, size: 18, text: report.Title},
+		{font: pdfFontRegular, size: 10, gap: 2, text: fmt.Sprintf("%s - %s", report.Start.Format("Jan 2, 2006"), report.End.Format("Jan 2, 2006"))},
+	}
+	mono := func(format string, args ...interface{}) pdfLine {
+		return pdfLine{font: pdfFontMono, size: 8, text: fmt.Sprintf(format, args...)}
+	}
+
+	for _, group := range report.Groups {
+		summary := "no data"
+		if group.Checks > 0 {
+			summary = fmt.Sprintf("%.3f%% uptime, %d outage(s)", group.UptimePercent, group.Outages)
+			if group.HasSLO() {
+				summary += fmt.Sprintf(", %d of %d monitors met their objective", group.SLOMet, group.SLOMet+group.SLOMissed)
+			}
+		}
+		lines = append(lines,
+			pdfLine{font: pdfFontBold, size: 13, gap: 14, text: group.Name},
+			pdfLine{font: pdfFontRegular, size: 10, text: summary},
+			mono("%-26s %9s %-16s %7s %9s %9s %8s", "Monitor", "Uptime", "Objective", "Outages", "Downtime", "MTTR", "p95").gapped(4))
+
+		for _, monitor := range group.Monitors {
+			name := truncate(monitor.Name, 26)
+			if !monitor.HasData() {
+				lines = append(lines, mono("%-26s %9s", name, "no data"))
+				continue
+			}
+			objective := ""
+			if monitor.SLO > 0 {
+				status := "missed"
+				if monitor.MeetsSLO {
+					status = "met"
+				}
+				objective = fmt.Sprintf("%.3g%% %s", monitor.SLO, status)
+			}
+			lines = append(lines, mono("%-26s %8.3f%% %-16s %7d %9s %9s %8s",
+				name, monitor.UptimePercent, objective, monitor.Outages,
+				formatSeconds(monitor.OutageSeconds), formatSeconds(monitor.MTTRSeconds),
+				fmt.Sprintf("%.0fms", monitor.P95LatencyMs)))
+		}
+	}
+
+	if report.ListsIncidents() {
+		lines = append(lines, pdfLine{font: pdfFontBold, size: 13, gap: 14, text: "Incidents"})
+		if len(report.Incidents) == 0 {
+			lines = append(lines, pdfLine{font: pdfFontRegular, size: 10, text: "No incidents"})
+		} else {
+			lines = append(lines, mono("%-26s %-22s %-22s %9s", "Monitor", "Started", "Resolved", "Duration").gapped(4))
+		}
+		for _, incident := range report.Incidents {
+			resolved := "ongoing"
+			if !incident.Ongoing {
+				resolved = incident.End.Format("2006-01-02 15:04 MST")
+			}
+			lines = append(lines, mono("%-26s %-22s %-22s %9s",
+				truncate(incident.Monitor, 26), incident.Start.Format("2006-01-02 15:04 MST"), resolved,
+				formatSeconds(incident.DurationSeconds)))
+		}
+	}
+
+	lines = append(lines, pdfLine{
+		font: pdfFontRegular, size: 8, gap: 16,
+		text: "Generated by Hall Monitor at " + report.GeneratedAt.Format("Jan 2, 2006 15:04 MST"),
+	})
+	return lines
+}
+
+// gapped returns the line with extra space above it
+func (l pdfLine) gapped(gap float64) pdfLine {
+	l.gap = gap
+	return l
+}
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n-1]) + "~"
+	}
+	return s
+}
+
+// writePDF paginates lines and writes them as a PDF document
+func writePDF(title string, lines []pdfLine) []byte {
+	// Lay lines out into page content streams
+	var pages []*bytes.Buffer
+	var page *bytes.Buffer
+	y := 0.0
+	for _, line := range lines {
+		height := line.size*1.3 + line.gap
+		if page == nil || y-height < pdfMargin {
+			page = &bytes.Buffer{}
+			pages = append(pages, page)
+			y = pdfPageHeight - pdfMargin
+			height = line.size * 1.3
+		}
+		y -= height
+		fmt.Fprintf(page, "BT /%s %.1f Tf %d %.2f Td (%s) Tj ET\n", line.font, line.size, pdfMargin, y, pdfString(line.text))
+	}
+
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects 1-2 are the catalog and page tree, 3 the info dictionary, then
+	// the fonts, then a page and its content stream for each page
+	fontObj := 4
+	pageObj := fontObj + len(pdfFonts)
+
+	buf.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObj+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object(fmt.Sprintf("<< /Title (%s) /Producer (Hall Monitor) >>", pdfString(title)))
+
+	var fontRefs []string
+	for i, font := range pdfFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.base))
+		fontRefs = append(fontRefs, fmt.Sprintf("/%s %d 0 R", font.name, fontObj+i))
+	}
+
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, strings.Join(fontRefs, " "), pageObj+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes()
+}
+
+// pdfString encodes s as the contents of a PDF literal string in
+// WinAnsiEncoding, replacing characters it cannot represent
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '–':
+			b.WriteString(`\226`) // En dash
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,97 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderPDF(t *testing.T) {
+	generator := newTestGenerator(t)
+	end := time.Date(2024, 3, 18, 8, 0, 0, 0, time.UTC)
+
+	report, err := generator.Generate(Options{Title: "SLA (core)", Start: end.Add(-7 * 24 * time.Hour), End: end, Incidents: true})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	pdf, err := RenderPDF(report)
+	if err != nil {
+		t.Fatalf("RenderPDF failed: %v", err)
+	}
+	checkPDFStructure(t, pdf, 1)
+
+	for _, want := range []string{`(SLA \(core\)) Tj`, "/BaseFont /Courier", "99.9% missed", "2024-03-12 09:00 UTC"} {
+		if !bytes.Contains(pdf, []byte(want)) {
+			t.Errorf("expected PDF to contain %q", want)
+		}
+	}
+}
+
+func TestWritePDFPaginates(t *testing.T) {
+	var lines []pdfLine
+	for i := 0; i < 150; i++ {
+		lines = append(lines, pdfLine{font: pdfFontMono, size: 8, text: fmt.Sprintf("line %d", i)})
+	}
+
+	// 742pt of usable height at 10.4pt per line fits 71 lines a page
+	checkPDFStructure(t, writePDF("Long", lines), 3)
+}
+
+func TestPDFString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "plain", want: "plain"},
+		{input: `a(b)c\d`, want: `a\(b\)c\\d`},
+		{input: "Mar 1 – Mar 8", want: `Mar 1 \226 Mar 8`},
+		{input: "café", want: `caf\351`},
+		{input: "日本", want: "??"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := pdfString(tt.input); got != tt.want {
+				t.Errorf("pdfString(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// checkPDFStructure verifies the header, trailer, page count and that every
+// cross-reference offset points at its object
+func checkPDFStructure(t *testing.T, pdf []byte, pages int) {
+	t.Helper()
+
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("expected a PDF header and trailer")
+	}
+	if count := fmt.Sprintf("/Count %d ", pages); !bytes.Contains(pdf, []byte(count)) {
+		t.Errorf("expected %d pages", pages)
+	}
+
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if startxref == nil {
+		t.Fatal("expected startxref")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	entries := strings.Split(string(pdf[xref:]), "\n")[3:]
+	for i, entry := range entries {
+		if !strings.HasSuffix(entry, " n ") {
+			break
+		}
+		offset, _ := strconv.Atoi(entry[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+}
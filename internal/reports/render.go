@@ -0,0 +1,74 @@
+package reports
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"math"
+	texttemplate "text/template"
+	"time"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+// templateFuncs format report figures for display
+var templateFuncs = map[string]interface{}{
+	"percent": func(v float64) string {
+		return fmt.Sprintf("%.2f%%", v)
+	},
+	"seconds": formatSeconds,
+	"add": func(a, b int) int {
+		return a + b
+	},
+	"millis": func(ms float64) string {
+		return fmt.Sprintf("%.0f ms", ms)
+	},
+	"date": func(t time.Time) string {
+		return t.Format("Jan 2, 2006")
+	},
+	"datetime": func(t time.Time) string {
+		return t.Format("Jan 2, 2006 15:04 MST")
+	},
+}
+
+var (
+	htmlTemplate     = htmltemplate.Must(htmltemplate.New("report.html").Funcs(templateFuncs).ParseFS(templateFS, "templates/report.html"))
+	markdownTemplate = texttemplate.Must(texttemplate.New("report.md").Funcs(templateFuncs).ParseFS(templateFS, "templates/report.md"))
+)
+
+// RenderHTML renders a report as a standalone HTML page suitable for email
+func RenderHTML(report *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := htmlTemplate.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderMarkdown renders a report as markdown tables for chat webhooks
+func RenderMarkdown(report *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := markdownTemplate.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("failed to render report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// formatSeconds formats a duration in seconds as e.g. "1h 5m" or "42s"
+func formatSeconds(seconds float64) string {
+	d := time.Duration(math.Round(seconds)) * time.Second
+	switch {
+	case d == 0:
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm %ds", int(d.Minutes()), int(d.Seconds())%60)
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,202 @@
+// Package reports builds uptime, latency and SLA summaries per monitor group,
+// renders them as HTML, markdown or PDF, and delivers them on a schedule by
+// email or webhook.
+package reports
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+)
+
+// StatsSource computes reliability stats and outages for a monitor;
+// *scheduler.Scheduler implements it
+type StatsSource interface {
+	GetMonitorStats(monitorName string, start, end time.Time) (scheduler.MonitorStats, error)
+	GetMonitorOutages(monitorName string, start, end time.Time) ([]scheduler.Outage, error)
+}
+
+// Options selects what a report covers
+type Options struct {
+	Name      string
+	Title     string
+	Groups    []string // All groups when empty
+	Start     time.Time
+	End       time.Time
+	SLO       float64 // Uptime objective in percent for every monitor, overriding their own
+	Incidents bool    // List each outage
+}
+
+// Report summarises uptime and latency per group over a time range
+type Report struct {
+	Name        string        `json:"name"`
+	Title       string        `json:"title"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+	Groups      []GroupReport `json:"groups"`
+	Incidents   []Incident    `json:"incidents,omitempty"` // Oldest first; nil unless requested
+}
+
+// ListsIncidents reports whether the report was generated with incidents,
+// even if there were none
+func (r *Report) ListsIncidents() bool {
+	return r.Incidents != nil
+}
+
+// GroupReport summarises a group's monitors
+type GroupReport struct {
+	Name          string          `json:"name"`
+	UptimePercent float64         `json:"uptime_percent"` // Over all checks of monitors with data
+	Checks        int             `json:"total_checks"`
+	Outages       int             `json:"outages"`
+	SLOMet        int             `json:"slo_met"`    // Monitors with an objective and data that met it
+	SLOMissed     int             `json:"slo_missed"` // Monitors with an objective and data that missed it
+	Monitors      []MonitorReport `json:"monitors"`
+}
+
+// HasSLO reports whether any monitor in the group has an objective and data
+func (g GroupReport) HasSLO() bool {
+	return g.SLOMet+g.SLOMissed > 0
+}
+
+// MonitorReport summarises one monitor
+type MonitorReport struct {
+	Name                   string  `json:"name"`
+	UptimePercent          float64 `json:"uptime_percent"`
+	Checks                 int     `json:"total_checks"`
+	Outages                int     `json:"outages"`
+	OngoingOutage          bool    `json:"ongoing_outage"`
+	OutageSeconds          float64 `json:"outage_seconds"`
+	MTTRSeconds            float64 `json:"mttr_seconds"`
+	AvgLatencyMs           float64 `json:"avg_latency_ms"`
+	P95LatencyMs           float64 `json:"p95_latency_ms"`
+	SLO                    float64 `json:"slo,omitempty"`                      // Uptime objective in percent; 0 when none
+	MeetsSLO               bool    `json:"meets_slo"`                          // Uptime reached the objective
+	AllowedDowntimeSeconds float64 `json:"allowed_downtime_seconds,omitempty"` // Downtime the objective permits over the range
+}
+
+// HasData reports whether the monitor had any checks in the range
+func (m MonitorReport) HasData() bool {
+	return m.Checks > 0
+}
+
+// Incident is an outage of one monitor
+type Incident struct {
+	Monitor string `json:"monitor"`
+	Group   string `json:"group"`
+	scheduler.Outage
+}
+
+// Generator builds reports from the configured monitors and stored results
+type Generator struct {
+	stats    StatsSource
+	monitors *monitors.MonitorManager
+}
+
+// NewGenerator creates a report generator
+func NewGenerator(stats StatsSource, monitorManager *monitors.MonitorManager) *Generator {
+	return &Generator{stats: stats, monitors: monitorManager}
+}
+
+// Generate builds a report over [opts.Start, opts.End) for the enabled
+// monitors of the selected groups
+func (g *Generator) Generate(opts Options) (*Report, error) {
+	groups := opts.Groups
+	if len(groups) == 0 {
+		groups = g.monitors.GetGroups()
+		sort.Strings(groups)
+	}
+	start, end := opts.Start, opts.End
+
+	report := &Report{
+		Name:        opts.Name,
+		Title:       opts.Title,
+		GeneratedAt: time.Now(),
+		Start:       start,
+		End:         end,
+		Groups:      make([]GroupReport, 0, len(groups)),
+	}
+	if opts.Incidents {
+		report.Incidents = []Incident{}
+	}
+
+	for _, group := range groups {
+		groupReport := GroupReport{Name: group, Monitors: []MonitorReport{}}
+		upChecks := 0
+
+		for _, monitor := range g.monitors.GetMonitorsByGroup(group) {
+			if !monitor.IsEnabled() {
+				continue
+			}
+
+			stats, err := g.stats.GetMonitorStats(monitor.GetName(), start, end)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get stats for %s: %w", monitor.GetName(), err)
+			}
+
+			monitorReport := MonitorReport{
+				Name:          monitor.GetName(),
+				Checks:        stats.Checks,
+				Outages:       stats.Outages,
+				OngoingOutage: stats.OngoingOutage,
+				OutageSeconds: stats.OutageSeconds,
+				MTTRSeconds:   stats.MTTRSeconds,
+				AvgLatencyMs:  stats.Latency.AvgMs,
+				P95LatencyMs:  stats.Latency.P95Ms,
+			}
+			if stats.Checks > 0 {
+				monitorReport.UptimePercent = float64(stats.UpChecks) / float64(stats.Checks) * 100
+			}
+
+			monitorReport.SLO = opts.SLO
+			if monitorReport.SLO == 0 {
+				monitorReport.SLO = monitor.GetConfig().SLO
+			}
+			if monitorReport.SLO > 0 {
+				monitorReport.AllowedDowntimeSeconds = end.Sub(start).Seconds() * (100 - monitorReport.SLO) / 100
+				if stats.Checks > 0 {
+					monitorReport.MeetsSLO = monitorReport.UptimePercent >= monitorReport.SLO
+					if monitorReport.MeetsSLO {
+						groupReport.SLOMet++
+					} else {
+						groupReport.SLOMissed++
+					}
+				}
+			}
+
+			if opts.Incidents && stats.Outages > 0 {
+				outages, err := g.stats.GetMonitorOutages(monitor.GetName(), start, end)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get outages for %s: %w", monitor.GetName(), err)
+				}
+				for _, outage := range outages {
+					report.Incidents = append(report.Incidents, Incident{Monitor: monitor.GetName(), Group: group, Outage: outage})
+				}
+			}
+
+			groupReport.Monitors = append(groupReport.Monitors, monitorReport)
+			groupReport.Checks += stats.Checks
+			groupReport.Outages += stats.Outages
+			upChecks += stats.UpChecks
+		}
+
+		if groupReport.Checks > 0 {
+			groupReport.UptimePercent = float64(upChecks) / float64(groupReport.Checks) * 100
+		}
+		sort.Slice(groupReport.Monitors, func(i, j int) bool {
+			return groupReport.Monitors[i].Name < groupReport.Monitors[j].Name
+		})
+
+		report.Groups = append(report.Groups, groupReport)
+	}
+
+	sort.SliceStable(report.Incidents, func(i, j int) bool {
+		return report.Incidents[i].Start.Before(report.Incidents[j].Start)
+	})
+
+	return report, nil
+}
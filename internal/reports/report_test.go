@@ -0,0 +1,216 @@
+package reports
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+type stubStats struct {
+	stats   map[string]scheduler.MonitorStats
+	outages map[string][]scheduler.Outage
+}
+
+func (s stubStats) GetMonitorStats(monitorName string, start, end time.Time) (scheduler.MonitorStats, error) {
+	return s.stats[monitorName], nil
+}
+
+func (s stubStats) GetMonitorOutages(monitorName string, start, end time.Time) ([]scheduler.Outage, error) {
+	return s.outages[monitorName], nil
+}
+
+func testLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	return logger
+}
+
+func newTestGenerator(t *testing.T) *Generator {
+	t.Helper()
+
+	logger := testLogger(t)
+	manager := monitors.NewMonitorManager(logger, metrics.NewMetrics(prometheus.NewRegistry()))
+	enabled, disabled := true, false
+	err := manager.LoadMonitors([]models.MonitorGroup{
+		{
+			Name: "core",
+			Monitors: []models.Monitor{
+				{Type: models.MonitorTypeHTTP, Name: "web", URL: "https://www.example.com", Enabled: &enabled, SLO: 99.9},
+				{Type: models.MonitorTypeHTTP, Name: "api", URL: "https://api.example.com", Enabled: &enabled, SLO: 99.9},
+				{Type: models.MonitorTypeHTTP, Name: "legacy", URL: "https://old.example.com", Enabled: &disabled},
+			},
+		},
+		{
+			Name: "edge",
+			Monitors: []models.Monitor{
+				{Type: models.MonitorTypeHTTP, Name: "cdn", URL: "https://cdn.example.com", Enabled: &enabled},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to load monitors: %v", err)
+	}
+
+	base := time.Date(2024, 3, 12, 9, 0, 0, 0, time.UTC)
+	return NewGenerator(stubStats{
+		stats: map[string]scheduler.MonitorStats{
+			"api": {
+				Checks: 100, UpChecks: 90, DownChecks: 10, Outages: 2, OutageSeconds: 600, MTTRSeconds: 300,
+				Latency: scheduler.LatencyStats{AvgMs: 42, P95Ms: 120},
+			},
+			"web":    {Checks: 100, UpChecks: 100},
+			"legacy": {Checks: 100},
+		},
+		outages: map[string][]scheduler.Outage{
+			"api": {
+				{Start: base.Add(24 * time.Hour), End: base.Add(24*time.Hour + 5*time.Minute), DurationSeconds: 300},
+				{Start: base, End: base.Add(5 * time.Minute), DurationSeconds: 300},
+			},
+		},
+	}, manager)
+}
+
+func TestGeneratorGenerate(t *testing.T) {
+	generator := newTestGenerator(t)
+	end := time.Now()
+
+	report, err := generator.Generate(Options{Name: "weekly", Title: "Weekly SLA", Start: end.Add(-7 * 24 * time.Hour), End: end})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(report.Groups) != 2 || report.Groups[0].Name != "core" || report.Groups[1].Name != "edge" {
+		t.Fatalf("expected groups core and edge, got %+v", report.Groups)
+	}
+
+	core := report.Groups[0]
+	if len(core.Monitors) != 2 || core.Monitors[0].Name != "api" || core.Monitors[1].Name != "web" {
+		t.Fatalf("expected enabled monitors api and web sorted, got %+v", core.Monitors)
+	}
+	if core.Checks != 200 || core.Outages != 2 || core.UptimePercent != 95 {
+		t.Errorf("unexpected core totals: %+v", core)
+	}
+	if api := core.Monitors[0]; api.UptimePercent != 90 || api.MTTRSeconds != 300 || api.P95LatencyMs != 120 {
+		t.Errorf("unexpected api summary: %+v", api)
+	}
+	if core.SLOMet != 1 || core.SLOMissed != 1 || core.Monitors[0].MeetsSLO || !core.Monitors[1].MeetsSLO {
+		t.Errorf("expected web to meet and api to miss the 99.9%% objective, got %+v", core)
+	}
+	if report.ListsIncidents() {
+		t.Errorf("expected no incidents unless requested, got %v", report.Incidents)
+	}
+
+	edge := report.Groups[1]
+	if edge.Checks != 0 || edge.UptimePercent != 0 || edge.Monitors[0].HasData() {
+		t.Errorf("expected edge to have no data, got %+v", edge)
+	}
+
+	report, err = generator.Generate(Options{Name: "core", Title: "Core", Groups: []string{"core"}, Start: end.Add(-time.Hour), End: end})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if len(report.Groups) != 1 || report.Groups[0].Name != "core" {
+		t.Errorf("expected only the core group, got %+v", report.Groups)
+	}
+}
+
+func TestGeneratorGenerateSLA(t *testing.T) {
+	generator := newTestGenerator(t)
+	end := time.Now()
+
+	report, err := generator.Generate(Options{
+		Title:     "SLA",
+		Groups:    []string{"core", "edge"},
+		Start:     end.Add(-100 * time.Hour),
+		End:       end,
+		SLO:       89,
+		Incidents: true,
+	})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	core := report.Groups[0]
+	if core.SLOMet != 2 || core.SLOMissed != 0 {
+		t.Errorf("expected the 89%% override to be met by both monitors, got %+v", core)
+	}
+	if api := core.Monitors[0]; api.SLO != 89 || api.AllowedDowntimeSeconds != 11*3600 {
+		t.Errorf("expected 11h allowed downtime at 89%% over 100h, got %+v", api)
+	}
+	if edge := report.Groups[1]; edge.HasSLO() {
+		t.Errorf("expected no SLO result for a group without data, got %+v", edge)
+	}
+
+	if len(report.Incidents) != 2 || report.Incidents[0].Monitor != "api" || report.Incidents[0].Group != "core" ||
+		!report.Incidents[0].Start.Before(report.Incidents[1].Start) {
+		t.Errorf("expected api's two incidents oldest first, got %+v", report.Incidents)
+	}
+}
+
+func TestRenderReport(t *testing.T) {
+	generator := newTestGenerator(t)
+	end := time.Date(2024, 3, 18, 8, 0, 0, 0, time.UTC)
+
+	report, err := generator.Generate(Options{Name: "weekly", Title: "Weekly <SLA>", Start: end.Add(-7 * 24 * time.Hour), End: end})
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	html, err := RenderHTML(report)
+	if err != nil {
+		t.Fatalf("RenderHTML failed: %v", err)
+	}
+	for _, want := range []string{"Weekly &lt;SLA&gt;", "90.00%", "10m 0s", "42 ms", "no data"} {
+		if !strings.Contains(string(html), want) {
+			t.Errorf("expected HTML to contain %q", want)
+		}
+	}
+
+	markdown, err := RenderMarkdown(report)
+	if err != nil {
+		t.Fatalf("RenderMarkdown failed: %v", err)
+	}
+	for _, want := range []string{
+		"**Weekly <SLA>**",
+		"Mar 11, 2024 – Mar 18, 2024",
+		"**core**: 95.00% uptime, 2 outages",
+		"| api | 90.00% | 2 | 10m 0s | 5m 0s | 42 ms / 120 ms |",
+		"| cdn | no data |",
+	} {
+		if !strings.Contains(string(markdown), want) {
+			t.Errorf("expected markdown to contain %q, got:\n%s", want, markdown)
+		}
+	}
+}
+
+func TestFormatSeconds(t *testing.T) {
+	tests := []struct {
+		seconds float64
+		want    string
+	}{
+		{0, "0s"},
+		{42.4, "42s"},
+		{125, "2m 5s"},
+		{3900, "1h 5m"},
+		{90000, "1d 1h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := formatSeconds(tt.seconds); got != tt.want {
+				t.Errorf("formatSeconds(%v) = %q, want %q", tt.seconds, got, tt.want)
+			}
+		})
+	}
+}
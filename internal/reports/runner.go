@@ -0,0 +1,318 @@
+package reports
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	defaultReportPeriod = "7d"
+	defaultSMTPPort     = 587
+	webhookTimeout      = 10 * time.Second
+)
+
+// scheduledReport is a configured report with its parsed schedule
+type scheduledReport struct {
+	cfg      config.ReportScheduleConfig
+	title    string
+	schedule *Schedule
+	period   time.Duration
+	location *time.Location
+}
+
+// Runner generates configured reports on their schedules, delivers them and
+// keeps the latest of each in memory
+type Runner struct {
+	reports   []*scheduledReport
+	generator *Generator
+	smtp      config.SMTPConfig
+	client    *http.Client
+	logger    *logging.Logger
+
+	// sendMail delivers email; replaced in tests
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+	latest map[string]*Report
+	mu     sync.RWMutex
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+}
+
+// NewRunner creates a report runner, parsing each schedule's cron
+// expression, period and timezone
+func NewRunner(cfg config.ReportsConfig, generator *Generator, logger *logging.Logger) (*Runner, error) {
+	runner := &Runner{
+		generator: generator,
+		smtp:      cfg.SMTP,
+		client:    &http.Client{Timeout: webhookTimeout},
+		logger:    logger,
+		sendMail:  smtp.SendMail,
+		latest:    make(map[string]*Report),
+		stopCh:    make(chan struct{}),
+	}
+	if runner.smtp.Port == 0 {
+		runner.smtp.Port = defaultSMTPPort
+	}
+
+	for _, reportCfg := range cfg.Schedules {
+		schedule, err := ParseSchedule(reportCfg.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("report %s: %w", reportCfg.Name, err)
+		}
+
+		periodStr := reportCfg.Period
+		if periodStr == "" {
+			periodStr = defaultReportPeriod
+		}
+		period, err := models.ParsePeriod(periodStr)
+		if err != nil {
+			return nil, fmt.Errorf("report %s: %w", reportCfg.Name, err)
+		}
+
+		location := time.Local
+		if reportCfg.Timezone != "" {
+			if location, err = time.LoadLocation(reportCfg.Timezone); err != nil {
+				return nil, fmt.Errorf("report %s: invalid timezone: %w", reportCfg.Name, err)
+			}
+		}
+
+		title := reportCfg.Title
+		if title == "" {
+			title = reportCfg.Name
+		}
+
+		runner.reports = append(runner.reports, &scheduledReport{
+			cfg:      reportCfg,
+			title:    title,
+			schedule: schedule,
+			period:   period,
+			location: location,
+		})
+	}
+
+	return runner, nil
+}
+
+// Start schedules every report
+func (r *Runner) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return nil
+	}
+
+	for _, report := range r.reports {
+		r.wg.Add(1)
+		go r.scheduleLoop(ctx, report)
+	}
+
+	r.running = true
+	return nil
+}
+
+// Stop stops scheduling reports, waiting for any being delivered
+func (r *Runner) Stop() error {
+	r.mu.Lock()
+	if !r.running {
+		r.mu.Unlock()
+		return nil
+	}
+	r.running = false
+	r.mu.Unlock()
+
+	close(r.stopCh)
+	r.wg.Wait()
+	return nil
+}
+
+// scheduleLoop runs a report each time its schedule fires
+func (r *Runner) scheduleLoop(ctx context.Context, report *scheduledReport) {
+	defer r.wg.Done()
+
+	for {
+		next := report.schedule.Next(time.Now().In(report.location))
+		if next.IsZero() {
+			r.logger.WithComponent(logging.ComponentReports).
+				WithFields(map[string]interface{}{
+					"report": report.cfg.Name,
+					"cron":   report.cfg.Cron,
+				}).
+				Warn("Report schedule never fires, not scheduling it")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-r.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if _, err := r.run(ctx, report); err != nil {
+			r.logger.WithComponent(logging.ComponentReports).
+				WithError(err).
+				WithFields(map[string]interface{}{"report": report.cfg.Name}).
+				Error("Failed to run scheduled report")
+		}
+	}
+}
+
+// Run generates and delivers the named report now
+func (r *Runner) Run(ctx context.Context, name string) (*Report, error) {
+	for _, report := range r.reports {
+		if report.cfg.Name == name {
+			return r.run(ctx, report)
+		}
+	}
+	return nil, fmt.Errorf("report %s not found", name)
+}
+
+// run generates a report covering the period up to now, stores it as the
+// latest and delivers it. The report is kept even if delivery fails.
+func (r *Runner) run(ctx context.Context, report *scheduledReport) (*Report, error) {
+	end := time.Now().In(report.location)
+	generated, err := r.generator.Generate(Options{
+		Name:   report.cfg.Name,
+		Title:  report.title,
+		Groups: report.cfg.Groups,
+		Start:  end.Add(-report.period),
+		End:    end,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.latest[report.cfg.Name] = generated
+	r.mu.Unlock()
+
+	var errs []string
+	if len(report.cfg.Email) > 0 {
+		if err := r.deliverEmail(report, generated); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if report.cfg.WebhookURL != "" {
+		if err := r.deliverWebhook(ctx, report, generated); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return generated, fmt.Errorf("failed to deliver report: %s", strings.Join(errs, "; "))
+	}
+
+	r.logger.WithComponent(logging.ComponentReports).
+		WithFields(map[string]interface{}{
+			"report": report.cfg.Name,
+			"groups": len(generated.Groups),
+		}).
+		Info("Delivered report")
+	return generated, nil
+}
+
+// Latest returns the most recently generated report with the given name
+func (r *Runner) Latest(name string) (*Report, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	report, ok := r.latest[name]
+	return report, ok
+}
+
+// LatestAll returns the most recently generated report of each schedule,
+// sorted by name
+func (r *Runner) LatestAll() []*Report {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reports := make([]*Report, 0, len(r.latest))
+	for _, report := range r.latest {
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Name < reports[j].Name })
+	return reports
+}
+
+// deliverEmail sends the report as an HTML email to the schedule's recipients
+func (r *Runner) deliverEmail(report *scheduledReport, generated *Report) error {
+	body, err := RenderHTML(generated)
+	if err != nil {
+		return err
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", r.smtp.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(report.cfg.Email, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", generated.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", generated.GeneratedAt.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.Write(body)
+
+	var auth smtp.Auth
+	if r.smtp.Username != "" {
+		auth = smtp.PlainAuth("", r.smtp.Username, r.smtp.Password, r.smtp.Host)
+	}
+
+	addr := net.JoinHostPort(r.smtp.Host, strconv.Itoa(r.smtp.Port))
+	if err := r.sendMail(addr, auth, r.smtp.From, report.cfg.Email, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// deliverWebhook POSTs the report as {"title", "text"} JSON with markdown text
+func (r *Runner) deliverWebhook(ctx context.Context, report *scheduledReport, generated *Report) error {
+	text, err := RenderMarkdown(generated)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"title": generated.Title,
+		"text":  string(text),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, report.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "HallMonitor/1.0")
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post report: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package reports
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+)
+
+func TestNewRunnerErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		report config.ReportScheduleConfig
+	}{
+		{name: "invalid cron", report: config.ReportScheduleConfig{Name: "weekly", Cron: "every monday"}},
+		{name: "invalid period", report: config.ReportScheduleConfig{Name: "weekly", Cron: "@weekly", Period: "week"}},
+		{name: "invalid timezone", report: config.ReportScheduleConfig{Name: "weekly", Cron: "@weekly", Timezone: "Mars/Olympus"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.ReportsConfig{Schedules: []config.ReportScheduleConfig{tt.report}}
+			if _, err := NewRunner(cfg, nil, testLogger(t)); err == nil {
+				t.Error("expected NewRunner to fail")
+			}
+		})
+	}
+}
+
+func TestRunnerRunDeliversReport(t *testing.T) {
+	var payload map[string]string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("failed to decode webhook payload: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer webhook.Close()
+
+	cfg := config.ReportsConfig{
+		SMTP: config.SMTPConfig{Host: "mail.example.com", Username: "reports", Password: "secret", From: "monitor@example.com"},
+		Schedules: []config.ReportScheduleConfig{{
+			Name:       "weekly",
+			Title:      "Weekly SLA",
+			Cron:       "0 8 * * 1",
+			Timezone:   "Europe/Berlin",
+			Email:      []string{"ops@example.com", "cto@example.com"},
+			WebhookURL: webhook.URL,
+		}},
+	}
+	runner, err := NewRunner(cfg, newTestGenerator(t), testLogger(t))
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+
+	var (
+		mailAddr string
+		mailAuth smtp.Auth
+		mailTo   []string
+		mailMsg  string
+	)
+	runner.sendMail = func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+		mailAddr, mailAuth, mailTo, mailMsg = addr, auth, to, string(msg)
+		return nil
+	}
+
+	if _, ok := runner.Latest("weekly"); ok {
+		t.Fatal("expected no report before the first run")
+	}
+
+	report, err := runner.Run(context.Background(), "weekly")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Title != "Weekly SLA" || report.End.Sub(report.Start) != 7*24*time.Hour {
+		t.Errorf("expected a 7 day Weekly SLA report, got %q over %v", report.Title, report.End.Sub(report.Start))
+	}
+	if report.End.Location().String() != "Europe/Berlin" {
+		t.Errorf("expected report times in Europe/Berlin, got %v", report.End.Location())
+	}
+
+	if mailAddr != "mail.example.com:587" || mailAuth == nil || len(mailTo) != 2 {
+		t.Errorf("unexpected email delivery: addr=%s auth=%v to=%v", mailAddr, mailAuth, mailTo)
+	}
+	for _, want := range []string{"Subject: Weekly SLA\r\n", "Content-Type: text/html", "ops@example.com, cto@example.com"} {
+		if !strings.Contains(mailMsg, want) {
+			t.Errorf("expected email to contain %q", want)
+		}
+	}
+
+	if payload["title"] != "Weekly SLA" || !strings.Contains(payload["text"], "| api |") {
+		t.Errorf("unexpected webhook payload: %v", payload)
+	}
+
+	if latest, ok := runner.Latest("weekly"); !ok || latest != report {
+		t.Error("expected the run to be kept as the latest report")
+	}
+	if all := runner.LatestAll(); len(all) != 1 || all[0] != report {
+		t.Errorf("expected LatestAll to return the report, got %v", all)
+	}
+
+	if _, err := runner.Run(context.Background(), "missing"); err == nil {
+		t.Error("expected an error for an unknown report")
+	}
+}
+
+func TestRunnerRunKeepsReportWhenDeliveryFails(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer webhook.Close()
+
+	cfg := config.ReportsConfig{
+		Schedules: []config.ReportScheduleConfig{{Name: "daily", Cron: "@daily", Period: "24h", WebhookURL: webhook.URL}},
+	}
+	runner, err := NewRunner(cfg, newTestGenerator(t), testLogger(t))
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+
+	if _, err := runner.Run(context.Background(), "daily"); err == nil || !strings.Contains(err.Error(), "status 502") {
+		t.Errorf("expected a delivery error, got %v", err)
+	}
+	if latest, ok := runner.Latest("daily"); !ok || latest.Title != "daily" {
+		t.Errorf("expected the report to be kept with the name as title, got %+v", latest)
+	}
+}
+
+func TestRunnerStartStop(t *testing.T) {
+	cfg := config.ReportsConfig{
+		Schedules: []config.ReportScheduleConfig{{Name: "weekly", Cron: "@weekly", WebhookURL: "http://127.0.0.1:1"}},
+	}
+	runner, err := NewRunner(cfg, newTestGenerator(t), testLogger(t))
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+
+	if err := runner.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := runner.Start(context.Background()); err != nil {
+		t.Fatalf("expected second Start to be a no-op, got %v", err)
+	}
+	if err := runner.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := runner.Stop(); err != nil {
+		t.Fatalf("expected second Stop to be a no-op, got %v", err)
+	}
+}
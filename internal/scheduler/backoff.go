@@ -80,6 +80,13 @@ func (bm *BackoffManager) GetBackoff(monitorName string) time.Duration {
 	return backoff
 }
 
+// Failures returns a monitor's consecutive failed checks
+func (bm *BackoffManager) Failures(monitorName string) int {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	return bm.failures[monitorName]
+}
+
 // ShouldCheck determines if a monitor should be checked based on backoff
 func (bm *BackoffManager) ShouldCheck(monitorName string, lastCheckTime time.Time) bool {
 	backoff := bm.GetBackoff(monitorName)
@@ -0,0 +1,98 @@
+package scheduler
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Fault is a simulated failure injected into a monitor until it expires
+type Fault struct {
+	Monitor   string    `json:"monitor"`
+	Reason    string    `json:"reason"`
+	StartedAt time.Time `json:"started_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// FaultInjector tracks simulated failures used to exercise alert routing.
+// While a fault is active the monitor's checks are replaced by synthetic down
+// results.
+type FaultInjector struct {
+	faults map[string]Fault // Monitor name -> active fault
+	mu     sync.RWMutex
+	now    func() time.Time
+}
+
+// NewFaultInjector creates a new fault injector
+func NewFaultInjector() *FaultInjector {
+	return &FaultInjector{
+		faults: make(map[string]Fault),
+		now:    time.Now,
+	}
+}
+
+// Inject fails monitorName for the given duration, replacing any active fault
+func (fi *FaultInjector) Inject(monitorName string, duration time.Duration, reason string) Fault {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	now := fi.now()
+	fault := Fault{
+		Monitor:   monitorName,
+		Reason:    reason,
+		StartedAt: now,
+		ExpiresAt: now.Add(duration),
+	}
+	fi.faults[monitorName] = fault
+	return fault
+}
+
+// Clear removes the fault for monitorName, reporting whether one was active
+func (fi *FaultInjector) Clear(monitorName string) bool {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	fault, ok := fi.faults[monitorName]
+	delete(fi.faults, monitorName)
+	return ok && fi.now().Before(fault.ExpiresAt)
+}
+
+// Active returns the unexpired fault for monitorName, if any
+func (fi *FaultInjector) Active(monitorName string) (Fault, bool) {
+	fi.mu.RLock()
+	fault, ok := fi.faults[monitorName]
+	fi.mu.RUnlock()
+
+	if !ok {
+		return Fault{}, false
+	}
+	if !fi.now().Before(fault.ExpiresAt) {
+		fi.mu.Lock()
+		if current, ok := fi.faults[monitorName]; ok && current == fault {
+			delete(fi.faults, monitorName)
+		}
+		fi.mu.Unlock()
+		return Fault{}, false
+	}
+	return fault, true
+}
+
+// List returns all unexpired faults sorted by monitor name
+func (fi *FaultInjector) List() []Fault {
+	fi.mu.Lock()
+	defer fi.mu.Unlock()
+
+	now := fi.now()
+	faults := make([]Fault, 0, len(fi.faults))
+	for name, fault := range fi.faults {
+		if !now.Before(fault.ExpiresAt) {
+			delete(fi.faults, name)
+			continue
+		}
+		faults = append(faults, fault)
+	}
+	sort.Slice(faults, func(i, j int) bool {
+		return faults[i].Monitor < faults[j].Monitor
+	})
+	return faults
+}
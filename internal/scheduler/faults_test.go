@@ -0,0 +1,112 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestFaultInjectorLifecycle(t *testing.T) {
+	now := time.Now()
+	fi := NewFaultInjector()
+	fi.now = func() time.Time { return now }
+
+	fi.Inject("api", 10*time.Minute, "alert drill")
+
+	fault, ok := fi.Active("api")
+	if !ok {
+		t.Fatal("expected fault to be active")
+	}
+	if fault.Reason != "alert drill" || !fault.ExpiresAt.Equal(now.Add(10*time.Minute)) {
+		t.Errorf("unexpected fault: %+v", fault)
+	}
+	if _, ok := fi.Active("other"); ok {
+		t.Error("expected no fault for other monitor")
+	}
+	if faults := fi.List(); len(faults) != 1 {
+		t.Errorf("expected 1 fault listed, got %d", len(faults))
+	}
+
+	// Faults expire on their own
+	now = now.Add(10 * time.Minute)
+	if _, ok := fi.Active("api"); ok {
+		t.Error("expected fault to expire")
+	}
+	if faults := fi.List(); len(faults) != 0 {
+		t.Errorf("expected expired fault to be dropped, got %+v", faults)
+	}
+
+	fi.Inject("api", time.Minute, "")
+	if !fi.Clear("api") {
+		t.Error("expected Clear to report an active fault")
+	}
+	if fi.Clear("api") {
+		t.Error("expected second Clear to report nothing to clear")
+	}
+}
+
+// countingMonitor counts how many times it is checked
+type countingMonitor struct {
+	mockMonitor
+	checks int32
+}
+
+func (m *countingMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
+	atomic.AddInt32(&m.checks, 1)
+	return m.mockMonitor.Check(ctx)
+}
+
+func TestWorkerInjectsSyntheticFailure(t *testing.T) {
+	wp := NewWorkerPool(1, testLogger(t), nil)
+	worker := &Worker{id: 1, pool: wp, logger: testLogger(t)}
+
+	rs := NewResultStore(10)
+	bm := NewBackoffManager()
+	fi := NewFaultInjector()
+	fi.Inject("api", time.Minute, "alert drill")
+
+	var dispatched *models.MonitorResult
+	monitor := &countingMonitor{mockMonitor: mockMonitor{name: "api", group: "core"}}
+	worker.processJob(context.Background(), &MonitorJob{
+		Monitor:     monitor,
+		ResultStore: rs,
+		Backoff:     bm,
+		Faults:      fi,
+		ScheduledAt: time.Now(),
+		OnResult:    func(result *models.MonitorResult) { dispatched = result },
+	})
+
+	if got := atomic.LoadInt32(&monitor.checks); got != 0 {
+		t.Errorf("expected real check to be skipped, got %d checks", got)
+	}
+
+	result := rs.GetLatestResult("api")
+	if result == nil {
+		t.Fatal("expected synthetic result to be stored")
+	}
+	if result.Status != models.StatusDown || !result.Synthetic {
+		t.Errorf("expected synthetic down result, got %+v", result)
+	}
+	if result.Error != "synthetic failure injected: alert drill" {
+		t.Errorf("unexpected error: %q", result.Error)
+	}
+	if dispatched != result {
+		t.Error("expected synthetic result to be dispatched to handlers")
+	}
+	if backoff := bm.GetBackoff("api"); backoff != 0 {
+		t.Errorf("expected backoff to be untouched, got %s", backoff)
+	}
+
+	// Real checks resume once the fault is cleared
+	fi.Clear("api")
+	worker.processJob(context.Background(), &MonitorJob{Monitor: monitor, ResultStore: rs, Faults: fi})
+	if got := atomic.LoadInt32(&monitor.checks); got != 1 {
+		t.Errorf("expected real check after clearing, got %d checks", got)
+	}
+	if result := rs.GetLatestResult("api"); result.Synthetic || result.Status != models.StatusUp {
+		t.Errorf("expected real up result, got %+v", result)
+	}
+}
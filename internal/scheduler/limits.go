@@ -0,0 +1,66 @@
+package scheduler
+
+import "sync"
+
+// GroupLimiter caps how many checks from the same group may run at once, so a
+// group of monitors pointing at one backend does not hammer it with
+// simultaneous checks
+type GroupLimiter struct {
+	limits map[string]int // Group name -> max concurrent checks
+	active map[string]int // Group name -> checks in flight
+	mu     sync.Mutex
+}
+
+// NewGroupLimiter creates a limiter with no limits
+func NewGroupLimiter() *GroupLimiter {
+	return &GroupLimiter{
+		limits: make(map[string]int),
+		active: make(map[string]int),
+	}
+}
+
+// SetLimits replaces the per-group limits; groups without a positive limit are
+// unrestricted. Checks already in flight keep counting against the new limits.
+func (gl *GroupLimiter) SetLimits(limits map[string]int) {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+
+	gl.limits = make(map[string]int, len(limits))
+	for group, limit := range limits {
+		if limit > 0 {
+			gl.limits[group] = limit
+		}
+	}
+}
+
+// TryAcquire reserves a check slot for group, returning false when the group
+// is already at its limit. Every successful call must be paired with Release.
+func (gl *GroupLimiter) TryAcquire(group string) bool {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+
+	if limit, ok := gl.limits[group]; ok && gl.active[group] >= limit {
+		return false
+	}
+	gl.active[group]++
+	return true
+}
+
+// Release frees a check slot reserved by TryAcquire
+func (gl *GroupLimiter) Release(group string) {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+
+	if gl.active[group] <= 1 {
+		delete(gl.active, group)
+		return
+	}
+	gl.active[group]--
+}
+
+// Active returns the number of checks in flight for group
+func (gl *GroupLimiter) Active(group string) int {
+	gl.mu.Lock()
+	defer gl.mu.Unlock()
+	return gl.active[group]
+}
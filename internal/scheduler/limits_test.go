@@ -0,0 +1,124 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestGroupLimiter(t *testing.T) {
+	gl := NewGroupLimiter()
+	gl.SetLimits(map[string]int{"db": 2, "web": 0})
+
+	tests := []struct {
+		name  string
+		group string
+		want  bool
+	}{
+		{name: "first db slot", group: "db", want: true},
+		{name: "second db slot", group: "db", want: true},
+		{name: "db at limit", group: "db", want: false},
+		{name: "zero limit is unrestricted", group: "web", want: true},
+		{name: "unlisted group is unrestricted", group: "edge", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gl.TryAcquire(tt.group); got != tt.want {
+				t.Errorf("TryAcquire(%s) = %v, want %v", tt.group, got, tt.want)
+			}
+		})
+	}
+
+	gl.Release("db")
+	if !gl.TryAcquire("db") {
+		t.Error("expected a db slot after release")
+	}
+	if got := gl.Active("db"); got != 2 {
+		t.Errorf("expected 2 active db checks, got %d", got)
+	}
+
+	// Lowering the limit applies to checks already in flight
+	gl.SetLimits(map[string]int{"db": 1})
+	gl.Release("db")
+	if gl.TryAcquire("db") {
+		t.Error("expected db to stay at its lowered limit")
+	}
+}
+
+func TestSchedulerDefersChecksAtGroupLimit(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	manager := monitors.NewMonitorManager(logger, metricsInstance)
+
+	var monitorList []monitors.Monitor
+	for _, name := range []string{"db-1", "db-2", "db-3"} {
+		monitorList = append(monitorList, &stubMonitor{
+			name:        name,
+			group:       "db",
+			monitorType: models.MonitorTypeTCP,
+			interval:    time.Minute,
+			enabled:     true,
+		})
+	}
+	setMonitorManagerMonitors(t, manager, monitorList)
+
+	sched := NewScheduler(logger, metricsInstance, manager)
+	sched.SetGroupLimits(map[string]int{"db": 2})
+
+	now := time.Now()
+	nextExecution := map[string]time.Time{
+		"db-1": now.Add(-time.Second),
+		"db-2": now.Add(-time.Second),
+		"db-3": now.Add(-time.Second),
+	}
+
+	// Workers are not started, so submitted jobs hold their group slots
+	sched.checkAndScheduleMonitors(context.Background(), now, nextExecution)
+
+	if got := sched.groupLimits.Active("db"); got != 2 {
+		t.Fatalf("expected 2 checks in flight, got %d", got)
+	}
+	if got := sched.workers.PendingJobs(); got != 2 {
+		t.Fatalf("expected 2 queued jobs, got %d", got)
+	}
+	if !nextExecution["db-3"].Before(now) {
+		t.Errorf("expected deferred monitor to stay due, next execution %v", nextExecution["db-3"])
+	}
+
+	// Finishing a check frees the slot for the deferred monitor
+	job := <-sched.workers.jobQueue
+	job.OnDone()
+	sched.checkAndScheduleMonitors(context.Background(), now.Add(time.Second), nextExecution)
+	if got := sched.workers.PendingJobs(); got != 2 {
+		t.Errorf("expected deferred monitor to be queued, got %d pending jobs", got)
+	}
+	if !nextExecution["db-3"].After(now) {
+		t.Errorf("expected deferred monitor to be rescheduled, next execution %v", nextExecution["db-3"])
+	}
+}
+
+func TestSchedulerSetWorkerCount(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	sched := NewScheduler(logger, metricsInstance, monitors.NewMonitorManager(logger, metricsInstance))
+
+	sched.SetWorkerCount(25)
+	if got := sched.GetStats().WorkerCount; got != 25 {
+		t.Errorf("expected 25 workers, got %d", got)
+	}
+	if got := cap(sched.workers.jobQueue); got != 50 {
+		t.Errorf("expected queue sized for 25 workers, got %d", got)
+	}
+
+	sched.SetWorkerCount(0)
+	if got := sched.GetStats().WorkerCount; got != defaultWorkerCount {
+		t.Errorf("expected default of %d workers, got %d", defaultWorkerCount, got)
+	}
+}
@@ -0,0 +1,126 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// QueueEntry describes when a monitor's next check is scheduled
+type QueueEntry struct {
+	Monitor             string               `json:"monitor"`
+	Group               string               `json:"group"`
+	Enabled             bool                 `json:"enabled"`
+	Interval            time.Duration        `json:"interval"`
+	NextRun             *time.Time           `json:"next_run,omitempty"` // Unset while disabled or the scheduler is stopped
+	DeferredUntil       *time.Time           `json:"deferred_until,omitempty"`
+	Backoff             time.Duration        `json:"backoff"`
+	ConsecutiveFailures int                  `json:"consecutive_failures"`
+	LastRun             *time.Time           `json:"last_run,omitempty"`
+	LastStatus          models.MonitorStatus `json:"last_status,omitempty"`
+}
+
+// Queue returns every monitor's schedule, ordered by next run with
+// unscheduled monitors last
+func (s *Scheduler) Queue() []QueueEntry {
+	s.scheduleMu.RLock()
+	defer s.scheduleMu.RUnlock()
+
+	monitors := s.monitorManager.GetMonitors()
+	entries := make([]QueueEntry, 0, len(monitors))
+	for _, monitor := range monitors {
+		name := monitor.GetName()
+		entry := QueueEntry{
+			Monitor:             name,
+			Group:               monitor.GetGroup(),
+			Enabled:             monitor.IsEnabled(),
+			Interval:            monitor.GetConfig().Interval.ToDuration(),
+			Backoff:             s.backoff.GetBackoff(name),
+			ConsecutiveFailures: s.backoff.Failures(name),
+		}
+		if next, ok := s.schedule[name]; ok {
+			entry.NextRun = &next
+		}
+		if until, ok := s.deferrals[name]; ok {
+			entry.DeferredUntil = &until
+		}
+		if latest := s.resultStore.GetLatestResult(name); latest != nil {
+			lastRun := latest.Timestamp
+			entry.LastRun = &lastRun
+			entry.LastStatus = latest.Status
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i].NextRun, entries[j].NextRun
+		switch {
+		case a == nil || b == nil:
+			if (a == nil) != (b == nil) {
+				return b == nil
+			}
+		case !a.Equal(*b):
+			return a.Before(*b)
+		}
+		return entries[i].Monitor < entries[j].Monitor
+	})
+	return entries
+}
+
+// DeferMonitor pushes a monitor's next check out to until. Checks then resume
+// at the monitor's usual interval from that time.
+func (s *Scheduler) DeferMonitor(monitorName string, until time.Time) error {
+	monitor := s.monitorManager.GetMonitorByName(monitorName)
+	if monitor == nil {
+		return fmt.Errorf("monitor %s not found", monitorName)
+	}
+	if !monitor.IsEnabled() {
+		return fmt.Errorf("monitor %s is disabled", monitorName)
+	}
+
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	// The scheduling loop applies the deferral on its next tick; the snapshot
+	// is updated now so the queue reflects it straight away
+	s.deferrals[monitorName] = until
+	if _, scheduled := s.schedule[monitorName]; scheduled {
+		s.schedule[monitorName] = until
+	}
+	return nil
+}
+
+// applyDeferrals moves deferred monitors' next execution. Deferrals stay
+// listed until the deferred check is scheduled.
+func (s *Scheduler) applyDeferrals(now time.Time, nextExecution map[string]time.Time) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	for name, until := range s.deferrals {
+		if !until.After(now) {
+			delete(s.deferrals, name)
+			continue
+		}
+		if _, scheduled := nextExecution[name]; scheduled {
+			nextExecution[name] = until
+		}
+	}
+}
+
+// publishSchedule records the loop's execution schedule for Queue
+func (s *Scheduler) publishSchedule(nextExecution map[string]time.Time) {
+	s.scheduleMu.Lock()
+	defer s.scheduleMu.Unlock()
+
+	schedule := make(map[string]time.Time, len(nextExecution))
+	for name, next := range nextExecution {
+		if until, ok := s.deferrals[name]; ok {
+			// A deferral made during the tick is applied on the next one
+			next = until
+		}
+		schedule[name] = next
+	}
+	s.schedule = schedule
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestSchedulerQueueAndDefer(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	manager := monitors.NewMonitorManager(logger, metricsInstance)
+
+	setMonitorManagerMonitors(t, manager, []monitors.Monitor{
+		&stubMonitor{name: "web", group: "edge", monitorType: models.MonitorTypeHTTP, interval: time.Minute, enabled: true},
+		&stubMonitor{name: "db", group: "core", monitorType: models.MonitorTypeTCP, interval: time.Minute, enabled: true},
+		&stubMonitor{name: "old", group: "core", monitorType: models.MonitorTypeTCP, interval: time.Minute, enabled: false},
+	})
+
+	sched := NewScheduler(logger, metricsInstance, manager)
+	sched.backoff.RecordFailure("db")
+	sched.backoff.RecordFailure("db")
+	sched.resultStore.StoreResult("db", &models.MonitorResult{Monitor: "db", Status: models.StatusDown, Timestamp: time.Now()})
+
+	now := time.Now()
+	nextExecution := map[string]time.Time{
+		"web": now.Add(20 * time.Second),
+		"db":  now.Add(10 * time.Second),
+	}
+	sched.publishSchedule(nextExecution)
+
+	queue := sched.Queue()
+	if len(queue) != 3 || queue[0].Monitor != "db" || queue[1].Monitor != "web" || queue[2].Monitor != "old" {
+		t.Fatalf("expected queue ordered db, web, old, got %+v", queue)
+	}
+	if queue[0].ConsecutiveFailures != 2 || queue[0].Backoff != 2*time.Second {
+		t.Errorf("expected db backoff after 2 failures, got %+v", queue[0])
+	}
+	if queue[0].LastRun == nil || queue[0].LastStatus != models.StatusDown {
+		t.Errorf("expected db's last run, got %+v", queue[0])
+	}
+	if queue[2].NextRun != nil || queue[2].Enabled {
+		t.Errorf("expected disabled monitor to be unscheduled, got %+v", queue[2])
+	}
+
+	if err := sched.DeferMonitor("old", now.Add(time.Hour)); err == nil {
+		t.Error("expected an error deferring a disabled monitor")
+	}
+	if err := sched.DeferMonitor("missing", now.Add(time.Hour)); err == nil {
+		t.Error("expected an error deferring an unknown monitor")
+	}
+
+	until := now.Add(time.Hour)
+	if err := sched.DeferMonitor("db", until); err != nil {
+		t.Fatalf("DeferMonitor returned error: %v", err)
+	}
+	// The queue reflects the deferral before the loop applies it
+	if queue := sched.Queue(); queue[0].Monitor != "web" || !queue[1].NextRun.Equal(until) || !queue[1].DeferredUntil.Equal(until) {
+		t.Errorf("expected db to move behind web, got %+v", queue)
+	}
+
+	// The loop's tick must not overwrite the deferral
+	sched.applyDeferrals(now, nextExecution)
+	if !nextExecution["db"].Equal(until) {
+		t.Fatalf("expected db's next execution to be deferred, got %v", nextExecution["db"])
+	}
+	nextExecution["db"] = now.Add(5 * time.Second)
+	sched.applyDeferrals(now.Add(time.Second), nextExecution)
+	if !nextExecution["db"].Equal(until) {
+		t.Errorf("expected the deferral to hold until it passes, got %v", nextExecution["db"])
+	}
+
+	// Once due, the deferral is forgotten and the monitor runs
+	sched.applyDeferrals(until.Add(time.Second), nextExecution)
+	if queue := sched.Queue(); queue[1].DeferredUntil != nil {
+		t.Errorf("expected the deferral to be cleared, got %+v", queue[1])
+	}
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter caps how many checks start per second across all monitors, so
+// a burst of due monitors does not flood the network or the targets. It is a
+// token bucket holding up to one second of checks.
+type RateLimiter struct {
+	perSecond float64 // 0 means unlimited
+	tokens    float64
+	last      time.Time
+	mu        sync.Mutex
+}
+
+// NewRateLimiter creates a limiter with no limit
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{}
+}
+
+// SetRate sets the maximum checks started per second; 0 removes the limit
+func (rl *RateLimiter) SetRate(perSecond int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if perSecond < 0 {
+		perSecond = 0
+	}
+	rl.perSecond = float64(perSecond)
+	rl.tokens = rl.perSecond
+	rl.last = time.Time{}
+}
+
+// Allow reports whether a check may start at now, consuming a token if so
+func (rl *RateLimiter) Allow(now time.Time) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if rl.perSecond == 0 {
+		return true
+	}
+
+	if !rl.last.IsZero() {
+		rl.tokens += now.Sub(rl.last).Seconds() * rl.perSecond
+		if rl.tokens > rl.perSecond {
+			rl.tokens = rl.perSecond
+		}
+	}
+	rl.last = now
+
+	if rl.tokens < 1 {
+		return false
+	}
+	rl.tokens--
+	return true
+}
@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	rl := NewRateLimiter()
+	start := time.Now()
+
+	if !rl.Allow(start) {
+		t.Fatal("expected an unlimited limiter to allow checks")
+	}
+
+	rl.SetRate(2)
+	tests := []struct {
+		name  string
+		after time.Duration
+		want  bool
+	}{
+		{name: "first of the second", want: true},
+		{name: "second of the second", want: true},
+		{name: "over the limit", want: false},
+		{name: "half a second refills one", after: 500 * time.Millisecond, want: true},
+		{name: "refilled token used", after: 500 * time.Millisecond, want: false},
+		{name: "idle time does not bank more than a second", after: time.Minute, want: true},
+		{name: "second after idle", after: time.Minute, want: true},
+		{name: "third after idle", after: time.Minute, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rl.Allow(start.Add(tt.after)); got != tt.want {
+				t.Errorf("Allow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	rl.SetRate(0)
+	if !rl.Allow(start) {
+		t.Error("expected removing the limit to allow checks")
+	}
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"sync"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// RecoveryTracker keeps a down monitor down until enough consecutive checks
+// succeed, so a single lucky success during an outage does not report it up
+type RecoveryTracker struct {
+	successes map[string]int // Monitor name -> consecutive successes while recovering
+	mu        sync.Mutex
+}
+
+// NewRecoveryTracker creates a new recovery tracker
+func NewRecoveryTracker() *RecoveryTracker {
+	return &RecoveryTracker{
+		successes: make(map[string]int),
+	}
+}
+
+// Observe records a check's status given the monitor's previously reported
+// status. It returns the consecutive successes so far and whether the status
+// may be reported as is; a recovery is held until threshold checks succeed.
+func (rt *RecoveryTracker) Observe(monitorName string, previous, current models.MonitorStatus, threshold int) (int, bool) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+
+	if current != models.StatusUp || threshold <= 1 {
+		delete(rt.successes, monitorName)
+		return 0, true
+	}
+
+	successes, recovering := rt.successes[monitorName]
+	if !recovering && previous != models.StatusDown {
+		return 0, true
+	}
+
+	successes++
+	if successes >= threshold {
+		delete(rt.successes, monitorName)
+		return successes, true
+	}
+	rt.successes[monitorName] = successes
+	return successes, false
+}
+
+// Reset forgets a monitor's recovery progress
+func (rt *RecoveryTracker) Reset(monitorName string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	delete(rt.successes, monitorName)
+}
@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestRecoveryTrackerObserve(t *testing.T) {
+	type step struct {
+		previous  models.MonitorStatus
+		current   models.MonitorStatus
+		successes int
+		confirmed bool
+	}
+
+	tests := []struct {
+		name      string
+		threshold int
+		steps     []step
+	}{
+		{
+			name:      "disabled",
+			threshold: 0,
+			steps:     []step{{previous: models.StatusDown, current: models.StatusUp, confirmed: true}},
+		},
+		{
+			name:      "already up",
+			threshold: 3,
+			steps:     []step{{previous: models.StatusUp, current: models.StatusUp, confirmed: true}},
+		},
+		{
+			name:      "confirmed after threshold",
+			threshold: 3,
+			steps: []step{
+				{previous: models.StatusDown, current: models.StatusUp, successes: 1},
+				{previous: models.StatusDown, current: models.StatusUp, successes: 2},
+				{previous: models.StatusDown, current: models.StatusUp, successes: 3, confirmed: true},
+				{previous: models.StatusUp, current: models.StatusUp, confirmed: true},
+			},
+		},
+		{
+			name:      "failure restarts the count",
+			threshold: 2,
+			steps: []step{
+				{previous: models.StatusDown, current: models.StatusUp, successes: 1},
+				{previous: models.StatusDown, current: models.StatusDown, confirmed: true},
+				{previous: models.StatusDown, current: models.StatusUp, successes: 1},
+				{previous: models.StatusDown, current: models.StatusUp, successes: 2, confirmed: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewRecoveryTracker()
+			for i, s := range tt.steps {
+				successes, confirmed := tracker.Observe("api", s.previous, s.current, tt.threshold)
+				if successes != s.successes || confirmed != s.confirmed {
+					t.Fatalf("step %d: Observe() = (%d, %v), want (%d, %v)", i, successes, confirmed, s.successes, s.confirmed)
+				}
+			}
+		})
+	}
+}
+
+// recoveringMonitor reports the statuses it is given in order
+type recoveringMonitor struct {
+	mockMonitor
+	threshold int
+	statuses  []models.MonitorStatus
+}
+
+func (m *recoveringMonitor) GetConfig() *models.Monitor {
+	config := m.mockMonitor.GetConfig()
+	config.RecoveryThreshold = m.threshold
+	return config
+}
+
+func (m *recoveringMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
+	status := m.statuses[0]
+	m.statuses = m.statuses[1:]
+	return &models.MonitorResult{Monitor: m.name, Status: status, Timestamp: time.Now()}, nil
+}
+
+func TestWorkerConfirmsRecovery(t *testing.T) {
+	monitor := &recoveringMonitor{
+		mockMonitor: mockMonitor{name: "api"},
+		threshold:   2,
+		statuses:    []models.MonitorStatus{models.StatusDown, models.StatusUp, models.StatusUp},
+	}
+	worker := &Worker{id: 1, pool: NewWorkerPool(1, testLogger(t), nil), logger: testLogger(t)}
+	store := NewResultStore(10)
+	backoff := NewBackoffManager()
+	job := &MonitorJob{Monitor: monitor, ResultStore: store, Backoff: backoff, Recovery: NewRecoveryTracker()}
+
+	want := []models.MonitorStatus{models.StatusDown, models.StatusDown, models.StatusUp}
+	for i, status := range want {
+		worker.processJob(context.Background(), job)
+
+		latest := store.GetLatestResult("api")
+		if latest.Status != status {
+			t.Fatalf("check %d: expected %s, got %s", i, status, latest.Status)
+		}
+	}
+
+	results := store.GetResults("api", 0)
+	var held *models.MonitorResult
+	for _, result := range results {
+		if strings.HasPrefix(result.Error, "recovering") {
+			held = result
+		}
+	}
+	if held == nil || held.Error != "recovering: 1 of 2 consecutive checks succeeded" {
+		t.Errorf("expected the held result to explain the pending recovery, got %+v", held)
+	}
+	if backoff.GetBackoff("api") != 0 {
+		t.Errorf("expected successful checks to clear backoff while recovering")
+	}
+}
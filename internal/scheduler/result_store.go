@@ -1,13 +1,19 @@
 package scheduler
 
 import (
+	"fmt"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/1broseidon/hallmonitor/internal/storage"
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
+// maxCountedResults bounds how many results are loaded to count them when the
+// persistent store cannot count on its own
+const maxCountedResults = 100000
+
 // PersistentStore interface for persistent storage backend
 type PersistentStore interface {
 	StoreResult(result *models.MonitorResult) error
@@ -21,6 +27,7 @@ type ResultStore struct {
 	results         map[string]*MonitorResults
 	mu              sync.RWMutex
 	persistentStore PersistentStore // Optional BadgerDB backend
+	sampler         *resultSampler  // Picks which results are persisted
 }
 
 // MonitorResults holds results for a specific monitor
@@ -52,14 +59,46 @@ func NewResultStoreWithPersistence(maxResults int, persistentStore PersistentSto
 		maxResults:      maxResults,
 		results:         make(map[string]*MonitorResults),
 		persistentStore: persistentStore,
+		sampler:         newResultSampler(),
 	}
 }
 
 // StoreResult stores a monitor result in memory and optionally to persistent storage
 func (rs *ResultStore) StoreResult(monitorName string, result *models.MonitorResult) {
+	rs.StoreSampledResult(monitorName, result, 0)
+}
+
+// StoreSampledResult stores a monitor result in memory and, with a
+// sampleRate above 1, persists only a sample of its successful results.
+// Memory always keeps every result.
+func (rs *ResultStore) StoreSampledResult(monitorName string, result *models.MonitorResult, sampleRate int) {
 	// Store in memory
 	rs.mu.Lock()
+	rs.remember(monitorName, result)
+	var persist []*models.MonitorResult
+	if rs.persistentStore != nil {
+		persist = rs.sampler.sample(monitorName, result, sampleRate)
+	}
+	rs.mu.Unlock()
 
+	// Store to persistent storage (if available) - do this outside the lock to avoid blocking
+	if len(persist) > 0 {
+		// Fire and forget - we don't want to slow down the monitoring
+		go func() {
+			for _, result := range persist {
+				if err := rs.persistentStore.StoreResult(result); err != nil {
+					// Log error but don't fail the operation
+					// The logger would need to be passed in, but for now we silently ignore
+					// This could be improved by adding a logger to the ResultStore
+				}
+			}
+		}()
+	}
+}
+
+// remember adds a result to a monitor's in-memory buffer. The caller must
+// hold the write lock.
+func (rs *ResultStore) remember(monitorName string, result *models.MonitorResult) {
 	// Get or create monitor results
 	monitorResults, exists := rs.results[monitorName]
 	if !exists {
@@ -79,20 +118,40 @@ func (rs *ResultStore) StoreResult(monitorName string, result *models.MonitorRes
 	if monitorResults.Count < rs.maxResults {
 		monitorResults.Count++
 	}
+}
 
-	rs.mu.Unlock()
+// Hydrate loads a monitor's latest persisted result into memory when it has
+// none yet, so its last-known state survives a restart. It returns the loaded
+// result, or nil when nothing was loaded.
+func (rs *ResultStore) Hydrate(monitorName string) (*models.MonitorResult, error) {
+	if rs.persistentStore == nil {
+		return nil, nil
+	}
 
-	// Store to persistent storage (if available) - do this outside the lock to avoid blocking
-	if rs.persistentStore != nil {
-		// Fire and forget - we don't want to slow down the monitoring
-		go func() {
-			if err := rs.persistentStore.StoreResult(result); err != nil {
-				// Log error but don't fail the operation
-				// The logger would need to be passed in, but for now we silently ignore
-				// This could be improved by adding a logger to the ResultStore
-			}
-		}()
+	rs.mu.RLock()
+	_, exists := rs.results[monitorName]
+	rs.mu.RUnlock()
+	if exists {
+		return nil, nil
+	}
+
+	result, err := rs.persistentStore.GetLatestResult(monitorName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load latest result for %s: %w", monitorName, err)
 	}
+	if result == nil {
+		return nil, nil
+	}
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	// A check may have finished while the result was loading
+	if _, exists := rs.results[monitorName]; exists {
+		return nil, nil
+	}
+	rs.remember(monitorName, result)
+	return result, nil
 }
 
 // GetResults returns the most recent results for a monitor
@@ -266,6 +325,16 @@ func (rs *ResultStore) CleanupOldResults(maxAge time.Duration) int {
 	return totalCleaned
 }
 
+// RemoveMonitor drops the in-memory results for a monitor; persisted history is kept
+func (rs *ResultStore) RemoveMonitor(monitorName string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	delete(rs.results, monitorName)
+	if rs.sampler != nil {
+		rs.sampler.forget(monitorName)
+	}
+}
+
 // GetUptime calculates uptime percentage for a monitor over a given period
 func (rs *ResultStore) GetUptime(monitorName string, period time.Duration) float64 {
 	results := rs.GetResults(monitorName, -1) // Get all results
@@ -302,6 +371,97 @@ func (rs *ResultStore) GetHistoricalResults(monitorName string, start, end time.
 	return rs.persistentStore.GetResults(monitorName, start, end, limit)
 }
 
+// FindResult returns the result a monitor recorded at timestamp, or nil when
+// there is none. Memory is checked first since sampled-out results are never
+// persisted; stores keeping coarser timestamps match at their precision.
+func (rs *ResultStore) FindResult(monitorName string, timestamp time.Time) (*models.MonitorResult, error) {
+	for _, result := range rs.GetResults(monitorName, 0) {
+		if result.Timestamp.Equal(timestamp) {
+			return result, nil
+		}
+	}
+	if rs.persistentStore == nil {
+		return nil, nil
+	}
+
+	results, err := rs.persistentStore.GetResults(monitorName, timestamp.Add(-time.Millisecond), timestamp.Add(time.Millisecond), 10)
+	if err != nil {
+		return nil, err
+	}
+	for _, result := range results {
+		if result.Timestamp.Equal(timestamp) || result.Timestamp.Equal(timestamp.Truncate(time.Microsecond)) {
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// CountHistoricalResults counts results for a time range by status. Stores
+// with uptime rollups serve counts from them, other stores that can count
+// without loading results do so, and otherwise results are loaded and counted.
+func (rs *ResultStore) CountHistoricalResults(monitorName string, start, end time.Time) (storage.ResultCounts, error) {
+	if counter, ok := rs.persistentStore.(storage.UptimeCounter); ok {
+		return counter.CountUptime(monitorName, start, end)
+	}
+	if streamer, ok := rs.persistentStore.(storage.ResultStreamer); ok {
+		return streamer.CountResults(monitorName, start, end)
+	}
+
+	results, err := rs.GetHistoricalResults(monitorName, start, end, maxCountedResults)
+	if err != nil {
+		return storage.ResultCounts{}, err
+	}
+
+	var counts storage.ResultCounts
+	for _, result := range results {
+		counts.AddN(result.Status, result.Weight())
+	}
+	return counts, nil
+}
+
+// SearchResults searches historical results across monitors, newest first.
+// Stores that can search do so; otherwise each monitor's results are loaded
+// and filtered.
+func (rs *ResultStore) SearchResults(query storage.ResultQuery) ([]*models.MonitorResult, error) {
+	if searcher, ok := rs.persistentStore.(storage.ResultSearcher); ok {
+		return searcher.SearchResults(query)
+	}
+
+	monitors := query.Monitors
+	if len(monitors) == 0 {
+		if lister, ok := rs.persistentStore.(interface{ GetMonitorNames() ([]string, error) }); ok {
+			names, err := lister.GetMonitorNames()
+			if err != nil {
+				return nil, err
+			}
+			monitors = names
+		} else {
+			monitors = rs.GetMonitorNames()
+		}
+	}
+
+	var matched []*models.MonitorResult
+	for _, monitor := range monitors {
+		results, err := rs.GetHistoricalResults(monitor, query.Start, query.End, maxCountedResults)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range results {
+			if query.Matches(result) {
+				matched = append(matched, result)
+			}
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Timestamp.After(matched[j].Timestamp)
+	})
+	if query.Limit > 0 && len(matched) > query.Limit {
+		matched = matched[:query.Limit]
+	}
+	return matched, nil
+}
+
 func (rs *ResultStore) getHistoricalResultsFromMemory(monitorName string, start, end time.Time, limit int) []*models.MonitorResult {
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
@@ -329,9 +489,9 @@ func (rs *ResultStore) getHistoricalResultsFromMemory(monitorName string, start,
 		matched = append(matched, result)
 	}
 
-	// Sort chronologically ascending
+	// Newest first, like persistent stores, so limit keeps the latest results
 	sort.Slice(matched, func(i, j int) bool {
-		return matched[i].Timestamp.Before(matched[j].Timestamp)
+		return matched[i].Timestamp.After(matched[j].Timestamp)
 	})
 
 	if limit > 0 && len(matched) > limit {
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/1broseidon/hallmonitor/internal/storage"
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
@@ -125,8 +126,8 @@ func TestResultStoreGetHistoricalResultsInMemory(t *testing.T) {
 	}
 
 	for i := 1; i < len(results); i++ {
-		if !results[i-1].Timestamp.Before(results[i].Timestamp) && !results[i-1].Timestamp.Equal(results[i].Timestamp) {
-			t.Fatalf("results not sorted chronologically")
+		if results[i-1].Timestamp.Before(results[i].Timestamp) {
+			t.Fatalf("results not sorted newest first")
 		}
 	}
 
@@ -137,4 +138,74 @@ func TestResultStoreGetHistoricalResultsInMemory(t *testing.T) {
 	if len(limited) != 2 {
 		t.Fatalf("expected 2 limited results, got %d", len(limited))
 	}
+	if !limited[0].Timestamp.Equal(end) || !limited[1].Timestamp.Equal(end.Add(-time.Minute)) {
+		t.Fatalf("expected the limit to keep the newest results, got %v and %v", limited[0].Timestamp, limited[1].Timestamp)
+	}
+}
+
+// latestOnlyStore is a PersistentStore that only knows each monitor's latest result
+type latestOnlyStore struct {
+	latest map[string]*models.MonitorResult
+}
+
+func (s *latestOnlyStore) StoreResult(result *models.MonitorResult) error {
+	s.latest[result.Monitor] = result
+	return nil
+}
+
+func (s *latestOnlyStore) GetLatestResult(monitor string) (*models.MonitorResult, error) {
+	return s.latest[monitor], nil
+}
+
+func (s *latestOnlyStore) GetResults(string, time.Time, time.Time, int) ([]*models.MonitorResult, error) {
+	return nil, nil
+}
+
+func TestResultStoreHydrate(t *testing.T) {
+	persisted := newResult("alpha", models.StatusDown, time.Now().Add(-time.Hour))
+	rs := NewResultStoreWithPersistence(10, &latestOnlyStore{latest: map[string]*models.MonitorResult{"alpha": persisted}})
+
+	result, err := rs.Hydrate("alpha")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result != persisted {
+		t.Fatalf("expected the persisted result, got %+v", result)
+	}
+	if got := rs.GetResults("alpha", 10); len(got) != 1 || got[0] != persisted {
+		t.Fatalf("expected the persisted result in memory, got %d results", len(got))
+	}
+
+	// Monitors with results in memory, or none persisted, are left alone
+	if result, _ := rs.Hydrate("alpha"); result != nil {
+		t.Errorf("expected no second hydration")
+	}
+	if result, _ := rs.Hydrate("beta"); result != nil {
+		t.Errorf("expected nothing for a monitor without a persisted result")
+	}
+	if names := rs.GetMonitorNames(); len(names) != 1 {
+		t.Errorf("expected only alpha in memory, got %v", names)
+	}
+
+	if result, err := NewResultStore(10).Hydrate("alpha"); result != nil || err != nil {
+		t.Errorf("expected no-op without persistence, got %v, %v", result, err)
+	}
+}
+
+func TestResultStoreCountHistoricalResults(t *testing.T) {
+	rs := NewResultStore(10)
+	base := time.Now().Add(-10 * time.Minute)
+
+	statuses := []models.MonitorStatus{models.StatusUp, models.StatusDown, models.StatusUp, models.StatusUnknown}
+	for i, status := range statuses {
+		rs.StoreResult("alpha", newResult("alpha", status, base.Add(time.Duration(i)*time.Minute)))
+	}
+
+	counts, err := rs.CountHistoricalResults("alpha", base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if counts != (storage.ResultCounts{Total: 4, Up: 2, Down: 1}) {
+		t.Fatalf("unexpected counts: %+v", counts)
+	}
 }
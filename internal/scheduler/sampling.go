@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// sampleState tracks the successful results a monitor has skipped since it
+// last persisted one
+type sampleState struct {
+	previous models.MonitorStatus  // Status of the last result seen
+	skipped  int                   // Successful results not yet persisted
+	last     *models.MonitorResult // Most recent skipped result
+}
+
+// resultSampler decides which results of high-frequency monitors are
+// persisted. Failures and status changes are always kept; runs of successful
+// results are persisted one in sampleRate, each weighted by the checks it
+// stands for, so uptime counted from storage stays exact. The last success
+// before a failure is kept too, marking where the outage began.
+type resultSampler struct {
+	monitors map[string]*sampleState
+}
+
+// newResultSampler creates an empty sampler
+func newResultSampler() *resultSampler {
+	return &resultSampler{monitors: make(map[string]*sampleState)}
+}
+
+// sample returns the results to persist for a new result. A sampleRate of 1
+// or less persists every result, flushing any skipped results first.
+func (rs *resultSampler) sample(monitorName string, result *models.MonitorResult, sampleRate int) []*models.MonitorResult {
+	state, ok := rs.monitors[monitorName]
+	if sampleRate <= 1 {
+		if !ok {
+			return []*models.MonitorResult{result}
+		}
+		delete(rs.monitors, monitorName)
+		return append(state.flush(), result)
+	}
+	if !ok {
+		state = &sampleState{}
+		rs.monitors[monitorName] = state
+	}
+	previous := state.previous
+	state.previous = result.Status
+
+	if result.Status != models.StatusUp || previous != models.StatusUp {
+		return append(state.flush(), result)
+	}
+
+	state.skipped++
+	state.last = result
+	if state.skipped < sampleRate {
+		return nil
+	}
+	return state.flush()
+}
+
+// forget drops a monitor's state without persisting what it skipped
+func (rs *resultSampler) forget(monitorName string) {
+	delete(rs.monitors, monitorName)
+}
+
+// flush returns the most recent skipped result weighted by every result
+// skipped, or nothing when none were
+func (s *sampleState) flush() []*models.MonitorResult {
+	if s.skipped == 0 {
+		return nil
+	}
+	weighted := *s.last
+	if s.skipped > 1 {
+		weighted.SampleWeight = s.skipped
+	}
+	s.skipped = 0
+	s.last = nil
+	return []*models.MonitorResult{&weighted}
+}
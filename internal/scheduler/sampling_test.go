@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestResultSampler(t *testing.T) {
+	sampler := newResultSampler()
+	base := time.Now()
+
+	steps := []struct {
+		status     models.MonitorStatus
+		sampleRate int
+		want       []int // Weights of the persisted results
+	}{
+		{status: models.StatusUp, sampleRate: 3, want: []int{1}}, // First result
+		{status: models.StatusUp, sampleRate: 3},
+		{status: models.StatusUp, sampleRate: 3},
+		{status: models.StatusUp, sampleRate: 3, want: []int{3}},
+		{status: models.StatusUp, sampleRate: 3},
+		{status: models.StatusDown, sampleRate: 3, want: []int{1, 1}}, // Last success, then the failure
+		{status: models.StatusDown, sampleRate: 3, want: []int{1}},
+		{status: models.StatusUp, sampleRate: 3, want: []int{1}}, // Recovery
+		{status: models.StatusUp, sampleRate: 3},
+		{status: models.StatusUp, sampleRate: 3},
+		{status: models.StatusUp, sampleRate: 0, want: []int{2, 1}}, // Sampling turned off flushes
+	}
+
+	var counts storage.ResultCounts
+	for i, step := range steps {
+		result := newResult("alpha", step.status, base.Add(time.Duration(i)*time.Second))
+		persisted := sampler.sample("alpha", result, step.sampleRate)
+		if len(persisted) != len(step.want) {
+			t.Fatalf("step %d: expected %d persisted results, got %d", i, len(step.want), len(persisted))
+		}
+		for j, p := range persisted {
+			if p.Weight() != step.want[j] {
+				t.Errorf("step %d: expected weight %d, got %d", i, step.want[j], p.Weight())
+			}
+			counts.AddN(p.Status, p.Weight())
+		}
+		if result.SampleWeight != 0 {
+			t.Errorf("step %d: the in-memory result must not be weighted", i)
+		}
+	}
+
+	// Weighted counts match what actually ran
+	if counts != (storage.ResultCounts{Total: 11, Up: 9, Down: 2}) {
+		t.Errorf("unexpected weighted counts: %+v", counts)
+	}
+	if len(sampler.monitors) != 0 {
+		t.Errorf("expected state to be dropped once sampling stops")
+	}
+}
@@ -4,8 +4,8 @@ package scheduler
 
 import (
 	"context"
-	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/1broseidon/hallmonitor/internal/logging"
@@ -15,6 +15,9 @@ import (
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
+// defaultWorkerCount is the worker pool size when none is configured
+const defaultWorkerCount = 10
+
 // Scheduler manages the execution of monitor checks
 type Scheduler struct {
 	logger         *logging.Logger
@@ -22,11 +25,32 @@ type Scheduler struct {
 	monitorManager *monitors.MonitorManager
 	resultStore    *ResultStore
 	workers        *WorkerPool
+	workerCount    int
+	autoscale      AutoscaleConfig
 	backoff        *BackoffManager
+	faults         *FaultInjector
+	timeouts       *TimeoutTracker
+	recovery       *RecoveryTracker
+	groupLimits    *GroupLimiter
+	rateLimit      *RateLimiter
+	warmUp         atomic.Int64 // Window initial checks are spread over, in nanoseconds
+	stallThreshold atomic.Int64 // Lateness that counts as a stall, in nanoseconds
+	catchUp        atomic.Bool  // Check every monitor right after a stall
+	lastStall      atomic.Pointer[Stall]
+	stallHandler   StallHandler
 	aggregator     Aggregator
+	processor      ResultProcessor
+	handlers       []ResultHandler
+	handlersMu     sync.RWMutex
+	reloads        []*monitors.ReloadDiff
+	reloadsMu      sync.Mutex
+	schedule       map[string]time.Time // Next execution per monitor, published by the scheduling loop
+	deferrals      map[string]time.Time // Monitor name -> time its next check was deferred to
+	scheduleMu     sync.RWMutex
 	stopChan       chan struct{}
 	wg             sync.WaitGroup
 	running        bool
+	readOnly       bool // Serve stored results without running checks
 	mu             sync.RWMutex
 }
 
@@ -36,15 +60,34 @@ type Aggregator interface {
 	Stop() error
 }
 
+// ResultHandler receives every completed check result. Implementations must
+// return quickly; slow work should be buffered and done asynchronously.
+type ResultHandler interface {
+	HandleResult(result *models.MonitorResult)
+}
+
+// ResultProcessor rewrites each check result before it is stored and handed
+// to result handlers. It runs on the worker that made the check.
+type ResultProcessor interface {
+	ProcessResult(monitor *models.Monitor, result *models.MonitorResult)
+}
+
 // NewScheduler creates a new scheduler instance without persistent storage
 func NewScheduler(logger *logging.Logger, metrics *metrics.Metrics, monitorManager *monitors.MonitorManager) *Scheduler {
 	return &Scheduler{
 		logger:         logger,
 		metrics:        metrics,
 		monitorManager: monitorManager,
-		resultStore:    NewResultStore(1000),               // Keep last 1000 results per monitor
-		workers:        NewWorkerPool(10, logger, metrics), // 10 concurrent workers
+		resultStore:    NewResultStore(1000), // Keep last 1000 results per monitor
+		workers:        NewWorkerPool(defaultWorkerCount, logger, metrics),
+		workerCount:    defaultWorkerCount,
 		backoff:        NewBackoffManager(),
+		faults:         NewFaultInjector(),
+		timeouts:       NewTimeoutTracker(),
+		recovery:       NewRecoveryTracker(),
+		groupLimits:    NewGroupLimiter(),
+		rateLimit:      NewRateLimiter(),
+		deferrals:      make(map[string]time.Time),
 		stopChan:       make(chan struct{}),
 		running:        false,
 	}
@@ -57,9 +100,16 @@ func NewSchedulerWithStorage(logger *logging.Logger, metrics *metrics.Metrics, m
 		metrics:        metrics,
 		monitorManager: monitorManager,
 		resultStore:    NewResultStoreWithPersistence(1000, persistentStore), // Keep last 1000 results per monitor with persistence
-		workers:        NewWorkerPool(10, logger, metrics),                   // 10 concurrent workers
+		workers:        NewWorkerPool(defaultWorkerCount, logger, metrics),
+		workerCount:    defaultWorkerCount,
 		backoff:        NewBackoffManager(),
+		faults:         NewFaultInjector(),
+		timeouts:       NewTimeoutTracker(),
+		recovery:       NewRecoveryTracker(),
+		groupLimits:    NewGroupLimiter(),
+		rateLimit:      NewRateLimiter(),
 		aggregator:     aggregator,
+		deferrals:      make(map[string]time.Time),
 		stopChan:       make(chan struct{}),
 		running:        false,
 	}
@@ -74,8 +124,16 @@ func (s *Scheduler) Start(ctx context.Context) error {
 		return nil
 	}
 
+	if s.readOnly {
+		s.logger.WithComponent(logging.ComponentScheduler).Info("Read-only mode: checks are not scheduled")
+		return nil
+	}
+
 	s.logger.WithComponent(logging.ComponentScheduler).Info("Starting scheduler")
 
+	// Show last-known state until each monitor's first check completes
+	s.hydrateResults()
+
 	// Start worker pool
 	s.workers.Start(ctx)
 
@@ -111,6 +169,45 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	return nil
 }
 
+// hydrateResults restores each enabled monitor's latest result from
+// persistent storage, so the dashboard and hallmonitor_monitor_up reflect the
+// last-known state right after a restart instead of "unknown"
+func (s *Scheduler) hydrateResults() {
+	restored := 0
+	for _, monitor := range s.monitorManager.GetMonitors() {
+		if !monitor.IsEnabled() {
+			continue
+		}
+
+		result, err := s.resultStore.Hydrate(monitor.GetName())
+		if err != nil {
+			s.logger.WithComponent(logging.ComponentScheduler).
+				WithError(err).
+				WithFields(map[string]interface{}{
+					"monitor": monitor.GetName(),
+				}).
+				Warn("Failed to restore last-known result")
+			continue
+		}
+		if result == nil {
+			continue
+		}
+
+		if s.metrics != nil {
+			s.metrics.SetMonitorStatus(monitor.GetName(), string(monitor.GetType()), monitor.GetGroup(), result.Status == models.StatusUp)
+		}
+		restored++
+	}
+
+	if restored > 0 {
+		s.logger.WithComponent(logging.ComponentScheduler).
+			WithFields(map[string]interface{}{
+				"monitors": restored,
+			}).
+			Info("Restored last-known results from storage")
+	}
+}
+
 // Stop gracefully stops the scheduler
 func (s *Scheduler) Stop() error {
 	s.mu.Lock()
@@ -158,28 +255,85 @@ func (s *Scheduler) Stop() error {
 	return nil
 }
 
-// Reload restarts the scheduler with updated monitors
-func (s *Scheduler) Reload(ctx context.Context) error {
-	s.logger.WithComponent(logging.ComponentScheduler).Info("Reloading scheduler")
+// ApplyReload updates scheduling for a monitor reload without restarting the
+// scheduler. Added and changed monitors are (re)scheduled, removed ones stop
+// being scheduled and lose their in-memory history, and unchanged monitors keep
+// their schedule, history, and backoff state.
+func (s *Scheduler) ApplyReload(diff *monitors.ReloadDiff) {
+	if diff == nil || !diff.HasChanges() {
+		return
+	}
 
-	// Stop the scheduler
-	if err := s.Stop(); err != nil {
-		return fmt.Errorf("failed to stop scheduler: %w", err)
+	for _, name := range diff.Changed {
+		s.backoff.Reset(name)
+	}
+	for _, name := range diff.Removed {
+		s.backoff.Reset(name)
+		s.timeouts.Reset(name)
+		s.recovery.Reset(name)
+		s.resultStore.RemoveMonitor(name)
+		s.scheduleMu.Lock()
+		delete(s.deferrals, name)
+		s.scheduleMu.Unlock()
 	}
 
-	// Reset the stop channel for restart
-	s.stopChan = make(chan struct{})
+	// The scheduling loop owns the execution schedule, so hand the diff over
+	s.reloadsMu.Lock()
+	s.reloads = append(s.reloads, diff)
+	s.reloadsMu.Unlock()
+
+	s.logger.WithComponent(logging.ComponentScheduler).
+		WithFields(map[string]interface{}{
+			"added":   len(diff.Added),
+			"removed": len(diff.Removed),
+			"changed": len(diff.Changed),
+		}).
+		Info("Scheduler reload applied")
+}
 
-	// Create a new worker pool (old one has closed channels)
-	s.workers = NewWorkerPool(10, s.logger, s.metrics)
+// takeReloads returns and clears reload diffs queued by ApplyReload
+func (s *Scheduler) takeReloads() []*monitors.ReloadDiff {
+	s.reloadsMu.Lock()
+	defer s.reloadsMu.Unlock()
+	reloads := s.reloads
+	s.reloads = nil
+	return reloads
+}
 
-	// Start the scheduler again
-	if err := s.Start(ctx); err != nil {
-		return fmt.Errorf("failed to start scheduler: %w", err)
+// applyReloads updates the execution schedule for queued reload diffs
+func (s *Scheduler) applyReloads(now time.Time, nextExecution map[string]time.Time) {
+	for _, diff := range s.takeReloads() {
+		for _, name := range diff.Removed {
+			delete(nextExecution, name)
+		}
+		// Paced like the initial schedule, so a large reload does not make
+		// every changed monitor due at once. Monitors disabled by the reload
+		// stop being scheduled.
+		names := make([]string, 0, len(diff.Added)+len(diff.Changed))
+		for _, name := range append(append([]string{}, diff.Added...), diff.Changed...) {
+			if monitor := s.monitorManager.GetMonitorByName(name); monitor != nil && !monitor.IsEnabled() {
+				delete(nextExecution, name)
+				continue
+			}
+			names = append(names, name)
+		}
+		s.staggerStarts(now, names, nextExecution)
 	}
+}
 
-	s.logger.WithComponent(logging.ComponentScheduler).Info("Scheduler reloaded successfully")
-	return nil
+// staggerStarts schedules the first check of each named monitor. With a
+// warm-up window the checks are spread evenly across it; otherwise each gets
+// a few seconds of jitter.
+func (s *Scheduler) staggerStarts(now time.Time, names []string, nextExecution map[string]time.Time) {
+	warmUp := time.Duration(s.warmUp.Load())
+	for i, name := range names {
+		if warmUp > 0 {
+			nextExecution[name] = now.Add(warmUp * time.Duration(i) / time.Duration(len(names)))
+			continue
+		}
+		// Add jitter to prevent thundering herd
+		nextExecution[name] = now.Add(time.Duration(rand.Intn(5)) * time.Second)
+	}
 }
 
 // IsRunning returns whether the scheduler is currently running
@@ -196,6 +350,11 @@ func (s *Scheduler) GetResults(monitorName string, limit int) []*models.MonitorR
 
 // GetLatestResult returns the most recent result for a monitor
 func (s *Scheduler) GetLatestResult(monitorName string) *models.MonitorResult {
+	// Nothing is checked locally, so the latest result is whatever was stored
+	if s.readOnly {
+		return s.resultStore.GetLatestResult(monitorName)
+	}
+
 	results := s.resultStore.GetResults(monitorName, 1)
 	if len(results) > 0 {
 		return results[0]
@@ -216,30 +375,173 @@ func (s *Scheduler) GetAllLatestResults() map[string]*models.MonitorResult {
 	return results
 }
 
+// AddResultHandler registers a handler invoked for every completed check
+func (s *Scheduler) AddResultHandler(handler ResultHandler) {
+	s.handlersMu.Lock()
+	defer s.handlersMu.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+// SetResultProcessor sets the processor applied to every result before it is
+// stored. Call it before Start.
+func (s *Scheduler) SetResultProcessor(processor ResultProcessor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processor = processor
+}
+
+// dispatchResult fans a completed result out to all registered handlers
+func (s *Scheduler) dispatchResult(result *models.MonitorResult) {
+	s.handlersMu.RLock()
+	handlers := s.handlers
+	s.handlersMu.RUnlock()
+
+	for _, handler := range handlers {
+		handler.HandleResult(result)
+	}
+}
+
+// SetReadOnly stops Start from scheduling checks, so results come only from
+// persistent storage written by another instance. Call it before Start.
+func (s *Scheduler) SetReadOnly(readOnly bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readOnly = readOnly
+}
+
+// SetWorkerCount sets how many checks may run at once across all monitors. A
+// running scheduler picks up the new size on its next Reload.
+func (s *Scheduler) SetWorkerCount(count int) {
+	if count <= 0 {
+		count = defaultWorkerCount
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.workerCount = count
+	if !s.running {
+		s.workers = s.newWorkerPool()
+	}
+}
+
+// SetAutoscale lets the worker pool grow up to config.MaxWorkers while checks
+// back up. A running scheduler picks up the change on its next Reload.
+func (s *Scheduler) SetAutoscale(config AutoscaleConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.autoscale = config
+	if !s.running {
+		s.workers = s.newWorkerPool()
+	}
+}
+
+// newWorkerPool creates a worker pool with the configured size and autoscaling
+func (s *Scheduler) newWorkerPool() *WorkerPool {
+	pool := NewWorkerPool(s.workerCount, s.logger, s.metrics)
+	pool.SetAutoscale(s.autoscale)
+	return pool
+}
+
+// SetGroupLimits sets the maximum concurrent checks per group name; groups
+// without a positive limit are unrestricted. Takes effect immediately.
+func (s *Scheduler) SetGroupLimits(limits map[string]int) {
+	s.groupLimits.SetLimits(limits)
+}
+
+// SetWarmUp sets the window that first checks are spread evenly across, on
+// start and for monitors added or changed by a reload. Zero keeps the default
+// few seconds of jitter. Takes effect on the next start or reload.
+func (s *Scheduler) SetWarmUp(window time.Duration) {
+	s.warmUp.Store(int64(window))
+}
+
+// SetStallDetection sets how late the scheduling loop must run to count as a
+// stall (0 for the default) and whether every monitor is checked right after
+// one. Takes effect immediately.
+func (s *Scheduler) SetStallDetection(threshold time.Duration, catchUp bool) {
+	s.stallThreshold.Store(int64(threshold))
+	s.catchUp.Store(catchUp)
+}
+
+// SetStallHandler sets the handler told about each stall. Call before Start.
+func (s *Scheduler) SetStallHandler(handler StallHandler) {
+	s.stallHandler = handler
+}
+
+// SetMaxChecksPerSecond caps how many checks start per second across all
+// monitors; 0 removes the cap. Takes effect immediately.
+func (s *Scheduler) SetMaxChecksPerSecond(limit int) {
+	s.rateLimit.SetRate(limit)
+}
+
+// Timeouts returns the tracker of how much of their timeout checks use
+func (s *Scheduler) Timeouts() *TimeoutTracker {
+	return s.timeouts
+}
+
+// Faults returns the injector used to simulate monitor failures
+func (s *Scheduler) Faults() *FaultInjector {
+	return s.faults
+}
+
+// CountHistoricalResults counts historical results for a monitor by status
+func (s *Scheduler) CountHistoricalResults(monitorName string, start, end time.Time) (storage.ResultCounts, error) {
+	return s.resultStore.CountHistoricalResults(monitorName, start, end)
+}
+
 // GetHistoricalResults returns historical results for a monitor
 func (s *Scheduler) GetHistoricalResults(monitorName string, start, end time.Time, limit int) ([]*models.MonitorResult, error) {
 	return s.resultStore.GetHistoricalResults(monitorName, start, end, limit)
 }
 
+// FindResult returns the result a monitor recorded at timestamp, or nil
+func (s *Scheduler) FindResult(monitorName string, timestamp time.Time) (*models.MonitorResult, error) {
+	return s.resultStore.FindResult(monitorName, timestamp)
+}
+
+// GetMonitorStats computes reliability stats for a monitor over a time range
+func (s *Scheduler) GetMonitorStats(monitorName string, start, end time.Time) (MonitorStats, error) {
+	return s.resultStore.MonitorStats(monitorName, start, end)
+}
+
+// GetMonitorOutages lists a monitor's outages over a time range
+func (s *Scheduler) GetMonitorOutages(monitorName string, start, end time.Time) ([]Outage, error) {
+	return s.resultStore.MonitorOutages(monitorName, start, end)
+}
+
+// SearchResults searches historical results across monitors
+func (s *Scheduler) SearchResults(query storage.ResultQuery) ([]*models.MonitorResult, error) {
+	return s.resultStore.SearchResults(query)
+}
+
 // schedulingLoop is the main scheduling loop
 func (s *Scheduler) schedulingLoop(ctx context.Context) {
 	defer s.wg.Done()
 
 	// Create a ticker for scheduling checks
-	ticker := time.NewTicker(1 * time.Second) // Check every second for due monitors
+	ticker := time.NewTicker(tickInterval) // Check every second for due monitors
 	defer ticker.Stop()
+	lastTick := time.Now()
 
 	// Track next execution time for each monitor
 	nextExecution := make(map[string]time.Time)
 
+	// Diffs queued before the loop started are covered by the full schedule below
+	s.takeReloads()
+
 	// Initialize next execution times
+	var names []string
 	for _, monitor := range s.monitorManager.GetMonitors() {
 		if monitor.IsEnabled() {
-			// Add jitter to prevent thundering herd
-			jitter := time.Duration(rand.Intn(5)) * time.Second
-			nextExecution[monitor.GetName()] = time.Now().Add(jitter)
+			names = append(names, monitor.GetName())
 		}
 	}
+	s.staggerStarts(time.Now(), names, nextExecution)
+	s.applyDeferrals(time.Now(), nextExecution)
+	s.publishSchedule(nextExecution)
+	defer s.publishSchedule(nil)
 
 	s.logger.WithComponent(logging.ComponentScheduler).
 		WithFields(map[string]interface{}{
@@ -256,7 +558,19 @@ func (s *Scheduler) schedulingLoop(ctx context.Context) {
 			s.logger.WithComponent(logging.ComponentScheduler).Info("Scheduler stopped by signal")
 			return
 		case now := <-ticker.C:
+			threshold := time.Duration(s.stallThreshold.Load())
+			if threshold <= 0 {
+				threshold = defaultStallThreshold
+			}
+			if stall, ok := detectStall(lastTick, now, threshold); ok {
+				s.handleStall(now, stall, nextExecution)
+			}
+			lastTick = now
+
+			s.applyReloads(now, nextExecution)
+			s.applyDeferrals(now, nextExecution)
 			s.checkAndScheduleMonitors(ctx, now, nextExecution)
+			s.publishSchedule(nextExecution)
 		}
 	}
 }
@@ -269,28 +583,56 @@ func (s *Scheduler) checkAndScheduleMonitors(ctx context.Context, now time.Time,
 		}
 
 		monitorName := monitor.GetName()
+		group := monitor.GetGroup()
 
 		// Check if this monitor is due for execution
 		if nextTime, exists := nextExecution[monitorName]; exists && now.After(nextTime) {
+			// Leave the monitor due so it is retried on the next tick once a
+			// check from its group finishes
+			if !s.groupLimits.TryAcquire(group) {
+				s.logger.WithComponent(logging.ComponentScheduler).
+					WithFields(map[string]interface{}{
+						"monitor": monitorName,
+						"group":   group,
+					}).
+					Debug("Group concurrency limit reached, deferring monitor check")
+				continue
+			}
+
+			// Over the rate limit the monitor stays due for the next tick
+			if !s.rateLimit.Allow(now) {
+				s.groupLimits.Release(group)
+				s.logger.WithComponent(logging.ComponentScheduler).
+					WithFields(map[string]interface{}{
+						"monitor": monitorName,
+					}).
+					Debug("Check rate limit reached, deferring monitor check")
+				continue
+			}
+
 			// Schedule the monitor check
 			job := &MonitorJob{
 				Monitor:     monitor,
 				ResultStore: s.resultStore,
 				Backoff:     s.backoff,
+				Faults:      s.faults,
+				Timeouts:    s.timeouts,
+				Recovery:    s.recovery,
+				Processor:   s.processor,
 				ScheduledAt: now,
+				OnResult:    s.dispatchResult,
+				OnDone:      func() { s.groupLimits.Release(group) },
 			}
 
 			// Submit job to worker pool
 			select {
 			case <-ctx.Done():
+				s.groupLimits.Release(group)
 				return
 			default:
 				if s.workers.Submit(job) {
 					// Update next execution time
-					interval := monitor.GetConfig().Interval.ToDuration()
-					if interval == 0 {
-						interval = 30 * time.Second // fallback
-					}
+					interval := monitorInterval(monitor)
 
 					// Add small jitter (±10% of interval) to prevent synchronization
 					jitter := time.Duration(rand.Intn(int(interval.Nanoseconds()/5))) - interval/10
@@ -305,6 +647,7 @@ func (s *Scheduler) checkAndScheduleMonitors(ctx context.Context, now time.Time,
 						Debug("Monitor scheduled")
 				} else {
 					// Worker pool is full, skip this execution
+					s.groupLimits.Release(group)
 					s.logger.WithComponent(logging.ComponentScheduler).
 						WithFields(map[string]interface{}{
 							"monitor": monitorName,
@@ -316,6 +659,58 @@ func (s *Scheduler) checkAndScheduleMonitors(ctx context.Context, now time.Time,
 	}
 }
 
+// handleStall records a stall noticed at the tick now, counts the checks it
+// cost each monitor, and, with catch-up enabled, checks every monitor again
+func (s *Scheduler) handleStall(now time.Time, stall Stall, nextExecution map[string]time.Time) {
+	var names []string
+	for _, monitor := range s.monitorManager.GetMonitors() {
+		if !monitor.IsEnabled() {
+			continue
+		}
+		names = append(names, monitor.GetName())
+		if missed := int(stall.Gap / monitorInterval(monitor)); missed > 0 {
+			stall.Missed += missed
+			if s.metrics != nil {
+				s.metrics.RecordMissedChecks(monitor.GetName(), monitor.GetGroup(), missed)
+			}
+		}
+	}
+	if s.catchUp.Load() && stall.Gap > 0 {
+		s.staggerStarts(now, names, nextExecution)
+		stall.CaughtUp = true
+	}
+
+	if s.metrics != nil {
+		s.metrics.RecordStall(stall.Kind, stall.Gap)
+	}
+	message := "Scheduler fell behind; checks were missed"
+	if stall.Kind == StallClockJump {
+		message = "System clock jumped; the host may have been suspended"
+	}
+	s.logger.WithComponent(logging.ComponentScheduler).
+		WithFields(map[string]interface{}{
+			"kind":          stall.Kind,
+			"gap":           stall.Gap.String(),
+			"clock_jump":    stall.ClockJump.String(),
+			"missed_checks": stall.Missed,
+			"caught_up":     stall.CaughtUp,
+		}).
+		Warn(message)
+
+	s.lastStall.Store(&stall)
+	if s.stallHandler != nil {
+		s.stallHandler.HandleStall(stall)
+	}
+}
+
+// monitorInterval returns how often a monitor is checked
+func monitorInterval(monitor monitors.Monitor) time.Duration {
+	if interval := monitor.GetConfig().Interval.ToDuration(); interval > 0 {
+		return interval
+	}
+	return 30 * time.Second // fallback
+}
+
 // GetStats returns scheduler statistics
 func (s *Scheduler) GetStats() SchedulerStats {
 	s.mu.RLock()
@@ -324,9 +719,12 @@ func (s *Scheduler) GetStats() SchedulerStats {
 	stats := SchedulerStats{
 		Running:       s.running,
 		TotalMonitors: len(s.monitorManager.GetMonitors()),
+		WorkerCount:   s.workerCount,
+		PoolSize:      s.workers.Size(),
 		ActiveWorkers: s.workers.ActiveWorkers(),
 		PendingJobs:   s.workers.PendingJobs(),
 		ProcessedJobs: s.workers.ProcessedJobs(),
+		LastStall:     s.lastStall.Load(),
 	}
 
 	// Count enabled monitors
@@ -341,12 +739,15 @@ func (s *Scheduler) GetStats() SchedulerStats {
 
 // SchedulerStats represents scheduler statistics
 type SchedulerStats struct {
-	Running         bool  `json:"running"`
-	TotalMonitors   int   `json:"total_monitors"`
-	EnabledMonitors int   `json:"enabled_monitors"`
-	ActiveWorkers   int   `json:"active_workers"`
-	PendingJobs     int   `json:"pending_jobs"`
-	ProcessedJobs   int64 `json:"processed_jobs"`
+	Running         bool   `json:"running"`
+	TotalMonitors   int    `json:"total_monitors"`
+	EnabledMonitors int    `json:"enabled_monitors"`
+	WorkerCount     int    `json:"worker_count"` // Configured base pool size
+	PoolSize        int    `json:"pool_size"`    // Current workers, including autoscaled ones
+	ActiveWorkers   int    `json:"active_workers"`
+	PendingJobs     int    `json:"pending_jobs"`
+	ProcessedJobs   int64  `json:"processed_jobs"`
+	LastStall       *Stall `json:"last_stall,omitempty"` // Most recent gap the loop fell behind by, if any
 }
 
 // Simple random number generator for jitter
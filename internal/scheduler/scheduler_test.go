@@ -3,12 +3,14 @@ package scheduler
 import (
 	"context"
 	"reflect"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 	"unsafe"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/rs/zerolog"
 	zerologlog "github.com/rs/zerolog/log"
 
@@ -163,3 +165,235 @@ func TestSchedulerProcessesMonitorJob(t *testing.T) {
 		t.Fatalf("expected next execution to be scheduled in the future, got %s", next)
 	}
 }
+
+type recordingHandler struct {
+	mu      sync.Mutex
+	results []*models.MonitorResult
+}
+
+func (h *recordingHandler) HandleResult(result *models.MonitorResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.results = append(h.results, result)
+}
+
+func (h *recordingHandler) count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.results)
+}
+
+func TestSchedulerDispatchesResultsToHandlers(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	manager := monitors.NewMonitorManager(logger, metricsInstance)
+
+	monitor := &stubMonitor{
+		name:        "api",
+		group:       "core",
+		monitorType: models.MonitorTypeHTTP,
+		interval:    5 * time.Second,
+		timeout:     time.Second,
+		enabled:     true,
+		result:      models.MonitorResult{Monitor: "api", Status: models.StatusUp},
+	}
+	setMonitorManagerMonitors(t, manager, []monitors.Monitor{monitor})
+
+	sched := NewScheduler(logger, metricsInstance, manager)
+	handler := &recordingHandler{}
+	sched.AddResultHandler(handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sched.workers = NewWorkerPool(1, logger, metricsInstance)
+	sched.workers.Start(ctx)
+	defer sched.workers.Stop()
+
+	sched.checkAndScheduleMonitors(ctx, time.Now(), map[string]time.Time{
+		monitor.GetName(): time.Now().Add(-time.Second),
+	})
+
+	deadline := time.Now().Add(500 * time.Millisecond)
+	for time.Now().Before(deadline) && handler.count() == 0 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if handler.count() != 1 {
+		t.Fatalf("expected handler to receive 1 result, got %d", handler.count())
+	}
+}
+
+func TestSchedulerHydratesLastKnownResults(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	manager := monitors.NewMonitorManager(logger, metricsInstance)
+
+	api := &stubMonitor{name: "api", group: "core", monitorType: models.MonitorTypeHTTP, enabled: true}
+	paused := &stubMonitor{name: "paused", group: "core", monitorType: models.MonitorTypeHTTP}
+	setMonitorManagerMonitors(t, manager, []monitors.Monitor{api, paused})
+
+	store := &latestOnlyStore{latest: map[string]*models.MonitorResult{
+		"api":    {Monitor: "api", Type: models.MonitorTypeHTTP, Group: "core", Status: models.StatusUp, Timestamp: time.Now().Add(-time.Minute)},
+		"paused": {Monitor: "paused", Type: models.MonitorTypeHTTP, Group: "core", Status: models.StatusDown, Timestamp: time.Now().Add(-time.Hour)},
+	}}
+	sched := NewSchedulerWithStorage(logger, metricsInstance, manager, store, nil)
+	sched.hydrateResults()
+
+	if latest := sched.GetLatestResult("api"); latest == nil || latest.Status != models.StatusUp {
+		t.Fatalf("expected the last-known result for api, got %+v", latest)
+	}
+	if got := testutil.ToFloat64(metricsInstance.MonitorUp.WithLabelValues("api", "http", "core")); got != 1 {
+		t.Errorf("expected hallmonitor_monitor_up 1 for api, got %v", got)
+	}
+	if latest := sched.GetLatestResult("paused"); latest != nil {
+		t.Errorf("expected disabled monitors not to be restored, got %+v", latest)
+	}
+}
+
+func TestSchedulerStaggerStarts(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	sched := NewScheduler(logger, metricsInstance, monitors.NewMonitorManager(logger, metricsInstance))
+
+	now := time.Now()
+	names := []string{"a", "b", "c", "d"}
+
+	sched.SetWarmUp(time.Minute)
+	nextExecution := make(map[string]time.Time)
+	sched.staggerStarts(now, names, nextExecution)
+	for i, name := range names {
+		if want := now.Add(time.Duration(i) * 15 * time.Second); !nextExecution[name].Equal(want) {
+			t.Errorf("expected %s at +%s, got +%s", name, want.Sub(now), nextExecution[name].Sub(now))
+		}
+	}
+
+	// Without a warm-up window, checks start within the default jitter
+	sched.SetWarmUp(0)
+	sched.staggerStarts(now, names, nextExecution)
+	for _, name := range names {
+		if offset := nextExecution[name].Sub(now); offset < 0 || offset >= 5*time.Second {
+			t.Errorf("expected %s within 5s, got +%s", name, offset)
+		}
+	}
+}
+
+func TestSchedulerMaxChecksPerSecond(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	manager := monitors.NewMonitorManager(logger, metricsInstance)
+
+	var monitorList []monitors.Monitor
+	nextExecution := make(map[string]time.Time)
+	now := time.Now()
+	for _, name := range []string{"a", "b", "c"} {
+		monitorList = append(monitorList, &stubMonitor{name: name, group: "core", monitorType: models.MonitorTypeHTTP, interval: time.Minute, enabled: true})
+		nextExecution[name] = now.Add(-time.Second)
+	}
+	setMonitorManagerMonitors(t, manager, monitorList)
+
+	sched := NewScheduler(logger, metricsInstance, manager)
+	sched.SetMaxChecksPerSecond(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sched.workers = NewWorkerPool(3, logger, metricsInstance)
+	sched.workers.Start(ctx)
+	defer sched.workers.Stop()
+
+	sched.checkAndScheduleMonitors(ctx, now, nextExecution)
+
+	due := 0
+	for _, next := range nextExecution {
+		if !next.After(now) {
+			due++
+		}
+	}
+	if due != 1 {
+		t.Errorf("expected 1 monitor left due over the rate limit, got %d", due)
+	}
+}
+
+func TestSchedulerApplyReload(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	manager := monitors.NewMonitorManager(logger, metricsInstance)
+	sched := NewScheduler(logger, metricsInstance, manager)
+
+	for _, name := range []string{"kept", "edited", "dropped"} {
+		sched.resultStore.StoreResult(name, &models.MonitorResult{Monitor: name, Status: models.StatusDown, Timestamp: time.Now()})
+		sched.backoff.RecordFailure(name)
+	}
+
+	now := time.Now()
+	keptNext := now.Add(time.Minute)
+	nextExecution := map[string]time.Time{
+		"kept":    keptNext,
+		"edited":  now.Add(time.Minute),
+		"dropped": now.Add(time.Minute),
+	}
+
+	sched.ApplyReload(&monitors.ReloadDiff{
+		Added:     []string{"fresh"},
+		Removed:   []string{"dropped"},
+		Changed:   []string{"edited"},
+		Unchanged: 1,
+	})
+	sched.applyReloads(now, nextExecution)
+
+	if sched.backoff.GetBackoff("kept") == 0 {
+		t.Error("expected unchanged monitor to keep its backoff")
+	}
+	if sched.backoff.GetBackoff("edited") != 0 || sched.backoff.GetBackoff("dropped") != 0 {
+		t.Error("expected changed and removed monitors to have backoff reset")
+	}
+
+	if len(sched.GetResults("kept", -1)) != 1 || len(sched.GetResults("edited", -1)) != 1 {
+		t.Error("expected unchanged and changed monitors to keep history")
+	}
+	if len(sched.GetResults("dropped", -1)) != 0 {
+		t.Error("expected removed monitor history to be dropped")
+	}
+
+	if !nextExecution["kept"].Equal(keptNext) {
+		t.Error("expected unchanged monitor schedule to be preserved")
+	}
+	if _, ok := nextExecution["dropped"]; ok {
+		t.Error("expected removed monitor to be unscheduled")
+	}
+	for _, name := range []string{"edited", "fresh"} {
+		next, ok := nextExecution[name]
+		if !ok || next.After(now.Add(5*time.Second)) {
+			t.Errorf("expected %s to be rescheduled within startup jitter, got %v", name, next)
+		}
+	}
+
+	if reloads := sched.takeReloads(); len(reloads) != 0 {
+		t.Errorf("expected queued reloads to be consumed, got %d", len(reloads))
+	}
+}
+
+func TestSchedulerApplyReloadUnschedulesDisabled(t *testing.T) {
+	logger := newSchedulerTestLogger(t)
+	metricsInstance := metrics.NewMetrics(prometheus.NewRegistry())
+	manager := monitors.NewMonitorManager(logger, metricsInstance)
+	setMonitorManagerMonitors(t, manager, []monitors.Monitor{
+		&stubMonitor{name: "paused", group: "core", monitorType: models.MonitorTypeTCP, interval: time.Minute, enabled: false},
+		&stubMonitor{name: "resumed", group: "core", monitorType: models.MonitorTypeTCP, interval: time.Minute, enabled: true},
+	})
+	sched := NewScheduler(logger, metricsInstance, manager)
+
+	now := time.Now()
+	nextExecution := map[string]time.Time{"paused": now.Add(time.Minute)}
+
+	// Toggling enabled changes the spec, so both arrive as changed monitors
+	sched.ApplyReload(&monitors.ReloadDiff{Changed: []string{"paused", "resumed"}})
+	sched.applyReloads(now, nextExecution)
+
+	if _, ok := nextExecution["paused"]; ok {
+		t.Error("expected the disabled monitor to be unscheduled")
+	}
+	if _, ok := nextExecution["resumed"]; !ok {
+		t.Error("expected the re-enabled monitor to be scheduled")
+	}
+}
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// tickInterval is how often the scheduling loop looks for due monitors
+const tickInterval = time.Second
+
+// defaultStallThreshold is how late a tick must be to count as a stall
+const defaultStallThreshold = 15 * time.Second
+
+// Stall kinds
+const (
+	// StallLoop means the loop ran late by the monotonic clock, as when a VM
+	// is paused or the host is starved of CPU
+	StallLoop = "stall"
+	// StallClockJump means the wall clock moved without the monotonic clock,
+	// as when the host sleeps or its clock is stepped
+	StallClockJump = "clock_jump"
+)
+
+// Stall is a gap in which the scheduling loop did not run on time. Checks due
+// during the gap did not run, which explains holes in monitor history.
+type Stall struct {
+	Kind      string        `json:"kind"`
+	Start     time.Time     `json:"start"`         // Last tick before the gap
+	End       time.Time     `json:"end"`           // Tick that noticed the gap
+	Gap       time.Duration `json:"gap"`           // How far the loop fell behind
+	ClockJump time.Duration `json:"clock_jump"`    // Wall time elapsed beyond monotonic time; negative if the clock went back
+	Missed    int           `json:"missed_checks"` // Checks that came due during the gap
+	CaughtUp  bool          `json:"caught_up"`     // Monitors were checked right away after it
+}
+
+// StallHandler is told about each stall the scheduler detects
+type StallHandler interface {
+	HandleStall(stall Stall)
+}
+
+// StallHandlerFunc adapts a function to a StallHandler
+type StallHandlerFunc func(stall Stall)
+
+// HandleStall calls f
+func (f StallHandlerFunc) HandleStall(stall Stall) {
+	f(stall)
+}
+
+// detectStall compares a tick with the previous one. Elapsed time is measured
+// on both clocks: the monotonic clock catches a stalled loop, and the wall
+// clock catches host sleep, during which the monotonic clock stops on some
+// platforms. A wall clock that goes back by threshold is reported too.
+func detectStall(last, now time.Time, threshold time.Duration) (Stall, bool) {
+	return measureStall(last.Round(0), now.Round(0), now.Sub(last), threshold)
+}
+
+// measureStall reports a stall between two wall clock times that were
+// monotonic apart on the monotonic clock
+func measureStall(start, end time.Time, monotonic, threshold time.Duration) (Stall, bool) {
+	wall := end.Sub(start)
+	stall := Stall{
+		Kind:      StallLoop,
+		Start:     start,
+		End:       end,
+		Gap:       max(monotonic, wall) - tickInterval,
+		ClockJump: wall - monotonic,
+	}
+	if stall.Gap < threshold && -stall.ClockJump < threshold {
+		return Stall{}, false
+	}
+	if stall.Gap < 0 {
+		stall.Gap = 0
+	}
+	if monotonic-tickInterval < threshold {
+		stall.Kind = StallClockJump
+	}
+	return stall, true
+}
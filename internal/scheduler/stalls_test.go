@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+)
+
+func TestMeasureStall(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		wall      time.Duration
+		monotonic time.Duration
+		wantStall bool
+		wantKind  string
+		wantGap   time.Duration
+	}{
+		{name: "on time", wall: time.Second, monotonic: time.Second},
+		{name: "slightly late", wall: 5 * time.Second, monotonic: 5 * time.Second},
+		{name: "loop stalled", wall: 2 * time.Minute, monotonic: 2 * time.Minute, wantStall: true, wantKind: StallLoop, wantGap: 2*time.Minute - time.Second},
+		{name: "host suspended", wall: time.Hour, monotonic: time.Second, wantStall: true, wantKind: StallClockJump, wantGap: time.Hour - time.Second},
+		{name: "clock stepped back", wall: -time.Minute, monotonic: time.Second, wantStall: true, wantKind: StallClockJump, wantGap: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stall, ok := measureStall(start, start.Add(tt.wall), tt.monotonic, 15*time.Second)
+			if ok != tt.wantStall {
+				t.Fatalf("measureStall() stall = %v, want %v (%+v)", ok, tt.wantStall, stall)
+			}
+			if !ok {
+				return
+			}
+			if stall.Kind != tt.wantKind || stall.Gap != tt.wantGap {
+				t.Errorf("kind %s gap %s, want %s %s", stall.Kind, stall.Gap, tt.wantKind, tt.wantGap)
+			}
+			if stall.ClockJump != tt.wall-tt.monotonic {
+				t.Errorf("clock jump %s, want %s", stall.ClockJump, tt.wall-tt.monotonic)
+			}
+		})
+	}
+}
+
+func TestDetectStallUsesMonotonicClock(t *testing.T) {
+	last := time.Now()
+	if _, ok := detectStall(last, last.Add(time.Second), 15*time.Second); ok {
+		t.Error("expected no stall for an on-time tick")
+	}
+	stall, ok := detectStall(last, last.Add(time.Minute), 15*time.Second)
+	if !ok || stall.Kind != StallLoop || stall.ClockJump != 0 {
+		t.Errorf("expected a loop stall without clock jump, got %+v (%v)", stall, ok)
+	}
+}
+
+func TestHandleStall(t *testing.T) {
+	for _, catchUp := range []bool{false, true} {
+		logger := newSchedulerTestLogger(t)
+		registry := prometheus.NewRegistry()
+		metricsInstance := metrics.NewMetrics(registry)
+		manager := monitors.NewMonitorManager(logger, metricsInstance)
+		setMonitorManagerMonitors(t, manager, []monitors.Monitor{
+			&stubMonitor{name: "fast", group: "core", interval: time.Minute, enabled: true},
+			&stubMonitor{name: "slow", group: "core", interval: time.Hour, enabled: true},
+			&stubMonitor{name: "off", group: "core", interval: time.Minute, enabled: false},
+		})
+
+		sched := NewScheduler(logger, metricsInstance, manager)
+		sched.SetStallDetection(0, catchUp)
+		var handled []Stall
+		sched.SetStallHandler(StallHandlerFunc(func(stall Stall) { handled = append(handled, stall) }))
+
+		now := time.Now()
+		later := now.Add(30 * time.Minute)
+		nextExecution := map[string]time.Time{"fast": later, "slow": later}
+		sched.handleStall(now, Stall{Kind: StallClockJump, Gap: 10 * time.Minute}, nextExecution)
+
+		if len(handled) != 1 {
+			t.Fatalf("catchUp=%v: expected the handler to be called once, got %d", catchUp, len(handled))
+		}
+		stall := handled[0]
+		if stall.Missed != 10 || stall.CaughtUp != catchUp {
+			t.Errorf("catchUp=%v: missed %d caught up %v, want 10 %v", catchUp, stall.Missed, stall.CaughtUp, catchUp)
+		}
+		if got := testutil.ToFloat64(metricsInstance.MissedChecks.WithLabelValues("fast", "core")); got != 10 {
+			t.Errorf("catchUp=%v: missed checks metric = %v, want 10", catchUp, got)
+		}
+		if got := testutil.ToFloat64(metricsInstance.Stalls.WithLabelValues(StallClockJump)); got != 1 {
+			t.Errorf("catchUp=%v: stalls metric = %v, want 1", catchUp, got)
+		}
+		if last := sched.GetStats().LastStall; last == nil || last.Missed != 10 {
+			t.Errorf("catchUp=%v: expected stats to report the stall, got %+v", catchUp, last)
+		}
+
+		rescheduled := nextExecution["fast"].Before(later) && nextExecution["slow"].Before(later)
+		if rescheduled != catchUp {
+			t.Errorf("catchUp=%v: monitors rescheduled = %v", catchUp, rescheduled)
+		}
+		if _, ok := nextExecution["off"]; ok {
+			t.Errorf("catchUp=%v: disabled monitor was scheduled", catchUp)
+		}
+	}
+}
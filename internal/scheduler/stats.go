@@ -0,0 +1,216 @@
+package scheduler
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// MonitorStats summarises a monitor's reliability over a time range. An
+// outage runs from the first down result to the next up result; outages
+// still open at the end of the range count up to the end.
+type MonitorStats struct {
+	Checks               int          `json:"total_checks"`
+	UpChecks             int          `json:"up_checks"`
+	DownChecks           int          `json:"down_checks"`
+	Outages              int          `json:"outages"`
+	OngoingOutage        bool         `json:"ongoing_outage"`
+	OutageSeconds        float64      `json:"outage_seconds"`         // Total time down
+	LongestOutageSeconds float64      `json:"longest_outage_seconds"` // Longest single outage
+	MTTRSeconds          float64      `json:"mttr_seconds"`           // Mean time to recovery, over outages that ended
+	MTBFSeconds          float64      `json:"mtbf_seconds"`           // Mean time up between outages; 0 without outages
+	Latency              LatencyStats `json:"latency"`
+}
+
+// LatencyStats summarises check durations in milliseconds
+type LatencyStats struct {
+	AvgMs float64 `json:"avg_ms"`
+	P50Ms float64 `json:"p50_ms"`
+	P95Ms float64 `json:"p95_ms"`
+	P99Ms float64 `json:"p99_ms"`
+	MaxMs float64 `json:"max_ms"`
+}
+
+// Outage is a period a monitor was down. An ongoing outage ends at the end of
+// the range it was computed over.
+type Outage struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Ongoing         bool      `json:"ongoing"`
+}
+
+// statsCollector builds MonitorStats from results in ascending time order
+type statsCollector struct {
+	stats       MonitorStats
+	durations   []time.Duration
+	inOutage    bool
+	outageStart time.Time
+	upSince     time.Time // Start of the current up period; zero when unknown
+	upTime      time.Duration
+	downTime    time.Duration
+	longest     time.Duration
+	recovered   int
+	recoverTime time.Duration
+	outages     []Outage
+}
+
+// add records the next result
+func (sc *statsCollector) add(result *models.MonitorResult) {
+	weight := result.Weight()
+	sc.stats.Checks += weight
+	if result.Duration > 0 {
+		sc.durations = append(sc.durations, result.Duration)
+	}
+
+	switch result.Status {
+	case models.StatusDown:
+		sc.stats.DownChecks += weight
+		if sc.inOutage {
+			return
+		}
+		sc.inOutage = true
+		sc.outageStart = result.Timestamp
+		sc.stats.Outages++
+		if !sc.upSince.IsZero() {
+			sc.upTime += result.Timestamp.Sub(sc.upSince)
+			sc.upSince = time.Time{}
+		}
+
+	case models.StatusUp:
+		sc.stats.UpChecks += weight
+		if sc.inOutage {
+			outage := result.Timestamp.Sub(sc.outageStart)
+			sc.recordOutage(sc.outageStart, result.Timestamp, false)
+			sc.recovered++
+			sc.recoverTime += outage
+			sc.inOutage = false
+		}
+		if sc.upSince.IsZero() {
+			sc.upSince = result.Timestamp
+		}
+	}
+}
+
+// recordOutage adds an outage to the list and its duration to the totals
+func (sc *statsCollector) recordOutage(start, end time.Time, ongoing bool) {
+	outage := end.Sub(start)
+	sc.outages = append(sc.outages, Outage{
+		Start:           start,
+		End:             end,
+		DurationSeconds: outage.Seconds(),
+		Ongoing:         ongoing,
+	})
+	sc.downTime += outage
+	if outage > sc.longest {
+		sc.longest = outage
+	}
+}
+
+// finish closes the open up period or outage at end and returns the stats
+func (sc *statsCollector) finish(end time.Time) MonitorStats {
+	if sc.inOutage {
+		sc.stats.OngoingOutage = true
+		if end.After(sc.outageStart) {
+			sc.recordOutage(sc.outageStart, end, true)
+		}
+	} else if !sc.upSince.IsZero() && end.After(sc.upSince) {
+		sc.upTime += end.Sub(sc.upSince)
+	}
+
+	sc.stats.OutageSeconds = sc.downTime.Seconds()
+	sc.stats.LongestOutageSeconds = sc.longest.Seconds()
+	if sc.recovered > 0 {
+		sc.stats.MTTRSeconds = (sc.recoverTime / time.Duration(sc.recovered)).Seconds()
+	}
+	if sc.stats.Outages > 0 {
+		sc.stats.MTBFSeconds = (sc.upTime / time.Duration(sc.stats.Outages)).Seconds()
+	}
+	sc.stats.Latency = latencyStats(sc.durations)
+
+	return sc.stats
+}
+
+// latencyStats computes the mean, nearest-rank percentiles and maximum of durations
+func latencyStats(durations []time.Duration) LatencyStats {
+	if len(durations) == 0 {
+		return LatencyStats{}
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+
+	var total time.Duration
+	for _, d := range durations {
+		total += d
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(durations))))
+		return milliseconds(durations[max(rank-1, 0)])
+	}
+
+	return LatencyStats{
+		AvgMs: milliseconds(total / time.Duration(len(durations))),
+		P50Ms: percentile(50),
+		P95Ms: percentile(95),
+		P99Ms: percentile(99),
+		MaxMs: milliseconds(durations[len(durations)-1]),
+	}
+}
+
+// milliseconds converts d to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// MonitorStats computes reliability stats for a time range from stored
+// results. Stores that can stream results are read oldest first without
+// loading the range; otherwise up to maxCountedResults results are loaded.
+func (rs *ResultStore) MonitorStats(monitorName string, start, end time.Time) (MonitorStats, error) {
+	collector, err := rs.collectStats(monitorName, start, end)
+	if err != nil {
+		return MonitorStats{}, err
+	}
+	return collector.finish(end), nil
+}
+
+// MonitorOutages lists the outages in a time range, oldest first, read the
+// same way as MonitorStats
+func (rs *ResultStore) MonitorOutages(monitorName string, start, end time.Time) ([]Outage, error) {
+	collector, err := rs.collectStats(monitorName, start, end)
+	if err != nil {
+		return nil, err
+	}
+	collector.finish(end)
+	return collector.outages, nil
+}
+
+// collectStats feeds a time range's results to a collector, oldest first
+func (rs *ResultStore) collectStats(monitorName string, start, end time.Time) (*statsCollector, error) {
+	collector := &statsCollector{}
+
+	if streamer, ok := rs.persistentStore.(storage.ResultStreamer); ok {
+		err := streamer.StreamResults(monitorName, start, end, false, func(result *models.MonitorResult) bool {
+			collector.add(result)
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return collector, nil
+	}
+
+	results, err := rs.GetHistoricalResults(monitorName, start, end, maxCountedResults)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Timestamp.Before(results[j].Timestamp)
+	})
+	for _, result := range results {
+		collector.add(result)
+	}
+	return collector, nil
+}
@@ -0,0 +1,122 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestResultStoreMonitorStats(t *testing.T) {
+	start := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	tests := []struct {
+		name     string
+		statuses map[int]models.MonitorStatus // Minute offset to status
+		end      int
+		want     MonitorStats
+	}{
+		{
+			name:     "always up",
+			statuses: map[int]models.MonitorStatus{0: models.StatusUp, 10: models.StatusUp},
+			end:      60,
+			want:     MonitorStats{Checks: 2, UpChecks: 2},
+		},
+		{
+			// Up 0-10, down 10-20, up 20-50, down 50-55, up 55-60
+			name: "two recovered outages",
+			statuses: map[int]models.MonitorStatus{
+				0: models.StatusUp, 10: models.StatusDown, 15: models.StatusDown, 20: models.StatusUp,
+				50: models.StatusDown, 55: models.StatusUp,
+			},
+			end: 60,
+			want: MonitorStats{
+				Checks: 6, UpChecks: 3, DownChecks: 3, Outages: 2,
+				OutageSeconds: 15 * 60, LongestOutageSeconds: 10 * 60,
+				MTTRSeconds: 7.5 * 60, MTBFSeconds: 22.5 * 60,
+			},
+		},
+		{
+			// Up 0-30, down from 30 to the end
+			name:     "ongoing outage",
+			statuses: map[int]models.MonitorStatus{0: models.StatusUp, 30: models.StatusDown, 40: models.StatusUnknown},
+			end:      60,
+			want: MonitorStats{
+				Checks: 3, UpChecks: 1, DownChecks: 1, Outages: 1, OngoingOutage: true,
+				OutageSeconds: 30 * 60, LongestOutageSeconds: 30 * 60, MTBFSeconds: 30 * 60,
+			},
+		},
+		{
+			name: "no results",
+			end:  60,
+			want: MonitorStats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := NewResultStore(100)
+			for minute, status := range tt.statuses {
+				rs.StoreResult("api", newResult("api", status, at(minute)))
+			}
+
+			stats, err := rs.MonitorStats("api", start, at(tt.end))
+			if err != nil {
+				t.Fatalf("MonitorStats failed: %v", err)
+			}
+			stats.Latency = LatencyStats{}
+			if stats != tt.want {
+				t.Errorf("MonitorStats() = %+v\nwant %+v", stats, tt.want)
+			}
+		})
+	}
+}
+
+func TestLatencyStats(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+
+	got := latencyStats(durations)
+	want := LatencyStats{AvgMs: 50.5, P50Ms: 50, P95Ms: 95, P99Ms: 99, MaxMs: 100}
+	if got != want {
+		t.Errorf("latencyStats() = %+v, want %+v", got, want)
+	}
+
+	if got := latencyStats(nil); got != (LatencyStats{}) {
+		t.Errorf("latencyStats(nil) = %+v, want zero", got)
+	}
+}
+
+func TestResultStoreMonitorOutages(t *testing.T) {
+	start := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+
+	rs := NewResultStore(100)
+	for minute, status := range map[int]models.MonitorStatus{
+		0: models.StatusUp, 10: models.StatusDown, 20: models.StatusUp, 50: models.StatusDown,
+	} {
+		rs.StoreResult("api", newResult("api", status, at(minute)))
+	}
+
+	outages, err := rs.MonitorOutages("api", start, at(60))
+	if err != nil {
+		t.Fatalf("MonitorOutages failed: %v", err)
+	}
+
+	want := []Outage{
+		{Start: at(10), End: at(20), DurationSeconds: 600},
+		{Start: at(50), End: at(60), DurationSeconds: 600, Ongoing: true},
+	}
+	if len(outages) != len(want) {
+		t.Fatalf("expected %d outages, got %+v", len(want), outages)
+	}
+	for i := range want {
+		if !outages[i].Start.Equal(want[i].Start) || !outages[i].End.Equal(want[i].End) ||
+			outages[i].DurationSeconds != want[i].DurationSeconds || outages[i].Ongoing != want[i].Ongoing {
+			t.Errorf("outage %d = %+v, want %+v", i, outages[i], want[i])
+		}
+	}
+}
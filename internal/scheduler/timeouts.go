@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// timeoutWindow is how many recent check attempts are kept per monitor
+	timeoutWindow = 20
+
+	// timeoutMinSamples is how many attempts are needed before pressure is reported
+	timeoutMinSamples = 5
+
+	// nearTimeoutRatio is the share of the timeout an attempt must use to count as near it
+	nearTimeoutRatio = 0.8
+)
+
+// Timeout pressure levels
+const (
+	PressureOK       = "ok"
+	PressureElevated = "elevated" // At least a quarter of attempts near or over the timeout
+	PressureHigh     = "high"     // At least half of attempts near or over the timeout
+)
+
+// TimeoutPressure summarizes how close a monitor's recent check attempts came
+// to their timeout
+type TimeoutPressure struct {
+	Monitor     string        `json:"monitor"`
+	Timeout     time.Duration `json:"timeout"`
+	Checks      int           `json:"checks"`       // Attempts in the window
+	NearTimeout int           `json:"near_timeout"` // Attempts using at least 80% of the timeout
+	Overruns    int           `json:"overruns"`     // Attempts that hit the deadline
+	MaxDuration time.Duration `json:"max_duration"` // Slowest attempt in the window
+	Pressure    float64       `json:"pressure"`     // Share of attempts near or over the timeout
+	Level       string        `json:"level"`
+	Suggestion  string        `json:"suggestion,omitempty"`
+}
+
+type timeoutSample struct {
+	duration time.Duration
+	overrun  bool
+}
+
+type timeoutHistory struct {
+	timeout time.Duration
+	samples []timeoutSample // Ring buffer of the most recent attempts
+	next    int
+}
+
+// TimeoutTracker records how much of their timeout budget check attempts use,
+// so monitors that routinely run close to or over it can be flagged
+type TimeoutTracker struct {
+	history map[string]*timeoutHistory // Monitor name -> recent attempts
+	mu      sync.RWMutex
+}
+
+// NewTimeoutTracker creates a new timeout tracker
+func NewTimeoutTracker() *TimeoutTracker {
+	return &TimeoutTracker{
+		history: make(map[string]*timeoutHistory),
+	}
+}
+
+// Record adds a check attempt and reports whether it ran near its timeout.
+// overrun is set when the attempt hit its context deadline.
+func (tt *TimeoutTracker) Record(monitorName string, timeout, duration time.Duration, overrun bool) bool {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	h, ok := tt.history[monitorName]
+	if !ok || h.timeout != timeout {
+		// A new timeout makes earlier attempts irrelevant
+		h = &timeoutHistory{timeout: timeout}
+		tt.history[monitorName] = h
+	}
+
+	sample := timeoutSample{duration: duration, overrun: overrun}
+	if len(h.samples) < timeoutWindow {
+		h.samples = append(h.samples, sample)
+	} else {
+		h.samples[h.next] = sample
+	}
+	h.next = (h.next + 1) % timeoutWindow
+
+	return !overrun && isNearTimeout(duration, timeout)
+}
+
+// Pressure returns the timeout pressure for a monitor, or false if it has no
+// recorded attempts
+func (tt *TimeoutTracker) Pressure(monitorName string) (TimeoutPressure, bool) {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
+	h, ok := tt.history[monitorName]
+	if !ok {
+		return TimeoutPressure{}, false
+	}
+	return h.pressure(monitorName), true
+}
+
+// List returns the timeout pressure of every tracked monitor, highest first
+func (tt *TimeoutTracker) List() []TimeoutPressure {
+	tt.mu.RLock()
+	defer tt.mu.RUnlock()
+
+	list := make([]TimeoutPressure, 0, len(tt.history))
+	for name, h := range tt.history {
+		list = append(list, h.pressure(name))
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Pressure != list[j].Pressure {
+			return list[i].Pressure > list[j].Pressure
+		}
+		return list[i].Monitor < list[j].Monitor
+	})
+	return list
+}
+
+// Reset forgets a monitor's attempts
+func (tt *TimeoutTracker) Reset(monitorName string) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+	delete(tt.history, monitorName)
+}
+
+// pressure summarizes the recorded attempts
+func (h *timeoutHistory) pressure(monitorName string) TimeoutPressure {
+	p := TimeoutPressure{
+		Monitor: monitorName,
+		Timeout: h.timeout,
+		Checks:  len(h.samples),
+		Level:   PressureOK,
+	}
+	for _, sample := range h.samples {
+		switch {
+		case sample.overrun:
+			p.Overruns++
+		case isNearTimeout(sample.duration, h.timeout):
+			p.NearTimeout++
+		}
+		p.MaxDuration = max(p.MaxDuration, sample.duration)
+	}
+	if p.Checks == 0 {
+		return p
+	}
+
+	p.Pressure = float64(p.NearTimeout+p.Overruns) / float64(p.Checks)
+	if p.Checks < timeoutMinSamples {
+		return p
+	}
+
+	switch {
+	case p.Pressure >= 0.5:
+		p.Level = PressureHigh
+	case p.Pressure >= 0.25:
+		p.Level = PressureElevated
+	default:
+		return p
+	}
+
+	if p.Overruns > 0 {
+		p.Suggestion = fmt.Sprintf("%d of the last %d attempts hit the %s timeout; raise timeout to %s or investigate the target's latency",
+			p.Overruns, p.Checks, h.timeout, suggestedTimeout(2*h.timeout))
+	} else {
+		p.Suggestion = fmt.Sprintf("attempts use up to %.0f%% of the %s timeout; consider raising timeout to %s",
+			100*float64(p.MaxDuration)/float64(h.timeout), h.timeout, suggestedTimeout(p.MaxDuration*3/2))
+	}
+	return p
+}
+
+// isNearTimeout reports whether duration used most of the timeout budget
+func isNearTimeout(duration, timeout time.Duration) bool {
+	return timeout > 0 && float64(duration) >= nearTimeoutRatio*float64(timeout)
+}
+
+// suggestedTimeout rounds a proposed timeout up to a whole second
+func suggestedTimeout(d time.Duration) time.Duration {
+	rounded := d.Round(time.Second)
+	if rounded < d {
+		rounded += time.Second
+	}
+	return max(rounded, time.Second)
+}
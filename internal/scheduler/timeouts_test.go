@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeoutTrackerPressure(t *testing.T) {
+	timeout := 10 * time.Second
+
+	tests := []struct {
+		name           string
+		durations      []time.Duration // A zero duration records an overrun
+		wantLevel      string
+		wantNear       int
+		wantOverruns   int
+		wantSuggestion string
+	}{
+		{
+			name:      "fast checks",
+			durations: []time.Duration{time.Second, time.Second, 2 * time.Second, time.Second, time.Second},
+			wantLevel: PressureOK,
+		},
+		{
+			name:         "too few samples",
+			durations:    []time.Duration{9 * time.Second, 0},
+			wantLevel:    PressureOK,
+			wantNear:     1,
+			wantOverruns: 1,
+		},
+		{
+			name:           "elevated near timeout",
+			durations:      []time.Duration{9 * time.Second, 8 * time.Second, time.Second, time.Second, time.Second},
+			wantLevel:      PressureElevated,
+			wantNear:       2,
+			wantSuggestion: "consider raising timeout to 14s",
+		},
+		{
+			name:           "high with overruns",
+			durations:      []time.Duration{0, 0, 9 * time.Second, time.Second, time.Second},
+			wantLevel:      PressureHigh,
+			wantNear:       1,
+			wantOverruns:   2,
+			wantSuggestion: "raise timeout to 20s",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewTimeoutTracker()
+			for _, d := range tt.durations {
+				if d == 0 {
+					tracker.Record("api", timeout, timeout, true)
+				} else {
+					tracker.Record("api", timeout, d, false)
+				}
+			}
+
+			pressure, ok := tracker.Pressure("api")
+			if !ok {
+				t.Fatal("expected pressure for tracked monitor")
+			}
+			if pressure.Level != tt.wantLevel {
+				t.Errorf("expected level %q, got %q (%+v)", tt.wantLevel, pressure.Level, pressure)
+			}
+			if pressure.NearTimeout != tt.wantNear || pressure.Overruns != tt.wantOverruns {
+				t.Errorf("expected %d near and %d overruns, got %d and %d", tt.wantNear, tt.wantOverruns, pressure.NearTimeout, pressure.Overruns)
+			}
+			if !strings.Contains(pressure.Suggestion, tt.wantSuggestion) || (tt.wantSuggestion == "" && pressure.Suggestion != "") {
+				t.Errorf("expected suggestion containing %q, got %q", tt.wantSuggestion, pressure.Suggestion)
+			}
+		})
+	}
+}
+
+func TestTimeoutTrackerWindow(t *testing.T) {
+	tracker := NewTimeoutTracker()
+	timeout := time.Second
+
+	for i := 0; i < timeoutWindow; i++ {
+		tracker.Record("api", timeout, timeout, true)
+	}
+	// Fast attempts push the overruns out of the window
+	for i := 0; i < timeoutWindow; i++ {
+		if tracker.Record("api", timeout, 100*time.Millisecond, false) {
+			t.Fatal("fast attempt reported as near timeout")
+		}
+	}
+
+	pressure, _ := tracker.Pressure("api")
+	if pressure.Checks != timeoutWindow || pressure.Overruns != 0 || pressure.Level != PressureOK {
+		t.Errorf("expected a full window of fast attempts, got %+v", pressure)
+	}
+}
+
+func TestTimeoutTrackerTimeoutChange(t *testing.T) {
+	tracker := NewTimeoutTracker()
+
+	for i := 0; i < timeoutMinSamples; i++ {
+		tracker.Record("api", time.Second, time.Second, true)
+	}
+	if tracker.List()[0].Level != PressureHigh {
+		t.Fatalf("expected high pressure, got %+v", tracker.List()[0])
+	}
+
+	// Raising the timeout discards attempts made under the old one
+	tracker.Record("api", 5*time.Second, time.Second, false)
+	pressure, _ := tracker.Pressure("api")
+	if pressure.Checks != 1 || pressure.Timeout != 5*time.Second {
+		t.Errorf("expected history reset on timeout change, got %+v", pressure)
+	}
+
+	tracker.Reset("api")
+	if _, ok := tracker.Pressure("api"); ok {
+		t.Error("expected no pressure after reset")
+	}
+}
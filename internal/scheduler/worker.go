@@ -2,6 +2,8 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,7 +16,8 @@ import (
 
 // WorkerPool manages a pool of workers for executing monitor checks
 type WorkerPool struct {
-	size          int
+	size          int // Minimum and initial number of workers
+	autoscale     AutoscaleConfig
 	jobQueue      chan *MonitorJob
 	workers       []*Worker
 	logger        *logging.Logger
@@ -24,14 +27,42 @@ type WorkerPool struct {
 	wg            sync.WaitGroup
 	processedJobs int64
 	activeWorkers int32
+
+	// current counts running workers and nextID numbers new ones; guarded by scaleMu
+	current int
+	nextID  int
+	stopped bool
+	scaleMu sync.Mutex
+}
+
+// AutoscaleConfig lets a worker pool grow beyond its base size while checks
+// back up, shrinking again once the extra workers sit idle
+type AutoscaleConfig struct {
+	MaxWorkers     int           // Upper bound on pool size; autoscaling is off unless above the base size
+	QueueThreshold int           // Queued jobs that trigger a scale-up while every worker is busy (default 1)
+	LagThreshold   time.Duration // Time a job waited in the queue that triggers a scale-up (default 2s)
+	IdleTimeout    time.Duration // How long an extra worker waits for work before exiting (default 1m)
 }
 
+// Autoscale defaults
+const (
+	defaultQueueThreshold = 1
+	defaultLagThreshold   = 2 * time.Second
+	defaultIdleTimeout    = time.Minute
+)
+
 // MonitorJob represents a monitor check job
 type MonitorJob struct {
 	Monitor     monitors.Monitor
 	ResultStore *ResultStore
 	Backoff     *BackoffManager
+	Faults      *FaultInjector   // Optional simulated failures
+	Timeouts    *TimeoutTracker  // Optional timeout budget accounting
+	Recovery    *RecoveryTracker // Optional recovery confirmation
+	Processor   ResultProcessor  // Optional rewriting of results before they are stored
 	ScheduledAt time.Time
+	OnResult    func(result *models.MonitorResult) // Optional callback for completed results
+	OnDone      func()                             // Optional callback once the job finishes, even if it panics
 }
 
 // Worker represents a single worker goroutine
@@ -57,6 +88,27 @@ func NewWorkerPool(size int, logger *logging.Logger, metrics *metrics.Metrics) *
 	}
 }
 
+// SetAutoscale enables autoscaling up to config.MaxWorkers. It must be called
+// before Start, and has no effect unless MaxWorkers exceeds the base size.
+func (wp *WorkerPool) SetAutoscale(config AutoscaleConfig) {
+	if config.MaxWorkers <= wp.size {
+		wp.autoscale = AutoscaleConfig{}
+		return
+	}
+	if config.QueueThreshold <= 0 {
+		config.QueueThreshold = defaultQueueThreshold
+	}
+	if config.LagThreshold <= 0 {
+		config.LagThreshold = defaultLagThreshold
+	}
+	if config.IdleTimeout <= 0 {
+		config.IdleTimeout = defaultIdleTimeout
+	}
+
+	wp.autoscale = config
+	wp.jobQueue = make(chan *MonitorJob, config.MaxWorkers*2) // Buffer for 2x the largest pool
+}
+
 // Start starts the worker pool
 func (wp *WorkerPool) Start(ctx context.Context) {
 	wp.ctx, wp.cancel = context.WithCancel(ctx)
@@ -64,29 +116,30 @@ func (wp *WorkerPool) Start(ctx context.Context) {
 	wp.logger.WithComponent(logging.ComponentScheduler).
 		WithFields(map[string]interface{}{
 			"worker_count": wp.size,
+			"max_workers":  max(wp.size, wp.autoscale.MaxWorkers),
 			"queue_size":   cap(wp.jobQueue),
 		}).
 		Info("Starting worker pool")
 
+	wp.scaleMu.Lock()
+	defer wp.scaleMu.Unlock()
+
 	// Start workers
 	for i := 0; i < wp.size; i++ {
-		worker := &Worker{
-			id:      i,
-			pool:    wp,
-			logger:  wp.logger,
-			metrics: wp.metrics,
-		}
-		wp.workers[i] = worker
-
-		wp.wg.Add(1)
-		go worker.start(wp.ctx)
+		wp.workers[i] = wp.spawnLocked()
 	}
+	wp.recordSizeLocked()
 }
 
 // Stop stops the worker pool
 func (wp *WorkerPool) Stop() {
 	wp.logger.WithComponent(logging.ComponentScheduler).Info("Stopping worker pool")
 
+	// Keep autoscaling from adding workers while the pool drains
+	wp.scaleMu.Lock()
+	wp.stopped = true
+	wp.scaleMu.Unlock()
+
 	// Cancel context and close job queue
 	wp.cancel()
 	close(wp.jobQueue)
@@ -94,6 +147,13 @@ func (wp *WorkerPool) Stop() {
 	// Wait for all workers to finish
 	wp.wg.Wait()
 
+	// Jobs still queued will never run; let their owners release them
+	for job := range wp.jobQueue {
+		if job.OnDone != nil {
+			job.OnDone()
+		}
+	}
+
 	wp.logger.WithComponent(logging.ComponentScheduler).
 		WithFields(map[string]interface{}{
 			"processed_jobs": atomic.LoadInt64(&wp.processedJobs),
@@ -105,6 +165,9 @@ func (wp *WorkerPool) Stop() {
 func (wp *WorkerPool) Submit(job *MonitorJob) bool {
 	select {
 	case wp.jobQueue <- job:
+		if wp.autoscale.MaxWorkers > 0 && len(wp.jobQueue) >= wp.autoscale.QueueThreshold && wp.ActiveWorkers() >= wp.Size() {
+			wp.scaleUp("queue_depth")
+		}
 		return true
 	default:
 		// Queue is full
@@ -112,6 +175,81 @@ func (wp *WorkerPool) Submit(job *MonitorJob) bool {
 	}
 }
 
+// Size returns the current number of workers
+func (wp *WorkerPool) Size() int {
+	wp.scaleMu.Lock()
+	defer wp.scaleMu.Unlock()
+	return wp.current
+}
+
+// scaleUp adds a worker unless the pool is stopped or already at its maximum
+func (wp *WorkerPool) scaleUp(reason string) {
+	wp.scaleMu.Lock()
+	defer wp.scaleMu.Unlock()
+
+	if wp.stopped || wp.ctx == nil || wp.current >= wp.autoscale.MaxWorkers {
+		return
+	}
+	wp.spawnLocked()
+	wp.recordSizeLocked()
+
+	if wp.metrics != nil {
+		wp.metrics.RecordWorkerScaling("up")
+	}
+	wp.logger.WithComponent(logging.ComponentScheduler).
+		WithFields(map[string]interface{}{
+			"reason":       reason,
+			"worker_count": wp.current,
+			"queue_depth":  len(wp.jobQueue),
+		}).
+		Debug("Worker pool scaled up")
+}
+
+// retire lets an idle worker exit if the pool is above its base size
+func (wp *WorkerPool) retire() bool {
+	wp.scaleMu.Lock()
+	defer wp.scaleMu.Unlock()
+
+	if wp.current <= wp.size {
+		return false
+	}
+	wp.current--
+	wp.recordSizeLocked()
+
+	if wp.metrics != nil {
+		wp.metrics.RecordWorkerScaling("down")
+	}
+	wp.logger.WithComponent(logging.ComponentScheduler).
+		WithFields(map[string]interface{}{
+			"worker_count": wp.current,
+		}).
+		Debug("Worker pool scaled down")
+	return true
+}
+
+// spawnLocked starts a new worker; callers must hold scaleMu
+func (wp *WorkerPool) spawnLocked() *Worker {
+	worker := &Worker{
+		id:      wp.nextID,
+		pool:    wp,
+		logger:  wp.logger,
+		metrics: wp.metrics,
+	}
+	wp.nextID++
+	wp.current++
+
+	wp.wg.Add(1)
+	go worker.start(wp.ctx)
+	return worker
+}
+
+// recordSizeLocked publishes the pool size; callers must hold scaleMu
+func (wp *WorkerPool) recordSizeLocked() {
+	if wp.metrics != nil {
+		wp.metrics.SetWorkerPoolSize(wp.current)
+	}
+}
+
 // ActiveWorkers returns the number of currently active workers
 func (wp *WorkerPool) ActiveWorkers() int {
 	return int(atomic.LoadInt32(&wp.activeWorkers))
@@ -137,8 +275,23 @@ func (w *Worker) start(ctx context.Context) {
 		}).
 		Debug("Worker started")
 
+	// Only autoscaled pools retire idle workers
+	var idle <-chan time.Time
+	var idleTimer *time.Timer
+	if w.pool.autoscale.MaxWorkers > 0 {
+		idleTimer = time.NewTimer(w.pool.autoscale.IdleTimeout)
+		defer idleTimer.Stop()
+		idle = idleTimer.C
+	}
+
 	for {
 		select {
+		case <-idle:
+			if w.pool.retire() {
+				return
+			}
+			idleTimer.Reset(w.pool.autoscale.IdleTimeout)
+
 		case <-ctx.Done():
 			w.logger.WithComponent(logging.ComponentScheduler).
 				WithFields(map[string]interface{}{
@@ -157,13 +310,32 @@ func (w *Worker) start(ctx context.Context) {
 				return
 			}
 
+			// Jobs waiting too long mean the pool cannot keep up
+			if w.pool.autoscale.MaxWorkers > 0 && time.Since(job.ScheduledAt) > w.pool.autoscale.LagThreshold {
+				w.pool.scaleUp("scheduling_lag")
+			}
+
 			w.processJob(ctx, job)
+
+			if idleTimer != nil {
+				if !idleTimer.Stop() {
+					select {
+					case <-idleTimer.C:
+					default:
+					}
+				}
+				idleTimer.Reset(w.pool.autoscale.IdleTimeout)
+			}
 		}
 	}
 }
 
 // processJob processes a single monitor job
 func (w *Worker) processJob(ctx context.Context, job *MonitorJob) {
+	if job.OnDone != nil {
+		defer job.OnDone()
+	}
+
 	// Add panic recovery to prevent worker crashes
 	defer func() {
 		if r := recover(); r != nil {
@@ -204,9 +376,6 @@ func (w *Worker) processJob(ctx context.Context, job *MonitorJob) {
 		timeout = 10 * time.Second
 	}
 
-	checkCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-
 	// Log start of check
 	w.logger.WithComponent(logging.ComponentScheduler).
 		WithMonitor(monitorName, string(monitor.GetType()), monitor.GetGroup()).
@@ -218,10 +387,18 @@ func (w *Worker) processJob(ctx context.Context, job *MonitorJob) {
 		}).
 		Debug("Starting monitor check")
 
+	// Simulated failures replace the check entirely
+	if job.Faults != nil {
+		if fault, ok := job.Faults.Active(monitorName); ok {
+			w.injectFailure(job, fault)
+			return
+		}
+	}
+
 	startTime := time.Now()
 
-	// Execute the monitor check
-	result, err := monitor.Check(checkCtx)
+	// Execute the monitor check, retrying failed attempts if configured
+	result, err := w.checkWithRetries(ctx, monitor, timeout, job.Timeouts)
 
 	duration := time.Since(startTime)
 
@@ -264,11 +441,24 @@ func (w *Worker) processJob(ctx context.Context, job *MonitorJob) {
 
 	// Store the result
 	if result != nil {
-		job.ResultStore.StoreResult(monitorName, result)
+		succeeded := result.Status == models.StatusUp
+		if job.Recovery != nil {
+			w.confirmRecovery(job, result)
+		}
+		result.Quiet = models.QuietAction(monitor.GetConfig().QuietHours, result.Timestamp)
+		if job.Processor != nil {
+			job.Processor.ProcessResult(monitor.GetConfig(), result)
+		}
+		job.ResultStore.StoreSampledResult(monitorName, result, monitor.GetConfig().SampleRate)
+
+		// Notify result handlers
+		if job.OnResult != nil {
+			job.OnResult(result)
+		}
 
-		// Update backoff based on result status
+		// Update backoff based on the check itself, so recovery checks keep their interval
 		if job.Backoff != nil {
-			if result.Status == models.StatusUp {
+			if succeeded {
 				job.Backoff.RecordSuccess(monitorName)
 			} else {
 				job.Backoff.RecordFailure(monitorName)
@@ -286,3 +476,136 @@ func (w *Worker) processJob(ctx context.Context, job *MonitorJob) {
 		)
 	}
 }
+
+// injectFailure stores a synthetic down result for a monitor with an active
+// fault. Backoff is left untouched so checks resume normally once it expires.
+func (w *Worker) injectFailure(job *MonitorJob, fault Fault) {
+	monitor := job.Monitor
+	monitorName := monitor.GetName()
+
+	errMsg := "synthetic failure injected"
+	if fault.Reason != "" {
+		errMsg += ": " + fault.Reason
+	}
+
+	result := &models.MonitorResult{
+		Monitor:   monitorName,
+		Type:      monitor.GetType(),
+		Group:     monitor.GetGroup(),
+		Status:    models.StatusDown,
+		Error:     errMsg,
+		Timestamp: time.Now(),
+		Synthetic: true,
+	}
+	result.Quiet = models.QuietAction(monitor.GetConfig().QuietHours, result.Timestamp)
+	if job.Recovery != nil {
+		job.Recovery.Reset(monitorName)
+	}
+
+	if w.metrics != nil {
+		w.metrics.RecordCheck(monitorName, string(result.Type), result.Group, "failure", 0, result.ID())
+		w.metrics.SetMonitorStatus(monitorName, string(result.Type), result.Group, false)
+		w.metrics.RecordError(monitorName, string(result.Type), result.Group, "synthetic")
+	}
+
+	if job.Processor != nil {
+		job.Processor.ProcessResult(monitor.GetConfig(), result)
+	}
+	job.ResultStore.StoreResult(monitorName, result)
+	if job.OnResult != nil {
+		job.OnResult(result)
+	}
+
+	w.logger.WithComponent(logging.ComponentScheduler).
+		WithMonitor(monitorName, string(monitor.GetType()), monitor.GetGroup()).
+		WithFields(map[string]interface{}{
+			"worker_id":  w.id,
+			"reason":     fault.Reason,
+			"expires_at": fault.ExpiresAt,
+		}).
+		Warn("Synthetic failure injected")
+}
+
+// confirmRecovery reports a successful check of a down monitor as still down
+// until the monitor's recoveryThreshold consecutive checks have succeeded
+func (w *Worker) confirmRecovery(job *MonitorJob, result *models.MonitorResult) {
+	monitor := job.Monitor
+	threshold := monitor.GetConfig().RecoveryThreshold
+
+	previous := models.StatusUnknown
+	if latest := job.ResultStore.GetLatestResult(monitor.GetName()); latest != nil {
+		previous = latest.Status
+	}
+
+	successes, confirmed := job.Recovery.Observe(monitor.GetName(), previous, result.Status, threshold)
+	if confirmed {
+		return
+	}
+
+	result.Status = models.StatusDown
+	result.Error = fmt.Sprintf("recovering: %d of %d consecutive checks succeeded", successes, threshold)
+	if w.metrics != nil {
+		w.metrics.SetMonitorStatus(monitor.GetName(), string(result.Type), result.Group, false)
+	}
+
+	w.logger.WithComponent(logging.ComponentScheduler).
+		WithMonitor(monitor.GetName(), string(monitor.GetType()), monitor.GetGroup()).
+		WithFields(map[string]interface{}{
+			"worker_id": w.id,
+			"successes": successes,
+			"threshold": threshold,
+		}).
+		Debug("Monitor check succeeded, awaiting recovery confirmation")
+}
+
+// retryDelay is the pause between attempts of a failing check
+var retryDelay = time.Second
+
+// checkWithRetries runs a monitor check, repeating it up to the monitor's
+// retry count while it fails. Each attempt gets the full timeout, and its use
+// of that budget is recorded in timeouts when given.
+func (w *Worker) checkWithRetries(ctx context.Context, monitor monitors.Monitor, timeout time.Duration, timeouts *TimeoutTracker) (*models.MonitorResult, error) {
+	retries := monitor.GetConfig().Retries
+
+	for attempt := 0; ; attempt++ {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		attemptStart := time.Now()
+		result, err := monitor.Check(checkCtx)
+		elapsed := time.Since(attemptStart)
+		overrun := errors.Is(checkCtx.Err(), context.DeadlineExceeded)
+		cancel()
+
+		if timeouts != nil {
+			near := timeouts.Record(monitor.GetName(), timeout, elapsed, overrun)
+			if w.metrics != nil && (near || overrun) {
+				kind := "near"
+				if overrun {
+					kind = "overrun"
+				}
+				w.metrics.RecordTimeoutPressure(monitor.GetName(), string(monitor.GetType()), monitor.GetGroup(), kind)
+			}
+		}
+
+		if err == nil && result != nil && result.Status == models.StatusUp {
+			return result, nil
+		}
+		if attempt >= retries {
+			return result, err
+		}
+
+		w.logger.WithComponent(logging.ComponentScheduler).
+			WithMonitor(monitor.GetName(), string(monitor.GetType()), monitor.GetGroup()).
+			WithFields(map[string]interface{}{
+				"worker_id": w.id,
+				"attempt":   attempt + 1,
+				"retries":   retries,
+			}).
+			Debug("Monitor check failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(retryDelay):
+		}
+	}
+}
@@ -352,3 +352,121 @@ func TestWorkerPoolBackoffIntegration(t *testing.T) {
 		t.Fatalf("expected at least one backed off monitor")
 	}
 }
+
+// flakyMonitor fails a fixed number of checks before succeeding
+type flakyMonitor struct {
+	mockMonitor
+	retries  int
+	failures int32
+	checks   int32
+}
+
+func (m *flakyMonitor) GetConfig() *models.Monitor {
+	config := m.mockMonitor.GetConfig()
+	config.Retries = m.retries
+	return config
+}
+
+func (m *flakyMonitor) Check(ctx context.Context) (*models.MonitorResult, error) {
+	status := models.StatusUp
+	if atomic.AddInt32(&m.checks, 1) <= m.failures {
+		status = models.StatusDown
+	}
+	return &models.MonitorResult{Monitor: m.name, Status: status, Timestamp: time.Now()}, nil
+}
+
+func TestWorkerCheckWithRetries(t *testing.T) {
+	previous := retryDelay
+	retryDelay = time.Millisecond
+	t.Cleanup(func() { retryDelay = previous })
+
+	tests := []struct {
+		name       string
+		retries    int
+		failures   int32
+		wantStatus models.MonitorStatus
+		wantChecks int32
+	}{
+		{name: "no retries", retries: 0, failures: 1, wantStatus: models.StatusDown, wantChecks: 1},
+		{name: "recovers within retries", retries: 2, failures: 2, wantStatus: models.StatusUp, wantChecks: 3},
+		{name: "exhausts retries", retries: 2, failures: 5, wantStatus: models.StatusDown, wantChecks: 3},
+		{name: "healthy first try", retries: 3, failures: 0, wantStatus: models.StatusUp, wantChecks: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker := &Worker{id: 1, logger: testLogger(t)}
+			monitor := &flakyMonitor{mockMonitor: mockMonitor{name: "flaky"}, retries: tt.retries, failures: tt.failures}
+
+			result, err := worker.checkWithRetries(context.Background(), monitor, time.Second, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, result.Status)
+			}
+			if got := atomic.LoadInt32(&monitor.checks); got != tt.wantChecks {
+				t.Errorf("expected %d checks, got %d", tt.wantChecks, got)
+			}
+		})
+	}
+}
+
+func TestWorkerPoolAutoscale(t *testing.T) {
+	wp := NewWorkerPool(1, testLogger(t), nil)
+	wp.SetAutoscale(AutoscaleConfig{MaxWorkers: 3, LagThreshold: time.Hour, IdleTimeout: 50 * time.Millisecond})
+	rs := NewResultStore(10)
+
+	wp.Start(context.Background())
+	defer wp.Stop()
+
+	submit := func(name string) {
+		t.Helper()
+		job := &MonitorJob{
+			Monitor:     &mockMonitor{name: name, group: "g", delay: 200 * time.Millisecond},
+			ResultStore: rs,
+			ScheduledAt: time.Now(),
+		}
+		if !wp.Submit(job) {
+			t.Fatalf("failed to submit %s", name)
+		}
+	}
+
+	waitFor := func(desc string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", desc)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// Occupy the only worker so further jobs back up in the queue
+	submit("busy")
+	waitFor("first job to start", func() bool { return wp.ActiveWorkers() == 1 })
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		submit(name)
+	}
+	waitFor("pool to scale up", func() bool { return wp.Size() > 1 })
+	if size := wp.Size(); size > 3 {
+		t.Fatalf("expected pool to stay within max of 3, got %d", size)
+	}
+
+	// Extra workers exit once the backlog clears
+	waitFor("all jobs to finish", func() bool { return wp.ProcessedJobs() == 5 })
+	waitFor("pool to scale down", func() bool { return wp.Size() == 1 })
+}
+
+func TestWorkerPoolAutoscaleDisabled(t *testing.T) {
+	wp := NewWorkerPool(4, testLogger(t), nil)
+	wp.SetAutoscale(AutoscaleConfig{MaxWorkers: 2})
+	if wp.autoscale.MaxWorkers != 0 {
+		t.Errorf("expected autoscaling disabled when maxWorkers is below the pool size")
+	}
+	if got := cap(wp.jobQueue); got != 8 {
+		t.Errorf("expected queue sized for 4 workers, got %d", got)
+	}
+}
@@ -0,0 +1,187 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSProvider reads secrets from AWS Secrets Manager. References are secret
+// names or ARNs with an optional JSON key, such as prod/app#token. Credentials
+// come from $AWS_ACCESS_KEY_ID, $AWS_SECRET_ACCESS_KEY, and
+// $AWS_SESSION_TOKEN; the region from the ARN, $AWS_REGION, or
+// $AWS_DEFAULT_REGION.
+type AWSProvider struct {
+	Client *http.Client
+	now    func() time.Time
+}
+
+// awsCredentials sign requests to AWS
+type awsCredentials struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+// Fetch reads the current version of a secret
+func (p *AWSProvider) Fetch(ctx context.Context, ref string) (Secret, error) {
+	secretID, field := splitField(ref)
+	if secretID == "" {
+		return Secret{}, fmt.Errorf("aws-sm reference needs a secret name or ARN")
+	}
+	creds := awsCredentials{
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return Secret{}, fmt.Errorf("$AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY are not set")
+	}
+	region := awsRegion(secretID)
+	if region == "" {
+		return Secret{}, fmt.Errorf("no region; set $AWS_REGION or use the secret's ARN")
+	}
+
+	endpoint := os.Getenv("AWS_ENDPOINT_URL_SECRETS_MANAGER")
+	if endpoint == "" {
+		endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	if endpoint == "" {
+		endpoint = "https://secretsmanager." + region + ".amazonaws.com"
+	}
+
+	body, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return Secret{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return Secret{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	now := time.Now
+	if p.now != nil {
+		now = p.now
+	}
+	signAWSRequest(req, body, creds, region, "secretsmanager", now())
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return Secret{}, fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Secret{}, fmt.Errorf("failed to read secrets manager response: %w", err)
+	}
+
+	var decoded struct {
+		SecretString string `json:"SecretString"`
+		SecretBinary string `json:"SecretBinary"`
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return Secret{}, fmt.Errorf("secrets manager returned %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Secret{}, fmt.Errorf("secrets manager returned %s: %s %s", resp.Status, decoded.Type, decoded.Message)
+	}
+
+	value := decoded.SecretString
+	if value == "" && decoded.SecretBinary != "" {
+		binary, err := base64.StdEncoding.DecodeString(decoded.SecretBinary)
+		if err != nil {
+			return Secret{}, fmt.Errorf("failed to decode binary secret: %w", err)
+		}
+		value = string(binary)
+	}
+	if value, err = jsonField(value, field); err != nil {
+		return Secret{}, err
+	}
+	return Secret{Value: value}, nil
+}
+
+// awsRegion returns the region of a secret ARN, or the configured region
+func awsRegion(secretID string) string {
+	// arn:aws:secretsmanager:REGION:ACCOUNT:secret:NAME
+	if parts := strings.SplitN(secretID, ":", 5); len(parts) == 5 && parts[0] == "arn" {
+		return parts[3]
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// signAWSRequest adds a Signature Version 4 Authorization header to req,
+// signing the host and every header already set
+func signAWSRequest(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.accessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data with key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// gcpEndpoint is the Secret Manager API
+const gcpEndpoint = "https://secretmanager.googleapis.com"
+
+// defaultMetadataHost serves access tokens on Google Cloud instances
+const defaultMetadataHost = "metadata.google.internal"
+
+// GCPProvider reads secrets from Google Cloud Secret Manager. References are
+// secret or version names with an optional JSON key, such as
+// projects/acme/secrets/app#token; the latest version is read when none is
+// named. The access token comes from $GOOGLE_OAUTH_ACCESS_TOKEN, or else the
+// instance metadata server ($GCE_METADATA_HOST overrides its address).
+type GCPProvider struct {
+	Client   *http.Client
+	endpoint string // Overrides gcpEndpoint in tests
+}
+
+// Fetch reads a secret version
+func (p *GCPProvider) Fetch(ctx context.Context, ref string) (Secret, error) {
+	name, field := splitField(ref)
+	name = strings.Trim(name, "/")
+	if !strings.HasPrefix(name, "projects/") || !strings.Contains(name, "/secrets/") {
+		return Secret{}, fmt.Errorf("gcp-sm reference must look like projects/PROJECT/secrets/SECRET[/versions/VERSION]")
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+
+	token, err := p.accessToken(ctx)
+	if err != nil {
+		return Secret{}, err
+	}
+	endpoint := p.endpoint
+	if endpoint == "" {
+		endpoint = gcpEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/v1/"+name+":access", nil)
+	if err != nil {
+		return Secret{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var decoded struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := p.do(req, &decoded); err != nil {
+		if decoded.Error.Message != "" {
+			return Secret{}, fmt.Errorf("%w: %s", err, decoded.Error.Message)
+		}
+		return Secret{}, err
+	}
+	data, err := base64.StdEncoding.DecodeString(decoded.Payload.Data)
+	if err != nil {
+		return Secret{}, fmt.Errorf("failed to decode secret payload: %w", err)
+	}
+	value, err := jsonField(string(data), field)
+	if err != nil {
+		return Secret{}, err
+	}
+	return Secret{Value: value}, nil
+}
+
+// accessToken returns an OAuth token for the Secret Manager API
+func (p *GCPProvider) accessToken(ctx context.Context) (string, error) {
+	if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = defaultMetadataHost
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var decoded struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := p.do(req, &decoded); err != nil {
+		return "", fmt.Errorf("no $GOOGLE_OAUTH_ACCESS_TOKEN and the metadata server gave no token: %w", err)
+	}
+	return decoded.AccessToken, nil
+}
+
+// do sends a request and decodes its JSON response into out, also when the
+// request fails, so error details can be read
+func (p *GCPProvider) do(req *http.Request, out interface{}) error {
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", req.URL.Host, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	decodeErr := json.Unmarshal(data, out)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", req.URL.Host, resp.Status)
+	}
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+	return nil
+}
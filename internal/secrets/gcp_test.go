@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGCPProviderFetch(t *testing.T) {
+	payload := base64.StdEncoding.EncodeToString([]byte(`{"token":"tok-1"}`))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/computeMetadata/v1/instance/service-accounts/default/token":
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(`{"access_token":"from-metadata","expires_in":3599}`))
+		case r.Header.Get("Authorization") != "Bearer from-metadata":
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"code":401,"message":"Request had invalid authentication credentials."}}`))
+		case r.URL.Path == "/v1/projects/acme/secrets/app/versions/latest:access",
+			r.URL.Path == "/v1/projects/acme/secrets/app/versions/2:access":
+			w.Write([]byte(`{"name":"projects/1/secrets/app/versions/2","payload":{"data":"` + payload + `"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":{"code":404,"message":"Secret not found"}}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "")
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+	provider := &GCPProvider{Client: server.Client(), endpoint: server.URL}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr string
+	}{
+		{ref: "projects/acme/secrets/app#token", want: "tok-1"},
+		{ref: "projects/acme/secrets/app/versions/2", want: `{"token":"tok-1"}`},
+		{ref: "projects/acme/secrets/other", wantErr: "Secret not found"},
+		{ref: "acme/app", wantErr: "must look like"},
+	}
+	for _, tt := range tests {
+		secret, err := provider.Fetch(context.Background(), tt.ref)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Fetch(%q) error = %v, want %q", tt.ref, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || secret.Value != tt.want {
+			t.Errorf("Fetch(%q) = %q, %v, want %q", tt.ref, secret.Value, err, tt.want)
+		}
+	}
+
+	t.Setenv("GOOGLE_OAUTH_ACCESS_TOKEN", "stale")
+	if _, err := provider.Fetch(context.Background(), "projects/acme/secrets/app"); err == nil || !strings.Contains(err.Error(), "invalid authentication") {
+		t.Errorf("expected the API's error message, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Package secrets resolves references to secrets held in HashiCorp Vault, AWS
+// Secrets Manager, and Google Cloud Secret Manager. Fetched values are cached,
+// and renewable Vault leases are kept alive while they are cached.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reference schemes
+const (
+	SchemeVault = "vault"
+	SchemeAWS   = "aws-sm"
+	SchemeGCP   = "gcp-sm"
+)
+
+// defaultTTL is how long values without a lease are cached
+const defaultTTL = 5 * time.Minute
+
+// fetchTimeout bounds fetching one secret
+const fetchTimeout = 10 * time.Second
+
+// Secret is a value fetched from a provider
+type Secret struct {
+	Value string
+	TTL   time.Duration // How long the value may be cached; 0 for the default
+
+	// Renew extends the value's lease and returns the new lease duration.
+	// It is nil for values that cannot be renewed.
+	Renew func(ctx context.Context) (time.Duration, error)
+}
+
+// Provider fetches secrets by reference from one secret store
+type Provider interface {
+	Fetch(ctx context.Context, ref string) (Secret, error)
+}
+
+// Resolver resolves references through registered providers, caching values
+type Resolver struct {
+	mu        sync.Mutex
+	providers map[string]Provider
+	cache     map[string]*entry
+	now       func() time.Time
+}
+
+// entry is a cached value
+type entry struct {
+	value   string
+	expires time.Time
+	renewal *time.Timer
+}
+
+// NewResolver creates a resolver without providers
+func NewResolver() *Resolver {
+	return &Resolver{
+		providers: make(map[string]Provider),
+		cache:     make(map[string]*entry),
+		now:       time.Now,
+	}
+}
+
+// Default resolves references with providers configured from the standard
+// environment variables of each secret store
+var Default = newDefaultResolver()
+
+// newDefaultResolver creates a resolver with every built-in provider
+func newDefaultResolver() *Resolver {
+	client := &http.Client{Timeout: fetchTimeout}
+	r := NewResolver()
+	r.Register(SchemeVault, &VaultProvider{Client: client})
+	r.Register(SchemeAWS, &AWSProvider{Client: client})
+	r.Register(SchemeGCP, &GCPProvider{Client: client})
+	return r
+}
+
+// Register makes a provider handle references with the given scheme
+func (r *Resolver) Register(scheme string, provider Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[scheme] = provider
+}
+
+// Schemes returns the registered schemes, sorted
+func (r *Resolver) Schemes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	schemes := make([]string, 0, len(r.providers))
+	for scheme := range r.providers {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// Resolve returns the value a reference points to, from the cache while it
+// is fresh
+func (r *Resolver) Resolve(ctx context.Context, scheme, ref string) (string, error) {
+	key := scheme + ":" + ref
+
+	r.mu.Lock()
+	provider, ok := r.providers[scheme]
+	if cached, hit := r.cache[key]; hit && r.now().Before(cached.expires) {
+		r.mu.Unlock()
+		return cached.value, nil
+	}
+	r.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("unknown secret store %q", scheme)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+	secret, err := provider.Fetch(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", key, err)
+	}
+
+	ttl := secret.TTL
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	cached := &entry{value: secret.Value, expires: r.now().Add(ttl)}
+	r.mu.Lock()
+	if previous, ok := r.cache[key]; ok && previous.renewal != nil {
+		previous.renewal.Stop()
+	}
+	r.cache[key] = cached
+	if secret.Renew != nil {
+		r.scheduleRenewal(key, cached, secret.Renew, ttl)
+	}
+	r.mu.Unlock()
+	return secret.Value, nil
+}
+
+// scheduleRenewal renews a cached value's lease when two thirds of it have
+// passed, for as long as the value stays cached. A lease that cannot be
+// renewed is dropped from the cache so the next load fetches a new value.
+// Called with r.mu held.
+func (r *Resolver) scheduleRenewal(key string, cached *entry, renew func(context.Context) (time.Duration, error), lease time.Duration) {
+	cached.renewal = time.AfterFunc(lease*2/3, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		defer cancel()
+		next, err := renew(ctx)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.cache[key] != cached {
+			return
+		}
+		if err != nil || next <= 0 {
+			delete(r.cache, key)
+			return
+		}
+		cached.expires = r.now().Add(next)
+		r.scheduleRenewal(key, cached, renew, next)
+	})
+}
+
+// Flush empties the cache and stops lease renewals
+func (r *Resolver) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, cached := range r.cache {
+		if cached.renewal != nil {
+			cached.renewal.Stop()
+		}
+		delete(r.cache, key)
+	}
+}
+
+// splitField splits a reference into the secret and the field after '#'
+func splitField(ref string) (string, string) {
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ref, ""
+}
+
+// pickField returns a field of a secret's key-value data. Without a field
+// name, data holding a single field yields that field.
+func pickField(data map[string]interface{}, field string) (string, error) {
+	if field == "" {
+		if len(data) != 1 {
+			return "", fmt.Errorf("secret has %d fields; name one after '#'", len(data))
+		}
+		for name := range data {
+			field = name
+		}
+	}
+	value, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secret has no field %q", field)
+	}
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode field %q: %w", field, err)
+		}
+		return string(encoded), nil
+	}
+}
+
+// jsonField returns a field of a secret stored as a JSON object, or the whole
+// secret when no field is named
+func jsonField(value, field string) (string, error) {
+	if field == "" {
+		return value, nil
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &data); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, so it has no field %q", field)
+	}
+	return pickField(data, field)
+}
@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProvider counts fetches and serves values with a fixed lease
+type fakeProvider struct {
+	mu      sync.Mutex
+	fetches int
+	lease   time.Duration
+	renew   func(ctx context.Context) (time.Duration, error)
+	err     error
+}
+
+func (p *fakeProvider) Fetch(_ context.Context, ref string) (Secret, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fetches++
+	if p.err != nil {
+		return Secret{}, p.err
+	}
+	return Secret{Value: "value-of-" + ref, TTL: p.lease, Renew: p.renew}, nil
+}
+
+func (p *fakeProvider) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetches
+}
+
+func TestResolverCaches(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := &fakeProvider{}
+	r := NewResolver()
+	r.now = func() time.Time { return now }
+	r.Register("fake", provider)
+
+	for i := 0; i < 3; i++ {
+		value, err := r.Resolve(context.Background(), "fake", "a")
+		if err != nil || value != "value-of-a" {
+			t.Fatalf("Resolve() = %q, %v", value, err)
+		}
+	}
+	if provider.count() != 1 {
+		t.Errorf("expected one fetch while cached, got %d", provider.count())
+	}
+
+	now = now.Add(defaultTTL + time.Second)
+	if _, err := r.Resolve(context.Background(), "fake", "a"); err != nil || provider.count() != 2 {
+		t.Errorf("expected an expired value to be fetched again, got %d fetches (%v)", provider.count(), err)
+	}
+
+	r.Flush()
+	if _, err := r.Resolve(context.Background(), "fake", "a"); err != nil || provider.count() != 3 {
+		t.Errorf("expected a flushed value to be fetched again, got %d fetches", provider.count())
+	}
+
+	provider.err = errors.New("boom")
+	if _, err := r.Resolve(context.Background(), "fake", "b"); err == nil {
+		t.Error("expected the fetch error")
+	}
+	if _, err := r.Resolve(context.Background(), "other", "a"); err == nil {
+		t.Error("expected an unknown scheme to fail")
+	}
+}
+
+func TestResolverRenewsLeases(t *testing.T) {
+	renewals := make(chan struct{}, 10)
+	fail := make(chan struct{})
+	provider := &fakeProvider{
+		lease: 30 * time.Millisecond,
+		renew: func(context.Context) (time.Duration, error) {
+			select {
+			case <-fail:
+				return 0, errors.New("lease expired")
+			default:
+			}
+			renewals <- struct{}{}
+			return 30 * time.Millisecond, nil
+		},
+	}
+	r := NewResolver()
+	r.Register("fake", provider)
+	defer r.Flush()
+
+	if _, err := r.Resolve(context.Background(), "fake", "db"); err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-renewals:
+		case <-time.After(time.Second):
+			t.Fatal("expected the lease to be renewed")
+		}
+	}
+	// Renewed leases keep the value cached past the original lease
+	if _, err := r.Resolve(context.Background(), "fake", "db"); err != nil || provider.count() != 1 {
+		t.Errorf("expected the renewed value from the cache, got %d fetches", provider.count())
+	}
+
+	// A failed renewal drops the value so the next load fetches a new one
+	close(fail)
+	deadline := time.Now().Add(time.Second)
+	for {
+		r.mu.Lock()
+		_, cached := r.cache["fake:db"]
+		r.mu.Unlock()
+		if !cached {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the value to be dropped after a failed renewal")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPickField(t *testing.T) {
+	data := map[string]interface{}{"token": "tok", "port": float64(5432), "tags": []interface{}{"a"}}
+	tests := []struct {
+		field   string
+		want    string
+		wantErr bool
+	}{
+		{field: "token", want: "tok"},
+		{field: "port", want: "5432"},
+		{field: "tags", want: `["a"]`},
+		{field: "missing", wantErr: true},
+		{field: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := pickField(data, tt.field)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("pickField(%q) = %q, %v", tt.field, got, err)
+		}
+	}
+	if got, err := pickField(map[string]interface{}{"only": "one"}, ""); err != nil || got != "one" {
+		t.Errorf("expected the only field without a name, got %q, %v", got, err)
+	}
+	if path, field := splitField("secret/data/app#token"); path != "secret/data/app" || field != "token" {
+		t.Errorf("splitField() = %q, %q", path, field)
+	}
+}
@@ -0,0 +1,129 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultVaultAddr is used when $VAULT_ADDR is not set, as the Vault CLI does
+const defaultVaultAddr = "https://127.0.0.1:8200"
+
+// VaultProvider reads secrets from HashiCorp Vault. References are API paths
+// with an optional field, such as secret/data/app#token for a KV v2 secret or
+// database/creds/app#password for dynamic credentials. The server and token
+// come from $VAULT_ADDR, $VAULT_TOKEN, and $VAULT_NAMESPACE.
+type VaultProvider struct {
+	Client *http.Client
+}
+
+// vaultResponse is the part of a Vault read response that is used
+type vaultResponse struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Errors        []string               `json:"errors"`
+}
+
+// Fetch reads a secret. KV v2 responses nest the fields under data.data.
+func (p *VaultProvider) Fetch(ctx context.Context, ref string) (Secret, error) {
+	path, field := splitField(ref)
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return Secret{}, fmt.Errorf("vault reference needs a path")
+	}
+
+	var resp vaultResponse
+	if err := p.call(ctx, http.MethodGet, "/v1/"+path, nil, &resp); err != nil {
+		return Secret{}, err
+	}
+	data := resp.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = nested
+		}
+	}
+	value, err := pickField(data, field)
+	if err != nil {
+		return Secret{}, err
+	}
+
+	secret := Secret{Value: value, TTL: time.Duration(resp.LeaseDuration) * time.Second}
+	if resp.Renewable && resp.LeaseID != "" {
+		leaseID := resp.LeaseID
+		secret.Renew = func(ctx context.Context) (time.Duration, error) {
+			return p.renew(ctx, leaseID)
+		}
+	}
+	return secret, nil
+}
+
+// renew extends a lease and returns its new duration
+func (p *VaultProvider) renew(ctx context.Context, leaseID string) (time.Duration, error) {
+	var resp vaultResponse
+	if err := p.call(ctx, http.MethodPut, "/v1/sys/leases/renew", map[string]string{"lease_id": leaseID}, &resp); err != nil {
+		return 0, err
+	}
+	return time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+// call sends a request to the Vault API
+func (p *VaultProvider) call(ctx context.Context, method, path string, body, out interface{}) error {
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		addr = defaultVaultAddr
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		return fmt.Errorf("$VAULT_TOKEN is not set")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(addr, "/")+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var decoded vaultResponse
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if json.Unmarshal(data, &decoded) == nil && len(decoded.Errors) > 0 {
+			return fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(decoded.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %s", resp.Status)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode vault response: %w", err)
+	}
+	return nil
+}
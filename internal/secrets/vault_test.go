@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVaultProviderFetch(t *testing.T) {
+	renewed := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.Header.Get("X-Vault-Namespace") != "team" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			w.Write([]byte(`{"lease_duration":0,"data":{"data":{"token":"tok-1","user":"app"},"metadata":{"version":3}}}`))
+		case "/v1/database/creds/app":
+			w.Write([]byte(`{"lease_id":"database/creds/app/abc","lease_duration":3600,"renewable":true,"data":{"password":"pw-1"}}`))
+		case "/v1/sys/leases/renew":
+			var body struct {
+				LeaseID string `json:"lease_id"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			renewed <- body.LeaseID
+			w.Write([]byte(`{"lease_id":"database/creds/app/abc","lease_duration":1800,"renewable":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("VAULT_NAMESPACE", "team")
+	provider := &VaultProvider{Client: server.Client()}
+
+	secret, err := provider.Fetch(context.Background(), "secret/data/app#token")
+	if err != nil || secret.Value != "tok-1" || secret.Renew != nil {
+		t.Errorf("KV v2 fetch = %+v, %v", secret, err)
+	}
+	if _, err := provider.Fetch(context.Background(), "secret/data/app"); err == nil || !strings.Contains(err.Error(), "2 fields") {
+		t.Errorf("expected a field to be required for a secret with several, got %v", err)
+	}
+
+	secret, err = provider.Fetch(context.Background(), "database/creds/app")
+	if err != nil || secret.Value != "pw-1" || secret.TTL != time.Hour || secret.Renew == nil {
+		t.Fatalf("dynamic secret fetch = %+v, %v", secret, err)
+	}
+	lease, err := secret.Renew(context.Background())
+	if err != nil || lease != 30*time.Minute {
+		t.Errorf("Renew() = %s, %v", lease, err)
+	}
+	if id := <-renewed; id != "database/creds/app/abc" {
+		t.Errorf("renewed lease %q", id)
+	}
+
+	if _, err := provider.Fetch(context.Background(), "secret/data/missing#token"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+	t.Setenv("VAULT_TOKEN", "wrong")
+	if _, err := provider.Fetch(context.Background(), "secret/data/app#token"); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("expected Vault's error message, got %v", err)
+	}
+}
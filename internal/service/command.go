@@ -0,0 +1,22 @@
+//go:build linux || darwin
+
+package service
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runCommand runs a service manager command, including its output in the
+// error when it fails
+func runCommand(name string, args ...string) error {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	if err != nil {
+		if text := strings.TrimSpace(string(output)); text != "" {
+			return fmt.Errorf("%s %s failed: %w: %s", name, strings.Join(args, " "), err, text)
+		}
+		return fmt.Errorf("%s %s failed: %w", name, strings.Join(args, " "), err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// handler reports the service's state to the service control manager and
+// passes on its stop requests
+type handler struct {
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Execute runs for the life of the service. It reports the service running,
+// closes stop on a stop or shutdown request, and reports the service
+// stopped once the server has shut down.
+func (h *handler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case request := <-requests:
+			switch request.Cmd {
+			case svc.Interrogate:
+				status <- request.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending, WaitHint: stopTimeout * 1000}
+				close(h.stop)
+				<-h.stopped
+				return false, 0
+			}
+		case <-h.stopped:
+			// The server stopped on its own
+			return false, 0
+		}
+	}
+}
+
+// Control connects to the service control manager when the process runs as a
+// Windows service. stop is closed when the manager asks the service to stop,
+// and stopped must be called once shutdown is complete. Outside a service,
+// stop is nil.
+func Control(name string) (stop <-chan struct{}, stopped func(), err error) {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to tell whether running as a service: %w", err)
+	}
+	if !isService {
+		return nil, func() {}, nil
+	}
+
+	h := &handler{stop: make(chan struct{}), stopped: make(chan struct{})}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = svc.Run(name, h)
+	}()
+	return h.stop, func() {
+		close(h.stopped)
+		<-done
+	}, nil
+}
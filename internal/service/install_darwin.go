@@ -0,0 +1,45 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// daemonDir is where installed launchd daemons are written
+const daemonDir = "/Library/LaunchDaemons"
+
+// Install writes a launchd daemon for the service and loads it, which starts
+// it. Installing again replaces the daemon and restarts it.
+func Install(def Definition) (string, error) {
+	plist, err := LaunchdPlist(def)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(daemonDir, def.Name+".plist")
+	if _, err := os.Stat(path); err == nil {
+		// Unload the old definition; it may not be loaded, so errors are ignored
+		_ = runCommand("launchctl", "bootout", "system/"+def.Name)
+	}
+	if err := os.WriteFile(path, []byte(plist), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, runCommand("launchctl", "bootstrap", "system", path)
+}
+
+// Uninstall unloads the service, which stops it, and removes its daemon
+func Uninstall(name string) (string, error) {
+	path := filepath.Join(daemonDir, name+".plist")
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return path, fmt.Errorf("service %s is not installed", name)
+	}
+	if err := runCommand("launchctl", "bootout", "system/"+name); err != nil {
+		return path, err
+	}
+	if err := os.Remove(path); err != nil {
+		return path, fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// unitDir is where installed systemd units are written
+const unitDir = "/etc/systemd/system"
+
+// Install writes a systemd unit for the service, then enables and starts it.
+// Installing again replaces the unit and restarts the service.
+func Install(def Definition) (string, error) {
+	unit, err := SystemdUnit(def)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(unitDir, def.Name+".service")
+	if err := os.WriteFile(path, []byte(unit), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := runCommand("systemctl", "daemon-reload"); err != nil {
+		return path, err
+	}
+	if err := runCommand("systemctl", "enable", def.Name); err != nil {
+		return path, err
+	}
+	return path, runCommand("systemctl", "restart", def.Name)
+}
+
+// Uninstall stops and disables the service and removes its unit
+func Uninstall(name string) (string, error) {
+	path := filepath.Join(unitDir, name+".service")
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return path, fmt.Errorf("service %s is not installed", name)
+	}
+	if err := runCommand("systemctl", "disable", "--now", name); err != nil {
+		return path, err
+	}
+	if err := os.Remove(path); err != nil {
+		return path, fmt.Errorf("failed to remove %s: %w", path, err)
+	}
+	return path, runCommand("systemctl", "daemon-reload")
+}
@@ -0,0 +1,18 @@
+//go:build !linux && !darwin && !windows
+
+package service
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// Install is not supported on this platform
+func Install(Definition) (string, error) {
+	return "", fmt.Errorf("installing a service is not supported on %s", runtime.GOOS)
+}
+
+// Uninstall is not supported on this platform
+func Uninstall(string) (string, error) {
+	return "", fmt.Errorf("uninstalling a service is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+// Install registers the service with the service control manager to start
+// automatically, restarting it when it fails, and starts it
+func Install(def Definition) (string, error) {
+	if err := def.Validate(); err != nil {
+		return "", err
+	}
+	m, err := mgr.Connect()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to the service control manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(def.Name); err == nil {
+		s.Close()
+		return "", fmt.Errorf("service %s is already installed; uninstall it first", def.Name)
+	}
+	config := mgr.Config{
+		DisplayName:      def.DisplayName,
+		Description:      def.Description,
+		StartType:        mgr.StartAutomatic,
+		DelayedAutoStart: true,
+	}
+	if def.User != "" {
+		config.ServiceStartName = def.User
+	}
+	s, err := m.CreateService(def.Name, def.Executable, config, def.Args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to create service %s: %w", def.Name, err)
+	}
+	defer s.Close()
+
+	restart := mgr.RecoveryAction{Type: mgr.ServiceRestart, Delay: 10 * time.Second}
+	if err := s.SetRecoveryActions([]mgr.RecoveryAction{restart, restart, restart}, uint32((24 * time.Hour).Seconds())); err != nil {
+		return def.Name, fmt.Errorf("failed to set recovery actions: %w", err)
+	}
+	if err := s.Start(); err != nil {
+		return def.Name, fmt.Errorf("failed to start service %s: %w", def.Name, err)
+	}
+	return def.Name, nil
+}
+
+// Uninstall stops the service and removes it from the service control manager
+func Uninstall(name string) (string, error) {
+	m, err := mgr.Connect()
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to the service control manager: %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(name)
+	if err != nil {
+		return name, fmt.Errorf("service %s is not installed", name)
+	}
+	defer s.Close()
+
+	if status, err := s.Query(); err == nil && status.State != svc.Stopped {
+		if _, err := s.Control(svc.Stop); err != nil {
+			return name, fmt.Errorf("failed to stop service %s: %w", name, err)
+		}
+		// Wait for the server to finish its graceful shutdown
+		deadline := time.Now().Add(stopTimeout * time.Second)
+		for status.State != svc.Stopped && time.Now().Before(deadline) {
+			time.Sleep(500 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+	if err := s.Delete(); err != nil {
+		return name, fmt.Errorf("failed to delete service %s: %w", name, err)
+	}
+	return name, nil
+}
@@ -0,0 +1,174 @@
+// Package service installs Hall Monitor as a managed background service:
+// a systemd unit on Linux, a launchd daemon on macOS, and a service
+// registered with the service control manager on Windows.
+package service
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// DefaultName is the service name used when none is given
+const DefaultName = "hallmonitor"
+
+// stopTimeout is how long the service manager waits for a graceful shutdown
+// before killing the process
+const stopTimeout = 30
+
+// namePattern limits service names to what every service manager accepts
+// as a unit name, launchd label, and file name
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Definition describes the service to install
+type Definition struct {
+	Name        string   // Service name, unit name, and launchd label
+	DisplayName string   // Human readable name shown by the service manager
+	Description string   // Longer description shown by the service manager
+	Executable  string   // Absolute path of the server binary
+	Args        []string // Arguments the service starts the binary with
+	WorkingDir  string   // Directory the service runs in, so relative paths in the config resolve
+	User        string   // Account the service runs as; empty for the manager's default
+}
+
+// Validate checks that the definition can be installed
+func (d Definition) Validate() error {
+	if !namePattern.MatchString(d.Name) {
+		return fmt.Errorf("invalid service name %q: use letters, digits, '.', '_', and '-'", d.Name)
+	}
+	if d.Executable == "" {
+		return fmt.Errorf("executable path is required")
+	}
+	return nil
+}
+
+// systemdTemplate is the unit written on Linux. systemd stops the service
+// with SIGTERM, which the server handles as a graceful shutdown.
+var systemdTemplate = template.Must(template.New("systemd").Funcs(template.FuncMap{
+	"command": systemdCommand,
+}).Parse(`# Generated by "hallmonitor -install-service"
+[Unit]
+Description={{.DisplayName}} - {{.Description}}
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart={{command .Executable .Args}}
+{{- if .WorkingDir}}
+WorkingDirectory={{.WorkingDir}}
+{{- end}}
+{{- if .User}}
+User={{.User}}
+# ICMP monitors need raw sockets; binding ports below 1024 needs the other
+AmbientCapabilities=CAP_NET_RAW CAP_NET_BIND_SERVICE
+{{- end}}
+Restart=on-failure
+RestartSec=10
+KillSignal=SIGTERM
+TimeoutStopSec={{.StopTimeout}}
+NoNewPrivileges=true
+PrivateTmp=true
+
+[Install]
+WantedBy=multi-user.target
+`))
+
+// launchdTemplate is the property list written on macOS. launchd stops the
+// daemon with SIGTERM and kills it after ExitTimeOut seconds.
+var launchdTemplate = template.Must(template.New("launchd").Funcs(template.FuncMap{
+	"xml": xmlEscape,
+}).Parse(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<!-- Generated by "hallmonitor -install-service" -->
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .Name}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>{{xml .Executable}}</string>
+{{- range .Args}}
+		<string>{{xml .}}</string>
+{{- end}}
+	</array>
+{{- if .WorkingDir}}
+	<key>WorkingDirectory</key>
+	<string>{{xml .WorkingDir}}</string>
+{{- end}}
+{{- if .User}}
+	<key>UserName</key>
+	<string>{{xml .User}}</string>
+{{- end}}
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>ExitTimeOut</key>
+	<integer>{{.StopTimeout}}</integer>
+	<key>StandardOutPath</key>
+	<string>/Library/Logs/{{xml .Name}}.log</string>
+	<key>StandardErrorPath</key>
+	<string>/Library/Logs/{{xml .Name}}.log</string>
+</dict>
+</plist>
+`))
+
+// templateData adds the fixed settings to a definition for the templates
+type templateData struct {
+	Definition
+	StopTimeout int
+}
+
+// SystemdUnit renders the systemd unit for a definition
+func SystemdUnit(def Definition) (string, error) {
+	return render(systemdTemplate, def)
+}
+
+// LaunchdPlist renders the launchd property list for a definition
+func LaunchdPlist(def Definition) (string, error) {
+	return render(launchdTemplate, def)
+}
+
+// render fills in a service template
+func render(tmpl *template.Template, def Definition) (string, error) {
+	if err := def.Validate(); err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(def.Description+def.DisplayName+def.Executable+strings.Join(def.Args, "")+def.WorkingDir+def.User, "\n\r") {
+		return "", fmt.Errorf("service settings must not contain line breaks")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, templateData{Definition: def, StopTimeout: stopTimeout}); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// systemdCommand joins a command line for ExecStart, quoting arguments that
+// systemd would otherwise split or expand
+func systemdCommand(executable string, args []string) string {
+	words := make([]string, 0, len(args)+1)
+	for _, word := range append([]string{executable}, args...) {
+		if word != "" && !strings.ContainsAny(word, " \t\"'\\$%;") {
+			words = append(words, word)
+			continue
+		}
+		word = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", "$$", "%", "%%").Replace(word)
+		words = append(words, `"`+word+`"`)
+	}
+	return strings.Join(words, " ")
+}
+
+// xmlEscape escapes text for a property list string
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func testDefinition() Definition {
+	return Definition{
+		Name:        "hallmonitor",
+		DisplayName: "Hall Monitor",
+		Description: "Network and service health monitoring",
+		Executable:  "/usr/local/bin/hallmonitor",
+		Args:        []string{"-config", "/etc/hall monitor/config.yml", "-profile", "prod"},
+		WorkingDir:  "/etc/hall monitor",
+	}
+}
+
+func TestSystemdUnit(t *testing.T) {
+	unit, err := SystemdUnit(testDefinition())
+	if err != nil {
+		t.Fatalf("SystemdUnit() error = %v", err)
+	}
+	for _, want := range []string{
+		`ExecStart=/usr/local/bin/hallmonitor -config "/etc/hall monitor/config.yml" -profile prod`,
+		"WorkingDirectory=/etc/hall monitor",
+		"KillSignal=SIGTERM",
+		"TimeoutStopSec=30",
+		"WantedBy=multi-user.target",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit is missing %q:\n%s", want, unit)
+		}
+	}
+	if strings.Contains(unit, "User=") {
+		t.Error("expected no User= without a service user")
+	}
+
+	def := testDefinition()
+	def.User = "hallmonitor"
+	unit, err = SystemdUnit(def)
+	if err != nil {
+		t.Fatalf("SystemdUnit() error = %v", err)
+	}
+	if !strings.Contains(unit, "User=hallmonitor\n") || !strings.Contains(unit, "CAP_NET_RAW") {
+		t.Errorf("expected the user and raw socket capability:\n%s", unit)
+	}
+}
+
+func TestSystemdCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"-config", "config.yml"}, want: "/bin/hm -config config.yml"},
+		{args: []string{"-profile", ""}, want: `/bin/hm -profile ""`},
+		{args: []string{`a "b"`}, want: `/bin/hm "a \"b\""`},
+		{args: []string{"$HOME/100%"}, want: `/bin/hm "$$HOME/100%%"`},
+	}
+	for _, tt := range tests {
+		if got := systemdCommand("/bin/hm", tt.args); got != tt.want {
+			t.Errorf("systemdCommand(%q) = %s, want %s", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	def := testDefinition()
+	def.Args = append(def.Args, "-x", "<&>")
+	def.User = "_hallmonitor"
+	plist, err := LaunchdPlist(def)
+	if err != nil {
+		t.Fatalf("LaunchdPlist() error = %v", err)
+	}
+	for _, want := range []string{
+		"<key>Label</key>\n\t<string>hallmonitor</string>",
+		"<string>/usr/local/bin/hallmonitor</string>\n\t\t<string>-config</string>\n\t\t<string>/etc/hall monitor/config.yml</string>",
+		"<string>&lt;&amp;&gt;</string>",
+		"<key>UserName</key>\n\t<string>_hallmonitor</string>",
+		"<key>ExitTimeOut</key>\n\t<integer>30</integer>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("plist is missing %q:\n%s", want, plist)
+		}
+	}
+}
+
+func TestDefinitionValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Definition)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(*Definition) {}},
+		{name: "dotted name", modify: func(d *Definition) { d.Name = "com.example.hallmonitor" }},
+		{name: "path in name", modify: func(d *Definition) { d.Name = "../evil" }, wantErr: true},
+		{name: "space in name", modify: func(d *Definition) { d.Name = "hall monitor" }, wantErr: true},
+		{name: "no executable", modify: func(d *Definition) { d.Executable = "" }, wantErr: true},
+		{name: "line break", modify: func(d *Definition) { d.User = "root\nExecStartPre=/bin/sh" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			def := testDefinition()
+			tt.modify(&def)
+			_, err := SystemdUnit(def)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SystemdUnit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
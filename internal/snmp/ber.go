@@ -0,0 +1,158 @@
+package snmp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BER tags used by SNMP messages
+const (
+	tagInteger     byte = 0x02
+	tagOctetString byte = 0x04
+	tagNull        byte = 0x05
+	tagOID         byte = 0x06
+	tagSequence    byte = 0x30
+	tagGauge32     byte = 0x42
+	tagTimeTicks   byte = 0x43
+	tagTrapV2      byte = 0xA7
+)
+
+// encodeTLV wraps content in a tag and BER length
+func encodeTLV(tag byte, content []byte) []byte {
+	buf := make([]byte, 0, len(content)+6)
+	buf = append(buf, tag)
+	buf = appendLength(buf, len(content))
+	return append(buf, content...)
+}
+
+// appendLength encodes a BER definite length
+func appendLength(buf []byte, length int) []byte {
+	if length < 0x80 {
+		return append(buf, byte(length))
+	}
+
+	var octets []byte
+	for n := length; n > 0; n >>= 8 {
+		octets = append([]byte{byte(n)}, octets...)
+	}
+	buf = append(buf, 0x80|byte(len(octets)))
+	return append(buf, octets...)
+}
+
+// encodeSequence concatenates items into a SEQUENCE
+func encodeSequence(items ...[]byte) []byte {
+	return encodeTLV(tagSequence, concat(items...))
+}
+
+// encodeInteger encodes a signed INTEGER using minimal two's complement
+func encodeInteger(v int64) []byte {
+	content := []byte{byte(v)}
+	for v > 0x7F || v < -0x80 {
+		v >>= 8
+		content = append([]byte{byte(v)}, content...)
+	}
+	return encodeTLV(tagInteger, content)
+}
+
+// encodeUnsigned encodes an unsigned 32-bit application type (Gauge32, TimeTicks)
+func encodeUnsigned(tag byte, v uint32) []byte {
+	content := []byte{byte(v)}
+	for n := v >> 8; n > 0; n >>= 8 {
+		content = append([]byte{byte(n)}, content...)
+	}
+	if content[0]&0x80 != 0 {
+		content = append([]byte{0}, content...)
+	}
+	return encodeTLV(tag, content)
+}
+
+// encodeOctetString encodes an OCTET STRING
+func encodeOctetString(b []byte) []byte {
+	return encodeTLV(tagOctetString, b)
+}
+
+// encodeNull encodes a NULL
+func encodeNull() []byte {
+	return []byte{tagNull, 0}
+}
+
+// encodeOID encodes an OBJECT IDENTIFIER
+func encodeOID(oid OID) []byte {
+	if len(oid) < 2 {
+		return encodeTLV(tagOID, nil)
+	}
+
+	content := appendBase128(nil, oid[0]*40+oid[1])
+	for _, arc := range oid[2:] {
+		content = appendBase128(content, arc)
+	}
+	return encodeTLV(tagOID, content)
+}
+
+// appendBase128 encodes an OID arc as big-endian base-128 with continuation bits
+func appendBase128(buf []byte, v uint32) []byte {
+	var tmp [5]byte
+	i := len(tmp) - 1
+	tmp[i] = byte(v & 0x7F)
+	for v >>= 7; v > 0; v >>= 7 {
+		i--
+		tmp[i] = byte(v&0x7F) | 0x80
+	}
+	return append(buf, tmp[i:]...)
+}
+
+// concat joins byte slices
+func concat(items ...[]byte) []byte {
+	size := 0
+	for _, item := range items {
+		size += len(item)
+	}
+	buf := make([]byte, 0, size)
+	for _, item := range items {
+		buf = append(buf, item...)
+	}
+	return buf
+}
+
+// OID is an SNMP object identifier
+type OID []uint32
+
+// ParseOID parses a dotted OID such as 1.3.6.1.4.1
+func ParseOID(s string) (OID, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), ".")
+	if s == "" {
+		return nil, fmt.Errorf("empty OID")
+	}
+
+	parts := strings.Split(s, ".")
+	oid := make(OID, 0, len(parts))
+	for _, part := range parts {
+		arc, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q: %w", s, err)
+		}
+		oid = append(oid, uint32(arc))
+	}
+
+	if len(oid) < 2 || oid[0] > 2 || (oid[0] < 2 && oid[1] >= 40) {
+		return nil, fmt.Errorf("invalid OID %q", s)
+	}
+	return oid, nil
+}
+
+// Append returns a new OID with arcs appended
+func (o OID) Append(arcs ...uint32) OID {
+	out := make(OID, 0, len(o)+len(arcs))
+	out = append(out, o...)
+	return append(out, arcs...)
+}
+
+// String returns the dotted representation
+func (o OID) String() string {
+	parts := make([]string, len(o))
+	for i, arc := range o {
+		parts[i] = strconv.FormatUint(uint64(arc), 10)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,93 @@
+package snmp
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncodeInteger(t *testing.T) {
+	tests := []struct {
+		value int64
+		want  []byte
+	}{
+		{0, []byte{0x02, 0x01, 0x00}},
+		{127, []byte{0x02, 0x01, 0x7F}},
+		{128, []byte{0x02, 0x02, 0x00, 0x80}},
+		{256, []byte{0x02, 0x02, 0x01, 0x00}},
+		{-1, []byte{0x02, 0x01, 0xFF}},
+		{-129, []byte{0x02, 0x02, 0xFF, 0x7F}},
+	}
+
+	for _, tt := range tests {
+		if got := encodeInteger(tt.value); !bytes.Equal(got, tt.want) {
+			t.Errorf("encodeInteger(%d) = % x, want % x", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeUnsigned(t *testing.T) {
+	got := encodeUnsigned(tagTimeTicks, 0xFFFFFFFF)
+	want := []byte{0x43, 0x05, 0x00, 0xFF, 0xFF, 0xFF, 0xFF}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeUnsigned() = % x, want % x", got, want)
+	}
+}
+
+func TestEncodeOID(t *testing.T) {
+	got := encodeOID(oidSysUpTime)
+	want := []byte{0x06, 0x08, 0x2B, 0x06, 0x01, 0x02, 0x01, 0x01, 0x03, 0x00}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeOID(sysUpTime) = % x, want % x", got, want)
+	}
+
+	// Arcs above 127 use base-128 continuation bytes
+	got = encodeOID(OID{1, 3, 6, 1, 4, 1, 99999})
+	want = []byte{0x06, 0x08, 0x2B, 0x06, 0x01, 0x04, 0x01, 0x86, 0x8D, 0x1F}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeOID(enterprise) = % x, want % x", got, want)
+	}
+}
+
+func TestAppendLengthLongForm(t *testing.T) {
+	tests := []struct {
+		length int
+		want   []byte
+	}{
+		{127, []byte{0x7F}},
+		{128, []byte{0x81, 0x80}},
+		{300, []byte{0x82, 0x01, 0x2C}},
+	}
+
+	for _, tt := range tests {
+		if got := appendLength(nil, tt.length); !bytes.Equal(got, tt.want) {
+			t.Errorf("appendLength(%d) = % x, want % x", tt.length, got, tt.want)
+		}
+	}
+}
+
+func TestParseOID(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "1.3.6.1.4.1.99999", want: "1.3.6.1.4.1.99999"},
+		{input: ".1.3.6.1", want: "1.3.6.1"},
+		{input: "", wantErr: true},
+		{input: "1.3.x", wantErr: true},
+		{input: "3.1", wantErr: true},
+		{input: "1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			oid, err := ParseOID(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && oid.String() != tt.want {
+				t.Errorf("ParseOID() = %s, want %s", oid, tt.want)
+			}
+		})
+	}
+}
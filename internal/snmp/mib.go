@@ -0,0 +1,197 @@
+package snmp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Arcs below the enterprise OID
+const (
+	notificationsArc uint32 = 0
+	objectsArc       uint32 = 1
+
+	monitorDownArc uint32 = 1
+	monitorUpArc   uint32 = 2
+)
+
+// trapObject describes a monitor attribute sent with every trap. The MIB is
+// generated from this table so the definition always matches what is sent.
+type trapObject struct {
+	name        string
+	arc         uint32
+	syntax      string
+	description string
+	value       func(result *models.MonitorResult) []byte
+}
+
+// trapObjects lists the varbinds appended to every trap, in order
+var trapObjects = []trapObject{
+	{
+		name:        "hmMonitorName",
+		arc:         1,
+		syntax:      "DisplayString",
+		description: "The name of the monitor.",
+		value:       func(r *models.MonitorResult) []byte { return displayString(r.Monitor) },
+	},
+	{
+		name:        "hmMonitorGroup",
+		arc:         2,
+		syntax:      "DisplayString",
+		description: "The group the monitor belongs to.",
+		value:       func(r *models.MonitorResult) []byte { return displayString(r.Group) },
+	},
+	{
+		name:        "hmMonitorType",
+		arc:         3,
+		syntax:      "DisplayString",
+		description: "The monitor type: http, tcp, ping or dns.",
+		value:       func(r *models.MonitorResult) []byte { return displayString(string(r.Type)) },
+	},
+	{
+		name:        "hmMonitorStatus",
+		arc:         4,
+		syntax:      "DisplayString",
+		description: "The monitor status after the transition: up or down.",
+		value:       func(r *models.MonitorResult) []byte { return displayString(string(r.Status)) },
+	},
+	{
+		name:        "hmMonitorError",
+		arc:         5,
+		syntax:      "DisplayString",
+		description: "The error reported by the failing check, empty on recovery.",
+		value:       func(r *models.MonitorResult) []byte { return displayString(r.Error) },
+	},
+	{
+		name:        "hmMonitorLatency",
+		arc:         6,
+		syntax:      "Gauge32",
+		description: "The duration of the check in milliseconds.",
+		value: func(r *models.MonitorResult) []byte {
+			return encodeUnsigned(tagGauge32, uint32(r.Duration.Milliseconds()))
+		},
+	},
+	{
+		name:        "hmMonitorTimestamp",
+		arc:         7,
+		syntax:      "DisplayString",
+		description: "The time of the check in RFC 3339 format.",
+		value: func(r *models.MonitorResult) []byte {
+			if r.Timestamp.IsZero() {
+				return displayString("")
+			}
+			return displayString(r.Timestamp.UTC().Format("2006-01-02T15:04:05Z07:00"))
+		},
+	},
+	{
+		name:        "hmMonitorSeverity",
+		arc:         8,
+		syntax:      "DisplayString",
+		description: "critical for an outage, warning for one starting in downgrading quiet hours, info on recovery.",
+		value:       func(r *models.MonitorResult) []byte { return displayString(trapSeverity(r)) },
+	},
+}
+
+// trapSeverity ranks a transition for receivers that route on severity
+func trapSeverity(r *models.MonitorResult) string {
+	switch {
+	case r.Status != models.StatusDown:
+		return "info"
+	case r.Quiet == models.QuietDowngrade:
+		return "warning"
+	default:
+		return "critical"
+	}
+}
+
+// displayString encodes a DisplayString, truncated to its 255 octet limit
+func displayString(s string) []byte {
+	if len(s) > 255 {
+		s = s[:255]
+	}
+	return encodeOctetString([]byte(s))
+}
+
+// GenerateMIB renders the HALLMONITOR-MIB definition rooted at enterprise
+func GenerateMIB(enterprise OID) string {
+	var b strings.Builder
+
+	b.WriteString("HALLMONITOR-MIB DEFINITIONS ::= BEGIN\n\n")
+	b.WriteString("IMPORTS\n")
+	b.WriteString("    MODULE-IDENTITY, OBJECT-TYPE, NOTIFICATION-TYPE,\n")
+	b.WriteString("    Gauge32, enterprises                        FROM SNMPv2-SMI\n")
+	b.WriteString("    DisplayString                               FROM SNMPv2-TC\n")
+	b.WriteString("    OBJECT-GROUP, NOTIFICATION-GROUP            FROM SNMPv2-CONF;\n\n")
+
+	b.WriteString("hallMonitorMIB MODULE-IDENTITY\n")
+	b.WriteString("    LAST-UPDATED \"202610150000Z\"\n")
+	b.WriteString("    ORGANIZATION \"Hall Monitor\"\n")
+	b.WriteString("    CONTACT-INFO \"https://github.com/1broseidon/hallmonitor\"\n")
+	b.WriteString("    DESCRIPTION  \"Notifications sent by Hall Monitor when monitors go down or recover.\"\n")
+	b.WriteString("    REVISION     \"202610150000Z\"\n")
+	b.WriteString("    DESCRIPTION  \"Initial version.\"\n")
+	fmt.Fprintf(&b, "    ::= { %s }\n\n", parentNotation(enterprise))
+
+	fmt.Fprintf(&b, "hmNotifications OBJECT IDENTIFIER ::= { hallMonitorMIB %d }\n", notificationsArc)
+	fmt.Fprintf(&b, "hmObjects       OBJECT IDENTIFIER ::= { hallMonitorMIB %d }\n", objectsArc)
+	b.WriteString("hmConformance   OBJECT IDENTIFIER ::= { hallMonitorMIB 2 }\n\n")
+
+	names := make([]string, 0, len(trapObjects))
+	for _, object := range trapObjects {
+		names = append(names, object.name)
+		fmt.Fprintf(&b, "%s OBJECT-TYPE\n", object.name)
+		fmt.Fprintf(&b, "    SYNTAX      %s\n", object.syntax)
+		b.WriteString("    MAX-ACCESS  accessible-for-notify\n")
+		b.WriteString("    STATUS      current\n")
+		fmt.Fprintf(&b, "    DESCRIPTION \"%s\"\n", object.description)
+		fmt.Fprintf(&b, "    ::= { hmObjects %d }\n\n", object.arc)
+	}
+	objects := strings.Join(names, ",\n                  ")
+
+	notifications := []struct {
+		name        string
+		arc         uint32
+		description string
+	}{
+		{"hmMonitorDown", monitorDownArc, "Sent when a monitor transitions to down."},
+		{"hmMonitorUp", monitorUpArc, "Sent when a monitor recovers from down."},
+	}
+	for _, n := range notifications {
+		fmt.Fprintf(&b, "%s NOTIFICATION-TYPE\n", n.name)
+		fmt.Fprintf(&b, "    OBJECTS     { %s }\n", objects)
+		b.WriteString("    STATUS      current\n")
+		fmt.Fprintf(&b, "    DESCRIPTION \"%s\"\n", n.description)
+		fmt.Fprintf(&b, "    ::= { hmNotifications %d }\n\n", n.arc)
+	}
+
+	b.WriteString("hmObjectGroup OBJECT-GROUP\n")
+	fmt.Fprintf(&b, "    OBJECTS     { %s }\n", objects)
+	b.WriteString("    STATUS      current\n")
+	b.WriteString("    DESCRIPTION \"Monitor attributes carried in notifications.\"\n")
+	b.WriteString("    ::= { hmConformance 1 }\n\n")
+
+	b.WriteString("hmNotificationGroup NOTIFICATION-GROUP\n")
+	b.WriteString("    NOTIFICATIONS { hmMonitorDown, hmMonitorUp }\n")
+	b.WriteString("    STATUS      current\n")
+	b.WriteString("    DESCRIPTION \"Monitor state change notifications.\"\n")
+	b.WriteString("    ::= { hmConformance 2 }\n\n")
+
+	b.WriteString("END\n")
+	return b.String()
+}
+
+// parentNotation expresses the enterprise OID relative to enterprises when possible
+func parentNotation(oid OID) string {
+	enterprises := OID{1, 3, 6, 1, 4, 1}
+	parent, arcs := "iso", oid[1:]
+	if len(oid) > len(enterprises) && oid[:len(enterprises)].String() == enterprises.String() {
+		parent, arcs = "enterprises", oid[len(enterprises):]
+	}
+
+	parts := []string{parent}
+	for _, arc := range arcs {
+		parts = append(parts, fmt.Sprintf("%d", arc))
+	}
+	return strings.Join(parts, " ")
+}
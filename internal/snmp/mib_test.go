@@ -0,0 +1,45 @@
+package snmp
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMIBMatchesBundledDefinition(t *testing.T) {
+	enterprise, err := ParseOID(DefaultEnterpriseOID)
+	if err != nil {
+		t.Fatalf("invalid default OID: %v", err)
+	}
+
+	bundled, err := os.ReadFile("../../deploy/observability/snmp/HALLMONITOR-MIB.txt")
+	if err != nil {
+		t.Fatalf("failed to read bundled MIB: %v", err)
+	}
+
+	if string(bundled) != GenerateMIB(enterprise) {
+		t.Error("bundled MIB is out of date; regenerate with: hallmonitor -print-mib > deploy/observability/snmp/HALLMONITOR-MIB.txt")
+	}
+}
+
+func TestGenerateMIBIncludesTrapObjects(t *testing.T) {
+	mib := GenerateMIB(OID{1, 3, 6, 1, 4, 1, 12345, 7})
+
+	if !strings.Contains(mib, "::= { enterprises 12345 7 }") {
+		t.Error("expected module identity under enterprises")
+	}
+	for _, object := range trapObjects {
+		if !strings.Contains(mib, object.name+" OBJECT-TYPE") {
+			t.Errorf("expected %s in MIB", object.name)
+		}
+	}
+	if !strings.Contains(mib, "hmMonitorDown NOTIFICATION-TYPE") || !strings.Contains(mib, "hmMonitorUp NOTIFICATION-TYPE") {
+		t.Error("expected notification definitions")
+	}
+}
+
+func TestParentNotationOutsideEnterprises(t *testing.T) {
+	if got := parentNotation(OID{1, 3, 6, 1, 3, 42}); got != "iso 3 6 1 3 42" {
+		t.Errorf("parentNotation() = %s", got)
+	}
+}
@@ -0,0 +1,376 @@
+// Package snmp sends SNMPv2c and SNMPv3 traps when monitors go down or recover
+// and generates the matching MIB definition.
+package snmp
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// DefaultEnterpriseOID roots the MIB when none is configured. Sites with their
+// own Private Enterprise Number should set snmp.enterpriseOid.
+const DefaultEnterpriseOID = "1.3.6.1.4.1.99999"
+
+const (
+	defaultTrapPort  = "162"
+	defaultCommunity = "public"
+	sendTimeout      = 5 * time.Second
+	maxMessageSize   = 65507
+
+	// maxEngineBoots is where RFC 3414 has snmpEngineBoots stop counting
+	maxEngineBoots = 2147483647
+	// engineBootsMetaKey prefixes the metadata key counting an engine ID's boots
+	engineBootsMetaKey = "snmp:engine_boots:"
+)
+
+// Standard OIDs carried by every SNMPv2 trap
+var (
+	oidSysUpTime   = OID{1, 3, 6, 1, 2, 1, 1, 3, 0}
+	oidSnmpTrapOID = OID{1, 3, 6, 1, 6, 3, 1, 1, 4, 1, 0}
+)
+
+// TrapSender emits a monitorDown trap when a monitor goes down and a
+// monitorUp trap when it recovers
+type TrapSender struct {
+	enterprise OID
+	engineID   []byte
+	targets    []*trapTarget
+	logger     *logging.Logger
+	started    time.Time
+
+	// engineBoots counts restarts of a configured engine ID; a generated one
+	// is new on every start, so it always boots for the first time
+	engineBoots       int64
+	generatedEngineID bool
+
+	requestID  atomic.Int32
+	lastStatus map[string]models.MonitorStatus
+	mu         sync.Mutex
+}
+
+// trapTarget is a resolved trap receiver
+type trapTarget struct {
+	address   string
+	version   string
+	community string
+	usm       *usm
+}
+
+// NewTrapSender creates a trap sender from configuration
+func NewTrapSender(cfg config.SNMPConfig, logger *logging.Logger) (*TrapSender, error) {
+	enterpriseOID := cfg.EnterpriseOID
+	if enterpriseOID == "" {
+		enterpriseOID = DefaultEnterpriseOID
+	}
+	enterprise, err := ParseOID(enterpriseOID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snmp.enterpriseOid: %w", err)
+	}
+
+	engineID, err := resolveEngineID(cfg.EngineID)
+	if err != nil {
+		return nil, err
+	}
+
+	sender := &TrapSender{
+		enterprise:        enterprise,
+		engineID:          engineID,
+		logger:            logger,
+		started:           time.Now(),
+		engineBoots:       1,
+		generatedEngineID: cfg.EngineID == "",
+		lastStatus:        make(map[string]models.MonitorStatus),
+	}
+
+	for i, targetCfg := range cfg.Targets {
+		target, err := newTrapTarget(targetCfg, engineID)
+		if err != nil {
+			return nil, fmt.Errorf("snmp.targets[%d]: %w", i, err)
+		}
+		sender.targets = append(sender.targets, target)
+	}
+
+	return sender, nil
+}
+
+// newTrapTarget resolves defaults and v3 keys for a receiver
+func newTrapTarget(cfg config.SNMPTargetConfig, engineID []byte) (*trapTarget, error) {
+	address := cfg.Address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, defaultTrapPort)
+	}
+
+	target := &trapTarget{
+		address: address,
+		version: strings.ToLower(cfg.Version),
+	}
+
+	switch target.version {
+	case "", "v2c":
+		target.version = "v2c"
+		target.community = cfg.Community
+		if target.community == "" {
+			target.community = defaultCommunity
+		}
+	case "v3":
+		u, err := newUSM(cfg.User, cfg.AuthProtocol, cfg.AuthPassword, cfg.PrivProtocol, cfg.PrivPassword, engineID)
+		if err != nil {
+			return nil, err
+		}
+		target.usm = u
+	default:
+		return nil, fmt.Errorf("unsupported SNMP version: %s", cfg.Version)
+	}
+
+	return target, nil
+}
+
+// resolveEngineID decodes a configured hex engine ID or generates a random one
+func resolveEngineID(configured string) ([]byte, error) {
+	if configured != "" {
+		engineID, err := hex.DecodeString(strings.TrimPrefix(configured, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid snmp.engineId: %w", err)
+		}
+		if len(engineID) < 5 || len(engineID) > 32 {
+			return nil, fmt.Errorf("snmp.engineId must be 5 to 32 bytes")
+		}
+		return engineID, nil
+	}
+
+	// RFC 3411 format: enterprise with high bit set, format 5 (octets), random bytes
+	engineID := []byte{0x80, 0x00, 0x00, 0x00, 0x05}
+	random := make([]byte, 8)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("failed to generate engine ID: %w", err)
+	}
+	return append(engineID, random...), nil
+}
+
+// EngineID returns the hex engine ID receivers need for SNMPv3 users
+func (s *TrapSender) EngineID() string {
+	return hex.EncodeToString(s.engineID)
+}
+
+// BootStore keeps the engine boot count across restarts. Storage backends
+// with metadata implement it.
+type BootStore interface {
+	GetMetadata(key string) ([]byte, error)
+	SetMetadata(key string, value []byte) error
+}
+
+// RecordBoot counts this start of a configured engine ID in store and sends
+// the new count with SNMPv3 traps. Engine time restarts at zero on every
+// start, so without a higher boot count receivers that check timeliness
+// would reject traps after a restart as replays. It must be called before
+// traps are sent.
+func (s *TrapSender) RecordBoot(store BootStore) error {
+	if s.generatedEngineID {
+		return nil
+	}
+
+	key := engineBootsMetaKey + s.EngineID()
+	data, err := store.GetMetadata(key)
+	if err != nil {
+		return fmt.Errorf("failed to read SNMP engine boots: %w", err)
+	}
+	var boots int64
+	if data != nil {
+		if boots, err = strconv.ParseInt(string(data), 10, 64); err != nil {
+			return fmt.Errorf("invalid SNMP engine boots %q: %w", data, err)
+		}
+	}
+	if boots < maxEngineBoots {
+		boots++
+	}
+
+	if err := store.SetMetadata(key, []byte(strconv.FormatInt(boots, 10))); err != nil {
+		return fmt.Errorf("failed to store SNMP engine boots: %w", err)
+	}
+	s.engineBoots = boots
+	return nil
+}
+
+// EngineBoots returns the boot count sent with SNMPv3 traps
+func (s *TrapSender) EngineBoots() int64 {
+	return s.engineBoots
+}
+
+// HandleResult sends a trap when a monitor transitions to or from down,
+// unless quiet hours suppress it
+func (s *TrapSender) HandleResult(result *models.MonitorResult) {
+	if result == nil || result.Status == models.StatusUnknown {
+		return
+	}
+
+	s.mu.Lock()
+	previous, seen := s.lastStatus[result.Monitor]
+	s.lastStatus[result.Monitor] = result.Status
+	s.mu.Unlock()
+
+	var notification OID
+	switch {
+	case result.Status == models.StatusDown && previous != models.StatusDown:
+		notification = s.enterprise.Append(notificationsArc, monitorDownArc)
+	case result.Status == models.StatusUp && seen && previous == models.StatusDown:
+		notification = s.enterprise.Append(notificationsArc, monitorUpArc)
+	default:
+		return
+	}
+
+	// The transition is still recorded so recovery after quiet hours is sent
+	if result.Quiet == models.QuietSuppress {
+		s.logger.WithComponent(logging.ComponentSNMP).
+			WithFields(map[string]interface{}{
+				"monitor": result.Monitor,
+				"status":  string(result.Status),
+			}).
+			Debug("SNMP trap suppressed by quiet hours")
+		return
+	}
+
+	if err := s.Send(notification, result); err != nil {
+		s.logger.WithComponent(logging.ComponentSNMP).
+			WithError(err).
+			WithFields(map[string]interface{}{
+				"monitor": result.Monitor,
+				"status":  string(result.Status),
+			}).
+			Warn("Failed to send SNMP trap")
+	}
+}
+
+// Send delivers a trap to every configured target
+func (s *TrapSender) Send(notification OID, result *models.MonitorResult) error {
+	pdu := s.trapPDU(notification, result)
+
+	var errs []string
+	for _, target := range s.targets {
+		message, err := s.encodeMessage(target, pdu)
+		if err == nil {
+			err = sendUDP(target.address, message)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", target.address, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("trap delivery failed: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// trapPDU builds an SNMPv2-Trap-PDU with the monitor's metadata as varbinds
+func (s *TrapSender) trapPDU(notification OID, result *models.MonitorResult) []byte {
+	uptime := uint32(time.Since(s.started) / (10 * time.Millisecond))
+
+	varbinds := [][]byte{
+		encodeSequence(encodeOID(oidSysUpTime), encodeUnsigned(tagTimeTicks, uptime)),
+		encodeSequence(encodeOID(oidSnmpTrapOID), encodeOID(notification)),
+	}
+	for _, object := range trapObjects {
+		oid := s.enterprise.Append(objectsArc, object.arc, 0)
+		varbinds = append(varbinds, encodeSequence(encodeOID(oid), object.value(result)))
+	}
+
+	return encodeTLV(tagTrapV2, concat(
+		encodeInteger(int64(s.requestID.Add(1))),
+		encodeInteger(0), // error-status
+		encodeInteger(0), // error-index
+		encodeSequence(varbinds...),
+	))
+}
+
+// encodeMessage wraps a PDU for the target's SNMP version
+func (s *TrapSender) encodeMessage(target *trapTarget, pdu []byte) ([]byte, error) {
+	if target.usm == nil {
+		return encodeSequence(
+			encodeInteger(1), // SNMPv2c
+			encodeOctetString([]byte(target.community)),
+			pdu,
+		), nil
+	}
+	return s.encodeV3Message(target.usm, pdu)
+}
+
+// encodeV3Message builds an SNMPv3 message secured with USM
+func (s *TrapSender) encodeV3Message(u *usm, pdu []byte) ([]byte, error) {
+	// Traps are sent by the authoritative engine, so boots and time are our own
+	engineBoots := s.engineBoots
+	engineTime := uint32(time.Since(s.started) / time.Second)
+
+	scopedPDU := encodeSequence(
+		encodeOctetString(s.engineID),
+		encodeOctetString(nil), // context name
+		pdu,
+	)
+
+	msgData := scopedPDU
+	var privParams []byte
+	if u.privKey != nil {
+		ciphertext, salt, err := u.encrypt(scopedPDU, uint32(engineBoots), engineTime)
+		if err != nil {
+			return nil, err
+		}
+		msgData = encodeOctetString(ciphertext)
+		privParams = salt
+	}
+
+	msgID := int64(s.requestID.Add(1))
+	build := func(authParams []byte) []byte {
+		securityParams := encodeSequence(
+			encodeOctetString(s.engineID),
+			encodeInteger(engineBoots),
+			encodeInteger(int64(engineTime)),
+			encodeOctetString([]byte(u.user)),
+			encodeOctetString(authParams),
+			encodeOctetString(privParams),
+		)
+		return encodeSequence(
+			encodeInteger(3), // SNMPv3
+			encodeSequence(
+				encodeInteger(msgID),
+				encodeInteger(maxMessageSize),
+				encodeOctetString([]byte{u.flags()}),
+				encodeInteger(usmSecurityModel),
+			),
+			encodeOctetString(securityParams),
+			msgData,
+		)
+	}
+
+	if u.authKey == nil {
+		return build(nil), nil
+	}
+
+	// The HMAC covers the whole message with zeroed auth parameters; the real
+	// parameters have the same length so the rebuilt message differs only there
+	return build(u.authenticate(build(make([]byte, authParamsLength)))), nil
+}
+
+// sendUDP writes a single datagram to address
+func sendUDP(address string, message []byte) error {
+	conn, err := net.DialTimeout("udp", address, sendTimeout)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(sendTimeout)); err != nil {
+		return err
+	}
+	_, err = conn.Write(message)
+	return err
+}
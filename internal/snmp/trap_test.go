@@ -0,0 +1,269 @@
+package snmp
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func testLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	return logger
+}
+
+// listenUDP starts a trap receiver and returns its address and a receive func
+func listenUDP(t *testing.T) (string, func() []byte) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	receive := func() []byte {
+		buf := make([]byte, maxMessageSize)
+		conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil
+		}
+		return buf[:n]
+	}
+	return conn.LocalAddr().String(), receive
+}
+
+func TestTrapSenderSendsOnTransitions(t *testing.T) {
+	address, receive := listenUDP(t)
+
+	sender, err := NewTrapSender(config.SNMPConfig{
+		Targets: []config.SNMPTargetConfig{{Address: address, Community: "secret"}},
+	}, testLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create sender: %v", err)
+	}
+
+	result := func(status models.MonitorStatus) *models.MonitorResult {
+		return &models.MonitorResult{
+			Monitor:   "api",
+			Group:     "core",
+			Type:      models.MonitorTypeHTTP,
+			Status:    status,
+			Error:     "timeout",
+			Timestamp: time.Now(),
+		}
+	}
+
+	enterprise := OID{1, 3, 6, 1, 4, 1, 99999}
+	downOID := encodeOID(enterprise.Append(notificationsArc, monitorDownArc))
+	upOID := encodeOID(enterprise.Append(notificationsArc, monitorUpArc))
+
+	// First up result is not a transition
+	sender.HandleResult(result(models.StatusUp))
+	if packet := receive(); packet != nil {
+		t.Fatalf("expected no trap for initial up status")
+	}
+
+	sender.HandleResult(result(models.StatusDown))
+	packet := receive()
+	if packet == nil {
+		t.Fatal("expected trap for down transition")
+	}
+	if !bytes.Contains(packet, encodeOctetString([]byte("secret"))) {
+		t.Error("expected community in trap")
+	}
+	if !bytes.Contains(packet, downOID) {
+		t.Error("expected monitorDown notification OID")
+	}
+	if !bytes.Contains(packet, encodeOctetString([]byte("api"))) || !bytes.Contains(packet, encodeOctetString([]byte("timeout"))) {
+		t.Error("expected monitor metadata varbinds")
+	}
+
+	// Repeated down results do not re-send
+	sender.HandleResult(result(models.StatusDown))
+	if packet := receive(); packet != nil {
+		t.Fatal("expected no trap for unchanged status")
+	}
+
+	sender.HandleResult(result(models.StatusUp))
+	packet = receive()
+	if packet == nil || !bytes.Contains(packet, upOID) {
+		t.Fatal("expected monitorUp trap on recovery")
+	}
+}
+
+func TestTrapSenderQuietHours(t *testing.T) {
+	address, receive := listenUDP(t)
+
+	sender, err := NewTrapSender(config.SNMPConfig{
+		Targets: []config.SNMPTargetConfig{{Address: address}},
+	}, testLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create sender: %v", err)
+	}
+
+	result := func(monitor string, status models.MonitorStatus, quiet string) *models.MonitorResult {
+		return &models.MonitorResult{Monitor: monitor, Status: status, Quiet: quiet, Timestamp: time.Now()}
+	}
+
+	sender.HandleResult(result("api", models.StatusDown, models.QuietSuppress))
+	if packet := receive(); packet != nil {
+		t.Fatal("expected no trap during suppressing quiet hours")
+	}
+
+	// Recovery after quiet hours is still reported
+	sender.HandleResult(result("api", models.StatusUp, ""))
+	if packet := receive(); packet == nil {
+		t.Fatal("expected a recovery trap after quiet hours")
+	}
+
+	sender.HandleResult(result("db", models.StatusDown, models.QuietDowngrade))
+	packet := receive()
+	if packet == nil {
+		t.Fatal("expected a trap during downgrading quiet hours")
+	}
+	if !bytes.Contains(packet, encodeOctetString([]byte("warning"))) {
+		t.Error("expected warning severity for a downgraded trap")
+	}
+}
+
+func TestTrapSenderV3Authentication(t *testing.T) {
+	address, receive := listenUDP(t)
+
+	sender, err := NewTrapSender(config.SNMPConfig{
+		EngineID: "8000000005deadbeef",
+		Targets: []config.SNMPTargetConfig{{
+			Address:      address,
+			Version:      "v3",
+			User:         "monitor",
+			AuthProtocol: "SHA",
+			AuthPassword: "authpassword",
+			PrivProtocol: "AES",
+			PrivPassword: "privpassword",
+		}},
+	}, testLogger(t))
+	if err != nil {
+		t.Fatalf("failed to create sender: %v", err)
+	}
+
+	if err := sender.Send(OID{1, 3, 6, 1, 4, 1, 99999, 0, 1}, &models.MonitorResult{Monitor: "api"}); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+
+	packet := receive()
+	if packet == nil {
+		t.Fatal("expected v3 trap")
+	}
+
+	// Scoped PDU is encrypted, so plaintext metadata must not appear
+	if bytes.Contains(packet, encodeOctetString([]byte("api"))) {
+		t.Error("expected scoped PDU to be encrypted")
+	}
+
+	marker := concat(encodeOctetString([]byte("monitor")), []byte{tagOctetString, authParamsLength})
+	idx := bytes.Index(packet, marker)
+	if idx < 0 {
+		t.Fatal("expected user and auth parameters in security parameters")
+	}
+	start := idx + len(marker)
+	received := append([]byte(nil), packet[start:start+authParamsLength]...)
+
+	zeroed := append([]byte(nil), packet...)
+	copy(zeroed[start:start+authParamsLength], make([]byte, authParamsLength))
+
+	key := localizeKey(sha1.New, passwordToKey(sha1.New, "authpassword"), sender.engineID)
+	mac := hmac.New(sha1.New, key)
+	mac.Write(zeroed)
+	if !bytes.Equal(received, mac.Sum(nil)[:authParamsLength]) {
+		t.Error("authentication parameters do not match HMAC-SHA-96 of the message")
+	}
+}
+
+func TestNewTrapSenderValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.SNMPConfig
+	}{
+		{name: "invalid enterprise", cfg: config.SNMPConfig{EnterpriseOID: "not.an.oid"}},
+		{name: "invalid engine id", cfg: config.SNMPConfig{EngineID: "zz"}},
+		{name: "short engine id", cfg: config.SNMPConfig{EngineID: "8000"}},
+		{name: "unknown version", cfg: config.SNMPConfig{Targets: []config.SNMPTargetConfig{{Address: "localhost", Version: "v1"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTrapSender(tt.cfg, testLogger(t)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestNewTrapTargetDefaultsPort(t *testing.T) {
+	target, err := newTrapTarget(config.SNMPTargetConfig{Address: "nms.example.com"}, nil)
+	if err != nil {
+		t.Fatalf("newTrapTarget failed: %v", err)
+	}
+	if target.address != "nms.example.com:162" || target.community != "public" {
+		t.Errorf("unexpected defaults: %+v", target)
+	}
+}
+
+// memoryBootStore is a BootStore kept in memory
+type memoryBootStore map[string][]byte
+
+func (m memoryBootStore) GetMetadata(key string) ([]byte, error) { return m[key], nil }
+
+func (m memoryBootStore) SetMetadata(key string, value []byte) error {
+	m[key] = value
+	return nil
+}
+
+func TestTrapSenderRecordBoot(t *testing.T) {
+	store := memoryBootStore{}
+	newSender := func(engineID string) *TrapSender {
+		t.Helper()
+		sender, err := NewTrapSender(config.SNMPConfig{EngineID: engineID}, testLogger(t))
+		if err != nil {
+			t.Fatalf("failed to create sender: %v", err)
+		}
+		if err := sender.RecordBoot(store); err != nil {
+			t.Fatalf("RecordBoot failed: %v", err)
+		}
+		return sender
+	}
+
+	// Each start of a configured engine ID boots once more
+	for want := int64(1); want <= 3; want++ {
+		if got := newSender("8000000005deadbeef").EngineBoots(); got != want {
+			t.Fatalf("expected engine boots %d, got %d", want, got)
+		}
+	}
+	if got := newSender("8000000005cafef00d").EngineBoots(); got != 1 {
+		t.Errorf("expected another engine ID to count its own boots, got %d", got)
+	}
+
+	// A generated engine ID is new on every start and is not recorded
+	if got := newSender("").EngineBoots(); got != 1 {
+		t.Errorf("expected a generated engine ID to boot once, got %d", got)
+	}
+	if len(store) != 2 {
+		t.Errorf("expected boots stored for the 2 configured engine IDs, got %v", store)
+	}
+
+	store[engineBootsMetaKey+"8000000005deadbeef"] = []byte("2147483647")
+	if got := newSender("8000000005deadbeef").EngineBoots(); got != maxEngineBoots {
+		t.Errorf("expected engine boots to stop at %d, got %d", maxEngineBoots, got)
+	}
+}
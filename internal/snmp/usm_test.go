@@ -0,0 +1,71 @@
+package snmp
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+)
+
+func TestLocalizeKeyRFC3414Vectors(t *testing.T) {
+	engineID, _ := hex.DecodeString("000000000000000000000002")
+
+	tests := []struct {
+		name string
+		key  []byte
+		want string
+	}{
+		{
+			name: "md5",
+			key:  localizeKey(md5.New, passwordToKey(md5.New, "maplesyrup"), engineID),
+			want: "526f5eed9fcce26f8964c2930787d82b",
+		},
+		{
+			name: "sha",
+			key:  localizeKey(sha1.New, passwordToKey(sha1.New, "maplesyrup"), engineID),
+			want: "6695febc9288e36282235fc7151f128497b38f3f",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(tt.key); got != tt.want {
+				t.Errorf("localized key = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUSMValidation(t *testing.T) {
+	engineID := []byte{0x80, 0, 0, 0, 5, 1, 2, 3}
+
+	tests := []struct {
+		name         string
+		auth         string
+		authPassword string
+		priv         string
+		privPassword string
+		wantFlags    byte
+		wantErr      bool
+	}{
+		{name: "noAuthNoPriv", wantFlags: 0},
+		{name: "authNoPriv", auth: "SHA", authPassword: "authpassword", wantFlags: flagAuth},
+		{name: "authPriv", auth: "MD5", authPassword: "authpassword", priv: "AES", privPassword: "privpassword", wantFlags: flagAuth | flagPriv},
+		{name: "priv without auth", priv: "AES", privPassword: "privpassword", wantErr: true},
+		{name: "short password", auth: "SHA", authPassword: "short", wantErr: true},
+		{name: "unknown auth", auth: "SHA512", authPassword: "authpassword", wantErr: true},
+		{name: "unknown priv", auth: "SHA", authPassword: "authpassword", priv: "DES", privPassword: "privpassword", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := newUSM("user", tt.auth, tt.authPassword, tt.priv, tt.privPassword, engineID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newUSM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && u.flags() != tt.wantFlags {
+				t.Errorf("flags = %02x, want %02x", u.flags(), tt.wantFlags)
+			}
+		})
+	}
+}
@@ -13,7 +13,8 @@ import (
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
-// Aggregator generates hourly and daily aggregate statistics from raw monitor results
+// Aggregator generates hourly and daily aggregate statistics from raw monitor
+// results, and rolls daily aggregates up into weekly and monthly ones
 type Aggregator struct {
 	store   *BadgerStore
 	logger  *logging.Logger
@@ -21,6 +22,7 @@ type Aggregator struct {
 	wg      sync.WaitGroup
 	running bool
 	mu      sync.RWMutex
+	runMu   sync.Mutex // Serializes aggregation runs and recomputes
 }
 
 // NewAggregator creates a new aggregator instance
@@ -96,8 +98,9 @@ func (a *Aggregator) aggregationLoop(ctx context.Context) {
 func (a *Aggregator) runAggregation() {
 	a.logger.WithComponent("aggregator").Info("Running aggregation")
 
-	// Get last aggregation time
-	lastRun := a.getLastAggregationTime()
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
+
 	now := time.Now()
 
 	// Get all monitor names
@@ -111,7 +114,7 @@ func (a *Aggregator) runAggregation() {
 
 	// Aggregate each monitor
 	for _, monitor := range monitors {
-		if err := a.aggregateMonitor(monitor, lastRun, now); err != nil {
+		if err := a.aggregateMonitor(monitor, now); err != nil {
 			a.logger.WithComponent("aggregator").
 				WithError(err).
 				WithFields(map[string]interface{}{"monitor": monitor}).
@@ -119,8 +122,11 @@ func (a *Aggregator) runAggregation() {
 		}
 	}
 
-	// Update last aggregation time
-	a.setLastAggregationTime(now)
+	if err := setMetadataTime(a.store, lastRunMetaKey, now); err != nil {
+		a.logger.WithComponent("aggregator").
+			WithError(err).
+			Error("Failed to store last aggregation time")
+	}
 
 	a.logger.WithComponent("aggregator").
 		WithFields(map[string]interface{}{
@@ -129,99 +135,208 @@ func (a *Aggregator) runAggregation() {
 		Info("Aggregation completed")
 }
 
-// aggregateMonitor aggregates data for a single monitor
-func (a *Aggregator) aggregateMonitor(monitor string, lastRun, now time.Time) error {
-	// Aggregate hourly data
-	if err := a.aggregateHourly(monitor, lastRun, now); err != nil {
-		return fmt.Errorf("hourly aggregation failed: %w", err)
-	}
+// lastRunMetaKey is the metadata key holding when aggregation last ran
+const lastRunMetaKey = "aggregator:last_run"
 
-	// Aggregate daily data
-	if err := a.aggregateDaily(monitor, lastRun, now); err != nil {
-		return fmt.Errorf("daily aggregation failed: %w", err)
+// LastRun returns when aggregation last ran, or nil if it never has
+func (a *Aggregator) LastRun() (*time.Time, error) {
+	return getMetadataTime(a.store, lastRunMetaKey)
+}
+
+// aggregateMonitor aggregates a monitor's periods completed since its
+// high-water mark for each period type. Without a mark, as for a new monitor
+// or a store written by an older version, it starts from the oldest data the
+// period is built from, so periods missed while the server was down are
+// filled in.
+func (a *Aggregator) aggregateMonitor(monitor string, now time.Time) error {
+	for _, period := range aggregatePeriodTypes {
+		from := a.getHighWater(period.periodType, monitor)
+		if from.IsZero() {
+			oldest, found, err := a.oldestSourceTime(monitor, period)
+			if err != nil {
+				return err
+			}
+			if !found {
+				continue
+			}
+			a.logger.WithComponent("aggregator").
+				WithFields(map[string]interface{}{
+					"monitor": monitor,
+					"period":  period.periodType,
+					"from":    oldest,
+				}).
+				Info("Backfilling aggregates from the oldest stored data")
+			from = oldest
+		}
+
+		until := period.truncate(now)
+		if _, err := a.aggregatePeriods(monitor, period, from, until); err != nil {
+			return fmt.Errorf("%s aggregation failed: %w", period.periodType, err)
+		}
+		if until.After(from) {
+			a.setHighWater(period.periodType, monitor, until)
+		}
 	}
 
 	return nil
 }
 
-// aggregateHourly generates hourly aggregates for a monitor
-func (a *Aggregator) aggregateHourly(monitor string, lastRun, now time.Time) error {
-	// Start from the hour after last run (or 24 hours ago if no last run)
-	if lastRun.IsZero() {
-		lastRun = now.Add(-24 * time.Hour)
+// oldestSourceTime returns the time of the oldest data a period type is built
+// from for a monitor: its oldest raw result, or its oldest daily aggregate
+func (a *Aggregator) oldestSourceTime(monitor string, period aggregatePeriod) (time.Time, bool, error) {
+	if period.fromDaily {
+		days, err := a.store.GetAggregates(monitor, "day", time.Unix(0, 0), time.Now())
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("failed to find oldest daily aggregate: %w", err)
+		}
+		if len(days) == 0 {
+			return time.Time{}, false, nil
+		}
+		return days[0].PeriodStart, true, nil
 	}
 
-	// Truncate to the start of the hour
-	currentHour := lastRun.Truncate(time.Hour)
-	endHour := now.Truncate(time.Hour)
+	var oldest time.Time
+	found := false
+	err := a.store.StreamResults(monitor, time.Unix(0, 0), time.Now(), false, func(result *models.MonitorResult) bool {
+		oldest, found = result.Timestamp, true
+		return false
+	})
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to find oldest result: %w", err)
+	}
+	return oldest, found, nil
+}
+
+// aggregatePeriods stores an aggregate for each period from the one
+// containing from up to the last one completed by until, and returns how many
+// it stored. Periods without data are skipped.
+func (a *Aggregator) aggregatePeriods(monitor string, period aggregatePeriod, from, until time.Time) (int, error) {
+	stored := 0
+	current := period.truncate(from)
+	end := period.truncate(until)
 
-	for currentHour.Before(endHour) {
-		nextHour := currentHour.Add(time.Hour)
+	for current.Before(end) {
+		next := period.next(current)
 
-		// Get results for this hour
-		results, err := a.store.GetResultsByPeriod(monitor, currentHour, nextHour)
+		agg, err := a.periodAggregate(monitor, period, current, next)
 		if err != nil {
-			return fmt.Errorf("failed to get results for hour %s: %w", currentHour, err)
+			return stored, err
 		}
 
-		// Skip if no results
-		if len(results) == 0 {
-			currentHour = nextHour
+		// Skip if no data
+		if agg == nil {
+			current = next
 			continue
 		}
 
-		// Calculate aggregate
-		agg := a.calculateAggregate(monitor, "hour", currentHour, nextHour, results)
-
-		// Store aggregate
 		if err := a.store.StoreAggregate(agg); err != nil {
-			return fmt.Errorf("failed to store hourly aggregate: %w", err)
+			return stored, fmt.Errorf("failed to store %s aggregate: %w", period.periodType, err)
 		}
+		stored++
 
-		currentHour = nextHour
+		current = next
 	}
 
-	return nil
+	return stored, nil
 }
 
-// aggregateDaily generates daily aggregates for a monitor
-func (a *Aggregator) aggregateDaily(monitor string, lastRun, now time.Time) error {
-	// Start from the day after last run (or 7 days ago if no last run)
-	if lastRun.IsZero() {
-		lastRun = now.Add(-7 * 24 * time.Hour)
+// periodAggregate computes the aggregate of start..end, or nil if there is no
+// data for it. Ends are exclusive, so data on the boundary is only counted in
+// the next period.
+func (a *Aggregator) periodAggregate(monitor string, period aggregatePeriod, start, end time.Time) (*models.AggregateResult, error) {
+	if period.fromDaily {
+		days, err := a.store.GetAggregates(monitor, "day", start, end.Add(-time.Nanosecond))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get daily aggregates for %s %s: %w", period.periodType, start, err)
+		}
+		return combineAggregates(monitor, period.periodType, start, end, days), nil
+	}
+
+	results, err := a.store.GetResultsByPeriod(monitor, start, end.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results for %s %s: %w", period.periodType, start, err)
+	}
+	if len(results) == 0 {
+		return nil, nil
 	}
+	return a.calculateAggregate(monitor, period.periodType, start, end, results), nil
+}
 
-	// Truncate to the start of the day
-	currentDay := lastRun.Truncate(24 * time.Hour)
-	endDay := now.Truncate(24 * time.Hour)
+// RecomputeResult summarizes the aggregates a recompute regenerated
+type RecomputeResult struct {
+	Monitors int `json:"monitors"`
+	Hourly   int `json:"hourly"`
+	Daily    int `json:"daily"`
+	Weekly   int `json:"weekly"`
+	Monthly  int `json:"monthly"`
+}
 
-	for currentDay.Before(endDay) {
-		nextDay := currentDay.Add(24 * time.Hour)
+// Recompute regenerates the aggregates of every completed period overlapping
+// from..to, for one monitor or, when monitor is empty, for every monitor with
+// stored results. Periods without the data they are built from keep the
+// aggregates they have, so rollups that outlive raw retention are not lost.
+func (a *Aggregator) Recompute(monitor string, from, to time.Time) (RecomputeResult, error) {
+	var summary RecomputeResult
+	if !to.After(from) {
+		return summary, fmt.Errorf("end of range must be after its start")
+	}
 
-		// Get results for this day
-		results, err := a.store.GetResultsByPeriod(monitor, currentDay, nextDay)
-		if err != nil {
-			return fmt.Errorf("failed to get results for day %s: %w", currentDay, err)
-		}
+	// Don't interleave with a scheduled run over the same periods
+	a.runMu.Lock()
+	defer a.runMu.Unlock()
 
-		// Skip if no results
-		if len(results) == 0 {
-			currentDay = nextDay
-			continue
+	monitors := []string{monitor}
+	if monitor == "" {
+		names, err := a.store.GetMonitorNames()
+		if err != nil {
+			return summary, fmt.Errorf("failed to get monitor names: %w", err)
 		}
+		monitors = names
+	}
 
-		// Calculate aggregate
-		agg := a.calculateAggregate(monitor, "day", currentDay, nextDay, results)
-
-		// Store aggregate
-		if err := a.store.StoreAggregate(agg); err != nil {
-			return fmt.Errorf("failed to store daily aggregate: %w", err)
+	now := time.Now()
+	for _, name := range monitors {
+		for _, period := range aggregatePeriodTypes {
+			// Include the period containing to, unless it is still running
+			until := period.truncate(to)
+			if until.Before(to) {
+				until = period.next(until)
+			}
+			if until.After(now) {
+				until = now
+			}
+
+			stored, err := a.aggregatePeriods(name, period, from, until)
+			switch period.periodType {
+			case "hour":
+				summary.Hourly += stored
+			case "day":
+				summary.Daily += stored
+			case "week":
+				summary.Weekly += stored
+			case "month":
+				summary.Monthly += stored
+			}
+			if err != nil {
+				return summary, fmt.Errorf("monitor %s: %w", name, err)
+			}
 		}
-
-		currentDay = nextDay
+		summary.Monitors++
 	}
 
-	return nil
+	a.logger.WithComponent("aggregator").
+		WithFields(map[string]interface{}{
+			"monitors": summary.Monitors,
+			"hourly":   summary.Hourly,
+			"daily":    summary.Daily,
+			"weekly":   summary.Weekly,
+			"monthly":  summary.Monthly,
+			"from":     from,
+			"to":       to,
+		}).
+		Info("Aggregates recomputed")
+
+	return summary, nil
 }
 
 // calculateAggregate computes aggregate statistics from a set of results
@@ -231,7 +346,6 @@ func (a *Aggregator) calculateAggregate(monitor, periodType string, start, end t
 		PeriodStart: start,
 		PeriodEnd:   end,
 		PeriodType:  periodType,
-		TotalChecks: len(results),
 	}
 
 	if len(results) == 0 {
@@ -245,11 +359,13 @@ func (a *Aggregator) calculateAggregate(monitor, periodType string, start, end t
 	var totalDuration time.Duration
 
 	for _, result := range results {
-		// Count up/down
+		// Count up/down, weighting sampled results by the checks they stand for
+		weight := result.Weight()
+		agg.TotalChecks += weight
 		if result.Status == models.StatusUp {
-			agg.UpChecks++
+			agg.UpChecks += weight
 		} else if result.Status == models.StatusDown {
-			agg.DownChecks++
+			agg.DownChecks += weight
 		}
 
 		// Track duration stats
@@ -262,50 +378,142 @@ func (a *Aggregator) calculateAggregate(monitor, periodType string, start, end t
 		}
 	}
 
-	// Calculate averages
+	// Calculate averages; durations are only known for stored results
 	if agg.TotalChecks > 0 {
-		agg.AvgDuration = totalDuration / time.Duration(agg.TotalChecks)
+		agg.AvgDuration = totalDuration / time.Duration(len(results))
 		agg.UptimePercent = float64(agg.UpChecks) / float64(agg.TotalChecks) * 100.0
 	}
 
 	return agg
 }
 
-// getLastAggregationTime retrieves the timestamp of the last successful aggregation
-func (a *Aggregator) getLastAggregationTime() time.Time {
-	data, err := a.store.GetMetadata("aggregator:last_run")
+// combineAggregates rolls aggregates of shorter periods up into one covering
+// start..end, or returns nil if there are none. Average durations are
+// weighted by each part's checks.
+func combineAggregates(monitor, periodType string, start, end time.Time, parts []*models.AggregateResult) *models.AggregateResult {
+	if len(parts) == 0 {
+		return nil
+	}
+
+	agg := &models.AggregateResult{
+		Monitor:     monitor,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		PeriodType:  periodType,
+		MinDuration: parts[0].MinDuration,
+		MaxDuration: parts[0].MaxDuration,
+	}
+
+	var weightedDuration float64
+	for _, part := range parts {
+		agg.TotalChecks += part.TotalChecks
+		agg.UpChecks += part.UpChecks
+		agg.DownChecks += part.DownChecks
+		weightedDuration += float64(part.AvgDuration) * float64(part.TotalChecks)
+		if part.MinDuration < agg.MinDuration {
+			agg.MinDuration = part.MinDuration
+		}
+		if part.MaxDuration > agg.MaxDuration {
+			agg.MaxDuration = part.MaxDuration
+		}
+	}
+
+	if agg.TotalChecks > 0 {
+		agg.AvgDuration = time.Duration(weightedDuration / float64(agg.TotalChecks))
+		agg.UptimePercent = float64(agg.UpChecks) / float64(agg.TotalChecks) * 100.0
+	}
+
+	return agg
+}
+
+// highWaterKey is the metadata key holding the end of the last period of a
+// type aggregated for a monitor
+func highWaterKey(periodType, monitor string) string {
+	return "aggregator:high_water:" + periodType + ":" + monitor
+}
+
+// getHighWater returns the end of the last period of a type aggregated for a
+// monitor, or zero time if it has never been aggregated
+func (a *Aggregator) getHighWater(periodType, monitor string) time.Time {
+	data, err := a.store.GetMetadata(highWaterKey(periodType, monitor))
 	if err != nil || data == nil {
-		return time.Time{} // Return zero time if not found
+		return time.Time{}
 	}
 
 	var timestamp time.Time
 	if err := json.Unmarshal(data, &timestamp); err != nil {
 		a.logger.WithComponent("aggregator").
 			WithError(err).
-			Warn("Failed to parse last aggregation time")
+			WithFields(map[string]interface{}{"monitor": monitor}).
+			Warn("Failed to parse aggregation high-water mark")
 		return time.Time{}
 	}
 
 	return timestamp
 }
 
-// setLastAggregationTime stores the timestamp of the last successful aggregation
-func (a *Aggregator) setLastAggregationTime(t time.Time) {
+// setHighWater records that a monitor's periods of a type are aggregated up
+// to t
+func (a *Aggregator) setHighWater(periodType, monitor string, t time.Time) {
 	data, err := json.Marshal(t)
 	if err != nil {
 		a.logger.WithComponent("aggregator").
 			WithError(err).
-			Error("Failed to marshal aggregation time")
+			Error("Failed to marshal aggregation high-water mark")
 		return
 	}
 
-	if err := a.store.SetMetadata("aggregator:last_run", data); err != nil {
+	if err := a.store.SetMetadata(highWaterKey(periodType, monitor), data); err != nil {
 		a.logger.WithComponent("aggregator").
 			WithError(err).
-			Error("Failed to store last aggregation time")
+			WithFields(map[string]interface{}{"monitor": monitor}).
+			Error("Failed to store aggregation high-water mark")
 	}
 }
 
+// LiveAggregate computes the aggregate of the period containing now, so
+// charts need not end at the last completed period. Hours and days come from
+// raw results; weeks and months from the daily aggregates so far and today's
+// results. It ends at now, is marked partial and is never stored. It returns
+// nil when the period has no data yet.
+func (a *Aggregator) LiveAggregate(monitor, periodType string, now time.Time) (*models.AggregateResult, error) {
+	period, ok := lookupPeriod(periodType)
+	if !ok {
+		return nil, fmt.Errorf("invalid period type: %s", periodType)
+	}
+	start := period.truncate(now)
+
+	var agg *models.AggregateResult
+	if period.fromDaily {
+		days, err := a.store.GetAggregates(monitor, "day", start, now.Truncate(24*time.Hour).Add(-time.Nanosecond))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get daily aggregates for current %s: %w", periodType, err)
+		}
+		today, err := a.LiveAggregate(monitor, "day", now)
+		if err != nil {
+			return nil, err
+		}
+		if today != nil {
+			days = append(days, today)
+		}
+		agg = combineAggregates(monitor, periodType, start, now, days)
+	} else {
+		results, err := a.store.GetResultsByPeriod(monitor, start, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get results for current %s: %w", periodType, err)
+		}
+		if len(results) > 0 {
+			agg = a.calculateAggregate(monitor, periodType, start, now, results)
+		}
+	}
+	if agg == nil {
+		return nil, nil
+	}
+
+	agg.Partial = true
+	return agg, nil
+}
+
 // GetAggregatesByPeriod returns aggregated data for a monitor within a time period
 func (a *Aggregator) GetAggregatesByPeriod(monitor string, start, end time.Time, periodType string) ([]*models.AggregateResult, error) {
 	return a.store.GetAggregatesByPeriod(monitor, start, end, periodType)
@@ -331,6 +539,18 @@ func (a *Aggregator) GetAggregatedMetrics(monitor string, start, end time.Time,
 		return nil, err
 	}
 
+	// The period in progress has no stored aggregate yet
+	now := time.Now()
+	if period, _ := lookupPeriod(periodType); end.After(period.truncate(now)) {
+		live, err := a.LiveAggregate(monitor, periodType, now)
+		if err != nil {
+			return nil, err
+		}
+		if live != nil {
+			aggregates = append(aggregates, live)
+		}
+	}
+
 	var dataPoints []AggregatorDataPoint
 	for _, agg := range aggregates {
 		dataPoints = append(dataPoints, AggregatorDataPoint{
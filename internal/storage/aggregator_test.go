@@ -218,3 +218,295 @@ func TestAggregator_AllUp(t *testing.T) {
 		t.Errorf("Expected 0 down checks, got %d", aggregate.DownChecks)
 	}
 }
+
+func TestAggregator_SampledResults(t *testing.T) {
+	agg := &Aggregator{}
+
+	now := time.Now()
+	results := []*models.MonitorResult{
+		{Monitor: "fast", Status: models.StatusUp, Duration: 100 * time.Millisecond, Timestamp: now, SampleWeight: 8},
+		{Monitor: "fast", Status: models.StatusDown, Duration: 300 * time.Millisecond, Timestamp: now.Add(time.Minute)},
+		{Monitor: "fast", Status: models.StatusUp, Duration: 200 * time.Millisecond, Timestamp: now.Add(2 * time.Minute)},
+	}
+
+	aggregate := agg.calculateAggregate("fast", "hour", now, now.Add(time.Hour), results)
+
+	// Each sampled result counts as the checks it stands for
+	if aggregate.TotalChecks != 10 || aggregate.UpChecks != 9 || aggregate.DownChecks != 1 {
+		t.Errorf("expected 10 checks with 9 up, got %+v", aggregate)
+	}
+	if aggregate.UptimePercent != 90.0 {
+		t.Errorf("expected 90%% uptime, got %.2f%%", aggregate.UptimePercent)
+	}
+	// Durations are only known for stored results
+	if aggregate.AvgDuration != 200*time.Millisecond {
+		t.Errorf("expected average duration 200ms, got %v", aggregate.AvgDuration)
+	}
+}
+
+func TestAggregator_Recompute(t *testing.T) {
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	store, err := NewBadgerStore(t.TempDir(), 7, logger)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	aggregator := NewAggregator(store, logger)
+
+	hour := time.Now().UTC().Truncate(time.Hour).Add(-3 * time.Hour)
+	for i, status := range []models.MonitorStatus{models.StatusUp, models.StatusDown, models.StatusUp} {
+		result := &models.MonitorResult{Monitor: "api", Status: status, Timestamp: hour.Add(time.Duration(i) * time.Minute)}
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+	if err := store.StoreResult(&models.MonitorResult{Monitor: "web", Status: models.StatusUp, Timestamp: hour}); err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+
+	// A stale aggregate is replaced; one without raw results is kept
+	stale := &models.AggregateResult{Monitor: "api", PeriodType: "hour", PeriodStart: hour, PeriodEnd: hour.Add(time.Hour), TotalChecks: 1, UpChecks: 1, UptimePercent: 100}
+	expired := &models.AggregateResult{Monitor: "api", PeriodType: "hour", PeriodStart: hour.Add(-time.Hour), PeriodEnd: hour, TotalChecks: 5, UpChecks: 5, UptimePercent: 100}
+	for _, agg := range []*models.AggregateResult{stale, expired} {
+		if err := store.StoreAggregate(agg); err != nil {
+			t.Fatalf("Failed to store aggregate: %v", err)
+		}
+	}
+
+	// The range ends mid-hour, which still includes that whole hour
+	summary, err := aggregator.Recompute("api", hour.Add(-time.Hour), hour.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("Recompute failed: %v", err)
+	}
+	if summary.Monitors != 1 || summary.Hourly != 1 {
+		t.Errorf("Expected 1 monitor and 1 hourly aggregate, got %+v", summary)
+	}
+
+	aggregates, err := store.GetAggregates("api", "hour", hour.Add(-time.Hour), hour)
+	if err != nil {
+		t.Fatalf("Failed to get aggregates: %v", err)
+	}
+	if len(aggregates) != 2 {
+		t.Fatalf("Expected 2 hourly aggregates, got %d", len(aggregates))
+	}
+	if aggregates[0].TotalChecks != 5 {
+		t.Errorf("Expected the aggregate without raw results to be kept, got %+v", aggregates[0])
+	}
+	if aggregates[1].TotalChecks != 3 || aggregates[1].DownChecks != 1 {
+		t.Errorf("Expected the stale aggregate to be recomputed, got %+v", aggregates[1])
+	}
+
+	// Every monitor, and never the hour still in progress
+	summary, err = aggregator.Recompute("", hour, time.Now())
+	if err != nil {
+		t.Fatalf("Recompute failed: %v", err)
+	}
+	if summary.Monitors != 2 || summary.Hourly != 2 {
+		t.Errorf("Expected 2 monitors and 2 hourly aggregates, got %+v", summary)
+	}
+
+	if _, err := aggregator.Recompute("api", hour, hour); err == nil {
+		t.Error("Expected an empty range to be rejected")
+	}
+}
+
+func TestAggregator_BackfillsGaps(t *testing.T) {
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	store, err := NewBadgerStore(t.TempDir(), 30, logger)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	aggregator := NewAggregator(store, logger)
+
+	// Results well before the old 24h/7d look-back, and over the last days
+	now := time.Now().UTC().Truncate(time.Hour)
+	oldest := now.Add(-10 * 24 * time.Hour)
+	for _, ts := range []time.Time{oldest, now.Add(-50 * time.Hour), now.Add(-2 * time.Hour)} {
+		for _, monitor := range []string{"api", "web"} {
+			if err := store.StoreResult(&models.MonitorResult{Monitor: monitor, Status: models.StatusUp, Timestamp: ts}); err != nil {
+				t.Fatalf("Failed to store result: %v", err)
+			}
+		}
+	}
+
+	// "web" was last aggregated two days ago, before a long outage; "api"
+	// has never been aggregated
+	aggregator.setHighWater("hour", "web", now.Add(-48*time.Hour))
+	aggregator.setHighWater("day", "web", now.Add(-48*time.Hour).Truncate(24*time.Hour))
+
+	if lastRun, err := aggregator.LastRun(); err != nil || lastRun != nil {
+		t.Fatalf("Expected no last run before aggregating, got %v (err %v)", lastRun, err)
+	}
+
+	aggregator.runAggregation()
+
+	if lastRun, err := aggregator.LastRun(); err != nil || lastRun == nil || time.Since(*lastRun) > time.Minute {
+		t.Errorf("Expected the run to be recorded, got %v (err %v)", lastRun, err)
+	}
+
+	hourly := func(monitor string) int {
+		aggs, err := store.GetAggregates(monitor, "hour", oldest.Add(-time.Hour), now)
+		if err != nil {
+			t.Fatalf("Failed to get aggregates: %v", err)
+		}
+		return len(aggs)
+	}
+	if got := hourly("api"); got != 3 {
+		t.Errorf("Expected api to be backfilled from its oldest result, got %d hourly aggregates", got)
+	}
+	if got := hourly("web"); got != 1 {
+		t.Errorf("Expected web to be aggregated from its high-water mark, got %d hourly aggregates", got)
+	}
+
+	if mark := aggregator.getHighWater("hour", "api"); !mark.Equal(time.Now().Truncate(time.Hour)) {
+		t.Errorf("Expected the hourly mark at the current hour, got %v", mark)
+	}
+
+	// Monitors without results are left alone
+	if err := aggregator.aggregateMonitor("missing", time.Now()); err != nil {
+		t.Errorf("Expected no error for a monitor without results, got %v", err)
+	}
+	if mark := aggregator.getHighWater("hour", "missing"); !mark.IsZero() {
+		t.Errorf("Expected no mark for a monitor without results, got %v", mark)
+	}
+}
+
+func TestAggregator_LiveAggregate(t *testing.T) {
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	store, err := NewBadgerStore(t.TempDir(), 7, logger)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	aggregator := NewAggregator(store, logger)
+
+	now := time.Now()
+	hour := now.Truncate(time.Hour)
+	for i, status := range []models.MonitorStatus{models.StatusUp, models.StatusDown} {
+		result := &models.MonitorResult{Monitor: "api", Status: status, Duration: 100 * time.Millisecond, Timestamp: hour.Add(time.Duration(i) * time.Nanosecond)}
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+
+	live, err := aggregator.LiveAggregate("api", "hour", now)
+	if err != nil {
+		t.Fatalf("LiveAggregate failed: %v", err)
+	}
+	if live == nil || !live.Partial || live.TotalChecks != 2 || live.UptimePercent != 50 {
+		t.Fatalf("Expected a partial aggregate of 2 checks at 50%%, got %+v", live)
+	}
+	if !live.PeriodStart.Equal(hour) || !live.PeriodEnd.Equal(now) {
+		t.Errorf("Expected the period %v to %v, got %v to %v", hour, now, live.PeriodStart, live.PeriodEnd)
+	}
+
+	// Partial aggregates are never stored
+	if aggs, _ := store.GetAggregates("api", "hour", hour, hour); len(aggs) != 0 {
+		t.Errorf("Expected no stored aggregate, got %d", len(aggs))
+	}
+
+	if live, err := aggregator.LiveAggregate("web", "hour", now); err != nil || live != nil {
+		t.Errorf("Expected nothing for a monitor without results, got %+v (err %v)", live, err)
+	}
+	if _, err := aggregator.LiveAggregate("api", "fortnight", now); err == nil {
+		t.Error("Expected an unknown period type to be rejected")
+	}
+
+	// Chart data ends with the hour in progress
+	points, err := aggregator.GetAggregatedMetrics("api", now.Add(-24*time.Hour), now, "1h")
+	if err != nil {
+		t.Fatalf("GetAggregatedMetrics failed: %v", err)
+	}
+	if len(points) != 1 || !points[0].Timestamp.Equal(hour) || points[0].Value != 100 {
+		t.Errorf("Expected one point for the current hour, got %+v", points)
+	}
+}
+
+func TestAggregator_WeeklyAndMonthly(t *testing.T) {
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	store, err := NewBadgerStore(t.TempDir(), 7, logger)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+	aggregator := NewAggregator(store, logger)
+
+	// Daily aggregates from two months ago, long after their raw results expired
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month()-2, 1, 0, 0, 0, 0, time.UTC)
+	for i, day := range []struct {
+		up, down int
+		avg      time.Duration
+	}{
+		{up: 100, avg: 100 * time.Millisecond},
+		{up: 50, down: 50, avg: 300 * time.Millisecond},
+		{up: 100, avg: 200 * time.Millisecond},
+	} {
+		start := month.AddDate(0, 0, i)
+		agg := &models.AggregateResult{
+			Monitor: "api", PeriodType: "day", PeriodStart: start, PeriodEnd: start.AddDate(0, 0, 1),
+			TotalChecks: day.up + day.down, UpChecks: day.up, DownChecks: day.down,
+			AvgDuration: day.avg, MinDuration: day.avg / 2, MaxDuration: day.avg * 2,
+		}
+		if err := store.StoreAggregate(agg); err != nil {
+			t.Fatalf("Failed to store aggregate: %v", err)
+		}
+	}
+
+	if err := aggregator.aggregateMonitor("api", time.Now()); err != nil {
+		t.Fatalf("Aggregation failed: %v", err)
+	}
+
+	monthly, err := store.GetAggregates("api", "month", month, month)
+	if err != nil || len(monthly) != 1 {
+		t.Fatalf("Expected one monthly aggregate, got %v (err %v)", monthly, err)
+	}
+	m := monthly[0]
+	if m.TotalChecks != 300 || m.UpChecks != 250 || m.DownChecks != 50 {
+		t.Errorf("Expected 250 of 300 checks up, got %+v", m)
+	}
+	if m.AvgDuration != 200*time.Millisecond || m.MinDuration != 50*time.Millisecond || m.MaxDuration != 600*time.Millisecond {
+		t.Errorf("Expected weighted durations, got avg %v min %v max %v", m.AvgDuration, m.MinDuration, m.MaxDuration)
+	}
+	if !m.PeriodEnd.Equal(month.AddDate(0, 1, 0)) {
+		t.Errorf("Expected the month to end on the first of the next, got %v", m.PeriodEnd)
+	}
+
+	// The days may fall in two weeks, which together hold every check
+	weekly, err := store.GetAggregates("api", "week", month.AddDate(0, 0, -7), month.AddDate(0, 0, 3))
+	if err != nil || len(weekly) == 0 {
+		t.Fatalf("Expected weekly aggregates, got %v (err %v)", weekly, err)
+	}
+	total := 0
+	for _, w := range weekly {
+		if w.PeriodStart.Weekday() != time.Monday {
+			t.Errorf("Expected weeks to start on Monday, got %v", w.PeriodStart)
+		}
+		total += w.TotalChecks
+	}
+	if total != 300 {
+		t.Errorf("Expected 300 checks across the weeks, got %d", total)
+	}
+
+	// Recomputes roll up the same way
+	summary, err := aggregator.Recompute("api", month, month.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("Recompute failed: %v", err)
+	}
+	if summary.Monthly != 1 || summary.Weekly != len(weekly) {
+		t.Errorf("Expected 1 monthly and %d weekly aggregates, got %+v", len(weekly), summary)
+	}
+}
@@ -0,0 +1,275 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Stored values are wrapped in a small envelope so the encoding can change
+// without breaking existing data:
+//
+//	[envelopeMagic][envelopeVersion][codec][payload...]
+//
+// Values written before the envelope existed are bare JSON objects, which
+// never start with envelopeMagic.
+const (
+	envelopeMagic   byte = 0xB1
+	envelopeVersion byte = 1
+	envelopeHeader       = 3
+)
+
+// Payload codecs
+const (
+	codecJSON        byte = 0 // Plain JSON
+	codecJSONZstd    byte = 1 // JSON compressed with zstd and resultDictionary
+	codecMsgpack     byte = 2 // MessagePack encoded from the model structs
+	codecMsgpackZstd byte = 3 // MessagePack compressed with zstd and msgpackDictionary
+)
+
+// Compression settings for BadgerStore
+const (
+	CompressionNone = "none"
+	CompressionZstd = "zstd"
+)
+
+// Encoding settings for BadgerStore
+const (
+	EncodingJSON    = "json"
+	EncodingMsgpack = "msgpack"
+)
+
+// resultDictionaryID identifies resultDictionary inside zstd frames. The
+// dictionary content must never change for a given ID, since stored values
+// can only be decompressed with the dictionary they were written with.
+const resultDictionaryID = 1
+
+// resultDictionary primes zstd with the field names and values that repeat in
+// every stored result, which is where most of the size of small JSON values
+// goes
+var resultDictionary = []byte(`{"monitor":"","type":"http","group":"default","status":"up","duration":0,"timestamp":"2024-01-01T00:00:00.000000000Z","error":"","metadata":{},"synthetic":true,` +
+	`"http_result":{"status_code":200,"response_time":0,"response_size":0,"headers":{"Content-Type":"application/json"},"ssl_cert_expiry":"","connection_reused":false,"protocol":"HTTP/1.1","http3_advertised":true,"body_hash":"","body_snippet":"","content_changed":true,"previous_body_hash":""},` +
+	`"ping_result":{"packets_sent":3,"packets_received":3,"packet_loss":0,"min_rtt":0,"max_rtt":0,"avg_rtt":0},` +
+	`"tcp_result":{"port":443,"connected":true,"response_time":0},"dns_result":{"query_type":"A","response_code":0,"response_time":0,"answers":[],"response_size":0},` +
+	`"rbl_result":{"target":"","checked":0,"listed":[{"blocklist":"","codes":["127.0.0.2"]}],"errors":{}},"domain_result":{"domain":"","expires_at":"","days_remaining":0,"registrar":"","source":"rdap"},` +
+	`"status":"down","type":"tcp","type":"ping","type":"dns","status":"unknown","error":"context deadline exceeded","error":"connection refused","error":"i/o timeout"}` +
+	`{"monitor":"","period_start":"","period_end":"","period_type":"hour","period_type":"day","total_checks":0,"up_checks":0,"down_checks":0,"uptime_percent":100,"avg_duration":0,"min_duration":0,"max_duration":0}` +
+	`{"id":"","time":"","time_end":"","monitor":"","group":"","text":"","tags":[]}`)
+
+// msgpackDictionaryID identifies msgpackDictionary inside zstd frames. Like
+// resultDictionary, its content must never change.
+const msgpackDictionaryID = 2
+
+// msgpackDictionaryTime stands in for every time in msgpackDictionarySamples
+var msgpackDictionaryTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// msgpackDictionarySamples are the values msgpackDictionary is built from.
+// resultDictionary is JSON text and barely helps MessagePack payloads, so
+// these carry the same fields encoded the way values are stored. Changing
+// them, or how marshalMsgpack writes them, changes the dictionary.
+var msgpackDictionarySamples = []interface{}{
+	&models.Annotation{Time: msgpackDictionaryTime, Tags: []string{""}},
+	&models.AggregateResult{PeriodStart: msgpackDictionaryTime, PeriodEnd: msgpackDictionaryTime, PeriodType: "day", UptimePercent: 100},
+	&models.AggregateResult{PeriodStart: msgpackDictionaryTime, PeriodEnd: msgpackDictionaryTime, PeriodType: "hour", UptimePercent: 100},
+	&models.MonitorResult{Type: models.MonitorTypeDomain, Group: "default", Status: models.StatusUnknown, Timestamp: msgpackDictionaryTime, Error: "i/o timeout",
+		DomainResult: &models.DomainResult{ExpiresAt: msgpackDictionaryTime, Source: "rdap"}},
+	&models.MonitorResult{Type: models.MonitorTypeRBL, Group: "default", Status: models.StatusDown, Timestamp: msgpackDictionaryTime,
+		RBLResult: &models.RBLResult{Listed: []models.RBLListing{{Codes: []string{"127.0.0.2"}}}}},
+	&models.MonitorResult{Type: models.MonitorTypeDNS, Group: "default", Status: models.StatusUp, Timestamp: msgpackDictionaryTime,
+		DNSResult: &models.DNSResult{QueryType: "A"}},
+	&models.MonitorResult{Type: models.MonitorTypeTCP, Group: "default", Status: models.StatusDown, Timestamp: msgpackDictionaryTime, Error: "connection refused",
+		TCPResult: &models.TCPResult{Port: 443}},
+	&models.MonitorResult{Type: models.MonitorTypePing, Group: "default", Status: models.StatusDown, Timestamp: msgpackDictionaryTime, Error: "context deadline exceeded",
+		PingResult: &models.PingResult{PacketsSent: 3, PacketLoss: 100}},
+	&models.MonitorResult{Type: models.MonitorTypeHTTP, Group: "default", Status: models.StatusUp, Timestamp: msgpackDictionaryTime, Synthetic: true,
+		HTTPResult: &models.HTTPResult{StatusCode: 200, Headers: map[string]string{"Content-Type": "application/json"}, Protocol: "HTTP/1.1", HTTP3Advertised: true, ContentChanged: true}},
+}
+
+// msgpackDictionary primes zstd for MessagePack payloads, built once from
+// msgpackDictionarySamples with the most common fields last so they sit
+// closest to the data
+var msgpackDictionary = buildMsgpackDictionary()
+
+// buildMsgpackDictionary encodes and concatenates msgpackDictionarySamples
+func buildMsgpackDictionary() []byte {
+	var dictionary []byte
+	for _, sample := range msgpackDictionarySamples {
+		packed, err := marshalMsgpack(sample)
+		if err != nil {
+			panic(fmt.Sprintf("invalid MessagePack dictionary sample: %v", err))
+		}
+		dictionary = append(dictionary, packed...)
+	}
+	return dictionary
+}
+
+// valueCodec encodes values stored in BadgerDB
+type valueCodec struct {
+	codec   byte
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+// newValueCodec creates a codec writing values with the given encoding
+// ("json" or "msgpack"; empty means json) and compression ("zstd" or
+// "none"; empty means zstd). Values written with any codec are readable.
+func newValueCodec(encoding, compression string) (*valueCodec, error) {
+	vc := &valueCodec{}
+	switch encoding {
+	case "", EncodingJSON:
+		vc.codec = codecJSON
+	case EncodingMsgpack:
+		vc.codec = codecMsgpack
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s (valid options: json, msgpack)", encoding)
+	}
+	switch compression {
+	case "", CompressionZstd:
+		// Each zstd codec directly follows its uncompressed counterpart
+		vc.codec++
+	case CompressionNone:
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s (valid options: zstd, none)", compression)
+	}
+
+	dictionaryID, dictionary := uint32(resultDictionaryID), resultDictionary
+	if vc.Encoding() == EncodingMsgpack {
+		dictionaryID, dictionary = msgpackDictionaryID, msgpackDictionary
+	}
+
+	var err error
+	vc.encoder, err = zstd.NewWriter(nil, zstd.WithEncoderDictRaw(dictionaryID, dictionary))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+	}
+	vc.decoder, err = zstd.NewReader(nil,
+		zstd.WithDecoderDictRaw(resultDictionaryID, resultDictionary),
+		zstd.WithDecoderDictRaw(msgpackDictionaryID, msgpackDictionary))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+
+	return vc, nil
+}
+
+// Name returns the compression the codec writes with
+func (vc *valueCodec) Name() string {
+	if vc.compressed() {
+		return CompressionZstd
+	}
+	return CompressionNone
+}
+
+// Encoding returns the encoding the codec writes with
+func (vc *valueCodec) Encoding() string {
+	if vc.codec == codecMsgpack || vc.codec == codecMsgpackZstd {
+		return EncodingMsgpack
+	}
+	return EncodingJSON
+}
+
+// compressed reports whether the codec writes zstd compressed values
+func (vc *valueCodec) compressed() bool {
+	return vc.codec == codecJSONZstd || vc.codec == codecMsgpackZstd
+}
+
+// Marshal encodes v into an enveloped value
+func (vc *valueCodec) Marshal(v interface{}) ([]byte, error) {
+	var payload []byte
+	var err error
+	if vc.Encoding() == EncodingMsgpack {
+		payload, err = marshalMsgpack(v)
+	} else {
+		payload, err = json.Marshal(v)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return vc.wrap(payload), nil
+}
+
+// Unmarshal decodes an enveloped or legacy JSON value into v
+func (vc *valueCodec) Unmarshal(data []byte, v interface{}) error {
+	payload, packed, err := vc.payload(data)
+	if err != nil {
+		return err
+	}
+	if packed {
+		return unmarshalMsgpack(payload, v)
+	}
+	return json.Unmarshal(payload, v)
+}
+
+// Reencode converts a value to the codec's current encoding, reporting false
+// when it is already up to date. The value is decoded into v, which must
+// point to a zero value of the type it was stored as.
+func (vc *valueCodec) Reencode(data []byte, v interface{}) ([]byte, bool, error) {
+	if isEnveloped(data) && data[1] == envelopeVersion && data[2] == vc.codec {
+		return nil, false, nil
+	}
+	if err := vc.Unmarshal(data, v); err != nil {
+		return nil, false, err
+	}
+	value, err := vc.Marshal(v)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// wrap builds an envelope around an encoded payload
+func (vc *valueCodec) wrap(payload []byte) []byte {
+	value := make([]byte, envelopeHeader, envelopeHeader+len(payload))
+	value[0], value[1], value[2] = envelopeMagic, envelopeVersion, vc.codec
+
+	if vc.compressed() {
+		return vc.encoder.EncodeAll(payload, value)
+	}
+	return append(value, payload...)
+}
+
+// payload returns the encoded value held in a stored value, and whether it
+// is MessagePack rather than JSON
+func (vc *valueCodec) payload(data []byte) ([]byte, bool, error) {
+	if !isEnveloped(data) {
+		// Written before the envelope existed
+		return data, false, nil
+	}
+	if data[1] != envelopeVersion {
+		return nil, false, fmt.Errorf("unsupported value envelope version %d", data[1])
+	}
+
+	switch data[2] {
+	case codecJSON, codecMsgpack:
+		return data[envelopeHeader:], data[2] == codecMsgpack, nil
+	case codecJSONZstd, codecMsgpackZstd:
+		payload, err := vc.decompress(data[envelopeHeader:])
+		return payload, data[2] == codecMsgpackZstd, err
+	default:
+		return nil, false, fmt.Errorf("unsupported value codec %d", data[2])
+	}
+}
+
+// decompress reverses zstd compression with resultDictionary
+func (vc *valueCodec) decompress(data []byte) ([]byte, error) {
+	payload, err := vc.decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress value: %w", err)
+	}
+	return payload, nil
+}
+
+// Close releases the codec's compression resources
+func (vc *valueCodec) Close() {
+	vc.encoder.Close()
+	vc.decoder.Close()
+}
+
+// isEnveloped reports whether a stored value has an envelope header
+func isEnveloped(data []byte) bool {
+	return len(data) >= envelopeHeader && data[0] == envelopeMagic
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func testCodecResult() *models.MonitorResult {
+	return &models.MonitorResult{
+		Monitor:   "api",
+		Type:      models.MonitorTypeHTTP,
+		Group:     "core",
+		Status:    models.StatusUp,
+		Duration:  120 * time.Millisecond,
+		Timestamp: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		HTTPResult: &models.HTTPResult{
+			StatusCode:   200,
+			ResponseTime: 118 * time.Millisecond,
+			ResponseSize: 512,
+			Protocol:     "HTTP/2.0",
+		},
+	}
+}
+
+func TestValueCodecRoundTrip(t *testing.T) {
+	legacy, err := json.Marshal(testCodecResult())
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+
+	tests := []struct {
+		encoding    string
+		compression string
+		wantCodec   byte
+	}{
+		{encoding: "", compression: "", wantCodec: codecJSONZstd},
+		{encoding: EncodingMsgpack, compression: CompressionZstd, wantCodec: codecMsgpackZstd},
+		{encoding: EncodingMsgpack, compression: CompressionNone, wantCodec: codecMsgpack},
+		{encoding: EncodingJSON, compression: CompressionZstd, wantCodec: codecJSONZstd},
+		{encoding: EncodingJSON, compression: CompressionNone, wantCodec: codecJSON},
+	}
+
+	// Values written by every codec, which each codec must be able to read
+	var written [][]byte
+	for _, tt := range tests {
+		codec, err := newValueCodec(tt.encoding, tt.compression)
+		if err != nil {
+			t.Fatalf("failed to create codec: %v", err)
+		}
+		value, err := codec.Marshal(testCodecResult())
+		codec.Close()
+		if err != nil {
+			t.Fatalf("failed to marshal: %v", err)
+		}
+		written = append(written, value)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding+"/"+tt.compression, func(t *testing.T) {
+			codec, err := newValueCodec(tt.encoding, tt.compression)
+			if err != nil {
+				t.Fatalf("failed to create codec: %v", err)
+			}
+			defer codec.Close()
+
+			value, err := codec.Marshal(testCodecResult())
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			if !isEnveloped(value) || value[1] != envelopeVersion || value[2] != tt.wantCodec {
+				t.Fatalf("unexpected envelope header % x", value[:envelopeHeader])
+			}
+			switch tt.wantCodec {
+			case codecJSONZstd, codecMsgpackZstd:
+				if len(value) >= len(legacy)/2 {
+					t.Errorf("expected compressed value well under %d bytes, got %d", len(legacy), len(value))
+				}
+			case codecMsgpack:
+				if len(value) >= len(legacy) {
+					t.Errorf("expected MessagePack value under %d bytes, got %d", len(legacy), len(value))
+				}
+			}
+
+			// Current, legacy and other codecs' values all decode
+			for _, data := range append([][]byte{value, legacy}, written...) {
+				var decoded models.MonitorResult
+				if err := codec.Unmarshal(data, &decoded); err != nil {
+					t.Fatalf("failed to unmarshal codec %d: %v", data[2], err)
+				}
+				// MessagePack times come back in the local time zone
+				decoded.Timestamp = decoded.Timestamp.UTC()
+				if !reflect.DeepEqual(&decoded, testCodecResult()) {
+					t.Errorf("unexpected decoded result: %+v", decoded)
+				}
+			}
+
+			if _, changed, err := codec.Reencode(value, &models.MonitorResult{}); err != nil || changed {
+				t.Errorf("expected current value to be left alone, changed=%v err=%v", changed, err)
+			}
+			reencoded, changed, err := codec.Reencode(legacy, &models.MonitorResult{})
+			if err != nil || !changed || reencoded[2] != tt.wantCodec {
+				t.Errorf("expected legacy value to be re-encoded, changed=%v err=%v", changed, err)
+			}
+		})
+	}
+}
+
+func TestValueCodecErrors(t *testing.T) {
+	if _, err := newValueCodec("", "gzip"); err == nil {
+		t.Error("expected error for unsupported compression")
+	}
+	if _, err := newValueCodec("cbor", ""); err == nil {
+		t.Error("expected error for unsupported encoding")
+	}
+
+	codec, err := newValueCodec(EncodingMsgpack, CompressionZstd)
+	if err != nil {
+		t.Fatalf("failed to create codec: %v", err)
+	}
+	defer codec.Close()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "unknown version", data: []byte{envelopeMagic, 9, codecJSON, '{', '}'}},
+		{name: "unknown codec", data: []byte{envelopeMagic, envelopeVersion, 9, '{', '}'}},
+		{name: "corrupt zstd", data: []byte{envelopeMagic, envelopeVersion, codecJSONZstd, 1, 2, 3}},
+		{name: "truncated msgpack", data: []byte{envelopeMagic, envelopeVersion, codecMsgpack, 0x81, 0xa1, 'a'}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var result models.MonitorResult
+			if err := codec.Unmarshal(tt.data, &result); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestMsgpackDictionaryUnchanged(t *testing.T) {
+	// Values compressed with msgpackDictionaryID can only be read with the
+	// exact dictionary they were written with
+	sum := sha256.Sum256(msgpackDictionary)
+	if got := hex.EncodeToString(sum[:]); got != "6aed161ac7387d4b853fbdb5646a09466bd03d5ed5a6621513c68bd3581f1c6d" {
+		t.Errorf("msgpackDictionary changed (sha256 %s); add a new dictionary ID instead", got)
+	}
+}
@@ -2,8 +2,10 @@ package storage
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dgraph-io/badger/v4"
@@ -15,8 +17,25 @@ import (
 // BadgerStore manages persistent storage of monitor results using BadgerDB
 type BadgerStore struct {
 	db            *badger.DB
+	codec         *valueCodec
+	rolling       *rollingCounter // Recent per-hour counts for uptime; nil when read-only
 	logger        *logging.Logger
 	retentionDays int
+	readOnly      bool
+
+	failureRetention FailureRetention
+	failuresMu       sync.Mutex
+	lastFailure      map[string]time.Time // Newest failure per monitor, for the context that follows it
+
+	tenantIndex // Which monitors' keys go in a tenant's key range
+}
+
+// BadgerOptions contains optional BadgerStore settings
+type BadgerOptions struct {
+	Encoding         string // "json" (default) or "msgpack"
+	Compression      string // "zstd" (default) or "none"
+	ReadOnly         bool   // Open an existing database without writing to it
+	FailureRetention FailureRetention
 }
 
 const (
@@ -24,31 +43,152 @@ const (
 	latestKeyPrefix    = "latest"
 	aggregateKeyPrefix = "agg"
 	metaKeyPrefix      = "meta"
+	annotationPrefix   = "annotation"
+	userPrefix         = "user"
+	sessionPrefix      = "session"
+	tenantKeyPrefix    = "tenant"
 	timestampKeyWidth  = 20
+
+	// Monitor names in keys are prefixed with their length in bytes
+	monitorLengthWidth  = 4
+	maxKeyMonitorLength = 9999
+
+	// encodingMetaKey records the value encoding existing data was migrated to
+	encodingMetaKey = "encoding"
+
+	// keySchemaMetaKey records the key layout existing data was migrated to
+	keySchemaMetaKey = "key_schema"
+	keySchema        = "2"
 )
 
 func formatTimestampKey(ts int64) string {
 	return fmt.Sprintf("%0*d", timestampKeyWidth, ts)
 }
 
+// monitorKeySegment encodes a monitor name for use in keys. The length prefix
+// keeps names containing ':' from running into the timestamp or into another
+// monitor's key range.
+func monitorKeySegment(monitor string) string {
+	return fmt.Sprintf("%0*d:%s", monitorLengthWidth, len(monitor), monitor)
+}
+
+// parseMonitorKeySegment reads a monitor name encoded by monitorKeySegment
+// from the start of key, returning the name and the rest of the key
+func parseMonitorKeySegment(key []byte) (string, []byte, bool) {
+	if len(key) <= monitorLengthWidth || key[monitorLengthWidth] != ':' {
+		return "", nil, false
+	}
+	length, err := strconv.Atoi(string(key[:monitorLengthWidth]))
+	if err != nil || length < 0 {
+		return "", nil, false
+	}
+
+	rest := key[monitorLengthWidth+1:]
+	if len(rest) < length {
+		return "", nil, false
+	}
+	return string(rest[:length]), rest[length:], true
+}
+
+// tenantKey puts key in tenant's key range, "tenant:{tenant}:{key}" with the
+// tenant length-prefixed like monitor names. Keys of no tenant are unchanged.
+func tenantKey(tenant, key string) string {
+	if tenant == "" {
+		return key
+	}
+	return fmt.Sprintf("%s:%s:%s", tenantKeyPrefix, monitorKeySegment(tenant), key)
+}
+
+// splitTenantKey returns the tenant whose key range key is in, and the key
+// within that range
+func splitTenantKey(key []byte) (string, []byte) {
+	rest, ok := bytes.CutPrefix(key, []byte(tenantKeyPrefix+":"))
+	if !ok {
+		return "", key
+	}
+	tenant, rest, ok := parseMonitorKeySegment(rest)
+	if !ok || len(rest) == 0 || rest[0] != ':' {
+		return "", key
+	}
+	return tenant, rest[1:]
+}
+
+// monitorKey returns the start of monitor's keys of a kind, such as
+// "result:{monitor}", in the key range of the monitor's tenant
+func (bs *BadgerStore) monitorKey(kind, monitor string) string {
+	return tenantKey(bs.tenantOf(monitor), kind+":"+monitorKeySegment(monitor))
+}
+
 // NewBadgerStore creates a new BadgerDB-backed storage
 func NewBadgerStore(path string, retentionDays int, logger *logging.Logger) (*BadgerStore, error) {
+	return NewBadgerStoreWithOptions(path, retentionDays, BadgerOptions{}, logger)
+}
+
+// NewBadgerStoreWithOptions creates a new BadgerDB-backed storage with
+// optional settings. Values stored with a different encoding are migrated to
+// the configured one before the store is returned.
+func NewBadgerStoreWithOptions(path string, retentionDays int, options BadgerOptions, logger *logging.Logger) (*BadgerStore, error) {
 	if retentionDays <= 0 {
 		retentionDays = 30 // default to 30 days
 	}
 
-	opts := badger.DefaultOptions(path)
+	codec, err := newValueCodec(options.Encoding, options.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := badger.DefaultOptions(path).WithReadOnly(options.ReadOnly)
 	opts.Logger = &badgerLogger{logger: logger}
 
 	db, err := badger.Open(opts)
 	if err != nil {
+		codec.Close()
 		return nil, fmt.Errorf("failed to open badger db: %w", err)
 	}
 
 	store := &BadgerStore{
 		db:            db,
+		codec:         codec,
 		logger:        logger,
 		retentionDays: retentionDays,
+		readOnly:      options.ReadOnly,
+
+		failureRetention: options.FailureRetention,
+		lastFailure:      make(map[string]time.Time),
+	}
+	// Results stored by another process would be missing from the counters
+	if !options.ReadOnly {
+		store.rolling = newRollingCounter(time.Now())
+	}
+
+	if options.ReadOnly {
+		// Migrations write, so a read-only store must already be up to date
+		if stored, err := store.GetMetadata(keySchemaMetaKey); err != nil || string(stored) != keySchema {
+			db.Close()
+			codec.Close()
+			return nil, fmt.Errorf("store uses an older key layout; open it once without readOnly to migrate it")
+		}
+
+		logger.WithComponent("storage").
+			WithFields(map[string]interface{}{
+				"path": path,
+			}).
+			Info("BadgerDB storage opened read-only")
+
+		return store, nil
+	}
+
+	if err := store.migrateKeys(); err != nil {
+		db.Close()
+		codec.Close()
+		return nil, fmt.Errorf("failed to migrate keys: %w", err)
+	}
+
+	// Old values stay readable, so a failed migration is not fatal
+	if err := store.migrateEncoding(); err != nil {
+		logger.WithComponent("storage").
+			WithError(err).
+			Warn("Failed to migrate stored values to the current encoding")
 	}
 
 	// Start garbage collection
@@ -56,8 +196,11 @@ func NewBadgerStore(path string, retentionDays int, logger *logging.Logger) (*Ba
 
 	logger.WithComponent("storage").
 		WithFields(map[string]interface{}{
-			"path":          path,
-			"retentionDays": retentionDays,
+			"path":                 path,
+			"retentionDays":        retentionDays,
+			"encoding":             codec.Encoding(),
+			"compression":          codec.Name(),
+			"failureRetentionDays": options.FailureRetention.Days,
 		}).
 		Info("BadgerDB storage initialized")
 
@@ -66,21 +209,31 @@ func NewBadgerStore(path string, retentionDays int, logger *logging.Logger) (*Ba
 
 // StoreResult stores a monitor result with TTL
 func (bs *BadgerStore) StoreResult(result *models.MonitorResult) error {
+	if bs.readOnly {
+		return ErrReadOnly
+	}
 	if result == nil {
 		return fmt.Errorf("result cannot be nil")
 	}
 
-	// Generate key: result:{monitor_name}:{unix_nano_timestamp}
-	key := fmt.Sprintf("%s:%s:%s", resultKeyPrefix, result.Monitor, formatTimestampKey(result.Timestamp.UnixNano()))
+	if len(result.Monitor) > maxKeyMonitorLength {
+		return fmt.Errorf("monitor name longer than %d bytes", maxKeyMonitorLength)
+	}
+
+	// Generate key: [tenant:{tenant_length}:{tenant}:]result:{name_length}:{monitor_name}:{unix_nano_timestamp}
+	key := bs.monitorKey(resultKeyPrefix, result.Monitor) + ":" + formatTimestampKey(result.Timestamp.UnixNano())
 
-	// Marshal result to JSON
-	value, err := json.Marshal(result)
+	value, err := bs.codec.Marshal(result)
 	if err != nil {
 		return fmt.Errorf("failed to marshal result: %w", err)
 	}
 
-	// Calculate TTL
+	// Calculate TTL; failures and their context may be kept longer
 	ttl := time.Duration(bs.retentionDays) * 24 * time.Hour
+	keep := bs.keepAsFailure(result)
+	if keep {
+		ttl = time.Duration(bs.failureRetention.Days) * 24 * time.Hour
+	}
 
 	// Store with TTL
 	err = bs.db.Update(func(txn *badger.Txn) error {
@@ -91,9 +244,19 @@ func (bs *BadgerStore) StoreResult(result *models.MonitorResult) error {
 	if err != nil {
 		return fmt.Errorf("failed to store result: %w", err)
 	}
+	bs.rolling.Record(result.Monitor, result.Timestamp, result.Status, result.Weight())
+
+	// Results from before a failure were stored before anyone knew to keep them
+	if keep && result.Status == models.StatusDown && bs.failureRetention.Context > 0 {
+		if err := bs.extendRetention(result.Monitor, result.Timestamp.Add(-bs.failureRetention.Context), result.Timestamp, ttl); err != nil {
+			bs.logger.WithComponent("storage").
+				WithError(err).
+				Warn("Failed to extend retention of results before a failure")
+		}
+	}
 
 	// Also update the latest result cache
-	latestKey := fmt.Sprintf("%s:%s", latestKeyPrefix, result.Monitor)
+	latestKey := bs.monitorKey(latestKeyPrefix, result.Monitor)
 	err = bs.db.Update(func(txn *badger.Txn) error {
 		entry := badger.NewEntry([]byte(latestKey), value).WithTTL(ttl)
 		return txn.SetEntry(entry)
@@ -110,7 +273,7 @@ func (bs *BadgerStore) StoreResult(result *models.MonitorResult) error {
 
 // GetLatestResult retrieves the most recent result for a monitor
 func (bs *BadgerStore) GetLatestResult(monitor string) (*models.MonitorResult, error) {
-	latestKey := fmt.Sprintf("%s:%s", latestKeyPrefix, monitor)
+	latestKey := bs.monitorKey(latestKeyPrefix, monitor)
 
 	var result *models.MonitorResult
 	err := bs.db.View(func(txn *badger.Txn) error {
@@ -121,7 +284,7 @@ func (bs *BadgerStore) GetLatestResult(monitor string) (*models.MonitorResult, e
 
 		return item.Value(func(val []byte) error {
 			result = &models.MonitorResult{}
-			return json.Unmarshal(val, result)
+			return bs.codec.Unmarshal(val, result)
 		})
 	})
 
@@ -135,64 +298,108 @@ func (bs *BadgerStore) GetLatestResult(monitor string) (*models.MonitorResult, e
 	return result, nil
 }
 
-// GetResults retrieves results for a monitor within a time range
+// GetResults retrieves results for a monitor within a time range, newest first
 func (bs *BadgerStore) GetResults(monitor string, start, end time.Time, limit int) ([]*models.MonitorResult, error) {
 	if limit <= 0 {
 		limit = 1000 // default limit
 	}
 
-	prefix := []byte(fmt.Sprintf("%s:%s:", resultKeyPrefix, monitor))
-	startKey := []byte(fmt.Sprintf("%s:%s:%s", resultKeyPrefix, monitor, formatTimestampKey(start.UnixNano())))
-	endKey := []byte(fmt.Sprintf("%s:%s:%s", resultKeyPrefix, monitor, formatTimestampKey(end.UnixNano())))
-
 	var results []*models.MonitorResult
+	err := bs.StreamResults(monitor, start, end, true, func(result *models.MonitorResult) bool {
+		results = append(results, result)
+		return len(results) < limit
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get results: %w", err)
+	}
 
-	err := bs.db.View(func(txn *badger.Txn) error {
+	return results, nil
+}
+
+// StreamResults calls fn for each result of a monitor within a time range,
+// newest first when descending is set, until fn returns false. Results are
+// decoded one at a time, so ranges of any size are read in constant memory.
+func (bs *BadgerStore) StreamResults(monitor string, start, end time.Time, descending bool, fn func(*models.MonitorResult) bool) error {
+	return bs.scanResults(monitor, start, end, descending, func(val []byte) (bool, error) {
+		var result models.MonitorResult
+		if err := bs.codec.Unmarshal(val, &result); err != nil {
+			return true, err
+		}
+		return fn(&result), nil
+	})
+}
+
+// CountResults counts a monitor's results within a time range by status
+// without keeping them in memory
+func (bs *BadgerStore) CountResults(monitor string, start, end time.Time) (ResultCounts, error) {
+	var counts ResultCounts
+	err := bs.scanResults(monitor, start, end, false, func(val []byte) (bool, error) {
+		// Only the status and weight are needed, so skip decoding the rest of the result
+		var result struct {
+			Status       models.MonitorStatus `json:"status"`
+			SampleWeight int                  `json:"sample_weight"`
+		}
+		if err := bs.codec.Unmarshal(val, &result); err != nil {
+			return true, err
+		}
+		counts.AddN(result.Status, max(result.SampleWeight, 1))
+		return true, nil
+	})
+	if err != nil {
+		return ResultCounts{}, fmt.Errorf("failed to count results: %w", err)
+	}
+
+	return counts, nil
+}
+
+// scanResults calls visit with the stored value of each result of a monitor
+// within a time range, in key order or reversed, until visit returns false.
+// Values that fail to decode are logged and skipped.
+func (bs *BadgerStore) scanResults(monitor string, start, end time.Time, descending bool, visit func(val []byte) (bool, error)) error {
+	monitorKey := bs.monitorKey(resultKeyPrefix, monitor)
+	prefix := []byte(monitorKey + ":")
+	startKey := []byte(monitorKey + ":" + formatTimestampKey(start.UnixNano()))
+	endKey := []byte(monitorKey + ":" + formatTimestampKey(end.UnixNano()))
+
+	// Reverse iteration starts at the end of the range and seeks to the
+	// largest key not above it
+	seekKey, pastRange := startKey, func(key []byte) bool { return bytes.Compare(key, endKey) > 0 }
+	if descending {
+		seekKey, pastRange = endKey, func(key []byte) bool { return bytes.Compare(key, startKey) < 0 }
+	}
+
+	return bs.db.View(func(txn *badger.Txn) error {
 		opts := badger.DefaultIteratorOptions
 		opts.Prefix = prefix
+		opts.Reverse = descending
 		it := txn.NewIterator(opts)
 		defer it.Close()
 
-		// Seek to start position
-		for it.Seek(startKey); it.ValidForPrefix(prefix); it.Next() {
+		for it.Seek(seekKey); it.ValidForPrefix(prefix); it.Next() {
 			item := it.Item()
-			key := item.Key()
-
-			// Check if we've exceeded the end key
-			if bytes.Compare(key, endKey) > 0 {
-				break
-			}
-
-			// Check if we've hit the limit
-			if len(results) >= limit {
+			if pastRange(item.Key()) {
 				break
 			}
 
+			more := true
 			err := item.Value(func(val []byte) error {
-				var result models.MonitorResult
-				if err := json.Unmarshal(val, &result); err != nil {
-					return err
-				}
-				results = append(results, &result)
-				return nil
+				var err error
+				more, err = visit(val)
+				return err
 			})
-
 			if err != nil {
 				bs.logger.WithComponent("storage").
 					WithError(err).
 					Warn("Failed to unmarshal result")
 				continue
 			}
+			if !more {
+				break
+			}
 		}
 
 		return nil
 	})
-
-	if err != nil {
-		return nil, fmt.Errorf("failed to get results: %w", err)
-	}
-
-	return results, nil
 }
 
 // GetResultsByPeriod retrieves all results for a monitor within a time range (for aggregation)
@@ -203,28 +410,35 @@ func (bs *BadgerStore) GetResultsByPeriod(monitor string, start, end time.Time)
 
 // StoreAggregate stores an aggregate result
 func (bs *BadgerStore) StoreAggregate(agg *models.AggregateResult) error {
+	if bs.readOnly {
+		return ErrReadOnly
+	}
 	if agg == nil {
 		return fmt.Errorf("aggregate cannot be nil")
 	}
 
-	// Generate key: agg:{type}:{monitor_name}:{period_timestamp}
-	var key string
 	var ttl time.Duration
-
-	if agg.PeriodType == "hour" {
-		key = fmt.Sprintf("%s:hour:%s:%s", aggregateKeyPrefix, agg.Monitor, formatTimestampKey(agg.PeriodStart.Unix()))
+	switch agg.PeriodType {
+	case "hour":
 		// Hourly aggregates kept for 2x retention period
 		ttl = time.Duration(bs.retentionDays*2) * 24 * time.Hour
-	} else if agg.PeriodType == "day" {
-		key = fmt.Sprintf("%s:day:%s:%s", aggregateKeyPrefix, agg.Monitor, formatTimestampKey(agg.PeriodStart.Unix()))
+	case "day":
 		// Daily aggregates kept for 1 year
 		ttl = 365 * 24 * time.Hour
-	} else {
+	case "week":
+		// Weekly aggregates kept for 2 years
+		ttl = 2 * 365 * 24 * time.Hour
+	case "month":
+		// Monthly aggregates kept for 5 years
+		ttl = 5 * 365 * 24 * time.Hour
+	default:
 		return fmt.Errorf("invalid period type: %s", agg.PeriodType)
 	}
 
-	// Marshal aggregate to JSON
-	value, err := json.Marshal(agg)
+	// Generate key: [tenant:{tenant_length}:{tenant}:]agg:{type}:{name_length}:{monitor_name}:{period_timestamp}
+	key := bs.monitorKey(aggregateKeyPrefix+":"+agg.PeriodType, agg.Monitor) + ":" + formatTimestampKey(agg.PeriodStart.Unix())
+
+	value, err := bs.codec.Marshal(agg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal aggregate: %w", err)
 	}
@@ -244,13 +458,14 @@ func (bs *BadgerStore) StoreAggregate(agg *models.AggregateResult) error {
 
 // GetAggregates retrieves aggregates for a monitor within a time range
 func (bs *BadgerStore) GetAggregates(monitor string, periodType string, start, end time.Time) ([]*models.AggregateResult, error) {
-	if periodType != "hour" && periodType != "day" {
+	if !validPeriodType(periodType) {
 		return nil, fmt.Errorf("invalid period type: %s", periodType)
 	}
 
-	prefix := []byte(fmt.Sprintf("%s:%s:%s:", aggregateKeyPrefix, periodType, monitor))
-	startKey := []byte(fmt.Sprintf("%s:%s:%s:%s", aggregateKeyPrefix, periodType, monitor, formatTimestampKey(start.Unix())))
-	endKey := []byte(fmt.Sprintf("%s:%s:%s:%s", aggregateKeyPrefix, periodType, monitor, formatTimestampKey(end.Unix())))
+	monitorKey := bs.monitorKey(aggregateKeyPrefix+":"+periodType, monitor)
+	prefix := []byte(monitorKey + ":")
+	startKey := []byte(monitorKey + ":" + formatTimestampKey(start.Unix()))
+	endKey := []byte(monitorKey + ":" + formatTimestampKey(end.Unix()))
 
 	var aggregates []*models.AggregateResult
 
@@ -271,7 +486,7 @@ func (bs *BadgerStore) GetAggregates(monitor string, periodType string, start, e
 
 			err := item.Value(func(val []byte) error {
 				var agg models.AggregateResult
-				if err := json.Unmarshal(val, &agg); err != nil {
+				if err := bs.codec.Unmarshal(val, &agg); err != nil {
 					return err
 				}
 				aggregates = append(aggregates, &agg)
@@ -301,7 +516,23 @@ func (bs *BadgerStore) GetAggregatesByPeriod(monitor string, start, end time.Tim
 	return bs.GetAggregates(monitor, periodType, start, end)
 }
 
-// GetMonitorNames returns all monitor names that have stored results
+// SearchResults scans each monitor's results within the query's time range,
+// newest first, keeping those that match
+func (bs *BadgerStore) SearchResults(query ResultQuery) ([]*models.MonitorResult, error) {
+	monitors := query.Monitors
+	if len(monitors) == 0 {
+		names, err := bs.GetMonitorNames()
+		if err != nil {
+			return nil, err
+		}
+		monitors = names
+	}
+
+	return searchByStreaming(bs, monitors, query)
+}
+
+// GetMonitorNames returns all monitor names that have stored results, in
+// any tenant
 func (bs *BadgerStore) GetMonitorNames() ([]string, error) {
 	monitorNames := make(map[string]bool)
 
@@ -311,20 +542,19 @@ func (bs *BadgerStore) GetMonitorNames() ([]string, error) {
 		it := txn.NewIterator(opts)
 		defer it.Close()
 
-		prefix := []byte(resultKeyPrefix + ":")
-		for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
-			item := it.Item()
-			key := item.Key()
-
-			rest := key[len(prefix):]
-			colonIdx := bytes.LastIndexByte(rest, ':')
-			if colonIdx <= 0 {
-				continue
-			}
+		resultPrefix := []byte(resultKeyPrefix + ":")
+		for _, prefix := range [][]byte{resultPrefix, []byte(tenantKeyPrefix + ":")} {
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				_, key := splitTenantKey(it.Item().Key())
+				rest, ok := bytes.CutPrefix(key, resultPrefix)
+				if !ok {
+					continue
+				}
 
-			monitorName := string(rest[:colonIdx])
-			if monitorName != "" {
-				monitorNames[monitorName] = true
+				monitorName, _, ok := parseMonitorKeySegment(rest)
+				if ok && monitorName != "" {
+					monitorNames[monitorName] = true
+				}
 			}
 		}
 
@@ -345,6 +575,9 @@ func (bs *BadgerStore) GetMonitorNames() ([]string, error) {
 
 // SetMetadata stores metadata (e.g., last aggregation time)
 func (bs *BadgerStore) SetMetadata(key string, value []byte) error {
+	if bs.readOnly {
+		return ErrReadOnly
+	}
 	metaKey := fmt.Sprintf("%s:%s", metaKeyPrefix, key)
 
 	return bs.db.Update(func(txn *badger.Txn) error {
@@ -380,20 +613,341 @@ func (bs *BadgerStore) GetMetadata(key string) ([]byte, error) {
 	return value, nil
 }
 
+// StoreAnnotation stores an annotation with the same TTL as results
+func (bs *BadgerStore) StoreAnnotation(annotation *models.Annotation) error {
+	if bs.readOnly {
+		return ErrReadOnly
+	}
+	if annotation == nil {
+		return fmt.Errorf("annotation cannot be nil")
+	}
+
+	// Generate key: [tenant:{tenant_length}:{tenant}:]annotation:{unix_nano_timestamp}:{id}
+	key := tenantKey(bs.annotationTenant(annotation), fmt.Sprintf("%s:%s:%s", annotationPrefix, formatTimestampKey(annotation.Time.UnixNano()), annotation.ID))
+
+	value, err := bs.codec.Marshal(annotation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal annotation: %w", err)
+	}
+
+	ttl := time.Duration(bs.retentionDays) * 24 * time.Hour
+	err = bs.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(badger.NewEntry([]byte(key), value).WithTTL(ttl))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store annotation: %w", err)
+	}
+
+	return nil
+}
+
+// GetAnnotations retrieves annotations whose time falls within a range,
+// oldest first, from outside any tenant and from every current tenant
+func (bs *BadgerStore) GetAnnotations(start, end time.Time) ([]*models.Annotation, error) {
+	var annotations []*models.Annotation
+	for _, tenant := range append([]string{""}, bs.tenantNames()...) {
+		if err := bs.scanAnnotations(tenant, start, end, &annotations); err != nil {
+			return nil, fmt.Errorf("failed to get annotations: %w", err)
+		}
+	}
+
+	// Each tenant's annotations are in order; merge them
+	sort.SliceStable(annotations, func(i, j int) bool {
+		return annotations[i].Time.Before(annotations[j].Time)
+	})
+	return annotations, nil
+}
+
+// scanAnnotations appends the annotations in tenant's key range whose time
+// falls within a range to annotations, oldest first
+func (bs *BadgerStore) scanAnnotations(tenant string, start, end time.Time, annotations *[]*models.Annotation) error {
+	prefix := []byte(tenantKey(tenant, annotationPrefix+":"))
+	startKey := []byte(tenantKey(tenant, fmt.Sprintf("%s:%s", annotationPrefix, formatTimestampKey(start.UnixNano()))))
+	endKey := []byte(tenantKey(tenant, fmt.Sprintf("%s:%s;", annotationPrefix, formatTimestampKey(end.UnixNano()))))
+
+	return bs.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(startKey); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			if bytes.Compare(item.Key(), endKey) > 0 {
+				break
+			}
+
+			err := item.Value(func(val []byte) error {
+				var annotation models.Annotation
+				if err := bs.codec.Unmarshal(val, &annotation); err != nil {
+					return err
+				}
+				*annotations = append(*annotations, &annotation)
+				return nil
+			})
+			if err != nil {
+				bs.logger.WithComponent("storage").
+					WithError(err).
+					Warn("Failed to unmarshal annotation")
+			}
+		}
+		return nil
+	})
+}
+
 // Close gracefully closes the database
 func (bs *BadgerStore) Close() error {
 	bs.logger.WithComponent("storage").Info("Closing BadgerDB")
+	defer bs.codec.Close()
 	return bs.db.Close()
 }
 
+// migrateKeys moves data stored under the original key layout, which used bare
+// monitor names, to length-prefixed names. It is skipped once the store has
+// been migrated.
+func (bs *BadgerStore) migrateKeys() error {
+	stored, err := bs.GetMetadata(keySchemaMetaKey)
+	if err != nil {
+		return err
+	}
+	if string(stored) == keySchema {
+		return nil
+	}
+
+	batch := bs.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	migrated := 0
+	err = bs.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for _, keyPrefix := range []string{resultKeyPrefix, latestKeyPrefix, aggregateKeyPrefix + ":hour", aggregateKeyPrefix + ":day"} {
+			prefix := []byte(keyPrefix + ":")
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				item := it.Item()
+				newKey, ok := migratedKey(keyPrefix, item.Key()[len(prefix):])
+				if !ok {
+					continue
+				}
+
+				value, err := item.ValueCopy(nil)
+				if err != nil {
+					return err
+				}
+				entry := badger.NewEntry(newKey, value)
+				entry.ExpiresAt = item.ExpiresAt()
+				if err := batch.SetEntry(entry); err != nil {
+					return err
+				}
+				if err := batch.Delete(item.KeyCopy(nil)); err != nil {
+					return err
+				}
+				migrated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := batch.Flush(); err != nil {
+		return fmt.Errorf("failed to write migrated keys: %w", err)
+	}
+
+	if migrated > 0 {
+		bs.logger.WithComponent("storage").
+			WithFields(map[string]interface{}{
+				"keys":       migrated,
+				"key_schema": keySchema,
+			}).
+			Info("Migrated stored keys to the current key schema")
+	}
+
+	return bs.SetMetadata(keySchemaMetaKey, []byte(keySchema))
+}
+
+// migratedKey converts the part of an original layout key after keyPrefix,
+// "{monitor}" for latest keys and "{monitor}:{timestamp}" otherwise, to the
+// current layout
+func migratedKey(keyPrefix string, rest []byte) ([]byte, bool) {
+	if keyPrefix == latestKeyPrefix {
+		return []byte(fmt.Sprintf("%s:%s", keyPrefix, monitorKeySegment(string(rest)))), true
+	}
+
+	// Timestamps never contain ':', so the name runs up to the last one
+	colonIdx := bytes.LastIndexByte(rest, ':')
+	if colonIdx <= 0 {
+		return nil, false
+	}
+	monitor, timestamp := string(rest[:colonIdx]), string(rest[colonIdx+1:])
+	return []byte(fmt.Sprintf("%s:%s:%s", keyPrefix, monitorKeySegment(monitor), timestamp)), true
+}
+
+// storedValue returns a pointer to a zero value of the type stored under
+// key, for decoding it
+func storedValue(key []byte) interface{} {
+	_, key = splitTenantKey(key)
+	kind, _, _ := bytes.Cut(key, []byte(":"))
+	switch string(kind) {
+	case resultKeyPrefix, latestKeyPrefix:
+		return &models.MonitorResult{}
+	case aggregateKeyPrefix:
+		return &models.AggregateResult{}
+	case annotationPrefix:
+		return &models.Annotation{}
+	case userPrefix:
+		return &models.User{}
+	case sessionPrefix:
+		return &models.Session{}
+	default:
+		return &map[string]interface{}{}
+	}
+}
+
+// migrateEncoding rewrites values stored with an older envelope or a different
+// encoding or compression setting, keeping their expiry. It is skipped once the store has
+// been migrated to the current encoding.
+func (bs *BadgerStore) migrateEncoding() error {
+	current := fmt.Sprintf("v%d:%s", envelopeVersion, bs.codec.Name())
+	if bs.codec.Encoding() != EncodingJSON {
+		current = fmt.Sprintf("v%d:%s:%s", envelopeVersion, bs.codec.Encoding(), bs.codec.Name())
+	}
+	stored, err := bs.GetMetadata(encodingMetaKey)
+	if err != nil {
+		return err
+	}
+	if string(stored) == current {
+		return nil
+	}
+
+	batch := bs.db.NewWriteBatch()
+	defer batch.Cancel()
+
+	migrated := 0
+	err = bs.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for _, keyPrefix := range []string{resultKeyPrefix, latestKeyPrefix, aggregateKeyPrefix, annotationPrefix, userPrefix, sessionPrefix, tenantKeyPrefix} {
+			prefix := []byte(keyPrefix + ":")
+			for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+				item := it.Item()
+
+				var value []byte
+				var changed bool
+				err := item.Value(func(val []byte) error {
+					var err error
+					value, changed, err = bs.codec.Reencode(val, storedValue(item.Key()))
+					return err
+				})
+				if err != nil {
+					bs.logger.WithComponent("storage").
+						WithError(err).
+						Warn("Skipping value that could not be migrated")
+					continue
+				}
+				if !changed {
+					continue
+				}
+
+				entry := badger.NewEntry(item.KeyCopy(nil), value)
+				entry.ExpiresAt = item.ExpiresAt()
+				if err := batch.SetEntry(entry); err != nil {
+					return err
+				}
+				migrated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to migrate values: %w", err)
+	}
+	if err := batch.Flush(); err != nil {
+		return fmt.Errorf("failed to write migrated values: %w", err)
+	}
+
+	if migrated > 0 {
+		bs.logger.WithComponent("storage").
+			WithFields(map[string]interface{}{
+				"values":   migrated,
+				"encoding": current,
+			}).
+			Info("Migrated stored values to the current encoding")
+	}
+
+	return bs.SetMetadata(encodingMetaKey, []byte(current))
+}
+
 // Capabilities returns the capabilities of the BadgerDB storage backend
 func (bs *BadgerStore) Capabilities() BackendCapabilities {
 	return BackendCapabilities{
 		SupportsAggregation: true,
 		SupportsRetention:   true,
 		SupportsRawResults:  true,
-		ReadOnly:            false,
+		ReadOnly:            bs.readOnly,
+	}
+}
+
+// Stats reports the size of the database, the number of unexpired keys by
+// prefix and the time range of the stored results
+func (bs *BadgerStore) Stats() (StorageStats, error) {
+	lsm, vlog := bs.db.Size()
+	stats := StorageStats{
+		RetentionDays: bs.retentionDays,
+		SizeBytes:     lsm + vlog,
+		Counts:        make(map[string]int64),
+	}
+
+	var oldest, newest int64
+	err := bs.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.PrefetchValues = false // We only need keys
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		resultPrefix := []byte(resultKeyPrefix + ":")
+		for it.Rewind(); it.Valid(); it.Next() {
+			// Tenants' keys are counted with the kind of key they hold
+			_, key := splitTenantKey(it.Item().Key())
+			prefix, _, _ := bytes.Cut(key, []byte(":"))
+			stats.Counts[string(prefix)]++
+
+			if !bytes.HasPrefix(key, resultPrefix) {
+				continue
+			}
+			_, rest, ok := parseMonitorKeySegment(key[len(resultPrefix):])
+			if !ok || len(rest) < 2 {
+				continue
+			}
+			ts, err := strconv.ParseInt(string(rest[1:]), 10, 64)
+			if err != nil {
+				continue
+			}
+			if oldest == 0 || ts < oldest {
+				oldest = ts
+			}
+			if ts > newest {
+				newest = ts
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to count keys: %w", err)
+	}
+
+	if oldest != 0 {
+		oldestTime, newestTime := time.Unix(0, oldest).UTC(), time.Unix(0, newest).UTC()
+		stats.OldestResult, stats.NewestResult = &oldestTime, &newestTime
+	}
+
+	stats.LastRetentionCleanup, err = getMetadataTime(bs, retentionCleanupMetaKey)
+	if err != nil {
+		return stats, err
 	}
+	return stats, nil
 }
 
 // runGC runs garbage collection periodically
@@ -407,6 +961,15 @@ func (bs *BadgerStore) runGC() {
 			bs.logger.WithComponent("storage").
 				WithError(err).
 				Debug("Garbage collection completed with notice")
+			continue
+		}
+
+		// Expired entries are dropped by compaction; this pass reclaims
+		// their space in the value log
+		if err := setMetadataTime(bs, retentionCleanupMetaKey, time.Now()); err != nil {
+			bs.logger.WithComponent("storage").
+				WithError(err).
+				Debug("Failed to record retention cleanup")
 		}
 	}
 }
@@ -1,10 +1,19 @@
 package storage
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
 	"github.com/1broseidon/hallmonitor/internal/logging"
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
@@ -39,6 +48,11 @@ func createTestStoreWithRetention(t *testing.T, retentionDays int) (*BadgerStore
 	return store, tmpDir
 }
 
+// exoticMonitorNames are valid monitor names that are awkward to embed in keys
+// and queries. Several collided with each other or with the timestamp in
+// BadgerStore's original bare-name keys.
+var exoticMonitorNames = []string{"a", "a:1", "a:0", "a:b:c", "api:", ":api", "0005:a", "a;b", "ünïcödé", "with space", `quote"d\\`, "flux${x}", "percent%3A", "o'brien"}
+
 func createTestStore(t *testing.T) (*BadgerStore, string) {
 	return createTestStoreWithRetention(t, 7)
 }
@@ -320,7 +334,7 @@ func TestBadgerStore_StoreAggregateInvalidPeriod(t *testing.T) {
 		Monitor:     "test-monitor",
 		PeriodStart: now,
 		PeriodEnd:   now.Add(time.Hour),
-		PeriodType:  "week",
+		PeriodType:  "year",
 	})
 	if err == nil {
 		t.Fatal("Expected error when storing aggregate with invalid period type")
@@ -334,7 +348,7 @@ func TestBadgerStore_GetAggregatesInvalidPeriod(t *testing.T) {
 		os.RemoveAll(tmpDir)
 	}()
 
-	_, err := store.GetAggregates("test-monitor", "week", time.Now(), time.Now().Add(time.Hour))
+	_, err := store.GetAggregates("test-monitor", "year", time.Now(), time.Now().Add(time.Hour))
 	if err == nil {
 		t.Fatal("Expected error when querying aggregates with invalid period type")
 	}
@@ -351,3 +365,568 @@ func TestBadgerStore_DefaultRetentionApplied(t *testing.T) {
 		t.Fatalf("Expected default retention of 30 days, got %d", store.retentionDays)
 	}
 }
+
+func TestBadgerStore_Annotations(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	now := time.Now()
+	for i, text := range []string{"first", "second", "third"} {
+		annotation := &models.Annotation{
+			ID:      text,
+			Time:    now.Add(time.Duration(i-2) * time.Hour),
+			Monitor: "api",
+			Text:    text,
+			Tags:    []string{"deploy"},
+		}
+		if err := store.StoreAnnotation(annotation); err != nil {
+			t.Fatalf("Failed to store annotation: %v", err)
+		}
+	}
+
+	annotations, err := store.GetAnnotations(now.Add(-90*time.Minute), now)
+	if err != nil {
+		t.Fatalf("Failed to get annotations: %v", err)
+	}
+	if len(annotations) != 2 {
+		t.Fatalf("Expected 2 annotations, got %d", len(annotations))
+	}
+	if annotations[0].Text != "second" || annotations[1].Text != "third" {
+		t.Errorf("Expected annotations oldest first, got %q, %q", annotations[0].Text, annotations[1].Text)
+	}
+	if len(annotations[0].Tags) != 1 || annotations[0].Tags[0] != "deploy" {
+		t.Errorf("Expected tags to round-trip, got %v", annotations[0].Tags)
+	}
+}
+
+func TestBadgerStore_Tenants(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	now := time.Now()
+	store.SetTenants(Tenants{
+		Monitors: map[string]string{"api": "acme"},
+		Groups:   map[string]string{"acme-group": "acme"},
+	})
+	for _, monitor := range []string{"api", "web"} {
+		result := &models.MonitorResult{Monitor: monitor, Type: models.MonitorTypeHTTP, Status: models.StatusUp, Timestamp: now}
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+	for i, annotation := range []*models.Annotation{
+		{ID: "monitor", Time: now.Add(-3 * time.Minute), Monitor: "api", Text: "monitor"},
+		{ID: "group", Time: now.Add(-2 * time.Minute), Group: "acme-group", Text: "group"},
+		{ID: "global", Time: now.Add(-1 * time.Minute), Text: "global"},
+	} {
+		if err := store.StoreAnnotation(annotation); err != nil {
+			t.Fatalf("Failed to store annotation %d: %v", i, err)
+		}
+	}
+
+	names, err := store.GetMonitorNames()
+	if err != nil {
+		t.Fatalf("Failed to get monitor names: %v", err)
+	}
+	sort.Strings(names)
+	if !reflect.DeepEqual(names, []string{"api", "web"}) {
+		t.Errorf("Expected monitors of every tenant, got %v", names)
+	}
+	annotations, err := store.GetAnnotations(now.Add(-time.Hour), now)
+	if err != nil {
+		t.Fatalf("Failed to get annotations: %v", err)
+	}
+	if len(annotations) != 3 || annotations[0].ID != "monitor" || annotations[1].ID != "group" || annotations[2].ID != "global" {
+		t.Errorf("Expected annotations of every tenant oldest first, got %v", annotations)
+	}
+
+	// Moved to another tenant, the monitor starts a new history
+	store.SetTenants(Tenants{Monitors: map[string]string{"api": "other"}})
+	if latest, err := store.GetLatestResult("api"); err != nil || latest != nil {
+		t.Errorf("Expected no results for api in its new tenant, got %v, %v", latest, err)
+	}
+	if latest, err := store.GetLatestResult("web"); err != nil || latest == nil {
+		t.Errorf("Expected web to keep its results, got %v, %v", latest, err)
+	}
+
+	// Moved back, its history is there again
+	store.SetTenants(Tenants{Monitors: map[string]string{"api": "acme"}})
+	results, err := store.GetResults("api", now.Add(-time.Hour), now.Add(time.Minute), 10)
+	if err != nil {
+		t.Fatalf("Failed to get results: %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("Expected 1 result for api in acme, got %d", len(results))
+	}
+}
+
+func TestTenantsFromConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Monitoring.Groups = []models.MonitorGroup{
+		{Name: "acme-group", Tenant: "acme", Monitors: []models.Monitor{{Name: "api"}}},
+		{Name: "shared", Monitors: []models.Monitor{{Name: "web"}}},
+	}
+
+	tenants := TenantsFromConfig(cfg)
+	if !reflect.DeepEqual(tenants.Monitors, map[string]string{"api": "acme"}) {
+		t.Errorf("Unexpected monitor tenants: %v", tenants.Monitors)
+	}
+	if !reflect.DeepEqual(tenants.Groups, map[string]string{"acme-group": "acme"}) {
+		t.Errorf("Unexpected group tenants: %v", tenants.Groups)
+	}
+}
+
+func TestBadgerStore_MigratesLegacyValues(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Write a result the way older versions did: bare JSON with a TTL
+	result := testCodecResult()
+	legacy, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal result: %v", err)
+	}
+	key := []byte(fmt.Sprintf("%s:%s:%s", resultKeyPrefix, monitorKeySegment(result.Monitor), formatTimestampKey(result.Timestamp.UnixNano())))
+	err = store.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(badger.NewEntry(key, legacy).WithTTL(time.Hour))
+	})
+	if err != nil {
+		t.Fatalf("failed to write legacy value: %v", err)
+	}
+	// Forget the previous migration so reopening runs it again
+	if err := store.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(metaKeyPrefix + ":" + encodingMetaKey))
+	}); err != nil {
+		t.Fatalf("failed to clear encoding metadata: %v", err)
+	}
+	store.Close()
+
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	store, err = NewBadgerStore(tmpDir, 7, logger)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	err = store.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		if item.ExpiresAt() == 0 {
+			t.Error("expected migrated value to keep its TTL")
+		}
+		return item.Value(func(val []byte) error {
+			if !isEnveloped(val) || val[2] != codecJSONZstd {
+				t.Errorf("expected value to be migrated to zstd, got % x", val[:min(len(val), envelopeHeader)])
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("failed to read migrated value: %v", err)
+	}
+
+	results, err := store.GetResults(result.Monitor, result.Timestamp.Add(-time.Minute), result.Timestamp.Add(time.Minute), 10)
+	if err != nil || len(results) != 1 || results[0].HTTPResult == nil {
+		t.Fatalf("expected migrated result to be readable, got %v (err %v)", results, err)
+	}
+
+	encoding, _ := store.GetMetadata(encodingMetaKey)
+	if string(encoding) != "v1:zstd" {
+		t.Errorf("expected encoding metadata v1:zstd, got %q", encoding)
+	}
+}
+
+func TestBadgerStore_MigratesToMsgpack(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer os.RemoveAll(tmpDir)
+
+	result := testCodecResult()
+	if err := store.StoreResult(result); err != nil {
+		t.Fatalf("failed to store result: %v", err)
+	}
+	store.Close()
+
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	store, err := NewBadgerStoreWithOptions(tmpDir, 7, BadgerOptions{Encoding: EncodingMsgpack}, logger)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	key := []byte(fmt.Sprintf("%s:%s:%s", resultKeyPrefix, monitorKeySegment(result.Monitor), formatTimestampKey(result.Timestamp.UnixNano())))
+	err = store.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get(key)
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			if !isEnveloped(val) || val[2] != codecMsgpackZstd {
+				t.Errorf("expected value to be migrated to msgpack, got % x", val[:min(len(val), envelopeHeader)])
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("failed to read migrated value: %v", err)
+	}
+
+	results, err := store.GetResults(result.Monitor, result.Timestamp.Add(-time.Minute), result.Timestamp.Add(time.Minute), 10)
+	if err != nil || len(results) != 1 || results[0].HTTPResult == nil || results[0].HTTPResult.Protocol != "HTTP/2.0" {
+		t.Fatalf("expected migrated result to be readable, got %v (err %v)", results, err)
+	}
+
+	encoding, _ := store.GetMetadata(encodingMetaKey)
+	if string(encoding) != "v1:msgpack:zstd" {
+		t.Errorf("expected encoding metadata v1:msgpack:zstd, got %q", encoding)
+	}
+}
+
+func TestBadgerStore_ExoticMonitorNames(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	// Names that collided with each other or with the timestamp in bare-name keys
+	names := []string{"a", "a:1", "a:0", "a:b:c", "api:", ":api", "0005:a", "a;b", "ünïcödé", "with space", `quote"d\`}
+	base := time.Now().Truncate(time.Hour)
+
+	for i, name := range names {
+		for j := 0; j < i+1; j++ {
+			result := &models.MonitorResult{
+				Monitor:   name,
+				Type:      models.MonitorTypeTCP,
+				Status:    models.StatusUp,
+				Timestamp: base.Add(time.Duration(j) * time.Second),
+			}
+			if err := store.StoreResult(result); err != nil {
+				t.Fatalf("failed to store result for %q: %v", name, err)
+			}
+		}
+		agg := &models.AggregateResult{Monitor: name, PeriodType: "hour", PeriodStart: base, TotalChecks: i + 1}
+		if err := store.StoreAggregate(agg); err != nil {
+			t.Fatalf("failed to store aggregate for %q: %v", name, err)
+		}
+	}
+
+	for i, name := range names {
+		t.Run(name, func(t *testing.T) {
+			results, err := store.GetResults(name, base.Add(-time.Minute), base.Add(time.Hour), 100)
+			if err != nil {
+				t.Fatalf("failed to get results: %v", err)
+			}
+			if len(results) != i+1 {
+				t.Fatalf("expected %d results, got %d", i+1, len(results))
+			}
+			for _, result := range results {
+				if result.Monitor != name {
+					t.Errorf("got result for %q", result.Monitor)
+				}
+			}
+
+			latest, err := store.GetLatestResult(name)
+			if err != nil || latest == nil || latest.Monitor != name {
+				t.Errorf("expected latest result for %q, got %v (err %v)", name, latest, err)
+			}
+
+			aggregates, err := store.GetAggregates(name, "hour", base.Add(-time.Hour), base.Add(time.Hour))
+			if err != nil || len(aggregates) != 1 || aggregates[0].TotalChecks != i+1 {
+				t.Errorf("expected own aggregate, got %v (err %v)", aggregates, err)
+			}
+		})
+	}
+
+	monitorNames, err := store.GetMonitorNames()
+	if err != nil {
+		t.Fatalf("failed to get monitor names: %v", err)
+	}
+	sort.Strings(monitorNames)
+	want := append([]string(nil), names...)
+	sort.Strings(want)
+	if !reflect.DeepEqual(monitorNames, want) {
+		t.Errorf("expected monitor names %q, got %q", want, monitorNames)
+	}
+
+	if err := store.StoreResult(&models.MonitorResult{Monitor: strings.Repeat("x", maxKeyMonitorLength+1)}); err == nil {
+		t.Error("expected error for overlong monitor name")
+	}
+}
+
+func TestBadgerStore_MigratesLegacyKeys(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer os.RemoveAll(tmpDir)
+
+	// Write data under the original bare-name key layout
+	ts := time.Now().Truncate(time.Hour)
+	legacy := map[string]interface{}{
+		fmt.Sprintf("result:a:b:%s", formatTimestampKey(ts.UnixNano())): &models.MonitorResult{Monitor: "a:b", Status: models.StatusUp, Timestamp: ts},
+		fmt.Sprintf("result:a:%s", formatTimestampKey(ts.UnixNano())):   &models.MonitorResult{Monitor: "a", Status: models.StatusDown, Timestamp: ts},
+		"latest:a:b": &models.MonitorResult{Monitor: "a:b", Status: models.StatusUp, Timestamp: ts},
+		fmt.Sprintf("agg:hour:a:b:%s", formatTimestampKey(ts.Unix())): &models.AggregateResult{Monitor: "a:b", PeriodType: "hour", PeriodStart: ts, TotalChecks: 7},
+		fmt.Sprintf("agg:day:a:b:%s", formatTimestampKey(ts.Unix())):  &models.AggregateResult{Monitor: "a:b", PeriodType: "day", PeriodStart: ts, TotalChecks: 9},
+	}
+	err := store.db.Update(func(txn *badger.Txn) error {
+		for key, value := range legacy {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			if err := txn.SetEntry(badger.NewEntry([]byte(key), data).WithTTL(time.Hour)); err != nil {
+				return err
+			}
+		}
+		return txn.Delete([]byte(metaKeyPrefix + ":" + keySchemaMetaKey))
+	})
+	if err != nil {
+		t.Fatalf("failed to write legacy keys: %v", err)
+	}
+	store.Close()
+
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	store, err = NewBadgerStore(tmpDir, 7, logger)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	defer store.Close()
+
+	results, err := store.GetResults("a:b", ts.Add(-time.Minute), ts.Add(time.Minute), 10)
+	if err != nil || len(results) != 1 || results[0].Monitor != "a:b" {
+		t.Errorf("expected one migrated result for a:b, got %v (err %v)", results, err)
+	}
+	results, err = store.GetResults("a", ts.Add(-time.Minute), ts.Add(time.Minute), 10)
+	if err != nil || len(results) != 1 || results[0].Status != models.StatusDown {
+		t.Errorf("expected one migrated result for a, got %v (err %v)", results, err)
+	}
+	if latest, err := store.GetLatestResult("a:b"); err != nil || latest == nil {
+		t.Errorf("expected migrated latest result, got %v (err %v)", latest, err)
+	}
+	for periodType, total := range map[string]int{"hour": 7, "day": 9} {
+		aggregates, err := store.GetAggregates("a:b", periodType, ts.Add(-time.Hour), ts.Add(time.Hour))
+		if err != nil || len(aggregates) != 1 || aggregates[0].TotalChecks != total {
+			t.Errorf("expected migrated %s aggregate, got %v (err %v)", periodType, aggregates, err)
+		}
+	}
+
+	// Old keys are gone
+	err = store.db.View(func(txn *badger.Txn) error {
+		for key := range legacy {
+			if _, err := txn.Get([]byte(key)); err != badger.ErrKeyNotFound {
+				t.Errorf("expected legacy key %q to be removed, got %v", key, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to check legacy keys: %v", err)
+	}
+}
+
+func TestBadgerStore_StreamAndCountResults(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	// Ten results, every third one down
+	base := time.Now().Truncate(time.Second)
+	for i := 0; i < 10; i++ {
+		status := models.StatusUp
+		if i%3 == 0 {
+			status = models.StatusDown
+		}
+		result := &models.MonitorResult{Monitor: "stream", Status: status, Timestamp: base.Add(time.Duration(i) * time.Second)}
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result %d: %v", i, err)
+		}
+	}
+	start, end := base.Add(2*time.Second), base.Add(7*time.Second)
+
+	tests := []struct {
+		name       string
+		descending bool
+		stopAfter  int
+		wantFirst  time.Time
+		wantCount  int
+	}{
+		{name: "ascending", wantFirst: start, wantCount: 6},
+		{name: "descending", descending: true, wantFirst: end, wantCount: 6},
+		{name: "descending early stop", descending: true, stopAfter: 2, wantFirst: end, wantCount: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen []*models.MonitorResult
+			err := store.StreamResults("stream", start, end, tt.descending, func(result *models.MonitorResult) bool {
+				seen = append(seen, result)
+				return tt.stopAfter == 0 || len(seen) < tt.stopAfter
+			})
+			if err != nil {
+				t.Fatalf("Failed to stream results: %v", err)
+			}
+			if len(seen) != tt.wantCount {
+				t.Fatalf("Expected %d results, got %d", tt.wantCount, len(seen))
+			}
+			if !seen[0].Timestamp.Equal(tt.wantFirst) {
+				t.Errorf("Expected first result at %v, got %v", tt.wantFirst, seen[0].Timestamp)
+			}
+			for i := 1; i < len(seen); i++ {
+				if seen[i].Timestamp.After(seen[i-1].Timestamp) == tt.descending {
+					t.Fatalf("Results out of order at %d", i)
+				}
+			}
+		})
+	}
+
+	// GetResults keeps the newest results when limited
+	results, err := store.GetResults("stream", base, base.Add(time.Minute), 3)
+	if err != nil || len(results) != 3 || !results[0].Timestamp.Equal(base.Add(9*time.Second)) {
+		t.Errorf("Expected the 3 newest results, got %v (err %v)", results, err)
+	}
+
+	counts, err := store.CountResults("stream", base, base.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to count results: %v", err)
+	}
+	if counts != (ResultCounts{Total: 10, Up: 6, Down: 4}) {
+		t.Errorf("Unexpected counts: %+v", counts)
+	}
+	if uptime := counts.UptimePercent(); uptime != 60 {
+		t.Errorf("Expected 60%% uptime, got %v", uptime)
+	}
+
+	// A sampled result counts as every check it stands for
+	sampled := &models.MonitorResult{Monitor: "stream", Status: models.StatusUp, Timestamp: base.Add(10 * time.Second), SampleWeight: 5}
+	if err := store.StoreResult(sampled); err != nil {
+		t.Fatalf("Failed to store sampled result: %v", err)
+	}
+	counts, err = store.CountResults("stream", base, base.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to count results: %v", err)
+	}
+	if counts != (ResultCounts{Total: 15, Up: 11, Down: 4}) {
+		t.Errorf("Unexpected weighted counts: %+v", counts)
+	}
+}
+
+func TestBadgerStore_ReadOnly(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer os.RemoveAll(tmpDir)
+
+	ts := time.Now().Add(-time.Minute)
+	for i, status := range []models.MonitorStatus{models.StatusUp, models.StatusDown} {
+		if err := store.StoreResult(&models.MonitorResult{
+			Monitor:   "api",
+			Type:      models.MonitorTypeHTTP,
+			Status:    status,
+			Timestamp: ts.Add(time.Duration(i) * time.Second),
+		}); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+	store.Close()
+
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	store, err := NewBadgerStoreWithOptions(tmpDir, 7, BadgerOptions{ReadOnly: true}, logger)
+	if err != nil {
+		t.Fatalf("Failed to open store read-only: %v", err)
+	}
+	defer store.Close()
+
+	if !store.Capabilities().ReadOnly {
+		t.Error("Expected read-only capability")
+	}
+
+	latest, err := store.GetLatestResult("api")
+	if err != nil || latest == nil || latest.Status != models.StatusDown {
+		t.Errorf("Expected the stored latest result, got %v (err %v)", latest, err)
+	}
+
+	// Counts come from storage, not from results this process never saw
+	counts, err := store.CountUptime("api", ts.Add(-time.Hour), time.Now())
+	if err != nil || counts.Total != 2 || counts.Up != 1 {
+		t.Errorf("Expected 2 stored results with 1 up, got %+v (err %v)", counts, err)
+	}
+
+	writes := map[string]func() error{
+		"result": func() error {
+			return store.StoreResult(&models.MonitorResult{Monitor: "api", Status: models.StatusUp, Timestamp: time.Now()})
+		},
+		"aggregate": func() error {
+			return store.StoreAggregate(&models.AggregateResult{Monitor: "api", PeriodType: "hour", PeriodStart: ts})
+		},
+		"metadata": func() error {
+			return store.SetMetadata("key", []byte("value"))
+		},
+		"annotation": func() error {
+			return store.StoreAnnotation(&models.Annotation{ID: "a", Text: "deploy", Time: ts})
+		},
+	}
+	for name, write := range writes {
+		t.Run(name, func(t *testing.T) {
+			if err := write(); !errors.Is(err, ErrReadOnly) {
+				t.Errorf("Expected ErrReadOnly, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBadgerStore_Stats(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.RetentionDays != 7 || stats.OldestResult != nil || stats.LastRetentionCleanup != nil {
+		t.Errorf("Unexpected stats for an empty store: %+v", stats)
+	}
+
+	oldest := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	newest := time.Now().Truncate(time.Second)
+	for _, result := range []*models.MonitorResult{
+		{Monitor: "api", Status: models.StatusUp, Timestamp: newest},
+		{Monitor: "api", Status: models.StatusUp, Timestamp: oldest.Add(time.Hour)},
+		{Monitor: "db:pg1", Status: models.StatusDown, Timestamp: oldest},
+	} {
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+	if err := store.StoreAggregate(&models.AggregateResult{Monitor: "api", PeriodType: "hour", PeriodStart: oldest, PeriodEnd: oldest.Add(time.Hour)}); err != nil {
+		t.Fatalf("Failed to store aggregate: %v", err)
+	}
+	cleanup := time.Now().Truncate(time.Second)
+	if err := setMetadataTime(store, retentionCleanupMetaKey, cleanup); err != nil {
+		t.Fatalf("Failed to record cleanup: %v", err)
+	}
+
+	stats, err = store.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	for prefix, want := range map[string]int64{resultKeyPrefix: 3, latestKeyPrefix: 2, aggregateKeyPrefix: 1} {
+		if got := stats.Counts[prefix]; got != want {
+			t.Errorf("Expected %d %s keys, got %d", want, prefix, got)
+		}
+	}
+	if stats.OldestResult == nil || !stats.OldestResult.Equal(oldest) || stats.NewestResult == nil || !stats.NewestResult.Equal(newest) {
+		t.Errorf("Expected results from %v to %v, got %v to %v", oldest, newest, stats.OldestResult, stats.NewestResult)
+	}
+	if stats.LastRetentionCleanup == nil || !stats.LastRetentionCleanup.Equal(cleanup) {
+		t.Errorf("Expected the last cleanup at %v, got %v", cleanup, stats.LastRetentionCleanup)
+	}
+}
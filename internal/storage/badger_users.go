@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// SaveUser creates or replaces a user. Users never expire.
+func (bs *BadgerStore) SaveUser(user *models.User) error {
+	if bs.readOnly {
+		return ErrReadOnly
+	}
+	if user == nil || user.Username == "" {
+		return fmt.Errorf("user requires a username")
+	}
+
+	// Generate key: user:{username}
+	key := fmt.Sprintf("%s:%s", userPrefix, user.Username)
+
+	value, err := bs.codec.Marshal(user)
+	if err != nil {
+		return fmt.Errorf("failed to marshal user: %w", err)
+	}
+
+	err = bs.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key), value)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store user: %w", err)
+	}
+
+	return nil
+}
+
+// GetUser returns a user, or nil if there is none with that name
+func (bs *BadgerStore) GetUser(username string) (*models.User, error) {
+	var user *models.User
+	err := bs.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(fmt.Sprintf("%s:%s", userPrefix, username)))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			user = &models.User{}
+			return bs.codec.Unmarshal(val, user)
+		})
+	})
+
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ListUsers returns every user sorted by name
+func (bs *BadgerStore) ListUsers() ([]*models.User, error) {
+	prefix := []byte(userPrefix + ":")
+
+	var users []*models.User
+	err := bs.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			err := it.Item().Value(func(val []byte) error {
+				var user models.User
+				if err := bs.codec.Unmarshal(val, &user); err != nil {
+					return err
+				}
+				users = append(users, &user)
+				return nil
+			})
+			if err != nil {
+				bs.logger.WithComponent("storage").
+					WithError(err).
+					Warn("Failed to unmarshal user")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	sort.Slice(users, func(i, j int) bool { return users[i].Username < users[j].Username })
+	return users, nil
+}
+
+// DeleteUser removes a user. Their sessions are rejected once the user is gone.
+func (bs *BadgerStore) DeleteUser(username string) error {
+	if bs.readOnly {
+		return ErrReadOnly
+	}
+
+	err := bs.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(fmt.Sprintf("%s:%s", userPrefix, username)))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSession stores a session until it expires
+func (bs *BadgerStore) SaveSession(session *models.Session) error {
+	if bs.readOnly {
+		return ErrReadOnly
+	}
+	if session == nil || session.TokenHash == "" {
+		return fmt.Errorf("session requires a token hash")
+	}
+
+	// Generate key: session:{token_hash}
+	key := fmt.Sprintf("%s:%s", sessionPrefix, session.TokenHash)
+
+	value, err := bs.codec.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	err = bs.db.Update(func(txn *badger.Txn) error {
+		entry := badger.NewEntry([]byte(key), value)
+		entry.ExpiresAt = uint64(session.ExpiresAt.Unix())
+		return txn.SetEntry(entry)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store session: %w", err)
+	}
+
+	return nil
+}
+
+// GetSession returns an unexpired session, or nil if there is none
+func (bs *BadgerStore) GetSession(tokenHash string) (*models.Session, error) {
+	var session *models.Session
+	err := bs.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(fmt.Sprintf("%s:%s", sessionPrefix, tokenHash)))
+		if err != nil {
+			return err
+		}
+		return item.Value(func(val []byte) error {
+			session = &models.Session{}
+			return bs.codec.Unmarshal(val, session)
+		})
+	})
+
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	// Badger expires entries at second granularity
+	if !time.Now().Before(session.ExpiresAt) {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// DeleteSession removes a session
+func (bs *BadgerStore) DeleteSession(tokenHash string) error {
+	if bs.readOnly {
+		return ErrReadOnly
+	}
+
+	err := bs.db.Update(func(txn *badger.Txn) error {
+		return txn.Delete([]byte(fmt.Sprintf("%s:%s", sessionPrefix, tokenHash)))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+
+	return nil
+}
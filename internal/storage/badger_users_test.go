@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestBadgerStore_Users(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	if user, err := store.GetUser("alice"); err != nil || user != nil {
+		t.Fatalf("Expected no user, got %v, %v", user, err)
+	}
+
+	for _, name := range []string{"bob", "alice"} {
+		if err := store.SaveUser(&models.User{Username: name, PasswordHash: "hash-" + name, Tenant: "acme"}); err != nil {
+			t.Fatalf("Failed to save user: %v", err)
+		}
+	}
+
+	user, err := store.GetUser("alice")
+	if err != nil {
+		t.Fatalf("Failed to get user: %v", err)
+	}
+	if user == nil || user.PasswordHash != "hash-alice" || user.Tenant != "acme" {
+		t.Errorf("Expected alice to round-trip, got %+v", user)
+	}
+
+	users, err := store.ListUsers()
+	if err != nil {
+		t.Fatalf("Failed to list users: %v", err)
+	}
+	if len(users) != 2 || users[0].Username != "alice" || users[1].Username != "bob" {
+		t.Errorf("Expected alice and bob sorted, got %+v", users)
+	}
+
+	if err := store.DeleteUser("bob"); err != nil {
+		t.Fatalf("Failed to delete user: %v", err)
+	}
+	if user, _ := store.GetUser("bob"); user != nil {
+		t.Errorf("Expected bob to be deleted, got %+v", user)
+	}
+}
+
+func TestBadgerStore_Sessions(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	now := time.Now()
+	sessions := []*models.Session{
+		{TokenHash: "live", Username: "alice", CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+		{TokenHash: "expired", Username: "alice", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	}
+	for _, session := range sessions {
+		if err := store.SaveSession(session); err != nil {
+			t.Fatalf("Failed to save session: %v", err)
+		}
+	}
+
+	session, err := store.GetSession("live")
+	if err != nil {
+		t.Fatalf("Failed to get session: %v", err)
+	}
+	if session == nil || session.Username != "alice" {
+		t.Errorf("Expected alice's session, got %+v", session)
+	}
+
+	if session, _ := store.GetSession("expired"); session != nil {
+		t.Errorf("Expected expired session to be gone, got %+v", session)
+	}
+
+	if err := store.DeleteSession("live"); err != nil {
+		t.Fatalf("Failed to delete session: %v", err)
+	}
+	if session, _ := store.GetSession("live"); session != nil {
+		t.Errorf("Expected session to be deleted, got %+v", session)
+	}
+}
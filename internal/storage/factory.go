@@ -21,15 +21,8 @@ const (
 	BackendInfluxDB BackendType = "influxdb"
 )
 
-// NewStore creates a new storage backend based on configuration
-func NewStore(cfg *config.StorageConfig, logger *logging.Logger) (ResultStore, error) {
-	if cfg == nil {
-		return nil, fmt.Errorf("storage config cannot be nil")
-	}
-	if logger == nil {
-		return nil, fmt.Errorf("logger cannot be nil")
-	}
-
+// ConfiguredBackend returns the backend type a storage configuration selects
+func ConfiguredBackend(cfg *config.StorageConfig) BackendType {
 	// Determine backend type, defaulting to "badger" for backward compatibility
 	backendType := BackendType(cfg.Backend)
 	if backendType == "" {
@@ -41,7 +34,19 @@ func NewStore(cfg *config.StorageConfig, logger *logging.Logger) (ResultStore, e
 			backendType = BackendNone
 		}
 	}
+	return backendType
+}
+
+// NewStore creates a new storage backend based on configuration
+func NewStore(cfg *config.StorageConfig, logger *logging.Logger) (ResultStore, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("storage config cannot be nil")
+	}
+	if logger == nil {
+		return nil, fmt.Errorf("logger cannot be nil")
+	}
 
+	backendType := ConfiguredBackend(cfg)
 	switch backendType {
 	case BackendNone:
 		logger.Info("Using NoOp storage - metrics only via Prometheus")
@@ -61,7 +66,12 @@ func NewStore(cfg *config.StorageConfig, logger *logging.Logger) (ResultStore, e
 			retentionDays = cfg.RetentionDays //nolint:staticcheck // Intentional use of deprecated field for backward compatibility
 		}
 
-		return NewBadgerStore(path, retentionDays, logger)
+		return NewBadgerStoreWithOptions(path, retentionDays, BadgerOptions{
+			Encoding:         cfg.Badger.Encoding,
+			Compression:      cfg.Badger.Compression,
+			ReadOnly:         cfg.ReadOnly,
+			FailureRetention: NewFailureRetention(cfg.FailureRetention),
+		}, logger)
 
 	case BackendPostgres:
 		logger.Info("Using PostgreSQL storage")
@@ -75,15 +85,20 @@ func NewStore(cfg *config.StorageConfig, logger *logging.Logger) (ResultStore, e
 			cfg.Postgres.SSLMode,
 		)
 
-		return NewPostgresStore(connString, cfg.Postgres.RetentionDays, logger)
+		return NewPostgresStoreWithOptions(connString, cfg.Postgres.RetentionDays, PostgresOptions{
+			Timescale:        cfg.Postgres.Timescale,
+			ReadOnly:         cfg.ReadOnly,
+			FailureRetention: NewFailureRetention(cfg.FailureRetention),
+		}, logger)
 
 	case BackendInfluxDB:
 		logger.Info("Using InfluxDB storage")
-		return NewInfluxDBStore(
+		return NewInfluxDBStoreWithOptions(
 			cfg.InfluxDB.URL,
 			cfg.InfluxDB.Token,
 			cfg.InfluxDB.Org,
 			cfg.InfluxDB.Bucket,
+			InfluxDBOptions{Version: cfg.InfluxDB.Version, ReadOnly: cfg.ReadOnly},
 			logger,
 		)
 
@@ -0,0 +1,25 @@
+package storage
+
+import "testing"
+
+func TestEscapeFluxString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "api", want: "api"},
+		{input: `say "hi"`, want: `say \"hi\"`},
+		{input: `C:\path`, want: `C:\\path`},
+		{input: "cost${x}", want: `cost\${x}`},
+		{input: "$5 {x}", want: "$5 {x}"},
+		{input: `\${x}`, want: `\\\${x}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := escapeFluxString(tt.input); got != tt.want {
+				t.Errorf("escapeFluxString(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
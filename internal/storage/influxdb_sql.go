@@ -0,0 +1,371 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// InfluxDB versions for InfluxDBOptions.Version
+const (
+	InfluxVersionAuto = "auto" // Detect from the server's /ping response
+	InfluxVersion2    = "2"    // InfluxDB 2.x, queried with Flux
+	InfluxVersion3    = "3"    // InfluxDB 3.x, queried with SQL
+)
+
+// InfluxDBOptions holds optional InfluxDBStore settings
+type InfluxDBOptions struct {
+	Version  string // "auto" (default), "2", or "3"
+	ReadOnly bool   // Reject writes; the token only needs read access
+}
+
+// sqlQueryTimeout bounds a single SQL query
+const sqlQueryTimeout = 30 * time.Second
+
+// detectInfluxVersion asks the server which major version it runs, falling
+// back to 2 when it does not say
+func detectInfluxVersion(ctx context.Context, client *http.Client, url, token string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(url, "/")+"/ping", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create ping request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("influxdb ping failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("influxdb ping failed: %s", resp.Status)
+	}
+
+	// 2.x reports its version in a header; 3.x also returns it in the body
+	version := resp.Header.Get("X-Influxdb-Version")
+	if version == "" {
+		var body struct {
+			Version string `json:"version"`
+		}
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&body); err == nil {
+			version = body.Version
+		}
+	}
+
+	if strings.HasPrefix(strings.TrimPrefix(version, "v"), "3") {
+		return InfluxVersion3, nil
+	}
+	return InfluxVersion2, nil
+}
+
+// influxSQLClient runs SQL queries against the InfluxDB 3 HTTP query API
+type influxSQLClient struct {
+	url      string
+	token    string
+	database string
+	http     *http.Client
+}
+
+// newInfluxSQLClient creates a SQL client for a database (a bucket in 2.x terms)
+func newInfluxSQLClient(url, token, database string, client *http.Client) *influxSQLClient {
+	return &influxSQLClient{
+		url:      strings.TrimRight(url, "/"),
+		token:    token,
+		database: database,
+		http:     client,
+	}
+}
+
+// Query runs a SQL query and returns its rows as column-to-value maps.
+// Numbers are returned as json.Number.
+func (c *influxSQLClient) Query(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	body, err := json.Marshal(map[string]string{
+		"db":     c.database,
+		"q":      query,
+		"format": "json",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sqlQueryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url+"/api/v3/query_sql", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create query request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sql query failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("sql query failed: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+
+	var rows []map[string]interface{}
+	if err := decoder.Decode(&rows); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to decode query response: %w", err)
+	}
+	return rows, nil
+}
+
+// sqlMonitorCondition matches the results of monitor, in its tenant when it
+// belongs to one. The tenant column only exists once a tenant's result has
+// been written, so monitors of no tenant do not refer to it.
+func (is *InfluxDBStore) sqlMonitorCondition(monitor string) string {
+	condition := fmt.Sprintf("monitor = '%s'", escapeSQLString(monitor))
+	if tenant := is.tenantOf(monitor); tenant != "" {
+		condition += fmt.Sprintf(" AND tenant = '%s'", escapeSQLString(tenant))
+	}
+	return condition
+}
+
+// sqlGetLatestResult is GetLatestResult for InfluxDB 3
+func (is *InfluxDBStore) sqlGetLatestResult(monitor string) (*models.MonitorResult, error) {
+	query := fmt.Sprintf(`
+		SELECT * FROM monitor_result
+		WHERE %s AND time >= now() - INTERVAL '24 hours'
+		ORDER BY time DESC
+		LIMIT 1
+	`, is.sqlMonitorCondition(monitor))
+
+	rows, err := is.sql.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query latest result: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil // No results found
+	}
+	return rowToMonitorResult(rows[0]), nil
+}
+
+// sqlGetResults is GetResults for InfluxDB 3
+func (is *InfluxDBStore) sqlGetResults(monitor string, start, end time.Time, limit int) ([]*models.MonitorResult, error) {
+	query := fmt.Sprintf(`
+		SELECT * FROM monitor_result
+		WHERE %s AND time >= '%s' AND time < '%s'
+		ORDER BY time DESC
+		LIMIT %d
+	`, is.sqlMonitorCondition(monitor), sqlTime(start), sqlTime(end), limit)
+
+	rows, err := is.sql.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query results: %w", err)
+	}
+
+	results := make([]*models.MonitorResult, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, rowToMonitorResult(row))
+	}
+	return results, nil
+}
+
+// sqlGetAggregates is GetAggregates for InfluxDB 3
+func (is *InfluxDBStore) sqlGetAggregates(monitor, periodType string, start, end time.Time) ([]*models.AggregateResult, error) {
+	// Weeks are binned from a Monday; months vary in length, so are truncated
+	bin := "date_bin(INTERVAL '1 hour', time)"
+	switch periodType {
+	case "day":
+		bin = "date_bin(INTERVAL '1 day', time)"
+	case "week":
+		bin = "date_bin(INTERVAL '7 days', time, TIMESTAMP '1970-01-05T00:00:00Z')"
+	case "month":
+		bin = "date_trunc('month', time)"
+	}
+	period, _ := lookupPeriod(periodType)
+
+	query := fmt.Sprintf(`
+		SELECT
+			%s AS period_start,
+			COUNT(*) AS total,
+			SUM(CASE WHEN status = 'up' THEN 1 ELSE 0 END) AS up,
+			SUM(CASE WHEN status = 'down' THEN 1 ELSE 0 END) AS down,
+			AVG(response_time_ms) AS avg_rt,
+			MIN(response_time_ms) AS min_rt,
+			MAX(response_time_ms) AS max_rt
+		FROM monitor_result
+		WHERE %s AND time >= '%s' AND time < '%s'
+		GROUP BY 1
+		ORDER BY 1 DESC
+	`, bin, is.sqlMonitorCondition(monitor), sqlTime(start), sqlTime(end))
+
+	rows, err := is.sql.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query aggregates: %w", err)
+	}
+
+	aggregates := make([]*models.AggregateResult, 0, len(rows))
+	for _, row := range rows {
+		periodStart, ok := sqlTimeValue(row["period_start"])
+		if !ok {
+			continue
+		}
+
+		aggregates = append(aggregates, &models.AggregateResult{
+			Monitor:     monitor,
+			PeriodType:  periodType,
+			PeriodStart: periodStart,
+			PeriodEnd:   period.next(periodStart),
+			TotalChecks: int(sqlInt64(row["total"])),
+			UpChecks:    int(sqlInt64(row["up"])),
+			DownChecks:  int(sqlInt64(row["down"])),
+			AvgDuration: time.Duration(sqlInt64(row["avg_rt"])) * time.Millisecond,
+			MinDuration: time.Duration(sqlInt64(row["min_rt"])) * time.Millisecond,
+			MaxDuration: time.Duration(sqlInt64(row["max_rt"])) * time.Millisecond,
+		})
+	}
+	return aggregates, nil
+}
+
+// sqlGetMonitorNames is GetMonitorNames for InfluxDB 3
+func (is *InfluxDBStore) sqlGetMonitorNames() ([]string, error) {
+	query := `
+		SELECT DISTINCT monitor FROM monitor_result
+		WHERE time >= now() - INTERVAL '30 days'
+		ORDER BY monitor
+	`
+
+	rows, err := is.sql.Query(context.Background(), query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query monitor names: %w", err)
+	}
+
+	monitors := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if monitor, ok := row["monitor"].(string); ok {
+			monitors = append(monitors, monitor)
+		}
+	}
+	return monitors, nil
+}
+
+// sqlStats is Stats for InfluxDB 3
+func (is *InfluxDBStore) sqlStats() (StorageStats, error) {
+	stats := StorageStats{Counts: map[string]int64{"monitor_result": 0}}
+
+	query := `SELECT COUNT(*) AS results, MIN(time) AS oldest, MAX(time) AS newest FROM monitor_result`
+
+	rows, err := is.sql.Query(context.Background(), query)
+	if err != nil {
+		return stats, fmt.Errorf("failed to query stats: %w", err)
+	}
+	if len(rows) == 0 {
+		return stats, nil
+	}
+
+	stats.Counts["monitor_result"] = sqlInt64(rows[0]["results"])
+	if oldest, ok := sqlTimeValue(rows[0]["oldest"]); ok {
+		stats.OldestResult = &oldest
+	}
+	if newest, ok := sqlTimeValue(rows[0]["newest"]); ok {
+		stats.NewestResult = &newest
+	}
+	return stats, nil
+}
+
+// rowToMonitorResult converts a SQL result row to a MonitorResult
+func rowToMonitorResult(row map[string]interface{}) *models.MonitorResult {
+	result := &models.MonitorResult{}
+	if ts, ok := sqlTimeValue(row["time"]); ok {
+		result.Timestamp = ts
+	}
+
+	// Tags
+	if monitor, ok := row["monitor"].(string); ok {
+		result.Monitor = monitor
+	}
+	if monitorType, ok := row["type"].(string); ok {
+		result.Type = models.MonitorType(monitorType)
+	}
+	if status, ok := row["status"].(string); ok {
+		result.Status = models.MonitorStatus(status)
+	}
+
+	// Fields
+	if rt, ok := row["response_time_ms"]; ok && rt != nil {
+		result.Duration = time.Duration(sqlInt64(rt)) * time.Millisecond
+	}
+	if sc, ok := row["status_code"]; ok && sc != nil {
+		result.HTTPResult = &models.HTTPResult{
+			StatusCode:   int(sqlInt64(sc)),
+			ResponseTime: result.Duration,
+		}
+	}
+	if em, ok := row["error_message"].(string); ok {
+		result.Error = em
+	}
+
+	// Metadata from meta_ tags; rows without a tag have it as null
+	metadata := make(map[string]interface{})
+	for key, value := range row {
+		if strings.HasPrefix(key, "meta_") && value != nil {
+			metadata[strings.TrimPrefix(key, "meta_")] = value
+		}
+	}
+	if len(metadata) > 0 {
+		result.Metadata = metadata
+	}
+
+	return result
+}
+
+// escapeSQLString escapes a value for use inside a single-quoted SQL string
+func escapeSQLString(s string) string {
+	return strings.ReplaceAll(s, `'`, `''`)
+}
+
+// sqlTime formats a timestamp for comparison against the time column
+func sqlTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// sqlTimeValue parses a timestamp from a SQL result row. InfluxDB 3 returns
+// UTC timestamps without a zone suffix.
+func sqlTimeValue(v interface{}) (time.Time, bool) {
+	switch v := v.(type) {
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t.UTC(), true
+			}
+		}
+	case json.Number:
+		if ns, err := v.Int64(); err == nil {
+			return time.Unix(0, ns).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// sqlInt64 converts a numeric SQL value to int64, truncating floats
+func sqlInt64(v interface{}) int64 {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return int64(f)
+	}
+	return 0
+}
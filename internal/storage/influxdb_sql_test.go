@@ -0,0 +1,252 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestDetectInfluxVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		body    string
+		status  int
+		want    string
+		wantErr bool
+	}{
+		{name: "2.x header", header: "v2.7.4", status: http.StatusNoContent, want: InfluxVersion2},
+		{name: "3.x header", header: "3.1.0", status: http.StatusOK, want: InfluxVersion3},
+		{name: "3.x body", body: `{"version":"3.0.2","revision":"abc"}`, status: http.StatusOK, want: InfluxVersion3},
+		{name: "no version", status: http.StatusNoContent, want: InfluxVersion2},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/ping" {
+					t.Errorf("unexpected request to %s", r.URL.Path)
+				}
+				if tt.header != "" {
+					w.Header().Set("X-Influxdb-Version", tt.header)
+				}
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			got, err := detectInfluxVersion(context.Background(), server.Client(), server.URL, "token")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("detectInfluxVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("detectInfluxVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeInfluxDB3 serves /ping and canned SQL query responses, recording queries
+type fakeInfluxDB3 struct {
+	mu      sync.Mutex
+	queries []map[string]string
+}
+
+func (f *fakeInfluxDB3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/ping":
+		_, _ = w.Write([]byte(`{"version":"3.0.0"}`))
+		return
+	case "/api/v2/write":
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "/api/v3/query_sql":
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer secret" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var body map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.mu.Lock()
+	f.queries = append(f.queries, body)
+	f.mu.Unlock()
+
+	query := body["q"]
+	switch {
+	case strings.Contains(query, "date_bin"):
+		_, _ = w.Write([]byte(`[{"period_start":"2024-03-10T01:00:00","total":12,"up":11,"down":1,"avg_rt":42.5,"min_rt":10,"max_rt":90}]`))
+	case strings.Contains(query, "COUNT(*)"):
+		_, _ = w.Write([]byte(`[{"results":1440,"oldest":"2024-03-09T00:00:00","newest":"2024-03-10T01:02:03.5"}]`))
+	case strings.Contains(query, "DISTINCT"):
+		_, _ = w.Write([]byte(`[{"monitor":"api"},{"monitor":"it's"}]`))
+	case strings.Contains(query, "syntax error"):
+		http.Error(w, "Error while planning query", http.StatusBadRequest)
+	default:
+		_, _ = w.Write([]byte(`[{"time":"2024-03-10T01:02:03.5","monitor":"it's","type":"http","status":"down","response_time_ms":250,"status_code":503,"error_message":"bad gateway","meta_region":"eu","meta_zone":null}]`))
+	}
+}
+
+func (f *fakeInfluxDB3) lastQuery() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.queries[len(f.queries)-1]
+}
+
+func TestInfluxDBStore_SQL(t *testing.T) {
+	fake := &fakeInfluxDB3{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+
+	store, err := NewInfluxDBStore(server.URL, "secret", "hallmonitor", "monitor_results", logger)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	if store.sql == nil {
+		t.Fatal("Expected InfluxDB 3 to be detected")
+	}
+
+	t.Run("results", func(t *testing.T) {
+		start := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+		results, err := store.GetResults("it's", start, start.Add(time.Hour), 10)
+		if err != nil {
+			t.Fatalf("GetResults failed: %v", err)
+		}
+
+		query := fake.lastQuery()
+		if query["db"] != "monitor_results" {
+			t.Errorf("Expected the bucket as database, got %q", query["db"])
+		}
+		for _, want := range []string{"monitor = 'it''s'", "time >= '2024-03-10T00:00:00Z'", "LIMIT 10"} {
+			if !strings.Contains(query["q"], want) {
+				t.Errorf("Expected query to contain %q, got:\n%s", want, query["q"])
+			}
+		}
+
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results))
+		}
+		r := results[0]
+		wantTime := time.Date(2024, 3, 10, 1, 2, 3, 500000000, time.UTC)
+		if !r.Timestamp.Equal(wantTime) || r.Monitor != "it's" || r.Status != models.StatusDown || r.Error != "bad gateway" {
+			t.Errorf("Unexpected result: %+v", r)
+		}
+		if r.Duration != 250*time.Millisecond || r.HTTPResult == nil || r.HTTPResult.StatusCode != 503 {
+			t.Errorf("Unexpected fields: duration %v, http %+v", r.Duration, r.HTTPResult)
+		}
+		if meta, ok := r.Metadata.(map[string]interface{}); !ok || meta["region"] != "eu" || len(meta) != 1 {
+			t.Errorf("Unexpected metadata: %v", r.Metadata)
+		}
+	})
+
+	t.Run("tenant", func(t *testing.T) {
+		store.SetTenants(Tenants{Monitors: map[string]string{"it's": "acme"}})
+		defer store.SetTenants(Tenants{})
+
+		start := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+		if _, err := store.GetResults("it's", start, start.Add(time.Hour), 10); err != nil {
+			t.Fatalf("GetResults failed: %v", err)
+		}
+		if query := fake.lastQuery()["q"]; !strings.Contains(query, "monitor = 'it''s' AND tenant = 'acme'") {
+			t.Errorf("Expected query to filter by tenant, got:\n%s", query)
+		}
+	})
+
+	t.Run("aggregates", func(t *testing.T) {
+		start := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+		aggs, err := store.GetAggregates("api", "hour", start, start.Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("GetAggregates failed: %v", err)
+		}
+		if !strings.Contains(fake.lastQuery()["q"], "INTERVAL '1 hour'") {
+			t.Errorf("Expected hourly bins, got:\n%s", fake.lastQuery()["q"])
+		}
+		if len(aggs) != 1 {
+			t.Fatalf("Expected 1 aggregate, got %d", len(aggs))
+		}
+		agg := aggs[0]
+		if !agg.PeriodStart.Equal(start.Add(time.Hour)) || !agg.PeriodEnd.Equal(start.Add(2*time.Hour)) {
+			t.Errorf("Unexpected period %v - %v", agg.PeriodStart, agg.PeriodEnd)
+		}
+		if agg.TotalChecks != 12 || agg.UpChecks != 11 || agg.DownChecks != 1 || agg.AvgDuration != 42*time.Millisecond {
+			t.Errorf("Unexpected aggregate: %+v", agg)
+		}
+	})
+
+	t.Run("monitor names", func(t *testing.T) {
+		names, err := store.GetMonitorNames()
+		if err != nil {
+			t.Fatalf("GetMonitorNames failed: %v", err)
+		}
+		if len(names) != 2 || names[0] != "api" || names[1] != "it's" {
+			t.Errorf("Unexpected names: %v", names)
+		}
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		stats, err := store.Stats()
+		if err != nil {
+			t.Fatalf("Stats failed: %v", err)
+		}
+		if stats.Counts["monitor_result"] != 1440 {
+			t.Errorf("Unexpected stats: %+v", stats)
+		}
+		if stats.OldestResult == nil || !stats.OldestResult.Equal(time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("Unexpected oldest result %v", stats.OldestResult)
+		}
+		if stats.NewestResult == nil || !stats.NewestResult.Equal(time.Date(2024, 3, 10, 1, 2, 3, 500000000, time.UTC)) {
+			t.Errorf("Unexpected newest result %v", stats.NewestResult)
+		}
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		_, err := store.sql.Query(context.Background(), "syntax error")
+		if err == nil || !strings.Contains(err.Error(), "Error while planning query") {
+			t.Errorf("Expected the server's error message, got %v", err)
+		}
+	})
+}
+
+func TestEscapeSQLString(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "api", want: "api"},
+		{input: "it's", want: "it''s"},
+		{input: `C:\path`, want: `C:\path`},
+		{input: "''", want: "''''"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := escapeSQLString(tt.input); got != tt.want {
+				t.Errorf("escapeSQLString(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
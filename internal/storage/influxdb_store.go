@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"sort"
 	"strings"
 	"time"
@@ -15,41 +16,80 @@ import (
 	"github.com/1broseidon/hallmonitor/pkg/models"
 )
 
-// InfluxDBStore manages persistent storage of monitor results using InfluxDB
+// InfluxDBStore manages persistent storage of monitor results using InfluxDB.
+// Writes use the 2.x write API, which 3.x also accepts; reads use Flux on 2.x
+// and SQL on 3.x.
 type InfluxDBStore struct {
 	client     influxdb2.Client
 	writeAPI   api.WriteAPI
 	queryAPI   api.QueryAPI
+	sql        *influxSQLClient // Set on InfluxDB 3
+	readOnly   bool
 	bucket     string
 	org        string
 	logger     *logging.Logger
 	stopErr    chan struct{}
 	errStopped chan struct{}
+
+	tenantIndex // Which tenant tag a monitor's results carry
 }
 
-// NewInfluxDBStore creates an InfluxDB-backed storage
+// NewInfluxDBStore creates an InfluxDB-backed storage, detecting the server version
 func NewInfluxDBStore(url, token, org, bucket string, logger *logging.Logger) (*InfluxDBStore, error) {
-	client := influxdb2.NewClient(url, token)
+	return NewInfluxDBStoreWithOptions(url, token, org, bucket, InfluxDBOptions{}, logger)
+}
 
-	// Test connection
+// NewInfluxDBStoreWithOptions creates an InfluxDB-backed storage with the given options
+func NewInfluxDBStoreWithOptions(url, token, org, bucket string, options InfluxDBOptions, logger *logging.Logger) (*InfluxDBStore, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	health, err := client.Health(ctx)
-	if err != nil {
-		client.Close()
-		return nil, fmt.Errorf("influxdb health check failed: %w", err)
+	httpClient := &http.Client{Timeout: sqlQueryTimeout}
+
+	version := options.Version
+	switch version {
+	case "", InfluxVersionAuto, InfluxVersion2, InfluxVersion3:
+	default:
+		return nil, fmt.Errorf("unsupported influxdb version: %s (valid options: auto, 2, 3)", options.Version)
 	}
 
-	if health.Status != "pass" {
-		client.Close()
-		return nil, fmt.Errorf("influxdb not healthy: %s", health.Status)
+	// The ping detects the version and, since 3.x has no Flux health
+	// endpoint, doubles as its connection test
+	if version != InfluxVersion2 {
+		detected, err := detectInfluxVersion(ctx, httpClient, url, token)
+		if err != nil {
+			return nil, err
+		}
+		if version != InfluxVersion3 {
+			version = detected
+		}
+	}
+
+	client := influxdb2.NewClient(url, token)
+
+	var sqlClient *influxSQLClient
+	if version == InfluxVersion3 {
+		sqlClient = newInfluxSQLClient(url, token, bucket, httpClient)
+	} else {
+		// Test connection
+		health, err := client.Health(ctx)
+		if err != nil {
+			client.Close()
+			return nil, fmt.Errorf("influxdb health check failed: %w", err)
+		}
+
+		if health.Status != "pass" {
+			client.Close()
+			return nil, fmt.Errorf("influxdb not healthy: %s", health.Status)
+		}
 	}
 
 	store := &InfluxDBStore{
 		client:     client,
 		writeAPI:   client.WriteAPI(org, bucket),
 		queryAPI:   client.QueryAPI(org),
+		sql:        sqlClient,
+		readOnly:   options.ReadOnly,
 		bucket:     bucket,
 		org:        org,
 		logger:     logger,
@@ -62,10 +102,12 @@ func NewInfluxDBStore(url, token, org, bucket string, logger *logging.Logger) (*
 
 	logger.WithComponent("storage").
 		WithFields(map[string]interface{}{
-			"backend": "influxdb",
-			"url":     url,
-			"org":     org,
-			"bucket":  bucket,
+			"backend":  "influxdb",
+			"url":      url,
+			"org":      org,
+			"bucket":   bucket,
+			"version":  version,
+			"readOnly": options.ReadOnly,
 		}).
 		Info("InfluxDB storage initialized successfully")
 
@@ -90,6 +132,9 @@ func (is *InfluxDBStore) listenForWriteErrors() {
 
 // StoreResult stores a monitor result
 func (is *InfluxDBStore) StoreResult(result *models.MonitorResult) error {
+	if is.readOnly {
+		return ErrReadOnly
+	}
 	if result == nil {
 		return fmt.Errorf("result cannot be nil")
 	}
@@ -102,6 +147,11 @@ func (is *InfluxDBStore) StoreResult(result *models.MonitorResult) error {
 		AddField("response_time_ms", result.Duration.Milliseconds()).
 		SetTime(result.Timestamp)
 
+	// Results of no tenant have no tenant tag
+	if tenant := is.tenantOf(result.Monitor); tenant != "" {
+		p.AddTag("tenant", tenant)
+	}
+
 	// Add optional fields
 	if result.HTTPResult != nil && result.HTTPResult.StatusCode > 0 {
 		p.AddField("status_code", result.HTTPResult.StatusCode)
@@ -126,17 +176,31 @@ func (is *InfluxDBStore) StoreResult(result *models.MonitorResult) error {
 	return nil
 }
 
+// fluxMonitorPredicate matches the results of monitor, in its tenant when it
+// belongs to one
+func (is *InfluxDBStore) fluxMonitorPredicate(monitor string) string {
+	predicate := fmt.Sprintf(`r.monitor == "%s"`, escapeFluxString(monitor))
+	if tenant := is.tenantOf(monitor); tenant != "" {
+		predicate += fmt.Sprintf(` and r.tenant == "%s"`, escapeFluxString(tenant))
+	}
+	return predicate
+}
+
 // GetLatestResult retrieves the most recent result for a monitor
 func (is *InfluxDBStore) GetLatestResult(monitor string) (*models.MonitorResult, error) {
+	if is.sql != nil {
+		return is.sqlGetLatestResult(monitor)
+	}
+
 	query := fmt.Sprintf(`
 		from(bucket: "%s")
 		|> range(start: -24h)
 		|> filter(fn: (r) => r._measurement == "monitor_result")
-		|> filter(fn: (r) => r.monitor == "%s")
+		|> filter(fn: (r) => %s)
 		|> sort(columns: ["_time"], desc: true)
 		|> limit(n: 1)
 		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
-	`, is.bucket, escapeFluxString(monitor))
+	`, is.bucket, is.fluxMonitorPredicate(monitor))
 
 	result, err := is.queryAPI.Query(context.Background(), query)
 	if err != nil {
@@ -160,16 +224,19 @@ func (is *InfluxDBStore) GetResults(monitor string, start, end time.Time, limit
 	if limit <= 0 {
 		limit = 1000 // default limit
 	}
+	if is.sql != nil {
+		return is.sqlGetResults(monitor, start, end, limit)
+	}
 
 	query := fmt.Sprintf(`
 		from(bucket: "%s")
 		|> range(start: %s, stop: %s)
 		|> filter(fn: (r) => r._measurement == "monitor_result")
-		|> filter(fn: (r) => r.monitor == "%s")
+		|> filter(fn: (r) => %s)
 		|> sort(columns: ["_time"], desc: true)
 		|> limit(n: %d)
 		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
-	`, is.bucket, start.Format(time.RFC3339), end.Format(time.RFC3339), escapeFluxString(monitor), limit)
+	`, is.bucket, start.Format(time.RFC3339), end.Format(time.RFC3339), is.fluxMonitorPredicate(monitor), limit)
 
 	queryResult, err := is.queryAPI.Query(context.Background(), query)
 	if err != nil {
@@ -192,16 +259,23 @@ func (is *InfluxDBStore) GetResults(monitor string, start, end time.Time, limit
 
 // GetAggregates retrieves aggregates for a monitor within a time range
 func (is *InfluxDBStore) GetAggregates(monitor, periodType string, start, end time.Time) ([]*models.AggregateResult, error) {
-	if periodType != "hour" && periodType != "day" {
+	if !validPeriodType(periodType) {
 		return nil, fmt.Errorf("invalid period type: %s", periodType)
 	}
+	if is.sql != nil {
+		return is.sqlGetAggregates(monitor, periodType, start, end)
+	}
 
-	// Determine window duration for Flux
-	var window string
-	if periodType == "hour" {
-		window = "1h"
-	} else {
+	// Determine window duration for Flux; weeks are shifted from the epoch's
+	// Thursday to start on Monday
+	window, offset := "1h", "0s"
+	switch periodType {
+	case "day":
 		window = "1d"
+	case "week":
+		window, offset = "1w", "-3d"
+	case "month":
+		window = "1mo"
 	}
 
 	// Use Flux to compute aggregations on the fly
@@ -209,9 +283,9 @@ func (is *InfluxDBStore) GetAggregates(monitor, periodType string, start, end ti
 		from(bucket: "%s")
 		|> range(start: %s, stop: %s)
 		|> filter(fn: (r) => r._measurement == "monitor_result")
-		|> filter(fn: (r) => r.monitor == "%s")
+		|> filter(fn: (r) => %s)
 		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
-		|> window(every: %s)
+		|> window(every: %s, offset: %s)
 		|> group(columns: ["_start", "_stop", "monitor"])
 		|> reduce(
 			identity: {
@@ -232,7 +306,7 @@ func (is *InfluxDBStore) GetAggregates(monitor, periodType string, start, end ti
 				monitor: r.monitor
 			})
 		)
-	`, is.bucket, start.Format(time.RFC3339), end.Format(time.RFC3339), escapeFluxString(monitor), window)
+	`, is.bucket, start.Format(time.RFC3339), end.Format(time.RFC3339), is.fluxMonitorPredicate(monitor), window, offset)
 
 	queryResult, err := is.queryAPI.Query(context.Background(), query)
 	if err != nil {
@@ -296,6 +370,10 @@ func (is *InfluxDBStore) StoreAggregate(agg *models.AggregateResult) error {
 
 // GetMonitorNames returns all monitor names that have stored results
 func (is *InfluxDBStore) GetMonitorNames() ([]string, error) {
+	if is.sql != nil {
+		return is.sqlGetMonitorNames()
+	}
+
 	query := fmt.Sprintf(`
 		import "influxdata/influxdb/schema"
 
@@ -363,10 +441,58 @@ func (is *InfluxDBStore) Capabilities() BackendCapabilities {
 		SupportsAggregation: true, // Can use Flux aggregation functions
 		SupportsRetention:   true, // Built-in retention policies
 		SupportsRawResults:  true,
-		ReadOnly:            false,
+		ReadOnly:            is.readOnly,
 	}
 }
 
+// Stats reports the number of stored results and their time range. InfluxDB
+// doesn't report its size, and expires data with the bucket's retention
+// policy rather than a cleanup.
+func (is *InfluxDBStore) Stats() (StorageStats, error) {
+	if is.sql != nil {
+		return is.sqlStats()
+	}
+
+	stats := StorageStats{Counts: map[string]int64{"monitor_result": 0}}
+
+	// Each result has one response time, so counting them counts results
+	query := fmt.Sprintf(`
+		data = from(bucket: "%s")
+		|> range(start: 0)
+		|> filter(fn: (r) => r._measurement == "monitor_result" and r._field == "response_time_ms")
+
+		data |> count() |> group() |> sum() |> yield(name: "count")
+		data |> first() |> group() |> sort(columns: ["_time"]) |> limit(n: 1) |> yield(name: "oldest")
+		data |> last() |> group() |> sort(columns: ["_time"], desc: true) |> limit(n: 1) |> yield(name: "newest")
+	`, is.bucket)
+
+	queryResult, err := is.queryAPI.Query(context.Background(), query)
+	if err != nil {
+		return stats, fmt.Errorf("failed to query stats: %w", err)
+	}
+	defer queryResult.Close()
+
+	for queryResult.Next() {
+		record := queryResult.Record()
+		switch record.Result() {
+		case "count":
+			stats.Counts["monitor_result"] = getInt64FromRecord(record, "_value")
+		case "oldest":
+			oldest := record.Time()
+			stats.OldestResult = &oldest
+		case "newest":
+			newest := record.Time()
+			stats.NewestResult = &newest
+		}
+	}
+
+	if queryResult.Err() != nil {
+		return stats, fmt.Errorf("query error: %w", queryResult.Err())
+	}
+
+	return stats, nil
+}
+
 // recordToMonitorResult converts a Flux query record to a MonitorResult
 func (is *InfluxDBStore) recordToMonitorResult(record *query.FluxRecord) *models.MonitorResult {
 	result := &models.MonitorResult{
@@ -418,6 +544,8 @@ func (is *InfluxDBStore) recordToMonitorResult(record *query.FluxRecord) *models
 func escapeFluxString(s string) string {
 	s = strings.ReplaceAll(s, `\`, `\\`)
 	s = strings.ReplaceAll(s, `"`, `\"`)
+	// "${" starts string interpolation in Flux
+	s = strings.ReplaceAll(s, `${`, `\${`)
 	return s
 }
 
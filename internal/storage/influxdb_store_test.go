@@ -169,3 +169,36 @@ func TestInfluxDBStore_GetMonitorNames(t *testing.T) {
 		t.Logf("Warning: Expected monitor names, got %d (may be due to write latency)", len(names))
 	}
 }
+
+func TestInfluxDBStore_ExoticMonitorNames(t *testing.T) {
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+
+	url, token, org, bucket := getTestInfluxDBConfig()
+	store, err := NewInfluxDBStore(url, token, org, bucket, logger)
+	if err != nil {
+		t.Skipf("InfluxDB not available: %v", err)
+	}
+	defer store.Close()
+
+	for _, monitor := range exoticMonitorNames {
+		result := &models.MonitorResult{
+			Monitor:   monitor,
+			Type:      models.MonitorTypeTCP,
+			Status:    models.StatusUp,
+			Timestamp: time.Now(),
+		}
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result for %q: %v", monitor, err)
+		}
+	}
+
+	// Give InfluxDB time to process the writes
+	time.Sleep(1 * time.Second)
+
+	for _, monitor := range exoticMonitorNames {
+		latest, err := store.GetLatestResult(monitor)
+		if err != nil || latest == nil || latest.Monitor != monitor {
+			t.Errorf("Expected latest result for %q, got %v (err %v)", monitor, latest, err)
+		}
+	}
+}
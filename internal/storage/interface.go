@@ -12,7 +12,7 @@ type ResultStore interface {
 	// Core operations
 	StoreResult(result *models.MonitorResult) error
 	GetLatestResult(monitor string) (*models.MonitorResult, error)
-	GetResults(monitor string, start, end time.Time, limit int) ([]*models.MonitorResult, error)
+	GetResults(monitor string, start, end time.Time, limit int) ([]*models.MonitorResult, error) // Newest first
 
 	// Aggregation (optional - return ErrNotSupported if backend doesn't support)
 	GetAggregates(monitor, periodType string, start, end time.Time) ([]*models.AggregateResult, error)
@@ -28,13 +28,96 @@ type ResultStore interface {
 	Capabilities() BackendCapabilities
 }
 
+// AnnotationStore is implemented by backends that can persist annotations
+type AnnotationStore interface {
+	StoreAnnotation(annotation *models.Annotation) error
+	GetAnnotations(start, end time.Time) ([]*models.Annotation, error)
+}
+
+// UserStore is implemented by backends that can persist local user accounts
+// and their sessions
+type UserStore interface {
+	// SaveUser creates or replaces a user
+	SaveUser(user *models.User) error
+	// GetUser returns a user, or nil if there is none with that name
+	GetUser(username string) (*models.User, error)
+	// ListUsers returns every user sorted by name
+	ListUsers() ([]*models.User, error)
+	DeleteUser(username string) error
+
+	// SaveSession stores a session until it expires
+	SaveSession(session *models.Session) error
+	// GetSession returns an unexpired session, or nil if there is none
+	GetSession(tokenHash string) (*models.Session, error)
+	DeleteSession(tokenHash string) error
+}
+
+// ResultStreamer is implemented by backends that can read raw results without
+// loading a whole time range into memory
+type ResultStreamer interface {
+	// StreamResults calls fn for each result within a time range, newest
+	// first when descending is set, until fn returns false
+	StreamResults(monitor string, start, end time.Time, descending bool, fn func(*models.MonitorResult) bool) error
+
+	// CountResults counts results within a time range by status
+	CountResults(monitor string, start, end time.Time) (ResultCounts, error)
+}
+
+// ResultSearcher is implemented by backends that can search results across
+// monitors without loading them all
+type ResultSearcher interface {
+	// SearchResults returns results matching query, newest first
+	SearchResults(query ResultQuery) ([]*models.MonitorResult, error)
+}
+
+// UptimeCounter is implemented by backends that keep rollups of result counts,
+// so uptime over long ranges does not need raw scans
+type UptimeCounter interface {
+	CountUptime(monitor string, start, end time.Time) (ResultCounts, error)
+}
+
+// ResultCounts tallies results by status
+type ResultCounts struct {
+	Total int `json:"total_checks"`
+	Up    int `json:"up_checks"`
+	Down  int `json:"down_checks"`
+}
+
+// Add counts a result with the given status
+func (rc *ResultCounts) Add(status models.MonitorStatus) {
+	rc.AddN(status, 1)
+}
+
+// AddN counts n results with the given status, as for a sampled result that
+// stands for several checks
+func (rc *ResultCounts) AddN(status models.MonitorStatus, n int) {
+	rc.Total += n
+	switch status {
+	case models.StatusUp:
+		rc.Up += n
+	case models.StatusDown:
+		rc.Down += n
+	}
+}
+
+// UptimePercent returns the share of up results, or 0 when there are none
+func (rc ResultCounts) UptimePercent() float64 {
+	if rc.Total == 0 {
+		return 0
+	}
+	return float64(rc.Up) / float64(rc.Total) * 100.0
+}
+
 // BackendCapabilities describes what features a storage backend supports
 type BackendCapabilities struct {
-	SupportsAggregation bool
-	SupportsRetention   bool
-	SupportsRawResults  bool
-	ReadOnly            bool
+	SupportsAggregation bool `json:"supports_aggregation"`
+	SupportsRetention   bool `json:"supports_retention"`
+	SupportsRawResults  bool `json:"supports_raw_results"`
+	ReadOnly            bool `json:"read_only"`
 }
 
 // ErrNotSupported is returned when a backend doesn't support an operation
 var ErrNotSupported = errors.New("operation not supported by this backend")
+
+// ErrReadOnly is returned by writes to a backend opened read-only
+var ErrReadOnly = errors.New("storage is read-only")
@@ -0,0 +1,47 @@
+-- Initial schema. Statements are idempotent so databases created before
+-- migrations existed adopt this version without changes.
+
+-- Monitor results table
+CREATE TABLE IF NOT EXISTS monitor_results (
+	id BIGSERIAL PRIMARY KEY,
+	monitor VARCHAR(255) NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	timestamp TIMESTAMPTZ NOT NULL,
+	response_time_ms BIGINT,
+	status_code INTEGER,
+	error_message TEXT,
+	metadata JSONB,
+	created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Indexes for common queries
+CREATE INDEX IF NOT EXISTS idx_monitor_results_monitor ON monitor_results(monitor);
+CREATE INDEX IF NOT EXISTS idx_monitor_results_timestamp ON monitor_results(timestamp DESC);
+CREATE INDEX IF NOT EXISTS idx_monitor_results_monitor_timestamp ON monitor_results(monitor, timestamp DESC);
+
+-- Aggregates table
+CREATE TABLE IF NOT EXISTS monitor_aggregates (
+	id BIGSERIAL PRIMARY KEY,
+	monitor VARCHAR(255) NOT NULL,
+	period_type VARCHAR(20) NOT NULL,
+	period_start TIMESTAMPTZ NOT NULL,
+	period_end TIMESTAMPTZ NOT NULL,
+	total_checks INTEGER NOT NULL,
+	up_checks INTEGER NOT NULL,
+	down_checks INTEGER NOT NULL,
+	avg_response_time_ms BIGINT,
+	min_response_time_ms BIGINT,
+	max_response_time_ms BIGINT,
+	created_at TIMESTAMPTZ DEFAULT NOW(),
+	UNIQUE(monitor, period_type, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_aggregates_monitor_period ON monitor_aggregates(monitor, period_type, period_start DESC);
+
+-- Metadata table for storing operational metadata
+CREATE TABLE IF NOT EXISTS storage_metadata (
+	key VARCHAR(255) PRIMARY KEY,
+	value BYTEA NOT NULL,
+	updated_at TIMESTAMPTZ DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+-- How many checks a stored result stands for when its monitor samples
+-- successful results; unsampled results count once.
+ALTER TABLE monitor_results ADD COLUMN IF NOT EXISTS sample_weight INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,13 @@
+-- Results and aggregates of a tenant's monitors carry the tenant's name, so
+-- each tenant's rows are kept apart. Rows of no tenant have ''.
+ALTER TABLE monitor_results ADD COLUMN IF NOT EXISTS tenant VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE monitor_aggregates ADD COLUMN IF NOT EXISTS tenant VARCHAR(255) NOT NULL DEFAULT '';
+
+DROP INDEX IF EXISTS idx_monitor_results_monitor_timestamp;
+CREATE INDEX IF NOT EXISTS idx_monitor_results_tenant_monitor_timestamp ON monitor_results(tenant, monitor, timestamp DESC);
+
+ALTER TABLE monitor_aggregates DROP CONSTRAINT IF EXISTS monitor_aggregates_monitor_period_type_period_start_key;
+ALTER TABLE monitor_aggregates ADD CONSTRAINT monitor_aggregates_tenant_monitor_period_key UNIQUE (tenant, monitor, period_type, period_start);
+
+DROP INDEX IF EXISTS idx_aggregates_monitor_period;
+CREATE INDEX IF NOT EXISTS idx_aggregates_tenant_monitor_period ON monitor_aggregates(tenant, monitor, period_type, period_start DESC);
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Values are encoded to MessagePack straight from the model structs, using
+// their json tags for field names so both encodings describe a value the
+// same way. Map keys are sorted so equal values encode identically, and
+// times use the MessagePack timestamp extension, which keeps the instant but
+// not the time zone: they decode in the local one.
+
+// marshalMsgpack encodes v as MessagePack
+func marshalMsgpack(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("failed to encode MessagePack value: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// unmarshalMsgpack decodes a value written by marshalMsgpack into v. Numbers
+// inside untyped fields such as metadata come back as int64, uint64 or
+// float64 whatever their encoded size.
+func unmarshalMsgpack(data []byte, v interface{}) error {
+	reader := bytes.NewReader(data)
+	dec := msgpack.NewDecoder(reader)
+	dec.SetCustomStructTag("json")
+	dec.UseLooseInterfaceDecoding(true)
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid MessagePack value: %w", err)
+	}
+	if reader.Len() != 0 {
+		return fmt.Errorf("invalid MessagePack value: %d trailing bytes", reader.Len())
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	expiry := now.Add(90 * 24 * time.Hour)
+	tests := []struct {
+		name  string
+		value interface{}
+		empty interface{}
+	}{
+		{
+			name: "result",
+			value: &models.MonitorResult{
+				Monitor:   "api",
+				Type:      models.MonitorTypeHTTP,
+				Group:     "core",
+				Status:    models.StatusDown,
+				Duration:  1500 * time.Millisecond,
+				Error:     "quote\"\\<\n héllo ✓ " + strings.Repeat("x", 70000),
+				Timestamp: now,
+				Labels:    map[string]string{"team": "web", "tier": "1"},
+				Geo:       &models.GeoInfo{Country: "DE", ASN: 3320},
+				HTTPResult: &models.HTTPResult{
+					StatusCode:    503,
+					ResponseTime:  -1,
+					ResponseSize:  1 << 40,
+					Headers:       map[string]string{"Content-Type": "text/html", "Server": "nginx"},
+					SSLCertExpiry: &expiry,
+					CertChain:     []models.Certificate{{Subject: "CN=api", NotBefore: now, NotAfter: expiry, DNSNames: []string{"api"}}},
+				},
+			},
+			empty: &models.MonitorResult{},
+		},
+		{
+			name:  "aggregate",
+			value: &models.AggregateResult{Monitor: "api", PeriodStart: now, PeriodEnd: now.Add(time.Hour), PeriodType: "hour", TotalChecks: 60, UpChecks: 59, DownChecks: 1, UptimePercent: 98.333, AvgDuration: time.Second},
+			empty: &models.AggregateResult{},
+		},
+		{
+			name:  "annotation",
+			value: &models.Annotation{ID: "a1", Time: now, Monitor: "api", Text: "deploy", Tags: []string{"release"}},
+			empty: &models.Annotation{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packed, err := marshalMsgpack(tt.value)
+			if err != nil {
+				t.Fatalf("failed to pack: %v", err)
+			}
+			again, _ := marshalMsgpack(tt.value)
+			if string(again) != string(packed) {
+				t.Error("expected equal values to encode identically")
+			}
+
+			if err := unmarshalMsgpack(packed, tt.empty); err != nil {
+				t.Fatalf("failed to unpack: %v", err)
+			}
+			// Times keep their instant; compare them in one time zone
+			repacked, err := marshalMsgpack(tt.empty)
+			if err != nil {
+				t.Fatalf("failed to repack: %v", err)
+			}
+			if string(repacked) != string(packed) {
+				t.Errorf("round trip mismatch: got %+v", tt.empty)
+			}
+		})
+	}
+}
+
+func TestMsgpackMetadata(t *testing.T) {
+	result := &models.MonitorResult{
+		Monitor:  "api",
+		Metadata: map[string]interface{}{"count": 3, "ratio": 0.5, "name": "x", "nested": []interface{}{true, nil}},
+	}
+	packed, err := marshalMsgpack(result)
+	if err != nil {
+		t.Fatalf("failed to pack: %v", err)
+	}
+
+	var decoded models.MonitorResult
+	if err := unmarshalMsgpack(packed, &decoded); err != nil {
+		t.Fatalf("failed to unpack: %v", err)
+	}
+	want := map[string]interface{}{"count": int64(3), "ratio": 0.5, "name": "x", "nested": []interface{}{true, nil}}
+	if !reflect.DeepEqual(decoded.Metadata, want) {
+		t.Errorf("unexpected metadata %#v", decoded.Metadata)
+	}
+}
+
+func TestMsgpackErrors(t *testing.T) {
+	tests := map[string][]byte{
+		"empty":          {},
+		"truncated":      {0x81, 0xa1, 'a'},
+		"trailing bytes": {0x80, 0xc0},
+		"wrong type":     {0x81, 0xa7, 'm', 'o', 'n', 'i', 't', 'o', 'r', 0xc3},
+		"unsupported":    {0xc1},
+	}
+	for name, data := range tests {
+		var result models.MonitorResult
+		if err := unmarshalMsgpack(data, &result); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
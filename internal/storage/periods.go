@@ -0,0 +1,56 @@
+package storage
+
+import "time"
+
+// aggregatePeriod describes an aggregate period type. Periods are aligned in
+// UTC: weeks start on Monday and months on the first.
+type aggregatePeriod struct {
+	periodType string
+	length     time.Duration // Zero for calendar months
+	fromDaily  bool          // Rolled up from daily aggregates rather than raw results
+}
+
+// aggregatePeriodTypes lists the aggregate period types, each after those it
+// is rolled up from
+var aggregatePeriodTypes = []aggregatePeriod{
+	{periodType: "hour", length: time.Hour},
+	{periodType: "day", length: 24 * time.Hour},
+	// Raw results rarely cover a whole week or month, and would be too many to
+	// load at once
+	{periodType: "week", length: 7 * 24 * time.Hour, fromDaily: true},
+	{periodType: "month", fromDaily: true},
+}
+
+// lookupPeriod returns the description of an aggregate period type
+func lookupPeriod(periodType string) (aggregatePeriod, bool) {
+	for _, period := range aggregatePeriodTypes {
+		if period.periodType == periodType {
+			return period, true
+		}
+	}
+	return aggregatePeriod{}, false
+}
+
+// validPeriodType reports whether periodType is an aggregate period type
+func validPeriodType(periodType string) bool {
+	_, ok := lookupPeriod(periodType)
+	return ok
+}
+
+// truncate returns the start of the period containing t. Truncating to whole
+// weeks lands on Mondays, since the zero time is a Monday.
+func (p aggregatePeriod) truncate(t time.Time) time.Time {
+	if p.length == 0 {
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(p.length)
+}
+
+// next returns the start of the period after the one starting at start
+func (p aggregatePeriod) next(start time.Time) time.Time {
+	if p.length == 0 {
+		return start.AddDate(0, 1, 0)
+	}
+	return start.Add(p.length)
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregatePeriods(t *testing.T) {
+	// Wednesday 2025-01-29 13:45 UTC
+	at := time.Date(2025, 1, 29, 13, 45, 0, 0, time.UTC)
+
+	tests := []struct {
+		periodType string
+		wantStart  time.Time
+		wantNext   time.Time
+	}{
+		{periodType: "hour", wantStart: time.Date(2025, 1, 29, 13, 0, 0, 0, time.UTC), wantNext: time.Date(2025, 1, 29, 14, 0, 0, 0, time.UTC)},
+		{periodType: "day", wantStart: time.Date(2025, 1, 29, 0, 0, 0, 0, time.UTC), wantNext: time.Date(2025, 1, 30, 0, 0, 0, 0, time.UTC)},
+		{periodType: "week", wantStart: time.Date(2025, 1, 27, 0, 0, 0, 0, time.UTC), wantNext: time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC)},
+		{periodType: "month", wantStart: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), wantNext: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.periodType, func(t *testing.T) {
+			period, ok := lookupPeriod(tt.periodType)
+			if !ok {
+				t.Fatalf("Expected %s to be a period type", tt.periodType)
+			}
+			start := period.truncate(at)
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("Expected the period to start at %v, got %v", tt.wantStart, start)
+			}
+			if next := period.next(start); !next.Equal(tt.wantNext) {
+				t.Errorf("Expected the next period at %v, got %v", tt.wantNext, next)
+			}
+		})
+	}
+
+	// Months are calendar months in UTC, whatever the location
+	est := time.FixedZone("EST", -5*60*60)
+	month, _ := lookupPeriod("month")
+	if got := month.truncate(time.Date(2025, 2, 28, 22, 0, 0, 0, est)); !got.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected March in UTC, got %v", got)
+	}
+
+	if validPeriodType("year") {
+		t.Error("Expected year not to be a period type")
+	}
+}
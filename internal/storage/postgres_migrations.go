@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema changes are SQL files named {version}_{description}.sql, applied in
+// version order. Applied migrations must never be edited; add a new one.
+//
+//go:embed migrations/postgres/*.sql
+var postgresMigrationFiles embed.FS
+
+// postgresMigrationsDir is where postgres migrations live in postgresMigrationFiles
+const postgresMigrationsDir = "migrations/postgres"
+
+// migrationLockID is the advisory lock key held while migrating, so several
+// instances starting against the same database apply migrations once
+const migrationLockID int64 = 0x68616c6c6d6f6e // "hallmon"
+
+// migration is a single versioned schema change
+type migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// loadMigrations reads and orders the migrations in dir, rejecting duplicate
+// versions and files that don't follow the naming scheme
+func loadMigrations(fsys fs.FS, dir string) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 || name == "" {
+			return nil, fmt.Errorf("invalid migration file name %q (expected {version}_{description}.sql)", entry.Name())
+		}
+		if other, dup := seen[version]; dup {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migrations = append(migrations, migration{Version: version, Name: name, SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// migrate applies pending migrations, each in its own transaction, while
+// holding an advisory lock
+func (ps *PostgresStore) migrate() error {
+	migrations, err := loadMigrations(postgresMigrationFiles, postgresMigrationsDir)
+	if err != nil {
+		return err
+	}
+
+	// Advisory locks belong to a session, so hold one connection throughout
+	conn, err := ps.pool.Acquire(ps.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ps.ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		_, _ = conn.Exec(ps.ctx, `SELECT pg_advisory_unlock($1)`, migrationLockID)
+	}()
+
+	_, err = conn.Exec(ps.ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	var current int
+	if err := conn.QueryRow(ps.ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&current); err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if len(migrations) > 0 && current > migrations[len(migrations)-1].Version {
+		// Migrations only add to the schema, so an older build can still run
+		ps.logger.WithComponent("storage").
+			WithFields(map[string]interface{}{
+				"schema_version": current,
+				"known_version":  migrations[len(migrations)-1].Version,
+			}).
+			Warn("Database schema is newer than this build")
+	}
+
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+
+		tx, err := conn.Begin(ps.ctx)
+		if err != nil {
+			return fmt.Errorf("failed to begin migration %d: %w", m.Version, err)
+		}
+		if _, err := tx.Exec(ps.ctx, m.SQL); err != nil {
+			_ = tx.Rollback(ps.ctx)
+			return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		if _, err := tx.Exec(ps.ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, m.Version, m.Name); err != nil {
+			_ = tx.Rollback(ps.ctx)
+			return fmt.Errorf("failed to record migration %d: %w", m.Version, err)
+		}
+		if err := tx.Commit(ps.ctx); err != nil {
+			return fmt.Errorf("failed to commit migration %d: %w", m.Version, err)
+		}
+
+		ps.logger.WithComponent("storage").
+			WithFields(map[string]interface{}{
+				"version": m.Version,
+				"name":    m.Name,
+			}).
+			Info("Applied database migration")
+	}
+
+	return nil
+}
+
+// checkSchema verifies, without changing anything, that the database has
+// every migration this build knows about
+func (ps *PostgresStore) checkSchema() error {
+	migrations, err := loadMigrations(postgresMigrationFiles, postgresMigrationsDir)
+	if err != nil {
+		return err
+	}
+
+	var current int
+	err = ps.pool.QueryRow(ps.ctx, `
+		SELECT CASE WHEN to_regclass('schema_migrations') IS NULL THEN 0
+		            ELSE (SELECT COALESCE(MAX(version), 0) FROM schema_migrations) END
+	`).Scan(&current)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	if len(migrations) > 0 && current < migrations[len(migrations)-1].Version {
+		return fmt.Errorf("database schema is at version %d but this build needs %d; start a writable instance to migrate it",
+			current, migrations[len(migrations)-1].Version)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        fstest.MapFS
+		wantVersions []int
+		wantErr      bool
+	}{
+		{
+			name: "ordered by version",
+			files: fstest.MapFS{
+				"m/0010_add_events.sql":     {Data: []byte("SELECT 10")},
+				"m/0002_add_incidents.sql":  {Data: []byte("SELECT 2")},
+				"m/0001_initial_schema.sql": {Data: []byte("SELECT 1")},
+				"m/README.md":               {Data: []byte("not a migration")},
+			},
+			wantVersions: []int{1, 2, 10},
+		},
+		{
+			name: "duplicate version",
+			files: fstest.MapFS{
+				"m/0001_initial.sql": {Data: []byte("SELECT 1")},
+				"m/1_other.sql":      {Data: []byte("SELECT 1")},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "missing description",
+			files:   fstest.MapFS{"m/0001.sql": {Data: []byte("SELECT 1")}},
+			wantErr: true,
+		},
+		{
+			name:    "non-numeric version",
+			files:   fstest.MapFS{"m/initial_schema.sql": {Data: []byte("SELECT 1")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files, "m")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadMigrations() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(migrations) != len(tt.wantVersions) {
+				t.Fatalf("Expected %d migrations, got %d", len(tt.wantVersions), len(migrations))
+			}
+			for i, m := range migrations {
+				if m.Version != tt.wantVersions[i] {
+					t.Errorf("migrations[%d].Version = %d, want %d", i, m.Version, tt.wantVersions[i])
+				}
+			}
+		})
+	}
+}
+
+func TestEmbeddedPostgresMigrations(t *testing.T) {
+	migrations, err := loadMigrations(postgresMigrationFiles, postgresMigrationsDir)
+	if err != nil {
+		t.Fatalf("Failed to load embedded migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+
+	// Versions are sequential so a gap doesn't hide a lost file
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("Migration %s has version %d, want %d", m.Name, m.Version, i+1)
+		}
+	}
+}
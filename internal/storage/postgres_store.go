@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -19,12 +20,28 @@ type PostgresStore struct {
 	logger         *logging.Logger
 	ctx            context.Context
 	retentionDays  int
+	failures       FailureRetention
+	timescale      bool // monitor_results is a TimescaleDB hypertable
+	readOnly       bool
 	stopCleanup    chan struct{}
 	cleanupStopped chan struct{}
+
+	tenantIndex // Which tenant's rows a monitor's results and aggregates are
 }
 
-// NewPostgresStore creates a PostgreSQL-backed storage
+// NewPostgresStore creates a PostgreSQL-backed storage, using TimescaleDB if installed
 func NewPostgresStore(connString string, retentionDays int, logger *logging.Logger) (*PostgresStore, error) {
+	return NewPostgresStoreWithOptions(connString, retentionDays, PostgresOptions{}, logger)
+}
+
+// NewPostgresStoreWithOptions creates a PostgreSQL-backed storage with the given options
+func NewPostgresStoreWithOptions(connString string, retentionDays int, options PostgresOptions, logger *logging.Logger) (*PostgresStore, error) {
+	switch options.Timescale {
+	case "", TimescaleAuto, TimescaleOff:
+	default:
+		return nil, fmt.Errorf("unsupported timescale setting: %s (valid options: auto, off)", options.Timescale)
+	}
+
 	ctx := context.Background()
 
 	config, err := pgxpool.ParseConfig(connString)
@@ -37,6 +54,10 @@ func NewPostgresStore(connString string, retentionDays int, logger *logging.Logg
 	config.MinConns = 2
 	config.MaxConnLifetime = time.Hour
 	config.MaxConnIdleTime = 30 * time.Minute
+	if options.ReadOnly {
+		// Also lets the store point at a hot standby
+		config.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
@@ -58,90 +79,75 @@ func NewPostgresStore(connString string, retentionDays int, logger *logging.Logg
 		logger:         logger,
 		ctx:            ctx,
 		retentionDays:  retentionDays,
+		failures:       options.FailureRetention,
+		readOnly:       options.ReadOnly,
 		stopCleanup:    make(chan struct{}),
 		cleanupStopped: make(chan struct{}),
 	}
 
-	// Initialize schema
-	if err := ps.initSchema(); err != nil {
+	// Bring the schema up to date; a read-only store relies on a writer for that
+	if options.ReadOnly {
+		err = ps.checkSchema()
+	} else {
+		err = ps.migrate()
+	}
+	if err != nil {
 		pool.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
-	// Start retention cleanup
-	go ps.runRetentionCleanup()
+	var timescaleVersion string
+	if options.Timescale != TimescaleOff {
+		timescaleVersion, err = ps.timescaleVersion()
+		if err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+	if timescaleVersion != "" {
+		if options.ReadOnly {
+			ps.timescale, err = ps.timescaleReady()
+		} else {
+			err = ps.initTimescale()
+			ps.timescale = err == nil
+		}
+		if err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to initialize timescaledb: %w", err)
+		}
+	}
+
+	// Start retention cleanup; the writer owns retention in read-only mode
+	if options.ReadOnly {
+		close(ps.cleanupStopped)
+	} else {
+		go ps.runRetentionCleanup()
+	}
 
 	logger.WithComponent("storage").
 		WithFields(map[string]interface{}{
 			"backend":       "postgres",
 			"retentionDays": retentionDays,
+			"timescaledb":   timescaleVersion,
+			"readOnly":      options.ReadOnly,
 		}).
 		Info("PostgreSQL storage initialized successfully")
 
 	return ps, nil
 }
 
-func (ps *PostgresStore) initSchema() error {
-	schema := `
-	-- Monitor results table
-	CREATE TABLE IF NOT EXISTS monitor_results (
-		id BIGSERIAL PRIMARY KEY,
-		monitor VARCHAR(255) NOT NULL,
-		type VARCHAR(50) NOT NULL,
-		status VARCHAR(20) NOT NULL,
-		timestamp TIMESTAMPTZ NOT NULL,
-		response_time_ms BIGINT,
-		status_code INTEGER,
-		error_message TEXT,
-		metadata JSONB,
-		created_at TIMESTAMPTZ DEFAULT NOW()
-	);
-
-	-- Indexes for common queries
-	CREATE INDEX IF NOT EXISTS idx_monitor_results_monitor ON monitor_results(monitor);
-	CREATE INDEX IF NOT EXISTS idx_monitor_results_timestamp ON monitor_results(timestamp DESC);
-	CREATE INDEX IF NOT EXISTS idx_monitor_results_monitor_timestamp ON monitor_results(monitor, timestamp DESC);
-
-	-- Aggregates table
-	CREATE TABLE IF NOT EXISTS monitor_aggregates (
-		id BIGSERIAL PRIMARY KEY,
-		monitor VARCHAR(255) NOT NULL,
-		period_type VARCHAR(20) NOT NULL,
-		period_start TIMESTAMPTZ NOT NULL,
-		period_end TIMESTAMPTZ NOT NULL,
-		total_checks INTEGER NOT NULL,
-		up_checks INTEGER NOT NULL,
-		down_checks INTEGER NOT NULL,
-		avg_response_time_ms BIGINT,
-		min_response_time_ms BIGINT,
-		max_response_time_ms BIGINT,
-		created_at TIMESTAMPTZ DEFAULT NOW(),
-		UNIQUE(monitor, period_type, period_start)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_aggregates_monitor_period ON monitor_aggregates(monitor, period_type, period_start DESC);
-
-	-- Metadata table for storing operational metadata
-	CREATE TABLE IF NOT EXISTS storage_metadata (
-		key VARCHAR(255) PRIMARY KEY,
-		value BYTEA NOT NULL,
-		updated_at TIMESTAMPTZ DEFAULT NOW()
-	);
-	`
-
-	_, err := ps.pool.Exec(ps.ctx, schema)
-	return err
-}
-
 // StoreResult stores a monitor result
 func (ps *PostgresStore) StoreResult(result *models.MonitorResult) error {
+	if ps.readOnly {
+		return ErrReadOnly
+	}
 	if result == nil {
 		return fmt.Errorf("result cannot be nil")
 	}
 
 	query := `
-		INSERT INTO monitor_results (monitor, type, status, timestamp, response_time_ms, status_code, error_message, metadata)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO monitor_results (monitor, type, status, timestamp, response_time_ms, status_code, error_message, metadata, sample_weight, tenant)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
 	// Convert metadata to JSON
@@ -169,6 +175,8 @@ func (ps *PostgresStore) StoreResult(result *models.MonitorResult) error {
 		statusCode,
 		result.Error,
 		metadataJSON,
+		result.Weight(),
+		ps.tenantOf(result.Monitor),
 	)
 
 	if err != nil {
@@ -181,9 +189,9 @@ func (ps *PostgresStore) StoreResult(result *models.MonitorResult) error {
 // GetLatestResult retrieves the most recent result for a monitor
 func (ps *PostgresStore) GetLatestResult(monitor string) (*models.MonitorResult, error) {
 	query := `
-		SELECT monitor, type, status, timestamp, response_time_ms, status_code, error_message, metadata
+		SELECT monitor, type, status, timestamp, response_time_ms, status_code, error_message, metadata, sample_weight
 		FROM monitor_results
-		WHERE monitor = $1
+		WHERE monitor = $1 AND tenant = $2
 		ORDER BY timestamp DESC
 		LIMIT 1
 	`
@@ -193,8 +201,9 @@ func (ps *PostgresStore) GetLatestResult(monitor string) (*models.MonitorResult,
 	var statusCode sql.NullInt64
 	var errorMessage sql.NullString
 	var metadataJSON []byte
+	var sampleWeight int
 
-	err := ps.pool.QueryRow(ps.ctx, query, monitor).Scan(
+	err := ps.pool.QueryRow(ps.ctx, query, monitor, ps.tenantOf(monitor)).Scan(
 		&result.Monitor,
 		&result.Type,
 		&result.Status,
@@ -203,6 +212,7 @@ func (ps *PostgresStore) GetLatestResult(monitor string) (*models.MonitorResult,
 		&statusCode,
 		&errorMessage,
 		&metadataJSON,
+		&sampleWeight,
 	)
 
 	if err == sql.ErrNoRows || err != nil && err.Error() == "no rows in result set" {
@@ -213,6 +223,9 @@ func (ps *PostgresStore) GetLatestResult(monitor string) (*models.MonitorResult,
 	}
 
 	result.Duration = time.Duration(responseTimeMs) * time.Millisecond
+	if sampleWeight > 1 {
+		result.SampleWeight = sampleWeight
+	}
 	if statusCode.Valid {
 		// Store HTTP-specific data if status code is present
 		result.HTTPResult = &models.HTTPResult{
@@ -234,33 +247,154 @@ func (ps *PostgresStore) GetLatestResult(monitor string) (*models.MonitorResult,
 	return &result, nil
 }
 
-// GetResults retrieves results for a monitor within a time range
+// GetResults retrieves results for a monitor within a time range, newest first
 func (ps *PostgresStore) GetResults(monitor string, start, end time.Time, limit int) ([]*models.MonitorResult, error) {
 	if limit <= 0 {
 		limit = 1000 // default limit
 	}
 
 	query := `
-		SELECT monitor, type, status, timestamp, response_time_ms, status_code, error_message, metadata
+		SELECT monitor, type, status, timestamp, response_time_ms, status_code, error_message, metadata, sample_weight
 		FROM monitor_results
-		WHERE monitor = $1 AND timestamp BETWEEN $2 AND $3
+		WHERE monitor = $1 AND tenant = $5 AND timestamp BETWEEN $2 AND $3
 		ORDER BY timestamp DESC
 		LIMIT $4
 	`
 
-	rows, err := ps.pool.Query(ps.ctx, query, monitor, start, end, limit)
+	var results []*models.MonitorResult
+	err := ps.scanResults(query, []interface{}{monitor, start, end, limit, ps.tenantOf(monitor)}, func(result *models.MonitorResult) bool {
+		results = append(results, result)
+		return true
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query results: %w", err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// StreamResults calls fn for each result of a monitor within a time range,
+// newest first when descending is set, until fn returns false
+func (ps *PostgresStore) StreamResults(monitor string, start, end time.Time, descending bool, fn func(*models.MonitorResult) bool) error {
+	order := "ASC"
+	if descending {
+		order = "DESC"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT monitor, type, status, timestamp, response_time_ms, status_code, error_message, metadata, sample_weight
+		FROM monitor_results
+		WHERE monitor = $1 AND tenant = $4 AND timestamp BETWEEN $2 AND $3
+		ORDER BY timestamp %s
+	`, order)
+
+	return ps.scanResults(query, []interface{}{monitor, start, end, ps.tenantOf(monitor)}, fn)
+}
+
+// SearchResults finds matching results with a single query, newest first.
+// Text is matched with ILIKE against the error message and metadata values,
+// and ErrorContains against the error message alone.
+func (ps *PostgresStore) SearchResults(query ResultQuery) ([]*models.MonitorResult, error) {
+	var conditions []string
+	args := []interface{}{query.Start, query.End}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions = append(conditions, "timestamp BETWEEN $1 AND $2")
+	if len(query.Monitors) > 0 {
+		// Each monitor is matched in its own tenant's rows
+		tenants := make([]string, len(query.Monitors))
+		for i, monitor := range query.Monitors {
+			tenants[i] = ps.tenantOf(monitor)
+		}
+		conditions = append(conditions, fmt.Sprintf("(tenant, monitor) IN (SELECT * FROM unnest(%s::text[], %s::text[]))", arg(tenants), arg(query.Monitors)))
+	}
+	if query.Status != "" {
+		conditions = append(conditions, "status = "+arg(string(query.Status)))
+	}
+	if query.StatusCode != 0 {
+		conditions = append(conditions, "status_code = "+arg(query.StatusCode))
+	}
+	if query.ErrorContains != "" {
+		conditions = append(conditions, "error_message ILIKE "+arg("%"+escapeLikePattern(query.ErrorContains)+"%"))
+	}
+	if query.Text != "" {
+		pattern := arg("%" + escapeLikePattern(query.Text) + "%")
+		// jsonb_each_text only accepts objects; CASE keeps it from seeing others
+		text := fmt.Sprintf(`error_message ILIKE %[1]s OR CASE
+				WHEN jsonb_typeof(metadata) = 'object'
+				THEN EXISTS (SELECT 1 FROM jsonb_each_text(metadata) m WHERE m.value ILIKE %[1]s)
+				ELSE metadata::text ILIKE %[1]s
+			END`, pattern)
+		if code, ok := query.textStatusCode(); ok {
+			text += " OR status_code = " + arg(code)
+		}
+		conditions = append(conditions, "("+text+")")
+	}
+
+	sqlQuery := fmt.Sprintf(`
+		SELECT monitor, type, status, timestamp, response_time_ms, status_code, error_message, metadata, sample_weight
+		FROM monitor_results
+		WHERE %s
+		ORDER BY timestamp DESC
+	`, strings.Join(conditions, " AND "))
+	if query.Limit > 0 {
+		sqlQuery += " LIMIT " + arg(query.Limit)
 	}
-	defer rows.Close()
 
 	var results []*models.MonitorResult
+	err := ps.scanResults(sqlQuery, args, func(result *models.MonitorResult) bool {
+		results = append(results, result)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// escapeLikePattern escapes LIKE wildcards so s matches literally
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// CountResults counts a monitor's results within a time range by status
+func (ps *PostgresStore) CountResults(monitor string, start, end time.Time) (ResultCounts, error) {
+	query := `
+		SELECT COALESCE(SUM(sample_weight), 0),
+			COALESCE(SUM(sample_weight) FILTER (WHERE status = 'up'), 0),
+			COALESCE(SUM(sample_weight) FILTER (WHERE status = 'down'), 0)
+		FROM monitor_results
+		WHERE monitor = $1 AND tenant = $4 AND timestamp BETWEEN $2 AND $3
+	`
+
+	var counts ResultCounts
+	err := ps.pool.QueryRow(ps.ctx, query, monitor, start, end, ps.tenantOf(monitor)).Scan(&counts.Total, &counts.Up, &counts.Down)
+	if err != nil {
+		return ResultCounts{}, fmt.Errorf("failed to count results: %w", err)
+	}
+
+	return counts, nil
+}
+
+// scanResults runs a monitor_results query and calls fn with each row until
+// it returns false
+func (ps *PostgresStore) scanResults(query string, args []interface{}, fn func(*models.MonitorResult) bool) error {
+	rows, err := ps.pool.Query(ps.ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query results: %w", err)
+	}
+	defer rows.Close()
+
 	for rows.Next() {
 		var result models.MonitorResult
 		var responseTimeMs int64
 		var statusCode sql.NullInt64
 		var errorMessage sql.NullString
 		var metadataJSON []byte
+		var sampleWeight int
 
 		err := rows.Scan(
 			&result.Monitor,
@@ -271,6 +405,7 @@ func (ps *PostgresStore) GetResults(monitor string, start, end time.Time, limit
 			&statusCode,
 			&errorMessage,
 			&metadataJSON,
+			&sampleWeight,
 		)
 		if err != nil {
 			ps.logger.WithComponent("storage").
@@ -280,6 +415,9 @@ func (ps *PostgresStore) GetResults(monitor string, start, end time.Time, limit
 		}
 
 		result.Duration = time.Duration(responseTimeMs) * time.Millisecond
+		if sampleWeight > 1 {
+			result.SampleWeight = sampleWeight
+		}
 		if statusCode.Valid {
 			// Store HTTP-specific data if status code is present
 			result.HTTPResult = &models.HTTPResult{
@@ -298,31 +436,36 @@ func (ps *PostgresStore) GetResults(monitor string, start, end time.Time, limit
 			}
 		}
 
-		results = append(results, &result)
+		if !fn(&result) {
+			return nil
+		}
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating results: %w", err)
+		return fmt.Errorf("error iterating results: %w", err)
 	}
 
-	return results, nil
+	return nil
 }
 
 // GetAggregates retrieves aggregates for a monitor within a time range
 func (ps *PostgresStore) GetAggregates(monitor, periodType string, start, end time.Time) ([]*models.AggregateResult, error) {
-	if periodType != "hour" && periodType != "day" {
+	if !validPeriodType(periodType) {
 		return nil, fmt.Errorf("invalid period type: %s", periodType)
 	}
+	if ps.timescale {
+		return ps.timescaleGetAggregates(monitor, periodType, start, end)
+	}
 
 	query := `
 		SELECT monitor, period_type, period_start, period_end, total_checks, up_checks, down_checks,
 		       avg_response_time_ms, min_response_time_ms, max_response_time_ms
 		FROM monitor_aggregates
-		WHERE monitor = $1 AND period_type = $2 AND period_start BETWEEN $3 AND $4
+		WHERE monitor = $1 AND tenant = $5 AND period_type = $2 AND period_start BETWEEN $3 AND $4
 		ORDER BY period_start DESC
 	`
 
-	rows, err := ps.pool.Query(ps.ctx, query, monitor, periodType, start, end)
+	rows, err := ps.pool.Query(ps.ctx, query, monitor, periodType, start, end, ps.tenantOf(monitor))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query aggregates: %w", err)
 	}
@@ -374,6 +517,9 @@ func (ps *PostgresStore) GetAggregates(monitor, periodType string, start, end ti
 
 // StoreAggregate stores an aggregate result
 func (ps *PostgresStore) StoreAggregate(agg *models.AggregateResult) error {
+	if ps.readOnly {
+		return ErrReadOnly
+	}
 	if agg == nil {
 		return fmt.Errorf("aggregate cannot be nil")
 	}
@@ -381,9 +527,9 @@ func (ps *PostgresStore) StoreAggregate(agg *models.AggregateResult) error {
 	query := `
 		INSERT INTO monitor_aggregates (
 			monitor, period_type, period_start, period_end, total_checks, up_checks, down_checks,
-			avg_response_time_ms, min_response_time_ms, max_response_time_ms
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (monitor, period_type, period_start)
+			avg_response_time_ms, min_response_time_ms, max_response_time_ms, tenant
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (tenant, monitor, period_type, period_start)
 		DO UPDATE SET
 			period_end = EXCLUDED.period_end,
 			total_checks = EXCLUDED.total_checks,
@@ -405,6 +551,7 @@ func (ps *PostgresStore) StoreAggregate(agg *models.AggregateResult) error {
 		agg.AvgDuration.Milliseconds(),
 		agg.MinDuration.Milliseconds(),
 		agg.MaxDuration.Milliseconds(),
+		ps.tenantOf(agg.Monitor),
 	)
 
 	if err != nil {
@@ -467,12 +614,15 @@ func (ps *PostgresStore) Capabilities() BackendCapabilities {
 		SupportsAggregation: true,
 		SupportsRetention:   true,
 		SupportsRawResults:  true,
-		ReadOnly:            false,
+		ReadOnly:            ps.readOnly,
 	}
 }
 
 // SetMetadata stores metadata (e.g., last aggregation time)
 func (ps *PostgresStore) SetMetadata(key string, value []byte) error {
+	if ps.readOnly {
+		return ErrReadOnly
+	}
 	query := `
 		INSERT INTO storage_metadata (key, value, updated_at)
 		VALUES ($1, $2, NOW())
@@ -525,7 +675,35 @@ func (ps *PostgresStore) runRetentionCleanup() {
 }
 
 func (ps *PostgresStore) cleanOldData() {
-	cutoff := time.Now().AddDate(0, 0, -ps.retentionDays)
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -ps.retentionDays)
+
+	// Results kept as failures survive until the failure retention; between
+	// the two cutoffs only the rest are deleted
+	if ps.failures.extends(ps.retentionDays) {
+		ps.cleanNonFailures(cutoff)
+		cutoff = now.AddDate(0, 0, -ps.failures.Days)
+	}
+
+	if ps.timescale {
+		dropped, err := ps.dropOldChunks(cutoff)
+		if err != nil {
+			ps.logger.WithComponent("storage").
+				WithError(err).
+				Error("Failed to clean old data")
+			return
+		}
+		if dropped > 0 {
+			ps.logger.WithComponent("storage").
+				WithFields(map[string]interface{}{
+					"chunks_dropped": dropped,
+					"cutoff_date":    cutoff,
+				}).
+				Info("Dropped old monitor result chunks")
+		}
+		ps.recordRetentionCleanup(now)
+		return
+	}
 
 	query := `DELETE FROM monitor_results WHERE timestamp < $1`
 	result, err := ps.pool.Exec(ps.ctx, query, cutoff)
@@ -545,4 +723,49 @@ func (ps *PostgresStore) cleanOldData() {
 			}).
 			Info("Cleaned old monitor results")
 	}
+	ps.recordRetentionCleanup(now)
+}
+
+// recordRetentionCleanup stores when old data was last cleaned, so readers
+// sharing the database can report it
+func (ps *PostgresStore) recordRetentionCleanup(t time.Time) {
+	if err := setMetadataTime(ps, retentionCleanupMetaKey, t); err != nil {
+		ps.logger.WithComponent("storage").
+			WithError(err).
+			Warn("Failed to record retention cleanup")
+	}
+}
+
+// Stats reports the size of the database, the rows in each table and the time
+// range of the stored results. Counting rows scans the tables, which takes a
+// while on large databases.
+func (ps *PostgresStore) Stats() (StorageStats, error) {
+	stats := StorageStats{
+		RetentionDays: ps.retentionDays,
+		Counts:        make(map[string]int64),
+	}
+
+	if err := ps.pool.QueryRow(ps.ctx, `SELECT pg_database_size(current_database())`).Scan(&stats.SizeBytes); err != nil {
+		return stats, fmt.Errorf("failed to get database size: %w", err)
+	}
+
+	var results, aggregates int64
+	var oldest, newest *time.Time
+	query := `SELECT COUNT(*), MIN(timestamp), MAX(timestamp) FROM monitor_results`
+	if err := ps.pool.QueryRow(ps.ctx, query).Scan(&results, &oldest, &newest); err != nil {
+		return stats, fmt.Errorf("failed to count results: %w", err)
+	}
+	if err := ps.pool.QueryRow(ps.ctx, `SELECT COUNT(*) FROM monitor_aggregates`).Scan(&aggregates); err != nil {
+		return stats, fmt.Errorf("failed to count aggregates: %w", err)
+	}
+	stats.Counts["monitor_results"] = results
+	stats.Counts["monitor_aggregates"] = aggregates
+	stats.OldestResult, stats.NewestResult = oldest, newest
+
+	var err error
+	stats.LastRetentionCleanup, err = getMetadataTime(ps, retentionCleanupMetaKey)
+	if err != nil {
+		return stats, err
+	}
+	return stats, nil
 }
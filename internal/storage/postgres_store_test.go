@@ -4,6 +4,7 @@
 package storage
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -190,3 +191,299 @@ func TestPostgresStore_GetMonitorNames(t *testing.T) {
 		_, _ = store.pool.Exec(store.ctx, "DELETE FROM monitor_results WHERE monitor = $1", monitor)
 	}
 }
+
+func TestPostgresStore_ExoticMonitorNames(t *testing.T) {
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+
+	store, err := NewPostgresStore(getTestPostgresConnection(), 30, logger)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+	}
+	defer store.Close()
+
+	for _, monitor := range exoticMonitorNames {
+		result := &models.MonitorResult{
+			Monitor:   monitor,
+			Type:      models.MonitorTypeTCP,
+			Status:    models.StatusUp,
+			Timestamp: time.Now(),
+		}
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result for %q: %v", monitor, err)
+		}
+	}
+
+	for _, monitor := range exoticMonitorNames {
+		latest, err := store.GetLatestResult(monitor)
+		if err != nil || latest == nil || latest.Monitor != monitor {
+			t.Errorf("Expected latest result for %q, got %v (err %v)", monitor, latest, err)
+		}
+	}
+
+	// Cleanup
+	for _, monitor := range exoticMonitorNames {
+		_, _ = store.pool.Exec(store.ctx, "DELETE FROM monitor_results WHERE monitor = $1", monitor)
+	}
+}
+
+func TestPostgresStore_Timescale(t *testing.T) {
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+
+	store, err := NewPostgresStore(getTestPostgresConnection(), 30, logger)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+	}
+	defer store.Close()
+
+	if !store.timescale {
+		t.Skip("TimescaleDB extension not installed")
+	}
+
+	monitor := "test-timescale-monitor"
+	defer func() {
+		_, _ = store.pool.Exec(store.ctx, "DELETE FROM monitor_results WHERE monitor = $1", monitor)
+	}()
+
+	hour := time.Now().Add(-2 * time.Hour).Truncate(time.Hour)
+	for i, status := range []models.MonitorStatus{models.StatusUp, models.StatusUp, models.StatusDown} {
+		result := &models.MonitorResult{
+			Monitor:   monitor,
+			Type:      models.MonitorTypeHTTP,
+			Status:    status,
+			Timestamp: hour.Add(time.Duration(i+1) * time.Minute),
+			Duration:  time.Duration(100*(i+1)) * time.Millisecond,
+		}
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+
+	if _, err := store.pool.Exec(store.ctx, "CALL refresh_continuous_aggregate('monitor_results_hourly', NULL, NULL)"); err != nil {
+		t.Fatalf("Failed to refresh continuous aggregate: %v", err)
+	}
+
+	aggs, err := store.GetAggregates(monitor, "hour", hour, hour)
+	if err != nil {
+		t.Fatalf("Failed to get aggregates: %v", err)
+	}
+	if len(aggs) != 1 {
+		t.Fatalf("Expected 1 hourly aggregate, got %d", len(aggs))
+	}
+	agg := aggs[0]
+	if agg.TotalChecks != 3 || agg.UpChecks != 2 || agg.DownChecks != 1 {
+		t.Errorf("Unexpected counts: %+v", agg)
+	}
+	if !agg.PeriodEnd.Equal(hour.Add(time.Hour)) || agg.MaxDuration != 300*time.Millisecond {
+		t.Errorf("Unexpected aggregate: %+v", agg)
+	}
+
+	// Retention drops whole chunks
+	old := &models.MonitorResult{
+		Monitor:   monitor,
+		Type:      models.MonitorTypeHTTP,
+		Status:    models.StatusUp,
+		Timestamp: time.Now().AddDate(0, 0, -60),
+	}
+	if err := store.StoreResult(old); err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	store.cleanOldData()
+
+	results, err := store.GetResults(monitor, time.Now().AddDate(0, 0, -90), time.Now().AddDate(0, 0, -31), 10)
+	if err != nil {
+		t.Fatalf("Failed to get results: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected results past retention to be dropped, got %d", len(results))
+	}
+}
+
+func TestPostgresStore_FailureRetention(t *testing.T) {
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+
+	store, err := NewPostgresStoreWithOptions(getTestPostgresConnection(), 30, PostgresOptions{
+		FailureRetention: FailureRetention{Days: 90, Context: 5 * time.Minute},
+	}, logger)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+	}
+	defer store.Close()
+
+	monitor := "failure-retention"
+	failure := time.Now().AddDate(0, 0, -60).Truncate(time.Second)
+	for _, result := range []*models.MonitorResult{
+		{Status: models.StatusUp, Timestamp: failure.Add(-time.Hour)},
+		{Status: models.StatusUp, Timestamp: failure.Add(-2 * time.Minute)},
+		{Status: models.StatusDown, Timestamp: failure},
+		{Status: models.StatusUp, Timestamp: failure.Add(10 * time.Minute)},
+		{Status: models.StatusDown, Timestamp: time.Now().AddDate(0, 0, -120)},
+	} {
+		result.Monitor, result.Type = monitor, models.MonitorTypeHTTP
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+	store.cleanOldData()
+
+	results, err := store.GetResults(monitor, time.Now().AddDate(0, 0, -365), time.Now(), 10)
+	if err != nil {
+		t.Fatalf("Failed to get results: %v", err)
+	}
+	if len(results) != 2 || !results[0].Timestamp.Equal(failure) || !results[1].Timestamp.Equal(failure.Add(-2*time.Minute)) {
+		t.Errorf("Expected the failure and the result just before it to be kept, got %d results", len(results))
+	}
+}
+
+func TestPostgresStore_Stats(t *testing.T) {
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+
+	store, err := NewPostgresStore(getTestPostgresConnection(), 30, logger)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+	}
+	defer store.Close()
+
+	if err := store.StoreResult(&models.MonitorResult{Monitor: "stats", Type: models.MonitorTypeHTTP, Status: models.StatusUp, Timestamp: time.Now()}); err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	store.cleanOldData()
+
+	stats, err := store.Stats()
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if stats.SizeBytes <= 0 || stats.Counts["monitor_results"] < 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if stats.OldestResult == nil || stats.NewestResult == nil || stats.NewestResult.Before(*stats.OldestResult) {
+		t.Errorf("Unexpected result range %v to %v", stats.OldestResult, stats.NewestResult)
+	}
+	if stats.LastRetentionCleanup == nil || time.Since(*stats.LastRetentionCleanup) > time.Minute {
+		t.Errorf("Expected the cleanup to be recorded, got %v", stats.LastRetentionCleanup)
+	}
+}
+
+func TestPostgresStore_Migrations(t *testing.T) {
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+
+	store, err := NewPostgresStore(getTestPostgresConnection(), 30, logger)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+	}
+	defer store.Close()
+
+	migrations, err := loadMigrations(postgresMigrationFiles, postgresMigrationsDir)
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	// Running again is a no-op
+	if err := store.migrate(); err != nil {
+		t.Fatalf("Second migration run failed: %v", err)
+	}
+
+	var applied, latest int
+	err = store.pool.QueryRow(store.ctx, "SELECT COUNT(*), MAX(version) FROM schema_migrations").Scan(&applied, &latest)
+	if err != nil {
+		t.Fatalf("Failed to read schema_migrations: %v", err)
+	}
+	if applied != len(migrations) || latest != migrations[len(migrations)-1].Version {
+		t.Errorf("Expected %d applied migrations up to %d, got %d up to %d",
+			len(migrations), migrations[len(migrations)-1].Version, applied, latest)
+	}
+}
+
+func TestPostgresStore_ReadOnly(t *testing.T) {
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+
+	// A writer brings the schema up to date first
+	writer, err := NewPostgresStore(getTestPostgresConnection(), 30, logger)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+	}
+	defer writer.Close()
+
+	result := &models.MonitorResult{
+		Monitor:   "read-only-test",
+		Type:      models.MonitorTypeHTTP,
+		Status:    models.StatusUp,
+		Timestamp: time.Now(),
+	}
+	if err := writer.StoreResult(result); err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+
+	store, err := NewPostgresStoreWithOptions(getTestPostgresConnection(), 30, PostgresOptions{ReadOnly: true}, logger)
+	if err != nil {
+		t.Fatalf("Failed to open read-only store: %v", err)
+	}
+	defer store.Close()
+
+	if !store.Capabilities().ReadOnly {
+		t.Error("Expected read-only capability")
+	}
+	if latest, err := store.GetLatestResult("read-only-test"); err != nil || latest == nil {
+		t.Errorf("Expected the writer's result, got %v (err %v)", latest, err)
+	}
+	if err := store.StoreResult(result); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("Expected ErrReadOnly, got %v", err)
+	}
+
+	// Sessions refuse writes even when bypassing the store
+	if _, err := store.pool.Exec(store.ctx, "DELETE FROM monitor_results WHERE monitor = 'read-only-test'"); err == nil {
+		t.Error("Expected the session to be read-only")
+	}
+}
+
+func TestPostgresStore_SearchResults(t *testing.T) {
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+
+	store, err := NewPostgresStore(getTestPostgresConnection(), 30, logger)
+	if err != nil {
+		t.Skipf("PostgreSQL not available: %v", err)
+	}
+	defer store.Close()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, result := range []*models.MonitorResult{
+		{Monitor: "search-a", Type: models.MonitorTypeHTTP, Status: models.StatusDown, Error: "100% refused", Timestamp: base},
+		{Monitor: "search-a", Type: models.MonitorTypeHTTP, Status: models.StatusUp, Metadata: map[string]interface{}{"pop": "FRA1"}, Timestamp: base.Add(time.Minute)},
+		{Monitor: "search-b", Type: models.MonitorTypeHTTP, Status: models.StatusDown, HTTPResult: &models.HTTPResult{StatusCode: 503}, Timestamp: base.Add(2 * time.Minute)},
+	} {
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+
+	monitors := []string{"search-a", "search-b"}
+	tests := []struct {
+		name  string
+		query ResultQuery
+		want  int
+	}{
+		{name: "literal percent", query: ResultQuery{Text: "100%"}, want: 1},
+		{name: "wildcard is escaped", query: ResultQuery{Text: "1_0"}, want: 0},
+		{name: "metadata value", query: ResultQuery{Text: "fra"}, want: 1},
+		{name: "text as status code", query: ResultQuery{Text: "503"}, want: 1},
+		{name: "status", query: ResultQuery{Status: models.StatusDown}, want: 2},
+		{name: "error contains", query: ResultQuery{Status: models.StatusDown, ErrorContains: "REFUSED"}, want: 1},
+		{name: "error contains skips metadata", query: ResultQuery{ErrorContains: "fra"}, want: 0},
+		{name: "limit", query: ResultQuery{Status: models.StatusDown, Limit: 1}, want: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			query.Monitors = monitors
+			query.Start, query.End = base.Add(-time.Minute), time.Now()
+
+			found, err := store.SearchResults(query)
+			if err != nil {
+				t.Fatalf("SearchResults failed: %v", err)
+			}
+			if len(found) != tt.want {
+				t.Errorf("Expected %d results, got %d", tt.want, len(found))
+			}
+		})
+	}
+}
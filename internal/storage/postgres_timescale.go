@@ -0,0 +1,293 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// TimescaleDB settings for PostgresOptions.Timescale
+const (
+	TimescaleAuto = "auto" // Use TimescaleDB when the extension is installed
+	TimescaleOff  = "off"  // Plain PostgreSQL tables even if TimescaleDB is installed
+)
+
+// PostgresOptions holds optional PostgresStore settings
+type PostgresOptions struct {
+	Timescale        string // "auto" (default) or "off"
+	ReadOnly         bool   // Serve reads only; schema and retention are left to a writer
+	FailureRetention FailureRetention
+}
+
+// Hypertable settings
+const (
+	timescaleChunkInterval    = "1 day"
+	timescaleCompressAfter    = "7 days"
+	timescaleRefreshLookback  = "3 days" // How far back continuous aggregates are refreshed
+	timescaleRefreshSchedule  = "1 hour"
+	timescaleRefreshEndOffset = "1 hour" // The current hour is computed at query time
+)
+
+// timescaleAggregateViews maps aggregate period types to the continuous
+// aggregates that maintain them
+var timescaleAggregateViews = map[string]struct {
+	view   string
+	bucket string
+}{
+	"hour": {view: "monitor_results_hourly", bucket: "1 hour"},
+	"day":  {view: "monitor_results_daily", bucket: "1 day"},
+}
+
+// timescaleRollups maps the period types rolled up from the daily continuous
+// aggregate at query time to their buckets. TimescaleDB starts weeks on Monday.
+var timescaleRollups = map[string]string{
+	"week":  "1 week",
+	"month": "1 month",
+}
+
+// timescaleVersion returns the installed TimescaleDB extension version, or
+// an empty string if it is not installed
+func (ps *PostgresStore) timescaleVersion() (string, error) {
+	var version string
+	err := ps.pool.QueryRow(ps.ctx, `SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'`).Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to detect timescaledb: %w", err)
+	}
+	return version, nil
+}
+
+// initTimescale turns monitor_results into a compressed hypertable with
+// continuous aggregates. Each step is idempotent, so it runs on every start.
+func (ps *PostgresStore) initTimescale() error {
+	var isHypertable bool
+	err := ps.pool.QueryRow(ps.ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM timescaledb_information.hypertables
+			WHERE hypertable_name = 'monitor_results'
+		)
+	`).Scan(&isHypertable)
+	if err != nil {
+		return fmt.Errorf("failed to check for hypertable: %w", err)
+	}
+
+	if !isHypertable {
+		if err := ps.convertToHypertable(); err != nil {
+			return err
+		}
+	}
+
+	// Recent chunks are queried by monitor and time, so compress by monitor
+	_, err = ps.pool.Exec(ps.ctx, `
+		ALTER TABLE monitor_results SET (
+			timescaledb.compress,
+			timescaledb.compress_segmentby = 'monitor',
+			timescaledb.compress_orderby = 'timestamp DESC'
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to enable compression: %w", err)
+	}
+	_, err = ps.pool.Exec(ps.ctx, fmt.Sprintf(
+		`SELECT add_compression_policy('monitor_results', INTERVAL '%s', if_not_exists => true)`,
+		timescaleCompressAfter))
+	if err != nil {
+		return fmt.Errorf("failed to add compression policy: %w", err)
+	}
+
+	for _, agg := range timescaleAggregateViews {
+		if err := ps.createContinuousAggregate(agg.view, agg.bucket); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// timescaleReady reports whether a writer has already set up the hypertable
+// and continuous aggregates that timescaleGetAggregates reads
+func (ps *PostgresStore) timescaleReady() (bool, error) {
+	var ready bool
+	err := ps.pool.QueryRow(ps.ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM timescaledb_information.hypertables
+			WHERE hypertable_name = 'monitor_results'
+		) AND (
+			SELECT COUNT(*) FROM timescaledb_information.continuous_aggregates
+			WHERE view_name IN ('monitor_results_hourly', 'monitor_results_daily')
+		) = 2
+	`).Scan(&ready)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for hypertable: %w", err)
+	}
+	return ready, nil
+}
+
+// convertToHypertable partitions monitor_results by time, moving existing rows
+// into chunks. Unique indexes on a hypertable must include the time column, so
+// the unused id primary key is dropped first.
+func (ps *PostgresStore) convertToHypertable() error {
+	ps.logger.WithComponent("storage").
+		Info("Converting monitor_results to a TimescaleDB hypertable; this may take a while for large tables")
+
+	tx, err := ps.pool.Begin(ps.ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin hypertable conversion: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ps.ctx) }()
+
+	if _, err := tx.Exec(ps.ctx, `ALTER TABLE monitor_results DROP CONSTRAINT IF EXISTS monitor_results_pkey`); err != nil {
+		return fmt.Errorf("failed to drop primary key: %w", err)
+	}
+	_, err = tx.Exec(ps.ctx, fmt.Sprintf(`
+		SELECT create_hypertable('monitor_results', 'timestamp',
+			chunk_time_interval => INTERVAL '%s',
+			migrate_data => true,
+			if_not_exists => true)
+	`, timescaleChunkInterval))
+	if err != nil {
+		return fmt.Errorf("failed to create hypertable: %w", err)
+	}
+
+	if err := tx.Commit(ps.ctx); err != nil {
+		return fmt.Errorf("failed to commit hypertable conversion: %w", err)
+	}
+	return nil
+}
+
+// createContinuousAggregate creates a continuous aggregate of result counts
+// and response times per monitor and bucket, refreshed in the background
+func (ps *PostgresStore) createContinuousAggregate(view, bucket string) error {
+	_, err := ps.pool.Exec(ps.ctx, fmt.Sprintf(`
+		CREATE MATERIALIZED VIEW IF NOT EXISTS %s
+		WITH (timescaledb.continuous) AS
+		SELECT
+			monitor,
+			time_bucket(INTERVAL '%s', timestamp) AS bucket,
+			SUM(sample_weight) AS total_checks,
+			SUM(CASE WHEN status = 'up' THEN sample_weight ELSE 0 END) AS up_checks,
+			SUM(CASE WHEN status = 'down' THEN sample_weight ELSE 0 END) AS down_checks,
+			AVG(response_time_ms) AS avg_response_time_ms,
+			MIN(response_time_ms) AS min_response_time_ms,
+			MAX(response_time_ms) AS max_response_time_ms
+		FROM monitor_results
+		GROUP BY monitor, bucket
+		WITH NO DATA
+	`, view, bucket))
+	if err != nil {
+		return fmt.Errorf("failed to create continuous aggregate %s: %w", view, err)
+	}
+
+	_, err = ps.pool.Exec(ps.ctx, fmt.Sprintf(`
+		SELECT add_continuous_aggregate_policy('%s',
+			start_offset => INTERVAL '%s',
+			end_offset => INTERVAL '%s',
+			schedule_interval => INTERVAL '%s',
+			if_not_exists => true)
+	`, view, timescaleRefreshLookback, timescaleRefreshEndOffset, timescaleRefreshSchedule))
+	if err != nil {
+		return fmt.Errorf("failed to add refresh policy for %s: %w", view, err)
+	}
+
+	return nil
+}
+
+// timescaleGetAggregates reads aggregates from the continuous aggregates.
+// They roll up by monitor name alone, which is unique across tenants, so a
+// monitor moved to another tenant keeps its rolled-up history.
+func (ps *PostgresStore) timescaleGetAggregates(monitor, periodType string, start, end time.Time) ([]*models.AggregateResult, error) {
+	period, _ := lookupPeriod(periodType)
+
+	var query string
+	if bucket, ok := timescaleRollups[periodType]; ok {
+		// Average response times are weighted by each day's checks
+		query = fmt.Sprintf(`
+			SELECT time_bucket(INTERVAL '%s', bucket) AS period, SUM(total_checks)::BIGINT,
+			       SUM(up_checks)::BIGINT, SUM(down_checks)::BIGINT,
+			       (SUM(avg_response_time_ms * total_checks) / NULLIF(SUM(total_checks), 0))::BIGINT,
+			       MIN(min_response_time_ms), MAX(max_response_time_ms)
+			FROM %s
+			WHERE monitor = $1 AND time_bucket(INTERVAL '%s', bucket) BETWEEN $2 AND $3
+			GROUP BY period
+			ORDER BY period DESC
+		`, bucket, timescaleAggregateViews["day"].view, bucket)
+	} else {
+		query = fmt.Sprintf(`
+			SELECT bucket, total_checks, up_checks, down_checks,
+			       avg_response_time_ms::BIGINT, min_response_time_ms, max_response_time_ms
+			FROM %s
+			WHERE monitor = $1 AND bucket BETWEEN $2 AND $3
+			ORDER BY bucket DESC
+		`, timescaleAggregateViews[periodType].view)
+	}
+
+	rows, err := ps.pool.Query(ps.ctx, query, monitor, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query continuous aggregate: %w", err)
+	}
+	defer rows.Close()
+
+	var aggregates []*models.AggregateResult
+	for rows.Next() {
+		var result models.AggregateResult
+		var avgMs, minMs, maxMs *int64
+
+		if err := rows.Scan(
+			&result.PeriodStart,
+			&result.TotalChecks,
+			&result.UpChecks,
+			&result.DownChecks,
+			&avgMs,
+			&minMs,
+			&maxMs,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan aggregate row: %w", err)
+		}
+
+		result.Monitor = monitor
+		result.PeriodType = periodType
+		result.PeriodEnd = period.next(result.PeriodStart)
+		if avgMs != nil {
+			result.AvgDuration = time.Duration(*avgMs) * time.Millisecond
+		}
+		if minMs != nil {
+			result.MinDuration = time.Duration(*minMs) * time.Millisecond
+		}
+		if maxMs != nil {
+			result.MaxDuration = time.Duration(*maxMs) * time.Millisecond
+		}
+
+		aggregates = append(aggregates, &result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating aggregates: %w", err)
+	}
+
+	return aggregates, nil
+}
+
+// dropOldChunks removes whole chunks older than cutoff, which is much cheaper
+// than deleting rows
+func (ps *PostgresStore) dropOldChunks(cutoff time.Time) (int, error) {
+	rows, err := ps.pool.Query(ps.ctx, `SELECT drop_chunks('monitor_results', older_than => $1::timestamptz)`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to drop chunks: %w", err)
+	}
+	defer rows.Close()
+
+	dropped := 0
+	for rows.Next() {
+		dropped++
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to drop chunks: %w", err)
+	}
+	return dropped, nil
+}
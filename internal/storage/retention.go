@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// FailureRetention keeps down results, and the results around them, longer
+// than the backend's retention so incidents can be analysed after the rest of
+// their history has expired
+type FailureRetention struct {
+	Days    int           // Days to keep failures
+	Context time.Duration // Results this close to a failure are kept as long as it
+}
+
+// NewFailureRetention converts failure retention settings from config
+func NewFailureRetention(cfg config.FailureRetentionConfig) FailureRetention {
+	return FailureRetention{Days: cfg.RetentionDays, Context: time.Duration(cfg.Context)}
+}
+
+// extends reports whether failures outlive results kept for retentionDays
+func (fr FailureRetention) extends(retentionDays int) bool {
+	return fr.Days > retentionDays
+}
+
+// keepAsFailure reports whether a result is a failure, or follows one closely
+// enough, to be kept for the failure retention
+func (bs *BadgerStore) keepAsFailure(result *models.MonitorResult) bool {
+	if !bs.failureRetention.extends(bs.retentionDays) {
+		return false
+	}
+
+	bs.failuresMu.Lock()
+	defer bs.failuresMu.Unlock()
+
+	last, ok := bs.lastFailure[result.Monitor]
+	if result.Status == models.StatusDown {
+		if !ok || result.Timestamp.After(last) {
+			bs.lastFailure[result.Monitor] = result.Timestamp
+		}
+		return true
+	}
+	since := result.Timestamp.Sub(last)
+	return ok && since >= 0 && since <= bs.failureRetention.Context
+}
+
+// extendRetention rewrites a monitor's results within a time range that are
+// still on the normal retention so they expire after ttl instead
+func (bs *BadgerStore) extendRetention(monitor string, start, end time.Time, ttl time.Duration) error {
+	monitorKey := bs.monitorKey(resultKeyPrefix, monitor)
+	prefix := []byte(monitorKey + ":")
+	startKey := []byte(monitorKey + ":" + formatTimestampKey(start.UnixNano()))
+	endKey := []byte(monitorKey + ":" + formatTimestampKey(end.UnixNano()))
+	// Entries expiring after this were already extended
+	normalExpiry := uint64(time.Now().Add(time.Duration(bs.retentionDays) * 24 * time.Hour).Unix())
+
+	return bs.db.Update(func(txn *badger.Txn) error {
+		var entries []*badger.Entry
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = prefix
+		it := txn.NewIterator(opts)
+		for it.Seek(startKey); it.ValidForPrefix(prefix); it.Next() {
+			item := it.Item()
+			if bytes.Compare(item.Key(), endKey) >= 0 {
+				break
+			}
+			if expires := item.ExpiresAt(); expires == 0 || expires > normalExpiry {
+				continue
+			}
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				it.Close()
+				return err
+			}
+			entries = append(entries, badger.NewEntry(item.KeyCopy(nil), value).WithTTL(ttl))
+		}
+		it.Close()
+
+		for _, entry := range entries {
+			if err := txn.SetEntry(entry); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// cleanNonFailures deletes results older than cutoff unless they are failures
+// or within the failure context of one
+func (ps *PostgresStore) cleanNonFailures(cutoff time.Time) {
+	query := `
+		DELETE FROM monitor_results r
+		WHERE r.timestamp < $1 AND r.status <> 'down'
+		AND NOT EXISTS (
+			SELECT 1 FROM monitor_results f
+			WHERE f.monitor = r.monitor AND f.status = 'down'
+			AND f.timestamp BETWEEN r.timestamp - make_interval(secs => $2) AND r.timestamp + make_interval(secs => $2)
+		)
+	`
+	result, err := ps.pool.Exec(ps.ctx, query, cutoff, ps.failures.Context.Seconds())
+	if err != nil {
+		ps.logger.WithComponent("storage").
+			WithError(err).
+			Error("Failed to clean old data")
+		return
+	}
+
+	if rows := result.RowsAffected(); rows > 0 {
+		ps.logger.WithComponent("storage").
+			WithFields(map[string]interface{}{
+				"rows_deleted": rows,
+				"cutoff_date":  cutoff,
+			}).
+			Info("Cleaned old monitor results outside failure retention")
+	}
+}
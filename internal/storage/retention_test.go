@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestBadgerStore_FailureRetention(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "hallmonitor-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	logger, _ := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	store, err := NewBadgerStoreWithOptions(tmpDir, 1, BadgerOptions{
+		FailureRetention: FailureRetention{Days: 90, Context: 5 * time.Minute},
+	}, logger)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	failure := time.Now().Truncate(time.Second)
+	tests := []struct {
+		name     string
+		status   models.MonitorStatus
+		offset   time.Duration
+		wantKept bool
+	}{
+		{name: "long before", status: models.StatusUp, offset: -10 * time.Minute, wantKept: false},
+		{name: "just before", status: models.StatusUp, offset: -3 * time.Minute, wantKept: true},
+		{name: "failure", status: models.StatusDown, offset: 0, wantKept: true},
+		{name: "just after", status: models.StatusUp, offset: 2 * time.Minute, wantKept: true},
+		{name: "long after", status: models.StatusUp, offset: 10 * time.Minute, wantKept: false},
+	}
+	for _, tt := range tests {
+		result := &models.MonitorResult{Monitor: "api", Status: tt.status, Timestamp: failure.Add(tt.offset)}
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+
+	// Kept results expire after the failure retention rather than the normal one
+	normalExpiry := uint64(time.Now().Add(48 * time.Hour).Unix())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := fmt.Sprintf("%s:%s:%s", resultKeyPrefix, monitorKeySegment("api"), formatTimestampKey(failure.Add(tt.offset).UnixNano()))
+			err := store.db.View(func(txn *badger.Txn) error {
+				item, err := txn.Get([]byte(key))
+				if err != nil {
+					return err
+				}
+				if kept := item.ExpiresAt() > normalExpiry; kept != tt.wantKept {
+					t.Errorf("kept = %v, want %v", kept, tt.wantKept)
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Failed to read result: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// rollingHours is how many hourly buckets the ingest counters keep: the last
+// day plus the current hour
+const rollingHours = 25
+
+// rollingBucket counts the results stored for one hour
+type rollingBucket struct {
+	hour   time.Time
+	counts ResultCounts
+}
+
+// rollingCounter keeps per-hour result counts for the last day, updated as
+// results are stored, so uptime for recent hours that have not been
+// aggregated yet does not need raw scans
+type rollingCounter struct {
+	since    time.Time // Hours starting earlier were not fully observed
+	monitors map[string]*[rollingHours]rollingBucket
+	mu       sync.Mutex
+}
+
+// newRollingCounter creates a counter that trusts hours starting after now
+func newRollingCounter(now time.Time) *rollingCounter {
+	return &rollingCounter{
+		since:    now,
+		monitors: make(map[string]*[rollingHours]rollingBucket),
+	}
+}
+
+// Record counts a stored result standing for weight checks
+func (rc *rollingCounter) Record(monitor string, timestamp time.Time, status models.MonitorStatus, weight int) {
+	hour := timestamp.Truncate(time.Hour)
+	if hour.Before(oldestRollingHour()) {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	buckets, ok := rc.monitors[monitor]
+	if !ok {
+		buckets = &[rollingHours]rollingBucket{}
+		rc.monitors[monitor] = buckets
+	}
+
+	bucket := &buckets[rollingIndex(hour)]
+	if !bucket.hour.Equal(hour) {
+		*bucket = rollingBucket{hour: hour}
+	}
+	bucket.counts.AddN(status, weight)
+}
+
+// Counts returns the results stored during the hour starting at hour, or
+// false if the counter did not observe the whole hour
+func (rc *rollingCounter) Counts(monitor string, hour time.Time) (ResultCounts, bool) {
+	if hour.Before(rc.since) || hour.Before(oldestRollingHour()) {
+		return ResultCounts{}, false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	buckets, ok := rc.monitors[monitor]
+	if !ok {
+		return ResultCounts{}, true
+	}
+	if bucket := buckets[rollingIndex(hour)]; bucket.hour.Equal(hour) {
+		return bucket.counts, true
+	}
+	return ResultCounts{}, true
+}
+
+// oldestRollingHour returns the start of the oldest hour the counters keep
+func oldestRollingHour() time.Time {
+	return time.Now().Truncate(time.Hour).Add(-(rollingHours - 1) * time.Hour)
+}
+
+// rollingIndex maps an hour to its bucket
+func rollingIndex(hour time.Time) int {
+	return int(hour.Unix()/3600) % rollingHours
+}
+
+// CountUptime counts a monitor's results within a time range using rollups
+// wherever they cover a whole period: daily and hourly aggregates, then the
+// ingest counters for recent hours. Raw results are only scanned for the
+// partial hours at either end and for hours no rollup covers.
+func (bs *BadgerStore) CountUptime(monitor string, start, end time.Time) (ResultCounts, error) {
+	firstHour := start.Truncate(time.Hour)
+	if firstHour.Before(start) {
+		firstHour = firstHour.Add(time.Hour)
+	}
+	lastHour := end.Truncate(time.Hour)
+	if !firstHour.Before(lastHour) {
+		return bs.CountResults(monitor, start, end)
+	}
+
+	daily, err := bs.aggregatesByStart(monitor, "day", firstHour.Truncate(24*time.Hour), lastHour)
+	if err != nil {
+		return ResultCounts{}, err
+	}
+	hourly, err := bs.aggregatesByStart(monitor, "hour", firstHour, lastHour.Add(-time.Hour))
+	if err != nil {
+		return ResultCounts{}, err
+	}
+
+	var counts ResultCounts
+	// Raw ranges are half-open, so a result on a boundary is counted once
+	addRaw := func(from, to time.Time) error {
+		if !from.Before(to) {
+			return nil
+		}
+		raw, err := bs.CountResults(monitor, from, to.Add(-time.Nanosecond))
+		if err != nil {
+			return err
+		}
+		counts.merge(raw)
+		return nil
+	}
+
+	if err := addRaw(start, firstHour); err != nil {
+		return ResultCounts{}, err
+	}
+
+	// Consecutive hours without a rollup are scanned together
+	var uncovered time.Time
+	for hour := firstHour; hour.Before(lastHour); {
+		rollup, next, ok := bs.rollupAt(monitor, hour, lastHour, daily, hourly)
+		if !ok {
+			if uncovered.IsZero() {
+				uncovered = hour
+			}
+			hour = next
+			continue
+		}
+
+		if !uncovered.IsZero() {
+			if err := addRaw(uncovered, hour); err != nil {
+				return ResultCounts{}, err
+			}
+			uncovered = time.Time{}
+		}
+		counts.merge(rollup)
+		hour = next
+	}
+	if !uncovered.IsZero() {
+		if err := addRaw(uncovered, lastHour); err != nil {
+			return ResultCounts{}, err
+		}
+	}
+
+	// The end of the range is inclusive, like CountResults
+	raw, err := bs.CountResults(monitor, lastHour, end)
+	if err != nil {
+		return ResultCounts{}, err
+	}
+	counts.merge(raw)
+
+	return counts, nil
+}
+
+// rollupAt returns the counts of the largest rollup starting at hour that
+// ends by lastHour, along with where it ends
+func (bs *BadgerStore) rollupAt(monitor string, hour, lastHour time.Time, daily, hourly map[int64]*models.AggregateResult) (ResultCounts, time.Time, bool) {
+	nextHour := hour.Add(time.Hour)
+
+	if nextDay := hour.Add(24 * time.Hour); hour.Truncate(24*time.Hour).Equal(hour) && !nextDay.After(lastHour) {
+		if agg, ok := daily[hour.Unix()]; ok {
+			return aggregateCounts(agg), nextDay, true
+		}
+	}
+	if agg, ok := hourly[hour.Unix()]; ok {
+		return aggregateCounts(agg), nextHour, true
+	}
+	if bs.rolling != nil {
+		if counts, ok := bs.rolling.Counts(monitor, hour); ok {
+			return counts, nextHour, true
+		}
+	}
+	return ResultCounts{}, nextHour, false
+}
+
+// aggregatesByStart loads aggregates whose periods start within a range,
+// keyed by period start
+func (bs *BadgerStore) aggregatesByStart(monitor, periodType string, start, end time.Time) (map[int64]*models.AggregateResult, error) {
+	aggregates, err := bs.GetAggregates(monitor, periodType, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s rollups: %w", periodType, err)
+	}
+
+	byStart := make(map[int64]*models.AggregateResult, len(aggregates))
+	for _, agg := range aggregates {
+		byStart[agg.PeriodStart.Unix()] = agg
+	}
+	return byStart, nil
+}
+
+// aggregateCounts returns the result counts recorded in an aggregate
+func aggregateCounts(agg *models.AggregateResult) ResultCounts {
+	return ResultCounts{Total: agg.TotalChecks, Up: agg.UpChecks, Down: agg.DownChecks}
+}
+
+// merge adds other's counts
+func (rc *ResultCounts) merge(other ResultCounts) {
+	rc.Total += other.Total
+	rc.Up += other.Up
+	rc.Down += other.Down
+}
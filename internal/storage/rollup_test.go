@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestRollingCounter(t *testing.T) {
+	now := time.Now()
+	currentHour := now.Truncate(time.Hour)
+	rc := newRollingCounter(currentHour.Add(-3 * time.Hour))
+
+	rc.Record("api", currentHour.Add(-2*time.Hour+time.Minute), models.StatusUp, 1)
+	rc.Record("api", currentHour.Add(-2*time.Hour+2*time.Minute), models.StatusDown, 1)
+	rc.Record("api", currentHour.Add(-48*time.Hour), models.StatusUp, 1) // Outside the window
+
+	tests := []struct {
+		name   string
+		hour   time.Time
+		want   ResultCounts
+		wantOK bool
+	}{
+		{name: "recorded hour", hour: currentHour.Add(-2 * time.Hour), want: ResultCounts{Total: 2, Up: 1, Down: 1}, wantOK: true},
+		{name: "quiet hour", hour: currentHour.Add(-time.Hour), want: ResultCounts{}, wantOK: true},
+		{name: "before counter started", hour: currentHour.Add(-4 * time.Hour), wantOK: false},
+		{name: "outside window", hour: currentHour.Add(-48 * time.Hour), wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := rc.Counts("api", tt.hour)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Counts() = %+v, %v; want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBadgerStore_CountUptime(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	// A result every 10 minutes over two and a half days, every fifth one down
+	base := time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC)
+	last := base.Add(54 * time.Hour)
+	for i, ts := 0, base; ts.Before(last); i, ts = i+1, ts.Add(10*time.Minute) {
+		status := models.StatusUp
+		if i%5 == 0 {
+			status = models.StatusDown
+		}
+		if err := store.StoreResult(&models.MonitorResult{Monitor: "api", Status: status, Timestamp: ts}); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+
+	ranges := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{name: "unaligned", start: base.Add(3*time.Hour + 17*time.Minute), end: last.Add(-25 * time.Minute)},
+		{name: "aligned", start: base, end: base.Add(48 * time.Hour)},
+		{name: "within an hour", start: base.Add(5*time.Hour + 5*time.Minute), end: base.Add(5*time.Hour + 45*time.Minute)},
+		{name: "hour boundaries", start: base.Add(6 * time.Hour), end: base.Add(8 * time.Hour)},
+	}
+	assertMatchesRaw := func(t *testing.T) {
+		for _, r := range ranges {
+			want, err := store.CountResults("api", r.start, r.end)
+			if err != nil {
+				t.Fatalf("Failed to count raw results: %v", err)
+			}
+			got, err := store.CountUptime("api", r.start, r.end)
+			if err != nil {
+				t.Fatalf("CountUptime failed: %v", err)
+			}
+			if got != want {
+				t.Errorf("%s: CountUptime = %+v, want %+v", r.name, got, want)
+			}
+		}
+	}
+
+	t.Run("raw fallback", assertMatchesRaw)
+
+	// Leave a gap in the hourly aggregates so it has to be scanned
+	aggregator := NewAggregator(store, store.logger)
+	aggregate := func(from, until time.Time) {
+		for _, period := range aggregatePeriodTypes {
+			if _, err := aggregator.aggregatePeriods("api", period, from, until); err != nil {
+				t.Fatalf("Failed to aggregate: %v", err)
+			}
+		}
+	}
+	aggregate(base, base.Add(30*time.Hour))
+	aggregate(base.Add(33*time.Hour), last)
+
+	t.Run("aggregates", assertMatchesRaw)
+
+	t.Run("rollups replace raw scans", func(t *testing.T) {
+		start, end := base.Add(30*time.Minute), last.Add(-30*time.Minute)
+		want, err := store.CountResults("api", start, end)
+		if err != nil {
+			t.Fatalf("Failed to count raw results: %v", err)
+		}
+
+		// Inflate an hourly aggregate outside any complete day, and a daily one
+		inflate := func(periodType string, periodStart time.Time) {
+			aggs, err := store.GetAggregates("api", periodType, periodStart, periodStart)
+			if err != nil || len(aggs) != 1 {
+				t.Fatalf("Expected a %s aggregate at %v, got %v (err %v)", periodType, periodStart, aggs, err)
+			}
+			aggs[0].TotalChecks += 100
+			aggs[0].UpChecks += 100
+			if err := store.StoreAggregate(aggs[0]); err != nil {
+				t.Fatalf("Failed to store aggregate: %v", err)
+			}
+		}
+		inflate("hour", base.Add(50*time.Hour))
+		inflate("day", base.Add(24*time.Hour))
+
+		got, err := store.CountUptime("api", start, end)
+		if err != nil {
+			t.Fatalf("CountUptime failed: %v", err)
+		}
+		if got.Total != want.Total+200 || got.Up != want.Up+200 || got.Down != want.Down {
+			t.Errorf("CountUptime = %+v, want aggregates used over raw %+v", got, want)
+		}
+	})
+
+	t.Run("ingest counters", func(t *testing.T) {
+		now := time.Now()
+		store.rolling = newRollingCounter(now.Add(-48 * time.Hour))
+
+		currentHour := now.Truncate(time.Hour)
+		for ts := currentHour.Add(-3 * time.Hour); ts.Before(now); ts = ts.Add(15 * time.Minute) {
+			if err := store.StoreResult(&models.MonitorResult{Monitor: "fresh", Status: models.StatusUp, Timestamp: ts}); err != nil {
+				t.Fatalf("Failed to store result: %v", err)
+			}
+		}
+
+		start := currentHour.Add(-3 * time.Hour).Add(5 * time.Minute)
+		want, err := store.CountResults("fresh", start, now)
+		if err != nil {
+			t.Fatalf("Failed to count raw results: %v", err)
+		}
+		got, err := store.CountUptime("fresh", start, now)
+		if err != nil || got != want {
+			t.Fatalf("CountUptime = %+v (err %v), want %+v", got, err, want)
+		}
+
+		// A result only the counter knows about shows it is being used
+		store.rolling.Record("fresh", currentHour.Add(-90*time.Minute), models.StatusDown, 1)
+		got, err = store.CountUptime("fresh", start, now)
+		if err != nil {
+			t.Fatalf("CountUptime failed: %v", err)
+		}
+		if got.Total != want.Total+1 || got.Down != want.Down+1 {
+			t.Errorf("CountUptime = %+v, want counter used over raw %+v", got, want)
+		}
+	})
+}
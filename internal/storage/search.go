@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// ResultQuery describes a search across stored results. Empty fields match
+// everything.
+type ResultQuery struct {
+	// Text matches, case-insensitively, a substring of the error message or
+	// of a metadata value. A number also matches the HTTP status code.
+	Text          string
+	ErrorContains string               // Case-insensitive substring of the error message only
+	StatusCode    int                  // Exact HTTP status code
+	Status        models.MonitorStatus // Exact status
+	Monitors      []string             // Monitors to search; all when empty
+	Start         time.Time
+	End           time.Time
+	Limit         int
+}
+
+// textStatusCode returns the status code Text also matches, if it is one
+func (q ResultQuery) textStatusCode() (int, bool) {
+	code, err := strconv.Atoi(strings.TrimSpace(q.Text))
+	if err != nil || code < 100 || code > 599 {
+		return 0, false
+	}
+	return code, true
+}
+
+// Matches reports whether result satisfies every filter except the time
+// range and monitors, which backends apply while scanning
+func (q ResultQuery) Matches(result *models.MonitorResult) bool {
+	statusCode := 0
+	if result.HTTPResult != nil {
+		statusCode = result.HTTPResult.StatusCode
+	}
+
+	if q.Status != "" && result.Status != q.Status {
+		return false
+	}
+	if q.StatusCode != 0 && statusCode != q.StatusCode {
+		return false
+	}
+	if q.ErrorContains != "" && !strings.Contains(strings.ToLower(result.Error), strings.ToLower(q.ErrorContains)) {
+		return false
+	}
+	if q.Text == "" {
+		return true
+	}
+
+	if code, ok := q.textStatusCode(); ok && statusCode == code {
+		return true
+	}
+	text := strings.ToLower(q.Text)
+	if strings.Contains(strings.ToLower(result.Error), text) {
+		return true
+	}
+	if metadata, ok := result.Metadata.(map[string]interface{}); ok {
+		for _, value := range metadata {
+			if strings.Contains(strings.ToLower(fmt.Sprint(value)), text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// searchByStreaming runs query by streaming each monitor's results newest
+// first and keeping the matches
+func searchByStreaming(streamer ResultStreamer, monitors []string, query ResultQuery) ([]*models.MonitorResult, error) {
+	var matched []*models.MonitorResult
+	for _, monitor := range monitors {
+		found := 0
+		err := streamer.StreamResults(monitor, query.Start, query.End, true, func(result *models.MonitorResult) bool {
+			if !query.Matches(result) {
+				return true
+			}
+			matched = append(matched, result)
+			found++
+			// Older matches of this monitor cannot make the newest Limit overall
+			return query.Limit <= 0 || found < query.Limit
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", monitor, err)
+		}
+	}
+
+	return newestResults(matched, query.Limit), nil
+}
+
+// newestResults sorts results newest first and keeps at most limit of them
+func newestResults(results []*models.MonitorResult, limit int) []*models.MonitorResult {
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Timestamp.After(results[j].Timestamp)
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestResultQuery_Matches(t *testing.T) {
+	result := &models.MonitorResult{
+		Monitor:    "api",
+		Status:     models.StatusDown,
+		Error:      "Connection refused",
+		HTTPResult: &models.HTTPResult{StatusCode: 503},
+		Metadata:   map[string]interface{}{"region": "eu-west", "attempt": 3},
+	}
+
+	tests := []struct {
+		name  string
+		query ResultQuery
+		want  bool
+	}{
+		{name: "empty", query: ResultQuery{}, want: true},
+		{name: "error substring", query: ResultQuery{Text: "refused"}, want: true},
+		{name: "case-insensitive", query: ResultQuery{Text: "CONNECTION"}, want: true},
+		{name: "metadata value", query: ResultQuery{Text: "eu-"}, want: true},
+		{name: "non-string metadata", query: ResultQuery{Text: "3"}, want: true},
+		{name: "metadata key only", query: ResultQuery{Text: "region"}, want: false},
+		{name: "text as status code", query: ResultQuery{Text: "503"}, want: true},
+		{name: "no match", query: ResultQuery{Text: "timeout"}, want: false},
+		{name: "status code", query: ResultQuery{StatusCode: 503}, want: true},
+		{name: "other status code", query: ResultQuery{StatusCode: 500}, want: false},
+		{name: "status", query: ResultQuery{Status: models.StatusDown, Text: "refused"}, want: true},
+		{name: "other status", query: ResultQuery{Status: models.StatusUp, Text: "refused"}, want: false},
+		{name: "error contains", query: ResultQuery{ErrorContains: "REFUSED"}, want: true},
+		{name: "error contains skips metadata", query: ResultQuery{ErrorContains: "eu-west"}, want: false},
+		{name: "status and error", query: ResultQuery{Status: models.StatusDown, ErrorContains: "timeout"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Matches(result); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBadgerStore_SearchResults(t *testing.T) {
+	store, tmpDir := createTestStore(t)
+	defer func() {
+		store.Close()
+		os.RemoveAll(tmpDir)
+	}()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	results := []*models.MonitorResult{
+		{Monitor: "api", Status: models.StatusDown, Error: "connection refused", Timestamp: base},
+		{Monitor: "api", Status: models.StatusUp, Timestamp: base.Add(time.Minute)},
+		{Monitor: "api", Status: models.StatusDown, Error: "connection refused", Timestamp: base.Add(2 * time.Minute)},
+		{Monitor: "web", Status: models.StatusDown, Error: "Connection reset", Timestamp: base.Add(3 * time.Minute)},
+		{Monitor: "web", Status: models.StatusDown, HTTPResult: &models.HTTPResult{StatusCode: 502}, Timestamp: base.Add(4 * time.Minute)},
+	}
+	for _, result := range results {
+		if err := store.StoreResult(result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query ResultQuery
+		want  []time.Time
+	}{
+		{
+			name:  "across monitors",
+			query: ResultQuery{Text: "connection"},
+			want:  []time.Time{base.Add(3 * time.Minute), base.Add(2 * time.Minute), base},
+		},
+		{
+			name:  "limit keeps the newest",
+			query: ResultQuery{Text: "connection", Limit: 2},
+			want:  []time.Time{base.Add(3 * time.Minute), base.Add(2 * time.Minute)},
+		},
+		{
+			name:  "one monitor",
+			query: ResultQuery{Text: "connection", Monitors: []string{"api"}},
+			want:  []time.Time{base.Add(2 * time.Minute), base},
+		},
+		{
+			name:  "failures by error",
+			query: ResultQuery{Status: models.StatusDown, ErrorContains: "refused", Monitors: []string{"api"}},
+			want:  []time.Time{base.Add(2 * time.Minute), base},
+		},
+		{
+			name:  "status code",
+			query: ResultQuery{StatusCode: 502},
+			want:  []time.Time{base.Add(4 * time.Minute)},
+		},
+		{
+			name:  "time range",
+			query: ResultQuery{Text: "connection", Start: base.Add(time.Minute), End: base.Add(150 * time.Second)},
+			want:  []time.Time{base.Add(2 * time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := tt.query
+			if query.Start.IsZero() {
+				query.Start, query.End = base.Add(-time.Minute), time.Now()
+			}
+
+			found, err := store.SearchResults(query)
+			if err != nil {
+				t.Fatalf("SearchResults failed: %v", err)
+			}
+			if len(found) != len(tt.want) {
+				t.Fatalf("Expected %d results, got %d", len(tt.want), len(found))
+			}
+			for i, result := range found {
+				if !result.Timestamp.Equal(tt.want[i]) {
+					t.Errorf("Result %d at %v, want %v", i, result.Timestamp, tt.want[i])
+				}
+			}
+		})
+	}
+}
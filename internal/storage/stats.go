@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// StatsReporter is implemented by backends that can describe the data they
+// hold, so operators can check retention and growth
+type StatsReporter interface {
+	Stats() (StorageStats, error)
+}
+
+// StorageStats describes what a backend holds. Counts are keyed by what the
+// backend stores records under: key prefixes for BadgerDB, tables for
+// PostgreSQL and measurements for InfluxDB.
+type StorageStats struct {
+	RetentionDays        int              `json:"retention_days,omitempty"` // Zero when the backend's own policy applies
+	SizeBytes            int64            `json:"size_bytes,omitempty"`     // Zero when the backend doesn't report it
+	Counts               map[string]int64 `json:"counts"`
+	OldestResult         *time.Time       `json:"oldest_result,omitempty"`
+	NewestResult         *time.Time       `json:"newest_result,omitempty"`
+	LastRetentionCleanup *time.Time       `json:"last_retention_cleanup,omitempty"`
+}
+
+// retentionCleanupMetaKey is the metadata key holding when expired data was
+// last cleaned up
+const retentionCleanupMetaKey = "retention:last_cleanup"
+
+// metadataStore is implemented by backends that keep metadata
+type metadataStore interface {
+	SetMetadata(key string, value []byte) error
+	GetMetadata(key string) ([]byte, error)
+}
+
+// setMetadataTime stores a timestamp as metadata
+func setMetadataTime(store metadataStore, key string, t time.Time) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	return store.SetMetadata(key, data)
+}
+
+// getMetadataTime returns a timestamp stored as metadata, or nil if there is
+// none
+func getMetadataTime(store metadataStore, key string) (*time.Time, error) {
+	data, err := store.GetMetadata(key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var t time.Time
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", key, err)
+	}
+	return &t, nil
+}
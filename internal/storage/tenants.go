@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Tenants says which tenant each monitor and group belongs to. Monitors and
+// groups missing from the maps belong to no tenant.
+type Tenants struct {
+	Monitors map[string]string
+	Groups   map[string]string
+}
+
+// TenantScoper is implemented by backends that keep each tenant's data apart:
+// BadgerDB under a key prefix per tenant, PostgreSQL in a tenant column and
+// InfluxDB with a tenant tag. Monitor names stay the handle callers use; the
+// backend looks up the tenant of each name it is given.
+type TenantScoper interface {
+	// SetTenants replaces the tenants monitors and groups belong to. A
+	// monitor moved to another tenant starts a new history there.
+	SetTenants(tenants Tenants)
+}
+
+// TenantsFromConfig maps the monitors and groups of cfg's tenant groups to
+// their tenant
+func TenantsFromConfig(cfg *config.Config) Tenants {
+	tenants := Tenants{Monitors: make(map[string]string), Groups: make(map[string]string)}
+	for _, group := range cfg.Monitoring.Groups {
+		if group.Tenant == "" {
+			continue
+		}
+		tenants.Groups[group.Name] = group.Tenant
+		for _, monitor := range group.Monitors {
+			tenants.Monitors[monitor.Name] = group.Tenant
+		}
+	}
+	return tenants
+}
+
+// ApplyTenants passes cfg's tenants to store when it keeps tenants apart. It
+// must be called before results of cfg's monitors are stored.
+func ApplyTenants(store ResultStore, cfg *config.Config) {
+	if scoper, ok := store.(TenantScoper); ok {
+		scoper.SetTenants(TenantsFromConfig(cfg))
+	}
+}
+
+// tenantIndex holds the tenants set on a backend. The zero value puts every
+// monitor outside any tenant.
+type tenantIndex struct {
+	current atomic.Pointer[Tenants]
+}
+
+// SetTenants replaces the tenants monitors and groups belong to
+func (ti *tenantIndex) SetTenants(tenants Tenants) {
+	ti.current.Store(&tenants)
+}
+
+// tenantOf returns the tenant monitor belongs to, or "" for none
+func (ti *tenantIndex) tenantOf(monitor string) string {
+	if tenants := ti.current.Load(); tenants != nil {
+		return tenants.Monitors[monitor]
+	}
+	return ""
+}
+
+// annotationTenant returns the tenant of the monitor or group an annotation
+// is scoped to, or "" for annotations on no tenant or on everything
+func (ti *tenantIndex) annotationTenant(annotation *models.Annotation) string {
+	tenants := ti.current.Load()
+	switch {
+	case tenants == nil:
+		return ""
+	case annotation.Monitor != "":
+		return tenants.Monitors[annotation.Monitor]
+	default:
+		return tenants.Groups[annotation.Group]
+	}
+}
+
+// tenantNames returns every tenant that has a monitor or group, sorted
+func (ti *tenantIndex) tenantNames() []string {
+	tenants := ti.current.Load()
+	if tenants == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for _, tenant := range tenants.Groups {
+		seen[tenant] = true
+	}
+	for _, tenant := range tenants.Monitors {
+		seen[tenant] = true
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
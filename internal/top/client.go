@@ -0,0 +1,143 @@
+package top
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/1broseidon/hallmonitor/internal/api"
+)
+
+// Client reads monitor statuses from a running server
+type Client struct {
+	BaseURL string
+	Token   string // Sent as a bearer token when set
+	HTTP    *http.Client
+}
+
+// Monitors fetches the current status of every monitor
+func (c *Client) Monitors(ctx context.Context) ([]api.MonitorStatus, error) {
+	resp, err := c.get(ctx, "/api/v1/monitors", "application/json", "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var listing struct {
+		Monitors []api.MonitorStatus `json:"monitors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return nil, fmt.Errorf("failed to decode monitors: %w", err)
+	}
+	return listing.Monitors, nil
+}
+
+// Stream follows the server's event stream, calling fn for each status
+// event, until the server closes the stream, ctx is done, or reading fails.
+// lastEventID resumes after an earlier stream; the ID of the last event read
+// is returned for the next call.
+func (c *Client) Stream(ctx context.Context, lastEventID string, fn func(api.StreamStatusEvent)) (string, error) {
+	resp, err := c.get(ctx, "/api/v1/stream", "text/event-stream", lastEventID)
+	if err != nil {
+		return lastEventID, err
+	}
+	defer resp.Body.Close()
+
+	return readEvents(resp.Body, lastEventID, func(event, data string) error {
+		if event != "status" {
+			return nil
+		}
+		var status api.StreamStatusEvent
+		if err := json.Unmarshal([]byte(data), &status); err != nil {
+			return fmt.Errorf("failed to decode status event: %w", err)
+		}
+		fn(status)
+		return nil
+	})
+}
+
+// get sends a GET request and returns the response when it succeeded
+func (c *Client) get(ctx context.Context, path, accept, lastEventID string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GET %s failed: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("GET %s: %s", path, apiErr.Message)
+		}
+		return nil, fmt.Errorf("GET %s returned %s", path, resp.Status)
+	}
+	return resp, nil
+}
+
+// readEvents parses a server-sent event stream, calling fn with the type and
+// data of each event. It returns the last event ID seen, or lastEventID if
+// the stream carried none.
+func readEvents(r io.Reader, lastEventID string, fn func(event, data string) error) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event
+			if len(data) > 0 {
+				if event == "" {
+					event = "message"
+				}
+				if err := fn(event, strings.Join(data, "\n")); err != nil {
+					return lastEventID, err
+				}
+			}
+			event, data = "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			// Comment, such as a heartbeat
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			lastEventID = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return lastEventID, fmt.Errorf("failed to read stream: %w", err)
+	}
+	return lastEventID, nil
+}
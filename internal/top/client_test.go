@@ -0,0 +1,89 @@
+package top
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/internal/api"
+)
+
+func TestReadEvents(t *testing.T) {
+	stream := "retry: 1000\n\n" +
+		"id: 4\nevent: status\ndata: {\"monitor\":\"web\"}\n\n" +
+		": heartbeat\n\n" +
+		"id: 5\nevent: alert\ndata: {\"monitor\":\"web\",\n" +
+		"data: \"state\":\"firing\"}\n\n"
+
+	type event struct{ kind, data string }
+	var got []event
+	lastID, err := readEvents(strings.NewReader(stream), "2", func(kind, data string) error {
+		got = append(got, event{kind, data})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("readEvents() error = %v", err)
+	}
+	if lastID != "5" {
+		t.Errorf("last event ID = %q, want 5", lastID)
+	}
+	want := []event{{"status", `{"monitor":"web"}`}, {"alert", "{\"monitor\":\"web\",\n\"state\":\"firing\"}"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+
+	if lastID, _ := readEvents(strings.NewReader(": heartbeat\n\n"), "7", func(string, string) error { return nil }); lastID != "7" {
+		t.Errorf("expected the previous ID without new events, got %q", lastID)
+	}
+}
+
+func TestClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":true,"message":"Invalid token"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/monitors":
+			w.Write([]byte(`{"monitors":[{"name":"web","group":"core","status":"up","duration":"12ms"}],"total":1}`))
+		case "/api/v1/stream":
+			if r.Header.Get("Last-Event-ID") != "3" {
+				t.Errorf("expected the stream to resume from 3, got %q", r.Header.Get("Last-Event-ID"))
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("id: 4\nevent: status\ndata: {\"monitor\":\"web\",\"status\":\"down\",\"duration\":\"1s\"}\n\n" +
+				"id: 5\nevent: alert\ndata: {\"monitor\":\"web\"}\n\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Token: "secret"}
+	statuses, err := client.Monitors(context.Background())
+	if err != nil {
+		t.Fatalf("Monitors() error = %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Name != "web" || *statuses[0].Duration != "12ms" {
+		t.Errorf("unexpected monitors %+v", statuses)
+	}
+
+	var events []api.StreamStatusEvent
+	lastID, err := client.Stream(context.Background(), "3", func(event api.StreamStatusEvent) {
+		events = append(events, event)
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if lastID != "5" || len(events) != 1 || events[0].Status != "down" {
+		t.Errorf("expected one status event and last ID 5, got %+v and %q", events, lastID)
+	}
+
+	client.Token = "wrong"
+	if _, err := client.Monitors(context.Background()); err == nil || !strings.Contains(err.Error(), "Invalid token") {
+		t.Errorf("expected the server's message in the error, got %v", err)
+	}
+}
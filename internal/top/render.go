@@ -0,0 +1,223 @@
+package top
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ANSI escape sequences used by the screen
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiInvert = "\x1b[7m"
+	clearLine  = "\x1b[K"
+	cursorHome = "\x1b[H"
+	clearBelow = "\x1b[J"
+)
+
+// sparkBars are the block characters of a sparkline, lowest first
+var sparkBars = []rune("▁▂▃▄▅▆▇█")
+
+// Column widths; the name and error columns take the space that is left
+const (
+	statusWidth  = 8
+	groupWidth   = 14
+	typeWidth    = 6
+	latencyWidth = 9
+	checkedWidth = 8
+	minNameWidth = 12
+	maxNameWidth = 24
+)
+
+// Screen is what a frame shows besides the table
+type Screen struct {
+	Server string    // Server address shown in the title
+	State  string    // Connection state, such as "live" or a reconnect error
+	Now    time.Time // Time ages are measured from
+	Width  int
+	Height int
+}
+
+// Render draws one frame of the table sized to the screen. The frame starts
+// at the top left corner and clears what is left of the previous one.
+func Render(t *Table, screen Screen) string {
+	width, height := screen.Width, screen.Height
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+
+	var b strings.Builder
+	b.WriteString(cursorHome)
+
+	counts := t.Counts()
+	order, reverse := t.Sort()
+	direction := ""
+	if reverse {
+		direction = ", reversed"
+	}
+	title := fmt.Sprintf("hallmonitor top - %s - %s | %d up, %d down, %d unknown, %d disabled | sort: %s%s",
+		screen.Server, screen.State, counts["up"], counts["down"], counts["unknown"], counts["disabled"], order, direction)
+	writeLine(&b, ansiBold+fit(title, width)+ansiReset)
+
+	// Give the name column what the fixed columns and sparkline leave
+	fixed := statusWidth + groupWidth + typeWidth + latencyWidth + historySize + checkedWidth + 7
+	nameWidth := min(max(width-fixed, minNameWidth), maxNameWidth)
+	errorWidth := width - fixed - nameWidth - 1
+
+	header := pad("STATUS", statusWidth) + " " + pad("NAME", nameWidth) + " " + pad("GROUP", groupWidth) + " " +
+		pad("TYPE", typeWidth) + " " + padLeft("LATENCY", latencyWidth) + " " + pad("TREND", historySize) + " " +
+		padLeft("CHECKED", checkedWidth)
+	if errorWidth > 0 {
+		header += " " + "ERROR"
+	}
+	writeLine(&b, ansiInvert+pad(fit(header, width), width)+ansiReset)
+
+	rows := t.Rows()
+	// Title, header, and help line
+	space := height - 3
+	shown := rows
+	if len(rows) > space {
+		shown = rows[:max(space-1, 0)]
+	}
+	for _, row := range shown {
+		line := colorStatus(row.Status, pad(row.Status, statusWidth)) + " " +
+			pad(fit(row.Name, nameWidth), nameWidth) + " " +
+			pad(fit(row.Group, groupWidth), groupWidth) + " " +
+			pad(fit(row.Type, typeWidth), typeWidth) + " " +
+			padLeft(formatLatency(row), latencyWidth) + " " +
+			pad(Sparkline(row.History, historySize), historySize) + " " +
+			padLeft(formatAge(row.LastCheck, screen.Now), checkedWidth)
+		if errorWidth > 0 && row.Error != "" {
+			line += " " + ansiDim + fit(oneLine(row.Error), errorWidth) + ansiReset
+		}
+		writeLine(&b, line)
+	}
+	if hidden := len(rows) - len(shown); hidden > 0 {
+		writeLine(&b, ansiDim+fmt.Sprintf("... %d more monitors; enlarge the terminal to see them", hidden)+ansiReset)
+	}
+
+	b.WriteString(clearBelow)
+	// The help line sits on the last row of the screen
+	fmt.Fprintf(&b, "\x1b[%d;1H", height)
+	b.WriteString(ansiDim + fit("q quit  s change sort  r reverse sort", width) + ansiReset + clearLine)
+	return b.String()
+}
+
+// Sparkline draws samples as block characters scaled to the largest one,
+// keeping the last width samples
+func Sparkline(samples []time.Duration, width int) string {
+	if len(samples) > width {
+		samples = samples[len(samples)-width:]
+	}
+	var peak time.Duration
+	for _, sample := range samples {
+		peak = max(peak, sample)
+	}
+
+	spark := make([]rune, 0, len(samples))
+	for _, sample := range samples {
+		level := 0
+		if peak > 0 && sample > 0 {
+			level = int(int64(sample) * int64(len(sparkBars)-1) / int64(peak))
+		}
+		spark = append(spark, sparkBars[level])
+	}
+	return string(spark)
+}
+
+// writeLine writes a table line and clears the rest of the terminal row
+func writeLine(b *strings.Builder, line string) {
+	b.WriteString(line)
+	b.WriteString(clearLine)
+	b.WriteString("\r\n")
+}
+
+// colorStatus colors text by the status it shows
+func colorStatus(status, text string) string {
+	switch status {
+	case "up":
+		return ansiGreen + text + ansiReset
+	case "down":
+		return ansiRed + ansiBold + text + ansiReset
+	case "disabled":
+		return ansiDim + text + ansiReset
+	default:
+		return ansiYellow + text + ansiReset
+	}
+}
+
+// formatLatency shows the latency of the last check
+func formatLatency(row *Row) string {
+	if row.LastCheck.IsZero() {
+		return "-"
+	}
+	switch {
+	case row.Latency >= 10*time.Second:
+		return row.Latency.Round(time.Second).String()
+	case row.Latency >= time.Millisecond:
+		return row.Latency.Round(time.Millisecond / 10).String()
+	default:
+		return row.Latency.Round(time.Microsecond).String()
+	}
+}
+
+// formatAge shows how long ago a check ran
+func formatAge(at, now time.Time) string {
+	if at.IsZero() {
+		return "never"
+	}
+	age := now.Sub(at)
+	switch {
+	case age < time.Second:
+		return "now"
+	case age < time.Minute:
+		return fmt.Sprintf("%ds ago", int(age.Seconds()))
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
+
+// oneLine keeps multi-line errors on their row
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// fit cuts s to width characters, marking the cut with an ellipsis
+func fit(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:max(width, 0)])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// pad fills s with spaces to width characters
+func pad(s string, width int) string {
+	if n := width - len([]rune(s)); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
+
+// padLeft right-aligns s in width characters
+func padLeft(s string, width int) string {
+	if n := width - len([]rune(s)); n > 0 {
+		return strings.Repeat(" ", n) + s
+	}
+	return s
+}
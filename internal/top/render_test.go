@@ -0,0 +1,66 @@
+package top
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/api"
+)
+
+func TestSparkline(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name    string
+		samples []time.Duration
+		width   int
+		want    string
+	}{
+		{name: "empty", width: 5, want: ""},
+		{name: "scaled to peak", samples: []time.Duration{0, 10 * ms, 35 * ms, 70 * ms}, width: 5, want: "▁▂▄█"},
+		{name: "flat", samples: []time.Duration{5 * ms, 5 * ms}, width: 5, want: "██"},
+		{name: "keeps latest", samples: []time.Duration{70 * ms, 0, 70 * ms}, width: 2, want: "▁█"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.samples, tt.width); got != tt.want {
+				t.Errorf("Sparkline() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	table := NewTable(SortStatus)
+	table.Apply(api.StreamStatusEvent{Monitor: "web", Group: "core", Type: "http", Status: "up", Duration: "120ms", Timestamp: now.Add(-5 * time.Second)})
+	table.Apply(api.StreamStatusEvent{Monitor: "db", Group: "data", Type: "tcp", Status: "down", Duration: "2s", Error: "connection\nrefused", Timestamp: now.Add(-2 * time.Minute)})
+
+	frame := Render(table, Screen{Server: "http://localhost:7878", State: "live", Now: now, Width: 160, Height: 10})
+	for _, want := range []string{"http://localhost:7878", "live", "1 up, 1 down", "sort: status", "STATUS", "120ms", "5s ago", "2m ago", "connection refused", "q quit"} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("frame is missing %q", want)
+		}
+	}
+	if strings.Index(frame, "db") > strings.Index(frame, "web") {
+		t.Error("expected the down monitor to be listed first")
+	}
+
+	// Rows that do not fit are counted instead
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		table.Apply(api.StreamStatusEvent{Monitor: name, Status: "up", Duration: "1ms", Timestamp: now})
+	}
+	frame = Render(table, Screen{Now: now, Width: 80, Height: 8})
+	if !strings.Contains(frame, "... 4 more monitors") {
+		t.Errorf("expected hidden rows to be counted, got %q", frame)
+	}
+}
+
+func TestFit(t *testing.T) {
+	if got := fit("hallmonitor", 5); got != "hall…" {
+		t.Errorf("fit() = %q", got)
+	}
+	if got := fit("web", 5); got != "web" {
+		t.Errorf("fit() = %q", got)
+	}
+}
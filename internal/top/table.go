@@ -0,0 +1,190 @@
+// Package top implements `hallmonitor top`, a live status table for a
+// terminal that follows a running server's event stream
+package top
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/api"
+)
+
+// historySize is the number of latency samples kept per monitor for its sparkline
+const historySize = 30
+
+// Sort orders of the table
+const (
+	SortStatus  = "status"
+	SortName    = "name"
+	SortGroup   = "group"
+	SortLatency = "latency"
+)
+
+// sortOrders lists the sort orders in the order the s key cycles through them
+var sortOrders = []string{SortStatus, SortName, SortGroup, SortLatency}
+
+// statusRank orders statuses so the ones needing attention come first
+var statusRank = map[string]int{
+	"down":     0,
+	"unknown":  1,
+	"up":       2,
+	"disabled": 3,
+}
+
+// ValidSort reports whether order is a known sort order
+func ValidSort(order string) bool {
+	for _, known := range sortOrders {
+		if order == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Row is the state of one monitor in the table
+type Row struct {
+	Name      string
+	Group     string
+	Type      string
+	Status    string
+	Latency   time.Duration
+	LastCheck time.Time
+	Error     string
+	History   []time.Duration // Latency of recent checks, oldest first
+}
+
+// Table holds the monitors shown by top and how they are sorted
+type Table struct {
+	rows    map[string]*Row
+	order   string
+	reverse bool
+}
+
+// NewTable creates an empty table sorted by order
+func NewTable(order string) *Table {
+	if !ValidSort(order) {
+		order = SortStatus
+	}
+	return &Table{rows: make(map[string]*Row), order: order}
+}
+
+// Load replaces the table's monitors with a listing from the monitors
+// endpoint. Latency history is kept for monitors that are still listed.
+func (t *Table) Load(statuses []api.MonitorStatus) {
+	rows := make(map[string]*Row, len(statuses))
+	for _, status := range statuses {
+		row := &Row{
+			Name:   status.Name,
+			Group:  status.Group,
+			Type:   status.Type,
+			Status: status.Status,
+		}
+		if previous, ok := t.rows[status.Name]; ok {
+			row.History = previous.History
+		}
+		if status.Duration != nil {
+			row.Latency, _ = time.ParseDuration(*status.Duration)
+		}
+		if status.LastCheck != nil {
+			row.LastCheck, _ = time.Parse(time.RFC3339, *status.LastCheck)
+		}
+		if status.Error != nil {
+			row.Error = *status.Error
+		}
+		if len(row.History) == 0 && !row.LastCheck.IsZero() {
+			row.History = []time.Duration{row.Latency}
+		}
+		rows[status.Name] = row
+	}
+	t.rows = rows
+}
+
+// Apply records a status event from the stream. Monitors the table has not
+// seen yet are added.
+func (t *Table) Apply(event api.StreamStatusEvent) {
+	row, ok := t.rows[event.Monitor]
+	if !ok {
+		row = &Row{Name: event.Monitor}
+		t.rows[event.Monitor] = row
+	}
+	latency, _ := time.ParseDuration(event.Duration)
+	row.Group = event.Group
+	row.Type = event.Type
+	row.Status = event.Status
+	row.Latency = latency
+	row.LastCheck = event.Timestamp
+	row.Error = event.Error
+	row.History = append(row.History, latency)
+	if len(row.History) > historySize {
+		row.History = row.History[len(row.History)-historySize:]
+	}
+}
+
+// Sort returns the current sort order and whether it is reversed
+func (t *Table) Sort() (string, bool) {
+	return t.order, t.reverse
+}
+
+// CycleSort switches to the next sort order
+func (t *Table) CycleSort() {
+	for i, order := range sortOrders {
+		if order == t.order {
+			t.order = sortOrders[(i+1)%len(sortOrders)]
+			return
+		}
+	}
+}
+
+// ToggleReverse flips the sort direction
+func (t *Table) ToggleReverse() {
+	t.reverse = !t.reverse
+}
+
+// Rows returns the monitors in the current sort order. Ties are broken by
+// name so rows do not jump around between updates.
+func (t *Table) Rows() []*Row {
+	rows := make([]*Row, 0, len(t.rows))
+	for _, row := range t.rows {
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i], rows[j]
+		if t.reverse {
+			a, b = b, a
+		}
+		switch t.order {
+		case SortStatus:
+			if ra, rb := rank(a.Status), rank(b.Status); ra != rb {
+				return ra < rb
+			}
+		case SortGroup:
+			if a.Group != b.Group {
+				return strings.ToLower(a.Group) < strings.ToLower(b.Group)
+			}
+		case SortLatency:
+			if a.Latency != b.Latency {
+				return a.Latency > b.Latency
+			}
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	return rows
+}
+
+// Counts returns the number of monitors in each status
+func (t *Table) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, row := range t.rows {
+		counts[row.Status]++
+	}
+	return counts
+}
+
+// rank returns the sort position of a status; unknown values sort with "unknown"
+func rank(status string) int {
+	if r, ok := statusRank[status]; ok {
+		return r
+	}
+	return statusRank["unknown"]
+}
@@ -0,0 +1,106 @@
+package top
+
+import (
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/api"
+)
+
+func strPtr(s string) *string { return &s }
+
+func names(rows []*Row) []string {
+	out := make([]string, len(rows))
+	for i, row := range rows {
+		out[i] = row.Name
+	}
+	return out
+}
+
+func TestTableSort(t *testing.T) {
+	table := NewTable(SortStatus)
+	table.Load([]api.MonitorStatus{
+		{Name: "web", Group: "core", Status: "up", Duration: strPtr("120ms"), LastCheck: strPtr("2026-01-01T12:00:00Z")},
+		{Name: "db", Group: "data", Status: "down", Duration: strPtr("5s"), LastCheck: strPtr("2026-01-01T12:00:00Z")},
+		{Name: "cache", Group: "data", Status: "unknown"},
+		{Name: "api", Group: "core", Status: "up", Duration: strPtr("40ms"), LastCheck: strPtr("2026-01-01T12:00:00Z")},
+		{Name: "old", Group: "attic", Status: "disabled"},
+	})
+
+	tests := []struct {
+		order   string
+		reverse bool
+		want    []string
+	}{
+		{order: SortStatus, want: []string{"db", "cache", "api", "web", "old"}},
+		{order: SortName, want: []string{"api", "cache", "db", "old", "web"}},
+		{order: SortGroup, want: []string{"old", "api", "web", "cache", "db"}},
+		{order: SortLatency, want: []string{"db", "web", "api", "cache", "old"}},
+		{order: SortName, reverse: true, want: []string{"web", "old", "db", "cache", "api"}},
+	}
+	for _, tt := range tests {
+		table.order, table.reverse = tt.order, tt.reverse
+		got := names(table.Rows())
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: got %v, want %v", tt.order, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s reverse=%v: got %v, want %v", tt.order, tt.reverse, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestTableApply(t *testing.T) {
+	table := NewTable("bogus")
+	if order, _ := table.Sort(); order != SortStatus {
+		t.Errorf("expected an unknown sort order to fall back to status, got %s", order)
+	}
+	table.Load([]api.MonitorStatus{{Name: "web", Status: "up", Duration: strPtr("100ms"), LastCheck: strPtr("2026-01-01T12:00:00Z")}})
+
+	at := time.Date(2026, 1, 1, 12, 1, 0, 0, time.UTC)
+	for i := 0; i < historySize+5; i++ {
+		table.Apply(api.StreamStatusEvent{Monitor: "web", Group: "core", Type: "http", Status: "down", Duration: "2s", Error: "timeout", Timestamp: at})
+	}
+	table.Apply(api.StreamStatusEvent{Monitor: "new", Group: "core", Type: "tcp", Status: "up", Duration: "3ms", Timestamp: at})
+
+	rows := table.Rows()
+	if len(rows) != 2 {
+		t.Fatalf("expected the unseen monitor to be added, got %v", names(rows))
+	}
+	web := rows[0]
+	if web.Name != "web" || web.Status != "down" || web.Latency != 2*time.Second || web.Error != "timeout" || !web.LastCheck.Equal(at) {
+		t.Errorf("unexpected row after events: %+v", web)
+	}
+	if len(web.History) != historySize || web.History[0] != 2*time.Second {
+		t.Errorf("expected history capped at %d recent samples, got %v", historySize, web.History)
+	}
+
+	// Reloading keeps history for monitors that are still listed
+	table.Load([]api.MonitorStatus{{Name: "web", Status: "up"}})
+	rows = table.Rows()
+	if len(rows) != 1 || len(rows[0].History) != historySize {
+		t.Errorf("expected reload to drop removed monitors and keep history, got %+v", rows)
+	}
+	if counts := table.Counts(); counts["up"] != 1 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+}
+
+func TestTableCycleSort(t *testing.T) {
+	table := NewTable(SortStatus)
+	var seen []string
+	for range sortOrders {
+		table.CycleSort()
+		order, _ := table.Sort()
+		seen = append(seen, order)
+	}
+	want := []string{SortName, SortGroup, SortLatency, SortStatus}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Fatalf("cycle order %v, want %v", seen, want)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+package top
+
+import "golang.org/x/sys/unix"
+
+// ioctls that read and write terminal attributes
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
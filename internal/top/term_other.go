@@ -0,0 +1,19 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package top
+
+import "errors"
+
+// keyInputSupported reports whether single key presses can be read
+const keyInputSupported = false
+
+// enableKeyInput is not supported on this platform; top is left with
+// Ctrl+C to quit
+func enableKeyInput(int) (func(), error) {
+	return nil, errors.New("key input is not supported on this platform")
+}
+
+// terminalSize is not supported on this platform; callers fall back to 80x24
+func terminalSize(int) (int, int, error) {
+	return 0, 0, errors.New("terminal size is not supported on this platform")
+}
@@ -0,0 +1,195 @@
+package top
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/api"
+)
+
+// Terminal control sequences for entering and leaving the full screen view
+const (
+	enterScreen = "\x1b[?1049h\x1b[?25l"
+	leaveScreen = "\x1b[?25h\x1b[?1049l"
+)
+
+// listTimeout bounds fetching the monitor listing
+const listTimeout = 15 * time.Second
+
+// maxReconnectDelay caps the wait between attempts to reach a lost server
+const maxReconnectDelay = 30 * time.Second
+
+// Options configure a top session
+type Options struct {
+	BaseURL string // Server to follow, such as http://localhost:7878
+	Token   string // API token, when the server requires one
+	Sort    string // Initial sort order
+}
+
+// update is a change to the table from the stream follower
+type update struct {
+	listing []api.MonitorStatus // A fresh listing to load, after reconnecting
+	event   *api.StreamStatusEvent
+	state   string // New connection state, when set
+}
+
+// Run shows the live table on the terminal until ctx is done or q is pressed
+func Run(ctx context.Context, opts Options) error {
+	client := &Client{
+		BaseURL: strings.TrimSuffix(opts.BaseURL, "/"),
+		Token:   opts.Token,
+		HTTP:    &http.Client{},
+	}
+
+	// Fail before taking over the screen if the server cannot be reached
+	listCtx, cancelList := context.WithTimeout(ctx, listTimeout)
+	statuses, err := client.Monitors(listCtx)
+	cancelList()
+	if err != nil {
+		return err
+	}
+	table := NewTable(opts.Sort)
+	table.Load(statuses)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates := make(chan update, 256)
+	go follow(ctx, client, updates)
+
+	keys := make(chan byte)
+	if keyInputSupported {
+		if restore, err := enableKeyInput(int(os.Stdin.Fd())); err == nil {
+			defer restore()
+			go readKeys(os.Stdin, keys)
+		}
+	}
+
+	out := os.Stdout
+	fmt.Fprint(out, enterScreen)
+	defer fmt.Fprint(out, leaveScreen)
+
+	screen := Screen{Server: client.BaseURL, State: "connecting"}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		screen.Now = time.Now()
+		screen.Width, screen.Height, err = terminalSize(int(out.Fd()))
+		if err != nil {
+			screen.Width, screen.Height = 80, 24
+		}
+		fmt.Fprint(out, Render(table, screen))
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case u := <-updates:
+			// Apply everything queued so a burst of results draws once
+			for more := true; more; {
+				apply(table, &screen, u)
+				select {
+				case u = <-updates:
+				default:
+					more = false
+				}
+			}
+		case key := <-keys:
+			switch key {
+			case 'q', 'Q':
+				return nil
+			case 's', 'S':
+				table.CycleSort()
+			case 'r', 'R':
+				table.ToggleReverse()
+			}
+		case <-ticker.C:
+			// Redraw so ages advance and resizes are picked up
+		}
+	}
+}
+
+// apply makes an update's changes to the table and screen
+func apply(table *Table, screen *Screen, u update) {
+	if u.listing != nil {
+		table.Load(u.listing)
+	}
+	if u.event != nil {
+		table.Apply(*u.event)
+	}
+	if u.state != "" {
+		screen.State = u.state
+	}
+}
+
+// follow reads the event stream into updates until ctx is done. The server
+// ends streams periodically, so they are resumed right away from the last
+// event; when the server cannot be reached it is retried with backoff, and
+// the listing is fetched again once it is back.
+func follow(ctx context.Context, client *Client, updates chan<- update) {
+	send := func(u update) {
+		select {
+		case updates <- u:
+		case <-ctx.Done():
+		}
+	}
+
+	var lastEventID string
+	delay := time.Second
+	lost := false
+	for ctx.Err() == nil {
+		if lost {
+			listCtx, cancel := context.WithTimeout(ctx, listTimeout)
+			statuses, err := client.Monitors(listCtx)
+			cancel()
+			if err != nil {
+				send(update{state: "reconnecting: " + err.Error()})
+				delay = wait(ctx, delay)
+				continue
+			}
+			send(update{listing: statuses})
+			lost = false
+		}
+
+		send(update{state: "live"})
+		var err error
+		lastEventID, err = client.Stream(ctx, lastEventID, func(event api.StreamStatusEvent) {
+			send(update{event: &event})
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			send(update{state: "reconnecting: " + err.Error()})
+			lost = true
+			delay = wait(ctx, delay)
+			continue
+		}
+		delay = time.Second
+	}
+}
+
+// wait sleeps for delay or until ctx is done and returns the next delay
+func wait(ctx context.Context, delay time.Duration) time.Duration {
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+	}
+	return min(delay*2, maxReconnectDelay)
+}
+
+// readKeys sends each byte read from r to keys until reading fails
+func readKeys(r io.Reader, keys chan<- byte) {
+	buf := make([]byte, 1)
+	for {
+		if _, err := r.Read(buf); err != nil {
+			return
+		}
+		keys <- buf[0]
+	}
+}
@@ -0,0 +1,400 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/i18n"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Notification events a webhook can subscribe to
+const (
+	EventDown          = "down"
+	EventRecovered     = "recovered"
+	EventTargetChanged = "target_changed"
+)
+
+// Event is a single monitor transition within a notification
+type Event struct {
+	Monitor   string    `json:"monitor"`
+	Group     string    `json:"group,omitempty"`
+	Event     string    `json:"event"`
+	Error     string    `json:"error,omitempty"`
+	Quiet     string    `json:"quiet,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+
+	// TargetChange is set on target_changed events
+	TargetChange *models.TargetChange `json:"target_change,omitempty"`
+
+	// Ownership details from the monitor's config, so responders know who to contact
+	Owner       string `json:"owner,omitempty"`
+	Team        string `json:"team,omitempty"`
+	RunbookURL  string `json:"runbook_url,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// MonitorSource looks up monitors by name for their ownership details
+type MonitorSource interface {
+	GetMonitorByName(name string) monitors.Monitor
+}
+
+// Notification is the JSON body posted to a notification webhook. Text is
+// duplicated into content so Slack and Discord both render it.
+type Notification struct {
+	Title   string  `json:"title"`
+	Text    string  `json:"text"`
+	Content string  `json:"content"`
+	Group   string  `json:"group,omitempty"`
+	Events  []Event `json:"events"`
+}
+
+// Notifier posts a message when a monitor goes down or recovers, or, if
+// subscribed, when its target changes. With a group window, events in the same
+// group are collected for that long and sent as one digest, keeping only the
+// latest transition and target change per monitor.
+type Notifier struct {
+	url     string
+	plugin  *notifyPlugin
+	events  map[string]bool
+	window  time.Duration
+	timeout time.Duration
+	catalog *i18n.Catalog
+	client  *http.Client
+	logger  *logging.Logger
+	source  MonitorSource
+
+	lastStatus map[string]models.MonitorStatus
+	pending    map[string]*digest
+	stopped    bool
+	mu         sync.Mutex
+	wg         sync.WaitGroup
+}
+
+// digest collects a group's transitions until its window closes
+type digest struct {
+	events map[string]Event
+	timer  *time.Timer
+}
+
+// NewNotifier creates a notification webhook from configuration. Plugins are
+// run from pluginDir.
+func NewNotifier(cfg config.WebhookConfig, pluginDir string, logger *logging.Logger) *Notifier {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	events := make(map[string]bool)
+	for _, event := range cfg.Events {
+		events[strings.ToLower(event)] = true
+	}
+	if len(events) == 0 {
+		events[EventDown] = true
+		events[EventRecovered] = true
+	}
+
+	n := &Notifier{
+		url:        cfg.URL,
+		events:     events,
+		window:     cfg.GroupWindow,
+		timeout:    timeout,
+		catalog:    i18n.Get(cfg.Locale),
+		client:     &http.Client{Timeout: timeout},
+		logger:     logger,
+		lastStatus: make(map[string]models.MonitorStatus),
+		pending:    make(map[string]*digest),
+	}
+	if cfg.Plugin != "" {
+		n.plugin = &notifyPlugin{dir: pluginDir, name: cfg.Plugin, args: cfg.PluginArgs, env: cfg.PluginEnv, options: cfg.Options}
+	}
+	return n
+}
+
+// SetMonitorSource sets where notifications look up monitor ownership details
+func (n *Notifier) SetMonitorSource(source MonitorSource) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.source = source
+}
+
+// HandleResult notifies on down and recovered transitions and target changes
+func (n *Notifier) HandleResult(result *models.MonitorResult) {
+	if result == nil {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if result.Status != models.StatusUnknown {
+		previous, seen := n.lastStatus[result.Monitor]
+		n.lastStatus[result.Monitor] = result.Status
+
+		switch {
+		case result.Status == models.StatusDown && previous != models.StatusDown:
+			n.notify(result, EventDown)
+		case result.Status == models.StatusUp && seen && previous == models.StatusDown:
+			n.notify(result, EventRecovered)
+		}
+	}
+	if result.TargetChange != nil {
+		n.notify(result, EventTargetChanged)
+	}
+}
+
+// notify sends or queues an event for result. Must be called with n.mu held.
+func (n *Notifier) notify(result *models.MonitorResult, event string) {
+	if n.stopped || !n.events[event] {
+		return
+	}
+	// The transition is still recorded so recovery after quiet hours is sent
+	if result.Quiet == models.QuietSuppress {
+		n.logger.WithComponent(logging.ComponentWebhook).
+			WithFields(map[string]interface{}{
+				"monitor": result.Monitor,
+				"event":   event,
+			}).
+			Debug("Notification suppressed by quiet hours")
+		return
+	}
+
+	e := Event{
+		Monitor:   result.Monitor,
+		Group:     result.Group,
+		Event:     event,
+		Error:     result.Error,
+		Quiet:     result.Quiet,
+		Timestamp: result.Timestamp,
+	}
+	if event == EventTargetChanged {
+		e.TargetChange = result.TargetChange
+	}
+	if n.source != nil {
+		if monitor := n.source.GetMonitorByName(result.Monitor); monitor != nil {
+			cfg := monitor.GetConfig()
+			e.Owner = cfg.Owner
+			e.Team = cfg.Team
+			e.RunbookURL = cfg.RunbookURL
+			e.Description = cfg.Description
+		}
+	}
+
+	if n.window <= 0 {
+		n.deliver(result.Group, []Event{e})
+		return
+	}
+
+	d, ok := n.pending[result.Group]
+	if !ok {
+		d = &digest{events: make(map[string]Event)}
+		group := result.Group
+		d.timer = time.AfterFunc(n.window, func() { n.flushGroup(group) })
+		n.pending[group] = d
+	}
+	// Transitions replace each other; a target change is kept alongside them
+	key := result.Monitor
+	if event == EventTargetChanged {
+		key += "\x00" + event
+	}
+	d.events[key] = e
+}
+
+// Stop sends any pending digests and waits for deliveries to finish
+func (n *Notifier) Stop() error {
+	n.mu.Lock()
+	n.stopped = true
+	for group, d := range n.pending {
+		d.timer.Stop()
+		delete(n.pending, group)
+		n.deliver(group, d.sortedEvents())
+	}
+	n.mu.Unlock()
+
+	n.wg.Wait()
+	return nil
+}
+
+// flushGroup sends a group's digest once its window closes
+func (n *Notifier) flushGroup(group string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	d, ok := n.pending[group]
+	if !ok {
+		return
+	}
+	delete(n.pending, group)
+	n.deliver(group, d.sortedEvents())
+}
+
+// deliver posts a notification in the background; callers hold n.mu
+func (n *Notifier) deliver(group string, events []Event) {
+	notification := newNotification(n.catalog, group, events)
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+
+		fields := map[string]interface{}{
+			"group":  group,
+			"events": len(events),
+		}
+		var err error
+		if n.plugin != nil {
+			fields["plugin"] = n.plugin.name
+			ctx, cancel := context.WithTimeout(context.Background(), n.timeout)
+			err = n.plugin.send(ctx, notification)
+			cancel()
+		} else {
+			fields["url"] = n.url
+			err = n.send(context.Background(), notification)
+		}
+		if err != nil {
+			n.logger.WithComponent(logging.ComponentWebhook).
+				WithError(err).
+				WithFields(fields).
+				Warn("Failed to deliver notification webhook")
+		}
+	}()
+}
+
+// send POSTs a notification as JSON
+func (n *Notifier) send(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "HallMonitor/1.0")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// sortedEvents returns the digest's events ordered by time, then monitor
+func (d *digest) sortedEvents() []Event {
+	events := make([]Event, 0, len(d.events))
+	for _, e := range d.events {
+		events = append(events, e)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Timestamp.Equal(events[j].Timestamp) {
+			return events[i].Timestamp.Before(events[j].Timestamp)
+		}
+		return events[i].Monitor < events[j].Monitor
+	})
+	return events
+}
+
+// contact summarises who owns the monitor and where its runbook lives
+func (e Event) contact(catalog *i18n.Catalog) string {
+	var parts []string
+	switch {
+	case e.Owner != "" && e.Team != "":
+		parts = append(parts, catalog.T("notify.owner_team", e.Owner, e.Team))
+	case e.Owner != "":
+		parts = append(parts, catalog.T("notify.owner", e.Owner))
+	case e.Team != "":
+		parts = append(parts, catalog.T("notify.team", e.Team))
+	}
+	if e.RunbookURL != "" {
+		parts = append(parts, catalog.T("notify.runbook", e.RunbookURL))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// newNotification renders events as a message in catalog's language,
+// summarising digests in the title
+func newNotification(catalog *i18n.Catalog, group string, events []Event) Notification {
+	var title string
+	if len(events) == 1 {
+		e := events[0]
+		switch e.Event {
+		case EventRecovered:
+			title = catalog.T("notify.title.recovered", e.Monitor)
+		case EventTargetChanged:
+			title = catalog.T("notify.title.target_changed", e.Monitor)
+		default:
+			title = catalog.T("notify.title.down", e.Monitor)
+		}
+	} else {
+		down, recovered, moved := 0, 0, 0
+		changed := make(map[string]bool)
+		for _, e := range events {
+			changed[e.Monitor] = true
+			switch e.Event {
+			case EventDown:
+				down++
+			case EventTargetChanged:
+				moved++
+			default:
+				recovered++
+			}
+		}
+		var parts []string
+		if down > 0 {
+			parts = append(parts, catalog.T("notify.count.down", down))
+		}
+		if recovered > 0 {
+			parts = append(parts, catalog.T("notify.count.recovered", recovered))
+		}
+		if moved > 0 {
+			parts = append(parts, catalog.T("notify.count.target_changed", moved))
+		}
+		title = catalog.T("notify.digest", len(changed), strings.Join(parts, ", "))
+		if group != "" {
+			title = group + ": " + title
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString("**" + title + "**")
+	for _, e := range events {
+		line := fmt.Sprintf("\n- %s: %s", e.Monitor, catalog.T("notify.event."+e.Event))
+		if e.Error != "" && e.Event == EventDown {
+			line += " (" + e.Error + ")"
+		}
+		if e.TargetChange != nil {
+			line += " (" + e.TargetChange.String() + ")"
+		}
+		if e.Quiet == models.QuietDowngrade {
+			line += " [" + catalog.T("notify.quiet_hours") + "]"
+		}
+		if contact := e.contact(catalog); contact != "" && e.Event == EventDown {
+			line += "; " + contact
+		}
+		text.WriteString(line)
+	}
+
+	return Notification{
+		Title:   title,
+		Text:    text.String(),
+		Content: text.String(),
+		Group:   group,
+		Events:  events,
+	}
+}
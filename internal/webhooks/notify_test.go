@@ -0,0 +1,274 @@
+package webhooks
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/i18n"
+	"github.com/1broseidon/hallmonitor/internal/metrics"
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+type inbox struct {
+	mu       sync.Mutex
+	messages []Notification
+}
+
+func (i *inbox) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var notification Notification
+		if err := json.NewDecoder(r.Body).Decode(&notification); err != nil {
+			t.Errorf("failed to decode notification: %v", err)
+		}
+		i.mu.Lock()
+		i.messages = append(i.messages, notification)
+		i.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (i *inbox) received() []Notification {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return append([]Notification(nil), i.messages...)
+}
+
+func transition(monitor, group string, status models.MonitorStatus) *models.MonitorResult {
+	return &models.MonitorResult{Monitor: monitor, Group: group, Status: status, Timestamp: time.Now()}
+}
+
+func TestNotifierTransitions(t *testing.T) {
+	box := &inbox{}
+	server := httptest.NewServer(box.handler(t))
+	defer server.Close()
+
+	notifier := NewNotifier(config.WebhookConfig{URL: server.URL, Events: []string{"down"}}, "", testLogger(t))
+
+	notifier.HandleResult(transition("api", "core", models.StatusUp))
+	notifier.HandleResult(transition("api", "core", models.StatusDown))
+	notifier.HandleResult(transition("api", "core", models.StatusDown))
+	notifier.HandleResult(transition("api", "core", models.StatusUp)) // Not subscribed
+
+	// Suppressed outages are not sent, but their recovery is still tracked
+	quiet := transition("db", "core", models.StatusDown)
+	quiet.Quiet = models.QuietSuppress
+	notifier.HandleResult(quiet)
+
+	if err := notifier.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	messages := box.received()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(messages))
+	}
+	if messages[0].Title != "api is down" || messages[0].Content != messages[0].Text {
+		t.Errorf("unexpected notification %+v", messages[0])
+	}
+}
+
+func TestNotifierGroupsDigests(t *testing.T) {
+	box := &inbox{}
+	server := httptest.NewServer(box.handler(t))
+	defer server.Close()
+
+	notifier := NewNotifier(config.WebhookConfig{URL: server.URL, GroupWindow: time.Hour}, "", testLogger(t))
+
+	for _, monitor := range []string{"web", "api", "db"} {
+		notifier.HandleResult(transition(monitor, "host-a", models.StatusUp))
+		notifier.HandleResult(transition(monitor, "host-a", models.StatusDown))
+	}
+	// A monitor flapping within the window keeps only its latest event
+	notifier.HandleResult(transition("web", "host-a", models.StatusUp))
+	notifier.HandleResult(transition("dns", "edge", models.StatusDown))
+
+	if got := len(box.received()); got != 0 {
+		t.Fatalf("expected notifications to wait for the window, got %d", got)
+	}
+
+	// Stop sends pending digests without waiting for the window
+	if err := notifier.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	messages := box.received()
+	if len(messages) != 2 {
+		t.Fatalf("expected one notification per group, got %d", len(messages))
+	}
+	byGroup := make(map[string]Notification)
+	for _, message := range messages {
+		byGroup[message.Group] = message
+	}
+
+	digest := byGroup["host-a"]
+	if len(digest.Events) != 3 {
+		t.Fatalf("expected 3 events in the host-a digest, got %d", len(digest.Events))
+	}
+	if want := "host-a: 3 monitors changed: 2 down, 1 recovered"; digest.Title != want {
+		t.Errorf("expected title %q, got %q", want, digest.Title)
+	}
+	if got := byGroup["edge"].Title; got != "dns is down" {
+		t.Errorf("expected a single-event title for edge, got %q", got)
+	}
+}
+
+func TestNewNotificationLocale(t *testing.T) {
+	events := []Event{
+		{Monitor: "api", Event: EventDown, Owner: "alice", Quiet: models.QuietDowngrade},
+		{Monitor: "db", Event: EventRecovered},
+	}
+
+	tests := []struct {
+		lang      string
+		wantTitle string
+		wantLine  string
+	}{
+		{lang: "en", wantTitle: "core: 2 monitors changed: 1 down, 1 recovered", wantLine: "- api: down [quiet hours]; owner alice"},
+		{lang: "de", wantTitle: "core: 2 Monitore geändert: 1 ausgefallen, 1 wieder verfügbar", wantLine: "- api: ausgefallen [Ruhezeit]; verantwortlich alice"},
+		{lang: "fr", wantTitle: "core: 2 moniteurs modifiés : 1 en panne, 1 rétabli(s)", wantLine: "- db: rétabli"},
+		{lang: "es", wantTitle: "core: 2 monitores cambiaron: 1 caído(s), 1 recuperado(s)", wantLine: "- api: caído [horas de silencio]; responsable alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			notification := newNotification(i18n.Get(tt.lang), "core", events)
+			if notification.Title != tt.wantTitle {
+				t.Errorf("expected title %q, got %q", tt.wantTitle, notification.Title)
+			}
+			if !strings.Contains(notification.Text, tt.wantLine) {
+				t.Errorf("expected %q in the text, got %q", tt.wantLine, notification.Text)
+			}
+		})
+	}
+}
+
+func TestNotifierTargetChanges(t *testing.T) {
+	box := &inbox{}
+	server := httptest.NewServer(box.handler(t))
+	defer server.Close()
+
+	moved := func(status models.MonitorStatus) *models.MonitorResult {
+		result := transition("api", "core", status)
+		result.TargetChange = &models.TargetChange{PreviousIP: "192.0.2.1", IP: "198.51.100.7"}
+		return result
+	}
+
+	// Target changes are only sent to subscribers
+	defaults := NewNotifier(config.WebhookConfig{URL: server.URL}, "", testLogger(t))
+	defaults.HandleResult(moved(models.StatusUp))
+	if err := defaults.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+	if got := len(box.received()); got != 0 {
+		t.Fatalf("expected no notifications without target_changed, got %d", got)
+	}
+
+	notifier := NewNotifier(config.WebhookConfig{
+		URL:         server.URL,
+		Events:      []string{"down", "target_changed"},
+		GroupWindow: time.Hour,
+	}, "", testLogger(t))
+	notifier.HandleResult(transition("api", "core", models.StatusUp))
+	notifier.HandleResult(moved(models.StatusDown))
+	if err := notifier.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	messages := box.received()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(messages))
+	}
+	digest := messages[0]
+	if len(digest.Events) != 2 {
+		t.Fatalf("expected down and target_changed events, got %+v", digest.Events)
+	}
+	if want := "core: 1 monitors changed: 1 down, 1 target changed"; digest.Title != want {
+		t.Errorf("expected title %q, got %q", want, digest.Title)
+	}
+	if !strings.Contains(digest.Text, "api: target_changed (IP 192.0.2.1 -> 198.51.100.7)") {
+		t.Errorf("expected the change in the text, got %q", digest.Text)
+	}
+}
+
+func TestNotifierWindowElapses(t *testing.T) {
+	box := &inbox{}
+	server := httptest.NewServer(box.handler(t))
+	defer server.Close()
+
+	notifier := NewNotifier(config.WebhookConfig{URL: server.URL, GroupWindow: 50 * time.Millisecond}, "", testLogger(t))
+	defer notifier.Stop()
+
+	notifier.HandleResult(transition("api", "core", models.StatusDown))
+	notifier.HandleResult(transition("db", "core", models.StatusDown))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(box.received()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected a digest once the window elapsed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if events := box.received()[0].Events; len(events) != 2 {
+		t.Errorf("expected 2 events in the digest, got %d", len(events))
+	}
+}
+
+func TestNotifierIncludesOwnership(t *testing.T) {
+	box := &inbox{}
+	server := httptest.NewServer(box.handler(t))
+	defer server.Close()
+
+	manager := monitors.NewMonitorManager(testLogger(t), metrics.NewMetrics(prometheus.NewRegistry()))
+	if err := manager.LoadMonitors([]models.MonitorGroup{{
+		Name: "core",
+		Monitors: []models.Monitor{{
+			Type:       models.MonitorTypeHTTP,
+			Name:       "api",
+			URL:        "https://api.example.com",
+			Owner:      "alice",
+			Team:       "platform",
+			RunbookURL: "https://wiki.example.com/runbooks/api",
+		}},
+	}}); err != nil {
+		t.Fatalf("LoadMonitors failed: %v", err)
+	}
+
+	notifier := NewNotifier(config.WebhookConfig{URL: server.URL}, "", testLogger(t))
+	notifier.SetMonitorSource(manager)
+
+	notifier.HandleResult(transition("api", "core", models.StatusDown))
+	notifier.HandleResult(transition("unknown", "core", models.StatusDown))
+	if err := notifier.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	messages := box.received()
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 notifications, got %d", len(messages))
+	}
+	for _, message := range messages {
+		e := message.Events[0]
+		switch e.Monitor {
+		case "api":
+			if e.Owner != "alice" || e.Team != "platform" || e.RunbookURL != "https://wiki.example.com/runbooks/api" {
+				t.Errorf("expected ownership details, got %+v", e)
+			}
+			if !strings.Contains(message.Text, "owner alice (platform), runbook https://wiki.example.com/runbooks/api") {
+				t.Errorf("expected contact details in the text, got %q", message.Text)
+			}
+		case "unknown":
+			if e.Owner != "" || strings.Contains(message.Text, "owner") {
+				t.Errorf("expected no ownership for an unknown monitor, got %+v", message)
+			}
+		}
+	}
+}
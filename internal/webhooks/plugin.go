@@ -0,0 +1,50 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1broseidon/hallmonitor/internal/plugin"
+)
+
+// NotifyPluginProtocolVersion is the version of the notification plugin
+// protocol sent in requests
+const NotifyPluginProtocolVersion = plugin.ProtocolVersion
+
+// NotifyPluginRequest is written to a notification plugin's stdin for each
+// notification
+type NotifyPluginRequest struct {
+	Protocol     int               `json:"protocol"`
+	Options      map[string]string `json:"options,omitempty"`
+	Notification Notification      `json:"notification"`
+}
+
+// notifyPlugin delivers notifications by running an executable. Exit status
+// 0 means delivered; otherwise the last line of stderr explains the failure.
+type notifyPlugin struct {
+	dir     string // monitoring.pluginDir, which name is relative to
+	name    string
+	args    []string
+	env     map[string]string
+	options map[string]string
+}
+
+// send runs the plugin once with notification
+func (p *notifyPlugin) send(ctx context.Context, notification Notification) error {
+	path, err := plugin.Resolve(p.dir, p.name)
+	if err != nil {
+		return fmt.Errorf("notification %w", err)
+	}
+	output, err := plugin.Run(ctx, path, p.args, p.env, NotifyPluginRequest{
+		Protocol:     NotifyPluginProtocolVersion,
+		Options:      p.options,
+		Notification: notification,
+	})
+	if err != nil {
+		return fmt.Errorf("notification %w", err)
+	}
+	if output.ExitCode != 0 {
+		return fmt.Errorf("notification plugin failed: %s", output.Failure())
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// writeNotifyPlugin writes a shell script plugin named notify.sh and returns
+// the directory it is in
+func writeNotifyPlugin(t *testing.T, script string) string {
+	t.Helper()
+
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("plugin tests need /bin/sh")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notify.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	return dir
+}
+
+func TestNotifierPlugin(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "request.json")
+	dir := writeNotifyPlugin(t, `cat > "$1"`)
+
+	notifier := NewNotifier(config.WebhookConfig{
+		Plugin:     "notify.sh",
+		PluginArgs: []string{out},
+		Options:    map[string]string{"room": "ops@conference.example.com"},
+	}, dir, testLogger(t))
+
+	notifier.HandleResult(transition("api", "core", models.StatusUp))
+	notifier.HandleResult(transition("api", "core", models.StatusDown))
+	if err := notifier.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("plugin did not run: %v", err)
+	}
+	var request NotifyPluginRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatalf("invalid request %s: %v", data, err)
+	}
+	if request.Protocol != NotifyPluginProtocolVersion || request.Options["room"] != "ops@conference.example.com" {
+		t.Errorf("unexpected request %+v", request)
+	}
+	if request.Notification.Title != "api is down" || len(request.Notification.Events) != 1 {
+		t.Errorf("unexpected notification %+v", request.Notification)
+	}
+}
+
+func TestNotifyPluginErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		wantErr string
+	}{
+		{name: "delivered", script: "cat >/dev/null"},
+		{name: "stderr", script: "echo 'connecting' >&2\necho 'gateway rejected the message' >&2\nexit 1", wantErr: "gateway rejected the message"},
+		{name: "exit status", script: "exit 4", wantErr: "exited with status 4"},
+		{name: "timeout", script: "exec sleep 5", timeout: 200 * time.Millisecond, wantErr: "did not finish"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plugin := &notifyPlugin{dir: writeNotifyPlugin(t, tt.script), name: "notify.sh"}
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 10 * time.Second
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			err := plugin.send(ctx, newNotification(nil, "core", []Event{{Monitor: "api", Event: EventDown}}))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,258 @@
+// Package webhooks delivers monitor check results to external HTTP collectors
+// as batched NDJSON payloads, and posts down and recovery notifications.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 5 * time.Second
+	defaultTimeout       = 10 * time.Second
+
+	// maxBufferedBatches bounds memory when the collector is unreachable
+	maxBufferedBatches = 10
+)
+
+// ResultWebhook buffers completed check results and POSTs them to a collector
+// in NDJSON batches, flushing when a batch fills or the flush interval elapses.
+type ResultWebhook struct {
+	url           string
+	onlyFailures  bool
+	batchSize     int
+	flushInterval time.Duration
+	headers       map[string]string
+	client        *http.Client
+	logger        *logging.Logger
+
+	buffer  []*models.MonitorResult
+	dropped int
+	mu      sync.Mutex
+
+	flushCh chan struct{}
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+}
+
+// NewResultWebhook creates a result webhook from configuration
+func NewResultWebhook(cfg config.ResultWebhookConfig, logger *logging.Logger) *ResultWebhook {
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultFlushInterval
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	return &ResultWebhook{
+		url:           cfg.URL,
+		onlyFailures:  cfg.OnlyFailures,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		headers:       cfg.Headers,
+		client:        &http.Client{Timeout: timeout},
+		logger:        logger,
+		flushCh:       make(chan struct{}, 1),
+		stopCh:        make(chan struct{}),
+	}
+}
+
+// HandleResult queues a result for delivery
+func (w *ResultWebhook) HandleResult(result *models.MonitorResult) {
+	if result == nil {
+		return
+	}
+	if w.onlyFailures && result.Status == models.StatusUp {
+		return
+	}
+
+	w.mu.Lock()
+	w.buffer = append(w.buffer, result)
+	if overflow := len(w.buffer) - w.batchSize*maxBufferedBatches; overflow > 0 {
+		// Drop the oldest results rather than growing without bound
+		w.buffer = w.buffer[overflow:]
+		w.dropped += overflow
+	}
+	full := len(w.buffer) >= w.batchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.flushCh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Start begins the background flush loop
+func (w *ResultWebhook) Start(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		return nil
+	}
+
+	w.wg.Add(1)
+	go w.flushLoop(ctx)
+
+	w.running = true
+	return nil
+}
+
+// Stop flushes any buffered results and stops the flush loop
+func (w *ResultWebhook) Stop() error {
+	w.mu.Lock()
+	if !w.running {
+		w.mu.Unlock()
+		return nil
+	}
+	w.running = false
+	w.mu.Unlock()
+
+	close(w.stopCh)
+	w.wg.Wait()
+	return nil
+}
+
+// flushLoop sends batches on a timer or when a batch fills
+func (w *ResultWebhook) flushLoop(ctx context.Context) {
+	defer w.wg.Done()
+
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.flush(context.Background())
+			return
+		case <-w.stopCh:
+			w.flush(context.Background())
+			return
+		case <-ticker.C:
+			w.flush(ctx)
+		case <-w.flushCh:
+			w.flush(ctx)
+		}
+	}
+}
+
+// flush delivers buffered results in batches, keeping them on failure
+func (w *ResultWebhook) flush(ctx context.Context) {
+	for {
+		w.mu.Lock()
+		if len(w.buffer) == 0 {
+			w.mu.Unlock()
+			return
+		}
+		n := len(w.buffer)
+		if n > w.batchSize {
+			n = w.batchSize
+		}
+		batch := make([]*models.MonitorResult, n)
+		copy(batch, w.buffer[:n])
+		dropped := w.dropped
+		w.dropped = 0
+		w.mu.Unlock()
+
+		if dropped > 0 {
+			w.logger.WithComponent(logging.ComponentWebhook).
+				WithFields(map[string]interface{}{
+					"url":     w.url,
+					"dropped": dropped,
+				}).
+				Warn("Result webhook buffer full, dropped oldest results")
+		}
+
+		if err := w.send(ctx, batch); err != nil {
+			w.logger.WithComponent(logging.ComponentWebhook).
+				WithError(err).
+				WithFields(map[string]interface{}{
+					"url":     w.url,
+					"results": len(batch),
+				}).
+				Warn("Failed to deliver result webhook batch")
+			return
+		}
+
+		w.mu.Lock()
+		w.buffer = w.buffer[sentPrefix(w.buffer, batch):]
+		w.mu.Unlock()
+	}
+}
+
+// sentPrefix returns how many results at the start of buffer belong to batch.
+// HandleResult trims the oldest results while a batch is in flight, counting
+// them as dropped, so only what is left of the batch may still lead the
+// buffer; results queued after it must stay.
+func sentPrefix(buffer, batch []*models.MonitorResult) int {
+	sent := make(map[*models.MonitorResult]bool, len(batch))
+	for _, result := range batch {
+		sent[result] = true
+	}
+	n := 0
+	for n < len(buffer) && sent[buffer[n]] {
+		n++
+	}
+	return n
+}
+
+// send POSTs a batch of results as newline-delimited JSON
+func (w *ResultWebhook) send(ctx context.Context, batch []*models.MonitorResult) error {
+	body, err := encodeNDJSON(batch)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("User-Agent", "HallMonitor/1.0")
+	for key, value := range w.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post results: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// encodeNDJSON encodes results as one JSON document per line
+func encodeNDJSON(results []*models.MonitorResult) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, result := range results {
+		if err := encoder.Encode(result); err != nil {
+			return nil, fmt.Errorf("failed to encode result: %w", err)
+		}
+	}
+	return buf.Bytes(), nil
+}
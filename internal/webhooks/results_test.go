@@ -0,0 +1,210 @@
+package webhooks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func testLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	return logger
+}
+
+type collector struct {
+	mu      sync.Mutex
+	batches [][]models.MonitorResult
+}
+
+func (c *collector) handler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Errorf("expected NDJSON content type, got %s", ct)
+		}
+
+		var batch []models.MonitorResult
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var result models.MonitorResult
+			if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+				t.Errorf("failed to decode NDJSON line: %v", err)
+			}
+			batch = append(batch, result)
+		}
+
+		c.mu.Lock()
+		c.batches = append(c.batches, batch)
+		c.mu.Unlock()
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+func (c *collector) total() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	total := 0
+	for _, batch := range c.batches {
+		total += len(batch)
+	}
+	return total
+}
+
+func TestResultWebhookBatchesResults(t *testing.T) {
+	col := &collector{}
+	server := httptest.NewServer(col.handler(t))
+	defer server.Close()
+
+	webhook := NewResultWebhook(config.ResultWebhookConfig{
+		URL:           server.URL,
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	}, testLogger(t))
+
+	if err := webhook.Start(context.Background()); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		webhook.HandleResult(&models.MonitorResult{Monitor: "api", Status: models.StatusUp, Timestamp: time.Now()})
+	}
+
+	// Stop flushes the remaining partial batch
+	if err := webhook.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	if got := col.total(); got != 5 {
+		t.Fatalf("expected 5 delivered results, got %d", got)
+	}
+	for _, batch := range col.batches {
+		if len(batch) > 2 {
+			t.Fatalf("expected batches of at most 2 results, got %d", len(batch))
+		}
+	}
+}
+
+func TestResultWebhookOnlyFailures(t *testing.T) {
+	col := &collector{}
+	server := httptest.NewServer(col.handler(t))
+	defer server.Close()
+
+	webhook := NewResultWebhook(config.ResultWebhookConfig{
+		URL:          server.URL,
+		OnlyFailures: true,
+	}, testLogger(t))
+
+	if err := webhook.Start(context.Background()); err != nil {
+		t.Fatalf("Start returned error: %v", err)
+	}
+
+	webhook.HandleResult(&models.MonitorResult{Monitor: "api", Status: models.StatusUp})
+	webhook.HandleResult(&models.MonitorResult{Monitor: "db", Status: models.StatusDown, Error: "refused"})
+
+	if err := webhook.Stop(); err != nil {
+		t.Fatalf("Stop returned error: %v", err)
+	}
+
+	if got := col.total(); got != 1 {
+		t.Fatalf("expected only the failed result to be delivered, got %d", got)
+	}
+	if col.batches[0][0].Monitor != "db" {
+		t.Fatalf("expected failed monitor db, got %s", col.batches[0][0].Monitor)
+	}
+}
+
+func TestResultWebhookRetainsResultsOnFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	webhook := NewResultWebhook(config.ResultWebhookConfig{URL: server.URL, BatchSize: 1}, testLogger(t))
+	for i := 0; i < 15; i++ {
+		webhook.HandleResult(&models.MonitorResult{Monitor: "api", Status: models.StatusDown})
+	}
+
+	webhook.flush(context.Background())
+
+	webhook.mu.Lock()
+	buffered := len(webhook.buffer)
+	webhook.mu.Unlock()
+
+	// Buffer is bounded to batchSize * maxBufferedBatches and kept after a failed delivery
+	if buffered != maxBufferedBatches {
+		t.Fatalf("expected %d buffered results, got %d", maxBufferedBatches, buffered)
+	}
+}
+
+func TestResultWebhookKeepsResultsQueuedDuringSend(t *testing.T) {
+	col := &collector{}
+	received := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Hold the first batch until the buffer has overflowed behind it
+		once.Do(func() {
+			close(received)
+			<-release
+		})
+		col.handler(t)(w, r)
+	}))
+	defer server.Close()
+
+	webhook := NewResultWebhook(config.ResultWebhookConfig{URL: server.URL, BatchSize: 2}, testLogger(t))
+	result := func(i int) *models.MonitorResult {
+		return &models.MonitorResult{Monitor: fmt.Sprintf("m%d", i), Status: models.StatusDown}
+	}
+	webhook.HandleResult(result(0))
+	webhook.HandleResult(result(1))
+
+	done := make(chan struct{})
+	go func() {
+		webhook.flush(context.Background())
+		close(done)
+	}()
+	<-received
+
+	// Overflow trims the two results in flight from the buffer
+	capacity := 2 * maxBufferedBatches
+	for i := 2; i < 2+capacity; i++ {
+		webhook.HandleResult(result(i))
+	}
+	webhook.mu.Lock()
+	dropped := webhook.dropped
+	webhook.mu.Unlock()
+	if dropped != 2 {
+		t.Errorf("expected the 2 trimmed results counted as dropped, got %d", dropped)
+	}
+
+	close(release)
+	<-done
+
+	if got := col.total(); got != 2+capacity {
+		t.Fatalf("expected all %d results delivered, got %d", 2+capacity, got)
+	}
+	seen := make(map[string]bool)
+	for _, batch := range col.batches {
+		for _, delivered := range batch {
+			seen[delivered.Monitor] = true
+		}
+	}
+	for i := 0; i < 2+capacity; i++ {
+		if !seen[fmt.Sprintf("m%d", i)] {
+			t.Errorf("expected result m%d to be delivered", i)
+		}
+	}
+}
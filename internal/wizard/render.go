@@ -0,0 +1,133 @@
+package wizard
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// configTemplate lays out a starter config with comments explaining each
+// section. Strings are written with quote so any answer stays valid YAML.
+var configTemplate = template.Must(template.New("config").Funcs(template.FuncMap{
+	"quote": quote,
+	"list":  list,
+}).Parse(`# Hall Monitor configuration, generated by "hallmonitor init".
+# See config.example.yml and docs/ for every option.
+
+server:
+  host: {{quote .Host}}
+  port: {{quote .Port}}
+  enableDashboard: {{.Dashboard}}  # Built-in dashboard at /
+  # adminTokens: ["change-me"]  # Required to edit the config through the API
+
+metrics:
+  enabled: true  # Prometheus metrics
+  path: "/metrics"
+
+logging:
+  level: "info"     # debug, info, warn, error
+  format: "json"    # json or text
+  output: "stdout"  # stdout, stderr, or a file path
+
+storage:
+  # Where check results are kept: badger (embedded), postgres, influxdb, or
+  # none (Prometheus metrics only, no history)
+  backend: {{quote .Storage}}
+{{- if eq .Storage "badger"}}
+  badger:
+    enabled: true
+    path: {{quote .BadgerPath}}
+    retentionDays: {{.RetentionDays}}
+    enableAggregation: true  # Keep hourly to monthly rollups
+{{- else if eq .Storage "postgres"}}
+  postgres:
+    host: {{quote .PostgresHost}}
+    port: {{.PostgresPort}}
+    database: {{quote .PostgresDatabase}}
+    user: {{quote .PostgresUser}}
+    password: {{quote .PostgresPassword}}
+    sslmode: "disable"   # Set to require or verify-full for remote servers
+    retentionDays: {{.RetentionDays}}
+    timescale: "auto"    # Use TimescaleDB when it is installed
+{{- else if eq .Storage "influxdb"}}
+  influxdb:
+    url: {{quote .InfluxURL}}
+    token: {{quote .InfluxToken}}
+    org: {{quote .InfluxOrg}}
+    bucket: {{quote .InfluxBucket}}
+    version: "auto"  # 2 (Flux) or 3 (SQL), detected by default
+{{- end}}
+
+monitoring:
+  defaultInterval: "30s"  # How often monitors are checked unless they set interval
+  defaultTimeout: "10s"
+{{- if .Monitors}}
+  groups:
+    - name: {{quote .Group}}
+      monitors:
+{{- range .Monitors}}
+        - type: {{quote .Type}}
+          name: {{quote .Name}}
+{{- if .URL}}
+          url: {{quote .URL}}
+          expectedStatus: 200
+{{- else}}
+          target: {{quote .Target}}
+{{- end}}
+{{- if .Query}}
+          query: {{quote .Query}}
+          queryType: {{quote .QueryType}}
+{{- end}}
+{{- end}}
+{{- else}}
+  groups: []
+  # groups:
+  #   - name: "services"
+  #     monitors:
+  #       - type: "http"
+  #         name: "website"
+  #         url: "https://example.com"
+  #       - type: "tcp"
+  #         name: "ssh"
+  #         target: "192.168.1.10:22"
+{{- end}}
+
+# Notifications on monitor state changes. Slack and Discord incoming webhook
+# URLs work as they are.
+{{- if .WebhookURL}}
+webhooks:
+  - url: {{quote .WebhookURL}}
+    events: {{list .WebhookEvents}}
+{{- else}}
+# webhooks:
+#   - url: "https://hooks.slack.com/services/..."
+#     events: ["down", "recovered"]
+{{- end}}
+`))
+
+// Render writes the answers as a commented config file
+func Render(answers Answers) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := configTemplate.Execute(&buf, answers); err != nil {
+		return nil, fmt.Errorf("failed to render config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// quote writes a string as a double-quoted YAML scalar. JSON strings are
+// valid YAML.
+func quote(value interface{}) string {
+	data, _ := json.Marshal(fmt.Sprint(value))
+	return string(data)
+}
+
+// list writes strings as a YAML flow sequence
+func list(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
@@ -0,0 +1,326 @@
+// Package wizard builds a starter configuration by asking a few questions,
+// for `hallmonitor init`.
+package wizard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Storage backends offered by the wizard
+var storageBackends = []string{"badger", "postgres", "influxdb", "none"}
+
+// Monitor types offered by the wizard
+var monitorTypes = []string{"http", "tcp", "ping", "dns"}
+
+// Answers are the choices made in the wizard
+type Answers struct {
+	Host      string
+	Port      string
+	Dashboard bool
+
+	Storage       string // badger, postgres, influxdb, or none
+	RetentionDays int
+	BadgerPath    string
+
+	PostgresHost     string
+	PostgresPort     int
+	PostgresDatabase string
+	PostgresUser     string
+	PostgresPassword string
+
+	InfluxURL    string
+	InfluxToken  string
+	InfluxOrg    string
+	InfluxBucket string
+
+	Group    string
+	Monitors []models.Monitor
+
+	WebhookURL    string
+	WebhookEvents []string
+}
+
+// DefaultAnswers are used for questions left blank
+func DefaultAnswers() Answers {
+	return Answers{
+		Host:             "0.0.0.0",
+		Port:             "7878",
+		Dashboard:        true,
+		Storage:          "badger",
+		RetentionDays:    30,
+		BadgerPath:       "./data/hallmonitor.db",
+		PostgresHost:     "localhost",
+		PostgresPort:     5432,
+		PostgresDatabase: "hallmonitor",
+		PostgresUser:     "hallmonitor",
+		InfluxURL:        "http://localhost:8086",
+		InfluxOrg:        "hallmonitor",
+		InfluxBucket:     "monitor_results",
+		Group:            "services",
+	}
+}
+
+// Run asks the wizard's questions on out, reading answers from in. Blank
+// answers take the default shown in brackets. When in ends early the
+// remaining questions take their defaults and no more monitors are added.
+func Run(in io.Reader, out io.Writer) (Answers, error) {
+	p := &prompter{in: bufio.NewReader(in), out: out}
+	answers := DefaultAnswers()
+
+	p.section("Server")
+	answers.Host = p.ask("Address to listen on", answers.Host, nil)
+	answers.Port = p.ask("Port", answers.Port, validPort)
+	answers.Dashboard = p.confirm("Enable the web dashboard?", answers.Dashboard)
+
+	p.section("Storage")
+	answers.Storage = p.choose("Where should check results be stored?", storageBackends, answers.Storage)
+	switch answers.Storage {
+	case "badger":
+		answers.BadgerPath = p.ask("Database directory", answers.BadgerPath, nil)
+		answers.RetentionDays = p.askInt("Days of history to keep", answers.RetentionDays)
+	case "postgres":
+		answers.PostgresHost = p.ask("PostgreSQL host", answers.PostgresHost, nil)
+		answers.PostgresPort = p.askInt("PostgreSQL port", answers.PostgresPort)
+		answers.PostgresDatabase = p.ask("Database", answers.PostgresDatabase, nil)
+		answers.PostgresUser = p.ask("User", answers.PostgresUser, nil)
+		answers.PostgresPassword = p.ask("Password", "", nil)
+		answers.RetentionDays = p.askInt("Days of history to keep", answers.RetentionDays)
+	case "influxdb":
+		answers.InfluxURL = p.ask("InfluxDB URL", answers.InfluxURL, validURL)
+		answers.InfluxToken = p.ask("API token", "", nil)
+		answers.InfluxOrg = p.ask("Organization", answers.InfluxOrg, nil)
+		answers.InfluxBucket = p.ask("Bucket", answers.InfluxBucket, nil)
+	}
+
+	p.section("Monitors")
+	if p.confirm("Add a monitor now?", true) {
+		answers.Group = p.ask("Group name", answers.Group, required)
+		for {
+			monitor := p.monitor(answers.Monitors)
+			if p.missing {
+				break
+			}
+			answers.Monitors = append(answers.Monitors, monitor)
+			if !p.confirm("Add another monitor?", false) {
+				break
+			}
+		}
+	}
+
+	p.section("Notifications")
+	if p.confirm("Send notifications to a webhook (Slack, Discord, or any JSON endpoint)?", false) {
+		answers.WebhookURL = p.ask("Webhook URL", "", validURL)
+		switch p.choose("Notify when monitors", []string{"go down", "recover", "both"}, "both") {
+		case "go down":
+			answers.WebhookEvents = []string{"down"}
+		case "recover":
+			answers.WebhookEvents = []string{"recovered"}
+		default:
+			answers.WebhookEvents = []string{"down", "recovered"}
+		}
+		if p.missing {
+			answers.WebhookURL, answers.WebhookEvents = "", nil
+		}
+	}
+
+	return answers, p.err
+}
+
+// monitor asks for one monitor. Names already in monitors are rejected.
+func (p *prompter) monitor(monitors []models.Monitor) models.Monitor {
+	monitor := models.Monitor{Type: models.MonitorType(p.choose("Monitor type", monitorTypes, "http"))}
+
+	var host string
+	switch monitor.Type {
+	case models.MonitorTypeHTTP:
+		monitor.URL = p.ask("URL to check", "", validURL)
+		if u, err := url.Parse(monitor.URL); err == nil {
+			host = u.Hostname()
+		}
+	case models.MonitorTypeTCP:
+		monitor.Target = p.ask("Host and port (host:port)", "", validHostPort)
+		host, _, _ = net.SplitHostPort(monitor.Target)
+	case models.MonitorTypePing:
+		monitor.Target = p.ask("Host to ping", "", required)
+		host = monitor.Target
+	case models.MonitorTypeDNS:
+		monitor.Target = p.ask("DNS server (host:port)", "1.1.1.1:53", validHostPort)
+		monitor.Query = p.ask("Name to resolve", "", required)
+		monitor.QueryType = "A"
+		host = monitor.Query
+	}
+
+	monitor.Name = p.ask("Monitor name", host, func(name string) error {
+		if name == "" {
+			return errors.New("a name is required")
+		}
+		for _, existing := range monitors {
+			if existing.Name == name {
+				return fmt.Errorf("%s is already taken", name)
+			}
+		}
+		return nil
+	})
+	return monitor
+}
+
+// prompter asks questions on out and reads answers from in
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	eof bool  // in has ended; remaining questions take their defaults
+	err error // First read error other than EOF
+
+	// missing is set when in ended before a required answer was given
+	missing bool
+}
+
+// section prints a heading for the questions that follow
+func (p *prompter) section(title string) {
+	fmt.Fprintf(p.out, "\n%s\n", title)
+}
+
+// ask prints a question and returns the answer, or def when it is blank.
+// Answers that fail valid are asked again.
+func (p *prompter) ask(question, def string, valid func(string) error) string {
+	for {
+		if def != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", question)
+		}
+		if p.eof || p.err != nil {
+			return p.ended(def, valid)
+		}
+
+		line, err := p.in.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				p.eof = true
+			} else {
+				p.err = fmt.Errorf("failed to read answer: %w", err)
+			}
+			if line == "" {
+				return p.ended(def, valid)
+			}
+		}
+
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = def
+		}
+		if valid == nil {
+			return answer
+		}
+		if err := valid(answer); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			if p.eof || p.err != nil {
+				return p.ended(def, valid)
+			}
+			continue
+		}
+		return answer
+	}
+}
+
+// ended answers with def once in has ended, noting when def is not a valid
+// answer
+func (p *prompter) ended(def string, valid func(string) error) string {
+	fmt.Fprintln(p.out)
+	if valid != nil && valid(def) != nil {
+		p.missing = true
+	}
+	return def
+}
+
+// askInt asks for a positive number
+func (p *prompter) askInt(question string, def int) int {
+	answer := p.ask(question, strconv.Itoa(def), func(answer string) error {
+		if n, err := strconv.Atoi(answer); err != nil || n <= 0 {
+			return errors.New("enter a positive number")
+		}
+		return nil
+	})
+	n, err := strconv.Atoi(answer)
+	if err != nil {
+		return def
+	}
+	return n
+}
+
+// confirm asks a yes or no question
+func (p *prompter) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	answer := p.ask(question+" ("+hint+")", "", func(answer string) error {
+		switch strings.ToLower(answer) {
+		case "", "y", "yes", "n", "no":
+			return nil
+		}
+		return errors.New("answer y or n")
+	})
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// choose asks for one of choices
+func (p *prompter) choose(question string, choices []string, def string) string {
+	return p.ask(question+" ("+strings.Join(choices, ", ")+")", def, func(answer string) error {
+		for _, choice := range choices {
+			if answer == choice {
+				return nil
+			}
+		}
+		return fmt.Errorf("choose one of %s", strings.Join(choices, ", "))
+	})
+}
+
+// required rejects blank answers
+func required(answer string) error {
+	if answer == "" {
+		return errors.New("an answer is required")
+	}
+	return nil
+}
+
+// validPort accepts TCP port numbers
+func validPort(answer string) error {
+	if port, err := strconv.Atoi(answer); err != nil || port < 1 || port > 65535 {
+		return errors.New("enter a port between 1 and 65535")
+	}
+	return nil
+}
+
+// validURL accepts http and https URLs
+func validURL(answer string) error {
+	u, err := url.Parse(answer)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("enter a URL starting with http:// or https://")
+	}
+	return nil
+}
+
+// validHostPort accepts host:port addresses
+func validHostPort(answer string) error {
+	host, port, err := net.SplitHostPort(answer)
+	if err != nil || host == "" || validPort(port) != nil {
+		return errors.New("enter a host and port, such as 192.168.1.10:22")
+	}
+	return nil
+}
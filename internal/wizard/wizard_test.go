@@ -0,0 +1,160 @@
+package wizard
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// loadRendered renders answers and loads the result as the server would
+func loadRendered(t *testing.T, answers Answers) *config.Config {
+	t.Helper()
+
+	data, err := Render(answers)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "config.yml")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("failed to load rendered config: %v\n%s", err, data)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("rendered config is invalid: %v\n%s", err, data)
+	}
+	return cfg
+}
+
+func TestRun(t *testing.T) {
+	input := strings.Join([]string{
+		"",            // Address
+		"99999",       // Invalid port, asked again
+		"8080",        // Port
+		"n",           // Dashboard
+		"",            // Storage: badger
+		"/var/lib/hm", // Database directory
+		"90",          // Retention
+		"",            // Add a monitor: yes
+		"home",        // Group
+		"",            // Type: http
+		"example.com", // Invalid URL, asked again
+		"https://example.com/health",
+		"",             // Name from the host
+		"y",            // Another
+		"tcp",          // Type
+		"nas.local:22", // Target
+		"nas-ssh",      // Name
+		"y",            // Another
+		"dns",          // Type
+		"",             // DNS server
+		"example.com",  // Query
+		"example.com",  // Name already taken, asked again
+		"dns",          // Name
+		"n",            // Another
+		"y",            // Webhook
+		"https://hooks.slack.com/services/T0/B0/x",
+		"go down", // Events
+	}, "\n") + "\n"
+
+	answers, err := Run(strings.NewReader(input), io.Discard)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := DefaultAnswers()
+	want.Port = "8080"
+	want.Dashboard = false
+	want.BadgerPath = "/var/lib/hm"
+	want.RetentionDays = 90
+	want.Group = "home"
+	want.Monitors = []models.Monitor{
+		{Type: models.MonitorTypeHTTP, Name: "example.com", URL: "https://example.com/health"},
+		{Type: models.MonitorTypeTCP, Name: "nas-ssh", Target: "nas.local:22"},
+		{Type: models.MonitorTypeDNS, Name: "dns", Target: "1.1.1.1:53", Query: "example.com", QueryType: "A"},
+	}
+	want.WebhookURL = "https://hooks.slack.com/services/T0/B0/x"
+	want.WebhookEvents = []string{"down"}
+	if !reflect.DeepEqual(answers, want) {
+		t.Errorf("answers = %+v\nwant %+v", answers, want)
+	}
+
+	cfg := loadRendered(t, answers)
+	if cfg.Server.Port != "8080" || cfg.Server.EnableDashboard {
+		t.Errorf("unexpected server config %+v", cfg.Server)
+	}
+	if cfg.Storage.Badger.Path != "/var/lib/hm" || cfg.Storage.Badger.RetentionDays != 90 {
+		t.Errorf("unexpected badger config %+v", cfg.Storage.Badger)
+	}
+	if len(cfg.Monitoring.Groups) != 1 || len(cfg.Monitoring.Groups[0].Monitors) != 3 {
+		t.Fatalf("expected one group with 3 monitors, got %+v", cfg.Monitoring.Groups)
+	}
+	if dns := cfg.Monitoring.Groups[0].Monitors[2]; dns.Query != "example.com" || dns.QueryType != "A" {
+		t.Errorf("unexpected dns monitor %+v", dns)
+	}
+	if len(cfg.Webhooks) != 1 || !reflect.DeepEqual(cfg.Webhooks[0].Events, []string{"down"}) {
+		t.Errorf("unexpected webhooks %+v", cfg.Webhooks)
+	}
+}
+
+func TestRunDefaults(t *testing.T) {
+	// Input that ends early leaves the rest at their defaults
+	answers, err := Run(strings.NewReader("127.0.0.1\n"), io.Discard)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	want := DefaultAnswers()
+	want.Host = "127.0.0.1"
+	if !reflect.DeepEqual(answers, want) {
+		t.Errorf("answers = %+v\nwant %+v", answers, want)
+	}
+
+	cfg := loadRendered(t, answers)
+	if len(cfg.Monitoring.Groups) != 0 || len(cfg.Webhooks) != 0 {
+		t.Errorf("expected no monitors or webhooks, got %+v and %+v", cfg.Monitoring.Groups, cfg.Webhooks)
+	}
+}
+
+func TestRunIncompleteMonitor(t *testing.T) {
+	// A monitor whose URL was never given is dropped
+	answers, err := Run(strings.NewReader("\n\n\n\n\n\ny\n\nhttp\n"), io.Discard)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(answers.Monitors) != 0 {
+		t.Errorf("expected no monitors, got %+v", answers.Monitors)
+	}
+}
+
+func TestRenderStorageBackends(t *testing.T) {
+	postgres := DefaultAnswers()
+	postgres.Storage = "postgres"
+	postgres.PostgresPassword = `p"a:ss #word`
+	cfg := loadRendered(t, postgres)
+	if cfg.Storage.Backend != "postgres" || cfg.Storage.Postgres.Password != postgres.PostgresPassword {
+		t.Errorf("unexpected postgres config %+v", cfg.Storage.Postgres)
+	}
+
+	influx := DefaultAnswers()
+	influx.Storage = "influxdb"
+	influx.InfluxToken = "token=="
+	cfg = loadRendered(t, influx)
+	if cfg.Storage.Backend != "influxdb" || cfg.Storage.InfluxDB.Token != "token==" {
+		t.Errorf("unexpected influxdb config %+v", cfg.Storage.InfluxDB)
+	}
+
+	none := DefaultAnswers()
+	none.Storage = "none"
+	if cfg = loadRendered(t, none); cfg.Storage.Backend != "none" {
+		t.Errorf("expected backend none, got %s", cfg.Storage.Backend)
+	}
+}
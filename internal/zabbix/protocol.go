@@ -0,0 +1,93 @@
+package zabbix
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// protocolHeader starts every Zabbix protocol packet, followed by the
+// little-endian data length and a reserved length
+var protocolHeader = []byte("ZBXD\x01")
+
+// maxResponseSize bounds the server's reply
+const maxResponseSize = 1 << 20
+
+// Item is a trapper item value
+type Item struct {
+	Host  string `json:"host"`
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Clock int64  `json:"clock"`
+	NS    int64  `json:"ns"`
+}
+
+// senderRequest is the body of a sender data request
+type senderRequest struct {
+	Request string `json:"request"`
+	Data    []Item `json:"data"`
+}
+
+// senderResponse is the server's reply to a sender data request
+type senderResponse struct {
+	Response string `json:"response"`
+	Info     string `json:"info"`
+}
+
+// infoPattern extracts counts from a response such as
+// "processed: 2; failed: 1; total: 3; seconds spent: 0.000055"
+var infoPattern = regexp.MustCompile(`processed: (\d+); failed: (\d+); total: (\d+)`)
+
+// encodeRequest frames items as a sender data request
+func encodeRequest(items []Item) ([]byte, error) {
+	data, err := json.Marshal(senderRequest{Request: "sender data", Data: items})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode items: %w", err)
+	}
+
+	packet := make([]byte, 0, len(protocolHeader)+8+len(data))
+	packet = append(packet, protocolHeader...)
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(len(data)))
+	packet = binary.LittleEndian.AppendUint32(packet, 0)
+	return append(packet, data...), nil
+}
+
+// readResponse reads a framed reply and returns how many items the server
+// processed and how many it rejected
+func readResponse(r io.Reader) (processed, failed int, err error) {
+	header := make([]byte, len(protocolHeader)+8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, 0, fmt.Errorf("failed to read response header: %w", err)
+	}
+	if !bytes.Equal(header[:len(protocolHeader)], protocolHeader) {
+		return 0, 0, fmt.Errorf("unexpected response header %q", header[:len(protocolHeader)])
+	}
+	length := binary.LittleEndian.Uint32(header[len(protocolHeader):])
+	if length > maxResponseSize {
+		return 0, 0, fmt.Errorf("response too large: %d bytes", length)
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	var response senderResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return 0, 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if response.Response != "success" {
+		return 0, 0, fmt.Errorf("server responded %q: %s", response.Response, response.Info)
+	}
+
+	match := infoPattern.FindStringSubmatch(response.Info)
+	if match == nil {
+		return 0, 0, nil
+	}
+	processed, _ = strconv.Atoi(match[1])
+	failed, _ = strconv.Atoi(match[2])
+	return processed, failed, nil
+}
@@ -0,0 +1,265 @@
+// Package zabbix sends monitor statuses to a Zabbix server or proxy as
+// trapper items, using the Zabbix sender protocol.
+package zabbix
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+const (
+	defaultPort      = "10051"
+	defaultKeyPrefix = "hallmonitor"
+	defaultInterval  = 30 * time.Second
+	dialTimeout      = 10 * time.Second
+)
+
+// Sender sends the latest result of each monitor every interval as three
+// trapper items on the configured Zabbix host:
+//
+//	{prefix}.status[monitor]   1 when up, 0 when down
+//	{prefix}.duration[monitor] check duration in seconds
+//	{prefix}.error[monitor]    the last error, empty when up
+//
+// Monitors without a new result since the last send are skipped.
+type Sender struct {
+	server    string
+	host      string
+	keyPrefix string
+	interval  time.Duration
+	logger    *logging.Logger
+
+	pending map[string]*models.MonitorResult
+	mu      sync.Mutex
+
+	stopCh  chan struct{}
+	wg      sync.WaitGroup
+	running bool
+}
+
+// NewSender creates a Zabbix sender from configuration
+func NewSender(cfg config.ZabbixConfig, logger *logging.Logger) *Sender {
+	server := cfg.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, defaultPort)
+	}
+	host := cfg.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	keyPrefix := strings.Trim(cfg.KeyPrefix, ".")
+	if keyPrefix == "" {
+		keyPrefix = defaultKeyPrefix
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	return &Sender{
+		server:    server,
+		host:      host,
+		keyPrefix: keyPrefix,
+		interval:  interval,
+		logger:    logger,
+		pending:   make(map[string]*models.MonitorResult),
+		stopCh:    make(chan struct{}),
+	}
+}
+
+// HandleResult keeps the result to send with the next batch
+func (s *Sender) HandleResult(result *models.MonitorResult) {
+	if result == nil || result.Status == models.StatusUnknown {
+		return
+	}
+
+	s.mu.Lock()
+	s.pending[result.Monitor] = result
+	s.mu.Unlock()
+}
+
+// Start sends batches every interval until stopped
+func (s *Sender) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+
+	s.logger.WithComponent(logging.ComponentZabbix).
+		WithFields(map[string]interface{}{
+			"server":   s.server,
+			"host":     s.host,
+			"interval": s.interval.String(),
+		}).
+		Info("Starting Zabbix sender")
+
+	s.wg.Add(1)
+	go s.sendLoop(ctx)
+
+	s.running = true
+	return nil
+}
+
+// Stop sends any pending results and stops the sender
+func (s *Sender) Stop() error {
+	s.mu.Lock()
+	if !s.running {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running = false
+	s.mu.Unlock()
+
+	s.logger.WithComponent(logging.ComponentZabbix).Info("Stopping Zabbix sender")
+	close(s.stopCh)
+	s.wg.Wait()
+	return nil
+}
+
+// sendLoop sends pending results on every tick, and once more on the way out
+func (s *Sender) sendLoop(ctx context.Context) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			s.flush(context.Background())
+			return
+		case <-ticker.C:
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush sends the pending results, keeping them for the next attempt when
+// the server cannot be reached unless newer results replace them
+func (s *Sender) flush(ctx context.Context) {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = make(map[string]*models.MonitorResult)
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	var items []Item
+	names := make([]string, 0, len(batch))
+	for name := range batch {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		items = append(items, s.items(batch[name])...)
+	}
+
+	failed, err := s.send(ctx, items)
+	if err != nil {
+		s.logger.WithComponent(logging.ComponentZabbix).
+			WithError(err).
+			WithFields(map[string]interface{}{
+				"server":  s.server,
+				"results": len(batch),
+			}).
+			Warn("Failed to send statuses to Zabbix")
+
+		s.mu.Lock()
+		for name, result := range batch {
+			if _, newer := s.pending[name]; !newer {
+				s.pending[name] = result
+			}
+		}
+		s.mu.Unlock()
+		return
+	}
+
+	// Items the server does not know about are rejected, not queued
+	if failed > 0 {
+		s.logger.WithComponent(logging.ComponentZabbix).
+			WithFields(map[string]interface{}{
+				"server": s.server,
+				"host":   s.host,
+				"failed": failed,
+				"total":  len(items),
+			}).
+			Warn("Zabbix rejected some items; check that trapper items exist on the host")
+	}
+}
+
+// send delivers items over a new connection and returns how many the
+// server rejected
+func (s *Sender) send(ctx context.Context, items []Item) (int, error) {
+	packet, err := encodeRequest(items)
+	if err != nil {
+		return 0, err
+	}
+
+	dialer := net.Dialer{Timeout: dialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.server)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(dialTimeout))
+	}
+
+	if _, err := conn.Write(packet); err != nil {
+		return 0, fmt.Errorf("failed to write: %w", err)
+	}
+	_, failed, err := readResponse(conn)
+	return failed, err
+}
+
+// items converts a result to trapper item values
+func (s *Sender) items(result *models.MonitorResult) []Item {
+	up := "0"
+	if result.Status == models.StatusUp {
+		up = "1"
+	}
+
+	item := func(name, value string) Item {
+		return Item{
+			Host:  s.host,
+			Key:   s.keyPrefix + "." + name + "[" + quoteKeyParam(result.Monitor) + "]",
+			Value: value,
+			Clock: result.Timestamp.Unix(),
+			NS:    int64(result.Timestamp.Nanosecond()),
+		}
+	}
+	return []Item{
+		item("status", up),
+		item("duration", strconv.FormatFloat(result.Duration.Seconds(), 'f', -1, 64)),
+		item("error", result.Error),
+	}
+}
+
+// quoteKeyParam quotes an item key parameter when it contains characters
+// with meaning in key syntax
+func quoteKeyParam(param string) string {
+	if !strings.ContainsAny(param, `",[] `) {
+		return param
+	}
+	return `"` + strings.ReplaceAll(param, `"`, `\"`) + `"`
+}
@@ -0,0 +1,157 @@
+package zabbix
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func testLogger(t *testing.T) *logging.Logger {
+	t.Helper()
+	logger, err := logging.InitLogger(logging.Config{Level: "error", Format: "json", Output: "stdout"})
+	if err != nil {
+		t.Fatalf("failed to create test logger: %v", err)
+	}
+	return logger
+}
+
+// trapperServer accepts sender requests, delivers their items, and replies
+// that every item was processed
+func trapperServer(t *testing.T) (string, <-chan []Item) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	requests := make(chan []Item, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			header := make([]byte, 13)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				conn.Close()
+				continue
+			}
+			body := make([]byte, binary.LittleEndian.Uint32(header[5:9]))
+			io.ReadFull(conn, body)
+
+			var request senderRequest
+			json.Unmarshal(body, &request)
+			requests <- request.Data
+
+			reply, _ := json.Marshal(senderResponse{
+				Response: "success",
+				Info:     fmt.Sprintf("processed: %d; failed: 0; total: %d; seconds spent: 0.000055", len(request.Data), len(request.Data)),
+			})
+			conn.Write(frame(reply))
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String(), requests
+}
+
+// frame wraps data in a protocol header
+func frame(data []byte) []byte {
+	packet := append([]byte(nil), protocolHeader...)
+	packet = binary.LittleEndian.AppendUint32(packet, uint32(len(data)))
+	packet = binary.LittleEndian.AppendUint32(packet, 0)
+	return append(packet, data...)
+}
+
+func TestSenderSendsStatuses(t *testing.T) {
+	address, requests := trapperServer(t)
+
+	sender := NewSender(config.ZabbixConfig{Server: address, Host: "hallmonitor-01", Interval: time.Hour}, testLogger(t))
+	if err := sender.Start(context.Background()); err != nil {
+		t.Fatalf("failed to start sender: %v", err)
+	}
+
+	timestamp := time.Unix(1700000000, 500)
+	sender.HandleResult(&models.MonitorResult{Monitor: "api", Status: models.StatusUp, Timestamp: timestamp})
+	sender.HandleResult(&models.MonitorResult{Monitor: "api", Status: models.StatusDown, Duration: 1500 * time.Millisecond, Error: "http 503", Timestamp: timestamp})
+
+	// Stopping sends what is pending
+	if err := sender.Stop(); err != nil {
+		t.Fatalf("failed to stop sender: %v", err)
+	}
+
+	var items []Item
+	select {
+	case items = <-requests:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for items")
+	}
+
+	want := []Item{
+		{Host: "hallmonitor-01", Key: "hallmonitor.status[api]", Value: "0", Clock: 1700000000, NS: 500},
+		{Host: "hallmonitor-01", Key: "hallmonitor.duration[api]", Value: "1.5", Clock: 1700000000, NS: 500},
+		{Host: "hallmonitor-01", Key: "hallmonitor.error[api]", Value: "http 503", Clock: 1700000000, NS: 500},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("expected %d items, got %v", len(want), items)
+	}
+	for i := range want {
+		if items[i] != want[i] {
+			t.Errorf("item %d = %+v, want %+v", i, items[i], want[i])
+		}
+	}
+}
+
+func TestReadResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		packet    []byte
+		processed int
+		failed    int
+		wantErr   bool
+	}{
+		{name: "success", packet: frame([]byte(`{"response":"success","info":"processed: 2; failed: 1; total: 3; seconds spent: 0.000055"}`)), processed: 2, failed: 1},
+		{name: "failure", packet: frame([]byte(`{"response":"failed","info":"host not monitored"}`)), wantErr: true},
+		{name: "not zabbix", packet: []byte("HTTP/1.1 400 Bad Request\r\n\r\n"), wantErr: true},
+		{name: "truncated", packet: frame([]byte(`{"response":"success"}`))[:15], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processed, failed, err := readResponse(bytes.NewReader(tt.packet))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if processed != tt.processed || failed != tt.failed {
+				t.Errorf("readResponse() = %d processed, %d failed; want %d, %d", processed, failed, tt.processed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestQuoteKeyParam(t *testing.T) {
+	tests := map[string]string{
+		"api":        "api",
+		"web/api":    "web/api",
+		"edge, eu":   `"edge, eu"`,
+		`say "hi"`:   `"say \"hi\""`,
+		"list[0]":    `"list[0]"`,
+		"with space": `"with space"`,
+	}
+	for param, want := range tests {
+		if got := quoteKeyParam(param); got != want {
+			t.Errorf("quoteKeyParam(%q) = %q, want %q", param, got, want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+// Package hallmonitor runs Hall Monitor inside another Go program. An
+// Instance checks monitors on their schedules and stores their results like
+// the standalone server, and hands results and alerts to callbacks.
+//
+// The API, dashboard, notification integrations, and reports are served by
+// the standalone server only; use OnResult and OnAlert to act on results.
+package hallmonitor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/yaml.v3"
+
+	"github.com/1broseidon/hallmonitor/internal/api"
+	"github.com/1broseidon/hallmonitor/internal/config"
+	"github.com/1broseidon/hallmonitor/internal/geoip"
+	"github.com/1broseidon/hallmonitor/internal/logging"
+	"github.com/1broseidon/hallmonitor/internal/pipeline"
+	"github.com/1broseidon/hallmonitor/internal/scheduler"
+	"github.com/1broseidon/hallmonitor/internal/storage"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Config configures an embedded instance
+type Config struct {
+	// ConfigFile is a Hall Monitor config file to load, as the standalone
+	// server does. Without one the standalone defaults apply, except that
+	// results are kept in memory only and logs go to stderr at warn level.
+	ConfigFile string
+
+	// Profile is a profile of ConfigFile to merge over it
+	Profile string
+
+	// Groups are monitor groups checked in addition to those of ConfigFile
+	Groups []models.MonitorGroup
+
+	// DataDir stores results in an embedded database in this directory,
+	// overriding the storage settings of ConfigFile
+	DataDir string
+
+	// LogLevel overrides the log level: debug, info, warn, or error
+	LogLevel string
+
+	// Registerer receives the Prometheus metrics of checks. Defaults to a
+	// registry of the instance's own.
+	Registerer prometheus.Registerer
+}
+
+// Instance is an embedded Hall Monitor
+type Instance struct {
+	config *config.Config
+	logger *logging.Logger
+	store  storage.ResultStore
+
+	// server holds the monitor manager and scheduler; its HTTP listener is
+	// never started
+	server    *api.Server
+	scheduler *scheduler.Scheduler
+	hooks     *hooks
+
+	mu      sync.Mutex
+	running bool
+}
+
+// New creates an instance from cfg. Monitors are not checked until Start.
+func New(cfg Config) (*Instance, error) {
+	settings, err := buildConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	logger, err := logging.InitLogger(logging.Config{
+		Level:   settings.Logging.Level,
+		Format:  settings.Logging.Format,
+		Output:  settings.Logging.Output,
+		Outputs: settings.Logging.Outputs,
+		Rotation: logging.RotationConfig{
+			MaxSizeMB:  settings.Logging.Rotation.MaxSizeMB,
+			MaxAge:     settings.Logging.Rotation.MaxAge,
+			MaxBackups: settings.Logging.Rotation.MaxBackups,
+			Interval:   settings.Logging.Rotation.Interval,
+			Compress:   settings.Logging.Rotation.Compress,
+		},
+		ComponentLevels: settings.Logging.ComponentLevels,
+		Fields:          settings.Logging.Fields,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	registerer := cfg.Registerer
+	if registerer == nil {
+		registerer = prometheus.NewRegistry()
+	}
+
+	store, err := storage.NewStore(&settings.Storage, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	var server *api.Server
+	if caps := store.Capabilities(); caps.SupportsRawResults {
+		var aggregator *storage.Aggregator
+		enableAggregation := settings.Storage.EnableAggregation || settings.Storage.Badger.EnableAggregation
+		if badgerStore, ok := store.(*storage.BadgerStore); ok && caps.SupportsAggregation && enableAggregation {
+			aggregator = storage.NewAggregator(badgerStore, logger)
+		}
+		server = api.NewServerWithStorage(settings, cfg.ConfigFile, logger, registerer, store, aggregator, store)
+	} else {
+		server = api.NewServer(settings, cfg.ConfigFile, logger, registerer)
+	}
+
+	if err := server.GetMonitorManager().LoadMonitors(settings.Monitoring.Groups); err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to load monitors: %w", err)
+	}
+
+	instance := &Instance{
+		config:    settings,
+		logger:    logger,
+		store:     store,
+		server:    server,
+		scheduler: server.GetScheduler(),
+		hooks:     newHooks(),
+	}
+	if len(settings.ResultHooks) > 0 || settings.GeoIP.Enabled() {
+		resultPipeline := pipeline.New(settings.ResultHooks, settings.Monitoring.PluginDir, logger)
+		if settings.GeoIP.Enabled() {
+			geo, err := geoip.Open(settings.GeoIP)
+			if err != nil {
+				store.Close()
+				return nil, err
+			}
+			resultPipeline.SetGeoIP(geo)
+		}
+		instance.scheduler.SetResultProcessor(resultPipeline)
+	}
+	instance.scheduler.AddResultHandler(instance.hooks)
+	return instance, nil
+}
+
+// buildConfig loads ConfigFile and applies the settings given in code over
+// it, so they get the same defaults and validation as a config file
+func buildConfig(cfg Config) (*config.Config, error) {
+	doc := make(map[string]interface{})
+	if cfg.ConfigFile != "" {
+		data, err := os.ReadFile(cfg.ConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse config: %w", err)
+		}
+		if doc == nil {
+			doc = make(map[string]interface{})
+		}
+	} else {
+		doc["storage"] = map[string]interface{}{"backend": "none"}
+		doc["logging"] = map[string]interface{}{"level": "warn", "output": "stderr"}
+	}
+
+	if len(cfg.Groups) > 0 {
+		// Round trip through YAML so groups read like those in a file
+		data, err := yaml.Marshal(cfg.Groups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal groups: %w", err)
+		}
+		var groups []interface{}
+		if err := yaml.Unmarshal(data, &groups); err != nil {
+			return nil, fmt.Errorf("failed to marshal groups: %w", err)
+		}
+		monitoring := section(doc, "monitoring")
+		existing, _ := monitoring["groups"].([]interface{})
+		monitoring["groups"] = append(existing, groups...)
+	}
+	if cfg.DataDir != "" {
+		settings := section(doc, "storage")
+		settings["backend"] = "badger"
+		badger := section(settings, "badger")
+		badger["enabled"] = true
+		badger["path"] = cfg.DataDir
+	}
+	if cfg.LogLevel != "" {
+		section(doc, "logging")["level"] = cfg.LogLevel
+	}
+
+	data, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return config.ParseConfig(data, cfg.Profile)
+}
+
+// section returns the mapping under key in doc, adding it when missing
+func section(doc map[string]interface{}, key string) map[string]interface{} {
+	if existing, ok := doc[key].(map[string]interface{}); ok {
+		return existing
+	}
+	created := make(map[string]interface{})
+	doc[key] = created
+	return created
+}
+
+// Start begins checking monitors on their schedules
+func (i *Instance) Start(ctx context.Context) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if i.running {
+		return fmt.Errorf("instance is already running")
+	}
+	if err := i.scheduler.Start(ctx); err != nil {
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
+	i.running = true
+	return nil
+}
+
+// Stop waits for running checks to finish and closes storage. The instance
+// cannot be started again.
+func (i *Instance) Stop() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	var stopErr error
+	if i.running {
+		if err := i.scheduler.Stop(); err != nil {
+			stopErr = fmt.Errorf("failed to stop scheduler: %w", err)
+		}
+		i.running = false
+	}
+	if i.store != nil {
+		if err := i.store.Close(); err != nil && stopErr == nil {
+			stopErr = fmt.Errorf("failed to close storage: %w", err)
+		}
+		i.store = nil
+	}
+	return stopErr
+}
+
+// Monitors returns the settings of the loaded monitors
+func (i *Instance) Monitors() []models.Monitor {
+	loaded := i.server.GetMonitorManager().GetMonitors()
+	result := make([]models.Monitor, len(loaded))
+	for j, monitor := range loaded {
+		result[j] = *monitor.GetConfig()
+	}
+	return result
+}
+
+// LatestResults returns the most recent result of each monitor that has
+// been checked
+func (i *Instance) LatestResults() map[string]*models.MonitorResult {
+	return i.scheduler.GetAllLatestResults()
+}
+
+// History returns stored results of a monitor between start and end, up to
+// limit results (0 for all). Without storage only recent results are kept.
+func (i *Instance) History(monitor string, start, end time.Time, limit int) ([]*models.MonitorResult, error) {
+	return i.scheduler.GetHistoricalResults(monitor, start, end, limit)
+}
@@ -0,0 +1,166 @@
+package hallmonitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+func TestInstance(t *testing.T) {
+	var failing atomic.Bool
+	err := RegisterMonitorType("test-queue", func(monitor *models.Monitor) (Checker, error) {
+		if monitor.Options["queue"] == "" {
+			return nil, errors.New("queue option is required")
+		}
+		return CheckerFunc(func(ctx context.Context, monitor *models.Monitor) (*models.MonitorResult, error) {
+			if failing.Load() {
+				return nil, errors.New("queue is stuck")
+			}
+			return nil, nil
+		}), nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterMonitorType failed: %v", err)
+	}
+
+	groups := []models.MonitorGroup{{
+		Name: "jobs",
+		Monitors: []models.Monitor{{
+			Type:     "test-queue",
+			Name:     "orders",
+			Interval: models.Duration(time.Second),
+			Options:  map[string]string{"queue": "orders"},
+		}},
+	}}
+	instance, err := New(Config{Groups: groups})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer instance.Stop()
+
+	results := make(chan *models.MonitorResult, 16)
+	alerts := make(chan Alert, 16)
+	instance.OnResult(func(result *models.MonitorResult) { results <- result })
+	instance.OnAlert(func(alert Alert) { alerts <- alert })
+
+	if err := instance.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := instance.Start(context.Background()); err == nil {
+		t.Error("expected error starting a running instance")
+	}
+
+	result := waitFor(t, results)
+	if result.Monitor != "orders" || result.Group != "jobs" || result.Status != models.StatusUp {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if latest := instance.LatestResults()["orders"]; latest == nil || latest.Status != models.StatusUp {
+		t.Errorf("unexpected latest result %+v", latest)
+	}
+
+	failing.Store(true)
+	alert := waitFor(t, alerts)
+	if alert.Event != AlertDown || alert.Result.Error != "queue is stuck" {
+		t.Errorf("unexpected alert %s %+v", alert.Event, alert.Result)
+	}
+	failing.Store(false)
+	if alert = waitFor(t, alerts); alert.Event != AlertRecovered {
+		t.Errorf("expected recovered alert, got %s", alert.Event)
+	}
+
+	if err := instance.Stop(); err != nil {
+		t.Errorf("Stop failed: %v", err)
+	}
+}
+
+func TestNewRejectedMonitors(t *testing.T) {
+	err := RegisterMonitorType("test-invalid", func(monitor *models.Monitor) (Checker, error) {
+		return nil, errors.New("queue option is required")
+	})
+	if err != nil {
+		t.Fatalf("RegisterMonitorType failed: %v", err)
+	}
+
+	groups := []models.MonitorGroup{{
+		Name:     "jobs",
+		Monitors: []models.Monitor{{Type: "test-invalid", Name: "orders"}},
+	}}
+	// Monitors that fail to load are logged and skipped, as in the server
+	instance, err := New(Config{Groups: groups})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer instance.Stop()
+	if monitors := instance.Monitors(); len(monitors) != 0 {
+		t.Errorf("expected the rejected monitor to be skipped, got %+v", monitors)
+	}
+
+	groups[0].Monitors[0].Type = "test-unregistered"
+	if _, err := New(Config{Groups: groups}); err == nil {
+		t.Error("expected error for an unregistered monitor type")
+	}
+}
+
+func TestNewMergesConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	content := `
+logging:
+  level: "error"
+monitoring:
+  defaultInterval: "45s"
+  groups:
+    - name: "web"
+      monitors:
+        - type: "http"
+          name: "site"
+          url: "http://127.0.0.1:1"
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	instance, err := New(Config{
+		ConfigFile: path,
+		DataDir:    filepath.Join(t.TempDir(), "data"),
+		Groups: []models.MonitorGroup{{
+			Name:     "infra",
+			Monitors: []models.Monitor{{Type: models.MonitorTypeTCP, Name: "ssh", Target: "127.0.0.1:22"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer instance.Stop()
+
+	if instance.config.Storage.Backend != "badger" || !instance.config.Storage.Badger.Enabled {
+		t.Errorf("expected badger storage, got %+v", instance.config.Storage)
+	}
+	monitors := instance.Monitors()
+	if len(monitors) != 2 {
+		t.Fatalf("expected 2 monitors, got %+v", monitors)
+	}
+	for _, monitor := range monitors {
+		if monitor.Interval != models.Duration(45*time.Second) {
+			t.Errorf("monitor %s: expected the file's default interval, got %v", monitor.Name, monitor.Interval)
+		}
+	}
+}
+
+// waitFor returns the next value sent on ch
+func waitFor[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case value := <-ch:
+		return value
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a callback")
+	}
+	var zero T
+	return zero
+}
@@ -0,0 +1,124 @@
+package hallmonitor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/1broseidon/hallmonitor/internal/monitors"
+	"github.com/1broseidon/hallmonitor/pkg/models"
+)
+
+// Alert events
+const (
+	AlertDown          = "down"
+	AlertRecovered     = "recovered"
+	AlertTargetChanged = "target_changed" // See Result.TargetChange
+)
+
+// Alert reports that a monitor went down, recovered, or, with
+// detectTargetChanges, reached a different address or certificate
+type Alert struct {
+	Event  string // AlertDown, AlertRecovered, or AlertTargetChanged
+	Result *models.MonitorResult
+}
+
+// OnResult calls fn with every check result. Callbacks run on the scheduler's
+// goroutine and should return quickly.
+func (i *Instance) OnResult(fn func(*models.MonitorResult)) {
+	i.hooks.mu.Lock()
+	defer i.hooks.mu.Unlock()
+	i.hooks.results = append(i.hooks.results, fn)
+}
+
+// OnAlert calls fn when a monitor goes down, when it recovers, and when its
+// target changes. Alerts silenced by quiet hours are not delivered.
+func (i *Instance) OnAlert(fn func(Alert)) {
+	i.hooks.mu.Lock()
+	defer i.hooks.mu.Unlock()
+	i.hooks.alerts = append(i.hooks.alerts, fn)
+}
+
+// hooks passes scheduler results to the callbacks of an instance
+type hooks struct {
+	mu         sync.Mutex
+	results    []func(*models.MonitorResult)
+	alerts     []func(Alert)
+	lastStatus map[string]models.MonitorStatus
+}
+
+func newHooks() *hooks {
+	return &hooks{lastStatus: make(map[string]models.MonitorStatus)}
+}
+
+// HandleResult calls the result callbacks, and the alert callbacks on down
+// and recovered transitions
+func (h *hooks) HandleResult(result *models.MonitorResult) {
+	if result == nil {
+		return
+	}
+
+	h.mu.Lock()
+	results := h.results
+	alerts := h.alerts
+	var events []string
+	if result.Status != models.StatusUnknown {
+		previous, seen := h.lastStatus[result.Monitor]
+		h.lastStatus[result.Monitor] = result.Status
+		switch {
+		case result.Status == models.StatusDown && previous != models.StatusDown:
+			events = append(events, AlertDown)
+		case result.Status == models.StatusUp && seen && previous == models.StatusDown:
+			events = append(events, AlertRecovered)
+		}
+	}
+	if result.TargetChange != nil {
+		events = append(events, AlertTargetChanged)
+	}
+	h.mu.Unlock()
+
+	for _, fn := range results {
+		fn(result)
+	}
+	// The transition is still recorded so recovery after quiet hours is sent
+	if result.Quiet == models.QuietSuppress {
+		return
+	}
+	for _, event := range events {
+		for _, fn := range alerts {
+			fn(Alert{Event: event, Result: result})
+		}
+	}
+}
+
+// Checker checks monitors of a custom type
+type Checker interface {
+	// Check returns the result of one check. A nil result means the monitor
+	// is up; an error marks it down. Monitor, type, group, and timing fields
+	// left empty are filled in.
+	Check(ctx context.Context, monitor *models.Monitor) (*models.MonitorResult, error)
+}
+
+// CheckerFunc adapts a function to a Checker
+type CheckerFunc func(ctx context.Context, monitor *models.Monitor) (*models.MonitorResult, error)
+
+// Check calls f
+func (f CheckerFunc) Check(ctx context.Context, monitor *models.Monitor) (*models.MonitorResult, error) {
+	return f(ctx, monitor)
+}
+
+// RegisterMonitorType adds a monitor type to every instance in the process,
+// and to config files loaded by them. create is called for each monitor of
+// the type when it is loaded; it should validate the monitor's settings,
+// usually its Target, URL, and Options. Register types before calling New.
+func RegisterMonitorType(monitorType models.MonitorType, create func(monitor *models.Monitor) (Checker, error)) error {
+	if create == nil {
+		return monitors.RegisterType(monitorType, nil)
+	}
+	return monitors.RegisterType(monitorType, func(monitor *models.Monitor) (monitors.CheckFunc, error) {
+		checker, err := create(monitor)
+		if err != nil {
+			return nil, err
+		}
+		return checker.Check, nil
+	})
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -84,3 +86,26 @@ func (d Duration) ToDuration() time.Duration {
 func (d Duration) Nanoseconds() int64 {
 	return int64(d)
 }
+
+// ParsePeriod parses a positive lookback period: a Go duration such as 36h,
+// or a whole number of days such as 30d
+func ParsePeriod(s string) (time.Duration, error) {
+	var period time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid period %q", s)
+		}
+		period = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if period, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid period %q", s)
+		}
+	}
+
+	if period <= 0 {
+		return 0, fmt.Errorf("period must be positive: %q", s)
+	}
+	return period, nil
+}
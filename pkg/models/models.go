@@ -3,6 +3,9 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,10 +13,14 @@ import (
 type MonitorType string
 
 const (
-	MonitorTypePing MonitorType = "ping"
-	MonitorTypeHTTP MonitorType = "http"
-	MonitorTypeTCP  MonitorType = "tcp"
-	MonitorTypeDNS  MonitorType = "dns"
+	MonitorTypePing   MonitorType = "ping"
+	MonitorTypeHTTP   MonitorType = "http"
+	MonitorTypeTCP    MonitorType = "tcp"
+	MonitorTypeDNS    MonitorType = "dns"
+	MonitorTypeRBL    MonitorType = "rbl"
+	MonitorTypeDomain MonitorType = "domain"
+	MonitorTypePlugin MonitorType = "plugin"
+	MonitorTypeWasm   MonitorType = "wasm"
 )
 
 // MonitorStatus represents the current status of a monitor
@@ -39,6 +46,12 @@ type Monitor struct {
 	Metrics  *MonitorMetricsConfig `yaml:"metrics,omitempty" json:"metrics,omitempty"`
 	Labels   map[string]string     `yaml:"labels,omitempty" json:"labels,omitempty"`
 
+	// Ownership and contact details shown to responders in the API, dashboard, and notifications
+	Owner       string `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Team        string `yaml:"team,omitempty" json:"team,omitempty"`
+	RunbookURL  string `yaml:"runbookURL,omitempty" json:"runbookURL,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+
 	// Monitor-specific fields
 	ExpectedStatus           int       `yaml:"expectedStatus,omitempty" json:"expectedStatus,omitempty"`
 	ExpectedResponse         string    `yaml:"expectedResponse,omitempty" json:"expectedResponse,omitempty"`
@@ -47,6 +60,112 @@ type Monitor struct {
 	Port                     int       `yaml:"port,omitempty" json:"port,omitempty"`
 	SSLCertExpiryWarningDays int       `yaml:"sslCertExpiryWarningDays,omitempty" json:"sslCertExpiryWarningDays,omitempty"`
 	HistogramBuckets         []float64 `yaml:"histogram_buckets,omitempty" json:"histogram_buckets,omitempty"`
+	Retries                  int       `yaml:"retries,omitempty" json:"retries,omitempty"`                     // Extra attempts before a check is reported down
+	RecoveryThreshold        int       `yaml:"recoveryThreshold,omitempty" json:"recoveryThreshold,omitempty"` // Consecutive successes before a down monitor is reported up
+	MaxDuration              Duration  `yaml:"maxDuration,omitempty" json:"maxDuration,omitempty"`             // Successful checks slower than this are reported down
+	SampleRate               int       `yaml:"sampleRate,omitempty" json:"sampleRate,omitempty"`               // Persist one in this many successful results (sub-10s intervals only)
+
+	// Egress restricts the addresses this monitor may connect to, on top of the global policy
+	Egress *EgressPolicy `yaml:"egress,omitempty" json:"egress,omitempty"`
+
+	// Resolver and Hosts control how HTTP, TCP, and ping targets are resolved
+	Resolver *ResolverConfig   `yaml:"resolver,omitempty" json:"resolver,omitempty"`
+	Hosts    map[string]string `yaml:"hosts,omitempty" json:"hosts,omitempty"` // Hostname to IP overrides, like /etc/hosts
+
+	// ExpectedIP and ExpectedIPs fail HTTP and TCP checks when the target resolves to any address outside them.
+	// Entries may be IP addresses or CIDR ranges.
+	ExpectedIP  string   `yaml:"expectedIP,omitempty" json:"expectedIP,omitempty"`
+	ExpectedIPs []string `yaml:"expectedIPs,omitempty" json:"expectedIPs,omitempty"`
+
+	// SourceIP or SourceInterface selects the local address checks originate from
+	SourceIP        string `yaml:"sourceIP,omitempty" json:"sourceIP,omitempty"`
+	SourceInterface string `yaml:"sourceInterface,omitempty" json:"sourceInterface,omitempty"`
+
+	// HTTP connection handling; by default connections are kept alive and reused between checks
+	DisableConnectionReuse bool `yaml:"disableConnectionReuse,omitempty" json:"disableConnectionReuse,omitempty"` // Open a fresh connection per check to measure cold latency
+	MaxConnections         int  `yaml:"maxConnections,omitempty" json:"maxConnections,omitempty"`                 // Cap on concurrent connections per host (0 = unlimited)
+
+	// HTTPVersion selects the HTTP protocol: "auto" (default) negotiates HTTP/2 when the server offers it,
+	// "1.1" disables HTTP/2, and "2" requires HTTP/2, using h2c prior knowledge for plain http URLs
+	HTTPVersion string `yaml:"httpVersion,omitempty" json:"httpVersion,omitempty"`
+
+	// HTTP cache handling, to avoid caches or deliberately exercise them
+	CacheBust           bool `yaml:"cacheBust,omitempty" json:"cacheBust,omitempty"`                     // Add a unique query parameter to every request
+	NoCache             bool `yaml:"noCache,omitempty" json:"noCache,omitempty"`                         // Send Cache-Control: no-cache so caches revalidate
+	ConditionalRequests bool `yaml:"conditionalRequests,omitempty" json:"conditionalRequests,omitempty"` // Revalidate with the previous Last-Modified and ETag; 304 is up
+
+	// RDAPServer overrides the RDAP base URL a domain monitor queries instead of the IANA bootstrap
+	RDAPServer string `yaml:"rdapServer,omitempty" json:"rdapServer,omitempty"`
+
+	// ExpiryWarningDays logs a warning when a domain registration expires within this many days (default 30)
+	ExpiryWarningDays int `yaml:"expiryWarningDays,omitempty" json:"expiryWarningDays,omitempty"`
+
+	// Blocklists are the DNSBL zones an rbl monitor queries; defaults depend on whether the target is an IP or a domain
+	Blocklists []string `yaml:"blocklists,omitempty" json:"blocklists,omitempty"`
+
+	// DetectContentChanges hashes HTTP response bodies and flags checks whose body differs from the previous one
+	DetectContentChanges bool `yaml:"detectContentChanges,omitempty" json:"detectContentChanges,omitempty"`
+
+	// DetectTargetChanges flags HTTP, TCP, and ping checks that reach a different address, or HTTPS checks served a different certificate, than the previous one
+	DetectTargetChanges bool `yaml:"detectTargetChanges,omitempty" json:"detectTargetChanges,omitempty"`
+
+	// CaptureHeaders are HTTP response headers recorded in each result, in addition to the defaults
+	CaptureHeaders []string `yaml:"captureHeaders,omitempty" json:"captureHeaders,omitempty"`
+
+	// PinnedKeys are SHA-256 public key pins; an HTTPS check goes down unless a certificate in the served chain matches one
+	PinnedKeys []string `yaml:"pinnedKeys,omitempty" json:"pinnedKeys,omitempty"`
+
+	// SLO is the uptime objective in percent (e.g. 99.9) that SLA reports measure compliance against
+	SLO float64 `yaml:"slo,omitempty" json:"slo,omitempty"`
+
+	// QuietHours are windows in which checks run but notifications are suppressed or downgraded
+	QuietHours []QuietHours `yaml:"quietHours,omitempty" json:"quietHours,omitempty"`
+
+	// Options are settings of custom monitor types and plugins, which interpret them
+	Options map[string]string `yaml:"options,omitempty" json:"options,omitempty"`
+
+	// Plugin is the executable a plugin monitor runs for each check, with PluginArgs as its arguments
+	// and PluginEnv added to its environment
+	Plugin     string            `yaml:"plugin,omitempty" json:"plugin,omitempty"`
+	PluginArgs []string          `yaml:"pluginArgs,omitempty" json:"pluginArgs,omitempty"`
+	PluginEnv  map[string]string `yaml:"pluginEnv,omitempty" json:"pluginEnv,omitempty"`
+
+	// Module is the WebAssembly (WASI) module a wasm monitor runs in a sandbox for each check, within Limits
+	Module string      `yaml:"module,omitempty" json:"module,omitempty"`
+	Limits *WasmLimits `yaml:"limits,omitempty" json:"limits,omitempty"`
+}
+
+// WasmLimits caps the resources a wasm monitor's module may use per check,
+// on top of the monitor's timeout
+type WasmLimits struct {
+	MemoryMB int    `yaml:"memoryMB,omitempty" json:"memoryMB,omitempty"` // Linear memory cap (default 64)
+	Fuel     uint64 `yaml:"fuel,omitempty" json:"fuel,omitempty"`         // Function calls per check (0 = unlimited)
+}
+
+// ResolverConfig selects the DNS servers used to resolve monitor targets
+type ResolverConfig struct {
+	Servers       []string `yaml:"servers,omitempty" json:"servers,omitempty"`             // "ip" or "ip:port", tried in order
+	SearchDomains []string `yaml:"searchDomains,omitempty" json:"searchDomains,omitempty"` // Appended to names without a dot
+}
+
+// IsZero reports whether the resolver config leaves system resolution unchanged
+func (r *ResolverConfig) IsZero() bool {
+	return r == nil || (len(r.Servers) == 0 && len(r.SearchDomains) == 0)
+}
+
+// EgressPolicy restricts which addresses monitors may connect to. Entries in
+// Allow and Deny are CIDRs or single IPs; when Allow is non-empty, only
+// addresses it contains are reachable. Deny rules always win over Allow.
+type EgressPolicy struct {
+	Allow         []string `yaml:"allow,omitempty" json:"allow,omitempty"`
+	Deny          []string `yaml:"deny,omitempty" json:"deny,omitempty"`
+	DenyPrivate   bool     `yaml:"denyPrivate,omitempty" json:"denyPrivate,omitempty"`     // RFC 1918, unique local, loopback, and unspecified addresses
+	DenyLinkLocal bool     `yaml:"denyLinkLocal,omitempty" json:"denyLinkLocal,omitempty"` // Link-local ranges and cloud metadata endpoints
+}
+
+// IsZero reports whether the policy places no restrictions
+func (p *EgressPolicy) IsZero() bool {
+	return p == nil || (len(p.Allow) == 0 && len(p.Deny) == 0 && !p.DenyPrivate && !p.DenyLinkLocal)
 }
 
 // MonitorMetricsConfig configures metrics collection for a monitor
@@ -60,27 +179,124 @@ type MonitorMetricsConfig struct {
 
 // MonitorGroup represents a group of related monitors
 type MonitorGroup struct {
-	Name     string    `yaml:"name" json:"name"`
-	Interval Duration  `yaml:"interval,omitempty" json:"interval,omitempty"`
-	Monitors []Monitor `yaml:"monitors" json:"monitors"`
+	Name     string              `yaml:"name" json:"name"`
+	Interval Duration            `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Monitors []Monitor           `yaml:"monitors" json:"monitors"`
+	Expand   []TemplateExpansion `yaml:"expand,omitempty" json:"expand,omitempty"`
+
+	// Tenant is the namespace the group belongs to; its monitors are visible only to that tenant's tokens
+	Tenant string `yaml:"tenant,omitempty" json:"tenant,omitempty"`
+
+	// MaxConcurrent caps how many of the group's checks run at once (0 = unlimited)
+	MaxConcurrent int `yaml:"maxConcurrent,omitempty" json:"maxConcurrent,omitempty"`
+
+	// Enabled set to false stops scheduling every member monitor (default: true)
+	Enabled *bool `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+
+	// Defaults inherited by member monitors that do not set their own
+	Timeout                  Duration          `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Headers                  map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`               // Merged under monitor headers (HTTP only)
+	CaptureHeaders           []string          `yaml:"captureHeaders,omitempty" json:"captureHeaders,omitempty"` // Added to monitor captureHeaders (HTTP only)
+	Labels                   map[string]string `yaml:"labels,omitempty" json:"labels,omitempty"`                 // Merged under monitor labels
+	ExpectedStatus           int               `yaml:"expectedStatus,omitempty" json:"expectedStatus,omitempty"`
+	Retries                  int               `yaml:"retries,omitempty" json:"retries,omitempty"`
+	RecoveryThreshold        int               `yaml:"recoveryThreshold,omitempty" json:"recoveryThreshold,omitempty"`
+	SSLCertExpiryWarningDays int               `yaml:"sslCertExpiryWarningDays,omitempty" json:"sslCertExpiryWarningDays,omitempty"`
+	SLO                      float64           `yaml:"slo,omitempty" json:"slo,omitempty"`
+	QuietHours               []QuietHours      `yaml:"quietHours,omitempty" json:"quietHours,omitempty"` // Used by monitors without their own
+}
+
+// IsEnabled returns whether the group's monitors may be scheduled
+func (g MonitorGroup) IsEnabled() bool {
+	return g.Enabled == nil || *g.Enabled
+}
+
+// TemplateExpansion generates monitors from a named template, one per
+// variable set. Values is shorthand for variable sets binding only {{value}}.
+type TemplateExpansion struct {
+	Template string              `yaml:"template" json:"template"`
+	Values   []string            `yaml:"values,omitempty" json:"values,omitempty"`
+	Vars     []map[string]string `yaml:"vars,omitempty" json:"vars,omitempty"`
 }
 
 // MonitorResult represents the result of a monitor check
 type MonitorResult struct {
-	Monitor   string        `json:"monitor"`
-	Type      MonitorType   `json:"type"`
-	Group     string        `json:"group"`
-	Status    MonitorStatus `json:"status"`
-	Duration  time.Duration `json:"duration"`
-	Error     string        `json:"error,omitempty"`
-	Timestamp time.Time     `json:"timestamp"`
-	Metadata  interface{}   `json:"metadata,omitempty"`
+	Monitor   string            `json:"monitor"`
+	Type      MonitorType       `json:"type"`
+	Group     string            `json:"group"`
+	Status    MonitorStatus     `json:"status"`
+	Duration  time.Duration     `json:"duration"`
+	Error     string            `json:"error,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	Metadata  interface{}       `json:"metadata,omitempty"`
+	Synthetic bool              `json:"synthetic,omitempty"` // Produced by failure injection, not a real check
+	Quiet     string            `json:"quiet,omitempty"`     // Quiet-hours action in effect when the check ran
+	Labels    map[string]string `json:"labels,omitempty"`    // Set by result hooks
+
+	// RemoteIP is the address an HTTP, TCP, or ping check reached, and Geo
+	// where it is, when GeoIP databases are configured
+	RemoteIP string   `json:"remote_ip,omitempty"`
+	Geo      *GeoInfo `json:"geo,omitempty"`
+
+	// TargetChange is set when detectTargetChanges is enabled and the remote
+	// address or certificate differs from the previous check
+	TargetChange *TargetChange `json:"target_change,omitempty"`
+
+	// SampleWeight is how many checks a persisted result stands for when its
+	// monitor samples successful results; 0 means just this one
+	SampleWeight int `json:"sample_weight,omitempty"`
 
 	// Type-specific result data
-	HTTPResult *HTTPResult `json:"http_result,omitempty"`
-	PingResult *PingResult `json:"ping_result,omitempty"`
-	TCPResult  *TCPResult  `json:"tcp_result,omitempty"`
-	DNSResult  *DNSResult  `json:"dns_result,omitempty"`
+	HTTPResult   *HTTPResult   `json:"http_result,omitempty"`
+	PingResult   *PingResult   `json:"ping_result,omitempty"`
+	TCPResult    *TCPResult    `json:"tcp_result,omitempty"`
+	DNSResult    *DNSResult    `json:"dns_result,omitempty"`
+	RBLResult    *RBLResult    `json:"rbl_result,omitempty"`
+	DomainResult *DomainResult `json:"domain_result,omitempty"`
+}
+
+// ID identifies a result among its monitor's results by its timestamp in
+// Unix nanoseconds
+func (r *MonitorResult) ID() string {
+	return strconv.FormatInt(r.Timestamp.UnixNano(), 10)
+}
+
+// Weight returns how many checks the result counts as in uptime math
+func (r *MonitorResult) Weight() int {
+	if r.SampleWeight > 1 {
+		return r.SampleWeight
+	}
+	return 1
+}
+
+// TargetChange describes how the target a monitor reaches moved. Fields of
+// whatever did not change are empty.
+type TargetChange struct {
+	PreviousIP         string `json:"previous_ip,omitempty"`
+	IP                 string `json:"ip,omitempty"`
+	PreviousCertSerial string `json:"previous_cert_serial,omitempty"`
+	CertSerial         string `json:"cert_serial,omitempty"`
+}
+
+// String summarises the change, e.g. "IP 192.0.2.1 -> 192.0.2.2"
+func (c *TargetChange) String() string {
+	var parts []string
+	if c.IP != "" {
+		parts = append(parts, fmt.Sprintf("IP %s -> %s", c.PreviousIP, c.IP))
+	}
+	if c.CertSerial != "" {
+		parts = append(parts, fmt.Sprintf("certificate serial %s -> %s", c.PreviousCertSerial, c.CertSerial))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GeoInfo locates an address by GeoIP lookup
+type GeoInfo struct {
+	Country      string `json:"country,omitempty"` // ISO 3166-1 alpha-2 code
+	CountryName  string `json:"country_name,omitempty"`
+	City         string `json:"city,omitempty"` // Only with a City database
+	ASN          uint   `json:"asn,omitempty"`
+	Organization string `json:"as_org,omitempty"`
 }
 
 // HTTPResult contains HTTP-specific check results
@@ -90,6 +306,35 @@ type HTTPResult struct {
 	ResponseSize  int64             `json:"response_size"`
 	Headers       map[string]string `json:"headers,omitempty"`
 	SSLCertExpiry *time.Time        `json:"ssl_cert_expiry,omitempty"`
+
+	ConnectionReused bool `json:"connection_reused"` // Request went over a kept-alive connection
+
+	Protocol        string `json:"protocol,omitempty"`         // Negotiated protocol, e.g. "HTTP/2.0"
+	HTTP3Advertised bool   `json:"http3_advertised,omitempty"` // Server offered HTTP/3 via Alt-Svc
+
+	// Set when detectContentChanges is enabled
+	BodyHash         string `json:"body_hash,omitempty"`          // SHA-256 of the response body
+	BodySnippet      string `json:"body_snippet,omitempty"`       // Start of the response body
+	ContentChanged   bool   `json:"content_changed,omitempty"`    // Body differs from the previous check
+	PreviousBodyHash string `json:"previous_body_hash,omitempty"` // Hash before the change
+
+	// CertChain is the certificate chain the server presented, leaf first
+	CertChain []Certificate `json:"cert_chain,omitempty"`
+}
+
+// Certificate describes one certificate of a TLS chain
+type Certificate struct {
+	Subject            string    `json:"subject"`
+	Issuer             string    `json:"issuer"`
+	SerialNumber       string    `json:"serial_number"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	DNSNames           []string  `json:"dns_names,omitempty"`
+	IPAddresses        []string  `json:"ip_addresses,omitempty"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	IsCA               bool      `json:"is_ca,omitempty"`
+	Fingerprint        string    `json:"fingerprint_sha256"`
+	KeyPin             string    `json:"key_pin"` // Value for pinnedKeys
 }
 
 // PingResult contains ping-specific check results
@@ -118,6 +363,29 @@ type DNSResult struct {
 	ResponseSize int           `json:"response_size"`
 }
 
+// DomainResult contains domain registration expiry check results
+type DomainResult struct {
+	Domain        string    `json:"domain"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	DaysRemaining int       `json:"days_remaining"`
+	Registrar     string    `json:"registrar,omitempty"`
+	Source        string    `json:"source"` // "rdap" or "whois"
+}
+
+// RBLResult contains DNSBL check results
+type RBLResult struct {
+	Target  string            `json:"target"`
+	Checked int               `json:"checked"`          // Blocklists queried
+	Listed  []RBLListing      `json:"listed,omitempty"` // Blocklists the target appears on
+	Errors  map[string]string `json:"errors,omitempty"` // Blocklist zone -> lookup error
+}
+
+// RBLListing is a single DNSBL listing with the return codes it answered
+type RBLListing struct {
+	Blocklist string   `json:"blocklist"`
+	Codes     []string `json:"codes"`
+}
+
 // AggregateResult represents aggregated monitoring data over a time period
 type AggregateResult struct {
 	Monitor       string        `json:"monitor"`
@@ -131,4 +399,63 @@ type AggregateResult struct {
 	AvgDuration   time.Duration `json:"avg_duration"`
 	MinDuration   time.Duration `json:"min_duration"`
 	MaxDuration   time.Duration `json:"max_duration"`
+	Partial       bool          `json:"partial,omitempty"` // Period still in progress, computed on demand up to PeriodEnd
+}
+
+// Annotation marks an event such as a deploy on the monitoring timeline. With
+// neither Monitor nor Group set it applies to every monitor.
+type Annotation struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	TimeEnd time.Time `json:"time_end,omitempty"`
+	Monitor string    `json:"monitor,omitempty"`
+	Group   string    `json:"group,omitempty"`
+	Text    string    `json:"text"`
+	Tags    []string  `json:"tags,omitempty"`
+}
+
+// Applies reports whether the annotation is scoped to the given monitor or group
+func (a *Annotation) Applies(monitor, group string) bool {
+	switch {
+	case a.Monitor != "":
+		return a.Monitor == monitor
+	case a.Group != "":
+		return a.Group == group
+	default:
+		return true
+	}
+}
+
+// User is a local account that signs in to the dashboard. Users with a Tenant
+// only see that tenant's groups; the others have full access.
+type User struct {
+	Username          string    `json:"username"`
+	PasswordHash      string    `json:"password_hash"` // bcrypt
+	Tenant            string    `json:"tenant,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+
+	// Two-factor authentication. TOTPPendingSecret holds a secret being
+	// enrolled until a code from it is confirmed.
+	TOTPSecret        string   `json:"totp_secret,omitempty"`
+	TOTPPendingSecret string   `json:"totp_pending_secret,omitempty"`
+	TOTPLastStep      int64    `json:"totp_last_step,omitempty"` // Last accepted time step, so codes cannot be replayed
+	RecoveryCodes     []string `json:"recovery_codes,omitempty"` // SHA-256 hashes of unused recovery codes
+}
+
+// TOTPEnabled reports whether the user signs in with a second factor
+func (u *User) TOTPEnabled() bool {
+	return u.TOTPSecret != ""
+}
+
+// Session is a signed-in user's session. Only a hash of the session token is
+// stored.
+type Session struct {
+	TokenHash string    `json:"token_hash"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Pending sessions passed the password check and wait for a second factor
+	Pending bool `json:"pending,omitempty"`
 }
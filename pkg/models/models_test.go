@@ -65,3 +65,31 @@ func TestMonitorEnabledPointer(t *testing.T) {
 		t.Fatalf("expected monitor to be disabled when pointer set to false")
 	}
 }
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "30d", want: 30 * 24 * time.Hour},
+		{input: "36h", want: 36 * time.Hour},
+		{input: "90m", want: 90 * time.Minute},
+		{input: "0d", wantErr: true},
+		{input: "-1h", wantErr: true},
+		{input: "1.5d", wantErr: true},
+		{input: "week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePeriod(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePeriod(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePeriod(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// KeyPinPrefix starts a base64 public key pin
+const KeyPinPrefix = "sha256/"
+
+// ParseKeyPin decodes a certificate public key pin: the SHA-256 digest of a
+// certificate's SubjectPublicKeyInfo, written as "sha256/" and base64 (as in
+// HPKP) or as hex with optional colons
+func ParseKeyPin(pin string) ([]byte, error) {
+	pin = strings.TrimSpace(pin)
+
+	var digest []byte
+	var err error
+	if encoded, ok := strings.CutPrefix(pin, KeyPinPrefix); ok {
+		digest, err = base64.StdEncoding.DecodeString(encoded)
+	} else {
+		digest, err = hex.DecodeString(strings.ReplaceAll(pin, ":", ""))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid key pin %q: %w", pin, err)
+	}
+	if len(digest) != sha256.Size {
+		return nil, fmt.Errorf("invalid key pin %q: expected a SHA-256 digest", pin)
+	}
+	return digest, nil
+}
+
+// FormatKeyPin returns the pin for a SubjectPublicKeyInfo in "sha256/" form
+func FormatKeyPin(spki []byte) string {
+	digest := sha256.Sum256(spki)
+	return KeyPinPrefix + base64.StdEncoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestParseKeyPin(t *testing.T) {
+	spki := []byte("subject public key info")
+	digest := sha256.Sum256(spki)
+	hexPin := hex.EncodeToString(digest[:])
+
+	var colons []string
+	for i := 0; i < len(hexPin); i += 2 {
+		colons = append(colons, strings.ToUpper(hexPin[i:i+2]))
+	}
+
+	tests := []struct {
+		name    string
+		pin     string
+		wantErr bool
+	}{
+		{name: "base64", pin: FormatKeyPin(spki)},
+		{name: "hex", pin: hexPin},
+		{name: "hex with colons", pin: strings.Join(colons, ":")},
+		{name: "not base64", pin: "sha256/not*base64", wantErr: true},
+		{name: "wrong length", pin: "sha256/AAAA", wantErr: true},
+		{name: "not hex", pin: "fingerprint", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKeyPin(tt.pin)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeyPin() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !bytes.Equal(got, digest[:]) {
+				t.Errorf("ParseKeyPin() = %x, want %x", got, digest)
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quiet-hours actions applied to notifications
+const (
+	QuietSuppress  = "suppress"  // Send no notifications
+	QuietDowngrade = "downgrade" // Send notifications at a lower severity
+)
+
+// QuietHours is a recurring window during which checks still run but
+// notifications are suppressed or downgraded. A window whose end is before
+// its start runs past midnight and belongs to the day it starts on.
+type QuietHours struct {
+	Days     []string `yaml:"days,omitempty" json:"days,omitempty"`         // "mon" to "sun" or full names; empty means every day
+	Start    string   `yaml:"start" json:"start"`                           // "HH:MM"
+	End      string   `yaml:"end" json:"end"`                               // "HH:MM", or "24:00" for the end of the day
+	Timezone string   `yaml:"timezone,omitempty" json:"timezone,omitempty"` // IANA name; defaults to local time
+	Action   string   `yaml:"action,omitempty" json:"action,omitempty"`     // "suppress" (default) or "downgrade"
+}
+
+// quietLocations caches loaded time zones by name
+var quietLocations sync.Map
+
+// weekdays maps accepted day names to weekdays
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "sunday": time.Sunday,
+	"mon": time.Monday, "monday": time.Monday,
+	"tue": time.Tuesday, "tuesday": time.Tuesday,
+	"wed": time.Wednesday, "wednesday": time.Wednesday,
+	"thu": time.Thursday, "thursday": time.Thursday,
+	"fri": time.Friday, "friday": time.Friday,
+	"sat": time.Saturday, "saturday": time.Saturday,
+}
+
+// Validate checks the window's days, times, time zone and action
+func (q QuietHours) Validate() error {
+	for _, day := range q.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("unknown day %q", day)
+		}
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return fmt.Errorf("invalid end: %w", err)
+	}
+	if start == end || start == 24*60 {
+		return fmt.Errorf("start and end must differ and start before 24:00")
+	}
+	if _, err := q.location(); err != nil {
+		return fmt.Errorf("invalid timezone: %w", err)
+	}
+	switch q.Action {
+	case "", QuietSuppress, QuietDowngrade:
+	default:
+		return fmt.Errorf("action must be %q or %q", QuietSuppress, QuietDowngrade)
+	}
+	return nil
+}
+
+// Contains reports whether t falls inside the window
+func (q QuietHours) Contains(t time.Time) bool {
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(q.End)
+	if err != nil {
+		return false
+	}
+	location, err := q.location()
+	if err != nil {
+		return false
+	}
+
+	local := t.In(location)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+
+	switch {
+	case start < end:
+		if minute < start || minute >= end {
+			return false
+		}
+	case minute >= start:
+		// Evening part of a window running past midnight
+	case minute < end:
+		// Morning part; the window started the day before
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+
+	if len(q.Days) == 0 {
+		return true
+	}
+	for _, name := range q.Days {
+		if weekdays[strings.ToLower(name)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// action returns the window's action, defaulting to suppress
+func (q QuietHours) action() string {
+	if q.Action == "" {
+		return QuietSuppress
+	}
+	return q.Action
+}
+
+// location returns the window's time zone
+func (q QuietHours) location() (*time.Location, error) {
+	if q.Timezone == "" {
+		return time.Local, nil
+	}
+	if cached, ok := quietLocations.Load(q.Timezone); ok {
+		return cached.(*time.Location), nil
+	}
+	location, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	quietLocations.Store(q.Timezone, location)
+	return location, nil
+}
+
+// QuietAction returns the action of the quiet-hours windows containing t, or
+// "" outside them. Suppression wins when windows overlap.
+func QuietAction(windows []QuietHours, t time.Time) string {
+	action := ""
+	for _, window := range windows {
+		if !window.Contains(t) {
+			continue
+		}
+		if window.action() == QuietSuppress {
+			return QuietSuppress
+		}
+		action = window.action()
+	}
+	return action
+}
+
+// parseClock parses "HH:MM" into minutes since midnight, allowing "24:00"
+func parseClock(value string) (int, error) {
+	hours, minutes, ok := strings.Cut(value, ":")
+	if !ok || len(hours) != 2 || len(minutes) != 2 {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+	if h < 0 || m < 0 || m > 59 || h > 24 || (h == 24 && m != 0) {
+		return 0, fmt.Errorf("%q is not a time of day", value)
+	}
+	return h*60 + m, nil
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietHoursContains(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	at := func(day, clock string) time.Time {
+		ts, err := time.ParseInLocation("2006-01-02 15:04", day+" "+clock, berlin)
+		if err != nil {
+			t.Fatalf("bad time: %v", err)
+		}
+		return ts
+	}
+
+	// 2026-10-16 is a Friday
+	overnight := QuietHours{Days: []string{"fri", "Saturday"}, Start: "22:00", End: "07:00", Timezone: "Europe/Berlin"}
+	office := QuietHours{Start: "09:00", End: "17:00", Timezone: "Europe/Berlin"}
+
+	tests := []struct {
+		name   string
+		window QuietHours
+		at     time.Time
+		want   bool
+	}{
+		{name: "friday night", window: overnight, at: at("2026-10-16", "23:30"), want: true},
+		{name: "saturday morning belongs to friday", window: overnight, at: at("2026-10-17", "06:59"), want: true},
+		{name: "end is exclusive", window: overnight, at: at("2026-10-17", "07:00"), want: false},
+		{name: "sunday morning belongs to saturday", window: overnight, at: at("2026-10-18", "03:00"), want: true},
+		{name: "monday morning belongs to sunday", window: overnight, at: at("2026-10-19", "03:00"), want: false},
+		{name: "thursday night", window: overnight, at: at("2026-10-15", "23:00"), want: false},
+		{name: "every day inside", window: office, at: at("2026-10-18", "09:00"), want: true},
+		{name: "every day outside", window: office, at: at("2026-10-18", "17:00"), want: false},
+		{name: "other time zone", window: office, at: at("2026-10-16", "12:00").UTC(), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.window.Contains(tt.at); got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietAction(t *testing.T) {
+	now := time.Now()
+	always := func(action string) QuietHours {
+		return QuietHours{Start: "00:00", End: "24:00", Action: action}
+	}
+
+	tests := []struct {
+		name    string
+		windows []QuietHours
+		want    string
+	}{
+		{name: "no windows", want: ""},
+		{name: "default suppresses", windows: []QuietHours{always("")}, want: QuietSuppress},
+		{name: "downgrade", windows: []QuietHours{always(QuietDowngrade)}, want: QuietDowngrade},
+		{name: "suppress wins", windows: []QuietHours{always(QuietDowngrade), always(QuietSuppress)}, want: QuietSuppress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuietAction(tt.windows, now); got != tt.want {
+				t.Errorf("QuietAction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		window  QuietHours
+		wantErr bool
+	}{
+		{name: "valid", window: QuietHours{Days: []string{"mon", "tue"}, Start: "22:00", End: "06:30", Timezone: "UTC", Action: QuietDowngrade}},
+		{name: "until midnight", window: QuietHours{Start: "20:00", End: "24:00"}},
+		{name: "unknown day", window: QuietHours{Days: []string{"someday"}, Start: "22:00", End: "06:00"}, wantErr: true},
+		{name: "bad time", window: QuietHours{Start: "9:00", End: "17:00"}, wantErr: true},
+		{name: "minutes out of range", window: QuietHours{Start: "09:60", End: "17:00"}, wantErr: true},
+		{name: "empty window", window: QuietHours{Start: "09:00", End: "09:00"}, wantErr: true},
+		{name: "unknown time zone", window: QuietHours{Start: "09:00", End: "17:00", Timezone: "Mars/Olympus"}, wantErr: true},
+		{name: "unknown action", window: QuietHours{Start: "09:00", End: "17:00", Action: "mute"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.window.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}